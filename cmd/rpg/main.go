@@ -4,11 +4,13 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/kon1790/rpg/internal/generator"
 	"github.com/kon1790/rpg/internal/server"
 )
 
@@ -16,8 +18,28 @@ func main() {
 	// Parse command line flags
 	outputDir := flag.String("output", "./output", "Base output directory for generated projects (language subdirs will be created)")
 	flag.StringVar(outputDir, "o", "./output", "Base output directory (shorthand)")
+	templateDir := flag.String("templates", "", "Directory of <language>/<construct>.tmpl code templates overriding the defaults")
+	dumpDir := flag.String("dump-templates", "", "Write the default code templates to this directory and exit")
 	flag.Parse()
 
+	if *dumpDir != "" {
+		files, err := generator.DumpTemplates(*dumpDir)
+		if err != nil {
+			log.Fatalf("Failed to dump templates: %v", err)
+		}
+		for _, f := range files {
+			fmt.Println(f)
+		}
+		return
+	}
+
+	// Fail at startup rather than on the first generation
+	if *templateDir != "" {
+		if _, err := generator.LoadTemplates(*templateDir); err != nil {
+			log.Fatalf("Invalid templates: %v", err)
+		}
+	}
+
 	// Create context that cancels on interrupt
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -31,7 +53,7 @@ func main() {
 	}()
 
 	// Create and run the MCP server
-	srv := server.New(*outputDir)
+	srv := server.New(*outputDir, *templateDir)
 	if err := srv.Run(ctx); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
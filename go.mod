@@ -5,6 +5,7 @@ go 1.25.5
 require (
 	github.com/modelcontextprotocol/go-sdk v1.2.0
 	github.com/smacker/go-tree-sitter v0.0.0-20240827094217-dd81d9e9be82
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
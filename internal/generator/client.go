@@ -0,0 +1,2534 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/layout"
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// clientCall is a client method for one HTTP endpoint.
+type clientCall struct {
+	Route route
+
+	// Params are the method's inputs, required ones first
+	Params []clientParam
+
+	// Result is the success response's pseudo-type, empty when it has none
+	Result string
+
+	// Errors are the declared errors the endpoint can return
+	Errors []clientError
+}
+
+// clientParam is a client method input sent as a path, query or header
+// parameter, or as the JSON body.
+type clientParam struct {
+	Name     string // name of the method parameter
+	Key      string // name in the request (path template, query or header)
+	Source   string // path, query, header or body
+	Type     string // pseudo-type without any Optional wrapper
+	Optional bool
+}
+
+// clientError is a declared error the client maps responses to, either by
+// the error code in the response body or by its status code.
+type clientError struct {
+	// Name is the error's PascalCase name (e.g., "NotFound")
+	Name string
+
+	// Code is the error code servers send as {"error": "<code>"}
+	Code string
+
+	// Status is the HTTP status code the error is returned with, 0 if unknown
+	Status int
+
+	// Description explains when the error occurs
+	Description string
+}
+
+// wellKnownStatuses maps conventional error names to HTTP status codes.
+var wellKnownStatuses = map[string]int{
+	"badrequest": 400, "invalid": 400, "invalidinput": 400, "validation": 400, "validationerror": 400,
+	"unauthorized": 401, "unauthenticated": 401,
+	"forbidden": 403, "permissiondenied": 403,
+	"notfound": 404,
+	"conflict": 409, "alreadyexists": 409,
+	"toomanyrequests": 429, "ratelimited": 429,
+	"internal": 500, "internalerror": 500,
+	"unavailable": 503,
+}
+
+// resolveClientCalls builds the client methods for the spec's endpoints and
+// the catalog of declared errors they share. Errors come from the functions
+// endpoints call and from their non-2xx responses.
+func resolveClientCalls(spec *specparser.SpecAnalysis) ([]clientCall, []clientError) {
+	var calls []clientCall
+	var catalog []clientError
+	known := make(map[string]int)
+
+	declare := func(e clientError) clientError {
+		if i, ok := known[e.Name]; ok {
+			if catalog[i].Status == 0 {
+				catalog[i].Status = e.Status
+			}
+			return catalog[i]
+		}
+		known[e.Name] = len(catalog)
+		catalog = append(catalog, e)
+		return e
+	}
+
+	for _, r := range resolveRoutes(spec) {
+		e := r.Endpoint
+		call := clientCall{Route: r}
+
+		for _, resp := range e.Responses {
+			if strings.HasPrefix(resp.Status, "2") {
+				call.Result = resp.Type
+				break
+			}
+		}
+
+		// Path parameters, then the body, then query and header parameters
+		var required, optional []clientParam
+		add := func(p clientParam) {
+			if p.Optional {
+				optional = append(optional, p)
+			} else {
+				required = append(required, p)
+			}
+		}
+		for _, p := range endpointPathParams(e) {
+			inner, _ := optionalInner(p.Type)
+			add(clientParam{Name: p.Name, Key: p.Name, Source: "path", Type: inner})
+		}
+		if e.RequestType != "" {
+			add(clientParam{Name: "body", Key: "body", Source: "body", Type: e.RequestType})
+		}
+		for _, group := range []struct {
+			source string
+			params []specparser.SpecParameter
+		}{{"query", e.QueryParams}, {"header", e.HeaderParams}} {
+			for _, p := range group.params {
+				inner, wrapped := optionalInner(p.Type)
+				add(clientParam{Name: p.Name, Key: p.Name, Source: group.source, Type: inner, Optional: wrapped || !p.Required})
+			}
+		}
+		call.Params = append(required, optional...)
+
+		// Errors declared by the called function, linked to a response status
+		// by name where possible
+		seen := make(map[string]bool)
+		if r.Function != nil {
+			for _, fe := range r.Function.Errors {
+				name := specErrorName(fe)
+				if name == "" || seen[name] {
+					continue
+				}
+				ce := clientError{Name: name, Code: fe.Type, Description: fe.Condition, Status: wellKnownStatuses[normalizeName(name)]}
+				if ce.Code == "" {
+					ce.Code = name
+				}
+				for _, resp := range e.Responses {
+					if normalizeName(resp.Type) == normalizeName(name) || normalizeName(resp.Description) == normalizeName(name) {
+						ce.Status, _ = strconv.Atoi(resp.Status)
+					}
+				}
+				seen[name] = true
+				call.Errors = append(call.Errors, declare(ce))
+			}
+		}
+
+		// Remaining error responses become errors of their own
+		for _, resp := range e.Responses {
+			status, err := strconv.Atoi(resp.Status)
+			if err != nil || status < 400 {
+				continue
+			}
+			linked := false
+			for _, ce := range call.Errors {
+				linked = linked || ce.Status == status
+			}
+			if linked {
+				continue
+			}
+			name := toPascalCase(resp.Type)
+			if name == "" {
+				name = toPascalCase(resp.Description)
+			}
+			if name == "" {
+				name = fmt.Sprintf("Status%d", status)
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			call.Errors = append(call.Errors, declare(clientError{Name: name, Code: name, Status: status, Description: resp.Description}))
+		}
+
+		calls = append(calls, call)
+	}
+
+	return calls, catalog
+}
+
+// generateClient generates a typed HTTP client for the spec's endpoints,
+// with errors mapped from the declared error conditions and tests that run
+// it against a local stub server: net/http for Go, fetch for TypeScript,
+// httpx for Python, java.net.http for Java, reqwest for Rust and HttpClient
+// for C#.
+func (g *Generator) generateClient(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	if len(spec.Endpoints) == 0 {
+		return nil
+	}
+
+	lang := adapter.GetLanguage()
+	calls, catalog := resolveClientCalls(spec)
+	c := &clientWriter{spec: spec, calls: calls, catalog: catalog, types: make(map[string]specparser.SpecType)}
+	for _, t := range spec.Types {
+		c.types[strings.ToLower(t.Name)] = t
+	}
+
+	var client, test string
+	var clientPath, testPath string
+	switch lang.ID {
+	case "go":
+		clientPath, testPath = "client/client.go", "client/client_test.go"
+		client, test = c.goClient(), c.goClientTest()
+	case "typescript":
+		clientPath, testPath = "src/client.ts", "src/client.test.ts"
+		client, test = c.tsClient(), c.tsClientTest()
+	case "python":
+		clientPath, testPath = "src/client.py", "tests/test_client.py"
+		client, test = c.pythonClient(), c.pythonClientTest()
+	case "java":
+		pkg := toPackageName(spec.Name)
+		clientPath = fmt.Sprintf("src/main/java/%s/ApiClient.java", pkg)
+		testPath = fmt.Sprintf("src/test/java/%s/ApiClientTest.java", pkg)
+		client, test = c.javaClient(), c.javaClientTest()
+	case "rust":
+		clientPath, testPath = "src/client.rs", "tests/client.rs"
+		client, test = c.rustClient(), c.rustClientTest()
+	case "csharp":
+		clientPath, testPath = "src/ApiClient.cs", "tests/ApiClientTests.cs"
+		client, test = c.csharpClient(), c.csharpClientTest()
+	default:
+		return nil
+	}
+
+	var elements []string
+	for _, call := range calls {
+		elements = append(elements, call.Route.Name)
+	}
+
+	return []GeneratedFile{
+		{Path: clientPath, Content: client, Category: "client", Elements: elements},
+		{Path: testPath, Content: test, Category: "test", Elements: elements},
+	}
+}
+
+// clientWriter renders a client and its tests for one language.
+type clientWriter struct {
+	spec    *specparser.SpecAnalysis
+	calls   []clientCall
+	catalog []clientError
+	types   map[string]specparser.SpecType
+}
+
+// region wraps a client method in region markers keyed by its endpoint.
+func (c *clientWriter) region(langID string, call clientCall, code string) string {
+	return wrapRegion(langID, "client", call.Route.Name, hashOf(call.Route.Endpoint), code)
+}
+
+// errorClass names a declared error's class with the language's suffix
+// (e.g., "NotFoundError"), without doubling a suffix the name already has.
+func errorClass(name, suffix string) string {
+	for _, existing := range []string{"Error", "Exception"} {
+		if strings.HasSuffix(name, existing) && len(name) > len(existing) {
+			name = strings.TrimSuffix(name, existing)
+		}
+	}
+	return name + suffix
+}
+
+// summary describes an endpoint for doc comments.
+func (c *clientWriter) summary(call clientCall) string {
+	e := call.Route.Endpoint
+	if e.Description != "" {
+		return fmt.Sprintf("%s %s - %s", e.Method, e.Path, e.Description)
+	}
+	return e.Method + " " + e.Path
+}
+
+// specType looks up a spec type by (case-insensitive) name.
+func (c *clientWriter) specType(name string) (specparser.SpecType, bool) {
+	t, ok := c.types[strings.ToLower(strings.TrimSpace(name))]
+	return t, ok
+}
+
+// testError returns the error a test provokes for a call: its first declared
+// error with a known status, or a generic 500.
+func testError(call clientCall) (clientError, bool) {
+	for _, e := range call.Errors {
+		if e.Status != 0 {
+			return e, true
+		}
+	}
+	return clientError{Status: 500}, false
+}
+
+// errorMessage turns an error name into a lower-case message.
+func errorMessage(name string) string {
+	return strings.ToLower(strings.Join(splitWords(toPascalCase(name)), " "))
+}
+
+// errorsByStatus returns the catalog errors with a known status, one per
+// status, ordered by status.
+func errorsByStatus(catalog []clientError) []clientError {
+	seen := make(map[int]bool)
+	var byStatus []clientError
+	for _, e := range catalog {
+		if e.Status != 0 && !seen[e.Status] {
+			seen[e.Status] = true
+			byStatus = append(byStatus, e)
+		}
+	}
+	sort.SliceStable(byStatus, func(i, j int) bool { return byStatus[i].Status < byStatus[j].Status })
+	return byStatus
+}
+
+// samplePathValue returns the raw path value sample arguments produce.
+func samplePathValue(pseudoType string) string {
+	switch strings.ToLower(pseudoType) {
+	case "int", "integer", "int64":
+		return "1"
+	case "float", "float64":
+		return "1.5"
+	case "bool", "boolean":
+		return "true"
+	}
+	return "1"
+}
+
+// samplePath returns the request path sample arguments produce.
+func samplePath(call clientCall) string {
+	path := call.Route.Endpoint.Path
+	types := make(map[string]string)
+	for _, p := range call.Params {
+		if p.Source == "path" {
+			types[p.Key] = p.Type
+		}
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := pathParamName(segment); ok {
+			segments[i] = samplePathValue(types[name])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// sampleJSON returns a JSON document for a pseudo-type, naming struct fields
+// with their wire names.
+func (c *clientWriter) sampleJSON(pseudoType, lang string, depth int) string {
+	t := strings.TrimSpace(pseudoType)
+	lower := strings.ToLower(t)
+
+	if _, optional := optionalInner(t); optional {
+		return "null"
+	}
+	switch {
+	case strings.HasPrefix(lower, "list[") || strings.HasPrefix(lower, "[]") || strings.HasPrefix(lower, "array["):
+		return "[]"
+	case strings.HasPrefix(lower, "map[") || strings.HasPrefix(lower, "dict["):
+		return "{}"
+	}
+	switch lower {
+	case "", "void":
+		return ""
+	case "string", "str", "uuid":
+		return `"1"`
+	case "int", "integer", "int64":
+		return "1"
+	case "float", "float64":
+		return "1.5"
+	case "bool", "boolean":
+		return "true"
+	case "date":
+		return `"2024-01-02"`
+	case "datetime":
+		return `"2024-01-02T03:04:05Z"`
+	case "any":
+		return "null"
+	}
+
+	st, ok := c.specType(t)
+	if !ok || depth > 3 {
+		return "null"
+	}
+	switch st.Kind {
+	case "enum":
+		if len(st.Values) == 0 {
+			return "null"
+		}
+		switch lang {
+		case "csharp":
+			return "0"
+		case "rust":
+			return strconv.Quote(strings.TrimPrefix(valueIdent("rust", st.Values[0].Name), "r#"))
+		case "python":
+			if st.Values[0].Value != "" {
+				return st.Values[0].Value
+			}
+		}
+		return strconv.Quote(st.Values[0].Name)
+	case "struct", "class", "type":
+		var fields []string
+		for _, f := range st.Fields {
+			if wireSkipped(f) {
+				continue
+			}
+			value := "null"
+			if sample, constrained, ok := constrainedSample(f); f.Required && constrained && ok {
+				value = sample
+			} else if f.Required {
+				value = c.sampleJSON(f.Type, lang, depth+1)
+			}
+			fields = append(fields, fmt.Sprintf("%q:%s", wireName(st, f), value))
+		}
+		return "{" + strings.Join(fields, ",") + "}"
+	}
+	return "null"
+}
+
+// identifierPattern matches (possibly qualified) identifiers in a rendered type.
+var identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]*`)
+
+// ============================================================================
+// Go (net/http)
+// ============================================================================
+
+// goType maps a pseudo-type for the client package, qualifying spec types
+// with the package of the module they are generated in.
+func (c *clientWriter) goType(pseudoType string) string {
+	return identifierPattern.ReplaceAllStringFunc(mapType(pseudoType, "go"), func(ident string) string {
+		if _, ok := c.specType(ident); ok {
+			return layout.Package("go", toPackageName(c.spec.Name), typeModule(c.spec, ident)) + "." + ident
+		}
+		return ident
+	})
+}
+
+// goImports returns the import lines of the module packages code refers
+// to, as a separate group.
+func (c *clientWriter) goImports(code string) string {
+	var sb strings.Builder
+	seen := make(map[string]bool)
+	for _, t := range c.spec.Types {
+		pkg := layout.Package("go", toPackageName(c.spec.Name), t.Module)
+		if importPath := goImportPath(c.spec, t.Module); !seen[importPath] && strings.Contains(code, pkg+".") {
+			seen[importPath] = true
+			sb.WriteString(fmt.Sprintf("\t%q\n", importPath))
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "\n" + sb.String()
+}
+
+// goParamType returns the Go type of a client parameter.
+func (c *clientWriter) goParamType(p clientParam) string {
+	if p.Optional {
+		return "*" + c.goType(p.Type)
+	}
+	return c.goType(p.Type)
+}
+
+func (c *clientWriter) goClient() string {
+	var sb strings.Builder
+
+	var methods strings.Builder
+	for _, call := range c.calls {
+		methods.WriteString(c.region("go", call, c.goMethod(call)))
+		methods.WriteString("\n")
+	}
+
+	imports := []string{"bytes", "context", "encoding/json", "errors", "fmt", "io", "net/http", "net/url", "strings"}
+	if len(c.catalog) == 0 {
+		imports = removeString(imports, "errors")
+	}
+	if strings.Contains(methods.String(), "time.") {
+		imports = append(imports, "time")
+	}
+	sort.Strings(imports)
+
+	sb.WriteString(fmt.Sprintf("// Package client is a typed HTTP client for the %s API.\n", c.spec.Name))
+	sb.WriteString("package client\n\nimport (\n")
+	for _, imp := range imports {
+		sb.WriteString(fmt.Sprintf("\t%q\n", imp))
+	}
+	sb.WriteString(c.goImports(methods.String()))
+	sb.WriteString(")\n\n")
+
+	sb.WriteString(`// Client calls the API over HTTP.
+type Client struct {
+	// BaseURL is the API root (e.g., "https://api.example.com")
+	BaseURL string
+
+	// HTTPClient sends requests; http.DefaultClient is used when nil
+	HTTPClient *http.Client
+
+	// Header is sent with every request (e.g., Authorization)
+	Header http.Header
+}
+
+// NewClient creates a client for the API at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Header:  http.Header{},
+	}
+}
+
+// APIError is returned for responses outside the 2xx range. It unwraps to
+// the declared error the response maps to, so callers can use errors.Is.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Err        error
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the declared error the response maps to, if any.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+`)
+
+	if len(c.catalog) > 0 {
+		sb.WriteString("// Declared errors\nvar (\n")
+		for i, e := range c.catalog {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			if e.Description != "" {
+				sb.WriteString(fmt.Sprintf("\t// Err%s is returned when %s\n", errorClass(e.Name, ""), lowerFirst(e.Description)))
+			}
+			sb.WriteString(fmt.Sprintf("\tErr%s = errors.New(%q)\n", errorClass(e.Name, ""), errorMessage(errorClass(e.Name, ""))))
+		}
+		sb.WriteString(")\n\n")
+	}
+
+	sb.WriteString("// errorCodes maps the error codes servers send to the declared errors.\n")
+	sb.WriteString("var errorCodes = map[string]error{\n")
+	for _, e := range c.catalog {
+		sb.WriteString(fmt.Sprintf("\t%q: Err%s,\n", e.Code, errorClass(e.Name, "")))
+	}
+	sb.WriteString("}\n\n")
+	sb.WriteString("// errorStatuses maps status codes to the declared errors.\n")
+	sb.WriteString("var errorStatuses = map[int]error{\n")
+	for _, e := range errorsByStatus(c.catalog) {
+		sb.WriteString(fmt.Sprintf("\t%d: Err%s,\n", e.Status, errorClass(e.Name, "")))
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString(methods.String())
+
+	sb.WriteString(`// do sends a request and decodes a JSON response into result.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp.StatusCode, data)
+	}
+	if result != nil && len(data) > 0 {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
+
+// newAPIError maps an error response to an APIError, reading the error code
+// and message from a {"error": ..., "message": ...} body when present.
+func newAPIError(status int, data []byte) *APIError {
+	apiErr := &APIError{StatusCode: status, Message: http.StatusText(status)}
+
+	var body struct {
+		Error   string ` + "`json:\"error\"`" + `
+		Code    string ` + "`json:\"code\"`" + `
+		Message string ` + "`json:\"message\"`" + `
+	}
+	if json.Unmarshal(data, &body) == nil {
+		apiErr.Code = body.Error
+		if apiErr.Code == "" {
+			apiErr.Code = body.Code
+		}
+		if body.Message != "" {
+			apiErr.Message = body.Message
+		}
+	}
+
+	apiErr.Err = errorCodes[apiErr.Code]
+	if apiErr.Err == nil {
+		apiErr.Err = errorStatuses[status]
+	}
+	return apiErr
+}
+`)
+	return sb.String()
+}
+
+// goMethod renders the client method for one endpoint.
+func (c *clientWriter) goMethod(call clientCall) string {
+	var sb strings.Builder
+	name := toPascalCase(call.Route.Name)
+
+	params := []string{"ctx context.Context"}
+	for _, p := range call.Params {
+		params = append(params, fmt.Sprintf("%s %s", paramIdent("go", p.Name), c.goParamType(p)))
+	}
+	returns := "error"
+	if call.Result != "" {
+		returns = fmt.Sprintf("(%s, error)", c.goType(call.Result))
+	}
+
+	sb.WriteString(fmt.Sprintf("// %s calls %s\n", name, c.summary(call)))
+	sb.WriteString(fmt.Sprintf("func (c *Client) %s(%s) %s {\n", name, strings.Join(params, ", "), returns))
+
+	query, header, body := "nil", "nil", "nil"
+	var path []string
+	for _, segment := range strings.Split(call.Route.Endpoint.Path, "/")[1:] {
+		if key, ok := pathParamName(segment); ok {
+			for _, p := range call.Params {
+				if p.Source == "path" && p.Key == key {
+					path = append(path, fmt.Sprintf("url.PathEscape(fmt.Sprint(%s))", paramIdent("go", p.Name)))
+				}
+			}
+		} else {
+			path = append(path, strconv.Quote(segment))
+		}
+	}
+	pathExpr := `"/"+` + strings.Join(path, `+"/"+`)
+	pathExpr = strings.ReplaceAll(pathExpr, `"+"`, "")
+	if len(path) == 0 {
+		pathExpr = `"/"`
+	}
+
+	for _, p := range call.Params {
+		v := paramIdent("go", p.Name)
+		switch p.Source {
+		case "query", "header":
+			target := "query"
+			if p.Source == "header" {
+				target = "header"
+			}
+			if (target == "query" && query == "nil") || (target == "header" && header == "nil") {
+				if target == "query" {
+					sb.WriteString("\tquery := url.Values{}\n")
+					query = "query"
+				} else {
+					sb.WriteString("\theader := http.Header{}\n")
+					header = "header"
+				}
+			}
+			if p.Optional {
+				sb.WriteString(fmt.Sprintf("\tif %s != nil {\n\t\t%s.Set(%q, fmt.Sprint(*%s))\n\t}\n", v, target, p.Key, v))
+			} else {
+				sb.WriteString(fmt.Sprintf("\t%s.Set(%q, fmt.Sprint(%s))\n", target, p.Key, v))
+			}
+		case "body":
+			body = v
+		}
+	}
+
+	if call.Result != "" {
+		sb.WriteString(fmt.Sprintf("\tvar result %s\n", c.goType(call.Result)))
+		sb.WriteString(fmt.Sprintf("\terr := c.do(ctx, %q, %s, %s, %s, %s, &result)\n", call.Route.Endpoint.Method, pathExpr, query, header, body))
+		sb.WriteString("\treturn result, err\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("\treturn c.do(ctx, %q, %s, %s, %s, %s, nil)\n", call.Route.Endpoint.Method, pathExpr, query, header, body))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// goSample returns a Go expression for a sample argument.
+func (c *clientWriter) goSample(p clientParam) string {
+	if p.Optional {
+		return "nil"
+	}
+	switch strings.ToLower(p.Type) {
+	case "string", "str", "uuid":
+		return `"1"`
+	case "int", "integer", "int64":
+		return "1"
+	case "float", "float64":
+		return "1.5"
+	case "bool", "boolean":
+		return "true"
+	}
+	if st, ok := c.specType(p.Type); ok && st.Kind != "enum" && st.Kind != "alias" {
+		return c.goType(p.Type) + "{}"
+	}
+	return fmt.Sprintf("*new(%s)", c.goType(p.Type))
+}
+
+func (c *clientWriter) goClientTest() string {
+	var sb strings.Builder
+	sb.WriteString("package client\n\n")
+
+	imports := []string{"context", "net/http", "net/http/httptest", "testing"}
+	var tests strings.Builder
+	for _, call := range c.calls {
+		name := toPascalCase(call.Route.Name)
+		var args []string
+		args = append(args, "context.Background()")
+		for _, p := range call.Params {
+			args = append(args, c.goSample(p))
+		}
+		invoke := fmt.Sprintf("NewClient(server.URL).%s(%s)", name, strings.Join(args, ", "))
+		discard := "_, err :="
+		if call.Result == "" {
+			discard = "err :="
+		}
+
+		status := call.Route.Status
+		body := c.sampleJSON(call.Result, "go", 0)
+		tests.WriteString(fmt.Sprintf("func Test%s(t *testing.T) {\n", name))
+		tests.WriteString(fmt.Sprintf("\tserver := stubServer(t, %s, %q, %q, %s)\n", status, call.Route.Endpoint.Method, samplePath(call), backQuote(body)))
+		tests.WriteString("\tdefer server.Close()\n\n")
+		tests.WriteString(fmt.Sprintf("\tif %s %s; err != nil {\n", discard, invoke))
+		tests.WriteString(fmt.Sprintf("\t\tt.Fatalf(\"%s() error: %%v\", err)\n\t}\n}\n\n", name))
+
+		e, declared := testError(call)
+		errBody := fmt.Sprintf(`{"error":%q,"message":"boom"}`, e.Code)
+		if !declared {
+			errBody = `{"message":"boom"}`
+		}
+		tests.WriteString(fmt.Sprintf("func Test%sError(t *testing.T) {\n", name))
+		tests.WriteString(fmt.Sprintf("\tserver := stubServer(t, %d, %q, %q, %s)\n", e.Status, call.Route.Endpoint.Method, samplePath(call), backQuote(errBody)))
+		tests.WriteString("\tdefer server.Close()\n\n")
+		tests.WriteString(fmt.Sprintf("\t%s %s\n", discard, invoke))
+		if declared {
+			tests.WriteString(fmt.Sprintf("\tif !errors.Is(err, Err%s) {\n", errorClass(e.Name, "")))
+			tests.WriteString(fmt.Sprintf("\t\tt.Errorf(\"Expected Err%s, got %%v\", err)\n\t}\n}\n\n", errorClass(e.Name, "")))
+		} else {
+			tests.WriteString("\tvar apiErr *APIError\n")
+			tests.WriteString(fmt.Sprintf("\tif !errors.As(err, &apiErr) || apiErr.StatusCode != %d {\n", e.Status))
+			tests.WriteString("\t\tt.Errorf(\"Expected APIError, got %v\", err)\n\t}\n}\n\n")
+		}
+	}
+	imports = append(imports, "errors")
+	sort.Strings(imports)
+
+	sb.WriteString("import (\n")
+	for _, imp := range imports {
+		sb.WriteString(fmt.Sprintf("\t%q\n", imp))
+	}
+	sb.WriteString(c.goImports(tests.String()))
+	sb.WriteString(")\n\n")
+
+	sb.WriteString(`// stubServer starts a local server that expects one request and answers it
+// with a canned response.
+func stubServer(t *testing.T, status int, method, path, body string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method || r.URL.Path != path {
+			t.Errorf("Unexpected request %s %s, expected %s %s", r.Method, r.URL.Path, method, path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+`)
+	sb.WriteString(strings.TrimSuffix(tests.String(), "\n"))
+	return sb.String()
+}
+
+// backQuote quotes a JSON document as a Go raw string literal.
+func backQuote(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// removeString returns values without s.
+func removeString(values []string, s string) []string {
+	var out []string
+	for _, v := range values {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// lowerFirst lowercases the first letter of a sentence.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// ============================================================================
+// TypeScript (fetch)
+// ============================================================================
+
+// typeImports returns the spec types generated code references.
+func (c *clientWriter) typeImports(code string) []string {
+	var names []string
+	for _, t := range c.spec.Types {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(t.Name) + `\b`).MatchString(code) {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// byModule groups type names by the module declaring them.
+func (c *clientWriter) byModule(names []string) []moduleNames {
+	return byModule(names, func(name string) string { return typeModule(c.spec, name) })
+}
+
+// foreignImports returns the Java or C# statements importing the
+// types code references from modules other than the root module.
+func (c *clientWriter) foreignImports(langID, code string) string {
+	var foreign []moduleNames
+	for _, group := range c.byModule(c.typeImports(code)) {
+		if group.Module != "" {
+			foreign = append(foreign, group)
+		}
+	}
+	return moduleImports(c.spec, langID, "", foreign)
+}
+
+func (c *clientWriter) tsClient() string {
+	var methods strings.Builder
+	for _, call := range c.calls {
+		methods.WriteString(c.region("typescript", call, c.tsMethod(call)))
+		methods.WriteString("\n")
+	}
+
+	var sb strings.Builder
+	if strings.Contains(methods.String(), "z.") {
+		sb.WriteString("import { z } from \"zod\";\n")
+	}
+	for _, group := range c.byModule(c.typeImports(methods.String())) {
+		sb.WriteString(fmt.Sprintf("import type { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath("src/client.ts", group.Module, "types")))
+	}
+	for _, group := range c.byModule(c.tsSchemaImports(methods.String())) {
+		sb.WriteString(fmt.Sprintf("import { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath("src/client.ts", group.Module, "types")))
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`/** Thrown for responses outside the 2xx range. */
+export class ApiError extends Error {
+  constructor(
+    public readonly status: number,
+    public readonly code: string,
+    message: string,
+  ) {
+    super(message);
+    this.name = new.target.name;
+  }
+}
+
+`)
+	for _, e := range c.catalog {
+		if e.Description != "" {
+			sb.WriteString(fmt.Sprintf("/** Thrown when %s */\n", lowerFirst(e.Description)))
+		}
+		sb.WriteString(fmt.Sprintf("export class %s extends ApiError {}\n\n", errorClass(e.Name, "Error")))
+	}
+
+	sb.WriteString("type ErrorClass = new (status: number, code: string, message: string) => ApiError;\n\n")
+	sb.WriteString("/** Maps the error codes servers send to the declared errors. */\n")
+	sb.WriteString("const errorCodes: Record<string, ErrorClass> = {\n")
+	for _, e := range c.catalog {
+		sb.WriteString(fmt.Sprintf("  %q: %s,\n", e.Code, errorClass(e.Name, "Error")))
+	}
+	sb.WriteString("};\n\n")
+	sb.WriteString("/** Maps status codes to the declared errors. */\n")
+	sb.WriteString("const errorStatuses: Record<number, ErrorClass> = {\n")
+	for _, e := range errorsByStatus(c.catalog) {
+		sb.WriteString(fmt.Sprintf("  %d: %s,\n", e.Status, errorClass(e.Name, "Error")))
+	}
+	sb.WriteString("};\n\n")
+
+	sb.WriteString(`interface RequestOptions {
+  query?: Record<string, unknown>;
+  headers?: Record<string, unknown>;
+  body?: unknown;
+}
+
+/** Calls the API over HTTP. */
+export class Client {
+  constructor(
+    private readonly baseUrl: string,
+    private readonly headers: Record<string, string> = {},
+  ) {}
+
+`)
+	sb.WriteString(methods.String())
+	sb.WriteString(`  private async request<T>(method: string, path: string, options: RequestOptions = {}): Promise<T> {
+    const url = new URL(this.baseUrl.replace(/\/$/, "") + path);
+    for (const [name, value] of Object.entries(options.query ?? {})) {
+      if (value !== undefined && value !== null) {
+        url.searchParams.set(name, String(value));
+      }
+    }
+
+    const headers: Record<string, string> = { Accept: "application/json", ...this.headers };
+    for (const [name, value] of Object.entries(options.headers ?? {})) {
+      if (value !== undefined && value !== null) {
+        headers[name] = String(value);
+      }
+    }
+    if (options.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+
+    const response = await fetch(url, {
+      method,
+      headers,
+      body: options.body === undefined ? undefined : JSON.stringify(options.body),
+    });
+    const text = await response.text();
+    let data: any = undefined;
+    try {
+      data = text ? JSON.parse(text) : undefined;
+    } catch {
+      // Not a JSON body
+    }
+    if (!response.ok) {
+      throw toError(response.status, data);
+    }
+    return data as T;
+  }
+}
+
+/** Maps an error response to a declared error by its code, then its status. */
+function toError(status: number, data: any): ApiError {
+  const code = String(data?.error ?? data?.code ?? "");
+  const message = String(data?.message ?? ` + "`HTTP ${status}`" + `);
+  const ErrorType = errorCodes[code] ?? errorStatuses[status] ?? ApiError;
+  return new ErrorType(status, code, message);
+}
+`)
+	return sb.String()
+}
+
+// tsMethod renders the client method for one endpoint.
+func (c *clientWriter) tsMethod(call clientCall) string {
+	var sb strings.Builder
+	var params []string
+	options := make(map[string][]string)
+	for _, p := range call.Params {
+		v := paramIdent("typescript", p.Name)
+		typ := mapType(p.Type, "typescript")
+		if p.Optional {
+			params = append(params, fmt.Sprintf("%s?: %s | null", v, typ))
+		} else {
+			params = append(params, fmt.Sprintf("%s: %s", v, typ))
+		}
+		switch p.Source {
+		case "query", "header":
+			entry := v
+			if p.Key != v {
+				entry = fmt.Sprintf("%q: %s", p.Key, v)
+			}
+			options[p.Source] = append(options[p.Source], entry)
+		}
+	}
+
+	var path []string
+	for _, segment := range strings.Split(call.Route.Endpoint.Path, "/") {
+		if key, ok := pathParamName(segment); ok {
+			segment = fmt.Sprintf("${encodeURIComponent(String(%s))}", paramIdent("typescript", paramFor(call, key).Name))
+		}
+		path = append(path, segment)
+	}
+
+	var opts []string
+	if q := options["query"]; len(q) > 0 {
+		opts = append(opts, fmt.Sprintf("query: { %s }", strings.Join(q, ", ")))
+	}
+	if h := options["header"]; len(h) > 0 {
+		opts = append(opts, fmt.Sprintf("headers: { %s }", strings.Join(h, ", ")))
+	}
+	for _, p := range call.Params {
+		if p.Source == "body" {
+			v := paramIdent("typescript", p.Name)
+			if expr, err := typeexpr.Parse(p.Type); err == nil {
+				if converted, ok := tsWireValue(expr, v, c.spec.Types); ok {
+					if p.Optional {
+						converted = fmt.Sprintf("%s == null ? undefined : %s", v, converted)
+					}
+					opts = append(opts, "body: "+converted)
+					continue
+				}
+			}
+			if v == "body" {
+				opts = append(opts, v)
+			} else {
+				opts = append(opts, "body: "+v)
+			}
+		}
+	}
+	pathExpr := strconv.Quote(strings.Join(path, "/"))
+	if strings.Contains(pathExpr, "${") {
+		pathExpr = "`" + strings.Join(path, "/") + "`"
+	}
+	args := fmt.Sprintf("%q, %s", call.Route.Endpoint.Method, pathExpr)
+	if len(opts) > 0 {
+		args += fmt.Sprintf(", { %s }", strings.Join(opts, ", "))
+	}
+
+	result := "void"
+	if call.Result != "" {
+		result = mapType(call.Result, "typescript")
+	}
+	sb.WriteString(fmt.Sprintf("  /** %s */\n", c.summary(call)))
+	sb.WriteString(fmt.Sprintf("  async %s(%s): Promise<%s> {\n", toCamelCase(call.Route.Name), strings.Join(params, ", "), result))
+	if schema, ok := c.tsResultSchema(call.Result); ok {
+		// Results holding spec types are parsed from their wire format
+		sb.WriteString(fmt.Sprintf("    return %s.parse(await this.request<unknown>(%s));\n", schema, args))
+	} else {
+		sb.WriteString(fmt.Sprintf("    return this.request<%s>(%s);\n", result, args))
+	}
+	sb.WriteString("  }\n")
+	return sb.String()
+}
+
+// tsResultSchema returns the zod schema parsing a result, when the result
+// holds spec types whose wire format needs parsing.
+func (c *clientWriter) tsResultSchema(pseudoType string) (string, bool) {
+	if pseudoType == "" {
+		return "", false
+	}
+	expr, err := typeexpr.Parse(pseudoType)
+	if err != nil {
+		return "", false
+	}
+	if expr.Kind == typeexpr.Named {
+		if st, ok := findType(c.spec.Types, expr.Name); ok && st.Kind != "interface" {
+			return tsSchemaName(st.Name), true
+		}
+	}
+	for _, name := range expr.Names() {
+		if st, ok := findType(c.spec.Types, name); ok && st.Kind != "interface" {
+			return zodSchema(expr, c.spec.Types), true
+		}
+	}
+	return "", false
+}
+
+// tsSchemaImports returns the schemas and wire conversions generated code
+// references.
+func (c *clientWriter) tsSchemaImports(code string) []string {
+	var names []string
+	for _, t := range c.spec.Types {
+		for _, name := range []string{tsSchemaName(t.Name), tsToWireName(t.Name)} {
+			if regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`).MatchString(code) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// paramFor returns a call's path parameter for a path template name.
+func paramFor(call clientCall, key string) clientParam {
+	for _, p := range call.Params {
+		if p.Source == "path" && p.Key == key {
+			return p
+		}
+	}
+	return clientParam{Name: key, Key: key, Source: "path", Type: "string"}
+}
+
+// scalarSample returns a sample literal for scalar pseudo-types, shared by
+// the C-like languages.
+func scalarSample(pseudoType string) (string, bool) {
+	switch strings.ToLower(pseudoType) {
+	case "string", "str", "uuid":
+		return `"1"`, true
+	case "int", "integer", "int64":
+		return "1", true
+	case "float", "float64":
+		return "1.5", true
+	case "bool", "boolean":
+		return "true", true
+	}
+	return "", false
+}
+
+// sampleArgs returns the sample arguments for a call, leaving out trailing
+// optional parameters for languages with default arguments.
+func (c *clientWriter) sampleArgs(call clientCall, sample func(clientParam) string, skipOptional bool) string {
+	var args []string
+	for _, p := range call.Params {
+		if p.Optional && skipOptional {
+			continue
+		}
+		args = append(args, sample(p))
+	}
+	return strings.Join(args, ", ")
+}
+
+func (c *clientWriter) tsClientTest() string {
+	var tests strings.Builder
+	imports := []string{"ApiError", "Client"}
+	for _, call := range c.calls {
+		name := toCamelCase(call.Route.Name)
+		args := c.sampleArgs(call, func(p clientParam) string {
+			if v, ok := scalarSample(p.Type); ok {
+				return v
+			}
+			if st, ok := c.specType(p.Type); ok && isStructKind(st.Kind) {
+				if wire, ok := structSample(st, c.spec.Types, 0); ok {
+					return fmt.Sprintf("%s.parse(%s)", tsSchemaName(st.Name), wire)
+				}
+			}
+			return fmt.Sprintf("{} as %s", mapType(p.Type, "typescript"))
+		}, true)
+		request := call.Route.Endpoint.Method + " " + samplePath(call)
+
+		tests.WriteString(fmt.Sprintf("  it(%q, async () => {\n", name))
+		tests.WriteString(fmt.Sprintf("    const url = await stub(%s, %s);\n", call.Route.Status, singleQuote(c.sampleJSON(call.Result, "typescript", 0))))
+		tests.WriteString(fmt.Sprintf("    await new Client(url).%s(%s);\n", name, args))
+		tests.WriteString(fmt.Sprintf("    expect(requests).toEqual([%q]);\n", request))
+		tests.WriteString("  });\n\n")
+
+		e, declared := testError(call)
+		errorType := "ApiError"
+		body := `{"message":"boom"}`
+		if declared {
+			errorType = errorClass(e.Name, "Error")
+			body = fmt.Sprintf(`{"error":%q,"message":"boom"}`, e.Code)
+			if !containsString(imports, errorType) {
+				imports = append(imports, errorType)
+			}
+		}
+		tests.WriteString(fmt.Sprintf("  it(%q, async () => {\n", name+" maps errors"))
+		tests.WriteString(fmt.Sprintf("    const url = await stub(%d, %s);\n", e.Status, singleQuote(body)))
+		tests.WriteString(fmt.Sprintf("    await expect(new Client(url).%s(%s)).rejects.toBeInstanceOf(%s);\n", name, args, errorType))
+		tests.WriteString("  });\n\n")
+	}
+	sort.Strings(imports)
+
+	var sb strings.Builder
+	sb.WriteString("import { afterEach, describe, expect, it } from \"vitest\";\n")
+	sb.WriteString("import { createServer, Server } from \"node:http\";\n")
+	sb.WriteString("import type { AddressInfo } from \"node:net\";\n")
+	sb.WriteString(fmt.Sprintf("import { %s } from \"./client\";\n", strings.Join(imports, ", ")))
+	for _, group := range c.byModule(c.typeImports(tests.String())) {
+		sb.WriteString(fmt.Sprintf("import type { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath("src/client.test.ts", group.Module, "types")))
+	}
+	for _, group := range c.byModule(c.tsSchemaImports(tests.String())) {
+		sb.WriteString(fmt.Sprintf("import { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath("src/client.test.ts", group.Module, "types")))
+	}
+	sb.WriteString(`
+let server: Server | undefined;
+let requests: string[] = [];
+
+afterEach(() => {
+  server?.close();
+  requests = [];
+});
+
+/** Starts a local server that answers every request with a canned response. */
+async function stub(status: number, body: string): Promise<string> {
+  server = createServer((req, res) => {
+    requests.push(` + "`${req.method} ${new URL(req.url ?? \"/\", \"http://localhost\").pathname}`" + `);
+    req.resume();
+    req.on("end", () => {
+      res.writeHead(status, { "Content-Type": "application/json" });
+      res.end(body);
+    });
+  });
+  await new Promise<void>((resolve) => server!.listen(0, "127.0.0.1", resolve));
+  return ` + "`http://127.0.0.1:${(server!.address() as AddressInfo).port}`" + `;
+}
+
+describe("Client", () => {
+`)
+	sb.WriteString(strings.TrimSuffix(tests.String(), "\n"))
+	sb.WriteString("});\n")
+	return sb.String()
+}
+
+// singleQuote quotes a JSON document with single quotes so its double quotes
+// need no escaping, for languages that accept either.
+func singleQuote(s string) string {
+	if strings.ContainsAny(s, `'\`) {
+		return strconv.Quote(s)
+	}
+	return "'" + s + "'"
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// Python (httpx)
+// ============================================================================
+
+func (c *clientWriter) pythonClient() string {
+	var methods strings.Builder
+	for _, call := range c.calls {
+		methods.WriteString("\n")
+		methods.WriteString(c.region("python", call, c.pythonMethod(call)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\"\"\"Typed HTTP client for the %s API.\"\"\"\n\n", c.spec.Name))
+	sb.WriteString("from enum import Enum\n")
+	sb.WriteString("from typing import Any, Dict, List, Optional\n")
+	sb.WriteString("from urllib.parse import quote\n\n")
+	sb.WriteString("import httpx\n")
+	sb.WriteString("from pydantic import BaseModel\n\n")
+	if groups := c.byModule(c.typeImports(methods.String())); len(groups) > 0 {
+		for _, group := range groups {
+			sb.WriteString(fmt.Sprintf("from %s import %s\n", pythonRelativePath(group.Module, "types"), strings.Join(group.Names, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`
+class ApiError(Exception):
+    """Raised for responses outside the 2xx range."""
+
+    def __init__(self, status: int, code: str, message: str):
+        super().__init__(message)
+        self.status = status
+        self.code = code
+`)
+	for _, e := range c.catalog {
+		sb.WriteString(fmt.Sprintf("\n\nclass %s(ApiError):\n", errorClass(e.Name, "Error")))
+		if e.Description != "" {
+			sb.WriteString(fmt.Sprintf("    \"\"\"Raised when %s\"\"\"\n", lowerFirst(e.Description)))
+		} else {
+			sb.WriteString("    pass\n")
+		}
+	}
+
+	sb.WriteString("\n\n# Maps the error codes servers send to the declared errors\n")
+	sb.WriteString("ERROR_CODES: Dict[str, type] = {\n")
+	for _, e := range c.catalog {
+		sb.WriteString(fmt.Sprintf("    %q: %s,\n", e.Code, errorClass(e.Name, "Error")))
+	}
+	sb.WriteString("}\n\n# Maps status codes to the declared errors\n")
+	sb.WriteString("ERROR_STATUSES: Dict[int, type] = {\n")
+	for _, e := range errorsByStatus(c.catalog) {
+		sb.WriteString(fmt.Sprintf("    %d: %s,\n", e.Status, errorClass(e.Name, "Error")))
+	}
+	sb.WriteString("}\n\n\n")
+
+	sb.WriteString(`class Client:
+    """Calls the API over HTTP."""
+
+    def __init__(self, base_url: str, headers: Optional[Dict[str, str]] = None):
+        self._http = httpx.Client(base_url=base_url.rstrip("/"), headers=headers or {})
+
+    def close(self) -> None:
+        self._http.close()
+
+    def __enter__(self) -> "Client":
+        return self
+
+    def __exit__(self, *args: Any) -> None:
+        self.close()
+`)
+	sb.WriteString(methods.String())
+	sb.WriteString(`
+    def _request(
+        self,
+        method: str,
+        path: str,
+        query: Optional[Dict[str, Any]] = None,
+        headers: Optional[Dict[str, Any]] = None,
+        body: Any = None,
+    ) -> Any:
+        response = self._http.request(
+            method,
+            path,
+            params={k: _format(v) for k, v in (query or {}).items() if v is not None},
+            headers={k: _format(v) for k, v in (headers or {}).items() if v is not None},
+            json=_encode(body) if body is not None else None,
+        )
+        if not response.is_success:
+            raise _error(response)
+        return response.json() if response.content else None
+
+
+def _format(value: Any) -> str:
+    """Formats a path, query or header value."""
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    if isinstance(value, Enum):
+        return str(value.value)
+    return str(value)
+
+
+def _path(value: Any) -> str:
+    """Formats and escapes a path parameter."""
+    return quote(_format(value), safe="")
+
+
+def _encode(value: Any) -> Any:
+    """Converts models and enums to JSON-compatible values."""
+    if isinstance(value, BaseModel):
+        return value.model_dump(mode="json", by_alias=True)
+    if isinstance(value, Enum):
+        return value.value
+    if isinstance(value, list):
+        return [_encode(v) for v in value]
+    if isinstance(value, dict):
+        return {k: _encode(v) for k, v in value.items()}
+    return value
+
+
+def _error(response: httpx.Response) -> ApiError:
+    """Maps an error response to a declared error by its code, then its status."""
+    code, message = "", response.reason_phrase
+    try:
+        data = response.json()
+    except ValueError:
+        data = None
+    if isinstance(data, dict):
+        code = str(data.get("error") or data.get("code") or "")
+        message = str(data.get("message") or message)
+    error_type = ERROR_CODES.get(code) or ERROR_STATUSES.get(response.status_code) or ApiError
+    return error_type(response.status_code, code, message)
+`)
+	return sb.String()
+}
+
+// pythonMethod renders the client method for one endpoint.
+func (c *clientWriter) pythonMethod(call clientCall) string {
+	var sb strings.Builder
+	params := []string{"self"}
+	groups := make(map[string][]string)
+	body := ""
+	for _, p := range call.Params {
+		v := paramIdent("python", p.Name)
+		typ := mapType(p.Type, "python")
+		if p.Optional {
+			params = append(params, fmt.Sprintf("%s: Optional[%s] = None", v, typ))
+		} else {
+			params = append(params, fmt.Sprintf("%s: %s", v, typ))
+		}
+		switch p.Source {
+		case "query", "header":
+			groups[p.Source] = append(groups[p.Source], fmt.Sprintf("%q: %s", p.Key, v))
+		case "body":
+			body = v
+		}
+	}
+
+	var path []string
+	for _, segment := range strings.Split(call.Route.Endpoint.Path, "/") {
+		if key, ok := pathParamName(segment); ok {
+			segment = fmt.Sprintf("{_path(%s)}", paramIdent("python", paramFor(call, key).Name))
+		}
+		path = append(path, segment)
+	}
+	pathExpr := strconv.Quote(strings.Join(path, "/"))
+	if strings.Contains(pathExpr, "{") {
+		pathExpr = "f" + pathExpr
+	}
+
+	args := []string{strconv.Quote(call.Route.Endpoint.Method), pathExpr}
+	if q := groups["query"]; len(q) > 0 {
+		args = append(args, fmt.Sprintf("query={%s}", strings.Join(q, ", ")))
+	}
+	if h := groups["header"]; len(h) > 0 {
+		args = append(args, fmt.Sprintf("headers={%s}", strings.Join(h, ", ")))
+	}
+	if body != "" {
+		args = append(args, "body="+body)
+	}
+
+	result := "None"
+	if call.Result != "" {
+		result = mapType(call.Result, "python")
+	}
+	sb.WriteString(fmt.Sprintf("    def %s(%s) -> %s:\n", toSnakeCase(call.Route.Name), strings.Join(params, ", "), result))
+	sb.WriteString(fmt.Sprintf("        \"\"\"%s\"\"\"\n", c.summary(call)))
+	request := fmt.Sprintf("self._request(%s)", strings.Join(args, ", "))
+	if call.Result == "" {
+		sb.WriteString(fmt.Sprintf("        %s\n", request))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("        data = %s\n", request))
+	sb.WriteString(fmt.Sprintf("        return %s\n", c.pythonDecode(call.Result, "data")))
+	return sb.String()
+}
+
+// pythonDecode converts decoded JSON to a spec type.
+func (c *clientWriter) pythonDecode(pseudoType, expr string) string {
+	t := strings.TrimSpace(pseudoType)
+	lower := strings.ToLower(t)
+	if strings.HasPrefix(lower, "list[") && strings.HasSuffix(t, "]") {
+		item := c.pythonDecode(t[len("list["):len(t)-1], "item")
+		if item == "item" {
+			return expr
+		}
+		return fmt.Sprintf("[%s for item in %s]", item, expr)
+	}
+	st, ok := c.specType(t)
+	if !ok {
+		return expr
+	}
+	switch st.Kind {
+	case "enum":
+		return fmt.Sprintf("%s(%s)", st.Name, expr)
+	case "struct", "class", "type":
+		return fmt.Sprintf("%s.model_validate(%s)", st.Name, expr)
+	}
+	return expr
+}
+
+// pythonSample returns a Python expression for a sample value.
+func (c *clientWriter) pythonSample(pseudoType string, depth int) string {
+	t := strings.TrimSpace(pseudoType)
+	lower := strings.ToLower(t)
+	if _, optional := optionalInner(t); optional {
+		return "None"
+	}
+	switch {
+	case strings.HasPrefix(lower, "list[") || strings.HasPrefix(lower, "[]"):
+		return "[]"
+	case strings.HasPrefix(lower, "map[") || strings.HasPrefix(lower, "dict["):
+		return "{}"
+	}
+	switch lower {
+	case "string", "str", "uuid":
+		return `"1"`
+	case "int", "integer", "int64":
+		return "1"
+	case "float", "float64":
+		return "1.5"
+	case "bool", "boolean":
+		return "True"
+	}
+	st, ok := c.specType(t)
+	if !ok || depth > 3 {
+		return "None"
+	}
+	switch st.Kind {
+	case "enum":
+		if len(st.Values) > 0 {
+			return st.Name + "." + st.Values[0].Name
+		}
+	case "struct", "class", "type":
+		var args []string
+		for _, f := range st.Fields {
+			if sample, constrained, ok := constrainedSample(f); f.Required && constrained && ok {
+				// JSON strings and numbers are valid Python literals
+				args = append(args, fmt.Sprintf("%s=%s", fieldIdent("python", f.Name), sample))
+			} else if f.Required {
+				args = append(args, fmt.Sprintf("%s=%s", fieldIdent("python", f.Name), c.pythonSample(f.Type, depth+1)))
+			}
+		}
+		return fmt.Sprintf("%s(%s)", st.Name, strings.Join(args, ", "))
+	}
+	return "None"
+}
+
+func (c *clientWriter) pythonClientTest() string {
+	var tests strings.Builder
+	imports := []string{"Client"}
+	for _, call := range c.calls {
+		name := toSnakeCase(call.Route.Name)
+		args := c.sampleArgs(call, func(p clientParam) string { return c.pythonSample(p.Type, 0) }, true)
+		request := call.Route.Endpoint.Method + " " + samplePath(call)
+
+		tests.WriteString(fmt.Sprintf("\n\ndef test_%s():\n", name))
+		tests.WriteString(fmt.Sprintf("    with stub_server(%s, %s) as (url, requests):\n", call.Route.Status, singleQuote(c.sampleJSON(call.Result, "python", 0))))
+		tests.WriteString(fmt.Sprintf("        with Client(url) as client:\n            client.%s(%s)\n", name, args))
+		tests.WriteString(fmt.Sprintf("    assert requests == [%q]\n", request))
+
+		e, declared := testError(call)
+		errorType := "ApiError"
+		body := `{"message":"boom"}`
+		if declared {
+			errorType = errorClass(e.Name, "Error")
+			body = fmt.Sprintf(`{"error":%q,"message":"boom"}`, e.Code)
+		}
+		if !containsString(imports, errorType) {
+			imports = append(imports, errorType)
+		}
+		tests.WriteString(fmt.Sprintf("\n\ndef test_%s_error():\n", name))
+		tests.WriteString(fmt.Sprintf("    with stub_server(%d, %s) as (url, _):\n", e.Status, singleQuote(body)))
+		tests.WriteString(fmt.Sprintf("        with Client(url) as client, pytest.raises(%s):\n            client.%s(%s)\n", errorType, name, args))
+	}
+	sort.Strings(imports)
+
+	var sb strings.Builder
+	sb.WriteString("import threading\n")
+	sb.WriteString("from contextlib import contextmanager\n")
+	sb.WriteString("from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer\n\n")
+	sb.WriteString("import pytest\n\n")
+	sb.WriteString(fmt.Sprintf("from src.client import %s\n", strings.Join(imports, ", ")))
+	for _, group := range c.byModule(c.typeImports(tests.String())) {
+		sb.WriteString(fmt.Sprintf("from %s import %s\n", pythonModulePath(group.Module, "types"), strings.Join(group.Names, ", ")))
+	}
+	sb.WriteString(`
+
+@contextmanager
+def stub_server(status, body):
+    """Runs a local server that answers every request with a canned response."""
+    requests = []
+
+    class Handler(BaseHTTPRequestHandler):
+        def respond(self):
+            requests.append(f"{self.command} {self.path.split('?')[0]}")
+            self.rfile.read(int(self.headers.get("Content-Length") or 0))
+            data = body.encode()
+            self.send_response(status)
+            self.send_header("Content-Type", "application/json")
+            self.send_header("Content-Length", str(len(data)))
+            self.end_headers()
+            self.wfile.write(data)
+
+        do_GET = do_POST = do_PUT = do_PATCH = do_DELETE = respond
+
+        def log_message(self, *args):
+            pass
+
+    server = ThreadingHTTPServer(("127.0.0.1", 0), Handler)
+    threading.Thread(target=server.serve_forever, daemon=True).start()
+    try:
+        yield f"http://127.0.0.1:{server.server_port}", requests
+    finally:
+        server.shutdown()
+        server.server_close()
+`)
+	sb.WriteString(tests.String())
+	return sb.String()
+}
+
+// ============================================================================
+// Java (java.net.http)
+// ============================================================================
+
+func (c *clientWriter) javaClient() string {
+	var methods strings.Builder
+	for _, call := range c.calls {
+		methods.WriteString(c.region("java", call, c.javaMethod(call)))
+		methods.WriteString("\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("package %s;\n\n", toPackageName(c.spec.Name)))
+	sb.WriteString(c.foreignImports("java", methods.String()))
+	sb.WriteString(`import com.fasterxml.jackson.core.type.TypeReference;
+import com.fasterxml.jackson.databind.DeserializationFeature;
+import com.fasterxml.jackson.databind.JsonNode;
+import com.fasterxml.jackson.databind.ObjectMapper;
+import java.io.IOException;
+import java.io.UncheckedIOException;
+import java.net.URI;
+import java.net.URLEncoder;
+import java.net.http.HttpClient;
+import java.net.http.HttpRequest;
+import java.net.http.HttpResponse;
+import java.nio.charset.StandardCharsets;
+import java.util.*;
+
+`)
+	sb.WriteString(fmt.Sprintf("/**\n * Typed HTTP client for the %s API.\n */\n", c.spec.Name))
+	sb.WriteString(`public class ApiClient {
+    /** Thrown for responses outside the 2xx range. */
+    public static class ApiException extends RuntimeException {
+        private final int status;
+        private final String code;
+
+        public ApiException(int status, String code, String message) {
+            super(message);
+            this.status = status;
+            this.code = code;
+        }
+
+        public int getStatus() { return status; }
+        public String getCode() { return code; }
+    }
+
+`)
+	for _, e := range c.catalog {
+		class := errorClass(e.Name, "Exception")
+		if e.Description != "" {
+			sb.WriteString(fmt.Sprintf("    /** Thrown when %s */\n", lowerFirst(e.Description)))
+		}
+		sb.WriteString(fmt.Sprintf("    public static class %s extends ApiException {\n", class))
+		sb.WriteString(fmt.Sprintf("        public %s(int status, String code, String message) { super(status, code, message); }\n", class))
+		sb.WriteString("    }\n\n")
+	}
+
+	sb.WriteString(`    private final String baseUrl;
+    private final HttpClient http = HttpClient.newHttpClient();
+    private final ObjectMapper mapper = new ObjectMapper()
+        .configure(DeserializationFeature.FAIL_ON_UNKNOWN_PROPERTIES, false);
+    private final Map<String, String> headers = new LinkedHashMap<>();
+
+    public ApiClient(String baseUrl) {
+        this.baseUrl = baseUrl.replaceAll("/+$", "");
+    }
+
+    /** Adds a header sent with every request (e.g., Authorization). */
+    public ApiClient withHeader(String name, String value) {
+        headers.put(name, value);
+        return this;
+    }
+
+`)
+	sb.WriteString(methods.String())
+	sb.WriteString(`    private <T> T send(String method, String path, Map<String, Object> query, Map<String, Object> headerValues, Object body, TypeReference<T> type) {
+        try {
+            StringBuilder url = new StringBuilder(baseUrl).append(path);
+            String separator = "?";
+            for (Map.Entry<String, Object> entry : query.entrySet()) {
+                if (entry.getValue() != null) {
+                    url.append(separator).append(encode(entry.getKey())).append('=').append(encode(entry.getValue()));
+                    separator = "&";
+                }
+            }
+
+            HttpRequest.Builder request = HttpRequest.newBuilder(URI.create(url.toString()))
+                .header("Accept", "application/json");
+            headers.forEach(request::header);
+            headerValues.forEach((name, value) -> {
+                if (value != null) {
+                    request.header(name, String.valueOf(value));
+                }
+            });
+            if (body != null) {
+                request.header("Content-Type", "application/json");
+                request.method(method, HttpRequest.BodyPublishers.ofString(mapper.writeValueAsString(body)));
+            } else {
+                request.method(method, HttpRequest.BodyPublishers.noBody());
+            }
+
+            HttpResponse<String> response = http.send(request.build(), HttpResponse.BodyHandlers.ofString());
+            if (response.statusCode() < 200 || response.statusCode() >= 300) {
+                throw error(response.statusCode(), response.body());
+            }
+            if (type == null || response.body().isEmpty()) {
+                return null;
+            }
+            return mapper.readValue(response.body(), type);
+        } catch (IOException e) {
+            throw new UncheckedIOException(e);
+        } catch (InterruptedException e) {
+            Thread.currentThread().interrupt();
+            throw new IllegalStateException(e);
+        }
+    }
+
+    private static String encode(Object value) {
+        return URLEncoder.encode(String.valueOf(value), StandardCharsets.UTF_8).replace("+", "%20");
+    }
+
+    /** Maps an error response to a declared error by its code, then its status. */
+    private ApiException error(int status, String body) {
+        String code = "";
+        String message = "HTTP " + status;
+        try {
+            JsonNode node = mapper.readTree(body);
+            code = node.path("error").asText(node.path("code").asText(""));
+            message = node.path("message").asText(message);
+        } catch (IOException e) {
+            // Not a JSON error body
+        }
+        switch (code) {
+`)
+	for _, e := range c.catalog {
+		sb.WriteString(fmt.Sprintf("            case %q: return new %s(status, code, message);\n", e.Code, errorClass(e.Name, "Exception")))
+	}
+	sb.WriteString("            default: break;\n        }\n        switch (status) {\n")
+	for _, e := range errorsByStatus(c.catalog) {
+		sb.WriteString(fmt.Sprintf("            case %d: return new %s(status, code, message);\n", e.Status, errorClass(e.Name, "Exception")))
+	}
+	sb.WriteString("            default: return new ApiException(status, code, message);\n        }\n    }\n}\n")
+	return sb.String()
+}
+
+// javaParamType returns the Java type of a client parameter; optional
+// parameters are boxed so they can be null.
+func javaParamType(p clientParam) string {
+	typ := mapType(p.Type, "java")
+	if p.Optional {
+		return boxedJavaType(typ)
+	}
+	return typ
+}
+
+// javaMethod renders the client method for one endpoint.
+func (c *clientWriter) javaMethod(call clientCall) string {
+	var sb strings.Builder
+	var params []string
+	groups := make(map[string][]string)
+	body := "null"
+	for _, p := range call.Params {
+		v := paramIdent("java", p.Name)
+		params = append(params, fmt.Sprintf("%s %s", javaParamType(p), v))
+		switch p.Source {
+		case "query", "header":
+			groups[p.Source] = append(groups[p.Source], fmt.Sprintf("%s.put(%q, %s);", p.Source, p.Key, v))
+		case "body":
+			body = v
+		}
+	}
+
+	var path []string
+	for _, segment := range strings.Split(call.Route.Endpoint.Path, "/") {
+		if key, ok := pathParamName(segment); ok {
+			path = append(path, fmt.Sprintf(`" + encode(%s) + "`, paramIdent("java", paramFor(call, key).Name)))
+		} else {
+			path = append(path, segment)
+		}
+	}
+	pathExpr := strings.TrimSuffix(`"`+strings.Join(path, "/")+`"`, ` + ""`)
+
+	result := "void"
+	typeRef := "null"
+	if call.Result != "" {
+		result = boxedJavaType(mapType(call.Result, "java"))
+		typeRef = fmt.Sprintf("new TypeReference<%s>() {}", result)
+	}
+
+	sb.WriteString(fmt.Sprintf("    /** %s */\n", c.summary(call)))
+	sb.WriteString(fmt.Sprintf("    public %s %s(%s) {\n", result, toCamelCase(call.Route.Name), strings.Join(params, ", ")))
+	query, header := "Map.of()", "Map.of()"
+	for _, source := range []string{"query", "header"} {
+		if len(groups[source]) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("        Map<String, Object> %s = new LinkedHashMap<>();\n", source))
+		for _, put := range groups[source] {
+			sb.WriteString("        " + put + "\n")
+		}
+		if source == "query" {
+			query = "query"
+		} else {
+			header = "header"
+		}
+	}
+	send := fmt.Sprintf("send(%q, %s, %s, %s, %s, %s)", call.Route.Endpoint.Method, pathExpr, query, header, body, typeRef)
+	if call.Result == "" {
+		sb.WriteString(fmt.Sprintf("        %s;\n", send))
+	} else {
+		sb.WriteString(fmt.Sprintf("        return %s;\n", send))
+	}
+	sb.WriteString("    }\n")
+	return sb.String()
+}
+
+func (c *clientWriter) javaClientTest() string {
+	var tests strings.Builder
+	for _, call := range c.calls {
+		name := toPascalCase(call.Route.Name)
+		args := c.sampleArgs(call, func(p clientParam) string {
+			if p.Optional {
+				return "null"
+			}
+			if v, ok := scalarSample(p.Type); ok && mapType(p.Type, "java") != "UUID" {
+				return v
+			}
+			if st, ok := c.specType(p.Type); ok && st.Kind != "enum" {
+				return fmt.Sprintf("new %s()", st.Name)
+			}
+			return "null"
+		}, false)
+		invoke := fmt.Sprintf("%s(%s)", toCamelCase(call.Route.Name), args)
+		request := call.Route.Endpoint.Method + " " + samplePath(call)
+
+		tests.WriteString(fmt.Sprintf("    @Test\n    public void test%s() throws IOException {\n", name))
+		tests.WriteString(fmt.Sprintf("        new ApiClient(stub(%s, %s)).%s;\n", call.Route.Status, strconv.Quote(c.sampleJSON(call.Result, "java", 0)), invoke))
+		tests.WriteString(fmt.Sprintf("        assertEquals(List.of(%q), requests);\n    }\n\n", request))
+
+		e, declared := testError(call)
+		errorType := "ApiClient.ApiException"
+		body := `{"message":"boom"}`
+		if declared {
+			errorType = "ApiClient." + errorClass(e.Name, "Exception")
+			body = fmt.Sprintf(`{"error":%q,"message":"boom"}`, e.Code)
+		}
+		tests.WriteString(fmt.Sprintf("    @Test\n    public void test%sError() throws IOException {\n", name))
+		tests.WriteString(fmt.Sprintf("        ApiClient client = new ApiClient(stub(%d, %s));\n", e.Status, strconv.Quote(body)))
+		tests.WriteString(fmt.Sprintf("        assertThrows(%s.class, () -> client.%s);\n    }\n\n", errorType, invoke))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("package %s;\n\n", toPackageName(c.spec.Name)))
+	sb.WriteString(c.foreignImports("java", tests.String()))
+	sb.WriteString(`import com.sun.net.httpserver.HttpServer;
+import java.io.IOException;
+import java.io.OutputStream;
+import java.net.InetSocketAddress;
+import java.nio.charset.StandardCharsets;
+import java.util.ArrayList;
+import java.util.List;
+import org.junit.jupiter.api.AfterEach;
+import org.junit.jupiter.api.Test;
+import static org.junit.jupiter.api.Assertions.*;
+
+public class ApiClientTest {
+    private HttpServer server;
+    private final List<String> requests = new ArrayList<>();
+
+    @AfterEach
+    void stop() {
+        if (server != null) {
+            server.stop(0);
+        }
+    }
+
+    /** Starts a local server that answers every request with a canned response. */
+    private String stub(int status, String body) throws IOException {
+        server = HttpServer.create(new InetSocketAddress("127.0.0.1", 0), 0);
+        server.createContext("/", exchange -> {
+            requests.add(exchange.getRequestMethod() + " " + exchange.getRequestURI().getPath());
+            exchange.getRequestBody().readAllBytes();
+            byte[] data = body.getBytes(StandardCharsets.UTF_8);
+            exchange.getResponseHeaders().set("Content-Type", "application/json");
+            exchange.sendResponseHeaders(status, data.length == 0 ? -1 : data.length);
+            try (OutputStream out = exchange.getResponseBody()) {
+                out.write(data);
+            }
+        });
+        server.start();
+        return "http://127.0.0.1:" + server.getAddress().getPort();
+    }
+
+`)
+	sb.WriteString(strings.TrimSuffix(tests.String(), "\n"))
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// ============================================================================
+// Rust (reqwest)
+// ============================================================================
+
+// rustParamType returns the Rust type of a client parameter; strings are
+// borrowed and bodies passed by reference.
+func rustParamType(p clientParam) string {
+	typ := mapType(p.Type, "rust")
+	if typ == "String" {
+		typ = "&str"
+	} else if p.Source == "body" {
+		typ = "&" + typ
+	}
+	if p.Optional {
+		return fmt.Sprintf("Option<%s>", typ)
+	}
+	return typ
+}
+
+func (c *clientWriter) rustClient() string {
+	var methods strings.Builder
+	for _, call := range c.calls {
+		methods.WriteString(c.region("rust", call, c.rustMethod(call)))
+		methods.WriteString("\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("//! Typed HTTP client for the %s API.\n\n", c.spec.Name))
+	sb.WriteString("use std::fmt;\n\n")
+	sb.WriteString("use reqwest::Method;\n")
+	sb.WriteString("use serde::de::DeserializeOwned;\n")
+	sb.WriteString("use serde::Serialize;\n")
+	if groups := c.byModule(c.typeImports(methods.String())); len(groups) > 0 {
+		sb.WriteString("\n")
+		for _, group := range groups {
+			sb.WriteString(fmt.Sprintf("use %s::*;\n", rustModulePath("crate", group.Module, "types")))
+		}
+	}
+
+	sb.WriteString("\n/// Errors returned by the client.\n#[derive(Debug)]\npub enum ClientError {\n")
+	for _, e := range c.catalog {
+		if e.Description != "" {
+			sb.WriteString(fmt.Sprintf("    /// Returned when %s\n", lowerFirst(e.Description)))
+		}
+		sb.WriteString(fmt.Sprintf("    %s { status: u16, message: String },\n", errorClass(e.Name, "")))
+	}
+	sb.WriteString(`    /// Any other response outside the 2xx range
+    Api { status: u16, code: String, message: String },
+    /// The request could not be sent
+    Http(reqwest::Error),
+    /// The response body could not be decoded
+    Decode(serde_json::Error),
+    /// The base URL is invalid
+    Url(String),
+}
+
+impl fmt::Display for ClientError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+`)
+	for _, e := range c.catalog {
+		variant := errorClass(e.Name, "")
+		sb.WriteString(fmt.Sprintf("            ClientError::%s { status, message } => write!(f, \"{} %s: {}\", status, message),\n", variant, e.Code))
+	}
+	sb.WriteString(`            ClientError::Api { status, code, message } => write!(f, "{} {}: {}", status, code, message),
+            ClientError::Http(err) => write!(f, "request failed: {}", err),
+            ClientError::Decode(err) => write!(f, "invalid response: {}", err),
+            ClientError::Url(url) => write!(f, "invalid base URL: {}", url),
+        }
+    }
+}
+
+impl std::error::Error for ClientError {}
+
+impl From<reqwest::Error> for ClientError {
+    fn from(err: reqwest::Error) -> Self {
+        ClientError::Http(err)
+    }
+}
+
+impl From<serde_json::Error> for ClientError {
+    fn from(err: serde_json::Error) -> Self {
+        ClientError::Decode(err)
+    }
+}
+
+/// Maps an error response to a declared error by its code, then its status.
+fn error_for(status: u16, code: String, message: String) -> ClientError {
+    match code.as_str() {
+`)
+	for _, e := range c.catalog {
+		sb.WriteString(fmt.Sprintf("        %q => return ClientError::%s { status, message },\n", e.Code, errorClass(e.Name, "")))
+	}
+	sb.WriteString("        _ => {}\n    }\n    match status {\n")
+	for _, e := range errorsByStatus(c.catalog) {
+		sb.WriteString(fmt.Sprintf("        %d => ClientError::%s { status, message },\n", e.Status, errorClass(e.Name, "")))
+	}
+	sb.WriteString(`        _ => ClientError::Api { status, code, message },
+    }
+}
+
+/// Calls the API over HTTP.
+#[derive(Debug, Clone)]
+pub struct Client {
+    base_url: String,
+    http: reqwest::Client,
+    headers: Vec<(String, String)>,
+}
+
+impl Client {
+    /// Creates a client for the API at ` + "`base_url`" + `.
+    pub fn new(base_url: impl Into<String>) -> Self {
+        Client {
+            base_url: base_url.into().trim_end_matches('/').to_string(),
+            http: reqwest::Client::new(),
+            headers: Vec::new(),
+        }
+    }
+
+    /// Adds a header sent with every request (e.g., Authorization).
+    pub fn with_header(mut self, name: impl Into<String>, value: impl Into<String>) -> Self {
+        self.headers.push((name.into(), value.into()));
+        self
+    }
+
+`)
+	sb.WriteString(methods.String())
+	sb.WriteString(`    async fn send<T: DeserializeOwned, B: Serialize + ?Sized>(
+        &self,
+        method: Method,
+        segments: &[String],
+        query: &[(&str, String)],
+        headers: &[(&str, String)],
+        body: Option<&B>,
+    ) -> Result<T, ClientError> {
+        let mut url = reqwest::Url::parse(&self.base_url).map_err(|_| ClientError::Url(self.base_url.clone()))?;
+        url.path_segments_mut()
+            .map_err(|_| ClientError::Url(self.base_url.clone()))?
+            .pop_if_empty()
+            .extend(segments);
+
+        let mut request = self.http.request(method, url).query(query);
+        for (name, value) in &self.headers {
+            request = request.header(name.as_str(), value.as_str());
+        }
+        for (name, value) in headers {
+            request = request.header(*name, value.as_str());
+        }
+        if let Some(body) = body {
+            request = request.json(body);
+        }
+
+        let response = request.send().await?;
+        let status = response.status();
+        let text = response.text().await?;
+        if !status.is_success() {
+            let body: serde_json::Value = serde_json::from_str(&text).unwrap_or(serde_json::Value::Null);
+            let code = body
+                .get("error")
+                .or_else(|| body.get("code"))
+                .and_then(|v| v.as_str())
+                .unwrap_or_default()
+                .to_string();
+            let message = body
+                .get("message")
+                .and_then(|v| v.as_str())
+                .map(str::to_string)
+                .unwrap_or_else(|| status.to_string());
+            return Err(error_for(status.as_u16(), code, message));
+        }
+        if text.is_empty() {
+            return Ok(serde_json::from_str("null")?);
+        }
+        Ok(serde_json::from_str(&text)?)
+    }
+}
+`)
+	return sb.String()
+}
+
+// rustMethod renders the client method for one endpoint.
+func (c *clientWriter) rustMethod(call clientCall) string {
+	var sb strings.Builder
+	params := []string{"&self"}
+	groups := make(map[string][]string)
+	body := "None::<&()>"
+	for _, p := range call.Params {
+		v := paramIdent("rust", p.Name)
+		params = append(params, fmt.Sprintf("%s: %s", v, rustParamType(p)))
+		switch p.Source {
+		case "query", "header":
+			if p.Optional {
+				groups[p.Source] = append(groups[p.Source], fmt.Sprintf("if let Some(value) = %s {\n            %s.push((%q, value.to_string()));\n        }", v, p.Source, p.Key))
+			} else {
+				groups[p.Source] = append(groups[p.Source], fmt.Sprintf("%s.push((%q, %s.to_string()));", p.Source, p.Key, v))
+			}
+		case "body":
+			body = fmt.Sprintf("Some(%s)", v)
+		}
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(call.Route.Endpoint.Path, "/") {
+		if segment == "" {
+			continue
+		}
+		if key, ok := pathParamName(segment); ok {
+			segments = append(segments, paramIdent("rust", paramFor(call, key).Name)+".to_string()")
+		} else {
+			segments = append(segments, fmt.Sprintf("%q.to_string()", segment))
+		}
+	}
+
+	result := "()"
+	if call.Result != "" {
+		result = mapType(call.Result, "rust")
+	}
+	sb.WriteString(fmt.Sprintf("    /// %s\n", c.summary(call)))
+	sb.WriteString(fmt.Sprintf("    pub async fn %s(%s) -> Result<%s, ClientError> {\n", toSnakeCase(call.Route.Name), strings.Join(params, ", "), result))
+	query, header := "&[]", "&[]"
+	for _, source := range []string{"query", "header"} {
+		if len(groups[source]) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("        let mut %s: Vec<(&str, String)> = Vec::new();\n", source))
+		for _, push := range groups[source] {
+			sb.WriteString("        " + push + "\n")
+		}
+		if source == "query" {
+			query = "&query"
+		} else {
+			header = "&header"
+		}
+	}
+	sb.WriteString(fmt.Sprintf("        let segments = [%s];\n", strings.Join(segments, ", ")))
+	sb.WriteString(fmt.Sprintf("        self.send(Method::%s, &segments, %s, %s, %s).await\n", call.Route.Endpoint.Method, query, header, body))
+	sb.WriteString("    }\n")
+	return sb.String()
+}
+
+// rustSample returns a Rust expression for a sample value.
+func (c *clientWriter) rustSample(pseudoType string, depth int) string {
+	t := strings.TrimSpace(pseudoType)
+	lower := strings.ToLower(t)
+	if _, optional := optionalInner(t); optional {
+		return "None"
+	}
+	switch {
+	case strings.HasPrefix(lower, "list[") || strings.HasPrefix(lower, "[]"):
+		return "Vec::new()"
+	case strings.HasPrefix(lower, "map[") || strings.HasPrefix(lower, "dict["):
+		return "HashMap::new()"
+	}
+	if v, ok := scalarSample(t); ok && lower != "uuid" {
+		if v == `"1"` {
+			return `"1".to_string()`
+		}
+		return v
+	}
+	st, ok := c.specType(t)
+	if !ok || depth > 3 {
+		return "Default::default()"
+	}
+	switch st.Kind {
+	case "enum":
+		if len(st.Values) > 0 {
+			return st.Name + "::" + valueIdent("rust", st.Values[0].Name)
+		}
+	case "struct", "class", "type":
+		var fields []string
+		for _, f := range st.Fields {
+			value := "None"
+			if f.Required {
+				value = c.rustSample(f.Type, depth+1)
+			}
+			fields = append(fields, fmt.Sprintf("%s: %s", fieldIdent("rust", f.Name), value))
+		}
+		return fmt.Sprintf("%s { %s }", st.Name, strings.Join(fields, ", "))
+	}
+	return "Default::default()"
+}
+
+func (c *clientWriter) rustClientTest() string {
+	var tests strings.Builder
+	for _, call := range c.calls {
+		name := toSnakeCase(call.Route.Name)
+		args := c.sampleArgs(call, func(p clientParam) string {
+			if p.Optional {
+				return "None"
+			}
+			v := c.rustSample(p.Type, 0)
+			if v == `"1".to_string()` {
+				return `"1"`
+			}
+			if p.Source == "body" {
+				return "&" + v
+			}
+			return v
+		}, false)
+		path := samplePath(call)
+
+		tests.WriteString(fmt.Sprintf("#[tokio::test]\nasync fn %s() {\n", name))
+		tests.WriteString(fmt.Sprintf("    let server = stub(%s, %q, %q, %s).await;\n", call.Route.Status, call.Route.Endpoint.Method, path, rawString(c.sampleJSON(call.Result, "rust", 0))))
+		tests.WriteString(fmt.Sprintf("    Client::new(server.uri()).%s(%s).await.unwrap();\n}\n\n", name, args))
+
+		e, declared := testError(call)
+		pattern := fmt.Sprintf("ClientError::Api { status: %d, .. }", e.Status)
+		body := `{"message":"boom"}`
+		if declared {
+			pattern = fmt.Sprintf("ClientError::%s { .. }", errorClass(e.Name, ""))
+			body = fmt.Sprintf(`{"error":%q,"message":"boom"}`, e.Code)
+		}
+		tests.WriteString(fmt.Sprintf("#[tokio::test]\nasync fn %s_error() {\n", name))
+		tests.WriteString(fmt.Sprintf("    let server = stub(%d, %q, %q, %s).await;\n", e.Status, call.Route.Endpoint.Method, path, rawString(body)))
+		tests.WriteString(fmt.Sprintf("    let err = Client::new(server.uri()).%s(%s).await.unwrap_err();\n", name, args))
+		tests.WriteString(fmt.Sprintf("    assert!(matches!(err, %s), \"unexpected error: {}\", err);\n}\n\n", pattern))
+	}
+
+	crate := toPackageName(c.spec.Name)
+	var sb strings.Builder
+	if strings.Contains(tests.String(), "HashMap::") {
+		sb.WriteString("use std::collections::HashMap;\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("use %s::client::{Client, ClientError};\n", crate))
+	for _, group := range c.byModule(c.typeImports(tests.String())) {
+		sb.WriteString(fmt.Sprintf("use %s::*;\n", rustModulePath(crate, group.Module, "types")))
+	}
+	sb.WriteString(`use wiremock::matchers::{method, path};
+use wiremock::{Mock, MockServer, ResponseTemplate};
+
+/// Starts a local server that expects one request and answers it with a
+/// canned response.
+async fn stub(status: u16, verb: &str, route: &str, body: &str) -> MockServer {
+    let server = MockServer::start().await;
+    Mock::given(method(verb))
+        .and(path(route))
+        .respond_with(ResponseTemplate::new(status).set_body_raw(body.to_string(), "application/json"))
+        .expect(1)
+        .mount(&server)
+        .await;
+    server
+}
+
+`)
+	sb.WriteString(strings.TrimSuffix(tests.String(), "\n"))
+	return sb.String()
+}
+
+// rawString quotes a JSON document as a Rust raw string literal.
+func rawString(s string) string {
+	if strings.Contains(s, `"#`) {
+		return strconv.Quote(s)
+	}
+	return `r#"` + s + `"#`
+}
+
+// ============================================================================
+// C# (HttpClient)
+// ============================================================================
+
+func (c *clientWriter) csharpClient() string {
+	var methods strings.Builder
+	for _, call := range c.calls {
+		methods.WriteString(c.region("csharp", call, c.csharpMethod(call)))
+		methods.WriteString("\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`using System;
+using System.Collections.Generic;
+using System.Globalization;
+using System.Net.Http;
+using System.Text;
+using System.Text.Json;
+using System.Threading.Tasks;
+
+`)
+	if imports := c.foreignImports("csharp", methods.String()); imports != "" {
+		sb.WriteString(imports + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("namespace %s\n{\n", toPascalCase(c.spec.Name)))
+	sb.WriteString(`    /// <summary>
+    /// Thrown for responses outside the 2xx range.
+    /// </summary>
+    public class ApiException : Exception
+    {
+        public ApiException(int status, string code, string message) : base(message)
+        {
+            Status = status;
+            Code = code;
+        }
+
+        public int Status { get; }
+
+        public string Code { get; }
+    }
+
+`)
+	sb.WriteString(fmt.Sprintf("    /// <summary>\n    /// Typed HTTP client for the %s API.\n    /// </summary>\n", c.spec.Name))
+	sb.WriteString("    public class ApiClient\n    {\n")
+
+	// Declared errors nest in the client, leaving the namespace's top-level
+	// names to the service's own exceptions
+	for _, e := range c.catalog {
+		class := errorClass(e.Name, "Exception")
+		if e.Description != "" {
+			sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// Thrown when %s\n        /// </summary>\n", lowerFirst(e.Description)))
+		}
+		sb.WriteString(fmt.Sprintf("        public class %s : ApiException\n        {\n", class))
+		sb.WriteString(fmt.Sprintf("            public %s(int status, string code, string message) : base(status, code, message) { }\n", class))
+		sb.WriteString("        }\n\n")
+	}
+
+	sb.WriteString(`        private static readonly JsonSerializerOptions JsonOptions = new JsonSerializerOptions(JsonSerializerDefaults.Web);
+        private readonly string _baseUrl;
+        private readonly HttpClient _http;
+
+        public ApiClient(string baseUrl, HttpClient? http = null)
+        {
+            _baseUrl = baseUrl.TrimEnd('/');
+            _http = http ?? new HttpClient();
+        }
+
+        /// <summary>
+        /// Headers sent with every request (e.g., Authorization).
+        /// </summary>
+        public Dictionary<string, string> Headers { get; } = new Dictionary<string, string>();
+
+`)
+	sb.WriteString(methods.String())
+	sb.WriteString(`        private async Task<T> SendAsync<T>(string method, string path, Dictionary<string, object?>? query, Dictionary<string, object?>? headers, object? body)
+        {
+            var url = new StringBuilder(_baseUrl).Append(path);
+            var separator = '?';
+            foreach (var (name, value) in query ?? new Dictionary<string, object?>())
+            {
+                if (value == null)
+                {
+                    continue;
+                }
+                url.Append(separator).Append(Uri.EscapeDataString(name)).Append('=').Append(Uri.EscapeDataString(Format(value)));
+                separator = '&';
+            }
+
+            using var request = new HttpRequestMessage(new HttpMethod(method), url.ToString());
+            foreach (var (name, value) in Headers)
+            {
+                request.Headers.TryAddWithoutValidation(name, value);
+            }
+            foreach (var (name, value) in headers ?? new Dictionary<string, object?>())
+            {
+                if (value != null)
+                {
+                    request.Headers.TryAddWithoutValidation(name, Format(value));
+                }
+            }
+            if (body != null)
+            {
+                request.Content = new StringContent(JsonSerializer.Serialize(body, JsonOptions), Encoding.UTF8, "application/json");
+            }
+
+            using var response = await _http.SendAsync(request);
+            var text = await response.Content.ReadAsStringAsync();
+            if (!response.IsSuccessStatusCode)
+            {
+                throw Error((int)response.StatusCode, text);
+            }
+            if (string.IsNullOrEmpty(text))
+            {
+                return default!;
+            }
+            return JsonSerializer.Deserialize<T>(text, JsonOptions)!;
+        }
+
+        private static string Format(object value)
+        {
+            return value is bool b ? (b ? "true" : "false") : Convert.ToString(value, CultureInfo.InvariantCulture) ?? "";
+        }
+
+        /// <summary>
+        /// Maps an error response to a declared error by its code, then its status.
+        /// </summary>
+        private static ApiException Error(int status, string text)
+        {
+            var code = "";
+            var message = "HTTP " + status;
+            try
+            {
+                using var document = JsonDocument.Parse(text);
+                var root = document.RootElement;
+                if (root.ValueKind == JsonValueKind.Object)
+                {
+                    if (root.TryGetProperty("error", out var error) || root.TryGetProperty("code", out error))
+                    {
+                        code = error.GetString() ?? "";
+                    }
+                    if (root.TryGetProperty("message", out var detail))
+                    {
+                        message = detail.GetString() ?? message;
+                    }
+                }
+            }
+            catch (JsonException)
+            {
+                // Not a JSON error body
+            }
+
+            return code switch
+            {
+`)
+	for _, e := range c.catalog {
+		sb.WriteString(fmt.Sprintf("                %q => new %s(status, code, message),\n", e.Code, errorClass(e.Name, "Exception")))
+	}
+	sb.WriteString("                _ => status switch\n                {\n")
+	for _, e := range errorsByStatus(c.catalog) {
+		sb.WriteString(fmt.Sprintf("                    %d => new %s(status, code, message),\n", e.Status, errorClass(e.Name, "Exception")))
+	}
+	sb.WriteString("                    _ => new ApiException(status, code, message),\n                },\n            };\n        }\n    }\n}\n")
+	return sb.String()
+}
+
+// csharpMethod renders the client method for one endpoint.
+func (c *clientWriter) csharpMethod(call clientCall) string {
+	var sb strings.Builder
+	var params []string
+	groups := make(map[string][]string)
+	body := "null"
+	for _, p := range call.Params {
+		v := paramIdent("csharp", p.Name)
+		typ := mapType(p.Type, "csharp")
+		if p.Optional {
+			params = append(params, fmt.Sprintf("%s? %s = null", typ, v))
+		} else {
+			params = append(params, fmt.Sprintf("%s %s", typ, v))
+		}
+		switch p.Source {
+		case "query", "header":
+			groups[p.Source] = append(groups[p.Source], fmt.Sprintf("[%q] = %s", p.Key, v))
+		case "body":
+			body = v
+		}
+	}
+
+	var path []string
+	for _, segment := range strings.Split(call.Route.Endpoint.Path, "/") {
+		if key, ok := pathParamName(segment); ok {
+			path = append(path, fmt.Sprintf(`" + Uri.EscapeDataString(Format(%s)) + "`, paramIdent("csharp", paramFor(call, key).Name)))
+		} else {
+			path = append(path, segment)
+		}
+	}
+	pathExpr := strings.TrimSuffix(`"`+strings.Join(path, "/")+`"`, ` + ""`)
+
+	query, header := "null", "null"
+	if q := groups["query"]; len(q) > 0 {
+		query = fmt.Sprintf("new Dictionary<string, object?> { %s }", strings.Join(q, ", "))
+	}
+	if h := groups["header"]; len(h) > 0 {
+		header = fmt.Sprintf("new Dictionary<string, object?> { %s }", strings.Join(h, ", "))
+	}
+
+	name := toPascalCase(call.Route.Name) + "Async"
+	sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// %s\n        /// </summary>\n", c.summary(call)))
+	if call.Result == "" {
+		sb.WriteString(fmt.Sprintf("        public async Task %s(%s)\n        {\n", name, strings.Join(params, ", ")))
+		sb.WriteString(fmt.Sprintf("            await SendAsync<object?>(%q, %s, %s, %s, %s);\n", call.Route.Endpoint.Method, pathExpr, query, header, body))
+	} else {
+		result := mapType(call.Result, "csharp")
+		sb.WriteString(fmt.Sprintf("        public Task<%s> %s(%s)\n        {\n", result, name, strings.Join(params, ", ")))
+		sb.WriteString(fmt.Sprintf("            return SendAsync<%s>(%q, %s, %s, %s, %s);\n", result, call.Route.Endpoint.Method, pathExpr, query, header, body))
+	}
+	sb.WriteString("        }\n")
+	return sb.String()
+}
+
+func (c *clientWriter) csharpClientTest() string {
+	var tests strings.Builder
+	for _, call := range c.calls {
+		name := toPascalCase(call.Route.Name)
+		args := c.sampleArgs(call, func(p clientParam) string {
+			if v, ok := scalarSample(p.Type); ok && mapType(p.Type, "csharp") != "Guid" {
+				return v
+			}
+			if st, ok := c.specType(p.Type); ok && st.Kind != "enum" {
+				return fmt.Sprintf("new %s()", st.Name)
+			}
+			return "default!"
+		}, true)
+		invoke := fmt.Sprintf("%sAsync(%s)", name, args)
+		request := call.Route.Endpoint.Method + " " + samplePath(call)
+
+		tests.WriteString(fmt.Sprintf("        [Fact]\n        public async Task %s()\n        {\n", name))
+		tests.WriteString(fmt.Sprintf("            var (url, request) = Stub(%s, %s);\n", call.Route.Status, strconv.Quote(c.sampleJSON(call.Result, "csharp", 0))))
+		tests.WriteString(fmt.Sprintf("            await new ApiClient(url).%s;\n", invoke))
+		tests.WriteString(fmt.Sprintf("            Assert.Equal(%q, await request);\n        }\n\n", request))
+
+		e, declared := testError(call)
+		errorType := "ApiException"
+		body := `{"message":"boom"}`
+		if declared {
+			errorType = "ApiClient." + errorClass(e.Name, "Exception")
+			body = fmt.Sprintf(`{"error":%q,"message":"boom"}`, e.Code)
+		}
+		tests.WriteString(fmt.Sprintf("        [Fact]\n        public async Task %sError()\n        {\n", name))
+		tests.WriteString(fmt.Sprintf("            var (url, _) = Stub(%d, %s);\n", e.Status, strconv.Quote(body)))
+		tests.WriteString(fmt.Sprintf("            await Assert.ThrowsAsync<%s>(() => new ApiClient(url).%s);\n        }\n\n", errorType, invoke))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`using System.Net;
+using System.Net.Sockets;
+using System.Text;
+using System.Threading.Tasks;
+using Xunit;
+
+`)
+	if imports := c.foreignImports("csharp", tests.String()); imports != "" {
+		sb.WriteString(imports + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("namespace %s.Tests\n{\n", toPascalCase(c.spec.Name)))
+	sb.WriteString(`    public class ApiClientTests
+    {
+        /// <summary>
+        /// Starts a local server that answers one request with a canned response.
+        /// </summary>
+        private static (string Url, Task<string> Request) Stub(int status, string body)
+        {
+            var socket = new TcpListener(IPAddress.Loopback, 0);
+            socket.Start();
+            var port = ((IPEndPoint)socket.LocalEndpoint).Port;
+            socket.Stop();
+
+            var url = $"http://127.0.0.1:{port}/";
+            var listener = new HttpListener();
+            listener.Prefixes.Add(url);
+            listener.Start();
+            var request = Task.Run(async () =>
+            {
+                var context = await listener.GetContextAsync();
+                var received = context.Request.HttpMethod + " " + context.Request.Url!.AbsolutePath;
+                var data = Encoding.UTF8.GetBytes(body);
+                context.Response.StatusCode = status;
+                context.Response.ContentType = "application/json";
+                await context.Response.OutputStream.WriteAsync(data);
+                context.Response.Close();
+                listener.Stop();
+                return received;
+            });
+            return (url.TrimEnd('/'), request);
+        }
+
+`)
+	sb.WriteString(strings.TrimSuffix(tests.String(), "\n"))
+	sb.WriteString("    }\n}\n")
+	return sb.String()
+}
@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// parseTaskAPI parses the task API fixture and declares an error on
+// createTask so clients map both function errors and error responses.
+func parseTaskAPI(t *testing.T) *specparser.SpecAnalysis {
+	t.Helper()
+	spec, err := specparser.NewParser().Parse(taskAPISpec, "tasks.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	for i := range spec.Functions {
+		if spec.Functions[i].Name == "createTask" {
+			spec.Functions[i].Errors = []specparser.SpecError{{Type: "Conflict", Condition: "A task with the same title exists"}}
+		}
+	}
+	return spec
+}
+
+func TestResolveClientCalls(t *testing.T) {
+	calls, catalog := resolveClientCalls(parseTaskAPI(t))
+	if len(calls) != 3 {
+		t.Fatalf("Expected 3 client calls, got %d", len(calls))
+	}
+
+	get := calls[0]
+	var params []string
+	for _, p := range get.Params {
+		params = append(params, p.Source+":"+p.Name)
+	}
+	if strings.Join(params, ",") != "path:id,query:verbose" || !get.Params[1].Optional {
+		t.Errorf("Unexpected getTask parameters: %+v", get.Params)
+	}
+	if get.Result != "NewTask" {
+		t.Errorf("Expected getTask to return NewTask, got %q", get.Result)
+	}
+
+	if post := calls[1]; post.Result != "string" || len(post.Params) != 1 || post.Params[0].Source != "body" {
+		t.Errorf("Unexpected createTask call: %+v", post)
+	}
+
+	tests := []struct {
+		name   string
+		code   string
+		status int
+	}{
+		{"NotFound", "NotFound", 404},
+		{"Conflict", "Conflict", 409},
+	}
+	if len(catalog) != len(tests) {
+		t.Fatalf("Expected %d declared errors, got %+v", len(tests), catalog)
+	}
+	for i, tt := range tests {
+		if e := catalog[i]; e.Name != tt.name || e.Code != tt.code || e.Status != tt.status {
+			t.Errorf("Error %d = %+v, expected %s (%s, %d)", i, e, tt.name, tt.code, tt.status)
+		}
+	}
+}
+
+func TestGenerateClient(t *testing.T) {
+	spec := parseTaskAPI(t)
+
+	tests := []struct {
+		language string
+		path     string
+		expected []string
+	}{
+		{"go", "client/client.go", []string{
+			"func (c *Client) GetTask(ctx context.Context, id string, verbose *bool) (tasks.NewTask, error) {",
+			`err := c.do(ctx, "GET", "/tasks/"+url.PathEscape(fmt.Sprint(id)), query, nil, nil, &result)`,
+			`ErrConflict = errors.New("conflict")`,
+			"409: ErrConflict,",
+			"// rpg:begin client:postTasks",
+		}},
+		{"go", "client/client_test.go", []string{
+			"server := stubServer(t, 404, \"GET\", \"/tasks/1\", `{\"error\":\"NotFound\",\"message\":\"boom\"}`)",
+			"if !errors.Is(err, ErrConflict) {",
+			"NewClient(server.URL).PostTasks(context.Background(), tasks.NewTask{})",
+		}},
+		{"typescript", "src/client.ts", []string{
+			"export class ConflictError extends ApiError {}",
+			"async getTask(id: string, verbose?: boolean | null): Promise<NewTask> {",
+			"{ query: { verbose } }",
+		}},
+		{"typescript", "src/client.test.ts", []string{
+			"rejects.toBeInstanceOf(NotFoundError)",
+			`expect(requests).toEqual(["DELETE /tasks/1"]);`,
+		}},
+		{"python", "src/client.py", []string{
+			"class ConflictError(ApiError):",
+			"def get_task(self, id: str, verbose: Optional[bool] = None) -> NewTask:",
+			"return NewTask.model_validate(data)",
+		}},
+		{"python", "tests/test_client.py", []string{
+			`client.post_tasks(NewTask(title="1"))`,
+			"pytest.raises(ConflictError)",
+		}},
+		{"java", "src/main/java/tasks/ApiClient.java", []string{
+			"public static class NotFoundException extends ApiException {",
+			`return send("GET", "/tasks/" + encode(id), query, Map.of(), null, new TypeReference<NewTask>() {});`,
+		}},
+		{"java", "src/test/java/tasks/ApiClientTest.java", []string{
+			"assertThrows(ApiClient.ConflictException.class, () -> client.postTasks(new NewTask()));",
+		}},
+		{"rust", "src/client.rs", []string{
+			"Conflict { status: u16, message: String },",
+			"pub async fn get_task(&self, id: &str, verbose: Option<bool>) -> Result<NewTask, ClientError> {",
+			"409 => ClientError::Conflict { status, message },",
+		}},
+		{"rust", "tests/client.rs", []string{
+			`post_tasks(&NewTask { title: "1".to_string() })`,
+			`let server = stub(404, "GET", "/tasks/1", r#"{"error":"NotFound","message":"boom"}"#).await;`,
+		}},
+		{"csharp", "src/ApiClient.cs", []string{
+			"public Task<NewTask> GetTaskAsync(string id, bool? verbose = null)",
+			`"Conflict" => new ConflictException(status, code, message),`,
+		}},
+		{"csharp", "tests/ApiClientTests.cs", []string{
+			"await Assert.ThrowsAsync<ApiClient.NotFoundException>(() => new ApiClient(url).GetTaskAsync(\"1\"));",
+		}},
+		{"rust", "src/lib.rs", []string{"pub mod client;"}},
+		{"rust", "Cargo.toml", []string{`reqwest = { version = "0.12", features = ["json"] }`, `wiremock = "0.6"`}},
+		{"python", "pyproject.toml", []string{`"httpx>=0.27"`}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language+"/"+tt.path, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), map[string][]string{tt.path: tt.expected})
+		})
+	}
+}
+
+func TestClientBuilds(t *testing.T) {
+	checkBuilds(t, specparser.Render(parseTaskAPI(t)), "go", "python")
+}
@@ -0,0 +1,1555 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// command is a spec command resolved against the spec's functions.
+type command struct {
+	Command specparser.SpecCommand
+
+	// Path is the command's words from the root (e.g., ["db", "migrate"])
+	Path []string
+
+	// Children are the paths of a group's subcommands
+	Children [][]string
+
+	// Inputs are the positional arguments in order, then the flags
+	Inputs []commandInput
+
+	// Function is the spec function the command calls, nil when none
+	// matches or the command groups subcommands
+	Function *specparser.SpecFunction
+
+	// Args binds each function parameter to an input; nil when some
+	// parameter has no matching input, leaving the command a stub
+	Args []commandArg
+
+	// ExitCode is the code the command exits with when its function fails:
+	// the first non-zero exit code the spec declares, or 1
+	ExitCode int
+
+	// Service is how Java, Rust and C# commands refer to the service holding
+	// Function, qualified when it is declared in another module
+	Service string
+}
+
+// commandInput is a positional argument or flag of a command.
+type commandInput struct {
+	Flag        bool
+	Name        string
+	Short       string
+	Description string
+
+	// Scalar is the canonical primitive the input parses to; inputs of
+	// types a command line cannot carry are parsed as strings
+	Scalar string
+
+	// List inputs take the remaining arguments, or repeat as flags
+	List bool
+
+	// Carried is false for inputs parsed as strings in place of their type
+	Carried bool
+
+	Required bool
+	Default  string
+}
+
+// commandArg binds a function parameter to a command input.
+type commandArg struct {
+	Input    int    // index of the input in the command's Inputs
+	Param    string // name of the function parameter
+	Optional bool   // whether the parameter is Optional
+}
+
+// bound reports whether the command can call its function.
+func (c command) bound() bool {
+	return c.Function != nil && c.Args != nil
+}
+
+// group reports whether the command only groups its subcommands.
+func (c command) group() bool {
+	return len(c.Children) > 0
+}
+
+// name is the command's path joined by slashes, which identifies its
+// region in generated files.
+func (c command) name() string {
+	return strings.Join(c.Path, "/")
+}
+
+// ident is the command's path as a PascalCase identifier.
+func (c command) ident() string {
+	return toPascalCase(strings.Join(c.Path, " "))
+}
+
+// positionals returns the command's positional arguments.
+func (c command) positionals() []commandInput {
+	var args []commandInput
+	for _, in := range c.Inputs {
+		if !in.Flag {
+			args = append(args, in)
+		}
+	}
+	return args
+}
+
+// boundTo returns the argument an input is bound to, if any.
+func (c command) boundTo(input int) (commandArg, bool) {
+	for _, a := range c.Args {
+		if a.Input == input {
+			return a, true
+		}
+	}
+	return commandArg{}, false
+}
+
+// absent reports whether an input can be left out without a value standing
+// in for it: an optional argument or valued flag with no default.
+func (in commandInput) absent() bool {
+	return !in.Required && in.Default == "" && !in.List && !(in.Flag && in.Scalar == typeexpr.Bool)
+}
+
+// pseudoType returns the type the input parses to.
+func (in commandInput) pseudoType() string {
+	if in.List {
+		return "List[" + in.Scalar + "]"
+	}
+	return in.Scalar
+}
+
+// literal renders the input's default value, or the zero value of its
+// type when it has none.
+func (in commandInput) literal(langID string) string {
+	if lit, ok := defaultLiteral(langID, specparser.SpecField{Type: in.Scalar, Default: in.Default}); ok {
+		return lit
+	}
+	return defaultValue(in.Scalar, langID)
+}
+
+// cliValue classifies a pseudo-type for the command line: the primitive it
+// holds, whether it is Optional and whether it is a list of them. ok is
+// false for types a command line cannot carry.
+func cliValue(pseudoType string) (scalar string, optional, list, ok bool) {
+	e, err := typeexpr.Parse(pseudoType)
+	if err != nil {
+		return "", false, false, false
+	}
+	if e.Kind == typeexpr.Optional {
+		optional, e = true, e.Args[0]
+	}
+	if e.Kind == typeexpr.List {
+		list, e = true, e.Args[0]
+	}
+	if e.Kind != typeexpr.Primitive {
+		return "", false, false, false
+	}
+	switch e.Name {
+	case typeexpr.String, typeexpr.Int, typeexpr.Int64, typeexpr.Float, typeexpr.Bool:
+		return e.Name, optional, list, true
+	}
+	return "", false, false, false
+}
+
+// newCommandInput resolves a declared argument or flag. Positional
+// booleans are parsed as strings, as are types a command line cannot
+// carry, and defaults that do not fit the type are dropped.
+func newCommandInput(flag bool, name, short, typ, def, description string, required bool) commandInput {
+	in := commandInput{Flag: flag, Name: name, Short: short, Description: description, Required: required}
+	scalar, optional, list, ok := cliValue(typ)
+	if ok && !flag && scalar == typeexpr.Bool && !list {
+		ok = false
+	}
+	if !ok {
+		scalar, list = typeexpr.String, false
+	}
+	in.Scalar, in.List, in.Carried = scalar, list, ok
+	in.Required = in.Required && !optional
+	if _, valid := defaultLiteral("go", specparser.SpecField{Type: scalar, Default: def}); valid && !list {
+		in.Default = def
+	}
+	return in
+}
+
+// resolveCommands resolves each command's inputs, function and argument
+// bindings, keeping the spec's order, which lists parents first. Functions are
+// matched by the command's Function, then by its path and then by its
+// name, ignoring case and separators.
+func resolveCommands(spec *specparser.SpecAnalysis) []command {
+	functions := make(map[string]*specparser.SpecFunction)
+	for i := range spec.Functions {
+		functions[normalizeName(spec.Functions[i].Name)] = &spec.Functions[i]
+	}
+
+	var commands []command
+	for _, sc := range spec.Commands {
+		c := command{Command: sc, Path: strings.Fields(sc.Path()), ExitCode: 1}
+		for _, sub := range spec.Commands {
+			if sub.Parent == sc.Path() {
+				c.Children = append(c.Children, strings.Fields(sub.Path()))
+			}
+		}
+		for _, e := range sc.ExitCodes {
+			if e.Code != 0 {
+				c.ExitCode = e.Code
+				break
+			}
+		}
+
+		if !c.group() {
+			for _, a := range sc.Args {
+				c.Inputs = append(c.Inputs, newCommandInput(false, a.Name, "", a.Type, a.Default, a.Description, a.Required))
+			}
+			for _, f := range sc.Flags {
+				c.Inputs = append(c.Inputs, newCommandInput(true, f.Name, f.Short, f.Type, f.Default, f.Description, f.Required))
+			}
+			for _, candidate := range []string{sc.Function, strings.Join(c.Path, ""), sc.Name} {
+				if f, ok := functions[normalizeName(candidate)]; ok && candidate != "" {
+					c.Function = f
+					break
+				}
+			}
+			if c.Function != nil {
+				c.Args = bindCommandArgs(c, c.Function)
+			}
+		}
+
+		commands = append(commands, c)
+	}
+	return commands
+}
+
+// bindCommandArgs matches function parameters to the command's arguments
+// and flags by name. Each parameter needs an input of its type, though
+// either may be Optional. It returns nil when a parameter cannot be bound.
+func bindCommandArgs(c command, f *specparser.SpecFunction) []commandArg {
+	args := []commandArg{}
+	for _, p := range f.Parameters {
+		scalar, optional, list, ok := cliValue(p.Type)
+		if !ok {
+			return nil
+		}
+		input := -1
+		for i, in := range c.Inputs {
+			if input < 0 && normalizeName(in.Name) == normalizeName(p.Name) {
+				input = i
+			}
+		}
+		if input < 0 {
+			return nil
+		}
+		in := c.Inputs[input]
+		if !in.Carried || in.Scalar != scalar || in.List != list {
+			return nil
+		}
+		args = append(args, commandArg{Input: input, Param: p.Name, Optional: optional})
+	}
+	return args
+}
+
+// usesAsync reports whether any function is async.
+func usesAsync(functions []specparser.SpecFunction) bool {
+	for _, f := range functions {
+		if f.IsAsync {
+			return true
+		}
+	}
+	return false
+}
+
+// commandRoots returns the paths of the top-level commands.
+func commandRoots(commands []command) [][]string {
+	var roots [][]string
+	for _, c := range commands {
+		if len(c.Path) == 1 {
+			roots = append(roots, c.Path)
+		}
+	}
+	return roots
+}
+
+// generateCommands generates the spec's command line with the language's
+// usual framework: cobra for Go, commander for TypeScript, argparse for
+// Python, picocli for Java, clap for Rust and System.CommandLine for C#.
+// Each command calls its spec function with the arguments and flags of
+// the same names, prints the result and exits with the command's error
+// code when the function fails; a command that cannot be bound is left a
+// stub.
+func (g *Generator) generateCommands(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	if len(spec.Commands) == 0 {
+		return nil
+	}
+
+	lang := adapter.GetLanguage()
+	program := toPackageName(spec.Name)
+	commands := resolveCommands(spec)
+	for i, c := range commands {
+		if c.Function == nil {
+			continue
+		}
+		commands[i].Service = serviceRef(spec, lang.ID, c.Function.Module)
+		// Other classes and crates only reach public functions
+		if !c.Function.IsPublic && (lang.ID == "java" || lang.ID == "rust" || lang.ID == "csharp") {
+			commands[i].Args = nil
+		}
+	}
+
+	var body strings.Builder
+	for _, c := range commands {
+		var code string
+		switch lang.ID {
+		case "go":
+			code = generateGoCommand(c, program)
+		case "typescript":
+			code = generateTypeScriptCommand(c, program)
+		case "python":
+			code = generatePythonCommand(c, program)
+		case "java":
+			code = generateJavaCommand(c, program)
+		case "rust":
+			code = generateRustCommand(c, program, commands)
+		case "csharp":
+			code = generateCSharpCommand(c, program)
+		}
+		body.WriteString("\n")
+		body.WriteString(wrapRegion(lang.ID, "command", c.name(), hashOf([]any{c.Command, c.Children}), code))
+	}
+	code := body.String()
+
+	var elements []string
+	for _, c := range commands {
+		elements = append(elements, c.name())
+	}
+
+	var files []GeneratedFile
+	var content strings.Builder
+	var path string
+	switch lang.ID {
+	case "go":
+		path = "cli.go"
+		imports := []string{}
+		for _, imp := range []struct{ pkg, use string }{
+			{"encoding/json", "printJSON("},
+			{"errors", "errors."},
+			{"fmt", "fmt."},
+			{"io", "printJSON("},
+			{"strconv", "strconv."},
+		} {
+			if strings.Contains(code, imp.use) {
+				imports = append(imports, imp.pkg)
+			}
+		}
+		content.WriteString(fmt.Sprintf("package %s\n\nimport (\n", toPackageName(spec.Name)))
+		for _, imp := range imports {
+			content.WriteString(fmt.Sprintf("\t%q\n", imp))
+		}
+		content.WriteString("\n\t\"github.com/spf13/cobra\"\n)\n\n")
+		content.WriteString("// ExitError is a command's failure with the exit code it reports.\n")
+		content.WriteString("type ExitError struct {\n\tCode int\n\tErr  error\n}\n\n")
+		content.WriteString("func (e *ExitError) Error() string { return e.Err.Error() }\n\n")
+		content.WriteString("func (e *ExitError) Unwrap() error { return e.Err }\n\n")
+		content.WriteString(fmt.Sprintf("// NewRootCommand builds the %s command line.\n", program))
+		content.WriteString("func NewRootCommand() *cobra.Command {\n\troot := &cobra.Command{\n")
+		content.WriteString(fmt.Sprintf("\t\tUse:          %q,\n", program))
+		if summary := commandSummary(spec.Overview); summary != "" {
+			content.WriteString(fmt.Sprintf("\t\tShort:        %q,\n", summary))
+		}
+		content.WriteString("\t\tSilenceUsage: true,\n\t}\n")
+		for _, root := range commandRoots(commands) {
+			content.WriteString(fmt.Sprintf("\troot.AddCommand(new%sCommand())\n", toPascalCase(strings.Join(root, " "))))
+		}
+		content.WriteString("\treturn root\n}\n")
+		content.WriteString(code)
+		if strings.Contains(code, "printJSON(") {
+			content.WriteString("\n// printJSON writes v to w as indented JSON.\n")
+			content.WriteString("func printJSON(w io.Writer, v any) error {\n")
+			content.WriteString("\tenc := json.NewEncoder(w)\n\tenc.SetIndent(\"\", \"  \")\n\treturn enc.Encode(v)\n}\n")
+		}
+
+		var foreign []moduleNames
+		for _, group := range commandFunctionImports(commands, lang.ID) {
+			if group.Module != "" {
+				foreign = append(foreign, group)
+			}
+		}
+		files = append(files, GeneratedFile{
+			Path:     path,
+			Content:  qualifyGo(spec, content.String(), foreign),
+			Category: "command",
+			Elements: elements,
+		})
+
+		pkg := toPackageName(spec.Name)
+		files = append(files, GeneratedFile{
+			Path: fmt.Sprintf("cmd/%s/main.go", program),
+			Content: fmt.Sprintf(`// Command %s runs the %s command line.
+package main
+
+import (
+	"errors"
+	"os"
+
+	%q
+)
+
+func main() {
+	if err := %s.NewRootCommand().Execute(); err != nil {
+		var exitErr *%s.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		os.Exit(1)
+	}
+}
+`, program, program, goImportPath(spec, ""), pkg, pkg),
+			Category: "command",
+		})
+		return files
+
+	case "typescript":
+		path = "src/cli.ts"
+		content.WriteString("import { pathToFileURL } from \"node:url\";\n\n")
+		content.WriteString("import { Command, InvalidArgumentError, type OptionValues } from \"commander\";\n")
+		for _, group := range commandFunctionImports(commands, lang.ID) {
+			content.WriteString(fmt.Sprintf("import { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath(path, group.Module, "service")))
+		}
+		content.WriteString(fmt.Sprintf("\n/**\n * Builds the %s command line.\n */\nexport function buildProgram(): Command {\n", program))
+		content.WriteString(fmt.Sprintf("  const program = new Command(%q)", program))
+		if summary := commandSummary(spec.Overview); summary != "" {
+			content.WriteString(fmt.Sprintf(".description(%q)", summary))
+		}
+		content.WriteString(";\n")
+		for _, root := range commandRoots(commands) {
+			content.WriteString(fmt.Sprintf("  add%sCommand(program);\n", toPascalCase(strings.Join(root, " "))))
+		}
+		content.WriteString("  return program;\n}\n")
+		content.WriteString(code)
+		if strings.Contains(code, "parseInteger") {
+			content.WriteString("\n/**\n * Parses an integer argument.\n */\nfunction parseInteger(value: string): number {\n")
+			content.WriteString("  const parsed = Number(value);\n  if (!Number.isInteger(parsed)) {\n    throw new InvalidArgumentError(\"Not an integer.\");\n  }\n  return parsed;\n}\n")
+		}
+		if strings.Contains(code, "parseNumber") {
+			content.WriteString("\n/**\n * Parses a number argument.\n */\nfunction parseNumber(value: string): number {\n")
+			content.WriteString("  const parsed = Number(value);\n  if (Number.isNaN(parsed)) {\n    throw new InvalidArgumentError(\"Not a number.\");\n  }\n  return parsed;\n}\n")
+		}
+		if strings.Contains(code, "collect(") {
+			content.WriteString("\n/**\n * Returns a parser collecting the values of a variadic argument or\n * repeated flag.\n */\n")
+			content.WriteString("function collect<T>(parse: (value: string) => T): (value: string, previous?: T[]) => T[] {\n")
+			content.WriteString("  return (value, previous = []) => [...previous, parse(value)];\n}\n")
+		}
+		content.WriteString("\n// Run the command line when this module is the entry point\n")
+		content.WriteString("if (process.argv[1] && import.meta.url === pathToFileURL(process.argv[1]).href) {\n")
+		content.WriteString("  void buildProgram().parseAsync(process.argv);\n}\n")
+
+	case "python":
+		path = "src/cli.py"
+		content.WriteString(fmt.Sprintf("\"\"\"The %s command line.\"\"\"\n\n", program))
+		content.WriteString("import argparse\n")
+		if strings.Contains(code, "asyncio.") {
+			content.WriteString("import asyncio\n")
+		}
+		if strings.Contains(code, "_print_json(") {
+			content.WriteString("import json\n")
+		}
+		content.WriteString("import sys\nfrom typing import Any, List, Optional\n\n")
+		for _, group := range commandFunctionImports(commands, lang.ID) {
+			content.WriteString(fmt.Sprintf("from %s import %s\n", pythonRelativePath(group.Module, "service"), strings.Join(group.Names, ", ")))
+		}
+		content.WriteString("\n\ndef build_parser() -> argparse.ArgumentParser:\n")
+		content.WriteString(fmt.Sprintf("    \"\"\"Builds the %s command line.\"\"\"\n", program))
+		content.WriteString(fmt.Sprintf("    parser = argparse.ArgumentParser(prog=%q", program))
+		if summary := commandSummary(spec.Overview); summary != "" {
+			content.WriteString(fmt.Sprintf(", description=%q", summary))
+		}
+		content.WriteString(")\n")
+		content.WriteString("    commands = parser.add_subparsers(dest=\"command\", metavar=\"command\", required=True)\n")
+		for _, root := range commandRoots(commands) {
+			content.WriteString(fmt.Sprintf("    add_%s(commands)\n", toSnakeCase(strings.Join(root, " "))))
+		}
+		content.WriteString("    return parser\n\n\n")
+		content.WriteString("def main(argv: Optional[List[str]] = None) -> int:\n")
+		content.WriteString("    \"\"\"Runs the command line and returns its exit code.\"\"\"\n")
+		content.WriteString("    args = build_parser().parse_args(argv)\n    return args.handler(args)\n")
+		content.WriteString(strings.ReplaceAll(code, "\n# rpg:begin", "\n\n# rpg:begin"))
+		if strings.Contains(code, "_print_json(") {
+			content.WriteString("\n\ndef _print_json(value: Any) -> None:\n")
+			content.WriteString("    \"\"\"Prints a value as JSON; pydantic models print as their wire format.\"\"\"\n")
+			content.WriteString("    print(json.dumps(value, indent=2, default=_jsonable))\n\n\n")
+			content.WriteString("def _jsonable(value: Any) -> Any:\n")
+			content.WriteString("    \"\"\"Converts a value json cannot encode, such as a pydantic model.\"\"\"\n")
+			content.WriteString("    if hasattr(value, \"model_dump\"):\n")
+			content.WriteString("        return value.model_dump(mode=\"json\", by_alias=True)\n")
+			content.WriteString("    return str(value)\n")
+		}
+		content.WriteString("\n\nif __name__ == \"__main__\":\n    sys.exit(main())\n")
+
+	case "java":
+		pkg := toPackageName(spec.Name)
+		path = fmt.Sprintf("src/main/java/%s/Cli.java", pkg)
+		content.WriteString(fmt.Sprintf("package %s;\n\n", pkg))
+		if strings.Contains(code, "List<") {
+			content.WriteString("import java.util.List;\n")
+		}
+		content.WriteString("import java.util.concurrent.Callable;\n\n")
+		if strings.Contains(code, "JSON.") {
+			content.WriteString("import com.fasterxml.jackson.databind.ObjectMapper;\n")
+		}
+		content.WriteString("import picocli.CommandLine;\nimport picocli.CommandLine.Command;\n")
+		content.WriteString("import picocli.CommandLine.Model.CommandSpec;\n")
+		if strings.Contains(code, "@Option(") {
+			content.WriteString("import picocli.CommandLine.Option;\n")
+		}
+		content.WriteString("import picocli.CommandLine.ParameterException;\n")
+		if strings.Contains(code, "@Parameters(") {
+			content.WriteString("import picocli.CommandLine.Parameters;\n")
+		}
+		content.WriteString("import picocli.CommandLine.Spec;\n\n")
+
+		var roots []string
+		for _, root := range commandRoots(commands) {
+			roots = append(roots, toPascalCase(strings.Join(root, " "))+"Command.class")
+		}
+		content.WriteString(fmt.Sprintf("/**\n * The %s command line.\n */\n", program))
+		content.WriteString(fmt.Sprintf("@Command(name = %q, mixinStandardHelpOptions = true", program))
+		if summary := commandSummary(spec.Overview); summary != "" {
+			content.WriteString(fmt.Sprintf(", description = %s", javaDescription(summary)))
+		}
+		content.WriteString(fmt.Sprintf(",\n        subcommands = {%s})\n", strings.Join(roots, ", ")))
+		content.WriteString("public class Cli implements Runnable {\n")
+		if strings.Contains(code, "JSON.") {
+			content.WriteString("    private static final ObjectMapper JSON = new ObjectMapper();\n\n")
+		}
+		content.WriteString("    @Spec\n    CommandSpec spec;\n\n")
+		content.WriteString("    public static void main(String[] args) {\n        System.exit(new CommandLine(new Cli()).execute(args));\n    }\n\n")
+		content.WriteString("    @Override\n    public void run() {\n")
+		content.WriteString("        throw new ParameterException(spec.commandLine(), \"Missing required subcommand\");\n    }\n")
+		content.WriteString(code)
+		content.WriteString("}\n")
+
+	case "rust":
+		path = "src/main.rs"
+		content.WriteString(fmt.Sprintf("//! The %s command line.\n\n", program))
+		content.WriteString("use std::process::ExitCode;\n\n")
+		var derives []string
+		if strings.Contains(code, "derive(Args)") {
+			derives = append(derives, "Args")
+		}
+		derives = append(derives, "Parser")
+		if strings.Contains(code, "derive(Subcommand)") || len(commandRoots(commands)) > 0 {
+			derives = append(derives, "Subcommand")
+		}
+		content.WriteString(fmt.Sprintf("use clap::{%s};\n\n", strings.Join(derives, ", ")))
+		if summary := commandSummary(spec.Overview); summary != "" {
+			content.WriteString(fmt.Sprintf("/// %s\n", summary))
+		}
+		content.WriteString(fmt.Sprintf("#[derive(Parser)]\n#[command(name = %q, version)]\nstruct Cli {\n", program))
+		content.WriteString("    #[command(subcommand)]\n    command: Commands,\n}\n\n")
+		content.WriteString(rustCommandEnum("Commands", commands, commandRoots(commands)))
+		content.WriteString("\nfn main() -> ExitCode {\n    Cli::parse().command.run()\n}\n")
+		content.WriteString(code)
+
+	case "csharp":
+		path = "src/Cli.cs"
+		content.WriteString("using System.CommandLine;\n")
+		if strings.Contains(code, "JsonSerializer.") {
+			content.WriteString("using System.Text.Json;\n")
+		}
+		content.WriteString(fmt.Sprintf("\nnamespace %s\n{\n", toPascalCase(spec.Name)))
+		content.WriteString(fmt.Sprintf("    /// <summary>\n    /// The %s command line.\n    /// </summary>\n", program))
+		content.WriteString("    public static class Cli\n    {\n")
+		if strings.Contains(code, "JsonSerializer.") {
+			content.WriteString("        private static readonly JsonSerializerOptions JsonOptions = new() { WriteIndented = true };\n\n")
+		}
+		content.WriteString("        public static int Main(string[] args)\n        {\n            return BuildRootCommand().Invoke(args);\n        }\n\n")
+		content.WriteString(fmt.Sprintf("        /// <summary>\n        /// Builds the %s command line.\n        /// </summary>\n", program))
+		content.WriteString("        public static RootCommand BuildRootCommand()\n        {\n")
+		content.WriteString(fmt.Sprintf("            var root = new RootCommand(%q);\n", commandSummary(spec.Overview)))
+		for _, root := range commandRoots(commands) {
+			content.WriteString(fmt.Sprintf("            root.AddCommand(%sCommand());\n", toPascalCase(strings.Join(root, " "))))
+		}
+		content.WriteString("            return root;\n        }\n")
+		content.WriteString(code)
+		content.WriteString("    }\n}\n")
+
+	default:
+		return nil
+	}
+
+	return []GeneratedFile{{
+		Path:     path,
+		Content:  content.String(),
+		Category: "command",
+		Elements: elements,
+	}}
+}
+
+// commandSummary returns the first line of a description.
+func commandSummary(description string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	return strings.TrimSpace(line)
+}
+
+// commandHelp returns the command's description followed by its exit
+// codes, for the long help text.
+func commandHelp(c command) string {
+	help := c.Command.Description
+	if len(c.Command.ExitCodes) == 0 {
+		return help
+	}
+	var sb strings.Builder
+	sb.WriteString("Exit codes:")
+	for _, e := range c.Command.ExitCodes {
+		sb.WriteString(fmt.Sprintf("\n  %d  %s", e.Code, e.Description))
+	}
+	if help == "" {
+		return sb.String()
+	}
+	return help + "\n\n" + sb.String()
+}
+
+// commandUsage returns the command's name followed by its positional
+// arguments, such as "shorten <url> [alias]" or "tag <names>...".
+func commandUsage(c command) string {
+	words := []string{c.Command.Name}
+	for _, in := range c.positionals() {
+		word := "<" + in.Name + ">"
+		if !in.Required || in.Default != "" {
+			word = "[" + in.Name + "]"
+		}
+		if in.List {
+			word += "..."
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// commandStubComment explains why a command is left unimplemented.
+func commandStubComment(c command, indent, prefix string) string {
+	switch {
+	case c.Function == nil:
+		return fmt.Sprintf("%s%s TODO: Implement (no spec function matches %s)\n", indent, prefix, strings.Join(c.Path, " "))
+	case c.Args != nil:
+		return fmt.Sprintf("%s%s TODO: Implement (%s is not public)\n", indent, prefix, c.Function.Name)
+	}
+	return fmt.Sprintf("%s%s TODO: Implement (bind arguments and flags to %s)\n", indent, prefix, c.Function.Name)
+}
+
+// commandFunctionImports returns the distinct functions called by bound
+// commands, as the language's identifiers grouped by the module declaring
+// them.
+func commandFunctionImports(commands []command, langID string) []moduleNames {
+	seen := make(map[string]bool)
+	var names []string
+	modules := make(map[string]string)
+	for _, c := range commands {
+		if c.bound() && !seen[c.Function.Name] {
+			seen[c.Function.Name] = true
+			ident := moduleFuncIdent(langID, *c.Function)
+			names = append(names, ident)
+			modules[ident] = c.Function.Module
+		}
+	}
+	return byModule(names, func(name string) string { return modules[name] })
+}
+
+// commandReturns reports whether a function returns a value and whether
+// it can fail, mirroring the signatures generateGoFunction produces.
+func commandReturns(f *specparser.SpecFunction) (hasResult, hasErr bool) {
+	hasResult = len(f.Returns) > 0 && !containsError([]string{f.Returns[0].Type})
+	hasErr = len(f.Errors) > 0
+	for _, ret := range f.Returns {
+		hasErr = hasErr || containsError([]string{ret.Type})
+	}
+	return hasResult, hasErr
+}
+
+// generateGoCommand generates a cobra command constructor. Flags bind to
+// variables declared before the command; positional arguments are parsed
+// when it runs.
+func generateGoCommand(c command, program string) string {
+	var sb strings.Builder
+	name := "new" + c.ident() + "Command"
+	sb.WriteString(fmt.Sprintf("// %s builds \"%s %s\".\n", name, program, strings.Join(c.Path, " ")))
+	sb.WriteString(fmt.Sprintf("func %s() *cobra.Command {\n", name))
+
+	if c.group() {
+		sb.WriteString(fmt.Sprintf("\tcmd := &cobra.Command{\n\t\tUse: %q,\n", c.Command.Name))
+		if summary := commandSummary(c.Command.Description); summary != "" {
+			sb.WriteString(fmt.Sprintf("\t\tShort: %q,\n", summary))
+		}
+		sb.WriteString("\t}\n")
+		for _, child := range c.Children {
+			sb.WriteString(fmt.Sprintf("\tcmd.AddCommand(new%sCommand())\n", toPascalCase(strings.Join(child, " "))))
+		}
+		sb.WriteString("\treturn cmd\n}\n")
+		return sb.String()
+	}
+
+	for _, in := range c.Inputs {
+		if in.Flag {
+			sb.WriteString(fmt.Sprintf("\tvar %s %s\n", commandVar("go", in), mapType(in.pseudoType(), "go")))
+		}
+	}
+
+	// Positional arguments: the required ones, then optional ones or a list
+	required, total, list := 0, 0, false
+	for _, in := range c.positionals() {
+		total++
+		if in.List {
+			list = true
+		} else if in.Required && in.Default == "" {
+			required++
+		}
+	}
+	validator := fmt.Sprintf("cobra.RangeArgs(%d, %d)", required, total)
+	switch {
+	case list:
+		validator = fmt.Sprintf("cobra.MinimumNArgs(%d)", required)
+		if c.positionals()[total-1].Required {
+			validator = fmt.Sprintf("cobra.MinimumNArgs(%d)", required+1)
+		}
+	case required == total:
+		validator = fmt.Sprintf("cobra.ExactArgs(%d)", total)
+	}
+
+	sb.WriteString(fmt.Sprintf("\tcmd := &cobra.Command{\n\t\tUse: %q,\n", commandUsage(c)))
+	if summary := commandSummary(c.Command.Description); summary != "" {
+		sb.WriteString(fmt.Sprintf("\t\tShort: %q,\n", summary))
+	}
+	if help := commandHelp(c); help != c.Command.Description {
+		sb.WriteString(fmt.Sprintf("\t\tLong: %q,\n", help))
+	}
+	sb.WriteString(fmt.Sprintf("\t\tArgs: %s,\n", validator))
+	sb.WriteString("\t\tRunE: func(cmd *cobra.Command, args []string) error {\n")
+
+	if !c.bound() {
+		sb.WriteString(commandStubComment(c, "\t\t\t", "//"))
+		sb.WriteString("\t\t\treturn errors.New(\"not implemented\")\n")
+	} else {
+		sb.WriteString(goCommandBody(c))
+	}
+	sb.WriteString("\t\t},\n\t}\n")
+
+	for _, in := range c.Inputs {
+		if !in.Flag {
+			continue
+		}
+		def := "nil"
+		if !in.List {
+			def = in.literal("go")
+		}
+		sb.WriteString(fmt.Sprintf("\tcmd.Flags().%s(&%s, %q, %q, %s, %q)\n", goFlagFunc(in), commandVar("go", in), in.Name, in.Short, def, in.Description))
+	}
+	for _, in := range c.Inputs {
+		if in.Flag && in.Required {
+			sb.WriteString(fmt.Sprintf("\t_ = cmd.MarkFlagRequired(%q)\n", in.Name))
+		}
+	}
+	sb.WriteString("\treturn cmd\n}\n")
+	return sb.String()
+}
+
+// goCommandBody parses a bound command's positional arguments, calls its
+// function and prints the result.
+func goCommandBody(c command) string {
+	var sb strings.Builder
+	const indent = "\t\t\t"
+	errDeclared := false
+	position := 0
+	values := make(map[int]string)
+
+	for i, in := range c.Inputs {
+		v := commandVar("go", in)
+		arg, isBound := c.boundTo(i)
+		pointer := isBound && arg.Optional
+		if in.Flag {
+			values[i] = v
+			switch {
+			case pointer && in.absent():
+				values[i] = v + "Value"
+				sb.WriteString(fmt.Sprintf("%svar %sValue *%s\n", indent, v, mapType(in.Scalar, "go")))
+				sb.WriteString(fmt.Sprintf("%sif cmd.Flags().Changed(%q) {\n%s\t%sValue = &%s\n%s}\n", indent, in.Name, indent, v, v, indent))
+			case pointer:
+				values[i] = "&" + v
+			}
+			continue
+		}
+
+		at := position
+		position++
+		if !isBound {
+			continue
+		}
+		values[i] = v
+		parse := goParse(in.Scalar)
+		switch {
+		case in.List:
+			if parse == "" {
+				sb.WriteString(fmt.Sprintf("%s%s := args[%d:]\n", indent, v, at))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("%svar %s %s\n", indent, v, mapType(in.pseudoType(), "go")))
+			sb.WriteString(fmt.Sprintf("%sfor _, raw := range args[%d:] {\n", indent, at))
+			sb.WriteString(fmt.Sprintf("%s\tparsed, err := %s\n", indent, fmt.Sprintf(parse, "raw")))
+			sb.WriteString(fmt.Sprintf("%s\tif err != nil {\n%s\t\treturn err\n%s\t}\n", indent, indent, indent))
+			sb.WriteString(fmt.Sprintf("%s\t%s = append(%s, parsed)\n%s}\n", indent, v, v, indent))
+
+		case in.Required && in.Default == "":
+			if parse == "" {
+				sb.WriteString(fmt.Sprintf("%s%s := args[%d]\n", indent, v, at))
+			} else {
+				errDeclared = true
+				sb.WriteString(fmt.Sprintf("%s%s, err := %s\n", indent, v, fmt.Sprintf(parse, fmt.Sprintf("args[%d]", at))))
+				sb.WriteString(fmt.Sprintf("%sif err != nil {\n%s\treturn err\n%s}\n", indent, indent, indent))
+			}
+			if pointer {
+				values[i] = "&" + v
+			}
+
+		default:
+			if pointer && in.absent() {
+				sb.WriteString(fmt.Sprintf("%svar %s *%s\n", indent, v, mapType(in.Scalar, "go")))
+			} else {
+				sb.WriteString(fmt.Sprintf("%s%s := %s\n", indent, v, in.literal("go")))
+				if pointer {
+					values[i] = "&" + v
+				}
+			}
+			sb.WriteString(fmt.Sprintf("%sif len(args) > %d {\n", indent, at))
+			if parse == "" {
+				sb.WriteString(fmt.Sprintf("%s\tparsed := args[%d]\n", indent, at))
+			} else {
+				sb.WriteString(fmt.Sprintf("%s\tparsed, err := %s\n", indent, fmt.Sprintf(parse, fmt.Sprintf("args[%d]", at))))
+				sb.WriteString(fmt.Sprintf("%s\tif err != nil {\n%s\t\treturn err\n%s\t}\n", indent, indent, indent))
+			}
+			if pointer && in.absent() {
+				sb.WriteString(fmt.Sprintf("%s\t%s = &parsed\n%s}\n", indent, v, indent))
+			} else {
+				sb.WriteString(fmt.Sprintf("%s\t%s = parsed\n%s}\n", indent, v, indent))
+			}
+		}
+	}
+
+	var args []string
+	for _, a := range c.Args {
+		args = append(args, values[a.Input])
+	}
+	call := fmt.Sprintf("%s(%s)", moduleFuncIdent("go", *c.Function), strings.Join(args, ", "))
+	hasResult, hasErr := commandReturns(c.Function)
+	switch {
+	case hasResult && hasErr:
+		sb.WriteString(fmt.Sprintf("%sresult, err := %s\n", indent, call))
+	case hasResult:
+		sb.WriteString(fmt.Sprintf("%sresult := %s\n", indent, call))
+	case hasErr && errDeclared:
+		sb.WriteString(fmt.Sprintf("%serr = %s\n", indent, call))
+	case hasErr:
+		sb.WriteString(fmt.Sprintf("%serr := %s\n", indent, call))
+	default:
+		sb.WriteString(fmt.Sprintf("%s%s\n", indent, call))
+	}
+	if hasErr {
+		sb.WriteString(fmt.Sprintf("%sif err != nil {\n%s\treturn &ExitError{Code: %d, Err: err}\n%s}\n", indent, indent, c.ExitCode, indent))
+	}
+	switch {
+	case !hasResult:
+		sb.WriteString(indent + "return nil\n")
+	case c.Command.Output == "json":
+		sb.WriteString(indent + "return printJSON(cmd.OutOrStdout(), result)\n")
+	default:
+		sb.WriteString(indent + "fmt.Fprintln(cmd.OutOrStdout(), result)\n")
+		sb.WriteString(indent + "return nil\n")
+	}
+	return sb.String()
+}
+
+// goFlagFunc returns the pflag function binding a flag to a variable.
+func goFlagFunc(in commandInput) string {
+	name := map[string]string{
+		typeexpr.String: "String",
+		typeexpr.Int:    "Int",
+		typeexpr.Int64:  "Int64",
+		typeexpr.Float:  "Float64",
+		typeexpr.Bool:   "Bool",
+	}[in.Scalar]
+	if in.List {
+		name += "Slice"
+	}
+	return name + "VarP"
+}
+
+// commandVar returns the variable holding an input, renamed where it would
+// shadow the names generated command code uses.
+func commandVar(langID string, in commandInput) string {
+	v := paramIdent(langID, in.Name)
+	switch v {
+	case "cmd", "args", "err", "result", "parsed", "raw", "options", "command", "context", "parser", "self":
+		return v + "Arg"
+	}
+	return v
+}
+
+// generateTypeScriptCommand generates a function adding a commander
+// command to its parent.
+func generateTypeScriptCommand(c command, program string) string {
+	var sb strings.Builder
+	name := "add" + c.ident() + "Command"
+	sb.WriteString(fmt.Sprintf("/**\n * Adds `%s %s`", program, strings.Join(c.Path, " ")))
+	if summary := commandSummary(c.Command.Description); summary != "" {
+		sb.WriteString(": " + strings.TrimSuffix(summary, "."))
+	}
+	sb.WriteString(".\n */\n")
+	sb.WriteString(fmt.Sprintf("function %s(parent: Command): void {\n", name))
+
+	if c.group() {
+		sb.WriteString(fmt.Sprintf("  const command = parent.command(%q)", c.Command.Name))
+		if c.Command.Description != "" {
+			sb.WriteString(fmt.Sprintf(".description(%q)", c.Command.Description))
+		}
+		sb.WriteString(";\n")
+		for _, child := range c.Children {
+			sb.WriteString(fmt.Sprintf("  add%sCommand(command);\n", toPascalCase(strings.Join(child, " "))))
+		}
+		sb.WriteString("}\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("  parent\n    .command(%q)\n", c.Command.Name))
+	if c.Command.Description != "" {
+		sb.WriteString(fmt.Sprintf("    .description(%q)\n", c.Command.Description))
+	}
+
+	var params []string
+	for _, in := range c.Inputs {
+		parser := map[string]string{typeexpr.Int: "parseInteger", typeexpr.Int64: "parseInteger", typeexpr.Float: "parseNumber"}[in.Scalar]
+		if parser != "" && in.List {
+			parser = "collect(" + parser + ")"
+		}
+		var extra []string
+		if parser != "" {
+			extra = append(extra, parser)
+		}
+		if in.Default != "" {
+			extra = append(extra, in.literal("typescript"))
+		}
+		tail := ""
+		if len(extra) > 0 {
+			tail = ", " + strings.Join(extra, ", ")
+		}
+
+		if !in.Flag {
+			word := in.Name
+			if in.List {
+				word += "..."
+			}
+			if in.Required && in.Default == "" {
+				word = "<" + word + ">"
+			} else {
+				word = "[" + word + "]"
+			}
+			sb.WriteString(fmt.Sprintf("    .argument(%q, %q%s)\n", word, in.Description, tail))
+
+			typ := mapType(in.pseudoType(), "typescript")
+			if in.absent() {
+				typ += " | undefined"
+			}
+			params = append(params, fmt.Sprintf("%s: %s", commandVar("typescript", in), typ))
+			continue
+		}
+
+		flags := "--" + in.Name
+		if in.Short != "" {
+			flags = "-" + in.Short + ", " + flags
+		}
+		if in.Scalar != typeexpr.Bool || in.List {
+			value := in.Name
+			if in.List {
+				value += "..."
+			}
+			flags += " <" + value + ">"
+		}
+		method := "option"
+		if in.Required {
+			method = "requiredOption"
+		}
+		sb.WriteString(fmt.Sprintf("    .%s(%q, %q%s)\n", method, flags, in.Description, tail))
+	}
+	if len(c.Command.ExitCodes) > 0 {
+		help := strings.TrimPrefix(commandHelp(c), c.Command.Description)
+		sb.WriteString(fmt.Sprintf("    .addHelpText(\"after\", %q)\n", "\n"+strings.TrimLeft(help, "\n")))
+	}
+	params = append(params, "options: OptionValues")
+
+	if !c.bound() {
+		sb.WriteString("    .action(() => {\n")
+		sb.WriteString(commandStubComment(c, "      ", "//"))
+		sb.WriteString("      console.error(\"not implemented\");\n      process.exitCode = 1;\n    });\n}\n")
+		return sb.String()
+	}
+
+	async := ""
+	if c.Function.IsAsync {
+		async = "async "
+	}
+	sb.WriteString(fmt.Sprintf("    .action(%s(%s) => {\n", async, strings.Join(params, ", ")))
+
+	var args []string
+	for _, a := range c.Args {
+		in := c.Inputs[a.Input]
+		value := commandVar("typescript", in)
+		if in.Flag {
+			value = "options" + tsOptionKey(in.Name)
+		}
+		if in.absent() {
+			if a.Optional {
+				value += " ?? null"
+			} else {
+				value += " ?? " + defaultValue(in.Scalar, "typescript")
+			}
+		}
+		args = append(args, value)
+	}
+	call := fmt.Sprintf("%s(%s)", funcIdent("typescript", c.Function.Name), strings.Join(args, ", "))
+	if c.Function.IsAsync {
+		call = "await " + call
+	}
+	sb.WriteString("      try {\n")
+	if len(c.Function.Returns) > 0 {
+		sb.WriteString(fmt.Sprintf("        const result = %s;\n", call))
+		if c.Command.Output == "json" {
+			sb.WriteString("        console.log(JSON.stringify(result, null, 2));\n")
+		} else {
+			sb.WriteString("        console.log(result);\n")
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("        %s;\n", call))
+	}
+	sb.WriteString("      } catch (err) {\n")
+	sb.WriteString("        console.error(err instanceof Error ? err.message : err);\n")
+	sb.WriteString(fmt.Sprintf("        process.exitCode = %d;\n      }\n    });\n}\n", c.ExitCode))
+	return sb.String()
+}
+
+// tsOptionKey returns the accessor of a flag's value on commander's
+// options, which camelCases dashed names.
+func tsOptionKey(name string) string {
+	words := strings.Split(name, "-")
+	for i := 1; i < len(words); i++ {
+		if words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	return propertyAccess(strings.Join(words, ""))
+}
+
+// generatePythonCommand generates a function adding an argparse subparser
+// and, for a command that is not a group, the handler it runs.
+func generatePythonCommand(c command, program string) string {
+	var sb strings.Builder
+	ident := toSnakeCase(strings.Join(c.Path, " "))
+	title := program + " " + strings.Join(c.Path, " ")
+
+	sb.WriteString(fmt.Sprintf("def add_%s(commands: Any) -> None:\n", ident))
+	if c.group() {
+		sb.WriteString(fmt.Sprintf("    \"\"\"Adds `%s`, which groups its subcommands.\"\"\"\n", title))
+	} else {
+		sb.WriteString(fmt.Sprintf("    \"\"\"Adds `%s`.\"\"\"\n", title))
+	}
+	sb.WriteString(fmt.Sprintf("    parser = commands.add_parser(%q", c.Command.Name))
+	if summary := commandSummary(c.Command.Description); summary != "" {
+		sb.WriteString(fmt.Sprintf(", help=%q, description=%q", summary, c.Command.Description))
+	}
+	if len(c.Command.ExitCodes) > 0 {
+		help := strings.TrimLeft(strings.TrimPrefix(commandHelp(c), c.Command.Description), "\n")
+		sb.WriteString(fmt.Sprintf(",\n        epilog=%q, formatter_class=argparse.RawDescriptionHelpFormatter", help))
+	}
+	sb.WriteString(")\n")
+
+	if c.group() {
+		sb.WriteString(fmt.Sprintf("    subcommands = parser.add_subparsers(dest=\"%s_command\", metavar=\"command\", required=True)\n", ident))
+		for _, child := range c.Children {
+			sb.WriteString(fmt.Sprintf("    add_%s(subcommands)\n", toSnakeCase(strings.Join(child, " "))))
+		}
+		return sb.String()
+	}
+
+	for _, in := range c.Inputs {
+		v := commandVar("python", in)
+		var opts []string
+		if in.Flag {
+			opts = append(opts, fmt.Sprintf("%q", "--"+in.Name))
+			if in.Short != "" {
+				opts = append(opts, fmt.Sprintf("%q", "-"+in.Short))
+			}
+			if v != strings.ReplaceAll(in.Name, "-", "_") {
+				opts = append(opts, fmt.Sprintf("dest=%q", v))
+			}
+			switch {
+			case in.Scalar == typeexpr.Bool && !in.List && in.literal("python") == "True":
+				opts = append(opts, "action=argparse.BooleanOptionalAction", "default=True")
+			case in.Scalar == typeexpr.Bool && !in.List:
+				opts = append(opts, "action=\"store_true\"")
+			case in.List:
+				opts = append(opts, "type="+mapType(in.Scalar, "python"), "action=\"append\"", "default=[]")
+			default:
+				opts = append(opts, "type="+mapType(in.Scalar, "python"))
+				if in.Default != "" {
+					opts = append(opts, "default="+in.literal("python"))
+				}
+			}
+			if in.Required {
+				opts = append(opts, "required=True")
+			}
+		} else {
+			opts = append(opts, fmt.Sprintf("%q", v))
+			if v != in.Name {
+				opts = append(opts, fmt.Sprintf("metavar=%q", in.Name))
+			}
+			opts = append(opts, "type="+mapType(in.Scalar, "python"))
+			switch {
+			case in.List && in.Required:
+				opts = append(opts, "nargs=\"+\"")
+			case in.List:
+				opts = append(opts, "nargs=\"*\"")
+			case !in.Required || in.Default != "":
+				opts = append(opts, "nargs=\"?\"")
+				if in.Default != "" {
+					opts = append(opts, "default="+in.literal("python"))
+				}
+			}
+		}
+		if in.Description != "" {
+			opts = append(opts, fmt.Sprintf("help=%q", in.Description))
+		}
+		sb.WriteString(fmt.Sprintf("    parser.add_argument(%s)\n", strings.Join(opts, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("    parser.set_defaults(handler=run_%s)\n\n\n", ident))
+
+	sb.WriteString(fmt.Sprintf("def run_%s(args: argparse.Namespace) -> int:\n", ident))
+	sb.WriteString(fmt.Sprintf("    \"\"\"Runs `%s` and returns its exit code.\"\"\"\n", title))
+	if !c.bound() {
+		sb.WriteString(commandStubComment(c, "    ", "#"))
+		sb.WriteString("    print(\"not implemented\", file=sys.stderr)\n    return 1\n")
+		return sb.String()
+	}
+
+	var args []string
+	for _, a := range c.Args {
+		args = append(args, "args."+commandVar("python", c.Inputs[a.Input]))
+	}
+	call := fmt.Sprintf("%s(%s)", funcIdent("python", c.Function.Name), strings.Join(args, ", "))
+	if c.Function.IsAsync {
+		call = "asyncio.run(" + call + ")"
+	}
+	sb.WriteString("    try:\n")
+	if len(c.Function.Returns) > 0 {
+		sb.WriteString(fmt.Sprintf("        result = %s\n", call))
+	} else {
+		sb.WriteString(fmt.Sprintf("        %s\n", call))
+	}
+	sb.WriteString("    except Exception as err:\n")
+	sb.WriteString(fmt.Sprintf("        print(err, file=sys.stderr)\n        return %d\n", c.ExitCode))
+	if len(c.Function.Returns) > 0 {
+		if c.Command.Output == "json" {
+			sb.WriteString("    _print_json(result)\n")
+		} else {
+			sb.WriteString("    print(result)\n")
+		}
+	}
+	sb.WriteString("    return 0\n")
+	return sb.String()
+}
+
+// generateJavaCommand generates a picocli command class nested in Cli.
+func generateJavaCommand(c command, program string) string {
+	var sb strings.Builder
+	class := c.ident() + "Command"
+	sb.WriteString(fmt.Sprintf("    /**\n     * %s %s", program, strings.Join(c.Path, " ")))
+	if summary := commandSummary(c.Command.Description); summary != "" {
+		sb.WriteString(": " + strings.TrimSuffix(summary, "."))
+	}
+	sb.WriteString(".\n     */\n")
+
+	attrs := []string{fmt.Sprintf("name = %q", c.Command.Name), "mixinStandardHelpOptions = true"}
+	if c.Command.Description != "" {
+		attrs = append(attrs, "description = "+javaDescription(c.Command.Description))
+	}
+	if len(c.Command.ExitCodes) > 0 {
+		var codes []string
+		for _, e := range c.Command.ExitCodes {
+			codes = append(codes, fmt.Sprintf("%q", fmt.Sprintf("%d:%s", e.Code, strings.ReplaceAll(e.Description, "%", "%%"))))
+		}
+		attrs = append(attrs, "exitCodeListHeading = \"Exit codes:%n\"", fmt.Sprintf("exitCodeList = {%s}", strings.Join(codes, ", ")))
+	}
+	if c.group() {
+		var children []string
+		for _, child := range c.Children {
+			children = append(children, toPascalCase(strings.Join(child, " "))+"Command.class")
+		}
+		attrs = append(attrs, fmt.Sprintf("subcommands = {%s}", strings.Join(children, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("    @Command(%s)\n", strings.Join(attrs, ",\n            ")))
+
+	if c.group() {
+		sb.WriteString(fmt.Sprintf("    static class %s implements Runnable {\n", class))
+		sb.WriteString("        @Spec\n        CommandSpec spec;\n\n")
+		sb.WriteString("        @Override\n        public void run() {\n")
+		sb.WriteString("            throw new ParameterException(spec.commandLine(), \"Missing required subcommand\");\n        }\n    }\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("    static class %s implements Callable<Integer> {\n", class))
+	position := 0
+	for i, in := range c.Inputs {
+		arg, isBound := c.boundTo(i)
+		typ := mapType(in.pseudoType(), "java")
+		if in.absent() && (!isBound || arg.Optional) {
+			typ = boxedJavaType(typ)
+		}
+
+		var attrs []string
+		if in.Flag {
+			if in.Short != "" {
+				attrs = append(attrs, fmt.Sprintf("names = {%q, %q}", "--"+in.Name, "-"+in.Short))
+			} else {
+				attrs = append(attrs, fmt.Sprintf("names = %q", "--"+in.Name))
+			}
+			if in.Required {
+				attrs = append(attrs, "required = true")
+			}
+		} else {
+			switch {
+			case in.List:
+				attrs = append(attrs, fmt.Sprintf("index = \"%d..*\"", position))
+				if in.Required {
+					attrs = append(attrs, "arity = \"1..*\"")
+				} else {
+					attrs = append(attrs, "arity = \"0..*\"")
+				}
+			case !in.Required || in.Default != "":
+				attrs = append(attrs, fmt.Sprintf("index = \"%d\"", position), "arity = \"0..1\"")
+			default:
+				attrs = append(attrs, fmt.Sprintf("index = \"%d\"", position))
+			}
+			position++
+		}
+		if in.Default != "" {
+			def := in.Default
+			if unquoted, err := strconv.Unquote(def); err == nil {
+				def = unquoted
+			}
+			attrs = append(attrs, fmt.Sprintf("defaultValue = %q", def))
+		}
+		if in.Description != "" {
+			attrs = append(attrs, "description = "+javaDescription(in.Description))
+		}
+
+		annotation := "Parameters"
+		if in.Flag {
+			annotation = "Option"
+		}
+		sb.WriteString(fmt.Sprintf("        @%s(%s)\n", annotation, strings.Join(attrs, ", ")))
+		sb.WriteString(fmt.Sprintf("        %s %s;\n\n", typ, commandVar("java", in)))
+	}
+
+	sb.WriteString("        @Override\n        public Integer call() {\n")
+	if !c.bound() {
+		sb.WriteString(commandStubComment(c, "            ", "//"))
+		sb.WriteString("            System.err.println(\"not implemented\");\n            return 1;\n        }\n    }\n")
+		return sb.String()
+	}
+
+	var args []string
+	for _, a := range c.Args {
+		args = append(args, commandVar("java", c.Inputs[a.Input]))
+	}
+	call := fmt.Sprintf("%s.%s(%s)", c.Service, funcIdent("java", c.Function.Name), strings.Join(args, ", "))
+	sb.WriteString("            try {\n")
+	if len(c.Function.Returns) > 0 {
+		sb.WriteString(fmt.Sprintf("                var result = %s;\n", call))
+		if c.Command.Output == "json" {
+			sb.WriteString("                System.out.println(JSON.writeValueAsString(result));\n")
+		} else {
+			sb.WriteString("                System.out.println(result);\n")
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("                %s;\n", call))
+	}
+	sb.WriteString("                return 0;\n            } catch (Exception e) {\n")
+	sb.WriteString("                System.err.println(e.getMessage());\n")
+	sb.WriteString(fmt.Sprintf("                return %d;\n            }\n        }\n    }\n", c.ExitCode))
+	return sb.String()
+}
+
+// javaDescription quotes a picocli description, which is a format string.
+func javaDescription(s string) string {
+	return fmt.Sprintf("%q", strings.ReplaceAll(s, "%", "%%"))
+}
+
+// rustCommandEnum generates the clap subcommand enum of the root or of a
+// group, with a run method dispatching to the chosen command.
+func rustCommandEnum(name string, commands []command, children [][]string) string {
+	var sb strings.Builder
+	sb.WriteString("#[derive(Subcommand)]\n")
+	sb.WriteString(fmt.Sprintf("enum %s {\n", name))
+	var arms []string
+	for _, path := range children {
+		var child command
+		for _, c := range commands {
+			if c.name() == strings.Join(path, "/") {
+				child = c
+			}
+		}
+		variant := toPascalCase(child.Command.Name)
+		if summary := commandSummary(child.Command.Description); summary != "" {
+			sb.WriteString(fmt.Sprintf("    /// %s\n", summary))
+		}
+		var attrs []string
+		if variant != toPascalCase(strings.ReplaceAll(child.Command.Name, "-", " ")) || strings.ToLower(variant) != strings.ReplaceAll(strings.ToLower(child.Command.Name), "-", "") {
+			attrs = append(attrs, fmt.Sprintf("name = %q", child.Command.Name))
+		}
+		if child.group() {
+			attrs = append(attrs, "subcommand")
+		} else if len(child.Command.ExitCodes) > 0 {
+			help := strings.TrimLeft(strings.TrimPrefix(commandHelp(child), child.Command.Description), "\n")
+			attrs = append(attrs, fmt.Sprintf("after_help = %q", help))
+		}
+		if len(attrs) > 0 {
+			sb.WriteString(fmt.Sprintf("    #[command(%s)]\n", strings.Join(attrs, ", ")))
+		}
+		if child.group() {
+			sb.WriteString(fmt.Sprintf("    %s(%sCommands),\n", variant, child.ident()))
+			arms = append(arms, fmt.Sprintf("            %s::%s(command) => command.run(),\n", name, variant))
+		} else {
+			sb.WriteString(fmt.Sprintf("    %s(%sArgs),\n", variant, child.ident()))
+			arms = append(arms, fmt.Sprintf("            %s::%s(args) => args.run(),\n", name, variant))
+		}
+	}
+	sb.WriteString("}\n\n")
+	sb.WriteString(fmt.Sprintf("impl %s {\n    fn run(self) -> ExitCode {\n        match self {\n", name))
+	sb.WriteString(strings.Join(arms, ""))
+	sb.WriteString("        }\n    }\n}\n")
+	return sb.String()
+}
+
+// generateRustCommand generates a clap subcommand enum for a group, or the
+// arguments struct of a command and the run method calling its function
+// from the library crate.
+func generateRustCommand(c command, program string, commands []command) string {
+	title := program + " " + strings.Join(c.Path, " ")
+	if c.group() {
+		return fmt.Sprintf("/// The subcommands of `%s`.\n", title) + rustCommandEnum(c.ident()+"Commands", commands, c.Children)
+	}
+
+	var sb strings.Builder
+	if !c.bound() && len(c.Inputs) > 0 {
+		sb.WriteString("#[allow(dead_code)]\n")
+	}
+	if len(c.Inputs) == 0 {
+		sb.WriteString(fmt.Sprintf("#[derive(Args)]\nstruct %sArgs {}\n\n", c.ident()))
+	} else {
+		sb.WriteString(fmt.Sprintf("#[derive(Args)]\nstruct %sArgs {\n", c.ident()))
+	}
+	for _, in := range c.Inputs {
+		if in.Description != "" {
+			sb.WriteString(fmt.Sprintf("    /// %s\n", in.Description))
+		}
+		typ := mapType(in.pseudoType(), "rust")
+		var attrs []string
+		if in.Flag {
+			// clap names a flag after its field, in kebab case
+			if strings.ReplaceAll(commandVar("rust", in), "_", "-") != in.Name {
+				attrs = append(attrs, fmt.Sprintf("long = %q", in.Name))
+			} else {
+				attrs = append(attrs, "long")
+			}
+			if in.Short != "" {
+				attrs = append(attrs, fmt.Sprintf("short = '%s'", in.Short))
+			}
+		} else if in.List && in.Required {
+			attrs = append(attrs, "required = true")
+		}
+		switch {
+		case in.List:
+		case in.Default != "" && in.Scalar == typeexpr.String:
+			def := in.Default
+			if unquoted, err := strconv.Unquote(def); err == nil {
+				def = unquoted
+			}
+			attrs = append(attrs, fmt.Sprintf("default_value = %q", def))
+		case in.Default != "":
+			attrs = append(attrs, "default_value_t = "+in.literal("rust"))
+		case in.absent():
+			typ = "Option<" + typ + ">"
+		}
+		if len(attrs) > 0 {
+			sb.WriteString(fmt.Sprintf("    #[arg(%s)]\n", strings.Join(attrs, ", ")))
+		}
+		sb.WriteString(fmt.Sprintf("    %s: %s,\n", commandVar("rust", in), typ))
+	}
+	if len(c.Inputs) > 0 {
+		sb.WriteString("}\n\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("impl %sArgs {\n", c.ident()))
+	sb.WriteString(fmt.Sprintf("    /// Runs `%s`.\n", title))
+	sb.WriteString("    fn run(self) -> ExitCode {\n")
+	if !c.bound() {
+		sb.WriteString(commandStubComment(c, "        ", "//"))
+		sb.WriteString("        eprintln!(\"not implemented\");\n        ExitCode::FAILURE\n    }\n}\n")
+		return sb.String()
+	}
+
+	var args []string
+	for _, a := range c.Args {
+		in := c.Inputs[a.Input]
+		value := "self." + commandVar("rust", in)
+		switch {
+		case a.Optional && !in.absent():
+			value = "Some(" + value + ")"
+		case !a.Optional && in.absent():
+			value += ".unwrap_or_default()"
+		}
+		args = append(args, value)
+	}
+	service := program + "::" + strings.TrimPrefix(c.Service, "crate::")
+	call := fmt.Sprintf("%s::%s(%s)", service, funcIdent("rust", c.Function.Name), strings.Join(args, ", "))
+	if c.Function.IsAsync {
+		call = fmt.Sprintf("tokio::runtime::Runtime::new().unwrap().block_on(%s)", call)
+	}
+
+	// Scalars print as themselves, other values in their debug form
+	hasResult := len(c.Function.Returns) > 0
+	print := "println!(\"{result:?}\");"
+	switch {
+	case c.Command.Output == "json":
+		print = "println!(\"{}\", serde_json::to_string_pretty(&result).unwrap());"
+	case hasResult && isScalarType(c.Function.Returns[0].Type):
+		print = "println!(\"{result}\");"
+	}
+
+	if rustWrapsErrors(*c.Function) {
+		sb.WriteString(fmt.Sprintf("        match %s {\n", call))
+		if hasResult {
+			sb.WriteString("            Ok(result) => {\n                " + print + "\n                ExitCode::SUCCESS\n            }\n")
+		} else {
+			sb.WriteString("            Ok(()) => ExitCode::SUCCESS,\n")
+		}
+		sb.WriteString("            Err(err) => {\n                eprintln!(\"{err}\");\n")
+		sb.WriteString(fmt.Sprintf("                ExitCode::from(%d)\n            }\n        }\n    }\n}\n", c.ExitCode))
+		return sb.String()
+	}
+	if hasResult {
+		sb.WriteString(fmt.Sprintf("        let result = %s;\n", call))
+		sb.WriteString("        " + print + "\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("        %s;\n", call))
+	}
+	sb.WriteString("        ExitCode::SUCCESS\n    }\n}\n")
+	return sb.String()
+}
+
+// generateCSharpCommand generates a method building a System.CommandLine
+// command, with a handler reading the parsed arguments and options.
+func generateCSharpCommand(c command, program string) string {
+	var sb strings.Builder
+	title := program + " " + strings.Join(c.Path, " ")
+	sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// Builds `%s`.\n        /// </summary>\n", title))
+	sb.WriteString(fmt.Sprintf("        private static Command %sCommand()\n        {\n", c.ident()))
+	sb.WriteString(fmt.Sprintf("            var command = new Command(%q, %q);\n", c.Command.Name, commandHelp(c)))
+
+	if c.group() {
+		for _, child := range c.Children {
+			sb.WriteString(fmt.Sprintf("            command.AddCommand(%sCommand());\n", toPascalCase(strings.Join(child, " "))))
+		}
+		sb.WriteString("            return command;\n        }\n")
+		return sb.String()
+	}
+
+	values := make(map[int]string)
+	for i, in := range c.Inputs {
+		typ := mapType(in.pseudoType(), "csharp")
+		if in.absent() {
+			typ += "?"
+		}
+		var def string
+		switch {
+		case in.Default != "":
+			def = "() => " + in.literal("csharp") + ", "
+		case !in.Flag && in.absent():
+			def = "() => null, "
+		}
+
+		if in.Flag {
+			v := commandVar("csharp", in) + "Option"
+			values[i] = fmt.Sprintf("context.ParseResult.GetValueForOption(%s)", v)
+			name := fmt.Sprintf("%q", "--"+in.Name)
+			if in.Short != "" {
+				name = fmt.Sprintf("new[] { %q, %q }", "--"+in.Name, "-"+in.Short)
+			}
+			sb.WriteString(fmt.Sprintf("            var %s = new Option<%s>(%s, %s%q)", v, typ, name, def, in.Description))
+			if in.Required {
+				sb.WriteString(" { IsRequired = true }")
+			}
+			sb.WriteString(fmt.Sprintf(";\n            command.AddOption(%s);\n", v))
+			continue
+		}
+
+		v := commandVar("csharp", in) + "Argument"
+		values[i] = fmt.Sprintf("context.ParseResult.GetValueForArgument(%s)", v)
+		sb.WriteString(fmt.Sprintf("            var %s = new Argument<%s>(%q, %s%q)", v, typ, in.Name, def, in.Description))
+		if in.List && in.Required {
+			sb.WriteString(" { Arity = ArgumentArity.OneOrMore }")
+		} else if in.List {
+			sb.WriteString(" { Arity = ArgumentArity.ZeroOrMore }")
+		}
+		sb.WriteString(fmt.Sprintf(";\n            command.AddArgument(%s);\n", v))
+	}
+
+	if !c.bound() {
+		sb.WriteString("            command.SetHandler(context =>\n            {\n")
+		sb.WriteString(commandStubComment(c, "                ", "//"))
+		sb.WriteString("                Console.Error.WriteLine(\"not implemented\");\n                context.ExitCode = 1;\n            });\n")
+		sb.WriteString("            return command;\n        }\n")
+		return sb.String()
+	}
+
+	var args []string
+	for _, a := range c.Args {
+		value := values[a.Input]
+		in := c.Inputs[a.Input]
+		if in.absent() && !a.Optional {
+			value += " ?? " + defaultValue(in.Scalar, "csharp")
+		}
+		args = append(args, value)
+	}
+	call := fmt.Sprintf("%s.%s(%s)", c.Service, funcIdent("csharp", c.Function.Name), strings.Join(args, ", "))
+	handler := "context =>"
+	if c.Function.IsAsync {
+		handler = "async context =>"
+		call = "await " + call
+	}
+	sb.WriteString(fmt.Sprintf("            command.SetHandler(%s\n            {\n                try\n                {\n", handler))
+	if len(c.Function.Returns) > 0 {
+		sb.WriteString(fmt.Sprintf("                    var result = %s;\n", call))
+		if c.Command.Output == "json" {
+			sb.WriteString("                    Console.WriteLine(JsonSerializer.Serialize(result, JsonOptions));\n")
+		} else {
+			sb.WriteString("                    Console.WriteLine(result);\n")
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("                    %s;\n", call))
+	}
+	sb.WriteString("                }\n                catch (Exception e)\n                {\n")
+	sb.WriteString("                    Console.Error.WriteLine(e.Message);\n")
+	sb.WriteString(fmt.Sprintf("                    context.ExitCode = %d;\n                }\n            });\n", c.ExitCode))
+	sb.WriteString("            return command;\n        }\n")
+	return sb.String()
+}
@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const shortenerSpec = "# Shortener\n\n" +
+	"Shortens URLs.\n\n" +
+	"## Types\n\n" +
+	"### Link (struct)\n\n" +
+	"- code: string - Short code\n" +
+	"- url: string - Target URL\n\n" +
+	"## Functions\n\n" +
+	"### Shorten\n\n" +
+	"**Parameters**\n" +
+	"- `url`: `string`\n" +
+	"- `alias`: `Optional[string]`\n" +
+	"- `ttl`: `int`\n\n" +
+	"**Returns** `Link`\n\n" +
+	"**Errors**\n" +
+	"- `InvalidUrl`: the URL is not valid\n\n" +
+	"### Migrate\n\n" +
+	"**Parameters**\n" +
+	"- `steps`: `int`\n" +
+	"- `dryRun`: `bool`\n\n" +
+	"**Returns** `int`\n\n" +
+	"## Commands\n\n" +
+	"### shorten <url>\n\n" +
+	"Shortens a URL.\n\n" +
+	"**Function**: `shorten`\n\n" +
+	"**Arguments**:\n" +
+	"- `url`: `string` - URL to shorten\n\n" +
+	"**Flags**:\n" +
+	"- `--alias`, `-a`: `string` - Custom alias\n" +
+	"- `--ttl`: `int` - Lifetime in seconds (default: 3600)\n\n" +
+	"**Exit Codes**:\n" +
+	"- `0` - Shortened\n" +
+	"- `2` - The URL is invalid\n\n" +
+	"**Output**: `json`\n\n" +
+	"### db migrate [steps]\n\n" +
+	"Applies pending migrations.\n\n" +
+	"**Arguments**:\n" +
+	"- `steps`: `int` - Migrations to apply (optional)\n\n" +
+	"**Flags**:\n" +
+	"- `--dry-run`: `bool` - Print the migrations without applying them\n\n" +
+	"### db seed\n\n" +
+	"Loads sample data.\n"
+
+func TestResolveCommands(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(shortenerSpec, "shortener.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		function string
+		bound    bool
+		exitCode int
+	}{
+		{"shorten", "Shorten", true, 2},
+		{"db", "", false, 1},
+		{"db/migrate", "Migrate", true, 1},
+		{"db/seed", "", false, 1},
+	}
+
+	commands := resolveCommands(spec)
+	if len(commands) != len(tests) {
+		t.Fatalf("Expected %d commands, got %d", len(tests), len(commands))
+	}
+	for i, tt := range tests {
+		c := commands[i]
+		if c.name() != tt.name {
+			t.Errorf("Expected command %d to be %s, got %s", i, tt.name, c.name())
+			continue
+		}
+		function := ""
+		if c.Function != nil {
+			function = c.Function.Name
+		}
+		if function != tt.function || c.bound() != tt.bound || c.ExitCode != tt.exitCode {
+			t.Errorf("Expected %s to call %q (bound %t, exit %d), got %q (bound %t, exit %d)",
+				tt.name, tt.function, tt.bound, tt.exitCode, function, c.bound(), c.ExitCode)
+		}
+	}
+}
+
+func TestGenerateCommands(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(shortenerSpec, "shortener.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		files    map[string][]string
+	}{
+		{"go", map[string][]string{
+			"cli.go":                {"\"github.com/spf13/cobra\"", "root.AddCommand(newDbCommand())", "cmd.AddCommand(newDbMigrateCommand())", "Shorten(url, aliasValue, ttl)", "&ExitError{Code: 2, Err: err}", "printJSON(cmd.OutOrStdout(), result)", "TODO: Implement (no spec function matches db seed)"},
+			"cmd/shortener/main.go": {"shortener.NewRootCommand().Execute()", "os.Exit(exitErr.Code)"},
+			"go.mod":                {"github.com/spf13/cobra v1.8.0"},
+		}},
+		{"typescript", map[string][]string{
+			"src/cli.ts":   {"from \"commander\"", ".option(\"-a, --alias <alias>\", \"Custom alias\")", ".option(\"--ttl <ttl>\", \"Lifetime in seconds\", parseInteger, 3600)", "shorten(url, options.alias ?? null, options.ttl)", "migrate(steps ?? 0, options.dryRun)", "process.exitCode = 2;"},
+			"package.json": {"\"commander\"", "\"bin\""},
+		}},
+		{"python", map[string][]string{
+			"src/cli.py":     {"import argparse", "from .service import shorten, migrate", "parser.add_argument(\"--dry-run\", action=\"store_true\"", "shorten(args.url, args.alias, args.ttl)", "return 2"},
+			"pyproject.toml": {"[project.scripts]", "shortener = \"src.cli:main\""},
+		}},
+		{"java", map[string][]string{
+			"src/main/java/shortener/Cli.java": {"subcommands = {ShortenCommand.class, DbCommand.class}", "exitCodeList = {\"0:Shortened\", \"2:The URL is invalid\"}", "@Option(names = {\"--alias\", \"-a\"}", "Service.shorten(url, alias, ttl)", "return 2;"},
+			"pom.xml":                          {"<artifactId>picocli</artifactId>"},
+		}},
+		{"rust", map[string][]string{
+			"src/main.rs": {"use clap::{Args, Parser, Subcommand};", "Db(DbCommands)", "#[arg(long, default_value_t = 3600)]", "shortener::service::shorten(self.url, self.alias, self.ttl)", "ExitCode::from(2)", "self.steps.unwrap_or_default()"},
+			"Cargo.toml":  {"clap = { version = \"4\", features = [\"derive\"] }"},
+		}},
+		{"csharp", map[string][]string{
+			"src/Cli.cs":       {"using System.CommandLine;", "root.AddCommand(DbCommand());", "new Option<string?>(new[] { \"--alias\", \"-a\" }", "Service.Shorten(", "context.ExitCode = 2;"},
+			"Shortener.csproj": {"<OutputType>Exe</OutputType>", "System.CommandLine"},
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), tt.files)
+		})
+	}
+}
+
+func TestCommandsBuild(t *testing.T) {
+	checkBuilds(t, shortenerSpec, "go", "python")
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// generatedFiles builds a spec in a language and returns the contents of
+// the generated files by path. Syntax diagnostics fail the test.
+func generatedFiles(t *testing.T, gen *Generator, spec *specparser.SpecAnalysis, language string) map[string]string {
+	t.Helper()
+	adapter, err := gen.registry.Get(language)
+	if err != nil {
+		t.Fatalf("Get(%s) error: %v", language, err)
+	}
+	files := make(map[string]string)
+	for _, f := range gen.build(spec, adapter) {
+		files[f.Path] = f.Content
+		for _, d := range f.Diagnostics {
+			t.Errorf("Unexpected diagnostic in %s: %s", f.Path, d)
+		}
+	}
+	return files
+}
+
+// expectGenerated checks that each file was generated and contains every
+// expected snippet.
+func expectGenerated(t *testing.T, files map[string]string, expected map[string][]string) {
+	t.Helper()
+	for path, wants := range expected {
+		content, ok := files[path]
+		if !ok {
+			t.Errorf("Expected %s to be generated", path)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", path, want, content)
+			}
+		}
+	}
+}
+
+// requireTool skips a test that builds generated code in -short mode or
+// when the language's toolchain is not installed.
+func requireTool(t *testing.T, name string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping build of generated code in short mode")
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		t.Skipf("%s not installed", name)
+	}
+}
+
+// generateProject parses a spec and generates it in a language into a
+// temporary directory, which it returns.
+func generateProject(t *testing.T, content, language string) string {
+	t.Helper()
+	spec, err := specparser.NewParser().Parse(content, "spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	dir := t.TempDir()
+	if _, err := NewGenerator(languages.NewRegistry()).Generate(spec, language, dir); err != nil {
+		t.Fatalf("Generate(%s) error: %v", language, err)
+	}
+	return dir
+}
+
+// writeFile adds a file, such as a test, to a generated project.
+func writeFile(t *testing.T, dir, path, content string) {
+	t.Helper()
+	target := filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runTool runs a command in a generated project, failing the test with its
+// output when it fails. Builds stay offline and on the installed toolchain.
+func runTool(t *testing.T, dir, name string, args ...string) {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local", "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s %v failed: %v\n%s", name, args, err, out)
+	}
+}
+
+// buildCommands compile a generated project without running it, offline.
+// go vet also type-checks the generated tests.
+var buildCommands = map[string][]string{
+	"go":     {"go", "vet", "./..."},
+	"python": {"python3", "-m", "compileall", "-q", "."},
+}
+
+// checkBuilds generates a spec in each language and compiles the project
+// with the language's build command, skipping languages whose toolchain is
+// not installed.
+func checkBuilds(t *testing.T, content string, langs ...string) {
+	t.Helper()
+	for _, language := range langs {
+		t.Run(language, func(t *testing.T) {
+			command := buildCommands[language]
+			requireTool(t, command[0])
+			runTool(t, generateProject(t, content, language), command[0], command[1:]...)
+		})
+	}
+}
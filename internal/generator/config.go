@@ -0,0 +1,1203 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// configSetting is a configuration item resolved for code generation.
+type configSetting struct {
+	specparser.SpecConfig
+
+	// Env is the environment variable the setting is read from
+	Env string
+
+	// Kind is string, int, int64, float, bool, duration or list
+	Kind string
+}
+
+// configSettings resolves the spec's configuration items, dropping items
+// whose environment variable repeats an earlier one.
+func configSettings(configs []specparser.SpecConfig) []configSetting {
+	var settings []configSetting
+	seen := make(map[string]bool)
+	for _, c := range configs {
+		env := configEnvName(c.Name)
+		if env == "" || seen[env] {
+			continue
+		}
+		seen[env] = true
+		settings = append(settings, configSetting{SpecConfig: c, Env: env, Kind: configKind(c.Type)})
+	}
+	return settings
+}
+
+// configEnvName turns a configuration name such as "PORT", "api-key" or
+// "--output" into an environment variable name.
+func configEnvName(name string) string {
+	name = strings.TrimLeft(strings.Trim(name, "`"), "-")
+	name = strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name)
+	return strings.ToUpper(name)
+}
+
+// configKind classifies a configuration type by how its value is parsed
+// from an environment variable; unknown types are read as strings.
+func configKind(typ string) string {
+	e := baseExpr(typ)
+	if e == nil {
+		return "string"
+	}
+	if e.Kind == typeexpr.List || e.Kind == typeexpr.Set {
+		return "list"
+	}
+	if e.Kind != typeexpr.Primitive {
+		return "string"
+	}
+	switch e.Name {
+	case typeexpr.Int, typeexpr.Int64, typeexpr.Bool, typeexpr.Duration:
+		return e.Name
+	case typeexpr.Float, typeexpr.Decimal:
+		return "float"
+	}
+	return "string"
+}
+
+// configTypes maps setting kinds to each language's field types.
+var configTypes = map[string]map[string]string{
+	"go": {
+		"string": "string", "int": "int", "int64": "int64", "float": "float64",
+		"bool": "bool", "duration": "time.Duration", "list": "[]string",
+	},
+	"typescript": {
+		"string": "string", "int": "number", "int64": "number", "float": "number",
+		"bool": "boolean", "duration": "number", "list": "string[]",
+	},
+	"python": {
+		"string": "str", "int": "int", "int64": "int", "float": "float",
+		"bool": "bool", "duration": "timedelta", "list": "list[str]",
+	},
+	"java": {
+		"string": "String", "int": "int", "int64": "long", "float": "double",
+		"bool": "boolean", "duration": "Duration", "list": "List<String>",
+	},
+	"rust": {
+		"string": "String", "int": "i32", "int64": "i64", "float": "f64",
+		"bool": "bool", "duration": "Duration", "list": "Vec<String>",
+	},
+	"csharp": {
+		"string": "string", "int": "int", "int64": "long", "float": "double",
+		"bool": "bool", "duration": "TimeSpan", "list": "string[]",
+	},
+}
+
+// javaBoxed maps Java primitives to the types that can hold null.
+var javaBoxed = map[string]string{"int": "Integer", "long": "Long", "double": "Double", "boolean": "Boolean"}
+
+// hasDefault reports whether a setting falls back to a default value.
+func (s configSetting) hasDefault() bool {
+	return s.Default != ""
+}
+
+// optional reports whether a setting may be left unset.
+func (s configSetting) optional() bool {
+	return !s.Required && !s.hasDefault()
+}
+
+// parseSpecDuration reads a duration default written as "30s", "5m" or
+// "250ms"; a bare number counts seconds.
+func parseSpecDuration(v string) (time.Duration, bool) {
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	d, err := time.ParseDuration(v)
+	return d, err == nil
+}
+
+// formatSeconds renders a duration as a number of seconds.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// configValue renders a setting's default in the form the language reads
+// from the environment. Durations use each language's native notation:
+// Go's "30s", milliseconds in TypeScript, seconds in Python and Rust, ISO
+// 8601 in Java and TimeSpan's "00:00:30" in C#.
+func configValue(langID string, s configSetting) string {
+	if s.Kind != "duration" {
+		return s.Default
+	}
+	d, ok := parseSpecDuration(s.Default)
+	if !ok {
+		return s.Default
+	}
+	switch langID {
+	case "go":
+		return d.String()
+	case "typescript":
+		return strconv.FormatInt(d.Milliseconds(), 10)
+	case "java":
+		return "PT" + formatSeconds(d) + "S"
+	case "csharp":
+		days := d / (24 * time.Hour)
+		rest := d % (24 * time.Hour)
+		v := fmt.Sprintf("%02d:%02d:%02d", int(rest.Hours()), int(rest.Minutes())%60, int(rest.Seconds())%60)
+		if days > 0 {
+			v = fmt.Sprintf("%d.%s", days, v)
+		}
+		if ms := rest.Milliseconds() % 1000; ms > 0 {
+			v += fmt.Sprintf(".%03d", ms)
+		}
+		return v
+	}
+	return formatSeconds(d)
+}
+
+// configList splits a list setting's default into its items.
+func configList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// configComment describes a setting for a doc comment, noting the unit of
+// durations where the language reads them as a number.
+func configComment(langID string, s configSetting) string {
+	desc := strings.TrimSuffix(s.Description, ".")
+	if desc == "" {
+		desc = s.Env
+	}
+	if s.Kind == "duration" {
+		switch langID {
+		case "typescript":
+			desc += ", in milliseconds"
+		case "python", "rust":
+			desc += ", in seconds"
+		}
+	}
+	return desc
+}
+
+// usesKind reports whether any setting has one of the given kinds.
+func usesKind(settings []configSetting, kinds ...string) bool {
+	for _, s := range settings {
+		for _, k := range kinds {
+			if s.Kind == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generateConfig generates a config module that reads the spec's
+// configuration from environment variables and an optional file, with
+// typed parsing, defaults and errors for missing required values, plus a
+// documented .env.example: os.LookupEnv for Go, process.env for
+// TypeScript, pydantic-settings for Python, System.getenv for Java,
+// std::env for Rust and IOptions for C#.
+func (g *Generator) generateConfig(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	settings := configSettings(spec.Configuration)
+	if len(settings) == 0 {
+		return nil
+	}
+
+	lang := adapter.GetLanguage()
+	var path, content string
+	switch lang.ID {
+	case "go":
+		path, content = "config/config.go", goConfig(spec, settings)
+	case "typescript":
+		path, content = "src/config.ts", tsConfig(spec, settings)
+	case "python":
+		path, content = "src/config.py", pythonConfig(spec, settings)
+	case "java":
+		pkg := toPackageName(spec.Name)
+		path, content = fmt.Sprintf("src/main/java/%s/Config.java", pkg), javaConfig(spec, settings)
+	case "rust":
+		path, content = "src/config.rs", rustConfig(spec, settings)
+	case "csharp":
+		path, content = fmt.Sprintf("src/%sOptions.cs", toPascalCase(spec.Name)), csharpConfig(spec, settings)
+	default:
+		return nil
+	}
+
+	var elements []string
+	for _, s := range settings {
+		elements = append(elements, s.Env)
+	}
+
+	return []GeneratedFile{
+		{Path: path, Content: content, Category: "config", Elements: elements},
+		{Path: ".env.example", Content: envExample(lang.ID, spec, settings), Category: "config", Elements: elements},
+	}
+}
+
+// envExample documents every setting with its type, default and whether
+// it is required. Optional settings without a default are commented out.
+func envExample(langID string, spec *specparser.SpecAnalysis, settings []configSetting) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Configuration for %s.\n", spec.Name))
+	if langID == "csharp" {
+		sb.WriteString("# Set these as environment variables; they override appsettings.json.\n")
+	} else {
+		sb.WriteString("# Copy to .env and fill in the required values; environment variables\n")
+		sb.WriteString("# override the file.\n")
+	}
+
+	for _, s := range settings {
+		note := s.Kind
+		switch {
+		case s.Required:
+			note += ", required"
+		case s.hasDefault():
+			note += ", default " + configValue(langID, s)
+		default:
+			note += ", optional"
+		}
+		sb.WriteString(fmt.Sprintf("\n# %s (%s)\n", configComment(langID, s), note))
+
+		value := configValue(langID, s)
+		if langID == "csharp" && s.Kind == "list" {
+			// IConfiguration binds arrays from indexed keys
+			items := configList(value)
+			if len(items) == 0 {
+				sb.WriteString(fmt.Sprintf("# %s__0=\n", s.Env))
+			}
+			for i, item := range items {
+				sb.WriteString(fmt.Sprintf("%s__%d=%s\n", s.Env, i, item))
+			}
+			continue
+		}
+		if s.optional() {
+			sb.WriteString("# ")
+		}
+		sb.WriteString(fmt.Sprintf("%s=%s\n", s.Env, value))
+	}
+	return sb.String()
+}
+
+// goConfig renders a config package whose Load reads os.LookupEnv before
+// a .env file and reports every problem at once with errors.Join.
+func goConfig(spec *specparser.SpecAnalysis, settings []configSetting) string {
+	types := configTypes["go"]
+	var sb strings.Builder
+
+	imports := []string{"errors", "fmt", "os"}
+	if usesKind(settings, "int", "int64", "float", "bool") {
+		imports = append(imports, "strconv")
+	}
+	imports = append(imports, "strings")
+	if usesKind(settings, "duration") {
+		imports = append(imports, "time")
+	}
+
+	sb.WriteString(fmt.Sprintf("// Package config loads the %s settings from\n// environment variables and an optional .env file.\n", spec.Name))
+	sb.WriteString("package config\n\nimport (\n")
+	for _, imp := range imports {
+		sb.WriteString(fmt.Sprintf("\t%q\n", imp))
+	}
+	sb.WriteString(")\n\n")
+
+	width := 0
+	for _, s := range settings {
+		width = max(width, len(toPascalCase(s.Env)))
+	}
+
+	sb.WriteString(fmt.Sprintf("// Config holds the %s settings.\ntype Config struct {\n", spec.Name))
+	for i, s := range settings {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("\t// %s (%s)\n", configComment("go", s), s.Env))
+		sb.WriteString(fmt.Sprintf("\t%s %s\n", toPascalCase(s.Env), types[s.Kind]))
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString(`// Load reads the settings from the environment, falling back to the
+// KEY=value lines of the file at path. Environment variables take
+// precedence over the file, and a missing file is ignored.
+func Load(path string) (*Config, error) {
+	file, err := readEnvFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromLookup(func(key string) (string, bool) {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			return v, true
+		}
+		v, ok := file[key]
+		return v, ok
+	})
+}
+
+// FromLookup builds the settings from a lookup function such as
+// os.LookupEnv, reporting every missing or invalid value at once.
+func FromLookup(lookup func(string) (string, bool)) (*Config, error) {
+	l := &loader{lookup: lookup}
+	cfg := &Config{
+`)
+	parsers := map[string]string{
+		"string": "parseString", "int": "strconv.Atoi", "int64": "parseInt64", "float": "parseFloat",
+		"bool": "strconv.ParseBool", "duration": "time.ParseDuration", "list": "parseList",
+	}
+	for _, s := range settings {
+		field := toPascalCase(s.Env) + ":"
+		sb.WriteString(fmt.Sprintf("\t\t%-*s value(l, %q, %q, %t, %s),\n", width+1, field, s.Env, configValue("go", s), s.Required, parsers[s.Kind]))
+	}
+	sb.WriteString(`	}
+	if err := errors.Join(l.errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loader reads settings through a lookup function, collecting errors.
+type loader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+// value reads a setting, falling back to def when it is unset or empty,
+// and parses it. Errors are recorded on the loader; a required setting
+// with no value and no default is an error.
+func value[T any](l *loader, key, def string, required bool, parse func(string) (T, error)) T {
+	var zero T
+	v, ok := l.lookup(key)
+	if !ok || v == "" {
+		v = def
+	}
+	if v == "" {
+		if required {
+			l.errs = append(l.errs, fmt.Errorf("%s is required", key))
+		}
+		return zero
+	}
+	parsed, err := parse(v)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: invalid value %q: %w", key, v, err))
+		return zero
+	}
+	return parsed
+}
+`)
+	if usesKind(settings, "string") {
+		sb.WriteString("\nfunc parseString(v string) (string, error) { return v, nil }\n")
+	}
+	if usesKind(settings, "int64") {
+		sb.WriteString("\nfunc parseInt64(v string) (int64, error) { return strconv.ParseInt(v, 10, 64) }\n")
+	}
+	if usesKind(settings, "float") {
+		sb.WriteString("\nfunc parseFloat(v string) (float64, error) { return strconv.ParseFloat(v, 64) }\n")
+	}
+	if usesKind(settings, "list") {
+		sb.WriteString(`
+// parseList splits a comma-separated list, dropping empty items.
+func parseList(v string) ([]string, error) {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+`)
+	}
+	sb.WriteString(`
+// readEnvFile reads KEY=value lines from a .env file, skipping blank lines
+// and comments and unquoting quoted values. A missing file reads as empty.
+func readEnvFile(path string) (map[string]string, error) {
+	vars := make(map[string]string)
+	if path == "" {
+		return vars, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return vars, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, v, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		vars[strings.TrimSpace(key)] = v
+	}
+	return vars, nil
+}
+`)
+	return sb.String()
+}
+
+// tsConfig renders a config module that reads process.env before a .env
+// file and throws a ConfigError listing every problem.
+func tsConfig(spec *specparser.SpecAnalysis, settings []configSetting) string {
+	types := configTypes["typescript"]
+	var sb strings.Builder
+
+	sb.WriteString("import { existsSync, readFileSync } from \"node:fs\";\n\n")
+	sb.WriteString(fmt.Sprintf("/** %s settings. */\nexport interface Config {\n", spec.Name))
+	for _, s := range settings {
+		optional := ""
+		if s.optional() {
+			optional = "?"
+		}
+		sb.WriteString(fmt.Sprintf("  /** %s (%s) */\n", configComment("typescript", s), s.Env))
+		sb.WriteString(fmt.Sprintf("  %s%s: %s;\n", toCamelCase(s.Env), optional, types[s.Kind]))
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString(`/** Lists every missing or invalid setting. */
+export class ConfigError extends Error {
+  constructor(readonly problems: string[]) {
+    super(` + "`invalid configuration: ${problems.join(\"; \")}`" + `);
+    this.name = "ConfigError";
+  }
+}
+
+export type Env = Record<string, string | undefined>;
+
+/**
+ * Loads the settings from the environment, falling back to the KEY=value
+ * lines of the file at path. Environment variables take precedence over
+ * the file, and a missing file is ignored.
+ */
+export function loadConfig(path = ".env", env: Env = process.env): Config {
+  const file = readEnvFile(path);
+  return configFrom((key) => env[key] || file[key]);
+}
+
+/**
+ * Builds the settings from a lookup function, throwing a ConfigError that
+ * lists every missing or invalid value.
+ */
+export function configFrom(lookup: (key: string) => string | undefined): Config {
+  const loader = new Loader(lookup);
+  const config = {
+`)
+	parsers := map[string]string{
+		"string": "parseString", "int": "parseInteger", "int64": "parseInteger", "float": "parseNumber",
+		"bool": "parseBoolean", "duration": "parseInteger", "list": "parseList",
+	}
+	for _, s := range settings {
+		def := "undefined"
+		if s.hasDefault() {
+			def = strconv.Quote(configValue("typescript", s))
+		}
+		sb.WriteString(fmt.Sprintf("    %s: loader.value(%q, %s, %t, %s),\n", toCamelCase(s.Env), s.Env, def, s.Required, parsers[s.Kind]))
+	}
+	sb.WriteString(`  };
+  if (loader.problems.length > 0) {
+    throw new ConfigError(loader.problems);
+  }
+  return config as Config;
+}
+
+/** Reads settings through a lookup function, collecting problems. */
+class Loader {
+  readonly problems: string[] = [];
+
+  constructor(private readonly lookup: (key: string) => string | undefined) {}
+
+  /**
+   * Reads a setting, falling back to its default when it is unset or
+   * empty, and parses it; parsers return undefined for invalid values.
+   */
+  value<T>(key: string, fallback: string | undefined, required: boolean, parse: (raw: string) => T | undefined): T | undefined {
+    const raw = this.lookup(key) || fallback;
+    if (raw === undefined) {
+      if (required) {
+        this.problems.push(` + "`${key} is required`" + `);
+      }
+      return undefined;
+    }
+    const parsed = parse(raw);
+    if (parsed === undefined) {
+      this.problems.push(` + "`${key}: invalid value \"${raw}\"`" + `);
+    }
+    return parsed;
+  }
+}
+`)
+	if usesKind(settings, "string") {
+		sb.WriteString("\nconst parseString = (raw: string): string => raw;\n")
+	}
+	if usesKind(settings, "int", "int64", "duration") {
+		sb.WriteString("\nconst parseInteger = (raw: string): number | undefined => (/^\\s*-?\\d+\\s*$/.test(raw) ? Number(raw) : undefined);\n")
+	}
+	if usesKind(settings, "float") {
+		sb.WriteString(`
+function parseNumber(raw: string): number | undefined {
+  const n = Number(raw);
+  return raw.trim() !== "" && Number.isFinite(n) ? n : undefined;
+}
+`)
+	}
+	if usesKind(settings, "bool") {
+		sb.WriteString(`
+function parseBoolean(raw: string): boolean | undefined {
+  switch (raw.trim().toLowerCase()) {
+    case "true":
+    case "1":
+      return true;
+    case "false":
+    case "0":
+      return false;
+  }
+  return undefined;
+}
+`)
+	}
+	if usesKind(settings, "list") {
+		sb.WriteString(`
+const parseList = (raw: string): string[] =>
+  raw
+    .split(",")
+    .map((item) => item.trim())
+    .filter((item) => item !== "");
+`)
+	}
+	sb.WriteString(`
+/**
+ * Reads KEY=value lines from a .env file, skipping blank lines and
+ * comments and unquoting quoted values. A missing file reads as empty.
+ */
+export function readEnvFile(path: string): Env {
+  const vars: Env = {};
+  if (!path || !existsSync(path)) {
+    return vars;
+  }
+  for (const entry of readFileSync(path, "utf8").split("\n")) {
+    const line = entry.trim();
+    const eq = line.indexOf("=");
+    if (line === "" || line.startsWith("#") || eq < 0) {
+      continue;
+    }
+    let value = line.slice(eq + 1).trim();
+    if (value.length >= 2 && (value[0] === '"' || value[0] === "'") && value.endsWith(value[0])) {
+      value = value.slice(1, -1);
+    }
+    vars[line.slice(0, eq).replace(/^export\s+/, "").trim()] = value;
+  }
+  return vars;
+}
+`)
+	return sb.String()
+}
+
+// pythonConfigLiteral renders a setting's default as a Python value.
+func pythonConfigLiteral(s configSetting) string {
+	v := configValue("python", s)
+	switch s.Kind {
+	case "string":
+		return strconv.Quote(v)
+	case "bool":
+		if b, err := strconv.ParseBool(v); err == nil && b {
+			return "True"
+		}
+		return "False"
+	case "duration":
+		return "timedelta(seconds=" + v + ")"
+	case "list":
+		items := configList(v)
+		for i, item := range items {
+			items[i] = strconv.Quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return v
+}
+
+// pythonConfig renders a pydantic-settings model; pydantic reports every
+// missing or invalid value in one ValidationError.
+func pythonConfig(spec *specparser.SpecAnalysis, settings []configSetting) string {
+	types := configTypes["python"]
+	hasList := usesKind(settings, "list")
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("\"\"\"%s settings, loaded from environment variables and an optional .env file.\"\"\"\n\n", spec.Name))
+	if usesKind(settings, "duration") {
+		sb.WriteString("from datetime import timedelta\n")
+	}
+	if hasList {
+		sb.WriteString("from typing import Annotated\n")
+	}
+	sb.WriteString("\n")
+	if hasList {
+		sb.WriteString("from pydantic import Field, field_validator\n")
+		sb.WriteString("from pydantic_settings import BaseSettings, NoDecode, SettingsConfigDict\n")
+	} else {
+		sb.WriteString("from pydantic import Field\n")
+		sb.WriteString("from pydantic_settings import BaseSettings, SettingsConfigDict\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\n\nclass Settings(BaseSettings):\n    \"\"\"%s settings; environment variables override the .env file.\"\"\"\n\n", spec.Name))
+	sb.WriteString("    model_config = SettingsConfigDict(env_file=\".env\", env_ignore_empty=True, extra=\"ignore\")\n\n")
+
+	var lists []string
+	for _, s := range settings {
+		name := strings.ToLower(s.Env)
+		typ := types[s.Kind]
+		if s.Kind == "list" {
+			// NoDecode reads the raw string so the validator can split it
+			typ = "Annotated[list[str], NoDecode]"
+			lists = append(lists, strconv.Quote(name))
+		}
+		desc := strconv.Quote(configComment("python", s))
+		switch {
+		case s.hasDefault():
+			sb.WriteString(fmt.Sprintf("    %s: %s = Field(%s, description=%s)\n", name, typ, pythonConfigLiteral(s), desc))
+		case s.Required:
+			sb.WriteString(fmt.Sprintf("    %s: %s = Field(description=%s)\n", name, typ, desc))
+		default:
+			sb.WriteString(fmt.Sprintf("    %s: %s | None = Field(None, description=%s)\n", name, typ, desc))
+		}
+	}
+	if hasList {
+		sb.WriteString(fmt.Sprintf(`
+    @field_validator(%s, mode="before")
+    @classmethod
+    def _split_lists(cls, value: object) -> object:
+        if isinstance(value, str):
+            return [item.strip() for item in value.split(",") if item.strip()]
+        return value
+`, strings.Join(lists, ", ")))
+	}
+
+	sb.WriteString(`
+
+def load_settings(env_file: str | None = ".env") -> Settings:
+    """Load the settings, raising pydantic.ValidationError for missing or invalid values."""
+    return Settings(_env_file=env_file)
+`)
+	return sb.String()
+}
+
+// javaConfig renders a Config record whose load reads System.getenv
+// before a .env file and throws a ConfigException listing every problem.
+func javaConfig(spec *specparser.SpecAnalysis, settings []configSetting) string {
+	types := configTypes["java"]
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("package %s;\n\n", toPackageName(spec.Name)))
+	imports := []string{"java.io.IOException", "java.io.UncheckedIOException", "java.nio.file.Files", "java.nio.file.Path"}
+	if usesKind(settings, "duration") {
+		imports = append(imports, "java.time.Duration")
+	}
+	imports = append(imports, "java.util.ArrayList")
+	if usesKind(settings, "list") {
+		imports = append(imports, "java.util.Arrays")
+	}
+	imports = append(imports, "java.util.HashMap", "java.util.List", "java.util.Map", "java.util.function.Function")
+	for _, imp := range imports {
+		sb.WriteString(fmt.Sprintf("import %s;\n", imp))
+	}
+
+	componentType := func(s configSetting) string {
+		typ := types[s.Kind]
+		if s.optional() {
+			if boxed, ok := javaBoxed[typ]; ok {
+				return boxed
+			}
+		}
+		return typ
+	}
+
+	sb.WriteString(fmt.Sprintf("\n/**\n * %s settings, loaded from environment variables and an optional .env file.\n *\n", spec.Name))
+	var components []string
+	for _, s := range settings {
+		sb.WriteString(fmt.Sprintf(" * @param %s %s (%s)\n", toCamelCase(s.Env), configComment("java", s), s.Env))
+		components = append(components, fmt.Sprintf("%s %s", componentType(s), toCamelCase(s.Env)))
+	}
+	sb.WriteString(" */\n")
+	sb.WriteString(fmt.Sprintf("public record Config(\n        %s) {\n\n", strings.Join(components, ",\n        ")))
+
+	sb.WriteString(`    /**
+     * Loads the settings from the environment, falling back to the
+     * KEY=value lines of the file at path. Environment variables take
+     * precedence over the file, and a missing file is ignored.
+     */
+    public static Config load(Path path) {
+        Map<String, String> file = readEnvFile(path);
+        Map<String, String> env = System.getenv();
+        return from(key -> {
+            String value = env.get(key);
+            return value == null || value.isEmpty() ? file.get(key) : value;
+        });
+    }
+
+    /**
+     * Builds the settings from a lookup function, throwing a
+     * ConfigException that lists every missing or invalid value.
+     */
+    public static Config from(Function<String, String> lookup) {
+        Loader loader = new Loader(lookup);
+`)
+	parsers := map[string]string{
+		"string": "String::valueOf", "int": "Integer::valueOf", "int64": "Long::valueOf", "float": "Double::valueOf",
+		"bool": "Config::parseBoolean", "duration": "Duration::parse", "list": "Config::parseList",
+	}
+	var args []string
+	for _, s := range settings {
+		typ := componentType(s)
+		if boxed, ok := javaBoxed[typ]; ok {
+			typ = boxed
+		}
+		def := "null"
+		if s.hasDefault() {
+			def = strconv.Quote(configValue("java", s))
+		}
+		name := toCamelCase(s.Env)
+		sb.WriteString(fmt.Sprintf("        %s %s = loader.value(%q, %s, %t, %s);\n", typ, name, s.Env, def, s.Required, parsers[s.Kind]))
+		args = append(args, name)
+	}
+	sb.WriteString(fmt.Sprintf("        loader.check();\n        return new Config(%s);\n    }\n", strings.Join(args, ", ")))
+
+	if usesKind(settings, "bool") {
+		sb.WriteString(`
+    private static Boolean parseBoolean(String raw) {
+        switch (raw.trim().toLowerCase()) {
+            case "true", "1":
+                return true;
+            case "false", "0":
+                return false;
+            default:
+                throw new IllegalArgumentException("not a boolean: " + raw);
+        }
+    }
+`)
+	}
+	if usesKind(settings, "list") {
+		sb.WriteString(`
+    private static List<String> parseList(String raw) {
+        return Arrays.stream(raw.split(",")).map(String::trim).filter(item -> !item.isEmpty()).toList();
+    }
+`)
+	}
+
+	sb.WriteString(`
+    /**
+     * Reads KEY=value lines from a .env file, skipping blank lines and
+     * comments and unquoting quoted values. A missing file reads as empty.
+     */
+    static Map<String, String> readEnvFile(Path path) {
+        Map<String, String> vars = new HashMap<>();
+        if (path == null || !Files.exists(path)) {
+            return vars;
+        }
+        try {
+            for (String entry : Files.readAllLines(path)) {
+                String line = entry.trim();
+                int eq = line.indexOf('=');
+                if (line.isEmpty() || line.startsWith("#") || eq < 0) {
+                    continue;
+                }
+                String value = line.substring(eq + 1).trim();
+                if (value.length() >= 2 && (value.charAt(0) == '"' || value.charAt(0) == '\'')
+                        && value.charAt(value.length() - 1) == value.charAt(0)) {
+                    value = value.substring(1, value.length() - 1);
+                }
+                vars.put(line.substring(0, eq).replaceFirst("^export\\s+", "").trim(), value);
+            }
+        } catch (IOException e) {
+            throw new UncheckedIOException(e);
+        }
+        return vars;
+    }
+
+    /** Lists every missing or invalid setting. */
+    public static final class ConfigException extends IllegalStateException {
+        private final List<String> problems;
+
+        ConfigException(List<String> problems) {
+            super("invalid configuration: " + String.join("; ", problems));
+            this.problems = List.copyOf(problems);
+        }
+
+        public List<String> problems() {
+            return problems;
+        }
+    }
+
+    /** Reads settings through a lookup function, collecting problems. */
+    private static final class Loader {
+        private final Function<String, String> lookup;
+        private final List<String> problems = new ArrayList<>();
+
+        Loader(Function<String, String> lookup) {
+            this.lookup = lookup;
+        }
+
+        <T> T value(String key, String fallback, boolean required, Function<String, T> parse) {
+            String raw = lookup.apply(key);
+            if (raw == null || raw.isEmpty()) {
+                raw = fallback;
+            }
+            if (raw == null) {
+                if (required) {
+                    problems.add(key + " is required");
+                }
+                return null;
+            }
+            try {
+                return parse.apply(raw.trim());
+            } catch (RuntimeException e) {
+                problems.add(key + ": invalid value \"" + raw + "\"");
+                return null;
+            }
+        }
+
+        void check() {
+            if (!problems.isEmpty()) {
+                throw new ConfigException(problems);
+            }
+        }
+    }
+}
+`)
+	return sb.String()
+}
+
+// rustConfig renders a Config struct whose load reads std::env before a
+// .env file and returns the first problem as a ConfigError.
+func rustConfig(spec *specparser.SpecAnalysis, settings []configSetting) string {
+	types := configTypes["rust"]
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("//! %s settings, loaded from environment variables and an optional .env\n//! file.\n\n", spec.Name))
+	sb.WriteString("use std::collections::HashMap;\nuse std::fmt;\n")
+	if usesKind(settings, "duration") {
+		sb.WriteString("use std::time::Duration;\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\n/// %s settings.\n#[derive(Debug, Clone, PartialEq)]\npub struct Config {\n", spec.Name))
+	for _, s := range settings {
+		typ := types[s.Kind]
+		if s.optional() {
+			typ = "Option<" + typ + ">"
+		}
+		sb.WriteString(fmt.Sprintf("    /// %s (%s)\n", configComment("rust", s), s.Env))
+		sb.WriteString(fmt.Sprintf("    pub %s: %s,\n", strings.ToLower(s.Env), typ))
+	}
+	sb.WriteString("}\n")
+
+	sb.WriteString(`
+/// A missing or invalid setting, or an unreadable .env file.
+#[derive(Debug, Clone, PartialEq)]
+pub enum ConfigError {
+    Missing(String),
+    Invalid { key: String, value: String },
+    File(String),
+}
+
+impl fmt::Display for ConfigError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            ConfigError::Missing(key) => write!(f, "{key} is required"),
+            ConfigError::Invalid { key, value } => write!(f, "{key}: invalid value {value:?}"),
+            ConfigError::File(message) => write!(f, "reading .env file: {message}"),
+        }
+    }
+}
+
+impl std::error::Error for ConfigError {}
+
+impl Config {
+    /// Loads the settings from the environment, falling back to the
+    /// KEY=value lines of the file at path. Environment variables take
+    /// precedence over the file, and a missing file is ignored.
+    pub fn load(path: &str) -> Result<Self, ConfigError> {
+        let mut vars = read_env_file(path)?;
+        vars.extend(std::env::vars().filter(|(_, value)| !value.is_empty()));
+        Self::from_vars(&vars)
+    }
+
+    /// Builds the settings from a map of variables.
+    pub fn from_vars(vars: &HashMap<String, String>) -> Result<Self, ConfigError> {
+        Ok(Self {
+`)
+	usesRequire := false
+	usesParse := usesKind(settings, "int", "int64", "float")
+	for _, s := range settings {
+		key := strconv.Quote(s.Env)
+		var convert string
+		switch s.Kind {
+		case "string":
+			convert = ""
+		case "bool":
+			convert = fmt.Sprintf("parse_bool(%s, &raw)", key)
+		case "duration":
+			convert = fmt.Sprintf("parse_duration(%s, &raw)", key)
+			usesParse = true
+		case "list":
+			convert = "split_list(&raw)"
+		default:
+			convert = fmt.Sprintf("parse(%s, &raw)", key)
+		}
+
+		var expr string
+		if s.optional() {
+			expr = fmt.Sprintf("lookup(vars, %s, None)", key)
+			switch s.Kind {
+			case "string":
+			case "list":
+				expr += fmt.Sprintf(".map(|raw| %s)", convert)
+			default:
+				expr += fmt.Sprintf(".map(|raw| %s).transpose()?", convert)
+			}
+		} else {
+			usesRequire = true
+			def := "None"
+			if s.hasDefault() {
+				def = fmt.Sprintf("Some(%s)", strconv.Quote(configValue("rust", s)))
+			}
+			raw := fmt.Sprintf("require(vars, %s, %s)?", key, def)
+			switch s.Kind {
+			case "string":
+				expr = raw
+			case "list":
+				expr = fmt.Sprintf("split_list(&%s)", raw)
+			default:
+				expr = strings.Replace(convert, "&raw", "&"+raw, 1) + "?"
+			}
+		}
+		sb.WriteString(fmt.Sprintf("            %s: %s,\n", strings.ToLower(s.Env), expr))
+	}
+	sb.WriteString("        })\n    }\n}\n")
+
+	sb.WriteString(`
+/// Returns a setting's value, or its default when it is unset or empty.
+fn lookup(vars: &HashMap<String, String>, key: &str, default: Option<&str>) -> Option<String> {
+    vars.get(key)
+        .filter(|value| !value.is_empty())
+        .cloned()
+        .or_else(|| default.map(str::to_string))
+}
+`)
+	if usesRequire {
+		sb.WriteString(`
+fn require(vars: &HashMap<String, String>, key: &str, default: Option<&str>) -> Result<String, ConfigError> {
+    lookup(vars, key, default).ok_or_else(|| ConfigError::Missing(key.to_string()))
+}
+`)
+	}
+	if usesParse {
+		sb.WriteString(`
+fn parse<T: std::str::FromStr>(key: &str, raw: &str) -> Result<T, ConfigError> {
+    raw.trim().parse().map_err(|_| ConfigError::Invalid {
+        key: key.to_string(),
+        value: raw.to_string(),
+    })
+}
+`)
+	}
+	if usesKind(settings, "bool") {
+		sb.WriteString(`
+fn parse_bool(key: &str, raw: &str) -> Result<bool, ConfigError> {
+    match raw.trim().to_ascii_lowercase().as_str() {
+        "true" | "1" => Ok(true),
+        "false" | "0" => Ok(false),
+        _ => Err(ConfigError::Invalid {
+            key: key.to_string(),
+            value: raw.to_string(),
+        }),
+    }
+}
+`)
+	}
+	if usesKind(settings, "duration") {
+		sb.WriteString(`
+fn parse_duration(key: &str, raw: &str) -> Result<Duration, ConfigError> {
+    Duration::try_from_secs_f64(parse(key, raw)?).map_err(|_| ConfigError::Invalid {
+        key: key.to_string(),
+        value: raw.to_string(),
+    })
+}
+`)
+	}
+	if usesKind(settings, "list") {
+		sb.WriteString(`
+fn split_list(raw: &str) -> Vec<String> {
+    raw.split(',')
+        .map(str::trim)
+        .filter(|item| !item.is_empty())
+        .map(str::to_string)
+        .collect()
+}
+`)
+	}
+	sb.WriteString(`
+/// Reads KEY=value lines from a .env file, skipping blank lines and
+/// comments and unquoting quoted values. A missing file reads as empty.
+fn read_env_file(path: &str) -> Result<HashMap<String, String>, ConfigError> {
+    let text = match std::fs::read_to_string(path) {
+        Ok(text) => text,
+        Err(e) if e.kind() == std::io::ErrorKind::NotFound => return Ok(HashMap::new()),
+        Err(e) => return Err(ConfigError::File(e.to_string())),
+    };
+    let mut vars = HashMap::new();
+    for line in text.lines().map(str::trim) {
+        if line.is_empty() || line.starts_with('#') {
+            continue;
+        }
+        let Some((key, value)) = line.split_once('=') else {
+            continue;
+        };
+        let key = key.trim_start_matches("export ").trim();
+        let mut value = value.trim();
+        for quote in ['"', '\''] {
+            if value.len() >= 2 && value.starts_with(quote) && value.ends_with(quote) {
+                value = &value[1..value.len() - 1];
+            }
+        }
+        vars.insert(key.to_string(), value.to_string());
+    }
+    Ok(vars)
+}
+`)
+	return sb.String()
+}
+
+// csharpConfigLiteral renders a setting's default as a C# value.
+func csharpConfigLiteral(s configSetting) string {
+	v := configValue("csharp", s)
+	switch s.Kind {
+	case "string":
+		return strconv.Quote(v)
+	case "bool":
+		if b, err := strconv.ParseBool(v); err == nil && b {
+			return "true"
+		}
+		return "false"
+	case "duration":
+		return fmt.Sprintf("TimeSpan.Parse(%q)", v)
+	case "list":
+		items := configList(v)
+		for i, item := range items {
+			items[i] = strconv.Quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return v
+}
+
+// csharpConfig renders an options class bound from IConfiguration with
+// validation on start. Keys are the environment variable names, and
+// required value types are nullable so [Required] can see them missing.
+func csharpConfig(spec *specparser.SpecAnalysis, settings []configSetting) string {
+	types := configTypes["csharp"]
+	name := toPascalCase(spec.Name) + "Options"
+	var sb strings.Builder
+
+	if usesRequiredConfig(settings) {
+		sb.WriteString("using System.ComponentModel.DataAnnotations;\n")
+	}
+	sb.WriteString("using Microsoft.Extensions.Configuration;\nusing Microsoft.Extensions.DependencyInjection;\n\n")
+	sb.WriteString(fmt.Sprintf("namespace %s\n{\n", toPascalCase(spec.Name)))
+	sb.WriteString(fmt.Sprintf("    /// <summary>%s settings, bound from configuration.</summary>\n", spec.Name))
+	sb.WriteString(fmt.Sprintf("    public sealed class %s\n    {\n", name))
+
+	var listDefaults []configSetting
+	for i, s := range settings {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		typ := types[s.Kind]
+		initializer := ""
+		switch {
+		case s.Kind == "list":
+			// The binder appends to an initialized array, so list
+			// defaults are applied after binding
+			initializer = " = [];"
+			if s.hasDefault() {
+				listDefaults = append(listDefaults, s)
+			}
+		case s.hasDefault():
+			initializer = fmt.Sprintf(" = %s;", csharpConfigLiteral(s))
+		case s.Kind == "string" && s.Required:
+			initializer = " = \"\";"
+		default:
+			typ += "?"
+		}
+		sb.WriteString(fmt.Sprintf("        /// <summary>%s</summary>\n", configComment("csharp", s)))
+		if s.Required {
+			sb.WriteString("        [Required]\n")
+			if s.Kind == "list" {
+				sb.WriteString("        [MinLength(1)]\n")
+			}
+		}
+		sb.WriteString(fmt.Sprintf("        [ConfigurationKeyName(%q)]\n", s.Env))
+		sb.WriteString(fmt.Sprintf("        public %s %s { get; set; }%s\n", typ, toPascalCase(s.Env), initializer))
+	}
+	sb.WriteString("    }\n\n")
+
+	sb.WriteString(fmt.Sprintf("    /// <summary>Registers <see cref=\"%s\"/> as validated options.</summary>\n", name))
+	sb.WriteString(fmt.Sprintf("    public static class %sExtensions\n    {\n", name))
+	sb.WriteString(`        /// <summary>
+        /// Builds configuration from an optional JSON file and environment
+        /// variables, which take precedence over the file.
+        /// </summary>
+        public static IConfiguration BuildConfiguration(string jsonFile = "appsettings.json") =>
+            new ConfigurationBuilder()
+                .AddJsonFile(Path.GetFullPath(jsonFile), optional: true)
+                .AddEnvironmentVariables()
+                .Build();
+
+`)
+	sb.WriteString(fmt.Sprintf(`        /// <summary>
+        /// Binds <see cref="%[1]s"/> from configuration and validates it
+        /// when the host starts.
+        /// </summary>
+        public static IServiceCollection Add%[1]s(this IServiceCollection services, IConfiguration configuration)
+        {
+            services.AddOptions<%[1]s>()
+                .Bind(configuration)
+`, name))
+	if len(listDefaults) > 0 {
+		sb.WriteString("                .PostConfigure(options =>\n                {\n")
+		for _, s := range listDefaults {
+			prop := toPascalCase(s.Env)
+			sb.WriteString(fmt.Sprintf("                    if (options.%s.Length == 0)\n                    {\n", prop))
+			sb.WriteString(fmt.Sprintf("                        options.%s = %s;\n                    }\n", prop, csharpConfigLiteral(s)))
+		}
+		sb.WriteString("                })\n")
+	}
+	sb.WriteString(`                .ValidateDataAnnotations()
+                .ValidateOnStart();
+            return services;
+        }
+    }
+}
+`)
+	return sb.String()
+}
+
+// usesRequiredConfig reports whether any setting is required.
+func usesRequiredConfig(settings []configSetting) bool {
+	for _, s := range settings {
+		if s.Required {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const billingSpec = "# Billing\n\n" +
+	"## Configuration\n\n" +
+	"| Variable | Type | Default | Required | Description |\n" +
+	"|----------|------|---------|----------|-------------|\n" +
+	"| PORT | int | 8080 | No | HTTP server listen port |\n" +
+	"| API_KEY | string | - | Yes | Payment provider key |\n" +
+	"| TIMEOUT | duration | 30s | No | Upstream request timeout |\n" +
+	"| ALLOWED_HOSTS | List[string] | a.com,b.com | No | Hosts allowed to call |\n\n" +
+	"- REGION: Deployment region (required)\n"
+
+func TestGenerateConfig(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(billingSpec, "billing.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected []string
+		envLines []string
+	}{
+		{"go", "config/config.go", []string{
+			"func Load(path string) (*Config, error) {",
+			"Timeout time.Duration",
+			`Port:         value(l, "PORT", "8080", false, strconv.Atoi),`,
+			`ApiKey:       value(l, "API_KEY", "", true, parseString),`,
+			`Timeout:      value(l, "TIMEOUT", "30s", false, time.ParseDuration),`,
+			"if err := errors.Join(l.errs...); err != nil {",
+		}, []string{"TIMEOUT=30s", "ALLOWED_HOSTS=a.com,b.com"}},
+		{"typescript", "src/config.ts", []string{
+			"export function loadConfig(path = \".env\", env: Env = process.env): Config {",
+			`timeout: loader.value("TIMEOUT", "30000", false, parseInteger),`,
+			`apiKey: loader.value("API_KEY", undefined, true, parseString),`,
+			"throw new ConfigError(loader.problems);",
+		}, []string{"TIMEOUT=30000"}},
+		{"python", "src/config.py", []string{
+			"class Settings(BaseSettings):",
+			`model_config = SettingsConfigDict(env_file=".env", env_ignore_empty=True, extra="ignore")`,
+			`port: int = Field(8080, description="HTTP server listen port")`,
+			`api_key: str = Field(description="Payment provider key")`,
+			"timeout: timedelta = Field(timedelta(seconds=30),",
+			"allowed_hosts: Annotated[list[str], NoDecode] = Field([\"a.com\", \"b.com\"],",
+		}, []string{"TIMEOUT=30"}},
+		{"java", "src/main/java/billing/Config.java", []string{
+			"public record Config(",
+			`Duration timeout = loader.value("TIMEOUT", "PT30S", false, Duration::parse);`,
+			"return new Config(port, apiKey, timeout, allowedHosts, region);",
+		}, []string{"TIMEOUT=PT30S"}},
+		{"rust", "src/config.rs", []string{
+			"pub fn load(path: &str) -> Result<Self, ConfigError> {",
+			`api_key: require(vars, "API_KEY", None)?,`,
+			`timeout: parse_duration("TIMEOUT", &require(vars, "TIMEOUT", Some("30"))?)?,`,
+		}, []string{"TIMEOUT=30"}},
+		{"csharp", "src/BillingOptions.cs", []string{
+			"public sealed class BillingOptions",
+			"[Required]\n        [ConfigurationKeyName(\"API_KEY\")]",
+			`public TimeSpan Timeout { get; set; } = TimeSpan.Parse("00:00:30");`,
+			".ValidateDataAnnotations()",
+		}, []string{"TIMEOUT=00:00:30", "ALLOWED_HOSTS__1=b.com"}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			files := generatedFiles(t, gen, spec, tt.language)
+			expectGenerated(t, files, map[string][]string{tt.path: tt.expected})
+
+			for _, want := range append(tt.envLines, "API_KEY=", "# Payment provider key (string, required)") {
+				if !strings.Contains(files[".env.example"], want+"\n") {
+					t.Errorf("Expected .env.example to contain %q, got:\n%s", want, files[".env.example"])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigBuilds(t *testing.T) {
+	checkBuilds(t, billingSpec, "go", "python")
+}
@@ -0,0 +1,396 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// catalogError is an entry in a project's error catalog: one declared error
+// shared by every function whose error conditions name it.
+type catalogError struct {
+	// Name is the error's PascalCase name without a suffix (e.g., "NotFound")
+	Name string
+
+	// Message is the error's default message
+	Message string
+
+	// Description explains when the error occurs
+	Description string
+}
+
+// specErrorName names a declared error after its type, or its condition
+// when it has none, as PascalCase without an Error/Exception suffix or a Go
+// style "Err" prefix.
+func specErrorName(e specparser.SpecError) string {
+	name := e.Type
+	if name == "" {
+		name = e.Condition
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return ' '
+	}, name)
+	name = toPascalCase(strings.Join(strings.Fields(name), " "))
+	if rest := strings.TrimPrefix(name, "Err"); rest != name && rest != "" && unicode.IsUpper(rune(rest[0])) {
+		name = rest
+	}
+	return errorClass(name, "")
+}
+
+// functionErrors returns a function's declared errors, once each, described
+// by the function's own conditions.
+func functionErrors(f specparser.SpecFunction) []catalogError {
+	var errs []catalogError
+	seen := make(map[string]bool)
+	for _, e := range f.Errors {
+		name := specErrorName(e)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		message := e.Message
+		if message == "" {
+			message = errorMessage(name)
+		}
+		description := strings.TrimSuffix(strings.TrimSpace(e.Condition), ".")
+		errs = append(errs, catalogError{Name: name, Message: message, Description: description})
+	}
+	return errs
+}
+
+// errorCatalog collects the errors the spec's functions declare, in order of
+// first declaration. The first message and description given win.
+func errorCatalog(spec *specparser.SpecAnalysis) []catalogError {
+	var catalog []catalogError
+	known := make(map[string]int)
+	for _, f := range spec.Functions {
+		for _, e := range functionErrors(f) {
+			if i, ok := known[e.Name]; ok {
+				if catalog[i].Description == "" {
+					catalog[i].Description = e.Description
+				}
+				continue
+			}
+			known[e.Name] = len(catalog)
+			catalog = append(catalog, e)
+		}
+	}
+
+	// Prefer messages written in the spec over derived ones
+	for _, f := range spec.Functions {
+		for _, e := range f.Errors {
+			if i, ok := known[specErrorName(e)]; ok && e.Message != "" && catalog[i].Message == errorMessage(catalog[i].Name) {
+				catalog[i].Message = e.Message
+			}
+		}
+	}
+	return catalog
+}
+
+// generateErrors generates the project's error catalog from the functions'
+// error conditions: sentinel errors and an *OpError for Go, Error subclasses
+// and a discriminated union for TypeScript, exception hierarchies for
+// Python, Java and C#, and a thiserror enum for Rust.
+func (g *Generator) generateErrors(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	catalog := errorCatalog(spec)
+	if len(catalog) == 0 {
+		return nil
+	}
+
+	var elements []string
+	for _, e := range catalog {
+		elements = append(elements, e.Name)
+	}
+	file := func(path, content string) GeneratedFile {
+		return GeneratedFile{Path: path, Content: content, Category: "error", Elements: elements}
+	}
+
+	switch lang := adapter.GetLanguage(); lang.ID {
+	case "go":
+		return []GeneratedFile{file("errors.go", goErrors(spec, catalog))}
+	case "typescript":
+		return []GeneratedFile{file("src/errors.ts", typeScriptErrors(catalog))}
+	case "python":
+		return []GeneratedFile{file("src/errors.py", pythonErrors(catalog))}
+	case "java":
+		pkg := toPackageName(spec.Name)
+		files := []GeneratedFile{file(fmt.Sprintf("src/main/java/%s/ServiceException.java", pkg), javaServiceException(pkg))}
+		for _, e := range catalog {
+			class := errorClass(e.Name, "Exception")
+			files = append(files, file(fmt.Sprintf("src/main/java/%s/%s.java", pkg, class), javaException(pkg, e)))
+		}
+		return files
+	case "rust":
+		return []GeneratedFile{file("src/error.rs", rustErrors(catalog))}
+	case "csharp":
+		return []GeneratedFile{file("src/Errors.cs", csharpErrors(spec, catalog))}
+	}
+	return nil
+}
+
+// thrownWhen describes when an error is thrown, for doc comments, after a
+// verb such as "returned" or none at all.
+func thrownWhen(verb string, e catalogError) string {
+	when := fmt.Sprintf("when %s", lowerFirst(e.Description))
+	if e.Description == "" {
+		when = fmt.Sprintf("for a %s error", errorMessage(e.Name))
+	}
+	return strings.TrimSpace(verb + " " + when)
+}
+
+// goErrors renders the Go sentinel errors and the *OpError that wraps them.
+func goErrors(spec *specparser.SpecAnalysis, catalog []catalogError) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("package %s\n\nimport \"errors\"\n\n", toPackageName(spec.Name)))
+
+	sb.WriteString("// Declared errors\nvar (\n")
+	for i, e := range catalog {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("\t// Err%s is %s\n", e.Name, thrownWhen("returned", e)))
+		sb.WriteString(fmt.Sprintf("\tErr%s = errors.New(%q)\n", e.Name, e.Message))
+	}
+	sb.WriteString(")\n\n")
+
+	sb.WriteString(`// OpError records the function that returned a declared error, with
+// optional detail. errors.Is matches it against the declared error.
+type OpError struct {
+	// Op is the name of the function that failed
+	Op string
+
+	// Err is the declared error
+	Err error
+
+	// Detail adds context to the error's message, if any
+	Detail string
+}
+
+// Error returns the function name, the declared error's message and the
+// detail.
+func (e *OpError) Error() string {
+	msg := e.Op + ": " + e.Err.Error()
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap returns the declared error.
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+`)
+	return sb.String()
+}
+
+// typeScriptErrors renders an Error subclass per declared error and a union
+// of them discriminated by code.
+func typeScriptErrors(catalog []catalogError) string {
+	var sb strings.Builder
+	sb.WriteString(`/** Base class for the errors service functions declare. */
+export abstract class ServiceError extends Error {
+  abstract readonly code: string;
+
+  constructor(message: string) {
+    super(message);
+    this.name = new.target.name;
+  }
+}
+
+`)
+	var classes []string
+	for _, e := range catalog {
+		class := errorClass(e.Name, "Error")
+		classes = append(classes, class)
+		sb.WriteString(fmt.Sprintf("/** %s */\n", thrownWhen("Thrown", e)))
+		sb.WriteString(fmt.Sprintf("export class %s extends ServiceError {\n", class))
+		sb.WriteString(fmt.Sprintf("  readonly code = %q;\n\n", e.Name))
+		sb.WriteString(fmt.Sprintf("  constructor(message = %q) {\n    super(message);\n  }\n}\n\n", e.Message))
+	}
+	sb.WriteString("/** Any declared error, discriminated by its code. */\n")
+	sb.WriteString(fmt.Sprintf("export type DeclaredError = %s;\n", strings.Join(classes, " | ")))
+	return sb.String()
+}
+
+// pythonErrors renders an exception class per declared error under a
+// common ServiceError.
+func pythonErrors(catalog []catalogError) string {
+	var sb strings.Builder
+	sb.WriteString(`"""Errors the service functions declare."""
+
+
+class ServiceError(Exception):
+    """Base class for the errors service functions declare."""
+
+    code = "ServiceError"
+    default_message = "service error"
+
+    def __init__(self, message: str | None = None) -> None:
+        super().__init__(message or self.default_message)
+`)
+	for _, e := range catalog {
+		sb.WriteString(fmt.Sprintf("\n\nclass %s(ServiceError):\n", errorClass(e.Name, "Error")))
+		sb.WriteString(fmt.Sprintf("    \"\"\"%s.\"\"\"\n\n", thrownWhen("Raised", e)))
+		sb.WriteString(fmt.Sprintf("    code = %q\n    default_message = %q\n", e.Name, e.Message))
+	}
+	return sb.String()
+}
+
+// javaServiceException renders the base class of the declared exceptions.
+func javaServiceException(pkg string) string {
+	return fmt.Sprintf(`package %s;
+
+/**
+ * Base class for the exceptions service methods declare.
+ */
+public abstract class ServiceException extends RuntimeException {
+    private final String code;
+
+    protected ServiceException(String code, String message) {
+        super(message);
+        this.code = code;
+    }
+
+    public String getCode() {
+        return code;
+    }
+}
+`, pkg)
+}
+
+// javaException renders one declared exception.
+func javaException(pkg string, e catalogError) string {
+	class := errorClass(e.Name, "Exception")
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("package %s;\n\n", pkg))
+	sb.WriteString(fmt.Sprintf("/**\n * %s.\n */\n", thrownWhen("Thrown", e)))
+	sb.WriteString(fmt.Sprintf("public class %s extends ServiceException {\n", class))
+	sb.WriteString(fmt.Sprintf("    public %s() {\n        this(%q);\n    }\n\n", class, e.Message))
+	sb.WriteString(fmt.Sprintf("    public %s(String message) {\n        super(%q, message);\n    }\n}\n", class, e.Name))
+	return sb.String()
+}
+
+// rustErrors renders the declared errors as a thiserror enum.
+func rustErrors(catalog []catalogError) string {
+	var sb strings.Builder
+	sb.WriteString("//! Errors the service functions declare.\n\n")
+	sb.WriteString("/// A declared error.\n#[derive(Debug, thiserror::Error)]\npub enum Error {\n")
+	for i, e := range catalog {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("    /// %s\n", thrownWhen("Returned", e)))
+		message := strings.NewReplacer("{", "{{", "}", "}}").Replace(e.Message)
+		sb.WriteString(fmt.Sprintf("    #[error(%q)]\n    %s,\n", message, e.Name))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// csharpErrors renders an exception class per declared error under a
+// common ServiceException.
+func csharpErrors(spec *specparser.SpecAnalysis, catalog []catalogError) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("using System;\n\nnamespace %s\n{\n", toPascalCase(spec.Name)))
+	sb.WriteString(`    /// <summary>
+    /// Base class for the exceptions service methods declare.
+    /// </summary>
+    public abstract class ServiceException : Exception
+    {
+        protected ServiceException(string code, string message) : base(message)
+        {
+            Code = code;
+        }
+
+        public string Code { get; }
+    }
+`)
+	for _, e := range catalog {
+		class := errorClass(e.Name, "Exception")
+		sb.WriteString(fmt.Sprintf("\n    /// <summary>\n    /// %s.\n    /// </summary>\n", thrownWhen("Thrown", e)))
+		sb.WriteString(fmt.Sprintf("    public class %s : ServiceException\n    {\n", class))
+		sb.WriteString(fmt.Sprintf("        public %s(string message = %q) : base(%q, message) { }\n    }\n", class, e.Message, e.Name))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// rustWrapsErrors reports whether a Rust function returns its declared
+// errors in a Result it adds, rather than a Result the spec already gives.
+func rustWrapsErrors(f specparser.SpecFunction) bool {
+	if len(functionErrors(f)) == 0 {
+		return false
+	}
+	return len(f.Returns) == 0 || !strings.HasPrefix(mapType(f.Returns[0].Type, "rust"), "Result<")
+}
+
+// rustReturnType returns a Rust function's return type, or "" for unit.
+func rustReturnType(f specparser.SpecFunction) string {
+	returnType := ""
+	if len(f.Returns) > 0 {
+		returnType = mapType(f.Returns[0].Type, "rust")
+	}
+	if !rustWrapsErrors(f) {
+		return returnType
+	}
+	if returnType == "" {
+		returnType = "()"
+	}
+	return fmt.Sprintf("Result<%s, Error>", returnType)
+}
+
+// errorDocs returns the lines documenting a function's declared errors in
+// its doc comment, without comment markers.
+func errorDocs(f specparser.SpecFunction, langID string) []string {
+	errs := functionErrors(f)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var lines []string
+	switch langID {
+	case "go":
+		lines = append(lines, "Errors:")
+		for _, e := range errs {
+			lines = append(lines, fmt.Sprintf("  - Err%s %s", e.Name, thrownWhen("is returned", e)))
+		}
+	case "typescript":
+		for _, e := range errs {
+			lines = append(lines, fmt.Sprintf("@throws {%s} %s", errorClass(e.Name, "Error"), upperFirst(thrownWhen("", e))))
+		}
+	case "python":
+		lines = append(lines, "Raises:")
+		for _, e := range errs {
+			lines = append(lines, fmt.Sprintf("    %s: %s.", errorClass(e.Name, "Error"), upperFirst(thrownWhen("", e))))
+		}
+	case "java":
+		for _, e := range errs {
+			lines = append(lines, fmt.Sprintf("@throws %s %s", errorClass(e.Name, "Exception"), thrownWhen("", e)))
+		}
+	case "rust":
+		lines = append(lines, "# Errors", "")
+		for _, e := range errs {
+			lines = append(lines, fmt.Sprintf("- [`Error::%s`] %s", e.Name, thrownWhen("is returned", e)))
+		}
+	case "csharp":
+		for _, e := range errs {
+			lines = append(lines, fmt.Sprintf("<exception cref=%q>%s.</exception>", errorClass(e.Name, "Exception"), thrownWhen("Thrown", e)))
+		}
+	}
+	return lines
+}
+
+// upperFirst upper-cases the first letter of a string.
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const accountsSpec = "# Accounts\n\n" +
+	"## Types\n\n" +
+	"### User (struct)\n\n" +
+	"- id: string - Identifier\n\n" +
+	"## Functions\n\n" +
+	"### getUser\n\n" +
+	"Fetches a user.\n\n" +
+	"**Parameters**\n- `id`: `string`\n\n" +
+	"**Returns** `User`\n\n" +
+	"**Errors**\n" +
+	"- `NotFound`: no user has the id - \"user not found\"\n" +
+	"- `ErrInvalidId`: the id is malformed\n\n" +
+	"### deleteUser\n\n" +
+	"**Parameters**\n- `id`: `string`\n\n" +
+	"**Errors**\n\n" +
+	"| Error | Condition | Message |\n" +
+	"|-------|-----------|---------|\n" +
+	"| NotFound | no user has the id | |\n" +
+	"| Forbidden | the caller may not delete users | |\n"
+
+func TestErrorCatalog(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(accountsSpec, "accounts.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	expected := []catalogError{
+		{Name: "NotFound", Message: "user not found", Description: "no user has the id"},
+		{Name: "InvalidId", Message: "invalid id", Description: "the id is malformed"},
+		{Name: "Forbidden", Message: "forbidden", Description: "the caller may not delete users"},
+	}
+	if got := errorCatalog(spec); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected catalog %+v, got %+v", expected, got)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(accountsSpec, "accounts.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		files    map[string][]string
+	}{
+		{"go", map[string][]string{
+			"errors.go": {
+				"\t// ErrNotFound is returned when no user has the id\n\tErrNotFound = errors.New(\"user not found\")",
+				"func (e *OpError) Unwrap() error {",
+			},
+			"service.go": {
+				"//\n// Errors:\n//   - ErrNotFound is returned when no user has the id\n",
+				"func deleteUser(id string) error {\n\t// TODO: Implement\n\treturn nil\n}",
+			},
+		}},
+		{"typescript", map[string][]string{
+			"src/errors.ts": {
+				"export class NotFoundError extends ServiceError {\n  readonly code = \"NotFound\";",
+				"export type DeclaredError = NotFoundError | InvalidIdError | ForbiddenError;",
+			},
+			"src/service.ts": {" * @throws {InvalidIdError} When the id is malformed\n"},
+		}},
+		{"python", map[string][]string{
+			"src/errors.py":  {"class ForbiddenError(ServiceError):", `default_message = "user not found"`},
+			"src/service.py": {"    Raises:\n        NotFoundError: When no user has the id.\n"},
+		}},
+		{"java", map[string][]string{
+			"src/main/java/accounts/ServiceException.java":   {"public abstract class ServiceException extends RuntimeException {"},
+			"src/main/java/accounts/InvalidIdException.java": {"super(\"InvalidId\", message);"},
+			"src/main/java/accounts/Service.java":            {"throws NotFoundException, ForbiddenException {"},
+		}},
+		{"rust", map[string][]string{
+			"src/error.rs":   {"#[derive(Debug, thiserror::Error)]", "    #[error(\"user not found\")]\n    NotFound,"},
+			"src/service.rs": {"use crate::error::Error;", "-> Result<User, Error> {", "-> Result<(), Error> {"},
+			"src/lib.rs":     {"pub mod error;"},
+			"Cargo.toml":     {`thiserror = "1"`},
+		}},
+		{"csharp", map[string][]string{
+			"src/Errors.cs":  {"public class NotFoundException : ServiceException", `public NotFoundException(string message = "user not found") : base("NotFound", message) { }`},
+			"src/Service.cs": {`<exception cref="ForbiddenException">Thrown when the caller may not delete users.</exception>`},
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), tt.files)
+		})
+	}
+}
+
+func TestErrorsBuild(t *testing.T) {
+	checkBuilds(t, accountsSpec, "go", "python")
+}
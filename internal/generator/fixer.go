@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/layout"
 	"github.com/kon1790/rpg/internal/parity"
 	"github.com/kon1790/rpg/internal/specparser"
 )
@@ -91,92 +92,85 @@ func (f *Fixer) fixTypeGaps(gaps []parity.ParityGap, spec *specparser.SpecAnalys
 	}
 
 	// Determine target file path
-	var targetPath string
+	var typesFile string
 	switch lang.ID {
 	case "go":
-		targetPath = filepath.Join(outputDir, "types.go")
+		typesFile = "types.go"
 	case "typescript":
-		targetPath = filepath.Join(outputDir, "src", "types.ts")
+		typesFile = "src/types.ts"
 	case "python":
-		targetPath = filepath.Join(outputDir, "src", "types.py")
+		typesFile = "src/types.py"
 	case "java":
-		targetPath = filepath.Join(outputDir, "src", "main", "java", toPackageName(spec.Name), "Types.java")
+		typesFile = fmt.Sprintf("src/main/java/%s/Types.java", toPackageName(spec.Name))
 	case "rust":
-		targetPath = filepath.Join(outputDir, "src", "types.rs")
+		typesFile = "src/types.rs"
 	case "csharp":
-		targetPath = filepath.Join(outputDir, "src", "Types.cs")
+		typesFile = "src/Types.cs"
 	default:
 		return modifiedFiles, fixed
 	}
 
-	// Read existing file content
-	existingContent, _ := os.ReadFile(targetPath)
-
-	// Generate new type definitions
-	var newTypes strings.Builder
-	for _, t := range typesToFix {
-		// Check if type already exists in file
-		if strings.Contains(string(existingContent), t.Name) {
+	// Each module's types go in its own file
+	for _, group := range specModules(&specparser.SpecAnalysis{Types: typesToFix}) {
+		if len(group.Types) == 0 {
 			continue
 		}
+		targetPath := filepath.Join(outputDir, filepath.FromSlash(layout.Path(lang.ID, group.Module, typesFile)))
+
+		// Read existing file content
+		existingContent, _ := os.ReadFile(targetPath)
+
+		// Generate new type definitions
+		var newTypes strings.Builder
+		var missing []specparser.SpecType
+		for _, t := range group.Types {
+			// Check if type already exists in file
+			if strings.Contains(string(existingContent), t.Name) {
+				continue
+			}
 
-		typeCode := f.generator.generateType(t, lang)
-		newTypes.WriteString(typeCode)
-		newTypes.WriteString("\n")
-		fixed++
-	}
+			typeCode := f.generator.generateType(t, spec.Types, lang)
+			newTypes.WriteString(wrapRegion(lang.ID, "type", t.Name, hashOf(t), typeCode))
+			newTypes.WriteString("\n")
+			missing = append(missing, t)
+		}
 
-	if newTypes.Len() == 0 {
-		return modifiedFiles, fixed
-	}
+		if newTypes.Len() == 0 {
+			continue
+		}
 
-	// Append to file or create new
-	var finalContent string
-	if len(existingContent) > 0 {
-		// Find appropriate place to insert (before closing brace for C#)
-		content := string(existingContent)
-		if lang.ID == "csharp" && strings.Contains(content, "}") {
-			// Insert before last closing brace
-			lastBrace := strings.LastIndex(content, "}")
-			finalContent = content[:lastBrace] + newTypes.String() + content[lastBrace:]
+		// Append to file or create new
+		var finalContent string
+		if len(existingContent) > 0 {
+			// Find appropriate place to insert (before closing brace for C#)
+			content := string(existingContent)
+			if lang.ID == "csharp" && strings.Contains(content, "}") {
+				// Insert before last closing brace
+				lastBrace := strings.LastIndex(content, "}")
+				finalContent = content[:lastBrace] + newTypes.String() + content[lastBrace:]
+			} else {
+				// Append at end
+				finalContent = content + "\n" + newTypes.String()
+			}
 		} else {
-			// Append at end
-			finalContent = content + "\n" + newTypes.String()
-		}
-	} else {
-		// Create new file with proper header
-		var header strings.Builder
-		switch lang.ID {
-		case "go":
-			header.WriteString(fmt.Sprintf("package %s\n\n", toPackageName(spec.Name)))
-		case "typescript":
-			header.WriteString("// Type definitions\n\n")
-		case "python":
-			header.WriteString("from dataclasses import dataclass\nfrom typing import Optional, List, Dict, Any\n\n")
-		case "java":
-			header.WriteString(fmt.Sprintf("package %s;\n\n", toPackageName(spec.Name)))
-		case "rust":
-			header.WriteString("use serde::{Deserialize, Serialize};\n\n")
-		case "csharp":
-			header.WriteString(fmt.Sprintf("namespace %s\n{\n", toPascalCase(spec.Name)))
+			// Create new file with the module's header and imports
+			group.Types = missing
+			finalContent = f.generator.generateModuleTypes(spec, group, lang).Content
 		}
-		finalContent = header.String() + newTypes.String()
-		if lang.ID == "csharp" {
-			finalContent += "}\n"
+
+		// Ensure directory exists
+		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+			continue
 		}
-	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-		return modifiedFiles, 0
-	}
+		// Write file
+		if err := os.WriteFile(targetPath, []byte(finalContent), 0644); err != nil {
+			continue
+		}
 
-	// Write file
-	if err := os.WriteFile(targetPath, []byte(finalContent), 0644); err != nil {
-		return modifiedFiles, 0
+		fixed += len(missing)
+		modifiedFiles = append(modifiedFiles, targetPath)
 	}
-
-	modifiedFiles = append(modifiedFiles, targetPath)
 	return modifiedFiles, fixed
 }
 
@@ -204,100 +198,91 @@ func (f *Fixer) fixBehavioralGaps(gaps []parity.ParityGap, spec *specparser.Spec
 	}
 
 	// Determine target file path
-	var targetPath string
+	var serviceFile string
 	switch lang.ID {
 	case "go":
-		targetPath = filepath.Join(outputDir, "service.go")
+		serviceFile = "service.go"
 	case "typescript":
-		targetPath = filepath.Join(outputDir, "src", "service.ts")
+		serviceFile = "src/service.ts"
 	case "python":
-		targetPath = filepath.Join(outputDir, "src", "service.py")
+		serviceFile = "src/service.py"
 	case "java":
-		targetPath = filepath.Join(outputDir, "src", "main", "java", toPackageName(spec.Name), "Service.java")
+		serviceFile = fmt.Sprintf("src/main/java/%s/Service.java", toPackageName(spec.Name))
 	case "rust":
-		targetPath = filepath.Join(outputDir, "src", "service.rs")
+		serviceFile = "src/service.rs"
 	case "csharp":
-		targetPath = filepath.Join(outputDir, "src", "Service.cs")
+		serviceFile = "src/Service.cs"
 	default:
 		return modifiedFiles, fixed
 	}
 
-	// Read existing file content
-	existingContent, _ := os.ReadFile(targetPath)
-
-	// Generate new function definitions
-	var newFuncs strings.Builder
-	for _, fn := range funcsToFix {
-		// Check if function already exists in file
-		if strings.Contains(string(existingContent), fn.Name) {
+	// Each module's functions go in its own file
+	for _, group := range specModules(&specparser.SpecAnalysis{Functions: funcsToFix}) {
+		if len(group.Functions) == 0 {
 			continue
 		}
+		targetPath := filepath.Join(outputDir, filepath.FromSlash(layout.Path(lang.ID, group.Module, serviceFile)))
+
+		// Read existing file content
+		existingContent, _ := os.ReadFile(targetPath)
+
+		// Generate new function definitions
+		var newFuncs strings.Builder
+		var missing []specparser.SpecFunction
+		for _, fn := range group.Functions {
+			// Check if function already exists in file
+			if strings.Contains(string(existingContent), fn.Name) {
+				continue
+			}
 
-		funcCode := f.generator.generateFunction(fn, lang)
-		newFuncs.WriteString(funcCode)
-		newFuncs.WriteString("\n")
-		fixed++
-	}
+			funcCode := f.generator.generateFunction(fn, spec.Types, lang)
+			newFuncs.WriteString(wrapRegion(lang.ID, "function", fn.Name, hashOf(fn), funcCode))
+			newFuncs.WriteString("\n")
+			missing = append(missing, fn)
+		}
 
-	if newFuncs.Len() == 0 {
-		return modifiedFiles, fixed
-	}
+		if newFuncs.Len() == 0 {
+			continue
+		}
 
-	// Append to file or create new
-	var finalContent string
-	if len(existingContent) > 0 {
-		content := string(existingContent)
-		// For languages with class/namespace wrappers, insert before closing brace
-		if (lang.ID == "java" || lang.ID == "csharp") && strings.Contains(content, "}") {
-			// Find the appropriate place to insert (before last closing brace)
-			lastBrace := strings.LastIndex(content, "}")
-			if lang.ID == "csharp" {
-				// C# has nested braces
-				secondLastBrace := strings.LastIndex(content[:lastBrace], "}")
-				if secondLastBrace > 0 {
-					lastBrace = secondLastBrace
+		// Append to file or create new
+		var finalContent string
+		if len(existingContent) > 0 {
+			content := string(existingContent)
+			// For languages with class/namespace wrappers, insert before closing brace
+			if (lang.ID == "java" || lang.ID == "csharp") && strings.Contains(content, "}") {
+				// Find the appropriate place to insert (before last closing brace)
+				lastBrace := strings.LastIndex(content, "}")
+				if lang.ID == "csharp" {
+					// C# has nested braces
+					secondLastBrace := strings.LastIndex(content[:lastBrace], "}")
+					if secondLastBrace > 0 {
+						lastBrace = secondLastBrace
+					}
 				}
+				finalContent = content[:lastBrace] + newFuncs.String() + content[lastBrace:]
+			} else {
+				finalContent = content + "\n" + newFuncs.String()
 			}
-			finalContent = content[:lastBrace] + newFuncs.String() + content[lastBrace:]
 		} else {
-			finalContent = content + "\n" + newFuncs.String()
-		}
-	} else {
-		// Create new file with proper header
-		var header strings.Builder
-		switch lang.ID {
-		case "go":
-			header.WriteString(fmt.Sprintf("package %s\n\n", toPackageName(spec.Name)))
-		case "typescript":
-			header.WriteString("// Functions\n\n")
-		case "python":
-			header.WriteString("from typing import Optional, Any\n\n")
-		case "java":
-			header.WriteString(fmt.Sprintf("package %s;\n\npublic class Service {\n", toPackageName(spec.Name)))
-		case "rust":
-			header.WriteString("use crate::types::*;\n\n")
-		case "csharp":
-			header.WriteString(fmt.Sprintf("namespace %s\n{\n    public static class Service\n    {\n", toPascalCase(spec.Name)))
+			// Create new file with the module's header and imports
+			group.Functions = missing
+			finalContent = f.generator.generateModuleFunctions(spec, group, lang).Content
 		}
-		finalContent = header.String() + newFuncs.String()
-		if lang.ID == "java" {
-			finalContent += "}\n"
-		} else if lang.ID == "csharp" {
-			finalContent += "    }\n}\n"
+
+		// Ensure directory exists
+		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+			continue
 		}
-	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-		return modifiedFiles, 0
-	}
+		// Write file
+		if err := os.WriteFile(targetPath, []byte(finalContent), 0644); err != nil {
+			continue
+		}
 
-	// Write file
-	if err := os.WriteFile(targetPath, []byte(finalContent), 0644); err != nil {
-		return modifiedFiles, 0
+		fixed += len(missing)
+		modifiedFiles = append(modifiedFiles, targetPath)
 	}
-
-	modifiedFiles = append(modifiedFiles, targetPath)
 	return modifiedFiles, fixed
 }
 
@@ -352,7 +337,7 @@ func (f *Fixer) fixTestGaps(gaps []parity.ParityGap, spec *specparser.SpecAnalys
 		}
 
 		testCode := f.generator.generateTest(t, lang)
-		newTests.WriteString(testCode)
+		newTests.WriteString(wrapRegion(lang.ID, "test", t.Name, hashOf(t), testCode))
 		newTests.WriteString("\n")
 		fixed++
 	}
@@ -4,24 +4,44 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/layout"
 	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
 )
 
 // Generator handles code generation from spec analysis.
 type Generator struct {
-	registry *languages.Registry
+	registry  *languages.Registry
+	templates *Templates
+
+	// renderErr is the first template failure of the current build
+	renderErr error
 }
 
 // NewGenerator creates a new code generator.
 func NewGenerator(registry *languages.Registry) *Generator {
 	return &Generator{
-		registry: registry,
+		registry:  registry,
+		templates: DefaultTemplates(),
 	}
 }
 
+// UseTemplates renders with the templates in dir in place of the defaults
+// (see LoadTemplates).
+func (g *Generator) UseTemplates(dir string) error {
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		return err
+	}
+	g.templates = templates
+	return nil
+}
+
 // Generate generates code files from a spec analysis for the target language.
 func (g *Generator) Generate(spec *specparser.SpecAnalysis, language, outputDir string) ([]GeneratedFile, error) {
 	adapter, err := g.registry.Get(language)
@@ -29,33 +49,31 @@ func (g *Generator) Generate(spec *specparser.SpecAnalysis, language, outputDir
 		return nil, fmt.Errorf("unsupported language %s: %w", language, err)
 	}
 
-	var files []GeneratedFile
-
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Get project structure
-	projectFiles := adapter.GetProjectStructure(spec.Name, len(spec.Tests) > 0)
-
-	// Generate types
-	typeFiles := g.generateTypes(spec, adapter, outputDir)
-	files = append(files, typeFiles...)
-
-	// Generate functions (grouped by receiver/module)
-	funcFiles := g.generateFunctions(spec, adapter, outputDir)
-	files = append(files, funcFiles...)
+	// Names that clash once converted would generate code that does not compile
+	if err := identifierCollisions(spec, adapter.GetLanguage().ID); err != nil {
+		return nil, err
+	}
 
-	// Generate tests
-	if len(spec.Tests) > 0 {
-		testFiles := g.generateTests(spec, adapter, outputDir)
-		files = append(files, testFiles...)
+	files := g.build(spec, adapter)
+	if g.renderErr != nil {
+		return nil, g.renderErr
 	}
 
-	// Generate project files (go.mod, package.json, etc.)
-	projFiles := g.generateProjectFiles(spec, adapter, projectFiles, outputDir)
-	files = append(files, projFiles...)
+	// Check every file parses in its language before writing any, so broken
+	// code never lands on disk or becomes the base of a later merge
+	for i, f := range files {
+		if len(f.Diagnostics) == 0 {
+			files[i].Diagnostics = checkSyntax(f.Path, f.Content)
+		}
+	}
+	if err := syntaxError(files); err != nil {
+		return files, err
+	}
 
 	// Write all files
 	for i, f := range files {
@@ -70,50 +88,224 @@ func (g *Generator) Generate(spec *specparser.SpecAnalysis, language, outputDir
 			continue
 		}
 		files[i].Size = len(f.Content)
+
+		// Record the generated version as the base for later regeneration
+		_ = writeSnapshot(outputDir, f.Path, f.Content)
 	}
 
 	return files, nil
 }
 
-// generateTypes generates type definition files.
-func (g *Generator) generateTypes(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter, outputDir string) []GeneratedFile {
-	if len(spec.Types) == 0 {
-		return nil
+// build generates all files for a spec in memory, stamping each with a
+// header hash of its content.
+func (g *Generator) build(specified *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	var files []GeneratedFile
+	g.renderErr = nil
+
+	// Name the types by their identifiers, so "Order Item" is OrderItem
+	spec := withTypeIdents(specified, adapter.GetLanguage().ID)
+
+	// Get project structure
+	projectFiles := adapter.GetProjectStructure(spec.Name, len(spec.Tests) > 0)
+
+	// Generate types
+	files = append(files, g.generateTypes(spec, adapter)...)
+
+	// Generate the error catalog the functions declare
+	files = append(files, g.generateErrors(spec, adapter)...)
+
+	// Generate the state machines and their transitions
+	files = append(files, g.generateStateMachines(spec, adapter)...)
+
+	// Generate repositories and migrations for the tables
+	files = append(files, g.generatePersistence(spec, adapter)...)
+
+	// Generate functions (grouped by receiver/module)
+	files = append(files, g.generateFunctions(spec, adapter)...)
+
+	// Generate HTTP routing and handler stubs
+	files = append(files, g.generateRoutes(spec, adapter)...)
+
+	// Generate a typed HTTP client for the endpoints
+	files = append(files, g.generateClient(spec, adapter)...)
+
+	// Generate the command line wired to the functions
+	files = append(files, g.generateCommands(spec, adapter)...)
+
+	// Generate tests
+	if len(spec.Tests) > 0 {
+		files = append(files, g.generateTests(spec, adapter)...)
 	}
 
-	lang := adapter.GetLanguage()
+	// Generate test doubles for the interfaces
+	files = append(files, g.generateMocks(spec, adapter)...)
+
+	// Generate property-based tests for the spec's invariants
+	files = append(files, g.generateProperties(spec, adapter)...)
+
+	// Generate serialization round-trip tests for the types
+	files = append(files, g.generateSerializationTests(spec, adapter)...)
+
+	// Generate the config loader and its .env.example
+	files = append(files, g.generateConfig(spec, adapter)...)
+
+	// Generate project files (go.mod, package.json, etc.)
+	files = append(files, g.generateProjectFiles(spec, adapter, projectFiles)...)
+
+	// Record the names escaped to legal identifiers for parity matching
+	files = append(files, g.generateIdentifierMap(specified, adapter.GetLanguage().ID)...)
+
+	for i := range files {
+		// Print Go through its syntax tree; a file that does not parse
+		// keeps its diagnostics and is never written
+		if isGoSource(files[i].Path) {
+			files[i].Content, files[i].Diagnostics = formatGo(files[i].Path, files[i].Content)
+		}
+		files[i].Content = stampHeader(files[i].Path, files[i].Content)
+	}
+
+	return files
+}
+
+// generateTypes generates type definition files, one per module.
+func (g *Generator) generateTypes(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
 	var files []GeneratedFile
+	for _, group := range specModules(spec) {
+		if len(group.Types) > 0 {
+			files = append(files, g.generateModuleTypes(spec, group, adapter.GetLanguage()))
+		}
+	}
+	return files
+}
 
-	// Group types or generate one file depending on language
-	var content strings.Builder
+// generateModuleTypes generates the type definition file of one module.
+// The root module's file is at the language's usual path; other modules'
+// files go in their directories and import the types they use from other
+// modules.
+func (g *Generator) generateModuleTypes(spec *specparser.SpecAnalysis, group moduleGroup, lang languages.Language) GeneratedFile {
+	// Generate each type. Python evaluates alias and union assignments when
+	// the module loads, so they follow the classes they name.
+	types := group.Types
+	if lang.ID == "python" {
+		var classes, assigned []specparser.SpecType
+		for _, t := range types {
+			if t.Kind == "alias" || t.Kind == "union" {
+				assigned = append(assigned, t)
+			} else {
+				classes = append(classes, t)
+			}
+		}
+		types = append(classes, assigned...)
+	}
+	var body strings.Builder
+	for _, t := range types {
+		typeCode := g.generateType(t, spec.Types, lang)
+		body.WriteString(wrapRegion(lang.ID, "type", t.Name, hashOf(t), typeCode))
+		body.WriteString("\n")
+	}
+	if anyValidation(group.Types) {
+		if errorType := validationErrorType(lang.ID); errorType != "" {
+			body.WriteString(errorType)
+			body.WriteString("\n")
+		}
+	}
+	code := body.String()
+	foreign := foreignTypes(spec, group.Module, group.Types, nil)
 
-	// Add package/module declaration based on language
+	// Add package/module declaration and the imports the types use
+	var content strings.Builder
 	switch lang.ID {
 	case "go":
-		content.WriteString(fmt.Sprintf("package %s\n\n", toPackageName(spec.Name)))
+		content.WriteString(fmt.Sprintf("package %s\n\n", layout.Package("go", toPackageName(spec.Name), group.Module)))
+		var imports []string
+		for _, imp := range []struct{ path, use string }{
+			{"regexp", "regexp."}, {"slices", "slices."}, {"time", "time.Time"}, {"time", "time.Duration"}, {"unicode/utf8", "utf8."},
+		} {
+			if strings.Contains(code, imp.use) && !containsString(imports, fmt.Sprintf("\t%q\n", imp.path)) {
+				imports = append(imports, fmt.Sprintf("\t%q\n", imp.path))
+			}
+		}
+		if len(imports) > 0 {
+			content.WriteString("import (\n" + strings.Join(imports, "") + ")\n\n")
+		}
 	case "typescript":
-		content.WriteString("// Type definitions\n\n")
+		content.WriteString("// Type definitions\n\nimport { z } from \"zod\";\n")
+		content.WriteString(moduleImports(spec, lang.ID, layout.Path(lang.ID, group.Module, "src/types.ts"), tsImportNames(code, foreign)))
+		content.WriteString("\n")
 	case "python":
-		content.WriteString("from dataclasses import dataclass\nfrom typing import Optional, List, Dict, Any\n\n")
+		content.WriteString("from __future__ import annotations\n\n")
+		if strings.Contains(code, "re.search(") {
+			content.WriteString("import re\n")
+		}
+		content.WriteString("from datetime import date, datetime, timedelta\nfrom decimal import Decimal\n\n")
+		if strings.Contains(code, "@model_validator") {
+			content.WriteString("from pydantic import BaseModel, ConfigDict, Field, model_validator\n")
+		} else {
+			content.WriteString("from pydantic import BaseModel, ConfigDict, Field\n")
+		}
+		content.WriteString("from typing import Optional, List, Dict, Set, Tuple, Union, Callable, Any, Protocol\n")
+		content.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		content.WriteString("\n")
 	case "java":
-		content.WriteString(fmt.Sprintf("package %s;\n\n", toPackageName(spec.Name)))
+		content.WriteString(fmt.Sprintf("package %s;\n\n", layout.Package("java", toPackageName(spec.Name), group.Module)))
+		imports := strings.SplitAfter(moduleImports(spec, lang.ID, "", foreign), "\n")
+		imports = imports[:len(imports)-1]
+		// The fields' types need their classes; validation and defaults
+		// construct the rest
+		used := javaTypeImports(signatureTypes(group.Types, nil))
+		for _, imp := range []struct{ path, use string }{
+			{"com.fasterxml.jackson.annotation.JsonIgnore", "@JsonIgnore"},
+			{"com.fasterxml.jackson.annotation.JsonProperty", "@JsonProperty"},
+			{"com.fasterxml.jackson.annotation.JsonSubTypes", "@JsonSubTypes"},
+			{"com.fasterxml.jackson.annotation.JsonTypeInfo", "@JsonTypeInfo"},
+			{"com.fasterxml.jackson.annotation.JsonValue", "@JsonValue"},
+			{"java.math.BigDecimal", "new BigDecimal("},
+			{"java.util.List", "List.of("},
+			{"java.util.regex.Pattern", "Pattern.compile("},
+		} {
+			if strings.Contains(code, imp.use) && !containsString(used, imp.path) {
+				used = append(used, imp.path)
+			}
+		}
+		sort.Strings(used)
+		for _, path := range used {
+			imports = append(imports, "import "+path+";\n")
+		}
+		if len(imports) > 0 {
+			content.WriteString(strings.Join(imports, "") + "\n")
+		}
 	case "rust":
-		content.WriteString("use serde::{Deserialize, Serialize};\n\n")
-	case "csharp":
-		content.WriteString(fmt.Sprintf("namespace %s\n{\n", toPascalCase(spec.Name)))
-	}
-
-	// Generate each type
-	for _, t := range spec.Types {
-		typeCode := g.generateType(t, lang)
-		content.WriteString(typeCode)
+		content.WriteString("use serde::{Deserialize, Serialize};\n")
+		// Rust collections need importing
+		var collections []string
+		for _, c := range []string{"HashMap", "HashSet"} {
+			if strings.Contains(code, c+"<") {
+				collections = append(collections, c)
+			}
+		}
+		if len(collections) > 0 {
+			content.WriteString(fmt.Sprintf("use std::collections::{%s};\n", strings.Join(collections, ", ")))
+		}
+		content.WriteString(moduleImports(spec, lang.ID, "", foreign))
 		content.WriteString("\n")
+	case "csharp":
+		if strings.Contains(code, "Regex.") {
+			content.WriteString("using System.Text.RegularExpressions;\n")
+		}
+		content.WriteString("using System.Text.Json.Serialization;\n")
+		content.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		content.WriteString(fmt.Sprintf("\nnamespace %s\n{\n", layout.Package("csharp", toPascalCase(spec.Name), group.Module)))
 	}
+	content.WriteString(code)
 
 	// Close namespace for C#
 	if lang.ID == "csharp" {
 		content.WriteString("}\n")
 	}
+	text := content.String()
+	if lang.ID == "go" {
+		text = qualifyGo(spec, text, foreign)
+	}
 
 	// Determine file path based on language
 	var filePath string
@@ -135,288 +327,105 @@ func (g *Generator) generateTypes(spec *specparser.SpecAnalysis, adapter languag
 	}
 
 	var elements []string
-	for _, t := range spec.Types {
+	for _, t := range group.Types {
 		elements = append(elements, t.Name)
 	}
 
-	files = append(files, GeneratedFile{
-		Path:     filePath,
-		Content:  content.String(),
+	return GeneratedFile{
+		Path:     layout.Path(lang.ID, group.Module, filePath),
+		Content:  text,
 		Category: "type",
 		Elements: elements,
-	})
-
-	return files
-}
-
-// generateType generates code for a single type.
-func (g *Generator) generateType(t specparser.SpecType, lang languages.Language) string {
-	var sb strings.Builder
-
-	// Add doc comment
-	if t.Description != "" {
-		switch lang.ID {
-		case "go", "java", "csharp", "rust":
-			sb.WriteString(fmt.Sprintf("// %s\n", t.Description))
-		case "python":
-			// Python docstrings are inside the class
-		case "typescript":
-			sb.WriteString(fmt.Sprintf("/** %s */\n", t.Description))
-		}
 	}
+}
 
+// generateType renders a single type from its language's struct, enum,
+// interface, union or alias template. The spec's types let structs tell
+// nested structs from enums when rendering serialization.
+func (g *Generator) generateType(t specparser.SpecType, types []specparser.SpecType, lang languages.Language) string {
+	construct := "struct"
 	switch t.Kind {
-	case "struct", "class":
-		sb.WriteString(g.generateStruct(t, lang))
-	case "interface":
-		sb.WriteString(g.generateInterface(t, lang))
-	case "enum":
-		sb.WriteString(g.generateEnum(t, lang))
-	default:
-		sb.WriteString(g.generateStruct(t, lang))
+	case "interface", "enum", "union", "alias":
+		construct = t.Kind
 	}
-
-	return sb.String()
-}
-
-// generateStruct generates a struct/class definition.
-func (g *Generator) generateStruct(t specparser.SpecType, lang languages.Language) string {
-	var sb strings.Builder
-
-	switch lang.ID {
-	case "go":
-		sb.WriteString(fmt.Sprintf("type %s struct {\n", t.Name))
-		for _, f := range t.Fields {
-			fieldType := mapType(f.Type, lang.ID)
-			jsonTag := fmt.Sprintf("`json:\"%s\"`", toSnakeCase(f.Name))
-			sb.WriteString(fmt.Sprintf("\t%s %s %s\n", toPascalCase(f.Name), fieldType, jsonTag))
-		}
-		sb.WriteString("}\n")
-
-	case "typescript":
-		sb.WriteString(fmt.Sprintf("export interface %s {\n", t.Name))
-		for _, f := range t.Fields {
-			fieldType := mapType(f.Type, lang.ID)
-			optional := ""
-			if !f.Required {
-				optional = "?"
-			}
-			sb.WriteString(fmt.Sprintf("  %s%s: %s;\n", toCamelCase(f.Name), optional, fieldType))
-		}
-		sb.WriteString("}\n")
-
-	case "python":
-		sb.WriteString("@dataclass\n")
-		sb.WriteString(fmt.Sprintf("class %s:\n", t.Name))
-		if t.Description != "" {
-			sb.WriteString(fmt.Sprintf("    \"\"\"%s\"\"\"\n", t.Description))
-		}
-		if len(t.Fields) == 0 {
-			sb.WriteString("    pass\n")
-		} else {
-			for _, f := range t.Fields {
-				fieldType := mapType(f.Type, lang.ID)
-				if !f.Required {
-					fieldType = fmt.Sprintf("Optional[%s]", fieldType)
-					sb.WriteString(fmt.Sprintf("    %s: %s = None\n", toSnakeCase(f.Name), fieldType))
-				} else {
-					sb.WriteString(fmt.Sprintf("    %s: %s\n", toSnakeCase(f.Name), fieldType))
-				}
-			}
-		}
-
-	case "java":
-		sb.WriteString(fmt.Sprintf("public class %s {\n", t.Name))
-		for _, f := range t.Fields {
-			fieldType := mapType(f.Type, lang.ID)
-			sb.WriteString(fmt.Sprintf("    private %s %s;\n", fieldType, toCamelCase(f.Name)))
-		}
-		sb.WriteString("\n")
-		// Generate getters/setters
-		for _, f := range t.Fields {
-			fieldType := mapType(f.Type, lang.ID)
-			fieldName := toCamelCase(f.Name)
-			pascalName := toPascalCase(f.Name)
-			sb.WriteString(fmt.Sprintf("    public %s get%s() { return %s; }\n", fieldType, pascalName, fieldName))
-			sb.WriteString(fmt.Sprintf("    public void set%s(%s %s) { this.%s = %s; }\n\n", pascalName, fieldType, fieldName, fieldName, fieldName))
-		}
-		sb.WriteString("}\n")
-
-	case "rust":
-		sb.WriteString("#[derive(Debug, Clone, Serialize, Deserialize)]\n")
-		sb.WriteString(fmt.Sprintf("pub struct %s {\n", t.Name))
-		for _, f := range t.Fields {
-			fieldType := mapType(f.Type, lang.ID)
-			if !f.Required {
-				fieldType = fmt.Sprintf("Option<%s>", fieldType)
-			}
-			sb.WriteString(fmt.Sprintf("    pub %s: %s,\n", toSnakeCase(f.Name), fieldType))
-		}
-		sb.WriteString("}\n")
-
-	case "csharp":
-		sb.WriteString(fmt.Sprintf("    public class %s\n    {\n", t.Name))
-		for _, f := range t.Fields {
-			fieldType := mapType(f.Type, lang.ID)
-			if !f.Required {
-				fieldType += "?"
-			}
-			sb.WriteString(fmt.Sprintf("        public %s %s { get; set; }\n", fieldType, toPascalCase(f.Name)))
+	v := newTypeView(t, types, lang)
+	if lang.ID == "go" && construct == "struct" && !g.templates.isCustom(lang.ID, construct) {
+		if code, ok := goStructDecl(v); ok {
+			return code
 		}
-		sb.WriteString("    }\n")
 	}
-
-	return sb.String()
+	return g.render(lang.ID, construct, v)
 }
 
-// generateInterface generates an interface definition.
-func (g *Generator) generateInterface(t specparser.SpecType, lang languages.Language) string {
-	var sb strings.Builder
-
-	switch lang.ID {
-	case "go":
-		sb.WriteString(fmt.Sprintf("type %s interface {\n", t.Name))
-		for _, m := range t.Methods {
-			sb.WriteString(fmt.Sprintf("\t%s\n", m))
-		}
-		sb.WriteString("}\n")
-
-	case "typescript":
-		sb.WriteString(fmt.Sprintf("export interface %s {\n", t.Name))
-		for _, m := range t.Methods {
-			sb.WriteString(fmt.Sprintf("  %s;\n", m))
-		}
-		sb.WriteString("}\n")
-
-	case "python":
-		sb.WriteString("from abc import ABC, abstractmethod\n\n")
-		sb.WriteString(fmt.Sprintf("class %s(ABC):\n", t.Name))
-		for _, m := range t.Methods {
-			sb.WriteString("    @abstractmethod\n")
-			sb.WriteString(fmt.Sprintf("    def %s(self): pass\n", toSnakeCase(m)))
-		}
-
-	case "java":
-		sb.WriteString(fmt.Sprintf("public interface %s {\n", t.Name))
-		for _, m := range t.Methods {
-			sb.WriteString(fmt.Sprintf("    %s;\n", m))
-		}
-		sb.WriteString("}\n")
-
-	case "rust":
-		sb.WriteString(fmt.Sprintf("pub trait %s {\n", t.Name))
-		for _, m := range t.Methods {
-			sb.WriteString(fmt.Sprintf("    fn %s(&self);\n", toSnakeCase(m)))
-		}
-		sb.WriteString("}\n")
-
-	case "csharp":
-		sb.WriteString(fmt.Sprintf("    public interface %s\n    {\n", t.Name))
-		for _, m := range t.Methods {
-			sb.WriteString(fmt.Sprintf("        %s;\n", m))
+// generateFunctions generates function/method files, one per module.
+func (g *Generator) generateFunctions(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	var files []GeneratedFile
+	for _, group := range specModules(spec) {
+		if len(group.Functions) > 0 {
+			files = append(files, g.generateModuleFunctions(spec, group, adapter.GetLanguage()))
 		}
-		sb.WriteString("    }\n")
 	}
-
-	return sb.String()
+	return files
 }
 
-// generateEnum generates an enum definition.
-func (g *Generator) generateEnum(t specparser.SpecType, lang languages.Language) string {
-	var sb strings.Builder
+// generateModuleFunctions generates the service file of one module, which
+// imports the types it uses from other modules. TypeScript and Python also
+// import the types of their own module, which live in a separate file.
+func (g *Generator) generateModuleFunctions(spec *specparser.SpecAnalysis, group moduleGroup, lang languages.Language) GeneratedFile {
+	foreign := foreignTypes(spec, group.Module, nil, group.Functions)
+	used := usedTypes(spec, nil, group.Functions)
+	var content strings.Builder
 
+	// Add package/module declaration
 	switch lang.ID {
 	case "go":
-		sb.WriteString(fmt.Sprintf("type %s string\n\nconst (\n", t.Name))
-		for _, v := range t.Values {
-			sb.WriteString(fmt.Sprintf("\t%s%s %s = \"%s\"\n", t.Name, toPascalCase(v.Name), t.Name, v.Name))
+		content.WriteString(fmt.Sprintf("package %s\n\n", layout.Package("go", toPackageName(spec.Name), group.Module)))
+		if imports := goTypeImports(signatureTypes(nil, group.Functions)); len(imports) > 0 {
+			content.WriteString("import (\n")
+			for _, imp := range imports {
+				content.WriteString(fmt.Sprintf("\t%q\n", imp))
+			}
+			content.WriteString(")\n\n")
 		}
-		sb.WriteString(")\n")
-
 	case "typescript":
-		sb.WriteString(fmt.Sprintf("export enum %s {\n", t.Name))
-		for _, v := range t.Values {
-			if v.Value != "" {
-				sb.WriteString(fmt.Sprintf("  %s = %s,\n", v.Name, v.Value))
-			} else {
-				sb.WriteString(fmt.Sprintf("  %s,\n", v.Name))
-			}
+		content.WriteString("// Functions\n\n")
+		if imports := moduleImports(spec, lang.ID, layout.Path(lang.ID, group.Module, "src/service.ts"), used); imports != "" {
+			content.WriteString(imports + "\n")
 		}
-		sb.WriteString("}\n")
-
 	case "python":
-		sb.WriteString("from enum import Enum\n\n")
-		sb.WriteString(fmt.Sprintf("class %s(Enum):\n", t.Name))
-		for _, v := range t.Values {
-			if v.Value != "" {
-				sb.WriteString(fmt.Sprintf("    %s = %s\n", v.Name, v.Value))
-			} else {
-				sb.WriteString(fmt.Sprintf("    %s = \"%s\"\n", v.Name, v.Name))
-			}
-		}
-
+		content.WriteString(pythonTypeImports(signatureTypes(nil, group.Functions)))
+		content.WriteString(moduleImports(spec, lang.ID, "", used))
+		content.WriteString("\n")
 	case "java":
-		sb.WriteString(fmt.Sprintf("public enum %s {\n", t.Name))
-		for i, v := range t.Values {
-			if i < len(t.Values)-1 {
-				sb.WriteString(fmt.Sprintf("    %s,\n", v.Name))
-			} else {
-				sb.WriteString(fmt.Sprintf("    %s\n", v.Name))
-			}
+		content.WriteString(fmt.Sprintf("package %s;\n\n", layout.Package("java", toPackageName(spec.Name), group.Module)))
+		imports := moduleImports(spec, lang.ID, "", foreign)
+		for _, imp := range javaTypeImports(signatureTypes(nil, group.Functions)) {
+			imports += "import " + imp + ";\n"
 		}
-		sb.WriteString("}\n")
-
+		if imports != "" {
+			content.WriteString(imports + "\n")
+		}
+		content.WriteString("public class Service {\n")
 	case "rust":
-		sb.WriteString("#[derive(Debug, Clone, Serialize, Deserialize)]\n")
-		sb.WriteString(fmt.Sprintf("pub enum %s {\n", t.Name))
-		for _, v := range t.Values {
-			sb.WriteString(fmt.Sprintf("    %s,\n", toPascalCase(v.Name)))
+		if group.Module == "" || len(group.Types) > 0 {
+			content.WriteString(fmt.Sprintf("use %s::*;\n", rustModulePath("crate", group.Module, "types")))
 		}
-		sb.WriteString("}\n")
-
-	case "csharp":
-		sb.WriteString(fmt.Sprintf("    public enum %s\n    {\n", t.Name))
-		for _, v := range t.Values {
-			sb.WriteString(fmt.Sprintf("        %s,\n", toPascalCase(v.Name)))
+		content.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		if len(errorCatalog(spec)) > 0 {
+			content.WriteString("use crate::error::Error;\n")
 		}
-		sb.WriteString("    }\n")
-	}
-
-	return sb.String()
-}
-
-// generateFunctions generates function/method files.
-func (g *Generator) generateFunctions(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter, outputDir string) []GeneratedFile {
-	if len(spec.Functions) == 0 {
-		return nil
-	}
-
-	lang := adapter.GetLanguage()
-	var files []GeneratedFile
-
-	// Group functions by receiver (for methods) or generate one file
-	var content strings.Builder
-
-	// Add package/module declaration
-	switch lang.ID {
-	case "go":
-		content.WriteString(fmt.Sprintf("package %s\n\n", toPackageName(spec.Name)))
-	case "typescript":
-		content.WriteString("// Functions\n\n")
-	case "python":
-		content.WriteString("from typing import Optional, Any\n\n")
-	case "java":
-		content.WriteString(fmt.Sprintf("package %s;\n\npublic class Service {\n", toPackageName(spec.Name)))
-	case "rust":
-		content.WriteString("use crate::types::*;\n\n")
+		content.WriteString("\n")
 	case "csharp":
-		content.WriteString(fmt.Sprintf("namespace %s\n{\n    public static class Service\n    {\n", toPascalCase(spec.Name)))
+		if imports := moduleImports(spec, lang.ID, "", foreign); imports != "" {
+			content.WriteString(imports + "\n")
+		}
+		content.WriteString(fmt.Sprintf("namespace %s\n{\n    public static class Service\n    {\n", layout.Package("csharp", toPascalCase(spec.Name), group.Module)))
 	}
 
 	// Generate each function
-	for _, f := range spec.Functions {
-		funcCode := g.generateFunction(f, lang)
-		content.WriteString(funcCode)
+	for _, f := range group.Functions {
+		funcCode := g.generateFunction(f, spec.Types, lang)
+		content.WriteString(wrapRegion(lang.ID, "function", f.Name, hashOf(f), funcCode))
 		content.WriteString("\n")
 	}
 
@@ -447,306 +456,55 @@ func (g *Generator) generateFunctions(spec *specparser.SpecAnalysis, adapter lan
 	}
 
 	var elements []string
-	for _, f := range spec.Functions {
+	for _, f := range group.Functions {
 		elements = append(elements, f.Name)
 	}
 
-	files = append(files, GeneratedFile{
-		Path:     filePath,
-		Content:  content.String(),
+	text := content.String()
+	if lang.ID == "go" {
+		text = qualifyGo(spec, text, foreign)
+	}
+	return GeneratedFile{
+		Path:     layout.Path(lang.ID, group.Module, filePath),
+		Content:  text,
 		Category: "function",
 		Elements: elements,
-	})
-
-	return files
-}
-
-// generateFunction generates code for a single function.
-func (g *Generator) generateFunction(f specparser.SpecFunction, lang languages.Language) string {
-	var sb strings.Builder
-
-	// Add doc comment
-	if f.Description != "" {
-		switch lang.ID {
-		case "go":
-			sb.WriteString(fmt.Sprintf("// %s %s\n", f.Name, f.Description))
-		case "typescript":
-			sb.WriteString(fmt.Sprintf("/**\n * %s\n */\n", f.Description))
-		case "python":
-			// Python docstrings are inside the function
-		case "java", "csharp":
-			sb.WriteString(fmt.Sprintf("    /**\n     * %s\n     */\n", f.Description))
-		case "rust":
-			sb.WriteString(fmt.Sprintf("/// %s\n", f.Description))
-		}
-	}
-
-	// Generate function signature and body
-	switch lang.ID {
-	case "go":
-		sb.WriteString(g.generateGoFunction(f))
-	case "typescript":
-		sb.WriteString(g.generateTypeScriptFunction(f))
-	case "python":
-		sb.WriteString(g.generatePythonFunction(f))
-	case "java":
-		sb.WriteString(g.generateJavaFunction(f))
-	case "rust":
-		sb.WriteString(g.generateRustFunction(f))
-	case "csharp":
-		sb.WriteString(g.generateCSharpFunction(f))
 	}
-
-	return sb.String()
 }
 
-// generateGoFunction generates a Go function.
-func (g *Generator) generateGoFunction(f specparser.SpecFunction) string {
-	var sb strings.Builder
-
-	// Build parameter list
-	var params []string
-	for _, p := range f.Parameters {
-		paramType := mapType(p.Type, "go")
-		params = append(params, fmt.Sprintf("%s %s", toCamelCase(p.Name), paramType))
-	}
-
-	// Build return types
-	var returns []string
-	for _, r := range f.Returns {
-		returns = append(returns, mapType(r.Type, "go"))
-	}
-
-	// Add error return if function has errors
-	if len(f.Errors) > 0 && !containsError(returns) {
-		returns = append(returns, "error")
-	}
-
-	returnStr := ""
-	if len(returns) == 1 {
-		returnStr = returns[0]
-	} else if len(returns) > 1 {
-		returnStr = fmt.Sprintf("(%s)", strings.Join(returns, ", "))
-	}
-
-	sb.WriteString(fmt.Sprintf("func %s(%s) %s {\n", f.Name, strings.Join(params, ", "), returnStr))
-
-	// Generate body with TODO
-	if f.Logic != "" {
-		sb.WriteString(fmt.Sprintf("\t// %s\n", f.Logic))
-	}
-	sb.WriteString("\t// TODO: Implement\n")
-	if len(returns) > 0 {
-		if containsError(returns) {
-			sb.WriteString("\treturn nil, nil\n")
-		} else {
-			sb.WriteString(fmt.Sprintf("\treturn %s\n", defaultValue(returns[0], "go")))
+// generateFunction renders a single function from its language's template.
+// The spec's types give a Go stub returning a struct or enum its zero value,
+// which defaultValue cannot tell from nil.
+func (g *Generator) generateFunction(f specparser.SpecFunction, types []specparser.SpecType, lang languages.Language) string {
+	v := newFunctionView(f, lang.ID)
+	if lang.ID == "go" && len(f.Returns) > 0 && len(v.Results) <= 2 {
+		zero := ""
+		if t, ok := findType(types, f.Returns[0].Type); ok {
+			switch {
+			case isStructKind(t.Kind):
+				zero = v.Results[0] + "{}"
+			case t.Kind == "enum":
+				zero = `""`
+			}
 		}
-	}
-	sb.WriteString("}\n")
-
-	return sb.String()
-}
-
-// generateTypeScriptFunction generates a TypeScript function.
-func (g *Generator) generateTypeScriptFunction(f specparser.SpecFunction) string {
-	var sb strings.Builder
-
-	// Build parameter list
-	var params []string
-	for _, p := range f.Parameters {
-		paramType := mapType(p.Type, "typescript")
-		params = append(params, fmt.Sprintf("%s: %s", toCamelCase(p.Name), paramType))
-	}
-
-	// Build return type
-	returnType := "void"
-	if len(f.Returns) > 0 {
-		returnType = mapType(f.Returns[0].Type, "typescript")
-	}
-
-	asyncPrefix := ""
-	if f.IsAsync {
-		asyncPrefix = "async "
-		if returnType != "void" {
-			returnType = fmt.Sprintf("Promise<%s>", returnType)
-		} else {
-			returnType = "Promise<void>"
+		switch {
+		case zero == "":
+		case len(v.Results) == 2 && v.Results[1] == "error":
+			v.Result = zero + ", nil"
+		case len(v.Results) == 1:
+			v.Result = zero
 		}
 	}
-
-	sb.WriteString(fmt.Sprintf("export %sfunction %s(%s): %s {\n", asyncPrefix, toCamelCase(f.Name), strings.Join(params, ", "), returnType))
-
-	if f.Logic != "" {
-		sb.WriteString(fmt.Sprintf("  // %s\n", f.Logic))
-	}
-	sb.WriteString("  // TODO: Implement\n")
-	if returnType != "void" && returnType != "Promise<void>" {
-		sb.WriteString(fmt.Sprintf("  return %s;\n", defaultValue(returnType, "typescript")))
-	}
-	sb.WriteString("}\n")
-
-	return sb.String()
-}
-
-// generatePythonFunction generates a Python function.
-func (g *Generator) generatePythonFunction(f specparser.SpecFunction) string {
-	var sb strings.Builder
-
-	// Build parameter list
-	var params []string
-	for _, p := range f.Parameters {
-		paramType := mapType(p.Type, "python")
-		params = append(params, fmt.Sprintf("%s: %s", toSnakeCase(p.Name), paramType))
-	}
-
-	// Build return type
-	returnType := ""
-	if len(f.Returns) > 0 {
-		returnType = fmt.Sprintf(" -> %s", mapType(f.Returns[0].Type, "python"))
-	}
-
-	asyncPrefix := ""
-	if f.IsAsync {
-		asyncPrefix = "async "
-	}
-
-	sb.WriteString(fmt.Sprintf("%sdef %s(%s)%s:\n", asyncPrefix, toSnakeCase(f.Name), strings.Join(params, ", "), returnType))
-
-	if f.Description != "" {
-		sb.WriteString(fmt.Sprintf("    \"\"\"%s\"\"\"\n", f.Description))
-	}
-
-	if f.Logic != "" {
-		sb.WriteString(fmt.Sprintf("    # %s\n", f.Logic))
-	}
-	sb.WriteString("    # TODO: Implement\n")
-	sb.WriteString("    pass\n")
-
-	return sb.String()
-}
-
-// generateJavaFunction generates a Java method.
-func (g *Generator) generateJavaFunction(f specparser.SpecFunction) string {
-	var sb strings.Builder
-
-	// Build parameter list
-	var params []string
-	for _, p := range f.Parameters {
-		paramType := mapType(p.Type, "java")
-		params = append(params, fmt.Sprintf("%s %s", paramType, toCamelCase(p.Name)))
-	}
-
-	// Build return type
-	returnType := "void"
-	if len(f.Returns) > 0 {
-		returnType = mapType(f.Returns[0].Type, "java")
-	}
-
-	visibility := "public"
-	if !f.IsPublic {
-		visibility = "private"
-	}
-
-	sb.WriteString(fmt.Sprintf("    %s static %s %s(%s) {\n", visibility, returnType, toCamelCase(f.Name), strings.Join(params, ", ")))
-
-	if f.Logic != "" {
-		sb.WriteString(fmt.Sprintf("        // %s\n", f.Logic))
-	}
-	sb.WriteString("        // TODO: Implement\n")
-	if returnType != "void" {
-		sb.WriteString(fmt.Sprintf("        return %s;\n", defaultValue(returnType, "java")))
-	}
-	sb.WriteString("    }\n")
-
-	return sb.String()
-}
-
-// generateRustFunction generates a Rust function.
-func (g *Generator) generateRustFunction(f specparser.SpecFunction) string {
-	var sb strings.Builder
-
-	// Build parameter list
-	var params []string
-	for _, p := range f.Parameters {
-		paramType := mapType(p.Type, "rust")
-		params = append(params, fmt.Sprintf("%s: %s", toSnakeCase(p.Name), paramType))
-	}
-
-	// Build return type
-	returnType := ""
-	if len(f.Returns) > 0 {
-		returnType = fmt.Sprintf(" -> %s", mapType(f.Returns[0].Type, "rust"))
-	}
-
-	visibility := "pub "
-	if !f.IsPublic {
-		visibility = ""
-	}
-
-	asyncPrefix := ""
-	if f.IsAsync {
-		asyncPrefix = "async "
-	}
-
-	sb.WriteString(fmt.Sprintf("%s%sfn %s(%s)%s {\n", visibility, asyncPrefix, toSnakeCase(f.Name), strings.Join(params, ", "), returnType))
-
-	if f.Logic != "" {
-		sb.WriteString(fmt.Sprintf("    // %s\n", f.Logic))
-	}
-	sb.WriteString("    // TODO: Implement\n")
-	sb.WriteString("    todo!()\n")
-	sb.WriteString("}\n")
-
-	return sb.String()
-}
-
-// generateCSharpFunction generates a C# method.
-func (g *Generator) generateCSharpFunction(f specparser.SpecFunction) string {
-	var sb strings.Builder
-
-	// Build parameter list
-	var params []string
-	for _, p := range f.Parameters {
-		paramType := mapType(p.Type, "csharp")
-		params = append(params, fmt.Sprintf("%s %s", paramType, toCamelCase(p.Name)))
-	}
-
-	// Build return type
-	returnType := "void"
-	if len(f.Returns) > 0 {
-		returnType = mapType(f.Returns[0].Type, "csharp")
-	}
-
-	asyncPrefix := ""
-	if f.IsAsync {
-		asyncPrefix = "async "
-		if returnType != "void" {
-			returnType = fmt.Sprintf("Task<%s>", returnType)
-		} else {
-			returnType = "Task"
+	if lang.ID == "go" && !g.templates.isCustom(lang.ID, "function") {
+		if code, ok := goFuncDecl(v); ok {
+			return code
 		}
 	}
-
-	visibility := "public"
-	if !f.IsPublic {
-		visibility = "private"
-	}
-
-	sb.WriteString(fmt.Sprintf("        %s static %s%s %s(%s)\n        {\n", visibility, asyncPrefix, returnType, toPascalCase(f.Name), strings.Join(params, ", ")))
-
-	if f.Logic != "" {
-		sb.WriteString(fmt.Sprintf("            // %s\n", f.Logic))
-	}
-	sb.WriteString("            // TODO: Implement\n")
-	sb.WriteString("            throw new NotImplementedException();\n")
-	sb.WriteString("        }\n")
-
-	return sb.String()
+	return g.render(lang.ID, "function", v)
 }
 
 // generateTests generates test files.
-func (g *Generator) generateTests(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter, outputDir string) []GeneratedFile {
+func (g *Generator) generateTests(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
 	if len(spec.Tests) == 0 {
 		return nil
 	}
@@ -775,7 +533,7 @@ func (g *Generator) generateTests(spec *specparser.SpecAnalysis, adapter languag
 	// Generate each test
 	for _, t := range spec.Tests {
 		testCode := g.generateTest(t, lang)
-		content.WriteString(testCode)
+		content.WriteString(wrapRegion(lang.ID, "test", t.Name, hashOf(t), testCode))
 		content.WriteString("\n")
 	}
 
@@ -823,153 +581,33 @@ func (g *Generator) generateTests(spec *specparser.SpecAnalysis, adapter languag
 	return files
 }
 
-// generateTest generates code for a single test.
+// generateTest renders a single test from its language's template.
 func (g *Generator) generateTest(t specparser.SpecTest, lang languages.Language) string {
-	var sb strings.Builder
-
-	testName := toPascalCase(strings.ReplaceAll(t.Name, " ", "_"))
-
-	switch lang.ID {
-	case "go":
-		sb.WriteString(fmt.Sprintf("func Test%s(t *testing.T) {\n", testName))
-		if t.Description != "" {
-			sb.WriteString(fmt.Sprintf("\t// %s\n", t.Description))
-		}
-		for _, g := range t.Given {
-			sb.WriteString(fmt.Sprintf("\t// Given: %s\n", g.Description))
-		}
-		if t.When != "" {
-			sb.WriteString(fmt.Sprintf("\t// When: %s\n", t.When))
-		}
-		for _, a := range t.Then {
-			sb.WriteString(fmt.Sprintf("\t// Then: %s\n", a.Description))
-		}
-		sb.WriteString("\t// TODO: Implement test\n")
-		sb.WriteString("}\n")
-
-	case "typescript":
-		sb.WriteString(fmt.Sprintf("describe('%s', () => {\n", t.Name))
-		sb.WriteString(fmt.Sprintf("  it('%s', () => {\n", t.Description))
-		for _, g := range t.Given {
-			sb.WriteString(fmt.Sprintf("    // Given: %s\n", g.Description))
-		}
-		if t.When != "" {
-			sb.WriteString(fmt.Sprintf("    // When: %s\n", t.When))
-		}
-		for _, a := range t.Then {
-			sb.WriteString(fmt.Sprintf("    // Then: %s\n", a.Description))
-		}
-		sb.WriteString("    // TODO: Implement test\n")
-		sb.WriteString("  });\n")
-		sb.WriteString("});\n")
-
-	case "python":
-		sb.WriteString(fmt.Sprintf("def test_%s():\n", toSnakeCase(testName)))
-		if t.Description != "" {
-			sb.WriteString(fmt.Sprintf("    \"\"\"%s\"\"\"\n", t.Description))
-		}
-		for _, g := range t.Given {
-			sb.WriteString(fmt.Sprintf("    # Given: %s\n", g.Description))
-		}
-		if t.When != "" {
-			sb.WriteString(fmt.Sprintf("    # When: %s\n", t.When))
-		}
-		for _, a := range t.Then {
-			sb.WriteString(fmt.Sprintf("    # Then: %s\n", a.Description))
-		}
-		sb.WriteString("    # TODO: Implement test\n")
-		sb.WriteString("    pass\n")
-
-	case "java":
-		sb.WriteString(fmt.Sprintf("    @Test\n    public void test%s() {\n", testName))
-		if t.Description != "" {
-			sb.WriteString(fmt.Sprintf("        // %s\n", t.Description))
-		}
-		for _, g := range t.Given {
-			sb.WriteString(fmt.Sprintf("        // Given: %s\n", g.Description))
-		}
-		if t.When != "" {
-			sb.WriteString(fmt.Sprintf("        // When: %s\n", t.When))
-		}
-		for _, a := range t.Then {
-			sb.WriteString(fmt.Sprintf("        // Then: %s\n", a.Description))
-		}
-		sb.WriteString("        // TODO: Implement test\n")
-		sb.WriteString("    }\n")
-
-	case "rust":
-		sb.WriteString(fmt.Sprintf("    #[test]\n    fn test_%s() {\n", toSnakeCase(testName)))
-		if t.Description != "" {
-			sb.WriteString(fmt.Sprintf("        // %s\n", t.Description))
-		}
-		for _, g := range t.Given {
-			sb.WriteString(fmt.Sprintf("        // Given: %s\n", g.Description))
-		}
-		if t.When != "" {
-			sb.WriteString(fmt.Sprintf("        // When: %s\n", t.When))
-		}
-		for _, a := range t.Then {
-			sb.WriteString(fmt.Sprintf("        // Then: %s\n", a.Description))
-		}
-		sb.WriteString("        // TODO: Implement test\n")
-		sb.WriteString("        todo!()\n")
-		sb.WriteString("    }\n")
+	return g.render(lang.ID, "test", newTestView(t, lang.ID))
+}
 
-	case "csharp":
-		sb.WriteString(fmt.Sprintf("        [Fact]\n        public void Test%s()\n        {\n", testName))
-		if t.Description != "" {
-			sb.WriteString(fmt.Sprintf("            // %s\n", t.Description))
-		}
-		for _, g := range t.Given {
-			sb.WriteString(fmt.Sprintf("            // Given: %s\n", g.Description))
-		}
-		if t.When != "" {
-			sb.WriteString(fmt.Sprintf("            // When: %s\n", t.When))
-		}
-		for _, a := range t.Then {
-			sb.WriteString(fmt.Sprintf("            // Then: %s\n", a.Description))
-		}
-		sb.WriteString("            // TODO: Implement test\n")
-		sb.WriteString("            throw new NotImplementedException();\n")
-		sb.WriteString("        }\n")
+// render executes a construct template, recording the first failure for
+// Generate and Regenerate to report.
+func (g *Generator) render(langID, construct string, data any) string {
+	code, err := g.templates.render(langID, construct, data)
+	if err != nil && g.renderErr == nil {
+		g.renderErr = fmt.Errorf("failed to render %s %s template: %w", langID, construct, err)
 	}
-
-	return sb.String()
+	return code
 }
 
 // generateProjectFiles generates project configuration files.
-func (g *Generator) generateProjectFiles(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter, projectFiles []languages.ProjectFile, outputDir string) []GeneratedFile {
+func (g *Generator) generateProjectFiles(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter, projectFiles []languages.ProjectFile) []GeneratedFile {
 	lang := adapter.GetLanguage()
 	var files []GeneratedFile
+	deps := manifestDependencies(spec, lang.ID)
 
 	switch lang.ID {
 	case "go":
-		files = append(files, GeneratedFile{
-			Path:     "go.mod",
-			Content:  fmt.Sprintf("module %s\n\ngo 1.21\n", toPackageName(spec.Name)),
-			Category: "config",
-		})
+		files = append(files, GeneratedFile{Path: "go.mod", Content: goMod(spec, deps), Category: "config"})
 
 	case "typescript":
-		files = append(files, GeneratedFile{
-			Path: "package.json",
-			Content: fmt.Sprintf(`{
-  "name": "%s",
-  "version": "1.0.0",
-  "type": "module",
-  "main": "dist/index.js",
-  "scripts": {
-    "build": "tsc",
-    "test": "vitest"
-  },
-  "devDependencies": {
-    "typescript": "^5.0.0",
-    "vitest": "^1.0.0"
-  }
-}
-`, toPackageName(spec.Name)),
-			Category: "config",
-		})
+		files = append(files, GeneratedFile{Path: "package.json", Content: packageJSON(spec, deps), Category: "config"})
 
 		files = append(files, GeneratedFile{
 			Path: "tsconfig.json",
@@ -989,41 +627,54 @@ func (g *Generator) generateProjectFiles(spec *specparser.SpecAnalysis, adapter
 		})
 
 	case "python":
-		files = append(files, GeneratedFile{
-			Path: "pyproject.toml",
-			Content: fmt.Sprintf(`[project]
-name = "%s"
-version = "1.0.0"
-requires-python = ">=3.10"
-
-[build-system]
-requires = ["setuptools>=61.0"]
-build-backend = "setuptools.build_meta"
-
-[tool.pytest.ini_options]
-testpaths = ["tests"]
-`, toPackageName(spec.Name)),
-			Category: "config",
-		})
+		files = append(files, GeneratedFile{Path: "pyproject.toml", Content: pyproject(spec, deps), Category: "config"})
+
+	case "java":
+		files = append(files, GeneratedFile{Path: "pom.xml", Content: pomXML(spec, deps), Category: "config"})
 
 	case "rust":
+		root := specModules(spec)[0]
+		var modules string
+		if len(root.Types) > 0 {
+			modules += "pub mod types;\n"
+		}
+		if len(errorCatalog(spec)) > 0 {
+			modules += "pub mod error;\n"
+		}
+		if len(root.Functions) > 0 {
+			modules += "pub mod service;\n"
+		}
+		if len(spec.Endpoints) > 0 {
+			modules += "pub mod routes;\npub mod client;\n"
+		}
+		if len(configSettings(spec.Configuration)) > 0 {
+			modules += "pub mod config;\n"
+		}
+		if machines, _ := stateMachines(spec); len(machines) > 0 {
+			modules += "pub mod state_machines;\n"
+		}
+		if tables, _ := entityTables(spec); len(tables) > 0 {
+			modules += "pub mod repositories;\n"
+		}
+		if len(specInterfaces(spec)) > 0 {
+			modules += "#[cfg(test)]\npub mod mocks;\n"
+		}
+		for _, top := range rustTopModules(spec) {
+			modules += fmt.Sprintf("pub mod %s;\n", top)
+		}
+		files = append(files, GeneratedFile{Path: "Cargo.toml", Content: cargoToml(spec, deps), Category: "config"})
+		files = append(files, rustModuleFiles(spec)...)
+
 		files = append(files, GeneratedFile{
-			Path: "Cargo.toml",
-			Content: fmt.Sprintf(`[package]
-name = "%s"
-version = "0.1.0"
-edition = "2021"
-
-[dependencies]
-serde = { version = "1.0", features = ["derive"] }
-serde_json = "1.0"
-`, toPackageName(spec.Name)),
+			Path:     "src/lib.rs",
+			Content:  modules,
 			Category: "config",
 		})
 
+	case "csharp":
 		files = append(files, GeneratedFile{
-			Path:     "src/lib.rs",
-			Content:  "pub mod types;\npub mod service;\n",
+			Path:     toPascalCase(spec.Name) + ".csproj",
+			Content:  csproj(spec, deps),
 			Category: "config",
 		})
 	}
@@ -1033,149 +684,40 @@ serde_json = "1.0"
 
 // Helper functions
 
-func mapType(pseudoType, lang string) string {
-	// Normalize the type
-	t := strings.ToLower(strings.TrimSpace(pseudoType))
-
-	// Handle arrays/lists
-	if strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "list[") || strings.HasPrefix(t, "array[") {
-		innerType := strings.TrimPrefix(t, "[]")
-		innerType = strings.TrimPrefix(innerType, "list[")
-		innerType = strings.TrimPrefix(innerType, "array[")
-		innerType = strings.TrimSuffix(innerType, "]")
-		mapped := mapType(innerType, lang)
-		switch lang {
-		case "go":
-			return "[]" + mapped
-		case "typescript":
-			return mapped + "[]"
-		case "python":
-			return fmt.Sprintf("List[%s]", mapped)
-		case "java":
-			return fmt.Sprintf("List<%s>", mapped)
-		case "rust":
-			return fmt.Sprintf("Vec<%s>", mapped)
-		case "csharp":
-			return fmt.Sprintf("List<%s>", mapped)
-		}
-	}
-
-	// Handle maps
-	if strings.HasPrefix(t, "map[") || strings.HasPrefix(t, "dict[") {
-		switch lang {
-		case "go":
-			return "map[string]interface{}"
-		case "typescript":
-			return "Record<string, any>"
-		case "python":
-			return "Dict[str, Any]"
-		case "java":
-			return "Map<String, Object>"
-		case "rust":
-			return "HashMap<String, serde_json::Value>"
-		case "csharp":
-			return "Dictionary<string, object>"
-		}
-	}
-
-	// Handle optional types
-	if strings.HasPrefix(t, "optional[") || strings.HasSuffix(t, "?") {
-		innerType := strings.TrimPrefix(t, "optional[")
-		innerType = strings.TrimSuffix(innerType, "]")
-		innerType = strings.TrimSuffix(innerType, "?")
-		mapped := mapType(innerType, lang)
-		switch lang {
-		case "go":
-			return "*" + mapped
-		case "typescript":
-			return mapped + " | null"
-		case "python":
-			return fmt.Sprintf("Optional[%s]", mapped)
-		case "java":
-			return mapped // Java uses null
-		case "rust":
-			return fmt.Sprintf("Option<%s>", mapped)
-		case "csharp":
-			return mapped + "?"
+// commentLines prefixes each line with a comment marker, separated by a
+// space from non-empty lines.
+func commentLines(marker string, lines []string) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			sb.WriteString(strings.TrimRight(marker, " ") + "\n")
+		} else {
+			sb.WriteString(marker + " " + line + "\n")
 		}
 	}
+	return sb.String()
+}
 
-	// Map basic types
-	typeMap := map[string]map[string]string{
-		"string": {
-			"go": "string", "typescript": "string", "python": "str",
-			"java": "String", "rust": "String", "csharp": "string",
-		},
-		"str": {
-			"go": "string", "typescript": "string", "python": "str",
-			"java": "String", "rust": "String", "csharp": "string",
-		},
-		"int": {
-			"go": "int", "typescript": "number", "python": "int",
-			"java": "int", "rust": "i32", "csharp": "int",
-		},
-		"integer": {
-			"go": "int", "typescript": "number", "python": "int",
-			"java": "int", "rust": "i32", "csharp": "int",
-		},
-		"int64": {
-			"go": "int64", "typescript": "number", "python": "int",
-			"java": "long", "rust": "i64", "csharp": "long",
-		},
-		"float": {
-			"go": "float64", "typescript": "number", "python": "float",
-			"java": "double", "rust": "f64", "csharp": "double",
-		},
-		"float64": {
-			"go": "float64", "typescript": "number", "python": "float",
-			"java": "double", "rust": "f64", "csharp": "double",
-		},
-		"bool": {
-			"go": "bool", "typescript": "boolean", "python": "bool",
-			"java": "boolean", "rust": "bool", "csharp": "bool",
-		},
-		"boolean": {
-			"go": "bool", "typescript": "boolean", "python": "bool",
-			"java": "boolean", "rust": "bool", "csharp": "bool",
-		},
-		"any": {
-			"go": "interface{}", "typescript": "any", "python": "Any",
-			"java": "Object", "rust": "serde_json::Value", "csharp": "object",
-		},
-		"bytes": {
-			"go": "[]byte", "typescript": "Uint8Array", "python": "bytes",
-			"java": "byte[]", "rust": "Vec<u8>", "csharp": "byte[]",
-		},
-		"error": {
-			"go": "error", "typescript": "Error", "python": "Exception",
-			"java": "Exception", "rust": "anyhow::Error", "csharp": "Exception",
-		},
-		"void": {
-			"go": "", "typescript": "void", "python": "None",
-			"java": "void", "rust": "()", "csharp": "void",
-		},
-		"date": {
-			"go": "time.Time", "typescript": "Date", "python": "datetime",
-			"java": "LocalDate", "rust": "chrono::NaiveDate", "csharp": "DateTime",
-		},
-		"datetime": {
-			"go": "time.Time", "typescript": "Date", "python": "datetime",
-			"java": "LocalDateTime", "rust": "chrono::DateTime<chrono::Utc>", "csharp": "DateTime",
-		},
-		"uuid": {
-			"go": "string", "typescript": "string", "python": "str",
-			"java": "UUID", "rust": "uuid::Uuid", "csharp": "Guid",
-		},
+func mapType(pseudoType, lang string) string {
+	expr, err := typeexpr.Parse(pseudoType)
+	if err != nil {
+		// Not a type expression; keep the author's name as a custom type
+		return typeName(pseudoType)
 	}
+	return expr.RenderNamed(lang, typeName)
+}
 
-	if langMap, ok := typeMap[t]; ok {
-		if mapped, ok := langMap[lang]; ok {
-			return mapped
+// typeName returns the name a reference to a spec type renders as: a
+// PascalCase identifier as written, such as HTTPRequest, and anything else
+// converted to PascalCase. It agrees with the declarations because
+// withTypeIdents names every type that way.
+func typeName(name string) string {
+	for i, r := range name {
+		if i == 0 && !unicode.IsUpper(r) || r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return toPascalCase(name)
 		}
 	}
-
-	// Return original type (might be a custom type)
-	return toPascalCase(pseudoType)
+	return name
 }
 
 func defaultValue(typeName, lang string) string {
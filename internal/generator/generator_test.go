@@ -0,0 +1,292 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const inventorySpec = "# Inventory\n\n" +
+	"## Types\n\n" +
+	"### Item (struct)\n\n" +
+	"- sku: string - Stock keeping unit\n" +
+	"- tags: `Set[string]` - Labels\n" +
+	"- prices: `Map[string, decimal]` - Price per currency\n" +
+	"- parent: `Optional[Item]` - Containing item\n" +
+	"- dimensions: `(float, float)` - Width and height\n" +
+	"- broken: `List[` - Malformed\n\n" +
+	"## Functions\n\n" +
+	"### findItems\n\n" +
+	"**Parameters**\n" +
+	"- `filter`: `func(Item) -> bool` - Predicate\n" +
+	"- `limit`: `int?` - Maximum results\n\n" +
+	"**Returns** `Result[List[Item], NotFound]`\n"
+
+func TestMapType(t *testing.T) {
+	tests := []struct {
+		pseudo   string
+		lang     string
+		expected string
+	}{
+		{"string", "rust", "String"},
+		{"[]int", "typescript", "number[]"},
+		{"list of user_profile", "java", "List<UserProfile>"},
+		{"Map[string, List[int]]", "go", "map[string][]int"},
+		{"map", "python", "Dict[str, Any]"},
+		{"Set[uuid]", "csharp", "HashSet<Guid>"},
+		{"Optional[int]", "java", "Integer"},
+		{"int?", "rust", "Option<i32>"},
+		{"Result[Item, NotFound]", "rust", "Result<Item, NotFound>"},
+		{"Result[Item, NotFound]", "go", "Item"},
+		{"(string, int)", "python", "Tuple[str, int]"},
+		{"func(Item) -> bool", "typescript", "(arg0: Item) => boolean"},
+		{"not a type!", "go", "NotAType!"},
+	}
+
+	for _, tt := range tests {
+		if got := mapType(tt.pseudo, tt.lang); got != tt.expected {
+			t.Errorf("mapType(%q, %s) = %q, expected %q", tt.pseudo, tt.lang, got, tt.expected)
+		}
+	}
+}
+
+func TestGenerateTypesImportsCollections(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(inventorySpec, "inventory.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	expectGenerated(t, generatedFiles(t, gen, spec, "rust"), map[string][]string{
+		"src/types.rs": {
+			"use std::collections::{HashMap, HashSet};",
+			"pub tags: HashSet<String>,",
+			"pub prices: HashMap<String, f64>,",
+			"pub dimensions: (f64, f64),",
+		},
+	})
+}
+
+func TestCollectionsBuild(t *testing.T) {
+	// The malformed field is left out; it is a syntax error in every language.
+	checkBuilds(t, strings.Replace(inventorySpec, "- broken: `List[` - Malformed\n", "", 1), "go", "python")
+}
+
+const accountSpec = "# Accounts\n\n" +
+	"## Types\n\n" +
+	"**Naming**: json: snake_case\n\n" +
+	"### Account (struct)\n\n" +
+	"| Field | Type | Description |\n" +
+	"|-------|------|-------------|\n" +
+	"| userId | string | Owner `json:\"uid\" db:\"user_id\"` |\n" +
+	"| displayName | string | Shown name |\n" +
+	"| address | Address | Postal address |\n" +
+	"| secret | string | Never sent `json:\"-\"` |\n\n" +
+	"### Address (struct)\n\n" +
+	"**Naming**: json: camelCase\n\n" +
+	"- postal_code: string - Postal code\n" +
+	"- lines: `List[string]` - Street lines\n\n" +
+	"### Audit (struct)\n\n" +
+	"- at: datetime - When\n"
+
+func TestGenerateSerialization(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(accountSpec, "accounts.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		lang  string
+		path  string
+		wants []string
+	}{
+		{"go", "types.go", []string{
+			"UserId      string  `json:\"uid\" db:\"user_id\"`",
+			"DisplayName string  `json:\"display_name\"`",
+			"Secret      string  `json:\"-\"`",
+			"PostalCode string   `json:\"postalCode\"`",
+		}},
+		{"rust", "src/types.rs", []string{
+			"#[serde(rename = \"uid\")]\n    pub user_id: String,",
+			"#[serde(skip)]\n    pub secret: String,",
+			"#[serde(rename = \"postalCode\")]",
+		}},
+		{"java", "src/main/java/accounts/Types.java", []string{
+			"import com.fasterxml.jackson.annotation.JsonIgnore;",
+			"@JsonProperty(\"display_name\")\n    private String displayName;",
+			"@JsonIgnore\n    private String secret;",
+		}},
+		{"csharp", "src/Types.cs", []string{
+			"using System.Text.Json.Serialization;",
+			"[JsonPropertyName(\"uid\")]\n        public string UserId { get; set; }",
+			"[JsonIgnore]",
+		}},
+		{"python", "src/types.py", []string{
+			"class Account(BaseModel):",
+			"model_config = ConfigDict(populate_by_name=True)",
+			"user_id: str = Field(alias=\"uid\")",
+			"secret: str = Field(default=None, exclude=True)",
+			"postal_code: str = Field(alias=\"postalCode\")",
+		}},
+		{"typescript", "src/types.ts", []string{
+			"import { z } from \"zod\";",
+			"export const AccountSchema: z.ZodType<Account, z.ZodTypeDef, unknown> = z.lazy(() =>",
+			"      uid: z.string(),\n",
+			"      address: z.lazy(() => AddressSchema),\n",
+			"      userId: wire.uid,\n",
+			"    address: addressToWire(value.address),\n",
+		}},
+		{"go", "types_test.go", []string{
+			"// Skipped, no portable sample: Audit",
+			"assertRoundTrip(t, `{\"uid\":\"x\",\"display_name\":\"x\",\"address\":{\"postalCode\":\"x\",\"lines\":[\"x\"]}}`, &Account{})",
+		}},
+		{"python", "tests/test_types.py", []string{
+			"def test_address_round_trip():",
+			"model_dump(mode=\"json\", by_alias=True) == wire",
+		}},
+		{"rust", "tests/types.rs", []string{"assert_round_trip::<Account>(r#\"{\"uid\""}},
+		{"typescript", "src/types.test.ts", []string{"expect(JSON.parse(JSON.stringify(accountToWire(value)))).toEqual(wire);"}},
+		{"java", "src/test/java/accounts/TypesTest.java", []string{"assertRoundTrip(\"{\\\"postalCode\\\":\\\"x\\\",\\\"lines\\\":[\\\"x\\\"]}\", Address.class);"}},
+		{"csharp", "tests/TypesTests.cs", []string{"AssertRoundTrip<Address>("}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.lang), map[string][]string{tt.path: tt.wants})
+		})
+	}
+}
+
+func TestSerializationBuilds(t *testing.T) {
+	checkBuilds(t, accountSpec, "go", "python")
+}
+
+const signupSpec = "# Signup\n\n" +
+	"## Types\n\n" +
+	"### Signup (struct)\n\n" +
+	"| Field | Type | Description |\n" +
+	"|-------|------|-------------|\n" +
+	"| username | string | Login name {length: 3..20, pattern: /^[a-z][a-z0-9_]*$/} |\n" +
+	"| email | string | Contact {format: email} |\n" +
+	"| age | int | Age in years {min: 13, max: 130} |\n" +
+	"| plan | string | Billing plan {oneOf: [free, pro], default: free} |\n" +
+	"| tags | Optional[List[string]] | Labels {maxLength: 5} |\n\n" +
+	"### Rating (struct)\n\n" +
+	"- score: float - Stars {min: 0, max: 5}\n" +
+	"- note: string - Comment (default: none)\n"
+
+func TestGenerateValidation(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(signupSpec, "signup.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		lang  string
+		path  string
+		wants []string
+	}{
+		{"go", "types.go", []string{
+			"\"unicode/utf8\"",
+			"var signupUsernamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)",
+			"func NewSignup(username string, email string, age int) (*Signup, error) {",
+			"\t\tPlan:     \"free\",\n",
+			"\tif utf8.RuneCountInString(v.Username) < 3 {\n\t\treturn &ValidationError{Field: \"username\", Message: \"must have at least 3 characters\"}\n\t}",
+			"\tif !slices.Contains([]string{\"free\", \"pro\"}, v.Plan) {",
+			"\tif v.Tags != nil {\n\t\tif len(v.Tags) > 5 {",
+			"type ValidationError struct {",
+		}},
+		{"typescript", "src/types.ts", []string{
+			"export function validateSignup(value: Signup): void {",
+			"  if (value.age < 13) {\n    throw new ValidationError(\"age\", \"must be at least 13\");\n  }",
+			"export function createSignup(init: Omit<Signup, \"plan\"> & Partial<Pick<Signup, \"plan\">>): Signup {",
+			"  const value: Signup = { plan: \"free\", ...init };",
+		}},
+		{"python", "src/types.py", []string{
+			"import re\n",
+			"from pydantic import BaseModel, ConfigDict, Field, model_validator",
+			"plan: str = \"free\"",
+			"        if re.search(\"^[a-z][a-z0-9_]*$\", self.username) is None:\n            raise ValueError(\"username must match ^[a-z][a-z0-9_]*$\")",
+			"        if self.tags is not None:\n            if len(self.tags) > 5:",
+		}},
+		{"java", "src/main/java/signup/Types.java", []string{
+			"import java.util.regex.Pattern;",
+			"private String plan = \"free\";",
+			"public Signup(String username, String email, int age) {",
+			"throw new ValidationException(\"email\", \"must be a valid email\");",
+			"if (score < 0.0) {",
+		}},
+		{"rust", "src/types.rs", []string{
+			"pub fn new(username: String, email: String, age: i32) -> Result<Self, ValidationError> {",
+			"            plan: \"free\".to_string(),\n",
+			"            tags: None,\n",
+			"        if let Some(tags) = &self.tags {\n            if tags.len() > 5 {",
+			"            return Err(ValidationError::new(\"score\", \"must be at most 5\"));",
+		}},
+		{"rust", "Cargo.toml", []string{"regex = \"1\""}},
+		{"csharp", "src/Types.cs", []string{
+			"using System.Text.RegularExpressions;",
+			"public string Plan { get; set; } = \"free\";",
+			"            if (Tags is { } tags)\n            {\n                if (tags.Count > 5)",
+			"if (!Regex.IsMatch(Username, @\"^[a-z][a-z0-9_]*$\"))",
+		}},
+		{"go", "types_test.go", []string{
+			"// Skipped, no portable sample: Signup",
+			"assertRoundTrip(t, `{\"score\":1.5,\"note\":\"x\"}`, &Rating{})",
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.lang+"/"+tt.path, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.lang), map[string][]string{tt.path: tt.wants})
+		})
+	}
+}
+
+func TestValidationBuilds(t *testing.T) {
+	checkBuilds(t, signupSpec, "go", "python")
+}
+
+func TestRustLibDeclaresGeneratedModules(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lib     string
+	}{
+		{"billing", billingSpec, "pub mod config;\n"},
+		{"orders", ordersSpec, "pub mod state_machines;\n"},
+		{"signup", signupSpec, "pub mod types;\n"},
+		{"ledger", ledgerSpec, "pub mod types;\n"},
+		{"accounts", accountsSpec, "pub mod types;\npub mod error;\npub mod service;\n"},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := specparser.NewParser().Parse(tt.content, tt.name+".spec.md")
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			lib, ok := generatedFiles(t, gen, spec, "rust")["src/lib.rs"]
+			if !ok {
+				t.Fatal("Expected src/lib.rs to be generated")
+			}
+			if _, modules, _ := strings.Cut(lib, "\n"); modules != tt.lib {
+				t.Errorf("Expected lib.rs to declare\n%s\ngot:\n%s", tt.lib, lib)
+			}
+		})
+	}
+
+	// Each crate builds, sharing one target directory
+	requireTool(t, "cargo")
+	t.Setenv("CARGO_TARGET_DIR", t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name+"/cargo", func(t *testing.T) {
+			runTool(t, generateProject(t, tt.content, "rust"), "cargo", "build", "--offline", "--quiet")
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// goStructDecl builds a Go struct declaration as a syntax tree and prints
+// it, followed by the type's extra code. It reports false when a name is
+// not a Go identifier or a type is not a Go type expression, leaving the
+// struct template to render the text and formatGo to diagnose it.
+func goStructDecl(v typeView) (string, bool) {
+	if !token.IsIdentifier(v.Name) {
+		return "", false
+	}
+	fields := &ast.FieldList{Opening: 1, Closing: 2}
+	for _, f := range v.Fields {
+		typ, ok := goTypeExpr(f.Type)
+		if !ok || !token.IsIdentifier(f.Ident) {
+			return "", false
+		}
+		field := &ast.Field{Names: []*ast.Ident{ast.NewIdent(f.Ident)}, Type: typ}
+		if f.Attribute != "" {
+			field.Tag = &ast.BasicLit{Kind: token.STRING, Value: f.Attribute}
+		}
+		fields.List = append(fields.List, field)
+	}
+	decl := &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{&ast.TypeSpec{
+			Name: ast.NewIdent(v.Name),
+			Type: &ast.StructType{Fields: fields},
+		}},
+	}
+
+	code, ok := printGoNode(decl)
+	if !ok {
+		return "", false
+	}
+	var doc string
+	if v.Description != "" {
+		doc = commentLines("//", []string{v.Description})
+	}
+	return doc + code + "\n" + v.Extra, true
+}
+
+// goFuncDecl builds a Go function stub as a syntax tree: its signature and
+// the statement returning the stub's result. The doc comment and the TODO
+// comments in the body are added around the printed nodes. It reports
+// false when the function cannot be built, like goStructDecl.
+func goFuncDecl(v functionView) (string, bool) {
+	if !token.IsIdentifier(v.Ident) {
+		return "", false
+	}
+	params := &ast.FieldList{}
+	for _, p := range v.Params {
+		typ, ok := goTypeExpr(p.Type)
+		if !ok || !token.IsIdentifier(p.Ident) {
+			return "", false
+		}
+		params.List = append(params.List, &ast.Field{Names: []*ast.Ident{ast.NewIdent(p.Ident)}, Type: typ})
+	}
+	var results *ast.FieldList
+	if len(v.Results) > 0 {
+		results = &ast.FieldList{}
+		for _, r := range v.Results {
+			typ, ok := goTypeExpr(r)
+			if !ok {
+				return "", false
+			}
+			results.List = append(results.List, &ast.Field{Type: typ})
+		}
+	}
+	signature, ok := printGoNode(&ast.FuncDecl{
+		Name: ast.NewIdent(v.Ident),
+		Type: &ast.FuncType{Params: params, Results: results},
+	})
+	if !ok {
+		return "", false
+	}
+
+	var body strings.Builder
+	if v.Logic != "" {
+		body.WriteString(commentLines("\t//", []string{v.Logic}))
+	}
+	body.WriteString("\t// TODO: Implement\n")
+	if v.Result != "" {
+		values, ok := goExprList(v.Result)
+		if !ok {
+			return "", false
+		}
+		ret, ok := printGoNode(&ast.ReturnStmt{Results: values})
+		if !ok {
+			return "", false
+		}
+		body.WriteString("\t" + ret + "\n")
+	}
+	return commentLines("//", v.Doc) + signature + " {\n" + body.String() + "}\n", true
+}
+
+// goTypeExpr parses a Go type, such as "[]Task" or "...string".
+func goTypeExpr(s string) (ast.Expr, bool) {
+	if elem, ok := strings.CutPrefix(s, "..."); ok {
+		typ, ok := goTypeExpr(elem)
+		return &ast.Ellipsis{Elt: typ}, ok
+	}
+	expr, err := parser.ParseExpr(s)
+	return expr, err == nil
+}
+
+// goExprList parses a comma-separated list of Go expressions, such as a
+// stub's "Task{}, nil" result.
+func goExprList(s string) ([]ast.Expr, bool) {
+	expr, err := parser.ParseExpr("f(" + s + ")")
+	if err != nil {
+		return nil, false
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || call.Ellipsis.IsValid() {
+		return nil, false
+	}
+	return call.Args, true
+}
+
+// printGoNode prints a syntax tree node with go/format.
+func printGoNode(node ast.Node) (string, bool) {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), node); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
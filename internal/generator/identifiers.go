@@ -0,0 +1,404 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kon1790/rpg/internal/parity"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// reservedWords are each language's keywords, and the other words that
+// cannot name a variable, field or function.
+var reservedWords = map[string][]string{
+	"go": {
+		"break", "case", "chan", "const", "continue", "default", "defer", "else",
+		"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+		"map", "package", "range", "return", "select", "struct", "switch", "type", "var",
+	},
+	"typescript": {
+		"await", "break", "case", "catch", "class", "const", "continue", "debugger",
+		"default", "delete", "do", "else", "enum", "export", "extends", "false",
+		"finally", "for", "function", "if", "implements", "import", "in", "instanceof",
+		"interface", "let", "new", "null", "package", "private", "protected", "public",
+		"return", "static", "super", "switch", "this", "throw", "true", "try",
+		"typeof", "var", "void", "while", "with", "yield",
+	},
+	"python": {
+		"False", "None", "True", "and", "as", "assert", "async", "await", "break",
+		"class", "continue", "def", "del", "elif", "else", "except", "finally", "for",
+		"from", "global", "if", "import", "in", "is", "lambda", "nonlocal", "not",
+		"or", "pass", "raise", "return", "try", "while", "with", "yield",
+	},
+	"java": {
+		"_", "abstract", "assert", "boolean", "break", "byte", "case", "catch", "char",
+		"class", "const", "continue", "default", "do", "double", "else", "enum",
+		"extends", "false", "final", "finally", "float", "for", "goto", "if",
+		"implements", "import", "instanceof", "int", "interface", "long", "native",
+		"new", "null", "package", "private", "protected", "public", "return", "short",
+		"static", "strictfp", "super", "switch", "synchronized", "this", "throw",
+		"throws", "transient", "true", "try", "void", "volatile", "while",
+		// Object's final methods cannot be overridden, so neither can a
+		// field's accessor share their names
+		"equals", "getClass", "hashCode", "notify", "notifyAll", "toString", "wait",
+	},
+	"rust": {
+		"Self", "abstract", "as", "async", "await", "become", "box", "break", "const",
+		"continue", "crate", "do", "dyn", "else", "enum", "extern", "false", "final",
+		"fn", "for", "if", "impl", "in", "let", "loop", "macro", "match", "mod",
+		"move", "mut", "override", "priv", "pub", "ref", "return", "self", "static",
+		"struct", "super", "trait", "true", "try", "type", "typeof", "unsafe",
+		"unsized", "use", "virtual", "where", "while", "yield",
+	},
+	"csharp": {
+		"abstract", "as", "base", "bool", "break", "byte", "case", "catch", "char",
+		"checked", "class", "const", "continue", "decimal", "default", "delegate", "do",
+		"double", "else", "enum", "event", "explicit", "extern", "false", "finally",
+		"fixed", "float", "for", "foreach", "goto", "if", "implicit", "in", "int",
+		"interface", "internal", "is", "lock", "long", "namespace", "new", "null",
+		"object", "operator", "out", "override", "params", "private", "protected",
+		"public", "readonly", "ref", "return", "sbyte", "sealed", "short", "sizeof",
+		"stackalloc", "static", "string", "struct", "switch", "this", "throw", "true",
+		"try", "typeof", "uint", "ulong", "unchecked", "unsafe", "ushort", "using",
+		"virtual", "void", "volatile", "while",
+	},
+}
+
+// reserved indexes reservedWords by language.
+var reserved = func() map[string]map[string]bool {
+	index := make(map[string]map[string]bool)
+	for lang, words := range reservedWords {
+		index[lang] = make(map[string]bool)
+		for _, w := range words {
+			index[lang][w] = true
+		}
+	}
+	return index
+}()
+
+// rustUnrawable are the Rust keywords a raw identifier (r#) cannot spell.
+var rustUnrawable = map[string]bool{"Self": true, "self": true, "super": true, "crate": true}
+
+// safeIdent makes an identifier legal in a language. Characters that cannot
+// appear in identifiers become underscores, a leading digit gets a prefix
+// (X in Go, to keep the name exported, n in Python, where pydantic takes
+// _-prefixed fields for private ones, _ elsewhere) and reserved words are
+// escaped: r#type in Rust, @class in C# and a trailing underscore
+// elsewhere.
+func safeIdent(langID, ident string) string {
+	var sb strings.Builder
+	for _, r := range ident {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	ident = sb.String()
+
+	switch {
+	case ident == "":
+		return "_"
+	case unicode.IsDigit([]rune(ident)[0]):
+		switch langID {
+		case "go":
+			return "X" + ident
+		case "python":
+			return "n" + ident
+		}
+		return "_" + ident
+	case !reserved[langID][ident]:
+		return ident
+	}
+
+	switch {
+	case langID == "rust" && !rustUnrawable[ident]:
+		return "r#" + ident
+	case langID == "csharp":
+		return "@" + ident
+	}
+	return ident + "_"
+}
+
+// identCase returns a spec name in a language's case for a kind of
+// identifier: type, field, parameter, function, method (of an interface) or
+// value (an enum member). Go functions and most enum members keep the
+// spec's spelling; in Go that decides whether a function is exported. Types
+// are PascalCase everywhere (see typeName).
+func identCase(langID, kind, name string) string {
+	spelled := strings.Join(strings.Fields(name), "_")
+	switch kind {
+	case "type":
+		return typeName(name)
+	case "method":
+		if langID == "go" && spelled != "" {
+			r := []rune(spelled)
+			return string(unicode.ToUpper(r[0])) + string(r[1:])
+		}
+		return identCase(langID, "function", name)
+	case "field":
+		switch langID {
+		case "go", "csharp":
+			return toPascalCase(name)
+		case "typescript", "java":
+			return toCamelCase(name)
+		}
+		return toSnakeCase(name)
+	case "function":
+		switch langID {
+		case "go":
+			return spelled
+		case "python", "rust":
+			return toSnakeCase(name)
+		case "csharp":
+			return toPascalCase(name)
+		}
+		return toCamelCase(name)
+	case "value":
+		switch langID {
+		case "go", "rust", "csharp":
+			return toPascalCase(name)
+		}
+		return spelled
+	}
+	switch langID {
+	case "python", "rust":
+		return toSnakeCase(name)
+	}
+	return toCamelCase(name)
+}
+
+// identFor returns the legal identifier for a spec name (see safeIdent).
+func identFor(langID, kind, name string) string {
+	ident := identCase(langID, kind, name)
+	// A type keeps to PascalCase with an X before a leading digit, in every
+	// language
+	if kind == "type" && ident != "" && unicode.IsDigit([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	// TypeScript properties and enum members may be reserved words
+	if langID == "typescript" && (kind == "field" || kind == "value") && reserved[langID][ident] {
+		return ident
+	}
+	return safeIdent(langID, ident)
+}
+
+// typeIdent, fieldIdent, paramIdent, funcIdent, methodIdent and valueIdent
+// return the identifiers of types, struct fields, parameters and locals,
+// functions, interface methods and enum members.
+func typeIdent(langID, name string) string   { return identFor(langID, "type", name) }
+func fieldIdent(langID, name string) string  { return identFor(langID, "field", name) }
+func paramIdent(langID, name string) string  { return identFor(langID, "parameter", name) }
+func funcIdent(langID, name string) string   { return identFor(langID, "function", name) }
+func methodIdent(langID, name string) string { return identFor(langID, "method", name) }
+func valueIdent(langID, name string) string  { return identFor(langID, "value", name) }
+
+// javaAccessor returns the getter or setter (prefix get or set) of a Java
+// field, escaped where it would clash with a final Object method such as
+// getClass.
+func javaAccessor(prefix, name string) string {
+	return safeIdent("java", prefix+toPascalCase(name))
+}
+
+// withTypeIdents returns spec with its types named by their identifiers in
+// a language, and the references to them renamed to match, so generators
+// can use a type's name as is. A spec whose type names are all legal is
+// returned unchanged.
+func withTypeIdents(spec *specparser.SpecAnalysis, langID string) *specparser.SpecAnalysis {
+	renames := make(map[string]string)
+	for _, t := range spec.Types {
+		if ident := typeIdent(langID, t.Name); ident != t.Name {
+			renames[t.Name] = ident
+		}
+	}
+	if len(renames) == 0 {
+		return spec
+	}
+
+	// Longer names first, so "Order Item" is renamed before "Item"
+	names := make([]string, 0, len(renames))
+	for name := range renames {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	rename := func(typeName string) string {
+		for _, name := range names {
+			typeName = replaceTypeName(typeName, name, renames[name])
+		}
+		return typeName
+	}
+	renameFunction := func(f specparser.SpecFunction) specparser.SpecFunction {
+		f.Receiver = rename(f.Receiver)
+		f.Parameters = append([]specparser.SpecParameter(nil), f.Parameters...)
+		for i := range f.Parameters {
+			f.Parameters[i].Type = rename(f.Parameters[i].Type)
+		}
+		f.Returns = append([]specparser.SpecReturn(nil), f.Returns...)
+		for i := range f.Returns {
+			f.Returns[i].Type = rename(f.Returns[i].Type)
+		}
+		return f
+	}
+
+	renamed := *spec
+	renamed.Types = append([]specparser.SpecType(nil), spec.Types...)
+	for i := range renamed.Types {
+		t := &renamed.Types[i]
+		t.Name = rename(t.Name)
+		t.Fields = append([]specparser.SpecField(nil), t.Fields...)
+		for j := range t.Fields {
+			t.Fields[j].Type = rename(t.Fields[j].Type)
+		}
+		t.Methods = append([]specparser.SpecFunction(nil), t.Methods...)
+		for j := range t.Methods {
+			t.Methods[j] = renameFunction(t.Methods[j])
+		}
+		t.Implements = append([]string(nil), t.Implements...)
+		for j := range t.Implements {
+			t.Implements[j] = rename(t.Implements[j])
+		}
+	}
+	renamed.Functions = append([]specparser.SpecFunction(nil), spec.Functions...)
+	for i := range renamed.Functions {
+		renamed.Functions[i] = renameFunction(renamed.Functions[i])
+	}
+	renamed.Endpoints = append([]specparser.SpecEndpoint(nil), spec.Endpoints...)
+	for i := range renamed.Endpoints {
+		e := &renamed.Endpoints[i]
+		e.RequestType = rename(e.RequestType)
+		e.Responses = append([]specparser.SpecResponse(nil), e.Responses...)
+		for j := range e.Responses {
+			e.Responses[j].Type = rename(e.Responses[j].Type)
+		}
+	}
+	renamed.Tables = append([]specparser.SpecTable(nil), spec.Tables...)
+	for i := range renamed.Tables {
+		renamed.Tables[i].Entity = rename(renamed.Tables[i].Entity)
+	}
+	return &renamed
+}
+
+// replaceTypeName replaces the whole-word occurrences of a type name in a
+// type expression.
+func replaceTypeName(typeName, name, ident string) string {
+	isWord := func(s string, i int) bool {
+		if i < 0 || i >= len(s) {
+			return false
+		}
+		r := rune(s[i])
+		return r == '_' || r == '$' || r >= utf8.RuneSelf || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(typeName, name)
+		if i < 0 {
+			break
+		}
+		end := i + len(name)
+		if isWord(typeName, i-1) || isWord(typeName, end) {
+			sb.WriteString(typeName[:end])
+		} else {
+			sb.WriteString(typeName[:i] + ident)
+		}
+		typeName = typeName[end:]
+	}
+	return sb.String() + typeName
+}
+
+// specIdentifier is a spec name and the identifier generated for it.
+type specIdentifier struct {
+	kind, scope, name, ident string
+}
+
+// specIdentifiers lists the identifiers a spec's types, fields, interface
+// methods, functions, parameters and enum members get in a language.
+func specIdentifiers(spec *specparser.SpecAnalysis, langID string) []specIdentifier {
+	var ids []specIdentifier
+	add := func(kind, scope, name string) {
+		ids = append(ids, specIdentifier{kind: kind, scope: scope, name: name, ident: identFor(langID, kind, name)})
+	}
+	for _, t := range spec.Types {
+		add("type", "", t.Name)
+		for _, m := range t.Methods {
+			add("method", t.Name, m.Name)
+		}
+		for _, f := range t.Fields {
+			add("field", t.Name, f.Name)
+		}
+		if t.Kind == "enum" {
+			for _, v := range t.Values {
+				add("value", t.Name, v.Name)
+			}
+		}
+	}
+	for _, f := range spec.Functions {
+		add("function", "", f.Name)
+		for _, p := range f.Parameters {
+			add("parameter", f.Name, p.Name)
+		}
+	}
+	return ids
+}
+
+// identifierCollisions reports spec names in the same scope that end up as
+// the same identifier in a language, e.g. the fields user_id and userId
+// both becoming UserId in Go.
+func identifierCollisions(spec *specparser.SpecAnalysis, langID string) error {
+	seen := make(map[string]specIdentifier)
+	var collisions []string
+	for _, id := range specIdentifiers(spec, langID) {
+		key := id.kind + "\x00" + id.scope + "\x00" + id.ident
+		prev, ok := seen[key]
+		if !ok {
+			seen[key] = id
+			continue
+		}
+		if prev.name == id.name {
+			continue
+		}
+		where := ""
+		if id.scope != "" {
+			where = " of " + id.scope
+		}
+		collisions = append(collisions, fmt.Sprintf("%ss %q and %q%s both become %s in %s", id.kind, prev.name, id.name, where, id.ident, langID))
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+	return fmt.Errorf("identifier collisions: %s", strings.Join(collisions, "; "))
+}
+
+// identifierMap lists the spec names whose identifiers had to be made
+// legal, for parity matching to link them back (see parity.IdentifierMapping).
+func identifierMap(spec *specparser.SpecAnalysis, langID string) []parity.IdentifierMapping {
+	var mappings []parity.IdentifierMapping
+	for _, id := range specIdentifiers(spec, langID) {
+		if id.ident != identCase(langID, id.kind, id.name) {
+			mappings = append(mappings, parity.IdentifierMapping{Kind: id.kind, Scope: id.scope, SpecName: id.name, Identifier: id.ident})
+		}
+	}
+	sort.SliceStable(mappings, func(i, j int) bool { return mappings[i].Kind < mappings[j].Kind })
+	return mappings
+}
+
+// generateIdentifierMap generates the identifier map of a spec, or nothing
+// when every name could be used as is.
+func (g *Generator) generateIdentifierMap(spec *specparser.SpecAnalysis, langID string) []GeneratedFile {
+	mappings := identifierMap(spec, langID)
+	if len(mappings) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(mappings, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return []GeneratedFile{{
+		Path:     parity.IdentifierMapFile,
+		Content:  string(data) + "\n",
+		Category: "config",
+	}}
+}
@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/parity"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const reservedSpec = "# Lexer\n\n" +
+	"## Types\n\n" +
+	"### Token (struct)\n\n" +
+	"- type: string - Token kind\n" +
+	"- class: string - Character class\n" +
+	"- self: bool - Refers to itself\n\n" +
+	"### Keyword (enum)\n\n" +
+	"- None\n" +
+	"- Self\n" +
+	"- 1st\n\n" +
+	"## Functions\n\n" +
+	"### match\n\n" +
+	"Matches a token.\n\n" +
+	"**Parameters**\n- `fn`: `string`\n- `in`: `Token`\n\n" +
+	"**Returns** `bool`\n"
+
+func TestSafeIdent(t *testing.T) {
+	tests := []struct {
+		language string
+		ident    string
+		expected string
+	}{
+		{"rust", "type", "r#type"},
+		{"rust", "self", "self_"},
+		{"csharp", "class", "@class"},
+		{"python", "class", "class_"},
+		{"java", "new", "new_"},
+		{"go", "1st", "X1st"},
+		{"python", "1st", "n1st"},
+		{"python", "2fa_code", "n2fa_code"},
+		{"java", "getClass", "getClass_"},
+		{"go", "first.name", "first_name"},
+		{"go", "Type", "Type"},
+	}
+
+	for _, tt := range tests {
+		if got := safeIdent(tt.language, tt.ident); got != tt.expected {
+			t.Errorf("Expected safeIdent(%s, %q) = %q, got %q", tt.language, tt.ident, tt.expected, got)
+		}
+	}
+}
+
+func TestIdentifierCollisions(t *testing.T) {
+	spec, err := specparser.NewParser().Parse("# X\n\n## Types\n\n### Account (struct)\n\n- user_id: string\n- userId: string\n", "x.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	err = identifierCollisions(spec, "go")
+	if err == nil || !strings.Contains(err.Error(), `"user_id" and "userId" of Account both become UserId in go`) {
+		t.Errorf("Expected a collision on UserId, got %v", err)
+	}
+	if _, err := NewGenerator(languages.NewRegistry()).Generate(spec, "go", t.TempDir()); err == nil {
+		t.Error("Expected Generate to refuse colliding identifiers")
+	}
+}
+
+func TestReservedIdentifiers(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(reservedSpec, "lexer.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected string
+	}{
+		{"go", "types.go", "\tKeywordX1st Keyword = \"1st\""},
+		{"typescript", "src/types.ts", "  class: string;"},
+		{"python", "src/service.py", "def match(fn: str, in_: Token) -> bool:"},
+		{"java", "src/main/java/lexer/Types.java", "private String class_;"},
+		{"rust", "src/types.rs", "pub r#type: String,"},
+		{"csharp", "src/Service.cs", "Match(string fn, Token @in)"},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			files, err := gen.Generate(spec, tt.language, t.TempDir())
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			contents := make(map[string]string)
+			for _, f := range files {
+				contents[f.Path] = f.Content
+			}
+			if !strings.Contains(contents[tt.path], tt.expected) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", tt.path, tt.expected, contents[tt.path])
+			}
+
+			// The escaped names are recorded for parity matching
+			var mappings []parity.IdentifierMapping
+			if err := json.Unmarshal([]byte(contents[parity.IdentifierMapFile]), &mappings); err != nil {
+				t.Fatalf("Expected an identifier map: %v", err)
+			}
+			if len(mappings) == 0 {
+				t.Error("Expected escaped identifiers in the map")
+			}
+		})
+	}
+}
+
+const typeNamesSpec = "# Shapes\n\n" +
+	"## Types\n\n" +
+	"### match (struct)\n\n" +
+	"- id: string\n" +
+	"- class: string\n\n" +
+	"### Order Item (struct)\n\n" +
+	"- sku: string\n" +
+	"- owner: match\n\n" +
+	"### 3D Point (struct)\n\n" +
+	"- x: int\n\n" +
+	"### shape store (interface)\n\n" +
+	"- `match(id: string): match`\n\n" +
+	"## Functions\n\n" +
+	"### fetch\n\n" +
+	"**Parameters**\n- `point`: `3D Point`\n\n" +
+	"**Returns** `Order Item`\n"
+
+func TestTypeIdentifiers(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(typeNamesSpec, "shapes.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected []string
+	}{
+		{"go", "types.go", []string{"type Match struct", "\tOwner Match  `json:\"owner\"`", "type X3dPoint struct", "type ShapeStore interface {\n\tMatch(id string) Match\n}"}},
+		{"typescript", "src/types.ts", []string{"export interface OrderItem {", "  owner: Match;", "export interface X3dPoint {"}},
+		{"python", "src/types.py", []string{"class Match(BaseModel):", "class X3dPoint(BaseModel):", "def match(self, id: str) -> Match:"}},
+		{"java", "src/main/java/shapes/Types.java", []string{"public class Match {", "public String getClass_() { return class_; }", "public void setClass(String class_)"}},
+		{"rust", "src/types.rs", []string{"pub struct Match {", "pub owner: Match,", "pub struct X3dPoint {", "fn r#match(&self, id: String) -> Match;"}},
+		{"csharp", "src/Types.cs", []string{"public class OrderItem", "public interface ShapeStore"}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			files, err := gen.Generate(spec, tt.language, t.TempDir())
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			contents := make(map[string]string)
+			for _, f := range files {
+				contents[f.Path] = f.Content
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(contents[tt.path], want) {
+					t.Errorf("Expected %s to contain %q, got:\n%s", tt.path, want, contents[tt.path])
+				}
+			}
+
+			var mappings []parity.IdentifierMapping
+			if err := json.Unmarshal([]byte(contents[parity.IdentifierMapFile]), &mappings); err != nil {
+				t.Fatalf("Expected an identifier map: %v", err)
+			}
+			found := false
+			for _, m := range mappings {
+				found = found || m.Kind == "type" && m.SpecName == "3D Point" && m.Identifier == "X3dPoint"
+			}
+			if !found {
+				t.Errorf("Expected 3D Point mapped to X3dPoint, got %+v", mappings)
+			}
+		})
+	}
+
+	// Type names that become the same identifier collide
+	clash, err := specparser.NewParser().Parse("# X\n\n## Types\n\n### Order Item (struct)\n\n- id: string\n\n### OrderItem (struct)\n\n- id: string\n", "x.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if err := identifierCollisions(clash, "go"); err == nil || !strings.Contains(err.Error(), `types "Order Item" and "OrderItem" both become OrderItem`) {
+		t.Errorf("Expected a collision on OrderItem, got %v", err)
+	}
+}
+
+func TestEscapedIdentifiersBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"reserved", reservedSpec},
+		{"types", typeNamesSpec},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkBuilds(t, tt.content, "go", "python")
+		})
+	}
+}
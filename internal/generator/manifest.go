@@ -0,0 +1,580 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// manifestDep is a package a generated project depends on.
+type manifestDep struct {
+	Name    string
+	Version string
+
+	// Dev marks test-only dependencies
+	Dev bool
+
+	// Features lists Cargo features to enable
+	Features []string
+}
+
+// manifestDependencies returns the packages the generated code needs,
+// followed by the spec's declared dependencies for the language. A
+// declared dependency replaces a generated one of the same name so the
+// spec can pin versions.
+func manifestDependencies(spec *specparser.SpecAnalysis, langID string) []manifestDep {
+	deps := generatedDependencies(spec, langID)
+	for _, d := range specPackages(spec, langID) {
+		replaced := false
+		for i := range deps {
+			if deps[i].Name == d.Name {
+				deps[i].Version = d.Version
+				replaced = true
+			}
+		}
+		if !replaced {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// generatedDependencies lists the packages generated code imports, with
+// each adapter's test framework as a dev dependency.
+func generatedDependencies(spec *specparser.SpecAnalysis, langID string) []manifestDep {
+	hasEndpoints := len(spec.Endpoints) > 0
+	hasTypes := len(spec.Types) > 0
+	hasConfig := len(configSettings(spec.Configuration)) > 0
+	hasInterfaces := len(specInterfaces(spec)) > 0
+	hasProperties := len(spec.Properties) > 0
+	hasCommands := len(spec.Commands) > 0
+	machines, _ := stateMachines(spec)
+	hasStateMachines := len(machines) > 0
+	tables, _ := entityTables(spec)
+	hasRepositories := len(tables) > 0
+	stored := storedTypesOf(tables)
+	used := usedPrimitives(specTypeExprs(spec))
+
+	var deps []manifestDep
+	add := func(cond bool, d manifestDep) {
+		if cond {
+			deps = append(deps, d)
+		}
+	}
+
+	switch langID {
+	case "go":
+		add(hasCommands, manifestDep{Name: "github.com/spf13/cobra", Version: "v1.8.0"})
+		add(hasRepositories, manifestDep{Name: "modernc.org/sqlite", Version: "v1.29.0", Dev: true})
+
+	case "typescript":
+		// Types parse their wire format with zod
+		add(hasEndpoints, manifestDep{Name: "express", Version: "^4.18.0"})
+		add(hasCommands, manifestDep{Name: "commander", Version: "^12.0.0"})
+		add(hasTypes, manifestDep{Name: "zod", Version: "^3.22.0"})
+		add(hasRepositories, manifestDep{Name: "knex", Version: "^3.1.0"})
+		add(hasEndpoints, manifestDep{Name: "@types/express", Version: "^4.17.0", Dev: true})
+		add(hasRepositories, manifestDep{Name: "better-sqlite3", Version: "^11.0.0", Dev: true})
+		add(hasEndpoints || hasConfig || hasProperties || hasCommands || hasRepositories, manifestDep{Name: "@types/node", Version: "^20.0.0", Dev: true})
+		add(hasProperties, manifestDep{Name: "fast-check", Version: "^3.15.0", Dev: true})
+		add(true, manifestDep{Name: "typescript", Version: "^5.0.0", Dev: true})
+		add(true, manifestDep{Name: "vitest", Version: "^1.0.0", Dev: true})
+
+	case "python":
+		// Types are pydantic models
+		add(hasEndpoints, manifestDep{Name: "fastapi", Version: ">=0.100"})
+		add(hasEndpoints, manifestDep{Name: "httpx", Version: ">=0.27"})
+		add(hasTypes || hasEndpoints || hasConfig, manifestDep{Name: "pydantic", Version: ">=2"})
+		add(hasConfig, manifestDep{Name: "pydantic-settings", Version: ">=2.7"})
+		add(hasRepositories, manifestDep{Name: "sqlalchemy", Version: ">=2"})
+		add(true, manifestDep{Name: "pytest", Version: ">=8", Dev: true})
+		add(hasProperties, manifestDep{Name: "hypothesis", Version: ">=6", Dev: true})
+
+	case "java":
+		add(hasTypes || hasEndpoints || hasCommands, manifestDep{Name: "com.fasterxml.jackson.core:jackson-databind", Version: "2.17.0"})
+		add(hasCommands, manifestDep{Name: "info.picocli:picocli", Version: "4.7.5"})
+		add(hasEndpoints, manifestDep{Name: "org.springframework.boot:spring-boot-starter-web", Version: "3.2.5"})
+		add(hasRepositories, manifestDep{Name: "org.springframework.data:spring-data-jpa", Version: "3.2.5"})
+		add(hasRepositories, manifestDep{Name: "org.hibernate.orm:hibernate-core", Version: "6.4.4.Final"})
+		add(true, manifestDep{Name: "org.junit.jupiter:junit-jupiter", Version: "5.10.2", Dev: true})
+		add(hasRepositories, manifestDep{Name: "org.xerial:sqlite-jdbc", Version: "3.45.2.0", Dev: true})
+		add(hasRepositories, manifestDep{Name: "org.hibernate.orm:hibernate-community-dialects", Version: "6.4.4.Final", Dev: true})
+		add(hasInterfaces, manifestDep{Name: "org.mockito:mockito-core", Version: "5.11.0", Dev: true})
+		add(hasProperties, manifestDep{Name: "net.jqwik:jqwik", Version: "1.8.4", Dev: true})
+
+	case "rust":
+		add(true, manifestDep{Name: "serde", Version: "1.0", Features: []string{"derive"}})
+		add(true, manifestDep{Name: "serde_json", Version: "1.0"})
+		add(usesPatterns(spec.Types), manifestDep{Name: "regex", Version: "1"})
+		add(len(errorCatalog(spec)) > 0 || hasStateMachines, manifestDep{Name: "thiserror", Version: "1"})
+		add(hasEndpoints, manifestDep{Name: "axum", Version: "0.7"})
+		add(hasEndpoints, manifestDep{Name: "reqwest", Version: "0.12", Features: []string{"json"}})
+		add(hasCommands, manifestDep{Name: "clap", Version: "4", Features: []string{"derive"}})
+		add(hasEndpoints || hasCommands && usesAsync(spec.Functions) || hasRepositories, manifestDep{Name: "tokio", Version: "1", Features: []string{"full"}})
+		// Repositories store the entities' dates and UUIDs through sqlx
+		sqlx := []string{"runtime-tokio", "sqlite"}
+		if stored[typeexpr.Date] || stored[typeexpr.DateTime] {
+			sqlx = append(sqlx, "chrono")
+		}
+		if stored[typeexpr.UUID] {
+			sqlx = append(sqlx, "uuid")
+		}
+		add(hasRepositories, manifestDep{Name: "sqlx", Version: "0.8", Features: sqlx})
+		add(used[typeexpr.Date] || used[typeexpr.DateTime], manifestDep{Name: "chrono", Version: "0.4", Features: []string{"serde"}})
+		add(used[typeexpr.UUID], manifestDep{Name: "uuid", Version: "1", Features: []string{"serde"}})
+		add(used[typeexpr.Error], manifestDep{Name: "anyhow", Version: "1"})
+		add(hasEndpoints, manifestDep{Name: "wiremock", Version: "0.6", Dev: true})
+		add(hasInterfaces, manifestDep{Name: "mockall", Version: "0.13", Dev: true})
+		add(hasProperties, manifestDep{Name: "proptest", Version: "1", Dev: true})
+		add(hasRepositories, manifestDep{Name: "tempfile", Version: "3", Dev: true})
+
+	case "csharp":
+		// The web SDK's shared framework already carries the options packages
+		for _, name := range []string{
+			"Microsoft.Extensions.Configuration.EnvironmentVariables",
+			"Microsoft.Extensions.Configuration.Json",
+			"Microsoft.Extensions.Options.ConfigurationExtensions",
+			"Microsoft.Extensions.Options.DataAnnotations",
+		} {
+			add(hasConfig && !hasEndpoints, manifestDep{Name: name, Version: "8.0.0"})
+		}
+		add(hasCommands, manifestDep{Name: "System.CommandLine", Version: "2.0.0-beta4.22272.1"})
+		add(hasRepositories, manifestDep{Name: "Microsoft.EntityFrameworkCore.Sqlite", Version: "8.0.0"})
+		add(true, manifestDep{Name: "Microsoft.NET.Test.Sdk", Version: "17.9.0", Dev: true})
+		add(true, manifestDep{Name: "xunit", Version: "2.7.0", Dev: true})
+		add(true, manifestDep{Name: "xunit.runner.visualstudio", Version: "2.5.7", Dev: true})
+		add(hasInterfaces, manifestDep{Name: "Moq", Version: "4.20.70", Dev: true})
+		add(hasProperties, manifestDep{Name: "FsCheck.Xunit", Version: "2.16.6", Dev: true})
+	}
+	return deps
+}
+
+// specTypeExprs returns every type expression the spec's generated code
+// renders: fields, alias targets, method, function and endpoint signatures,
+// command arguments and flags, and configuration items.
+func specTypeExprs(spec *specparser.SpecAnalysis) []string {
+	exprs := signatureTypes(spec.Types, spec.Functions)
+	for _, e := range spec.Endpoints {
+		for _, group := range [][]specparser.SpecParameter{e.PathParams, e.QueryParams, e.HeaderParams} {
+			for _, p := range group {
+				exprs = append(exprs, p.Type)
+			}
+		}
+		exprs = append(exprs, e.RequestType)
+		for _, r := range e.Responses {
+			exprs = append(exprs, r.Type)
+		}
+	}
+	for _, c := range spec.Commands {
+		for _, a := range c.Args {
+			exprs = append(exprs, a.Type)
+		}
+		for _, f := range c.Flags {
+			exprs = append(exprs, f.Type)
+		}
+	}
+	for _, c := range spec.Configuration {
+		exprs = append(exprs, c.Type)
+	}
+	return exprs
+}
+
+// signatureTypes returns the type expressions of the types' fields, alias
+// targets and methods and of the functions' parameters and results.
+func signatureTypes(types []specparser.SpecType, functions []specparser.SpecFunction) []string {
+	var exprs []string
+	addFunction := func(f specparser.SpecFunction) {
+		for _, p := range f.Parameters {
+			exprs = append(exprs, p.Type)
+		}
+		for _, r := range f.Returns {
+			exprs = append(exprs, r.Type)
+		}
+	}
+	for _, t := range types {
+		for _, f := range t.Fields {
+			exprs = append(exprs, f.Type)
+		}
+		exprs = append(exprs, t.AliasOf)
+		for _, m := range t.Methods {
+			addFunction(m)
+		}
+	}
+	for _, f := range functions {
+		addFunction(f)
+	}
+	return exprs
+}
+
+// walkTypeExprs calls fn for every node of the type expressions that parse.
+func walkTypeExprs(exprs []string, fn func(*typeexpr.Expr)) {
+	for _, s := range exprs {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		if expr, err := typeexpr.Parse(s); err == nil {
+			expr.Walk(fn)
+		}
+	}
+}
+
+// usedPrimitives returns the primitive types the type expressions name.
+func usedPrimitives(exprs []string) map[string]bool {
+	used := make(map[string]bool)
+	walkTypeExprs(exprs, func(e *typeexpr.Expr) {
+		if e.Kind == typeexpr.Primitive {
+			used[e.Name] = true
+		}
+	})
+	return used
+}
+
+// packageNamePatterns match the package names each ecosystem accepts. A
+// dependency without per-language packages is only added where its name
+// fits, so "net/http" stays out of pyproject and "uuid" out of go.mod.
+var packageNamePatterns = map[string]*regexp.Regexp{
+	"go":         regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+(/[^\s/]+)*$`),
+	"typescript": regexp.MustCompile(`^(@[a-z0-9._~-]+/)?[a-z0-9._~-]+$`),
+	"python":     regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(\[[\w,\s-]+\])?$`),
+	"java":       regexp.MustCompile(`^[\w.-]+:[\w.-]+$`),
+	"rust":       regexp.MustCompile(`^[A-Za-z0-9_-]+$`),
+	"csharp":     regexp.MustCompile(`^[A-Za-z0-9_.-]+$`),
+}
+
+// specPackages resolves the spec's declared dependencies for a language.
+// A dependency naming per-language packages applies only to those
+// languages; one without applies wherever its name is a valid package.
+// Standard library packages are skipped.
+func specPackages(spec *specparser.SpecAnalysis, langID string) []manifestDep {
+	var deps []manifestDep
+	for _, d := range spec.Dependencies {
+		if d.Stdlib {
+			continue
+		}
+		pkg := d.Name
+		if len(d.Packages) > 0 {
+			p, ok := d.Packages[langID]
+			if !ok {
+				continue
+			}
+			pkg = p
+		}
+
+		// The dependency's version belongs to its own name, not to a
+		// differently named package standing in for it
+		name, version := splitPackage(langID, pkg)
+		if version == "" && d.Version != "" && name == d.Name {
+			version = packageVersion(langID, d.Version)
+		}
+		if pattern := packageNamePatterns[langID]; pattern != nil && !pattern.MatchString(name) {
+			continue
+		}
+		deps = append(deps, manifestDep{Name: name, Version: version})
+	}
+	return deps
+}
+
+// splitPackage separates the version a package name may carry: "mod@v1"
+// or "mod v1" in Go, "group:artifact:1.0" in Java, a PEP 508 specifier in
+// Python, and "name@1.0" elsewhere.
+func splitPackage(langID, pkg string) (string, string) {
+	pkg = strings.TrimSpace(pkg)
+	switch langID {
+	case "java":
+		if parts := strings.Split(pkg, ":"); len(parts) == 3 {
+			return parts[0] + ":" + parts[1], parts[2]
+		}
+		return pkg, ""
+	case "python":
+		if i := strings.IndexAny(pkg, "<>=!~; "); i > 0 {
+			return pkg[:i], strings.TrimSpace(pkg[i:])
+		}
+		return pkg, ""
+	}
+	if name, version, ok := strings.Cut(pkg, " "); ok {
+		return name, strings.Trim(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(version), "=")), `"`)
+	}
+	if at := strings.LastIndex(pkg, "@"); at > 0 {
+		return pkg[:at], pkg[at+1:]
+	}
+	return pkg, ""
+}
+
+// packageVersion renders a spec's version for a language: Go versions
+// start with "v", and a bare Python version becomes a lower bound.
+func packageVersion(langID, version string) string {
+	switch langID {
+	case "go":
+		if !strings.HasPrefix(version, "v") {
+			return "v" + version
+		}
+	case "python":
+		if version[0] >= '0' && version[0] <= '9' {
+			return ">=" + version
+		}
+	}
+	return version
+}
+
+// splitDev separates runtime dependencies from dev dependencies.
+func splitDev(deps []manifestDep) (runtime, dev []manifestDep) {
+	for _, d := range deps {
+		if d.Dev {
+			dev = append(dev, d)
+		} else {
+			runtime = append(runtime, d)
+		}
+	}
+	return runtime, dev
+}
+
+// goMod renders go.mod with a require block for versioned modules. Go
+// cannot require a module without a version, so unversioned ones are left
+// as notes to run go get.
+func goMod(spec *specparser.SpecAnalysis, deps []manifestDep) string {
+	// Method-qualified ServeMux patterns need Go 1.22
+	goVersion := "1.21"
+	if len(spec.Endpoints) > 0 {
+		goVersion = "1.22"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("module %s\n\ngo %s\n", toPackageName(spec.Name), goVersion))
+
+	var required, notes []string
+	for _, d := range deps {
+		if d.Version == "" {
+			notes = append(notes, fmt.Sprintf("// %s has no version in the spec; add it with: go get %s\n", d.Name, d.Name))
+			continue
+		}
+		required = append(required, fmt.Sprintf("\t%s %s\n", d.Name, d.Version))
+	}
+	if len(required) > 0 {
+		sb.WriteString("\nrequire (\n" + strings.Join(required, "") + ")\n")
+	}
+	if len(notes) > 0 {
+		sb.WriteString("\n" + strings.Join(notes, ""))
+	}
+	return sb.String()
+}
+
+// packageJSON renders package.json with dependencies and devDependencies
+// in npm's sorted order; unversioned packages track latest.
+func packageJSON(spec *specparser.SpecAnalysis, deps []manifestDep) string {
+	runtime, dev := splitDev(deps)
+	block := func(name string, deps []manifestDep) string {
+		if len(deps) == 0 {
+			return ""
+		}
+		sort.SliceStable(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+		entries := make([]string, len(deps))
+		for i, d := range deps {
+			version := d.Version
+			if version == "" {
+				version = "latest"
+			}
+			entries[i] = fmt.Sprintf("    %q: %q", d.Name, version)
+		}
+		return fmt.Sprintf(",\n  %q: {\n%s\n  }", name, strings.Join(entries, ",\n"))
+	}
+
+	// Commands install as a binary named after the package
+	bin := ""
+	if len(spec.Commands) > 0 {
+		bin = fmt.Sprintf("\n  \"bin\": {\n    %q: \"dist/cli.js\"\n  },", toPackageName(spec.Name))
+	}
+
+	return fmt.Sprintf(`{
+  "name": "%s",
+  "version": "1.0.0",
+  "type": "module",
+  "main": "dist/index.js",%s
+  "scripts": {
+    "build": "tsc",
+    "test": "vitest"
+  }%s%s
+}
+`, toPackageName(spec.Name), bin, block("dependencies", runtime), block("devDependencies", dev))
+}
+
+// pyproject renders pyproject.toml with runtime dependencies and the test
+// framework as a "test" optional dependency group.
+func pyproject(spec *specparser.SpecAnalysis, deps []manifestDep) string {
+	runtime, dev := splitDev(deps)
+	requirements := func(deps []manifestDep) string {
+		quoted := make([]string, len(deps))
+		for i, d := range deps {
+			quoted[i] = fmt.Sprintf("%q", d.Name+d.Version)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+
+	dependencies := ""
+	if len(runtime) > 0 {
+		dependencies = "dependencies = " + requirements(runtime) + "\n"
+	}
+	optional := ""
+	if len(dev) > 0 {
+		optional = "\n[project.optional-dependencies]\ntest = " + requirements(dev) + "\n"
+	}
+	if len(spec.Commands) > 0 {
+		optional += fmt.Sprintf("\n[project.scripts]\n%s = \"src.cli:main\"\n", toPackageName(spec.Name))
+	}
+
+	return fmt.Sprintf(`[project]
+name = "%s"
+version = "1.0.0"
+requires-python = ">=3.10"
+%s%s
+[build-system]
+requires = ["setuptools>=61.0"]
+build-backend = "setuptools.build_meta"
+
+[tool.pytest.ini_options]
+testpaths = ["tests"]
+`, toPackageName(spec.Name), dependencies, optional)
+}
+
+// pomXML renders a Maven pom.xml targeting Java 17, with dev dependencies
+// in test scope and Surefire for JUnit 5. Maven needs a version for every
+// dependency, so unversioned ones are left as comments.
+func pomXML(spec *specparser.SpecAnalysis, deps []manifestDep) string {
+	var sb strings.Builder
+	pkg := toPackageName(spec.Name)
+	sb.WriteString(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0"
+         xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+         xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 https://maven.apache.org/xsd/maven-4.0.0.xsd">
+  <modelVersion>4.0.0</modelVersion>
+
+  <groupId>%s</groupId>
+  <artifactId>%s</artifactId>
+  <version>1.0.0</version>
+
+  <properties>
+    <maven.compiler.release>17</maven.compiler.release>
+    <project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>
+  </properties>
+`, pkg, pkg))
+
+	if len(deps) > 0 {
+		runtime, dev := splitDev(deps)
+		sb.WriteString("\n  <dependencies>\n")
+		for _, d := range append(runtime, dev...) {
+			group, artifact, _ := strings.Cut(d.Name, ":")
+			if d.Version == "" {
+				sb.WriteString(fmt.Sprintf("    <!-- %s has no version in the spec -->\n", d.Name))
+				continue
+			}
+			sb.WriteString("    <dependency>\n")
+			sb.WriteString(fmt.Sprintf("      <groupId>%s</groupId>\n", group))
+			sb.WriteString(fmt.Sprintf("      <artifactId>%s</artifactId>\n", artifact))
+			sb.WriteString(fmt.Sprintf("      <version>%s</version>\n", d.Version))
+			if d.Dev {
+				sb.WriteString("      <scope>test</scope>\n")
+			}
+			sb.WriteString("    </dependency>\n")
+		}
+		sb.WriteString("  </dependencies>\n")
+	}
+
+	sb.WriteString(`
+  <build>
+    <plugins>
+      <plugin>
+        <groupId>org.apache.maven.plugins</groupId>
+        <artifactId>maven-surefire-plugin</artifactId>
+        <version>3.2.5</version>
+      </plugin>
+    </plugins>
+  </build>
+</project>
+`)
+	return sb.String()
+}
+
+// cargoToml renders Cargo.toml; unversioned crates accept any version.
+func cargoToml(spec *specparser.SpecAnalysis, deps []manifestDep) string {
+	runtime, dev := splitDev(deps)
+	table := func(name string, deps []manifestDep) string {
+		if len(deps) == 0 {
+			return ""
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("\n[%s]\n", name))
+		for _, d := range deps {
+			version := d.Version
+			if version == "" {
+				version = "*"
+			}
+			if len(d.Features) == 0 {
+				sb.WriteString(fmt.Sprintf("%s = %q\n", d.Name, version))
+				continue
+			}
+			features := make([]string, len(d.Features))
+			for i, f := range d.Features {
+				features[i] = fmt.Sprintf("%q", f)
+			}
+			sb.WriteString(fmt.Sprintf("%s = { version = %q, features = [%s] }\n", d.Name, version, strings.Join(features, ", ")))
+		}
+		return sb.String()
+	}
+
+	return fmt.Sprintf(`[package]
+name = "%s"
+version = "0.1.0"
+edition = "2021"
+%s%s`, toPackageName(spec.Name), table("dependencies", runtime), table("dev-dependencies", dev))
+}
+
+// csproj renders an SDK-style project for .NET 8 with a PackageReference
+// per dependency; unversioned packages float to the latest release. Routes
+// use the web SDK, and the project is a library unless the command line
+// gives it an entry point.
+func csproj(spec *specparser.SpecAnalysis, deps []manifestDep) string {
+	sdk := "Microsoft.NET.Sdk"
+	if len(spec.Endpoints) > 0 {
+		sdk = "Microsoft.NET.Sdk.Web"
+	}
+	outputType := "Library"
+	if len(spec.Commands) > 0 {
+		outputType = "Exe"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<Project Sdk="%s">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <OutputType>%s</OutputType>
+    <RootNamespace>%s</RootNamespace>
+    <ImplicitUsings>enable</ImplicitUsings>
+    <Nullable>enable</Nullable>
+  </PropertyGroup>
+`, sdk, outputType, toPascalCase(spec.Name)))
+
+	// The repository tests apply the migrations from the output directory
+	if tables, _ := entityTables(spec); len(tables) > 0 {
+		sb.WriteString("\n  <ItemGroup>\n    <None Include=\"migrations/**\" CopyToOutputDirectory=\"PreserveNewest\" />\n  </ItemGroup>\n")
+	}
+
+	if len(deps) > 0 {
+		runtime, dev := splitDev(deps)
+		sb.WriteString("\n  <ItemGroup>\n")
+		for _, d := range append(runtime, dev...) {
+			version := d.Version
+			if version == "" {
+				version = "*"
+			}
+			sb.WriteString(fmt.Sprintf("    <PackageReference Include=%q Version=%q />\n", d.Name, version))
+		}
+		sb.WriteString("  </ItemGroup>\n")
+	}
+
+	sb.WriteString("\n</Project>\n")
+	return sb.String()
+}
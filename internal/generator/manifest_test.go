@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const ledgerSpec = "# Ledger\n\n" +
+	"## Types\n\n" +
+	"### Entry (struct)\n\n" +
+	"- id: string - Identifier\n\n" +
+	"## Dependencies\n\n" +
+	"- `uuid@1.6.0` - Unique identifiers\n" +
+	"  - go: `github.com/google/uuid@v1.6.0`\n" +
+	"  - typescript: `uuid@^9.0.1`\n" +
+	"  - java: `com.fasterxml.uuid:java-uuid-generator:4.3.0`\n" +
+	"  - rust: `uuid`\n" +
+	"- `github.com/shopspring/decimal` - Money arithmetic\n\n" +
+	"| Package | Version | Purpose | C# |\n" +
+	"|---------|---------|---------|----|\n" +
+	"| humanize | 4.9.0 | Readable numbers | Humanizer.Core |\n\n" +
+	"### Go Standard Library\n\n" +
+	"| Package | Purpose |\n" +
+	"|---------|---------|\n" +
+	"| net/http | HTTP server |\n"
+
+func TestGenerateManifests(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(ledgerSpec, "ledger.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected []string
+		absent   []string
+	}{
+		{"go", "go.mod", []string{
+			"require (\n\tgithub.com/google/uuid v1.6.0\n)",
+			"// github.com/shopspring/decimal has no version in the spec",
+		}, []string{"net/http", "humanize"}},
+		{"typescript", "package.json", []string{
+			"\"dependencies\": {\n    \"uuid\": \"^9.0.1\",\n    \"zod\": \"^3.22.0\"\n  }",
+			`"vitest": "^1.0.0"`,
+		}, []string{"shopspring", "humanize"}},
+		{"python", "pyproject.toml", []string{
+			`dependencies = ["pydantic>=2"]`,
+			"[project.optional-dependencies]\ntest = [\"pytest>=8\"]",
+		}, []string{"uuid", "humanize"}},
+		{"java", "pom.xml", []string{
+			"<artifactId>java-uuid-generator</artifactId>\n      <version>4.3.0</version>",
+			"<artifactId>junit-jupiter</artifactId>\n      <version>5.10.2</version>\n      <scope>test</scope>",
+			"<artifactId>maven-surefire-plugin</artifactId>",
+		}, nil},
+		{"rust", "Cargo.toml", []string{
+			`uuid = "1.6.0"`,
+		}, []string{"[dev-dependencies]", "humanize"}},
+		{"csharp", "Ledger.csproj", []string{
+			`<Project Sdk="Microsoft.NET.Sdk">`,
+			`<PackageReference Include="Humanizer.Core" Version="*" />`,
+			`<PackageReference Include="xunit" Version="2.7.0" />`,
+		}, []string{"uuid", "humanize"}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			files := generatedFiles(t, gen, spec, tt.language)
+			expectGenerated(t, files, map[string][]string{tt.path: tt.expected})
+			for _, unwanted := range tt.absent {
+				if strings.Contains(files[tt.path], unwanted) {
+					t.Errorf("Expected %s not to contain %q, got:\n%s", tt.path, unwanted, files[tt.path])
+				}
+			}
+		})
+	}
+}
+
+const journalSpec = "# Journal\n\n" +
+	"## Types\n\n" +
+	"### Posting (struct)\n\n" +
+	"- id: uuid - Identifier\n" +
+	"- amount: decimal - Amount\n" +
+	"- bookedAt: datetime - Booking time\n\n" +
+	"## Functions\n\n" +
+	"### balance\n\n" +
+	"Sums the postings booked since a day.\n\n" +
+	"**Parameters**\n" +
+	"- `postings: List[Posting]` - Postings to sum\n" +
+	"- `since: date` - First day\n\n" +
+	"**Returns** `decimal`\n"
+
+func TestGenerateTypeLibraries(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(journalSpec, "journal.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected []string
+	}{
+		{"rust", "Cargo.toml", []string{
+			`chrono = { version = "0.4", features = ["serde"] }`,
+			`uuid = { version = "1", features = ["serde"] }`,
+		}},
+		{"java", "src/main/java/journal/Types.java", []string{
+			"import java.math.BigDecimal;\nimport java.time.LocalDateTime;\nimport java.util.UUID;\n",
+		}},
+		{"java", "src/main/java/journal/Service.java", []string{
+			"import java.math.BigDecimal;\nimport java.time.LocalDate;\nimport java.util.List;\n",
+		}},
+		{"go", "service.go", []string{
+			"import (\n\t\"time\"\n)\n",
+		}},
+		{"python", "src/service.py", []string{
+			"from datetime import datetime\nfrom decimal import Decimal\nfrom typing import Optional, Any, List\n",
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language+"/"+tt.path, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), map[string][]string{tt.path: tt.expected})
+		})
+	}
+}
+
+// csharpCheckProject compiles a generated C# project's sources without its
+// test packages, which a build could only restore from NuGet.
+const csharpCheckProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <ImplicitUsings>enable</ImplicitUsings>
+    <Nullable>enable</Nullable>
+    <EnableDefaultCompileItems>false</EnableDefaultCompileItems>
+  </PropertyGroup>
+  <ItemGroup>
+    <Compile Include="../src/**/*.cs" />
+  </ItemGroup>
+</Project>
+`
+
+func TestTypeLibrariesBuild(t *testing.T) {
+	tests := []struct {
+		language string
+		tool     string
+		build    func(t *testing.T, dir string)
+	}{
+		{"go", "go", func(t *testing.T, dir string) {
+			runTool(t, dir, "go", "vet", "./...")
+		}},
+		{"rust", "cargo", func(t *testing.T, dir string) {
+			runTool(t, dir, "cargo", "build", "--offline", "--quiet")
+		}},
+		{"python", "python3", func(t *testing.T, dir string) {
+			runTool(t, dir, "python3", "-m", "compileall", "-q", "src")
+		}},
+		{"typescript", "tsc", func(t *testing.T, dir string) {
+			runTool(t, dir, "npm", "install", "--offline", "--no-audit", "--no-fund")
+			runTool(t, dir, "tsc", "--noEmit", "-p", ".")
+		}},
+		{"java", "mvn", func(t *testing.T, dir string) {
+			runTool(t, dir, "mvn", "--offline", "--quiet", "compile")
+		}},
+		{"csharp", "dotnet", func(t *testing.T, dir string) {
+			writeFile(t, dir, "check/Check.csproj", csharpCheckProject)
+			runTool(t, filepath.Join(dir, "check"), "dotnet", "build", "--nologo")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			requireTool(t, tt.tool)
+			tt.build(t, generateProject(t, journalSpec, tt.language))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package generator
+
+import "strings"
+
+// mergeHunk is a region where ours and theirs both changed the base
+// differently and no automatic resolution is possible.
+type mergeHunk struct {
+	Base   []string
+	Ours   []string
+	Theirs []string
+
+	// Start and End delimit the hunk's lines in the merged output
+	// (End is exclusive). The merged output keeps the ours side.
+	Start int
+	End   int
+}
+
+// mergeResult is the output of a three-way merge.
+type mergeResult struct {
+	Lines     []string
+	Conflicts []mergeHunk
+}
+
+// merge3 performs a line-based three-way merge (diff3) of ours and theirs
+// against their common base. Changes made on only one side are applied;
+// identical changes on both sides are applied once; overlapping different
+// changes are reported as conflicts and resolved in favour of ours so that
+// hand-written code is never lost.
+func merge3(base, ours, theirs []string) mergeResult {
+	toOurs := matchIndex(base, ours)
+	toTheirs := matchIndex(base, theirs)
+
+	var result mergeResult
+	io, ia, ib := 0, 0, 0
+
+	for {
+		// Copy stable lines: unchanged in both ours and theirs
+		for io < len(base) && toOurs[io] == ia && toTheirs[io] == ib {
+			result.Lines = append(result.Lines, base[io])
+			io++
+			ia++
+			ib++
+		}
+
+		// Find the next base line matched on both sides
+		o := io
+		for o < len(base) && (toOurs[o] < 0 || toTheirs[o] < 0) {
+			o++
+		}
+
+		a, b := len(ours), len(theirs)
+		if o < len(base) {
+			a, b = toOurs[o], toTheirs[o]
+		}
+
+		baseChunk, oursChunk, theirsChunk := base[io:o], ours[ia:a], theirs[ib:b]
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			result.Lines = append(result.Lines, theirsChunk...)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			result.Lines = append(result.Lines, oursChunk...)
+		default:
+			start := len(result.Lines)
+			result.Lines = append(result.Lines, oursChunk...)
+			result.Conflicts = append(result.Conflicts, mergeHunk{
+				Base:   baseChunk,
+				Ours:   oursChunk,
+				Theirs: theirsChunk,
+				Start:  start,
+				End:    len(result.Lines),
+			})
+		}
+
+		io, ia, ib = o, a, b
+		if io >= len(base) {
+			break
+		}
+	}
+
+	return result
+}
+
+// matchIndex maps each line of a to the index of its matching line in b
+// along a longest common subsequence, or -1 when the line was changed.
+func matchIndex(a, b []string) []int {
+	index := make([]int, len(a))
+	for i := range index {
+		index[i] = -1
+	}
+	for _, m := range lcsMatches(a, b) {
+		index[m[0]] = m[1]
+	}
+	return index
+}
+
+// lcsMatches returns the (i, j) index pairs of matching lines between a and b
+// using Myers' O(ND) difference algorithm.
+func lcsMatches(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+
+	// trace[d] holds the furthest-reaching x for diagonals -d..d before
+	// step d, which is enough to walk the edit path back afterwards.
+	var trace [][]int
+	found := false
+
+	for d := 0; d <= maxD && !found; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var matches [][2]int
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := 0
+		if d > 0 {
+			prevX = at(prevK)
+		}
+		prevY := prevX - prevK
+		if d == 0 {
+			prevY = 0
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matches = append(matches, [2]int{x, y})
+		}
+		x, y = prevX, prevY
+	}
+
+	// Reverse into ascending order
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches
+}
+
+// splitLines splits content into lines, dropping the trailing newline.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// joinLines is the inverse of splitLines.
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		expected  string
+		conflicts int
+	}{
+		{
+			name:     "no changes",
+			base:     "a\nb\nc\n",
+			ours:     "a\nb\nc\n",
+			theirs:   "a\nb\nc\n",
+			expected: "a\nb\nc\n",
+		},
+		{
+			name:     "ours only",
+			base:     "a\nb\nc\n",
+			ours:     "a\nB\nc\n",
+			theirs:   "a\nb\nc\n",
+			expected: "a\nB\nc\n",
+		},
+		{
+			name:     "theirs only",
+			base:     "a\nb\nc\n",
+			ours:     "a\nb\nc\n",
+			theirs:   "a\nb\nc\nd\n",
+			expected: "a\nb\nc\nd\n",
+		},
+		{
+			name:     "non-overlapping changes",
+			base:     "a\nb\nc\nd\ne\n",
+			ours:     "A\nb\nc\nd\ne\n",
+			theirs:   "a\nb\nc\nd\nE\n",
+			expected: "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:     "identical changes",
+			base:     "a\nb\nc\n",
+			ours:     "a\nX\nc\n",
+			theirs:   "a\nX\nc\n",
+			expected: "a\nX\nc\n",
+		},
+		{
+			name:      "overlapping changes keep ours",
+			base:      "a\nb\nc\n",
+			ours:      "a\nours\nc\n",
+			theirs:    "a\ntheirs\nc\n",
+			expected:  "a\nours\nc\n",
+			conflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := merge3(splitLines(tt.base), splitLines(tt.ours), splitLines(tt.theirs))
+			if got := joinLines(result.Lines); got != tt.expected {
+				t.Errorf("merge3() = %q, expected %q", got, tt.expected)
+			}
+			if len(result.Conflicts) != tt.conflicts {
+				t.Errorf("merge3() conflicts = %d, expected %d", len(result.Conflicts), tt.conflicts)
+			}
+		})
+	}
+}
+
+func regenSpec() *specparser.SpecAnalysis {
+	return &specparser.SpecAnalysis{
+		Name: "users",
+		Functions: []specparser.SpecFunction{
+			{Name: "CreateUser", Returns: []specparser.SpecReturn{{Type: "string"}}},
+			{Name: "DeleteUser", Returns: []specparser.SpecReturn{{Type: "bool"}}},
+		},
+	}
+}
+
+func TestRegeneratePreservesHandWrittenCode(t *testing.T) {
+	dir := t.TempDir()
+	gen := NewGenerator(languages.NewRegistry())
+
+	if _, err := gen.Generate(regenSpec(), "go", dir); err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	servicePath := filepath.Join(dir, "service.go")
+	original, err := os.ReadFile(servicePath)
+	if err != nil {
+		t.Fatalf("Failed to read service.go: %v", err)
+	}
+	if !strings.HasPrefix(string(original), "// rpg:generated hash=") {
+		t.Fatalf("Expected header hash, got:\n%s", original)
+	}
+
+	// Hand-edit CreateUser, then change DeleteUser in the spec
+	edited := strings.Replace(string(original), "func CreateUser() string {\n", "func CreateUser() string {\n\t// hand-written\n", 1)
+	if edited == string(original) {
+		t.Fatalf("Unexpected generated code:\n%s", original)
+	}
+	if err := os.WriteFile(servicePath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := regenSpec()
+	spec.Functions[1].Returns = []specparser.SpecReturn{{Type: "error"}}
+
+	result, err := gen.Regenerate(spec, "go", dir)
+	if err != nil {
+		t.Fatalf("Regenerate() error: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %+v", result.Conflicts)
+	}
+
+	for _, f := range result.Files {
+		if f.Path != "service.go" {
+			continue
+		}
+		if f.Status != StatusUpdated || !f.UserModified {
+			t.Errorf("Expected updated, user-modified service.go, got %+v", f)
+		}
+		if strings.Join(f.ChangedElements, ",") != "function:DeleteUser" {
+			t.Errorf("Expected only DeleteUser to change, got %v", f.ChangedElements)
+		}
+	}
+
+	merged, _ := os.ReadFile(servicePath)
+	if !strings.Contains(string(merged), "// hand-written") {
+		t.Errorf("Expected hand-written code to be preserved:\n%s", merged)
+	}
+	if !strings.Contains(string(merged), "func DeleteUser() error") {
+		t.Errorf("Expected DeleteUser to be regenerated:\n%s", merged)
+	}
+}
+
+func TestRegenerateReportsConflicts(t *testing.T) {
+	dir := t.TempDir()
+	gen := NewGenerator(languages.NewRegistry())
+
+	if _, err := gen.Generate(regenSpec(), "go", dir); err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// Hand-edit the signature line that the spec change will also rewrite
+	servicePath := filepath.Join(dir, "service.go")
+	original, _ := os.ReadFile(servicePath)
+	edited := strings.Replace(string(original), "func DeleteUser() bool {", "func DeleteUser() (deleted bool) {", 1)
+	if err := os.WriteFile(servicePath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := regenSpec()
+	spec.Functions[1].Returns = []specparser.SpecReturn{{Type: "error"}}
+
+	result, err := gen.Regenerate(spec, "go", dir)
+	if err != nil {
+		t.Fatalf("Regenerate() error: %v", err)
+	}
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %+v", result.Conflicts)
+	}
+	if c := result.Conflicts[0]; c.Element != "function:DeleteUser" || c.SpecHash == "" {
+		t.Errorf("Unexpected conflict: %+v", c)
+	}
+
+	merged, _ := os.ReadFile(servicePath)
+	if !strings.Contains(string(merged), "func DeleteUser() (deleted bool) {") {
+		t.Errorf("Expected local version to be kept:\n%s", merged)
+	}
+
+	// The conflict persists until resolved
+	again, err := gen.Regenerate(spec, "go", dir)
+	if err != nil {
+		t.Fatalf("Regenerate() error: %v", err)
+	}
+	if len(again.Conflicts) != 1 {
+		t.Errorf("Expected conflict to be reported again, got %+v", again.Conflicts)
+	}
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// specInterfaces returns the spec's interface types.
+func specInterfaces(spec *specparser.SpecAnalysis) []specparser.SpecType {
+	var interfaces []specparser.SpecType
+	for _, t := range spec.Types {
+		if t.Kind == "interface" {
+			interfaces = append(interfaces, t)
+		}
+	}
+	return interfaces
+}
+
+// generateMocks generates a test double for each interface in the
+// language's usual style: recording fakes in Go, vitest mocks in
+// TypeScript, create_autospec fixtures in Python, Mockito mocks in Java,
+// mockall mocks in Rust and Moq mocks in C#. Each double is rendered from
+// the language's mock template.
+func (g *Generator) generateMocks(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	interfaces := specInterfaces(spec)
+	if len(interfaces) == 0 {
+		return nil
+	}
+
+	lang := adapter.GetLanguage()
+	pkg := toPackageName(spec.Name)
+	module := func(name string) string { return typeModule(spec, name) }
+
+	var names []string
+	var body strings.Builder
+	for _, t := range interfaces {
+		names = append(names, t.Name)
+		code := g.render(lang.ID, "mock", newTypeView(t, spec.Types, lang))
+		body.WriteString("\n")
+		body.WriteString(wrapRegion(lang.ID, "mock", t.Name, hashOf(t), code))
+	}
+	code := body.String()
+
+	// The interfaces, and for Go and Rust the types their methods use,
+	// grouped by the module declaring them
+	declared := make(map[string]bool)
+	for _, t := range spec.Types {
+		declared[typeName(t.Name)] = true
+	}
+	used := append([]string(nil), names...)
+	for _, name := range referencedTypes(interfaces, nil) {
+		if declared[name] && !containsString(used, name) {
+			used = append(used, name)
+		}
+	}
+	var foreign []moduleNames
+	for _, group := range byModule(used, module) {
+		if group.Module != "" {
+			foreign = append(foreign, group)
+		}
+	}
+
+	var sb strings.Builder
+	var path string
+	switch lang.ID {
+	case "go":
+		path = "mocks_test.go"
+		sb.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+		sb.WriteString(`// FakeCall is a call recorded by a fake: the method called and its
+// arguments.
+type FakeCall struct {
+	Method string
+	Args   []any
+}
+`)
+		sb.WriteString(code)
+
+	case "typescript":
+		path = "src/mocks.ts"
+		sb.WriteString("import { vi, type Mocked } from \"vitest\";\n")
+		for _, group := range byModule(names, module) {
+			sb.WriteString(fmt.Sprintf("import type { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath(path, group.Module, "types")))
+		}
+		sb.WriteString(code)
+
+	case "python":
+		path = "tests/conftest.py"
+		sb.WriteString("from unittest.mock import create_autospec\n\nimport pytest\n\n")
+		sb.WriteString(moduleImports(spec, lang.ID, "", byModule(names, module)))
+		sb.WriteString(strings.ReplaceAll(code, "\n# rpg:begin", "\n\n# rpg:begin"))
+
+	case "java":
+		path = fmt.Sprintf("src/test/java/%s/Mocks.java", pkg)
+		sb.WriteString(fmt.Sprintf("package %s;\n\n", pkg))
+		sb.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		sb.WriteString("import static org.mockito.Mockito.mock;\n\n")
+		sb.WriteString("/** Mockito mocks of the interfaces. */\npublic final class Mocks {\n    private Mocks() {}\n")
+		sb.WriteString(code)
+		sb.WriteString("}\n")
+
+	case "rust":
+		path = "src/mocks.rs"
+		sb.WriteString("//! mockall mocks of the traits, for unit tests.\n\nuse mockall::mock;\n\n")
+		for _, group := range byModule(used, module) {
+			sb.WriteString(fmt.Sprintf("use %s::{%s};\n", rustModulePath("crate", group.Module, "types"), strings.Join(group.Names, ", ")))
+		}
+		sb.WriteString(code)
+
+	case "csharp":
+		path = "tests/Mocks.cs"
+		sb.WriteString("using Moq;\n")
+		sb.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		sb.WriteString(fmt.Sprintf("\nnamespace %s.Tests\n{\n", toPascalCase(spec.Name)))
+		sb.WriteString("    /// <summary>\n    /// Moq mocks of the interfaces.\n    /// </summary>\n    public static class Mocks\n    {")
+		sb.WriteString(code)
+		sb.WriteString("    }\n}\n")
+
+	default:
+		return nil
+	}
+
+	content := sb.String()
+	if lang.ID == "go" {
+		content = qualifyGo(spec, content, foreign)
+	}
+	return []GeneratedFile{{Path: path, Content: content, Category: "test", Elements: names}}
+}
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+func TestGenerateMocks(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(storeInterfaceSpec, "store.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		files    map[string][]string
+	}{
+		{"go", map[string][]string{
+			"mocks_test.go": {
+				"type FakeCall struct {",
+				"\tFindFunc func(id string) User\n",
+				"var _ UserStore = (*FakeUserStore)(nil)",
+				"func (f *FakeUserStore) Find(id string) (r0 User) {\n\tf.Calls = append(f.Calls, FakeCall{Method: \"Find\", Args: []any{id}})\n\tif f.FindFunc != nil {\n\t\treturn f.FindFunc(id)\n\t}\n\treturn\n}",
+				"\tif f.SaveFunc != nil {\n\t\tf.SaveFunc(user, overwrite)\n\t}\n}",
+			},
+		}},
+		{"typescript", map[string][]string{
+			"src/mocks.ts": {"import type { UserStore } from \"./types\";", "export function mockUserStore(): Mocked<UserStore> {", "    find: vi.fn(),\n    save: vi.fn(),\n    list: vi.fn(),\n"},
+		}},
+		{"python", map[string][]string{
+			"tests/conftest.py": {"from src.types import UserStore", "def mock_user_store():", "return create_autospec(UserStore, instance=True)"},
+		}},
+		{"java", map[string][]string{
+			"src/test/java/store/Mocks.java": {"public static UserStore mockUserStore() {\n        return mock(UserStore.class);"},
+			"pom.xml":                        {"<artifactId>mockito-core</artifactId>"},
+		}},
+		{"rust", map[string][]string{
+			"src/mocks.rs": {"use crate::types::{UserStore, User};", "    impl UserStore for UserStore {\n        fn find(&self, id: String) -> User;\n", "        async fn list(&self, limit: i32) -> Vec<User>;\n"},
+			"src/lib.rs":   {"#[cfg(test)]\npub mod mocks;"},
+			"Cargo.toml":   {"mockall"},
+		}},
+		{"csharp", map[string][]string{
+			"tests/Mocks.cs": {"public static Mock<UserStore> MockUserStore() => new Mock<UserStore>();"},
+			"Store.csproj":   {"\"Moq\""},
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), tt.files)
+		})
+	}
+
+	// Specs without interfaces get no mocks
+	plain, err := specparser.NewParser().Parse(storeSpec, "shop.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	adapter, _ := gen.registry.Get("java")
+	if files := gen.generateMocks(plain, adapter); len(files) != 0 {
+		t.Errorf("Expected no mocks, got %+v", files)
+	}
+}
+
+func TestGoMocksRun(t *testing.T) {
+	requireTool(t, "go")
+	runTool(t, generateProject(t, storeInterfaceSpec, "go"), "go", "test", "./...")
+}
@@ -0,0 +1,516 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/layout"
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// moduleGroup holds the types and functions a spec declares in one module.
+type moduleGroup struct {
+	// Module is the module path ("" for the root module)
+	Module string
+
+	Types     []specparser.SpecType
+	Functions []specparser.SpecFunction
+}
+
+// specModules groups a spec's types and functions by module: the root
+// module first, then the others in the order they first appear.
+func specModules(spec *specparser.SpecAnalysis) []moduleGroup {
+	groups := []moduleGroup{{}}
+	index := map[string]int{"": 0}
+	group := func(module string) *moduleGroup {
+		i, ok := index[module]
+		if !ok {
+			i = len(groups)
+			index[module] = i
+			groups = append(groups, moduleGroup{Module: module})
+		}
+		return &groups[i]
+	}
+	for _, t := range spec.Types {
+		g := group(t.Module)
+		g.Types = append(g.Types, t)
+	}
+	for _, f := range spec.Functions {
+		g := group(f.Module)
+		g.Functions = append(g.Functions, f)
+	}
+	return groups
+}
+
+// typeModule returns the module declaring a type, matching the names
+// derived from it (such as TypeScript's schemas) too.
+func typeModule(spec *specparser.SpecAnalysis, name string) string {
+	for _, t := range spec.Types {
+		if name == t.Name || name == typeName(t.Name) || name == tsSchemaName(t.Name) || name == tsToWireName(t.Name) {
+			return t.Module
+		}
+	}
+	return ""
+}
+
+// moduleFuncIdent returns a function's identifier. Go exports the
+// functions of modules other than the root so the root package's handlers
+// can call them.
+func moduleFuncIdent(langID string, f specparser.SpecFunction) string {
+	ident := funcIdent(langID, f.Name)
+	if langID == "go" && f.Module != "" {
+		return strings.ToUpper(ident[:1]) + ident[1:]
+	}
+	return ident
+}
+
+// moduleNames is a list of names declared in one module.
+type moduleNames struct {
+	Module string
+	Names  []string
+}
+
+// byModule groups names by the module declaring them, in the order the
+// modules first appear. Names keep their order within a module.
+func byModule(names []string, module func(string) string) []moduleNames {
+	var groups []moduleNames
+	index := make(map[string]int)
+	for _, name := range names {
+		m := module(name)
+		i, ok := index[m]
+		if !ok {
+			i = len(groups)
+			index[m] = i
+			groups = append(groups, moduleNames{Module: m})
+		}
+		groups[i].Names = append(groups[i].Names, name)
+	}
+	return groups
+}
+
+// referencedTypes returns the user types named by the types' fields and
+// methods and the functions' parameters and returns, sorted.
+func referencedTypes(types []specparser.SpecType, functions []specparser.SpecFunction) []string {
+	seen := make(map[string]bool)
+	collect := func(pseudoType string) {
+		expr, err := typeexpr.Parse(pseudoType)
+		if err != nil {
+			seen[typeName(pseudoType)] = true
+			return
+		}
+		expr.RenderNamed("go", func(name string) string {
+			seen[typeName(name)] = true
+			return name
+		})
+	}
+	var methods []specparser.SpecFunction
+	for _, t := range types {
+		for _, f := range t.Fields {
+			collect(f.Type)
+		}
+		methods = append(methods, t.Methods...)
+	}
+	for _, f := range append(methods, functions...) {
+		for _, p := range f.Parameters {
+			collect(p.Type)
+		}
+		for _, r := range f.Returns {
+			collect(r.Type)
+		}
+	}
+
+	var names []string
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// usedTypes returns the declared types the types and functions reference,
+// grouped by the module declaring them.
+func usedTypes(spec *specparser.SpecAnalysis, types []specparser.SpecType, functions []specparser.SpecFunction) []moduleNames {
+	declared := make(map[string]bool)
+	for _, t := range spec.Types {
+		declared[typeName(t.Name)] = true
+	}
+	var names []string
+	for _, name := range referencedTypes(types, functions) {
+		if declared[name] {
+			names = append(names, name)
+		}
+	}
+	return byModule(names, func(name string) string { return typeModule(spec, name) })
+}
+
+// foreignTypes returns the referenced types declared in modules other than
+// module, grouped by the module declaring them.
+func foreignTypes(spec *specparser.SpecAnalysis, module string, types []specparser.SpecType, functions []specparser.SpecFunction) []moduleNames {
+	var groups []moduleNames
+	for _, g := range usedTypes(spec, types, functions) {
+		if g.Module != module {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// goImportPath returns the import path of a module's Go package.
+func goImportPath(spec *specparser.SpecAnalysis, module string) string {
+	return path.Join(toPackageName(spec.Name), layout.Dir("go", module))
+}
+
+// tsModulePath returns the import specifier of a module's TypeScript file
+// (such as "types" or "service") relative to the importing file.
+func tsModulePath(from, module, base string) string {
+	target := layout.Path("typescript", module, "src/"+base)
+	rel := relativePath(path.Dir(from), target)
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+// relativePath returns the slash-separated path of target from dir.
+func relativePath(dir, target string) string {
+	from := strings.Split(path.Clean(dir), "/")
+	to := strings.Split(path.Clean(target), "/")
+	if from[0] == "." {
+		from = nil
+	}
+	n := 0
+	for n < len(from) && n < len(to)-1 && from[n] == to[n] {
+		n++
+	}
+	parts := make([]string, 0, len(from)-n+len(to)-n)
+	for range from[n:] {
+		parts = append(parts, "..")
+	}
+	return strings.Join(append(parts, to[n:]...), "/")
+}
+
+// pythonModulePath returns the dotted path of a module's Python file from
+// the project root, such as "src.billing.types".
+func pythonModulePath(module, base string) string {
+	if module == "" {
+		return "src." + base
+	}
+	return "src." + layout.Package("python", "", module) + "." + base
+}
+
+// pythonRelativePath returns a module's Python file relative to the src
+// package, such as ".billing.types".
+func pythonRelativePath(module, base string) string {
+	if module == "" {
+		return "." + base
+	}
+	return "." + layout.Package("python", "", module) + "." + base
+}
+
+// rustModulePath returns a module's Rust file below crate, such as
+// "crate::billing::types".
+func rustModulePath(crate, module, base string) string {
+	if module == "" {
+		return crate + "::" + base
+	}
+	return crate + "::" + layout.Package("rust", "", module) + "::" + base
+}
+
+// serviceRef returns how Java, Rust and C# handlers refer to a module's
+// service: the root module's by name, others by their qualified path.
+func serviceRef(spec *specparser.SpecAnalysis, langID, module string) string {
+	switch {
+	case langID == "rust" && module == "":
+		return "service"
+	case module == "":
+		return "Service"
+	case langID == "java":
+		return layout.Package(langID, toPackageName(spec.Name), module) + ".Service"
+	case langID == "rust":
+		return rustModulePath("crate", module, "service")
+	case langID == "csharp":
+		return layout.Package(langID, toPascalCase(spec.Name), module) + ".Service"
+	}
+	return ""
+}
+
+// moduleImports returns the statements importing the named types from the
+// types files of their modules. file is the importing file, which
+// TypeScript imports relative to.
+func moduleImports(spec *specparser.SpecAnalysis, langID, file string, groups []moduleNames) string {
+	var sb strings.Builder
+	for _, g := range groups {
+		switch langID {
+		case "typescript":
+			sb.WriteString(fmt.Sprintf("import { %s } from %q;\n", strings.Join(g.Names, ", "), tsModulePath(file, g.Module, "types")))
+		case "python":
+			sb.WriteString(fmt.Sprintf("from %s import %s\n", pythonModulePath(g.Module, "types"), strings.Join(g.Names, ", ")))
+		case "java":
+			sb.WriteString(fmt.Sprintf("import %s.*;\n", layout.Package("java", toPackageName(spec.Name), g.Module)))
+		case "rust":
+			sb.WriteString(fmt.Sprintf("use %s::{%s};\n", rustModulePath("crate", g.Module, "types"), strings.Join(g.Names, ", ")))
+		case "csharp":
+			sb.WriteString(fmt.Sprintf("using %s;\n", layout.Package("csharp", toPascalCase(spec.Name), g.Module)))
+		}
+	}
+	return sb.String()
+}
+
+// goTypeImports returns the packages the rendered type expressions need
+// imported in Go, such as time for a date.
+func goTypeImports(exprs []string) []string {
+	var imports []string
+	walkTypeExprs(exprs, func(e *typeexpr.Expr) {
+		switch e.Name {
+		case typeexpr.Date, typeexpr.DateTime, typeexpr.Duration:
+			if e.Kind == typeexpr.Primitive && !containsString(imports, "time") {
+				imports = append(imports, "time")
+			}
+		}
+	})
+	return imports
+}
+
+// javaTypeImports returns the classes the rendered type expressions need
+// imported in Java, such as java.math.BigDecimal for a decimal.
+func javaTypeImports(exprs []string) []string {
+	classes := map[string]string{
+		typeexpr.Decimal: "java.math.BigDecimal", typeexpr.Date: "java.time.LocalDate",
+		typeexpr.DateTime: "java.time.LocalDateTime", typeexpr.Duration: "java.time.Duration", typeexpr.UUID: "java.util.UUID",
+	}
+	var imports []string
+	add := func(path string) {
+		if path != "" && !containsString(imports, path) {
+			imports = append(imports, path)
+		}
+	}
+	walkTypeExprs(exprs, func(e *typeexpr.Expr) {
+		switch e.Kind {
+		case typeexpr.Primitive:
+			add(classes[e.Name])
+		case typeexpr.List:
+			add("java.util.List")
+		case typeexpr.Map:
+			add("java.util.Map")
+		case typeexpr.Set:
+			add("java.util.Set")
+		case typeexpr.Tuple:
+			if len(e.Args) == 2 {
+				add("java.util.Map")
+			} else {
+				add("java.util.List")
+			}
+		}
+	})
+	sort.Strings(imports)
+	return imports
+}
+
+// pythonTypeImports returns the statements importing the standard library
+// and typing names the rendered type expressions use in Python, always
+// including Optional and Any.
+func pythonTypeImports(exprs []string) string {
+	var datetimes []string
+	decimal := false
+	typing := []string{"Optional", "Any"}
+	add := func(names *[]string, name string) {
+		if !containsString(*names, name) {
+			*names = append(*names, name)
+		}
+	}
+	walkTypeExprs(exprs, func(e *typeexpr.Expr) {
+		switch e.Kind {
+		case typeexpr.Primitive:
+			switch e.Name {
+			case typeexpr.Date, typeexpr.DateTime:
+				add(&datetimes, "datetime")
+			case typeexpr.Duration:
+				add(&datetimes, "timedelta")
+			case typeexpr.Decimal:
+				decimal = true
+			}
+		case typeexpr.List:
+			add(&typing, "List")
+		case typeexpr.Map:
+			add(&typing, "Dict")
+		case typeexpr.Set:
+			add(&typing, "Set")
+		case typeexpr.Tuple:
+			add(&typing, "Tuple")
+		case typeexpr.Union:
+			add(&typing, "Union")
+		case typeexpr.Func:
+			add(&typing, "Callable")
+		}
+	})
+
+	var sb strings.Builder
+	if len(datetimes) > 0 {
+		sort.Strings(datetimes)
+		sb.WriteString("from datetime import " + strings.Join(datetimes, ", ") + "\n")
+	}
+	if decimal {
+		sb.WriteString("from decimal import Decimal\n")
+	}
+	sb.WriteString("from typing import " + strings.Join(typing, ", ") + "\n")
+	return sb.String()
+}
+
+// rustModuleFiles returns the mod.rs declaring the files of each module
+// directory, down from the top-level modules lib.rs declares.
+func rustModuleFiles(spec *specparser.SpecAnalysis) []GeneratedFile {
+	children := make(map[string][]string)
+	var dirs []string
+	add := func(dir, child string) {
+		if _, ok := children[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		if !containsString(children[dir], child) {
+			children[dir] = append(children[dir], child)
+		}
+	}
+	for _, g := range specModules(spec)[1:] {
+		dir := layout.Dir("rust", g.Module)
+		if len(g.Types) > 0 {
+			add(dir, "types")
+		}
+		if len(g.Functions) > 0 {
+			add(dir, "service")
+		}
+		for d := dir; strings.Contains(d, "/"); d = path.Dir(d) {
+			add(path.Dir(d), path.Base(d))
+		}
+	}
+
+	var files []GeneratedFile
+	for _, dir := range dirs {
+		var sb strings.Builder
+		for _, child := range children[dir] {
+			sb.WriteString(fmt.Sprintf("pub mod %s;\n", child))
+		}
+		files = append(files, GeneratedFile{
+			Path:     "src/" + dir + "/mod.rs",
+			Content:  sb.String(),
+			Category: "config",
+		})
+	}
+	return files
+}
+
+// rustTopModules returns the top-level module directories lib.rs declares.
+func rustTopModules(spec *specparser.SpecAnalysis) []string {
+	var tops []string
+	for _, g := range specModules(spec)[1:] {
+		top := strings.Split(layout.Dir("rust", g.Module), "/")[0]
+		if !containsString(tops, top) {
+			tops = append(tops, top)
+		}
+	}
+	return tops
+}
+
+// qualifyGo rewrites a Go file's references to names declared in other
+// modules' packages as qualified names, adding the imports they need. The
+// file must parse; otherwise it is returned unchanged for formatGo to
+// report.
+func qualifyGo(spec *specparser.SpecAnalysis, content string, groups []moduleNames) string {
+	if len(groups) == 0 {
+		return content
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return content
+	}
+
+	qualifier := make(map[string]string)
+	var imports []string
+	for _, g := range groups {
+		pkg := layout.Package("go", toPackageName(spec.Name), g.Module)
+		if imp := fmt.Sprintf("\t%q\n", goImportPath(spec, g.Module)); !containsString(imports, imp) {
+			imports = append(imports, imp)
+		}
+		for _, name := range g.Names {
+			qualifier[name] = pkg
+		}
+	}
+
+	// Names that declare or select rather than refer to a type
+	skip := make(map[*ast.Ident]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			skip[n.Sel] = true
+		case *ast.Field:
+			for _, name := range n.Names {
+				skip[name] = true
+			}
+		case *ast.KeyValueExpr:
+			if id, ok := n.Key.(*ast.Ident); ok {
+				skip[id] = true
+			}
+		case *ast.TypeSpec:
+			skip[n.Name] = true
+		case *ast.FuncDecl:
+			skip[n.Name] = true
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				skip[name] = true
+			}
+		}
+		return true
+	})
+
+	var offsets []int
+	names := make(map[int]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && !skip[id] {
+			if pkg, ok := qualifier[id.Name]; ok {
+				offset := fset.Position(id.Pos()).Offset
+				offsets = append(offsets, offset)
+				names[offset] = pkg
+			}
+		}
+		return true
+	})
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	for _, offset := range offsets {
+		content = content[:offset] + names[offset] + "." + content[offset:]
+	}
+
+	// Add a group to the file's import block, or a block after the package
+	// clause when it has none
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Rparen.IsValid() {
+			end := fset.Position(gen.Rparen).Offset
+			return content[:end] + "\n" + strings.Join(imports, "") + content[end:]
+		}
+	}
+	end := fset.Position(file.Name.End()).Offset
+	return content[:end] + "\n\nimport (\n" + strings.Join(imports, "") + ")" + content[end:]
+}
+
+// tsImportNames adds the schemas and wire conversions code uses to the
+// types it imports from other modules.
+func tsImportNames(code string, groups []moduleNames) []moduleNames {
+	var out []moduleNames
+	for _, g := range groups {
+		names := append([]string(nil), g.Names...)
+		for _, name := range g.Names {
+			for _, derived := range []string{tsSchemaName(name), tsToWireName(name)} {
+				if regexp.MustCompile(`\b` + derived + `\b`).MatchString(code) {
+					names = append(names, derived)
+				}
+			}
+		}
+		out = append(out, moduleNames{Module: g.Module, Names: names})
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const storeSpec = "# Shop\n\n" +
+	"## Types\n\n" +
+	"### Item (struct)\n\n" +
+	"- name: string - Display name\n\n" +
+	"### Invoice (struct)\n\n" +
+	"**Module**: `billing`\n\n" +
+	"- id: string - Invoice ID\n" +
+	"- items: List<Item> - Items billed\n\n" +
+	"### Refund (struct)\n\n" +
+	"**Module**: `billing/refunds`\n\n" +
+	"- invoice: Invoice - Refunded invoice\n\n" +
+	"## Functions\n\n" +
+	"### findItem\n\n" +
+	"**Parameters**\n- `name`: `string`\n\n" +
+	"**Returns** `Item`\n\n" +
+	"### createInvoice\n\n" +
+	"**Module**: `billing`\n\n" +
+	"**Parameters**\n- `item`: `Item`\n\n" +
+	"**Returns** `Invoice`\n\n" +
+	"## API Endpoints\n\n" +
+	"### POST /invoices\n\n" +
+	"**Function**: `createInvoice`\n\n" +
+	"**Request**: `Item`\n\n" +
+	"**Response 201**: `Invoice`\n\n" +
+	"### GET /items/{name}\n\n" +
+	"**Function**: `findItem`\n\n" +
+	"**Response 200**: `Item`\n"
+
+func TestSpecModules(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(storeSpec, "shop.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	groups := specModules(spec)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 modules, got %+v", groups)
+	}
+	for i, expected := range []string{"", "billing", "billing/refunds"} {
+		if groups[i].Module != expected {
+			t.Errorf("Expected module %d to be %q, got %q", i, expected, groups[i].Module)
+		}
+	}
+	if len(groups[1].Types) != 1 || len(groups[1].Functions) != 1 {
+		t.Errorf("Expected billing to hold Invoice and createInvoice, got %+v", groups[1])
+	}
+
+}
+
+func TestGenerateModules(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(storeSpec, "shop.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		files    map[string][]string
+	}{
+		{"go", map[string][]string{
+			"billing/types.go":         {"package billing", "\"shop\"", "[]shop.Item"},
+			"billing/refunds/types.go": {"package refunds", "\"shop/billing\"", "billing.Invoice"},
+			"billing/service.go":       {"func CreateInvoice(item shop.Item) Invoice"},
+			// Each package serves the endpoints of its own functions
+			"routes.go":         {"package shop", "result := findItem(name)"},
+			"billing/routes.go": {"package billing", "var item shop.Item", "result := CreateInvoice(item)"},
+			// The modules import the root package, so its test stands outside
+			"types_test.go": {"package shop_test", "\t\"shop\"\n", "&shop.Item{}", "&billing.Invoice{}"},
+		}},
+		{"typescript", map[string][]string{
+			"src/billing/types.ts":         {"import { Item, ItemSchema", "from \"../types\""},
+			"src/billing/refunds/types.ts": {"from \"../types\""},
+			// Functions import their own module's types too
+			"src/billing/service.ts": {"import { Invoice } from \"./types\";\nimport { Item } from \"../types\";\n"},
+		}},
+		{"python", map[string][]string{
+			"src/billing/refunds/types.py": {"from src.billing.types import Invoice"},
+			"src/billing/service.py":       {"from src.billing.types import Invoice\nfrom src.types import Item\n"},
+			"src/routes.py":                {"from .billing.service import create_invoice", "from .service import find_item"},
+		}},
+		{"java", map[string][]string{
+			"src/main/java/shop/billing/Types.java":         {"package shop.billing;", "import shop.*;"},
+			"src/main/java/shop/billing/refunds/Types.java": {"package shop.billing.refunds;", "import shop.billing.*;"},
+			"src/main/java/shop/Routes.java":                {"shop.billing.Service.createInvoice(item)", "Service.findItem(name)"},
+		}},
+		{"rust", map[string][]string{
+			"src/lib.rs":                   {"pub mod billing;"},
+			"src/billing/mod.rs":           {"pub mod types;", "pub mod service;", "pub mod refunds;"},
+			"src/billing/refunds/types.rs": {"use crate::billing::types::{Invoice};"},
+			"src/routes.rs":                {"crate::billing::service::create_invoice(item)", "service::find_item(name)"},
+		}},
+		{"csharp", map[string][]string{
+			"src/Billing/Types.cs":         {"namespace Shop.Billing", "using Shop;"},
+			"src/Billing/Refunds/Types.cs": {"namespace Shop.Billing.Refunds", "using Shop.Billing;"},
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), tt.files)
+		})
+	}
+}
+
+func TestModulesBuild(t *testing.T) {
+	checkBuilds(t, storeSpec, "go", "python")
+}
@@ -0,0 +1,1685 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// storedTypes are the pseudo-types repositories read and write as column
+// values.
+var storedTypes = map[string]bool{
+	typeexpr.String: true, typeexpr.Int: true, typeexpr.Int64: true, typeexpr.Float: true,
+	typeexpr.Decimal: true, typeexpr.Bool: true, typeexpr.Bytes: true, typeexpr.Date: true,
+	typeexpr.DateTime: true, typeexpr.UUID: true,
+}
+
+// keyTypes are the pseudo-types a repository looks rows up by.
+var keyTypes = map[string]bool{typeexpr.String: true, typeexpr.Int: true, typeexpr.Int64: true, typeexpr.UUID: true}
+
+// entityTable is a spec table resolved for a repository: its entity type
+// and the field stored in each column.
+type entityTable struct {
+	specparser.SpecTable
+
+	// Type is the table's entity
+	Type specparser.SpecType
+
+	// Fields holds the entity field of each column, in column order
+	Fields []specparser.SpecField
+
+	// Key is the index of the primary key column
+	Key int
+}
+
+// storedType returns the primitive a field stores, with Optional
+// unwrapped, or "" when it is not one repositories store.
+func storedType(f specparser.SpecField) string {
+	e := baseExpr(f.Type)
+	if e == nil || e.Kind != typeexpr.Primitive || !storedTypes[e.Name] {
+		return ""
+	}
+	return e.Name
+}
+
+// resolveEntityTable matches a table's columns to the fields of its entity
+// by their db tag or name, and checks a repository can store it.
+func resolveEntityTable(spec *specparser.SpecAnalysis, table specparser.SpecTable) (*entityTable, error) {
+	if table.Entity == "" {
+		return nil, fmt.Errorf("has no entity")
+	}
+	typ, ok := findType(spec.Types, table.Entity)
+	if !ok || !isStructKind(typ.Kind) {
+		return nil, fmt.Errorf("entity %s is not a struct of the spec", table.Entity)
+	}
+	if typ.Module != "" {
+		return nil, fmt.Errorf("entity %s is in module %s", typ.Name, typ.Module)
+	}
+	if len(table.PrimaryKey) != 1 {
+		if len(table.PrimaryKey) == 0 {
+			return nil, fmt.Errorf("has no primary key")
+		}
+		return nil, fmt.Errorf("has a composite primary key")
+	}
+	if len(table.Columns) < 2 {
+		return nil, fmt.Errorf("has no columns besides its key")
+	}
+
+	r := &entityTable{SpecTable: table, Type: typ, Key: -1}
+	used := make(map[string]bool)
+	for i, col := range table.Columns {
+		var field *specparser.SpecField
+		for j, f := range typ.Fields {
+			if tag, ok := f.Tags["db"]; ok && tag == col.Name || !ok && toSnakeCase(f.Name) == toSnakeCase(col.Name) {
+				field = &typ.Fields[j]
+				break
+			}
+		}
+		if field == nil {
+			return nil, fmt.Errorf("column %s has no field in %s", col.Name, typ.Name)
+		}
+		if storedType(*field) == "" {
+			return nil, fmt.Errorf("field %s of %s has type %s, which is not stored in a column", field.Name, typ.Name, field.Type)
+		}
+		used[field.Name] = true
+		r.Fields = append(r.Fields, *field)
+		if col.Name == table.PrimaryKey[0] {
+			r.Key = i
+		}
+	}
+	for _, f := range typ.Fields {
+		if !used[f.Name] {
+			return nil, fmt.Errorf("field %s of %s has no column", f.Name, typ.Name)
+		}
+	}
+	if r.Key < 0 {
+		return nil, fmt.Errorf("primary key %s is not a column", table.PrimaryKey[0])
+	}
+	key := r.Fields[r.Key]
+	if !key.Required || !keyTypes[storedType(key)] {
+		return nil, fmt.Errorf("primary key %s must be a required string, int or uuid", table.PrimaryKey[0])
+	}
+	if r.Columns[r.Key].AutoIncrement && storedType(key) != typeexpr.Int && storedType(key) != typeexpr.Int64 {
+		return nil, fmt.Errorf("generated primary key %s is not an integer", table.PrimaryKey[0])
+	}
+	return r, nil
+}
+
+// entityTables resolves the spec's tables for repositories, describing
+// those that cannot have one and why.
+func entityTables(spec *specparser.SpecAnalysis) ([]*entityTable, []string) {
+	var tables []*entityTable
+	var skipped []string
+	for _, t := range spec.Tables {
+		r, err := resolveEntityTable(spec, t)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", t.Name, err))
+			continue
+		}
+		tables = append(tables, r)
+	}
+	return tables, skipped
+}
+
+// storedTypesOf returns the set of primitives the tables' columns store.
+func storedTypesOf(tables []*entityTable) map[string]bool {
+	stored := make(map[string]bool)
+	for _, t := range tables {
+		for _, f := range t.Fields {
+			stored[storedType(f)] = true
+		}
+	}
+	return stored
+}
+
+// generatedKey reports whether the database assigns the primary key.
+func (t *entityTable) generatedKey() bool {
+	return t.Columns[t.Key].AutoIncrement
+}
+
+// keyName and keyField return the primary key column's name and field.
+func (t *entityTable) keyName() string                { return t.Columns[t.Key].Name }
+func (t *entityTable) keyField() specparser.SpecField { return t.Fields[t.Key] }
+
+// inserted returns the indexes of the columns an insert writes: every
+// column but a generated key.
+func (t *entityTable) inserted() []int {
+	var cols []int
+	for i := range t.Columns {
+		if i != t.Key || !t.generatedKey() {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// updated returns the indexes of the columns an update writes: every
+// column but the key.
+func (t *entityTable) updated() []int {
+	var cols []int
+	for i := range t.Columns {
+		if i != t.Key {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// probe returns the index of the column the tests change to check an
+// update is stored, or -1: the first required, unconstrained string,
+// integer or boolean column besides the key.
+func (t *entityTable) probe() int {
+	for _, i := range t.updated() {
+		f := t.Fields[i]
+		switch storedType(f) {
+		case typeexpr.String, typeexpr.Int, typeexpr.Int64, typeexpr.Bool:
+			if f.Required && len(f.Constraints) == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// probeCheck renders a check that actual holds the nth sample of the
+// probe field f: the mismatch condition in Go and an assertion elsewhere.
+// Booleans are tested directly rather than compared with a literal.
+func probeCheck(langID string, f specparser.SpecField, actual string, n int) string {
+	lit := strings.TrimSuffix(storedLiteral(langID, f, n), ".to_string()")
+	if storedType(f) != typeexpr.Bool {
+		switch langID {
+		case "go":
+			return fmt.Sprintf("%s != %s", actual, lit)
+		case "java":
+			return fmt.Sprintf("assertEquals(%s, %s)", lit, actual)
+		case "rust":
+			return fmt.Sprintf("assert_eq!(%s, %s)", actual, lit)
+		case "csharp":
+			return fmt.Sprintf("Assert.Equal(%s, %s)", lit, actual)
+		}
+	}
+	holds := n == 1
+	switch {
+	case langID == "go" && holds:
+		return "!" + actual
+	case langID == "go":
+		return actual
+	case langID == "java" && holds:
+		return fmt.Sprintf("assertTrue(%s)", actual)
+	case langID == "java":
+		return fmt.Sprintf("assertFalse(%s)", actual)
+	case langID == "rust" && holds:
+		return fmt.Sprintf("assert!(%s)", actual)
+	case langID == "rust":
+		return fmt.Sprintf("assert!(!%s)", actual)
+	case holds:
+		return fmt.Sprintf("Assert.True(%s)", actual)
+	}
+	return fmt.Sprintf("Assert.False(%s)", actual)
+}
+
+// sampled returns the indexes of the fields a test sets on the entity it
+// inserts: the required ones, but for a generated key.
+func (t *entityTable) sampled() []int {
+	var cols []int
+	for _, i := range t.inserted() {
+		if t.Fields[i].Required {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// storedLiteral renders a sample value of a stored field in a language.
+// The first and second samples (n = 1 and 2) differ; fields with
+// constraints take a value that meets them.
+func storedLiteral(langID string, f specparser.SpecField, n int) string {
+	typ := storedType(f)
+	text := []string{"a", "b"}[n-1]
+	number := strconv.Itoa(n)
+	if sample, constrained, ok := constrainedSample(f); constrained && ok {
+		if s, err := strconv.Unquote(sample); err == nil {
+			text = s
+		} else {
+			number = sample
+		}
+	}
+	if typ == typeexpr.UUID {
+		text = fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+	}
+	day := fmt.Sprintf("2024-01-%02d", n)
+
+	switch typ {
+	case typeexpr.String:
+		if langID == "rust" {
+			return strconv.Quote(text) + ".to_string()"
+		}
+		return strconv.Quote(text)
+	case typeexpr.Int, typeexpr.Int64:
+		return numberLiteral(langID, baseExpr(f.Type), number)
+	case typeexpr.Float, typeexpr.Decimal:
+		if number == strconv.Itoa(n) {
+			number += ".5"
+		}
+		if langID == "python" && typ == typeexpr.Decimal {
+			return fmt.Sprintf("Decimal(%q)", number)
+		}
+		return numberLiteral(langID, baseExpr(f.Type), number)
+	case typeexpr.Bool:
+		switch {
+		case langID == "python" && n == 1:
+			return "True"
+		case langID == "python":
+			return "False"
+		case n == 1:
+			return "true"
+		}
+		return "false"
+	case typeexpr.Bytes:
+		switch langID {
+		case "go":
+			return fmt.Sprintf("[]byte(%q)", text)
+		case "typescript":
+			return fmt.Sprintf("new Uint8Array([%d])", n)
+		case "python":
+			return fmt.Sprintf("b%q", text)
+		case "java":
+			return fmt.Sprintf("new byte[] {%d}", n)
+		case "rust":
+			return fmt.Sprintf("vec![%d]", n)
+		case "csharp":
+			return fmt.Sprintf("new byte[] { %d }", n)
+		}
+	case typeexpr.Date:
+		switch langID {
+		case "go":
+			return fmt.Sprintf("time.Date(2024, 1, %d, 0, 0, 0, 0, time.UTC)", n)
+		case "typescript":
+			return fmt.Sprintf("new Date(%q)", day+"T00:00:00.000Z")
+		case "python":
+			return fmt.Sprintf("datetime(2024, 1, %d)", n)
+		case "java":
+			return fmt.Sprintf("LocalDate.of(2024, 1, %d)", n)
+		case "rust":
+			return fmt.Sprintf("chrono::NaiveDate::from_ymd_opt(2024, 1, %d).unwrap()", n)
+		case "csharp":
+			return fmt.Sprintf("new DateTime(2024, 1, %d)", n)
+		}
+	case typeexpr.DateTime:
+		switch langID {
+		case "go":
+			return fmt.Sprintf("time.Date(2024, 1, %d, 12, 0, 0, 0, time.UTC)", n)
+		case "typescript":
+			return fmt.Sprintf("new Date(%q)", day+"T12:00:00.000Z")
+		case "python":
+			return fmt.Sprintf("datetime(2024, 1, %d, 12, 0)", n)
+		case "java":
+			return fmt.Sprintf("LocalDateTime.of(2024, 1, %d, 12, 0)", n)
+		case "rust":
+			return fmt.Sprintf("%q.parse::<chrono::DateTime<chrono::Utc>>().unwrap()", day+"T12:00:00Z")
+		case "csharp":
+			return fmt.Sprintf("new DateTime(2024, 1, %d, 12, 0, 0)", n)
+		}
+	case typeexpr.UUID:
+		switch langID {
+		case "java":
+			return fmt.Sprintf("UUID.fromString(%q)", text)
+		case "rust":
+			return fmt.Sprintf("uuid::Uuid::from_u128(%d)", n)
+		case "csharp":
+			return fmt.Sprintf("Guid.Parse(%q)", text)
+		}
+		return strconv.Quote(text)
+	}
+	return ""
+}
+
+// ============================================================================
+// Migrations
+// ============================================================================
+
+// sqlDialects are the databases the shared migrations are written for.
+var sqlDialects = []string{"sqlite", "postgres", "mysql"}
+
+// dialectTypes maps the stored pseudo-types to each dialect's column
+// types. Other column types, such as lists, are stored as JSON.
+var dialectTypes = map[string]map[string]string{
+	"sqlite": {
+		typeexpr.String: "TEXT", typeexpr.Int: "INTEGER", typeexpr.Int64: "INTEGER", typeexpr.Float: "REAL",
+		typeexpr.Decimal: "NUMERIC", typeexpr.Bool: "BOOLEAN", typeexpr.Bytes: "BLOB", typeexpr.Date: "DATE",
+		typeexpr.DateTime: "TIMESTAMP", typeexpr.UUID: "TEXT", typeexpr.Duration: "INTEGER", "": "TEXT",
+	},
+	"postgres": {
+		typeexpr.String: "TEXT", typeexpr.Int: "INTEGER", typeexpr.Int64: "BIGINT", typeexpr.Float: "DOUBLE PRECISION",
+		typeexpr.Decimal: "NUMERIC", typeexpr.Bool: "BOOLEAN", typeexpr.Bytes: "BYTEA", typeexpr.Date: "DATE",
+		typeexpr.DateTime: "TIMESTAMP", typeexpr.UUID: "UUID", typeexpr.Duration: "BIGINT", "": "JSONB",
+	},
+	"mysql": {
+		typeexpr.String: "VARCHAR(255)", typeexpr.Int: "INT", typeexpr.Int64: "BIGINT", typeexpr.Float: "DOUBLE",
+		typeexpr.Decimal: "DECIMAL", typeexpr.Bool: "BOOLEAN", typeexpr.Bytes: "BLOB", typeexpr.Date: "DATE",
+		typeexpr.DateTime: "DATETIME", typeexpr.UUID: "CHAR(36)", typeexpr.Duration: "BIGINT", "": "JSON",
+	},
+}
+
+var (
+	// sqlTypeParams matches the length or precision of a column's SQL type
+	sqlTypeParams = regexp.MustCompile(`(?i)^\s*(?:n?varchar|character varying|n?char|character|numeric|decimal)\s*(\(\s*\d+\s*(?:,\s*\d+\s*)?\))`)
+
+	// portableDefault matches defaults every dialect accepts
+	portableDefault = regexp.MustCompile(`(?i)^(?:-?\d+(?:\.\d+)?|'(?:[^']|'')*'|true|false|null|current_timestamp|current_date)$`)
+
+	plainSQLName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+	// nonWord matches the runs an index name drops from its columns
+	nonWord = regexp.MustCompile(`\W+`)
+)
+
+// sqlReserved holds the reserved words likely to name a table or column.
+var sqlReserved = map[string]bool{
+	"all": true, "check": true, "column": true, "default": true, "desc": true, "from": true, "group": true,
+	"index": true, "key": true, "limit": true, "order": true, "primary": true, "references": true,
+	"select": true, "table": true, "to": true, "user": true, "values": true, "where": true,
+}
+
+// quoteSQL quotes a table or column name for a dialect when it is mixed
+// case or a reserved word, with backticks in MySQL and double quotes
+// elsewhere.
+func quoteSQL(dialect, name string) string {
+	if plainSQLName.MatchString(name) && !sqlReserved[name] {
+		return name
+	}
+	if dialect == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// columnSQLType returns a column's type in a dialect, keeping the length
+// or precision of its SQL type.
+func columnSQLType(dialect string, c specparser.SpecColumn) string {
+	typ := c.Type
+	if e, err := typeexpr.Parse(c.Type); err != nil || e.Kind != typeexpr.Primitive || dialectTypes[dialect][e.Name] == "" {
+		typ = ""
+	}
+	params := sqlTypeParams.FindStringSubmatch(c.SQLType)
+	switch {
+	case typ == typeexpr.String && params != nil:
+		return "VARCHAR" + strings.ReplaceAll(params[1], " ", "")
+	case typ == typeexpr.Decimal && params != nil:
+		return dialectTypes[dialect][typ] + strings.ReplaceAll(params[1], " ", "")
+	}
+	return dialectTypes[dialect][typ]
+}
+
+// columnDefinition renders a column of a CREATE TABLE statement.
+func columnDefinition(dialect string, t specparser.SpecTable, c specparser.SpecColumn) string {
+	name, typ := quoteSQL(dialect, c.Name), columnSQLType(dialect, c)
+	key := len(t.PrimaryKey) == 1 && t.PrimaryKey[0] == c.Name
+	if key && c.AutoIncrement && (c.Type == typeexpr.Int || c.Type == typeexpr.Int64) {
+		switch dialect {
+		case "sqlite":
+			return name + " INTEGER PRIMARY KEY AUTOINCREMENT"
+		case "postgres":
+			return name + " " + typ + " GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY"
+		case "mysql":
+			return name + " " + typ + " NOT NULL AUTO_INCREMENT PRIMARY KEY"
+		}
+	}
+	def := name + " " + typ
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if portableDefault.MatchString(c.Default) {
+		if !strings.HasPrefix(c.Default, "'") {
+			def += " DEFAULT " + strings.ToUpper(c.Default)
+		} else {
+			def += " DEFAULT " + c.Default
+		}
+	}
+	if key {
+		def += " PRIMARY KEY"
+	} else if c.Unique {
+		def += " UNIQUE"
+	}
+	return def
+}
+
+// quoteSQLColumns quotes a list of column names, leaving expressions of
+// index columns as they are (in parentheses for MySQL).
+func quoteSQLColumns(dialect string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		switch {
+		case isIdentifier(c):
+			quoted[i] = quoteSQL(dialect, c)
+		case dialect == "mysql":
+			quoted[i] = "(" + c + ")"
+		default:
+			quoted[i] = c
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// createTable renders a table's CREATE TABLE statement and its indexes.
+func createTable(dialect string, t specparser.SpecTable) string {
+	var defs []string
+	for _, c := range t.Columns {
+		defs = append(defs, columnDefinition(dialect, t, c))
+	}
+	if len(t.PrimaryKey) > 1 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", quoteSQLColumns(dialect, t.PrimaryKey)))
+	}
+	for _, fk := range t.ForeignKeys {
+		def := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s", quoteSQLColumns(dialect, fk.Columns), quoteSQL(dialect, fk.RefTable))
+		if len(fk.RefColumns) > 0 {
+			def += fmt.Sprintf(" (%s)", quoteSQLColumns(dialect, fk.RefColumns))
+		}
+		if fk.OnDelete != "" {
+			def += " ON DELETE " + strings.ToUpper(fk.OnDelete)
+		}
+		if fk.OnUpdate != "" {
+			def += " ON UPDATE " + strings.ToUpper(fk.OnUpdate)
+		}
+		if fk.Name != "" {
+			def = "CONSTRAINT " + quoteSQL(dialect, fk.Name) + " " + def
+		}
+		defs = append(defs, def)
+	}
+	for _, check := range t.Checks {
+		defs = append(defs, fmt.Sprintf("CHECK (%s)", check))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CREATE TABLE %s (\n    %s\n);\n", quoteSQL(dialect, t.Name), strings.Join(defs, ",\n    ")))
+	for _, idx := range t.Indexes {
+		name := idx.Name
+		if name == "" {
+			suffix := "_idx"
+			if idx.Unique {
+				suffix = "_key"
+			}
+			name = t.Name + "_" + strings.Trim(nonWord.ReplaceAllString(strings.Join(idx.Columns, "_"), "_"), "_") + suffix
+		}
+		unique := ""
+		if idx.Unique {
+			unique = "UNIQUE "
+		}
+		sb.WriteString(fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);\n", unique, quoteSQL(dialect, name), quoteSQL(dialect, t.Name), quoteSQLColumns(dialect, idx.Columns)))
+	}
+	return sb.String()
+}
+
+// migrationOrder orders tables so each comes after the tables its foreign
+// keys reference, keeping the spec's order otherwise. Tables in a cycle
+// keep their order.
+func migrationOrder(tables []specparser.SpecTable) []specparser.SpecTable {
+	known := make(map[string]bool)
+	for _, t := range tables {
+		known[t.Name] = true
+	}
+	var ordered []specparser.SpecTable
+	created := make(map[string]bool)
+	remaining := tables
+	for len(remaining) > 0 {
+		var next []specparser.SpecTable
+		for _, t := range remaining {
+			ready := true
+			for _, fk := range t.ForeignKeys {
+				if fk.RefTable != t.Name && known[fk.RefTable] && !created[fk.RefTable] {
+					ready = false
+				}
+			}
+			if ready {
+				ordered = append(ordered, t)
+				created[t.Name] = true
+			} else {
+				next = append(next, t)
+			}
+		}
+		if len(next) == len(remaining) {
+			return append(ordered, next...)
+		}
+		remaining = next
+	}
+	return ordered
+}
+
+// migrationFiles renders the up and down migrations of the spec's tables
+// for each dialect. Statements end with ";" at the end of a line, which
+// the generated tests split on.
+func migrationFiles(spec *specparser.SpecAnalysis, notes string) []GeneratedFile {
+	tables := migrationOrder(spec.Tables)
+	var files []GeneratedFile
+	for _, dialect := range sqlDialects {
+		var up, down strings.Builder
+		up.WriteString(fmt.Sprintf("-- Creates the tables of %s.\n%s", spec.Name, notes))
+		for _, t := range tables {
+			up.WriteString("\n")
+			up.WriteString(createTable(dialect, t))
+		}
+		down.WriteString(fmt.Sprintf("-- Drops the tables of %s.\n\n", spec.Name))
+		for i := len(tables) - 1; i >= 0; i-- {
+			down.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", quoteSQL(dialect, tables[i].Name)))
+		}
+		dir := "migrations/" + dialect + "/"
+		files = append(files,
+			GeneratedFile{Path: dir + "0001_create_tables.up.sql", Content: up.String(), Category: "migration"},
+			GeneratedFile{Path: dir + "0001_create_tables.down.sql", Content: down.String(), Category: "migration"})
+	}
+	return files
+}
+
+// sqliteMigration is the migration the generated tests apply, relative to
+// the project root.
+const sqliteMigration = "migrations/sqlite/0001_create_tables.up.sql"
+
+// ============================================================================
+// Generation
+// ============================================================================
+
+// generatePersistence generates the spec's tables as SQL migrations shared
+// by every port, and for each table with an entity a repository that
+// inserts, gets, updates, deletes and lists it, along with integration
+// tests that run the repositories against a SQLite file with the
+// migrations applied.
+func (g *Generator) generatePersistence(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	if len(spec.Tables) == 0 {
+		return nil
+	}
+	lang := adapter.GetLanguage()
+	tables, skipped := entityTables(spec)
+
+	notes := func(prefix string) string {
+		if len(skipped) == 0 {
+			return ""
+		}
+		text := fmt.Sprintf("%s Repositories not generated:\n", prefix)
+		for _, s := range skipped {
+			text += fmt.Sprintf("%s   - %s\n", prefix, s)
+		}
+		return text + "\n"
+	}
+	if len(tables) == 0 {
+		return migrationFiles(spec, "--\n"+notes("--"))
+	}
+	files := migrationFiles(spec, "")
+
+	var names []string
+	for _, t := range tables {
+		names = append(names, t.Type.Name)
+	}
+	source := func(path, content string) GeneratedFile {
+		return GeneratedFile{Path: path, Content: content, Category: "repository", Elements: names}
+	}
+	test := func(path, content string) GeneratedFile {
+		return GeneratedFile{Path: path, Content: content, Category: "test", Elements: names}
+	}
+	region := func(t *entityTable, code string) string {
+		return wrapRegion(lang.ID, "repository", t.Type.Name, hashOf([]any{t.SpecTable, t.Type}), code)
+	}
+	pkg := toPackageName(spec.Name)
+
+	switch lang.ID {
+	case "go":
+		var code, tests strings.Builder
+		for _, t := range tables {
+			code.WriteString("\n")
+			code.WriteString(region(t, goRepository(t)))
+			tests.WriteString(goRepositoryTest(t))
+		}
+		content := fmt.Sprintf("package %s\n\nimport (\n\t\"context\"\n\t\"database/sql\"\n)\n\n%s%s%s", pkg, notes("//"), goRowAffected, code.String())
+		imports := "\t\"context\"\n\t\"database/sql\"\n\t\"errors\"\n\t\"os\"\n\t\"path/filepath\"\n\t\"testing\"\n"
+		if strings.Contains(tests.String(), "time.") {
+			imports += "\t\"time\"\n"
+		}
+		imports += "\n\t_ \"modernc.org/sqlite\"\n"
+		testContent := fmt.Sprintf("package %s\n\nimport (\n%s)\n\n%s%s", pkg, imports, goOpenTestDB, tests.String())
+		files = append(files, source("repositories.go", content), test("repositories_test.go", testContent))
+
+	case "typescript":
+		var code, tests strings.Builder
+		var repos []string
+		for _, t := range tables {
+			if code.Len() > 0 {
+				code.WriteString("\n")
+			}
+			code.WriteString(region(t, typeScriptRepository(t)))
+			tests.WriteString(typeScriptRepositoryTest(t))
+			repos = append(repos, t.Type.Name+"Repository")
+		}
+		sort.Strings(names)
+		sort.Strings(repos)
+		content := fmt.Sprintf("import type { Knex } from \"knex\";\n\nimport type { %s } from %q;\n\n%s%s", strings.Join(names, ", "), tsModulePath("src/repositories.ts", "", "types"), notes("//"), code.String())
+		testContent := "import { afterEach, beforeEach, describe, expect, it } from \"vitest\";\n"
+		testContent += "import { mkdtempSync, readFileSync } from \"node:fs\";\nimport { tmpdir } from \"node:os\";\nimport { join } from \"node:path\";\nimport knex, { type Knex } from \"knex\";\n"
+		testContent += fmt.Sprintf("import { %s } from \"./repositories\";\n", strings.Join(repos, ", "))
+		testContent += typeScriptTestDB + tests.String()
+		files = append(files, source("src/repositories.ts", content), test("src/repositories.test.ts", testContent))
+
+	case "python":
+		var code, tests strings.Builder
+		var repos []string
+		for _, t := range tables {
+			code.WriteString("\n\n")
+			code.WriteString(region(t, pythonRepository(t)))
+			tests.WriteString(pythonRepositoryTest(t))
+			repos = append(repos, t.Type.Name+"Repository")
+		}
+		sort.Strings(names)
+		sort.Strings(repos)
+		content := "\"\"\"Repositories storing the spec's entities with SQLAlchemy.\"\"\"\n\n"
+		content += "from typing import Any, Dict, List, Optional\n\n"
+		content += fmt.Sprintf("from sqlalchemy import %s\n", strings.Join(sqlAlchemyImports(tables), ", "))
+		content += "from sqlalchemy.engine import Engine, RowMapping\n\n"
+		content += fmt.Sprintf("from %s import %s\n\n", pythonRelativePath("", "types"), strings.Join(names, ", "))
+		content += notes("#") + "metadata = MetaData()\n" + code.String()
+		var testContent string
+		for _, imp := range []struct{ use, line string }{
+			{"datetime(", "from datetime import datetime\n"},
+			{"Decimal(", "from decimal import Decimal\n"},
+		} {
+			if strings.Contains(tests.String(), imp.use) {
+				testContent += imp.line
+			}
+		}
+		testContent += "from pathlib import Path\n\nimport pytest\nfrom sqlalchemy import create_engine\n\n"
+		testContent += fmt.Sprintf("from %s import %s\n", pythonModulePath("", "repositories"), strings.Join(repos, ", "))
+		testContent += fmt.Sprintf("from %s import %s\n", pythonModulePath("", "types"), strings.Join(names, ", "))
+		testContent += pythonTestDB + tests.String()
+		files = append(files, source("src/repositories.py", content), test("tests/test_repositories.py", testContent))
+
+	case "java":
+		dir := fmt.Sprintf("src/main/java/%s/", pkg)
+		var tests strings.Builder
+		for _, t := range tables {
+			content := fmt.Sprintf("package %s;\n\nimport org.springframework.data.jpa.repository.JpaRepository;\n\n%s", pkg, notes("//"))
+			content += region(t, javaRepository(t))
+			files = append(files, source(dir+t.Type.Name+"Repository.java", content))
+			tests.WriteString(javaRepositoryTest(t))
+		}
+		files = append(files,
+			source("src/main/resources/META-INF/orm.xml", javaMappings(pkg, tables)),
+			source("src/main/resources/META-INF/persistence.xml", fmt.Sprintf(javaPersistenceUnit, pkg)))
+		imports := []string{
+			"jakarta.persistence.EntityManager", "jakarta.persistence.EntityManagerFactory", "jakarta.persistence.Persistence",
+			"java.nio.file.Files", "java.nio.file.Path", "java.sql.Connection", "java.sql.DriverManager", "java.sql.Statement",
+			"java.util.Map", "java.util.function.Supplier",
+		}
+		for _, imp := range []struct{ path, use string }{
+			{"java.math.BigDecimal", "new BigDecimal("}, {"java.time.LocalDate", "LocalDate.of("},
+			{"java.time.LocalDateTime", "LocalDateTime.of("}, {"java.util.UUID", "UUID.fromString("},
+		} {
+			if strings.Contains(tests.String(), imp.use) {
+				imports = append(imports, imp.path)
+			}
+		}
+		imports = append(imports, "org.junit.jupiter.api.AfterEach", "org.junit.jupiter.api.BeforeEach", "org.junit.jupiter.api.Test",
+			"org.junit.jupiter.api.io.TempDir", "org.springframework.data.jpa.repository.support.JpaRepositoryFactory")
+		sort.Strings(imports)
+		testContent := fmt.Sprintf("package %s;\n\nimport static org.junit.jupiter.api.Assertions.*;\n\n", pkg)
+		for _, imp := range imports {
+			testContent += "import " + imp + ";\n"
+		}
+		testContent += fmt.Sprintf(javaTestDB, pkg) + tests.String() + "}\n"
+		files = append(files, test(fmt.Sprintf("src/test/java/%s/RepositoriesTest.java", pkg), testContent))
+
+	case "rust":
+		var code, tests strings.Builder
+		var repos []string
+		for _, t := range tables {
+			if code.Len() > 0 {
+				code.WriteString("\n")
+			}
+			code.WriteString(region(t, rustRepository(t)))
+			tests.WriteString(rustRepositoryTest(t))
+			repos = append(repos, t.Type.Name+"Repository")
+		}
+		sort.Strings(names)
+		sort.Strings(repos)
+		content := "//! Repositories storing the spec's entities in SQLite with sqlx.\n\n"
+		content += "use sqlx::sqlite::{SqlitePool, SqliteRow};\nuse sqlx::Row;\n\n"
+		content += fmt.Sprintf("use %s::{%s};\n\n", rustModulePath("crate", "", "types"), strings.Join(names, ", "))
+		content += notes("//") + code.String()
+		testContent := "use sqlx::sqlite::{SqliteConnectOptions, SqlitePool};\n\n"
+		testContent += fmt.Sprintf("use %s::{%s};\n", rustModulePath(pkg, "", "repositories"), strings.Join(repos, ", "))
+		testContent += fmt.Sprintf("use %s::{%s};\n", rustModulePath(pkg, "", "types"), strings.Join(names, ", "))
+		testContent += rustTestDB + tests.String()
+		files = append(files, source("src/repositories.rs", content), test("tests/repositories.rs", testContent))
+
+	case "csharp":
+		namespace := toPascalCase(spec.Name)
+		context := namespace + "DbContext"
+		var code, tests strings.Builder
+		for _, t := range tables {
+			code.WriteString("\n")
+			code.WriteString(region(t, csharpRepository(t, context)))
+			tests.WriteString("\n")
+			tests.WriteString(csharpRepositoryTest(t))
+		}
+		content := fmt.Sprintf("using Microsoft.EntityFrameworkCore;\n\n%snamespace %s\n{\n%s%s}\n", notes("//"), namespace, csharpDbContext(context, tables), code.String())
+		testContent := fmt.Sprintf("using Microsoft.Data.Sqlite;\nusing Microsoft.EntityFrameworkCore;\nusing Xunit;\n\nnamespace %s.Tests\n{\n%s%s    }\n}\n", namespace, fmt.Sprintf(csharpTestDB, context), tests.String())
+		files = append(files, source("src/Repositories.cs", content), test("tests/RepositoriesTests.cs", testContent))
+	}
+	return files
+}
+
+// columnList joins the quoted names of the columns at indexes, for SQL
+// written by hand in Go and Rust.
+func (t *entityTable) columnList(indexes []int, format string) string {
+	parts := make([]string, len(indexes))
+	for i, c := range indexes {
+		name := quoteSQL("sqlite", t.Columns[c].Name)
+		parts[i] = strings.ReplaceAll(format, "%s", name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// queries returns the insert, select, update and delete statements of a
+// table with ? placeholders; select takes a WHERE or ORDER BY suffix.
+func (t *entityTable) queries() (insert, selectAll, update, remove string) {
+	table, key := quoteSQL("sqlite", t.Name), quoteSQL("sqlite", t.keyName())
+	all := make([]int, len(t.Columns))
+	for i := range all {
+		all[i] = i
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(t.inserted())), ", ")
+	insert = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, t.columnList(t.inserted(), "%s"), placeholders)
+	selectAll = fmt.Sprintf("SELECT %s FROM %s", t.columnList(all, "%s"), table)
+	update = fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?", table, t.columnList(t.updated(), "%s = ?"), key)
+	remove = fmt.Sprintf("DELETE FROM %s WHERE %s = ?", table, key)
+	return insert, selectAll, update, remove
+}
+
+// ============================================================================
+// Go
+// ============================================================================
+
+const goRowAffected = `// rowAffected returns sql.ErrNoRows when a statement matched no row.
+func rowAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+`
+
+// goRepository renders a table's repository over database/sql. The
+// queries use ? placeholders, as SQLite and MySQL drivers do.
+func goRepository(t *entityTable) string {
+	name := t.Type.Name
+	repo := name + "Repository"
+	field := func(i int) string { return "v." + fieldIdent("go", t.Fields[i].Name) }
+	fields := func(indexes []int, prefix string) string {
+		var parts []string
+		for _, i := range indexes {
+			parts = append(parts, prefix+field(i))
+		}
+		return strings.Join(parts, ", ")
+	}
+	all := make([]int, len(t.Columns))
+	for i := range all {
+		all[i] = i
+	}
+	keyType := mapType(t.keyField().Type, "go")
+	insert, selectAll, update, remove := t.queries()
+	where := fmt.Sprintf(" WHERE %s = ?", quoteSQL("sqlite", t.keyName()))
+	order := fmt.Sprintf(" ORDER BY %s", quoteSQL("sqlite", t.keyName()))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("// %s stores %s entities in the %s table.\n", repo, name, t.Name))
+	sb.WriteString(fmt.Sprintf("type %s struct {\n\tdb *sql.DB\n}\n\n", repo))
+	sb.WriteString(fmt.Sprintf("// New%s returns a repository of the %s table in db.\n", repo, t.Name))
+	sb.WriteString(fmt.Sprintf("func New%s(db *sql.DB) *%s {\n\treturn &%s{db: db}\n}\n\n", repo, repo, repo))
+
+	if t.generatedKey() {
+		sb.WriteString(fmt.Sprintf("// Insert adds v, setting its %s to the key the database generates.\n", fieldIdent("go", t.keyField().Name)))
+		sb.WriteString(fmt.Sprintf("func (r *%s) Insert(ctx context.Context, v *%s) error {\n", repo, name))
+		sb.WriteString(fmt.Sprintf("\tres, err := r.db.ExecContext(ctx, %q, %s)\n\tif err != nil {\n\t\treturn err\n\t}\n", insert, fields(t.inserted(), "")))
+		sb.WriteString("\tid, err := res.LastInsertId()\n\tif err != nil {\n\t\treturn err\n\t}\n")
+		if keyType == "int64" {
+			sb.WriteString(fmt.Sprintf("\t%s = id\n\treturn nil\n}\n\n", field(t.Key)))
+		} else {
+			sb.WriteString(fmt.Sprintf("\t%s = %s(id)\n\treturn nil\n}\n\n", field(t.Key), keyType))
+		}
+	} else {
+		sb.WriteString("// Insert adds v.\n")
+		sb.WriteString(fmt.Sprintf("func (r *%s) Insert(ctx context.Context, v *%s) error {\n", repo, name))
+		sb.WriteString(fmt.Sprintf("\t_, err := r.db.ExecContext(ctx, %q, %s)\n\treturn err\n}\n\n", insert, fields(t.inserted(), "")))
+	}
+
+	sb.WriteString(fmt.Sprintf("// Get returns the %s with the given key, or sql.ErrNoRows.\n", name))
+	sb.WriteString(fmt.Sprintf("func (r *%s) Get(ctx context.Context, key %s) (*%s, error) {\n", repo, keyType, name))
+	sb.WriteString(fmt.Sprintf("\tvar v %s\n", name))
+	sb.WriteString(fmt.Sprintf("\tif err := r.db.QueryRowContext(ctx, %q, key).Scan(%s); err != nil {\n\t\treturn nil, err\n\t}\n", selectAll+where, fields(all, "&")))
+	sb.WriteString("\treturn &v, nil\n}\n\n")
+
+	sb.WriteString("// Update writes v to the row with its key, returning sql.ErrNoRows when\n// there is none.\n")
+	sb.WriteString(fmt.Sprintf("func (r *%s) Update(ctx context.Context, v *%s) error {\n", repo, name))
+	sb.WriteString(fmt.Sprintf("\tres, err := r.db.ExecContext(ctx, %q, %s)\n", update, fields(append(t.updated(), t.Key), "")))
+	sb.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n\treturn rowAffected(res)\n}\n\n")
+
+	sb.WriteString(fmt.Sprintf("// Delete removes the %s with the given key, returning sql.ErrNoRows when\n// there is none.\n", name))
+	sb.WriteString(fmt.Sprintf("func (r *%s) Delete(ctx context.Context, key %s) error {\n", repo, keyType))
+	sb.WriteString(fmt.Sprintf("\tres, err := r.db.ExecContext(ctx, %q, key)\n", remove))
+	sb.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n\treturn rowAffected(res)\n}\n\n")
+
+	sb.WriteString(fmt.Sprintf("// List returns every %s, ordered by key.\n", name))
+	sb.WriteString(fmt.Sprintf("func (r *%s) List(ctx context.Context) ([]%s, error) {\n", repo, name))
+	sb.WriteString(fmt.Sprintf("\trows, err := r.db.QueryContext(ctx, %q)\n", selectAll+order))
+	sb.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n\tdefer rows.Close()\n")
+	sb.WriteString(fmt.Sprintf("\tvar list []%s\n\tfor rows.Next() {\n\t\tvar v %s\n", name, name))
+	sb.WriteString(fmt.Sprintf("\t\tif err := rows.Scan(%s); err != nil {\n\t\t\treturn nil, err\n\t\t}\n", fields(all, "&")))
+	sb.WriteString("\t\tlist = append(list, v)\n\t}\n\treturn list, rows.Err()\n}\n")
+	return sb.String()
+}
+
+const goOpenTestDB = `// openTestDB opens a SQLite database in a temporary file with the
+// migrations applied.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	schema, err := os.ReadFile("` + sqliteMigration + `")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatalf("Applying the migration failed: %v", err)
+	}
+	return db
+}
+`
+
+// goRepositoryTest renders a test that runs a repository's methods
+// against SQLite.
+func goRepositoryTest(t *entityTable) string {
+	name := t.Type.Name
+	key := "v." + fieldIdent("go", t.keyField().Name)
+	var values []string
+	for _, i := range t.sampled() {
+		values = append(values, fmt.Sprintf("%s: %s", fieldIdent("go", t.Fields[i].Name), storedLiteral("go", t.Fields[i], 1)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\nfunc Test%sRepository(t *testing.T) {\n\tctx := context.Background()\n", name))
+	sb.WriteString(fmt.Sprintf("\trepo := New%sRepository(openTestDB(t))\n\n", name))
+	sb.WriteString(fmt.Sprintf("\tv := &%s{%s}\n", name, strings.Join(values, ", ")))
+	sb.WriteString("\tif err := repo.Insert(ctx, v); err != nil {\n\t\tt.Fatalf(\"Insert() error: %v\", err)\n\t}\n")
+	sb.WriteString(fmt.Sprintf("\tgot, err := repo.Get(ctx, %s)\n\tif err != nil {\n\t\tt.Fatalf(\"Get() error: %%v\", err)\n\t}\n", key))
+	probe := t.probe()
+	if probe >= 0 {
+		f := t.Fields[probe]
+		ident := fieldIdent("go", f.Name)
+		first, second := storedLiteral("go", f, 1), storedLiteral("go", f, 2)
+		sb.WriteString(fmt.Sprintf("\tif %s {\n\t\tt.Errorf(\"Expected %s %%v, got %%v\", %s, got.%s)\n\t}\n\n", probeCheck("go", f, "got."+ident, 1), ident, first, ident))
+		sb.WriteString(fmt.Sprintf("\tgot.%s = %s\n", ident, second))
+		sb.WriteString("\tif err := repo.Update(ctx, got); err != nil {\n\t\tt.Fatalf(\"Update() error: %v\", err)\n\t}\n")
+		sb.WriteString(fmt.Sprintf("\tif got, err := repo.Get(ctx, %s); err != nil || %s {\n", key, probeCheck("go", f, "got."+ident, 2)))
+		sb.WriteString("\t\tt.Errorf(\"Expected the update to be stored, got %+v, %v\", got, err)\n\t}\n\n")
+	} else {
+		sb.WriteString("\tif err := repo.Update(ctx, got); err != nil {\n\t\tt.Fatalf(\"Update() error: %v\", err)\n\t}\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("\tif list, err := repo.List(ctx); err != nil || len(list) != 1 {\n\t\tt.Errorf(\"Expected 1 %s, got %%d, %%v\", len(list), err)\n\t}\n\n", name))
+	sb.WriteString(fmt.Sprintf("\tif err := repo.Delete(ctx, %s); err != nil {\n\t\tt.Fatalf(\"Delete() error: %%v\", err)\n\t}\n", key))
+	sb.WriteString(fmt.Sprintf("\tif _, err := repo.Get(ctx, %s); !errors.Is(err, sql.ErrNoRows) {\n", key))
+	sb.WriteString("\t\tt.Errorf(\"Expected sql.ErrNoRows after Delete, got %v\", err)\n\t}\n}\n")
+	return sb.String()
+}
+
+// ============================================================================
+// TypeScript
+// ============================================================================
+
+// tsToColumn and tsFromColumn convert a field to the value a knex driver
+// binds and back: dates as ISO strings, booleans as 0 or 1 and bytes as
+// Buffers, which SQLite drivers need.
+func tsToColumn(f specparser.SpecField, acc string) string {
+	var conv string
+	switch storedType(f) {
+	case typeexpr.Date:
+		conv = "%s.toISOString().slice(0, 10)"
+	case typeexpr.DateTime:
+		conv = "%s.toISOString()"
+	case typeexpr.Bool:
+		conv = "%s ? 1 : 0"
+	case typeexpr.Bytes:
+		conv = "Buffer.from(%s)"
+	default:
+		if f.Required {
+			return acc
+		}
+		return acc + " ?? null"
+	}
+	if f.Required {
+		return fmt.Sprintf(conv, acc)
+	}
+	return fmt.Sprintf("%s === undefined ? null : %s", acc, fmt.Sprintf(conv, acc))
+}
+
+func tsFromColumn(f specparser.SpecField, acc string) string {
+	var conv string
+	switch storedType(f) {
+	case typeexpr.Date, typeexpr.DateTime:
+		conv = "new Date(%s)"
+	case typeexpr.Bool:
+		conv = "Boolean(%s)"
+	case typeexpr.Bytes:
+		conv = "new Uint8Array(%s)"
+	case typeexpr.Int64, typeexpr.Decimal:
+		// Postgres drivers return these as strings
+		conv = "Number(%s)"
+	default:
+		if f.Required {
+			return acc
+		}
+		return acc + " ?? undefined"
+	}
+	if f.Required {
+		return fmt.Sprintf(conv, acc)
+	}
+	return fmt.Sprintf("%s == null ? undefined : %s", acc, fmt.Sprintf(conv, acc))
+}
+
+// typeScriptRepository renders a table's repository over a knex query
+// builder, with the functions converting its entity to and from rows.
+func typeScriptRepository(t *entityTable) string {
+	name := t.Type.Name
+	table, key := strconv.Quote(t.Name), strconv.Quote(t.keyName())
+	keyIdent := fieldIdent("typescript", t.keyField().Name)
+	keyType := mapType(t.keyField().Type, "typescript")
+	toRow, fromRow := toCamelCase(name)+"ToRow", toCamelCase(name)+"FromRow"
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("/** Stores %s entities in the %s table. */\n", name, t.Name))
+	sb.WriteString(fmt.Sprintf("export class %sRepository {\n  constructor(private readonly db: Knex) {}\n\n", name))
+	if t.generatedKey() {
+		sb.WriteString(fmt.Sprintf("  /** Adds a %s, returning it with the key the database generated. */\n", name))
+		sb.WriteString(fmt.Sprintf("  async insert(value: %s): Promise<%s> {\n", name, name))
+		sb.WriteString(fmt.Sprintf("    const [row] = await this.db(%s).insert(%s(value)).returning(%s);\n", table, toRow, key))
+		sb.WriteString(fmt.Sprintf("    return { ...value, %s: row%s };\n  }\n\n", tsKey(keyIdent), propertyAccess(t.keyName())))
+	} else {
+		sb.WriteString(fmt.Sprintf("  /** Adds a %s. */\n", name))
+		sb.WriteString(fmt.Sprintf("  async insert(value: %s): Promise<%s> {\n", name, name))
+		sb.WriteString(fmt.Sprintf("    await this.db(%s).insert({ %s: value%s, ...%s(value) });\n", table, tsKey(t.keyName()), propertyAccess(keyIdent), toRow))
+		sb.WriteString("    return value;\n  }\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("  /** Returns the %s with the given key, or undefined. */\n", name))
+	sb.WriteString(fmt.Sprintf("  async get(key: %s): Promise<%s | undefined> {\n", keyType, name))
+	sb.WriteString(fmt.Sprintf("    const row = await this.db(%s).where(%s, key).first();\n", table, key))
+	sb.WriteString(fmt.Sprintf("    return row === undefined ? undefined : %s(row);\n  }\n\n", fromRow))
+	sb.WriteString("  /** Writes a value to the row with its key, returning whether there was one. */\n")
+	sb.WriteString(fmt.Sprintf("  async update(value: %s): Promise<boolean> {\n", name))
+	sb.WriteString(fmt.Sprintf("    return (await this.db(%s).where(%s, value%s).update(%s(value))) > 0;\n  }\n\n", table, key, propertyAccess(keyIdent), toRow))
+	sb.WriteString(fmt.Sprintf("  /** Removes the %s with the given key, returning whether there was one. */\n", name))
+	sb.WriteString(fmt.Sprintf("  async delete(key: %s): Promise<boolean> {\n", keyType))
+	sb.WriteString(fmt.Sprintf("    return (await this.db(%s).where(%s, key).delete()) > 0;\n  }\n\n", table, key))
+	sb.WriteString(fmt.Sprintf("  /** Returns every %s, ordered by key. */\n", name))
+	sb.WriteString(fmt.Sprintf("  async list(): Promise<%s[]> {\n", name))
+	sb.WriteString(fmt.Sprintf("    const rows = await this.db(%s).orderBy(%s);\n    return rows.map((row) => %s(row));\n  }\n}\n\n", table, key, fromRow))
+
+	sb.WriteString(fmt.Sprintf("/** Converts a %s to the values of its columns besides the key. */\n", name))
+	sb.WriteString(fmt.Sprintf("function %s(value: %s): Record<string, unknown> {\n  return {\n", toRow, name))
+	for _, i := range t.updated() {
+		sb.WriteString(fmt.Sprintf("    %s: %s,\n", tsKey(t.Columns[i].Name), tsToColumn(t.Fields[i], "value"+propertyAccess(fieldIdent("typescript", t.Fields[i].Name)))))
+	}
+	sb.WriteString("  };\n}\n\n")
+	sb.WriteString(fmt.Sprintf("/** Converts a row of the %s table to a %s. */\n", t.Name, name))
+	sb.WriteString(fmt.Sprintf("function %s(row: Record<string, any>): %s {\n  return {\n", fromRow, name))
+	for i, f := range t.Fields {
+		sb.WriteString(fmt.Sprintf("    %s: %s,\n", tsKey(fieldIdent("typescript", f.Name)), tsFromColumn(f, "row"+propertyAccess(t.Columns[i].Name))))
+	}
+	sb.WriteString("  };\n}\n")
+	return sb.String()
+}
+
+const typeScriptTestDB = `
+let db: Knex;
+
+beforeEach(async () => {
+  const dir = mkdtempSync(join(tmpdir(), "repositories-"));
+  db = knex({ client: "better-sqlite3", connection: { filename: join(dir, "test.db") }, useNullAsDefault: true });
+  const migration = readFileSync(new URL("../` + sqliteMigration + `", import.meta.url), "utf8");
+  for (const statement of migration.split(";\n")) {
+    if (statement.trim()) {
+      await db.raw(statement);
+    }
+  }
+});
+
+afterEach(async () => {
+  await db.destroy();
+});
+`
+
+// typeScriptRepositoryTest renders a test that runs a repository's
+// methods against SQLite.
+func typeScriptRepositoryTest(t *entityTable) string {
+	name := t.Type.Name
+	key := "saved" + propertyAccess(fieldIdent("typescript", t.keyField().Name))
+	var values []string
+	for i, f := range t.Fields {
+		switch {
+		case i == t.Key && t.generatedKey():
+			values = append(values, fmt.Sprintf("%s: 0", tsKey(fieldIdent("typescript", f.Name))))
+		case f.Required:
+			values = append(values, fmt.Sprintf("%s: %s", tsKey(fieldIdent("typescript", f.Name)), storedLiteral("typescript", f, 1)))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\ndescribe(\"%sRepository\", () => {\n", name))
+	sb.WriteString(fmt.Sprintf("  it(\"stores %s entities\", async () => {\n", name))
+	sb.WriteString(fmt.Sprintf("    const repository = new %sRepository(db);\n", name))
+	sb.WriteString(fmt.Sprintf("    const saved = await repository.insert({ %s });\n", strings.Join(values, ", ")))
+	sb.WriteString(fmt.Sprintf("    const found = await repository.get(%s);\n    expect(found).toBeDefined();\n", key))
+	probe := t.probe()
+	if probe >= 0 {
+		ident := propertyAccess(fieldIdent("typescript", t.Fields[probe].Name))
+		sb.WriteString(fmt.Sprintf("    expect(found!%s).toBe(%s);\n", ident, storedLiteral("typescript", t.Fields[probe], 1)))
+		sb.WriteString(fmt.Sprintf("    expect(await repository.update({ ...found!, %s: %s })).toBe(true);\n", tsKey(fieldIdent("typescript", t.Fields[probe].Name)), storedLiteral("typescript", t.Fields[probe], 2)))
+		sb.WriteString(fmt.Sprintf("    expect((await repository.get(%s))?%s).toBe(%s);\n", key, ident, storedLiteral("typescript", t.Fields[probe], 2)))
+	} else {
+		sb.WriteString("    expect(await repository.update(found!)).toBe(true);\n")
+	}
+	sb.WriteString("    expect(await repository.list()).toHaveLength(1);\n")
+	sb.WriteString(fmt.Sprintf("    expect(await repository.delete(%s)).toBe(true);\n", key))
+	sb.WriteString(fmt.Sprintf("    expect(await repository.get(%s)).toBeUndefined();\n  });\n});\n", key))
+	return sb.String()
+}
+
+// ============================================================================
+// Python
+// ============================================================================
+
+// sqlAlchemyTypes maps the stored pseudo-types to SQLAlchemy column types.
+// Dates are DateTime since entities hold them as datetimes.
+var sqlAlchemyTypes = map[string]string{
+	typeexpr.String: "String", typeexpr.Int: "Integer", typeexpr.Int64: "BigInteger", typeexpr.Float: "Float",
+	typeexpr.Decimal: "Numeric", typeexpr.Bool: "Boolean", typeexpr.Bytes: "LargeBinary", typeexpr.Date: "DateTime",
+	typeexpr.DateTime: "DateTime", typeexpr.UUID: "String",
+}
+
+// sqlAlchemyImports lists the names the SQLAlchemy tables and queries of
+// the repositories use, sorted.
+func sqlAlchemyImports(tables []*entityTable) []string {
+	names := []string{"Column", "MetaData", "Table", "delete", "insert", "select", "update"}
+	for _, t := range tables {
+		for _, f := range t.Fields {
+			if typ := sqlAlchemyTypes[storedType(f)]; !containsString(names, typ) {
+				names = append(names, typ)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pythonRepository renders a table's SQLAlchemy table and its repository,
+// with the functions converting its entity to and from rows.
+func pythonRepository(t *entityTable) string {
+	name := t.Type.Name
+	snake := toSnakeCase(name)
+	table := snake + "_table"
+	keyIdent := fieldIdent("python", t.keyField().Name)
+	keyType := mapType(t.keyField().Type, "python")
+	keyColumn := fmt.Sprintf("%s.c[%q]", table, t.keyName())
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s = Table(\n    %q,\n    metadata,\n", table, t.Name))
+	for i, c := range t.Columns {
+		typ := sqlAlchemyTypes[storedType(t.Fields[i])]
+		switch params := sqlTypeParams.FindStringSubmatch(c.SQLType); {
+		case storedType(t.Fields[i]) == typeexpr.UUID:
+			typ += "(36)"
+		case typ == "String" && params != nil:
+			typ += params[1]
+		}
+		args := []string{strconv.Quote(c.Name), typ}
+		if i == t.Key {
+			args = append(args, "primary_key=True")
+		} else if !c.Nullable {
+			args = append(args, "nullable=False")
+		}
+		if c.Unique && i != t.Key {
+			args = append(args, "unique=True")
+		}
+		sb.WriteString(fmt.Sprintf("    Column(%s),\n", strings.Join(args, ", ")))
+	}
+	sb.WriteString(")\n\n\n")
+
+	sb.WriteString(fmt.Sprintf("class %sRepository:\n    \"\"\"Stores %s entities in the %s table.\"\"\"\n\n", name, name, t.Name))
+	sb.WriteString("    def __init__(self, engine: Engine) -> None:\n        self.engine = engine\n\n")
+	sb.WriteString(fmt.Sprintf("    def insert(self, value: %s) -> %s:\n", name, name))
+	if t.generatedKey() {
+		sb.WriteString(fmt.Sprintf("        \"\"\"Adds a %s, returning it with the key the database generated.\"\"\"\n", name))
+		sb.WriteString("        with self.engine.begin() as conn:\n")
+		sb.WriteString(fmt.Sprintf("            result = conn.execute(insert(%s).values(_%s_row(value)))\n", table, snake))
+		sb.WriteString(fmt.Sprintf("        return value.model_copy(update={%q: result.inserted_primary_key[0]})\n\n", keyIdent))
+	} else {
+		sb.WriteString(fmt.Sprintf("        \"\"\"Adds a %s.\"\"\"\n", name))
+		sb.WriteString("        with self.engine.begin() as conn:\n")
+		sb.WriteString(fmt.Sprintf("            conn.execute(insert(%s).values({%q: value.%s, **_%s_row(value)}))\n", table, t.keyName(), keyIdent, snake))
+		sb.WriteString("        return value\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("    def get(self, key: %s) -> Optional[%s]:\n", keyType, name))
+	sb.WriteString(fmt.Sprintf("        \"\"\"Returns the %s with the given key, or None.\"\"\"\n", name))
+	sb.WriteString("        with self.engine.connect() as conn:\n")
+	sb.WriteString(fmt.Sprintf("            row = conn.execute(select(%s).where(%s == key)).mappings().first()\n", table, keyColumn))
+	sb.WriteString(fmt.Sprintf("        return None if row is None else _%s_from_row(row)\n\n", snake))
+	sb.WriteString(fmt.Sprintf("    def update(self, value: %s) -> bool:\n", name))
+	sb.WriteString("        \"\"\"Writes a value to the row with its key, returning whether there was one.\"\"\"\n")
+	sb.WriteString("        with self.engine.begin() as conn:\n")
+	sb.WriteString(fmt.Sprintf("            result = conn.execute(update(%s).where(%s == value.%s).values(_%s_row(value)))\n", table, keyColumn, keyIdent, snake))
+	sb.WriteString("        return result.rowcount > 0\n\n")
+	sb.WriteString(fmt.Sprintf("    def delete(self, key: %s) -> bool:\n", keyType))
+	sb.WriteString(fmt.Sprintf("        \"\"\"Removes the %s with the given key, returning whether there was one.\"\"\"\n", name))
+	sb.WriteString("        with self.engine.begin() as conn:\n")
+	sb.WriteString(fmt.Sprintf("            result = conn.execute(delete(%s).where(%s == key))\n", table, keyColumn))
+	sb.WriteString("        return result.rowcount > 0\n\n")
+	sb.WriteString(fmt.Sprintf("    def list(self) -> List[%s]:\n", name))
+	sb.WriteString(fmt.Sprintf("        \"\"\"Returns every %s, ordered by key.\"\"\"\n", name))
+	sb.WriteString("        with self.engine.connect() as conn:\n")
+	sb.WriteString(fmt.Sprintf("            rows = conn.execute(select(%s).order_by(%s)).mappings().all()\n", table, keyColumn))
+	sb.WriteString(fmt.Sprintf("        return [_%s_from_row(row) for row in rows]\n\n\n", snake))
+
+	sb.WriteString(fmt.Sprintf("def _%s_row(value: %s) -> Dict[str, Any]:\n", snake, name))
+	sb.WriteString(fmt.Sprintf("    \"\"\"Returns the values of a %s's columns besides the key.\"\"\"\n    return {\n", name))
+	for _, i := range t.updated() {
+		sb.WriteString(fmt.Sprintf("        %q: value.%s,\n", t.Columns[i].Name, fieldIdent("python", t.Fields[i].Name)))
+	}
+	sb.WriteString("    }\n\n\n")
+	sb.WriteString(fmt.Sprintf("def _%s_from_row(row: RowMapping) -> %s:\n", snake, name))
+	sb.WriteString(fmt.Sprintf("    \"\"\"Converts a row of the %s table to a %s.\"\"\"\n    return %s(\n", t.Name, name, name))
+	for i, f := range t.Fields {
+		sb.WriteString(fmt.Sprintf("        %s=row[%q],\n", fieldIdent("python", f.Name), t.Columns[i].Name))
+	}
+	sb.WriteString("    )\n")
+	return sb.String()
+}
+
+const pythonTestDB = `
+MIGRATION = Path(__file__).resolve().parent.parent / "` + sqliteMigration + `"
+
+
+@pytest.fixture
+def engine(tmp_path):
+    """A SQLite database in a temporary file with the migrations applied."""
+    engine = create_engine(f"sqlite:///{tmp_path / 'test.db'}")
+    with engine.begin() as conn:
+        for statement in MIGRATION.read_text().split(";\n"):
+            if statement.strip():
+                conn.exec_driver_sql(statement)
+    yield engine
+    engine.dispose()
+`
+
+// pythonRepositoryTest renders a test that runs a repository's methods
+// against SQLite.
+func pythonRepositoryTest(t *entityTable) string {
+	name := t.Type.Name
+	key := "saved." + fieldIdent("python", t.keyField().Name)
+	var values []string
+	for i, f := range t.Fields {
+		switch {
+		case i == t.Key && t.generatedKey():
+			values = append(values, fmt.Sprintf("%s=0", fieldIdent("python", f.Name)))
+		case f.Required:
+			values = append(values, fmt.Sprintf("%s=%s", fieldIdent("python", f.Name), storedLiteral("python", f, 1)))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n\ndef test_%s_repository(engine):\n", toSnakeCase(name)))
+	sb.WriteString(fmt.Sprintf("    repository = %sRepository(engine)\n", name))
+	sb.WriteString(fmt.Sprintf("    saved = repository.insert(%s(%s))\n", name, strings.Join(values, ", ")))
+	sb.WriteString(fmt.Sprintf("    found = repository.get(%s)\n    assert found is not None\n", key))
+	probe := t.probe()
+	if probe >= 0 {
+		ident := fieldIdent("python", t.Fields[probe].Name)
+		equals := "=="
+		if storedType(t.Fields[probe]) == typeexpr.Bool {
+			equals = "is"
+		}
+		sb.WriteString(fmt.Sprintf("    assert found.%s %s %s\n", ident, equals, storedLiteral("python", t.Fields[probe], 1)))
+		sb.WriteString(fmt.Sprintf("    assert repository.update(found.model_copy(update={%q: %s}))\n", ident, storedLiteral("python", t.Fields[probe], 2)))
+		sb.WriteString(fmt.Sprintf("    assert repository.get(%s).%s %s %s\n", key, ident, equals, storedLiteral("python", t.Fields[probe], 2)))
+	} else {
+		sb.WriteString("    assert repository.update(found)\n")
+	}
+	sb.WriteString("    assert len(repository.list()) == 1\n")
+	sb.WriteString(fmt.Sprintf("    assert repository.delete(%s)\n", key))
+	sb.WriteString(fmt.Sprintf("    assert repository.get(%s) is None\n", key))
+	return sb.String()
+}
+
+// ============================================================================
+// Java
+// ============================================================================
+
+// javaRepository renders a table's Spring Data repository. Its entity is
+// mapped to the table in META-INF/orm.xml, so the spec's types need no JPA
+// annotations.
+func javaRepository(t *entityTable) string {
+	keyType := mapType(t.keyField().Type, "java")
+	if boxed, ok := javaBoxed[keyType]; ok {
+		keyType = boxed
+	}
+	return fmt.Sprintf("/** Stores %s entities in the %s table; META-INF/orm.xml maps them. */\npublic interface %sRepository extends JpaRepository<%s, %s> {\n}\n", t.Type.Name, t.Name, t.Type.Name, t.Type.Name, keyType)
+}
+
+// javaMappings renders orm.xml, mapping each entity's fields to the
+// columns of its table.
+func javaMappings(pkg string, tables []*entityTable) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<entity-mappings xmlns="https://jakarta.ee/xml/ns/persistence/orm"
+                 xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+                 xsi:schemaLocation="https://jakarta.ee/xml/ns/persistence/orm https://jakarta.ee/xml/ns/persistence/orm/orm_3_1.xsd"
+                 version="3.1">
+    <persistence-unit-metadata>
+        <persistence-unit-defaults>
+            <delimited-identifiers/>
+            <access>FIELD</access>
+        </persistence-unit-defaults>
+    </persistence-unit-metadata>
+`)
+	sb.WriteString(fmt.Sprintf("    <package>%s</package>\n", pkg))
+	for _, t := range tables {
+		sb.WriteString(fmt.Sprintf("    <entity class=%q>\n        <table name=%q/>\n        <attributes>\n", t.Type.Name, t.Name))
+		column := func(i int) string {
+			c := t.Columns[i]
+			attrs := fmt.Sprintf("name=%q", c.Name)
+			if !c.Nullable && i != t.Key {
+				attrs += ` nullable="false"`
+			}
+			if c.Unique && i != t.Key {
+				attrs += ` unique="true"`
+			}
+			if params := sqlTypeParams.FindStringSubmatch(c.SQLType); params != nil && storedType(t.Fields[i]) == typeexpr.String {
+				attrs += fmt.Sprintf(" length=%q", strings.Trim(params[1], "() "))
+			}
+			return fmt.Sprintf("<column %s/>", attrs)
+		}
+		sb.WriteString(fmt.Sprintf("            <id name=%q>\n                %s\n", fieldIdent("java", t.keyField().Name), column(t.Key)))
+		if t.generatedKey() {
+			sb.WriteString("                <generated-value strategy=\"IDENTITY\"/>\n")
+		}
+		sb.WriteString("            </id>\n")
+		for _, i := range t.updated() {
+			sb.WriteString(fmt.Sprintf("            <basic name=%q>\n                %s\n            </basic>\n", fieldIdent("java", t.Fields[i].Name), column(i)))
+		}
+		sb.WriteString("        </attributes>\n    </entity>\n")
+	}
+	sb.WriteString("</entity-mappings>\n")
+	return sb.String()
+}
+
+// javaPersistenceUnit is persistence.xml, naming the persistence unit the
+// tests open; the mappings come from META-INF/orm.xml.
+const javaPersistenceUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<persistence xmlns="https://jakarta.ee/xml/ns/persistence"
+             xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+             xsi:schemaLocation="https://jakarta.ee/xml/ns/persistence https://jakarta.ee/xml/ns/persistence/persistence_3_0.xsd"
+             version="3.0">
+    <persistence-unit name="%s" transaction-type="RESOURCE_LOCAL">
+        <exclude-unlisted-classes>true</exclude-unlisted-classes>
+    </persistence-unit>
+</persistence>
+`
+
+const javaTestDB = `
+class RepositoriesTest {
+    private EntityManagerFactory factory;
+    private EntityManager entityManager;
+    private JpaRepositoryFactory repositories;
+
+    @BeforeEach
+    void setUp(@TempDir Path dir) throws Exception {
+        String url = "jdbc:sqlite:" + dir.resolve("test.db");
+        try (Connection connection = DriverManager.getConnection(url); Statement statement = connection.createStatement()) {
+            for (String sql : Files.readString(Path.of("` + sqliteMigration + `")).split(";\n")) {
+                if (!sql.isBlank()) {
+                    statement.execute(sql);
+                }
+            }
+        }
+        factory = Persistence.createEntityManagerFactory("%s", Map.of(
+            "jakarta.persistence.jdbc.url", url,
+            "hibernate.dialect", "org.hibernate.community.dialect.SQLiteDialect"));
+        entityManager = factory.createEntityManager();
+        repositories = new JpaRepositoryFactory(entityManager);
+    }
+
+    @AfterEach
+    void tearDown() {
+        entityManager.close();
+        factory.close();
+    }
+
+    /** Runs work in a transaction, then clears the persistence context so later reads come from the database. */
+    private <T> T inTransaction(Supplier<T> work) {
+        entityManager.getTransaction().begin();
+        T result = work.get();
+        entityManager.getTransaction().commit();
+        entityManager.clear();
+        return result;
+    }
+`
+
+// javaRepositoryTest renders a test that runs a repository's methods
+// against SQLite.
+func javaRepositoryTest(t *entityTable) string {
+	name := t.Type.Name
+	getter := func(i int) string { return javaAccessor("get", t.Fields[i].Name) + "()" }
+	setter := func(i int, v string) string {
+		return fmt.Sprintf("%s(%s)", javaAccessor("set", t.Fields[i].Name), v)
+	}
+	key := "saved." + getter(t.Key)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n    @Test\n    void %sRepository() {\n", toCamelCase(name)))
+	sb.WriteString(fmt.Sprintf("        %sRepository repository = repositories.getRepository(%sRepository.class);\n", name, name))
+	sb.WriteString(fmt.Sprintf("        %s value = new %s();\n", name, name))
+	for _, i := range t.sampled() {
+		sb.WriteString(fmt.Sprintf("        value.%s;\n", setter(i, storedLiteral("java", t.Fields[i], 1))))
+	}
+	sb.WriteString(fmt.Sprintf("        %s saved = inTransaction(() -> repository.save(value));\n", name))
+	sb.WriteString(fmt.Sprintf("        %s found = repository.findById(%s).orElseThrow();\n", name, key))
+	probe := t.probe()
+	if probe >= 0 {
+		sb.WriteString(fmt.Sprintf("        %s;\n", probeCheck("java", t.Fields[probe], "found."+getter(probe), 1)))
+		sb.WriteString(fmt.Sprintf("        found.%s;\n", setter(probe, storedLiteral("java", t.Fields[probe], 2))))
+		sb.WriteString("        inTransaction(() -> repository.save(found));\n")
+		sb.WriteString(fmt.Sprintf("        %s;\n", probeCheck("java", t.Fields[probe], fmt.Sprintf("repository.findById(%s).orElseThrow().%s", key, getter(probe)), 2)))
+	} else {
+		sb.WriteString("        inTransaction(() -> repository.save(found));\n")
+	}
+	sb.WriteString("        assertEquals(1, repository.findAll().size());\n")
+	sb.WriteString(fmt.Sprintf("        inTransaction(() -> {\n            repository.deleteById(%s);\n            return null;\n        });\n", key))
+	sb.WriteString(fmt.Sprintf("        assertTrue(repository.findById(%s).isEmpty());\n    }\n", key))
+	return sb.String()
+}
+
+// ============================================================================
+// Rust
+// ============================================================================
+
+// rustRepository renders a table's repository over a sqlx SQLite pool,
+// with the function converting rows to its entity.
+func rustRepository(t *entityTable) string {
+	name := t.Type.Name
+	snake := toSnakeCase(name)
+	keyIdent := fieldIdent("rust", t.keyField().Name)
+	keyType := mapType(t.keyField().Type, "rust")
+	if keyType == "String" {
+		keyType = "&str"
+	}
+	binds := func(indexes []int) string {
+		var sb strings.Builder
+		for _, i := range indexes {
+			sb.WriteString(fmt.Sprintf("            .bind(&value.%s)\n", fieldIdent("rust", t.Fields[i].Name)))
+		}
+		return sb.String()
+	}
+	insert, selectAll, update, remove := t.queries()
+	where := fmt.Sprintf(" WHERE %s = ?", quoteSQL("sqlite", t.keyName()))
+	order := fmt.Sprintf(" ORDER BY %s", quoteSQL("sqlite", t.keyName()))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("/// Stores %s entities in the %s table.\npub struct %sRepository {\n    pool: SqlitePool,\n}\n\n", name, t.Name, name))
+	sb.WriteString(fmt.Sprintf("impl %sRepository {\n", name))
+	sb.WriteString(fmt.Sprintf("    /// Creates a repository of the %s table in a pool.\n    pub fn new(pool: SqlitePool) -> Self {\n        Self { pool }\n    }\n\n", t.Name))
+	if t.generatedKey() {
+		sb.WriteString(fmt.Sprintf("    /// Adds a %s, returning it with the key the database generated.\n", name))
+	} else {
+		sb.WriteString(fmt.Sprintf("    /// Adds a %s, returning a copy of it.\n", name))
+	}
+	sb.WriteString(fmt.Sprintf("    pub async fn insert(&self, value: &%s) -> Result<%s, sqlx::Error> {\n", name, name))
+	if t.generatedKey() {
+		sb.WriteString(fmt.Sprintf("        let result = sqlx::query(%q)\n%s            .execute(&self.pool)\n            .await?;\n", insert, binds(t.inserted())))
+		sb.WriteString("        let mut value = value.clone();\n")
+		if keyType == "i64" {
+			sb.WriteString(fmt.Sprintf("        value.%s = result.last_insert_rowid();\n", keyIdent))
+		} else {
+			sb.WriteString(fmt.Sprintf("        value.%s = result.last_insert_rowid() as %s;\n", keyIdent, keyType))
+		}
+		sb.WriteString("        Ok(value)\n    }\n\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("        sqlx::query(%q)\n%s            .execute(&self.pool)\n            .await?;\n", insert, binds(t.inserted())))
+		sb.WriteString("        Ok(value.clone())\n    }\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("    /// Returns the %s with the given key, if there is one.\n", name))
+	sb.WriteString(fmt.Sprintf("    pub async fn get(&self, key: %s) -> Result<Option<%s>, sqlx::Error> {\n", keyType, name))
+	sb.WriteString(fmt.Sprintf("        let row = sqlx::query(%q)\n            .bind(key)\n            .fetch_optional(&self.pool)\n            .await?;\n", selectAll+where))
+	sb.WriteString(fmt.Sprintf("        row.as_ref().map(%s_from_row).transpose()\n    }\n\n", snake))
+	sb.WriteString("    /// Writes a value to the row with its key, returning whether there was one.\n")
+	sb.WriteString(fmt.Sprintf("    pub async fn update(&self, value: &%s) -> Result<bool, sqlx::Error> {\n", name))
+	sb.WriteString(fmt.Sprintf("        let result = sqlx::query(%q)\n%s            .execute(&self.pool)\n            .await?;\n", update, binds(append(t.updated(), t.Key))))
+	sb.WriteString("        Ok(result.rows_affected() > 0)\n    }\n\n")
+	sb.WriteString(fmt.Sprintf("    /// Removes the %s with the given key, returning whether there was one.\n", name))
+	sb.WriteString(fmt.Sprintf("    pub async fn delete(&self, key: %s) -> Result<bool, sqlx::Error> {\n", keyType))
+	sb.WriteString(fmt.Sprintf("        let result = sqlx::query(%q)\n            .bind(key)\n            .execute(&self.pool)\n            .await?;\n", remove))
+	sb.WriteString("        Ok(result.rows_affected() > 0)\n    }\n\n")
+	sb.WriteString(fmt.Sprintf("    /// Returns every %s, ordered by key.\n", name))
+	sb.WriteString(fmt.Sprintf("    pub async fn list(&self) -> Result<Vec<%s>, sqlx::Error> {\n", name))
+	sb.WriteString(fmt.Sprintf("        let rows = sqlx::query(%q)\n            .fetch_all(&self.pool)\n            .await?;\n", selectAll+order))
+	sb.WriteString(fmt.Sprintf("        rows.iter().map(%s_from_row).collect()\n    }\n}\n\n", snake))
+
+	sb.WriteString(fmt.Sprintf("/// Converts a row of the %s table to a %s.\n", t.Name, name))
+	sb.WriteString(fmt.Sprintf("fn %s_from_row(row: &SqliteRow) -> Result<%s, sqlx::Error> {\n    Ok(%s {\n", snake, name, name))
+	for i, f := range t.Fields {
+		sb.WriteString(fmt.Sprintf("        %s: row.try_get(%q)?,\n", fieldIdent("rust", f.Name), t.Columns[i].Name))
+	}
+	sb.WriteString("    })\n}\n")
+	return sb.String()
+}
+
+const rustTestDB = `
+/// Opens a SQLite database in a temporary directory with the migrations
+/// applied.
+async fn test_pool(dir: &tempfile::TempDir) -> SqlitePool {
+    let options = SqliteConnectOptions::new()
+        .filename(dir.path().join("test.db"))
+        .create_if_missing(true);
+    let pool = SqlitePool::connect_with(options).await.unwrap();
+    sqlx::raw_sql(include_str!("../` + sqliteMigration + `"))
+        .execute(&pool)
+        .await
+        .unwrap();
+    pool
+}
+`
+
+// rustRepositoryTest renders a test that runs a repository's methods
+// against SQLite.
+func rustRepositoryTest(t *entityTable) string {
+	name := t.Type.Name
+	key := "saved." + fieldIdent("rust", t.keyField().Name)
+	if storedType(t.keyField()) == typeexpr.String {
+		key = "&" + key
+	}
+	var values []string
+	for i, f := range t.Fields {
+		ident := fieldIdent("rust", f.Name)
+		switch {
+		case i == t.Key && t.generatedKey():
+			values = append(values, fmt.Sprintf("        %s: 0,\n", ident))
+		case f.Required:
+			values = append(values, fmt.Sprintf("        %s: %s,\n", ident, storedLiteral("rust", f, 1)))
+		default:
+			values = append(values, fmt.Sprintf("        %s: None,\n", ident))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n#[tokio::test]\nasync fn %s_repository() {\n", toSnakeCase(name)))
+	sb.WriteString("    let dir = tempfile::tempdir().unwrap();\n")
+	sb.WriteString(fmt.Sprintf("    let repository = %sRepository::new(test_pool(&dir).await);\n", name))
+	sb.WriteString(fmt.Sprintf("    let value = %s {\n%s    };\n", name, strings.Join(values, "")))
+	sb.WriteString("    let saved = repository.insert(&value).await.unwrap();\n")
+	probe := t.probe()
+	if probe >= 0 {
+		ident := fieldIdent("rust", t.Fields[probe].Name)
+		sb.WriteString(fmt.Sprintf("    let mut found = repository.get(%s).await.unwrap().expect(\"inserted %s\");\n", key, name))
+		sb.WriteString(fmt.Sprintf("    %s;\n", probeCheck("rust", t.Fields[probe], "found."+ident, 1)))
+		sb.WriteString(fmt.Sprintf("    found.%s = %s;\n", ident, storedLiteral("rust", t.Fields[probe], 2)))
+		sb.WriteString("    assert!(repository.update(&found).await.unwrap());\n")
+		sb.WriteString(fmt.Sprintf("    let updated = repository.get(%s).await.unwrap().unwrap();\n", key))
+		sb.WriteString(fmt.Sprintf("    %s;\n", probeCheck("rust", t.Fields[probe], "updated."+ident, 2)))
+	} else {
+		sb.WriteString(fmt.Sprintf("    let found = repository.get(%s).await.unwrap().expect(\"inserted %s\");\n", key, name))
+		sb.WriteString("    assert!(repository.update(&found).await.unwrap());\n")
+	}
+	sb.WriteString("    assert_eq!(repository.list().await.unwrap().len(), 1);\n")
+	sb.WriteString(fmt.Sprintf("    assert!(repository.delete(%s).await.unwrap());\n", key))
+	sb.WriteString(fmt.Sprintf("    assert!(repository.get(%s).await.unwrap().is_none());\n}\n", key))
+	return sb.String()
+}
+
+// ============================================================================
+// C#
+// ============================================================================
+
+// csharpDbContext renders the EF Core context mapping each entity to the
+// columns of its table.
+func csharpDbContext(context string, tables []*entityTable) string {
+	var sb strings.Builder
+	sb.WriteString("    /// <summary>\n    /// The database session of the spec's tables.\n    /// </summary>\n")
+	sb.WriteString(fmt.Sprintf("    public class %s : DbContext\n    {\n", context))
+	sb.WriteString(fmt.Sprintf("        public %s(DbContextOptions<%s> options) : base(options) { }\n\n", context, context))
+	sb.WriteString("        protected override void OnModelCreating(ModelBuilder modelBuilder)\n        {\n")
+	for i, t := range tables {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("            modelBuilder.Entity<%s>(entity =>\n            {\n", t.Type.Name))
+		sb.WriteString(fmt.Sprintf("                entity.ToTable(%q);\n", t.Name))
+		sb.WriteString(fmt.Sprintf("                entity.HasKey(e => e.%s);\n", fieldIdent("csharp", t.keyField().Name)))
+		for j, c := range t.Columns {
+			property := fmt.Sprintf("entity.Property(e => e.%s).HasColumnName(%q)", fieldIdent("csharp", t.Fields[j].Name), c.Name)
+			switch {
+			case j == t.Key && t.generatedKey():
+				property += ".ValueGeneratedOnAdd()"
+			case j == t.Key:
+				property += ".ValueGeneratedNever()"
+			case !c.Nullable:
+				property += ".IsRequired()"
+			}
+			if params := sqlTypeParams.FindStringSubmatch(c.SQLType); params != nil && storedType(t.Fields[j]) == typeexpr.String {
+				property += fmt.Sprintf(".HasMaxLength(%s)", strings.Trim(params[1], "() "))
+			}
+			sb.WriteString(fmt.Sprintf("                %s;\n", property))
+		}
+		sb.WriteString("            });\n")
+	}
+	sb.WriteString("        }\n    }\n")
+	return sb.String()
+}
+
+// csharpRepository renders a table's repository over the EF Core context.
+func csharpRepository(t *entityTable, context string) string {
+	name := t.Type.Name
+	set := fmt.Sprintf("_db.Set<%s>()", name)
+	keyType := mapType(t.keyField().Type, "csharp")
+	keyIdent := fieldIdent("csharp", t.keyField().Name)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("    /// <summary>\n    /// Stores %s entities in the %s table.\n    /// </summary>\n", name, t.Name))
+	sb.WriteString(fmt.Sprintf("    public class %sRepository\n    {\n        private readonly %s _db;\n\n", name, context))
+	sb.WriteString(fmt.Sprintf("        public %sRepository(%s db)\n        {\n            _db = db;\n        }\n\n", name, context))
+	if t.generatedKey() {
+		sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// Adds a %s, setting its key to the one the database generated.\n        /// </summary>\n", name))
+	} else {
+		sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// Adds a %s.\n        /// </summary>\n", name))
+	}
+	sb.WriteString(fmt.Sprintf("        public async Task<%s> InsertAsync(%s value)\n        {\n", name, name))
+	sb.WriteString(fmt.Sprintf("            %s.Add(value);\n            await _db.SaveChangesAsync();\n            return value;\n        }\n\n", set))
+	sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// Returns the %s with the given key, or null.\n        /// </summary>\n", name))
+	sb.WriteString(fmt.Sprintf("        public async Task<%s?> GetAsync(%s key)\n        {\n", name, keyType))
+	sb.WriteString(fmt.Sprintf("            return await %s.FindAsync(key);\n        }\n\n", set))
+	sb.WriteString("        /// <summary>\n        /// Writes a value to the row with its key, returning whether there was one.\n        /// </summary>\n")
+	sb.WriteString(fmt.Sprintf("        public async Task<bool> UpdateAsync(%s value)\n        {\n", name))
+	sb.WriteString(fmt.Sprintf("            %s.Update(value);\n            try\n            {\n                await _db.SaveChangesAsync();\n                return true;\n            }\n", set))
+	sb.WriteString("            catch (DbUpdateConcurrencyException)\n            {\n                _db.ChangeTracker.Clear();\n                return false;\n            }\n        }\n\n")
+	sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// Removes the %s with the given key, returning whether there was one.\n        /// </summary>\n", name))
+	sb.WriteString(fmt.Sprintf("        public async Task<bool> DeleteAsync(%s key)\n        {\n", keyType))
+	sb.WriteString("            var value = await GetAsync(key);\n            if (value == null)\n            {\n                return false;\n            }\n")
+	sb.WriteString(fmt.Sprintf("            %s.Remove(value);\n            await _db.SaveChangesAsync();\n            return true;\n        }\n\n", set))
+	sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// Returns every %s, ordered by key.\n        /// </summary>\n", name))
+	sb.WriteString(fmt.Sprintf("        public async Task<List<%s>> ListAsync()\n        {\n", name))
+	sb.WriteString(fmt.Sprintf("            return await %s.OrderBy(e => e.%s).ToListAsync();\n        }\n    }\n", set, keyIdent))
+	return sb.String()
+}
+
+const csharpTestDB = `    public class RepositoriesTests : IDisposable
+    {
+        private readonly string _path = Path.Combine(Path.GetTempPath(), $"{Guid.NewGuid()}.db");
+        private readonly %[1]s _db;
+
+        public RepositoriesTests()
+        {
+            _db = new %[1]s(new DbContextOptionsBuilder<%[1]s>().UseSqlite($"Data Source={_path}").Options);
+            var migration = File.ReadAllText(Path.Combine(AppContext.BaseDirectory, "` + sqliteMigration + `"));
+            foreach (var statement in migration.Split(";\n"))
+            {
+                if (!string.IsNullOrWhiteSpace(statement))
+                {
+                    _db.Database.ExecuteSqlRaw(statement);
+                }
+            }
+        }
+
+        public void Dispose()
+        {
+            _db.Dispose();
+            SqliteConnection.ClearAllPools();
+            File.Delete(_path);
+        }
+`
+
+// csharpRepositoryTest renders a test that runs a repository's methods
+// against SQLite, clearing the change tracker so reads come from the
+// database.
+func csharpRepositoryTest(t *entityTable) string {
+	name := t.Type.Name
+	key := "saved." + fieldIdent("csharp", t.keyField().Name)
+	var values []string
+	for _, i := range t.sampled() {
+		values = append(values, fmt.Sprintf("%s = %s", fieldIdent("csharp", t.Fields[i].Name), storedLiteral("csharp", t.Fields[i], 1)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("        [Fact]\n        public async Task Stores%sEntities()\n        {\n", name))
+	sb.WriteString(fmt.Sprintf("            var repository = new %sRepository(_db);\n", name))
+	sb.WriteString(fmt.Sprintf("            var saved = await repository.InsertAsync(new %s { %s });\n", name, strings.Join(values, ", ")))
+	sb.WriteString("            _db.ChangeTracker.Clear();\n")
+	sb.WriteString(fmt.Sprintf("            var found = await repository.GetAsync(%s);\n            Assert.NotNull(found);\n", key))
+	probe := t.probe()
+	if probe >= 0 {
+		ident := fieldIdent("csharp", t.Fields[probe].Name)
+		sb.WriteString(fmt.Sprintf("            %s;\n", probeCheck("csharp", t.Fields[probe], "found!."+ident, 1)))
+		sb.WriteString(fmt.Sprintf("            found.%s = %s;\n", ident, storedLiteral("csharp", t.Fields[probe], 2)))
+		sb.WriteString("            Assert.True(await repository.UpdateAsync(found));\n            _db.ChangeTracker.Clear();\n")
+		sb.WriteString(fmt.Sprintf("            %s;\n", probeCheck("csharp", t.Fields[probe], fmt.Sprintf("(await repository.GetAsync(%s))!.%s", key, ident), 2)))
+	} else {
+		sb.WriteString("            Assert.True(await repository.UpdateAsync(found!));\n            _db.ChangeTracker.Clear();\n")
+	}
+	sb.WriteString("            Assert.Single(await repository.ListAsync());\n")
+	sb.WriteString(fmt.Sprintf("            Assert.True(await repository.DeleteAsync(%s));\n", key))
+	sb.WriteString(fmt.Sprintf("            Assert.Null(await repository.GetAsync(%s));\n        }\n", key))
+	return sb.String()
+}
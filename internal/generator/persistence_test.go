@@ -0,0 +1,249 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const blogSpec = "# Blog\n\n" +
+	"Posts and their authors.\n\n" +
+	"## Types\n\n" +
+	"### Post (struct)\n\n" +
+	"| Field | Type | Description |\n" +
+	"|-------|------|-------------|\n" +
+	"| slug | string | {maxLength: 80} `db:\"slug\"` |\n" +
+	"| author_id | int | `db:\"author_id\"` |\n" +
+	"| title | string | `db:\"title\"` |\n" +
+	"| score | Optional[decimal] | `db:\"score\"` |\n" +
+	"| published | Optional[date] | `db:\"published\"` |\n\n" +
+	"### User (struct)\n\n" +
+	"| Field | Type | Description |\n" +
+	"|-------|------|-------------|\n" +
+	"| id | int | `db:\"id\"` |\n" +
+	"| email | string | {maxLength: 120} `db:\"email\"` |\n" +
+	"| name | Optional[string] | `db:\"name\"` |\n" +
+	"| active | bool | `db:\"active\"` |\n" +
+	"| created_at | datetime | `db:\"created_at\"` |\n\n" +
+	"### PostTag (struct)\n\n" +
+	"| Field | Type | Description |\n" +
+	"|-------|------|-------------|\n" +
+	"| post_slug | string | `db:\"post_slug\"` |\n" +
+	"| tag | string | `db:\"tag\"` |\n\n" +
+	"## Database\n\n" +
+	"### `posts`\n\n" +
+	"**Entity**: `Post`\n\n" +
+	"| Column | Type | SQL Type | Nullable | Default | Constraints | Description |\n" +
+	"|--------|------|----------|----------|---------|-------------|-------------|\n" +
+	"| `slug` | `string` | `VARCHAR(80)` | No | - | primary key | - |\n" +
+	"| `author_id` | `int` | `INT` | No | - | - | - |\n" +
+	"| `title` | `string` | `TEXT` | No | - | - | - |\n" +
+	"| `score` | `decimal` | `NUMERIC(5,2)` | Yes | `0` | - | - |\n" +
+	"| `published` | `date` | `DATE` | Yes | - | - | - |\n\n" +
+	"**Indexes**:\n" +
+	"- `posts_author_idx`: `author_id`\n\n" +
+	"**Foreign Keys**:\n" +
+	"- `author_id` references `users` (`id`) on delete cascade\n\n" +
+	"### `users`\n\n" +
+	"**Entity**: `User`\n\n" +
+	"| Column | Type | SQL Type | Nullable | Default | Constraints | Description |\n" +
+	"|--------|------|----------|----------|---------|-------------|-------------|\n" +
+	"| `id` | `int` | `INTEGER` | No | - | primary key, auto increment | - |\n" +
+	"| `email` | `string` | `VARCHAR(120)` | No | - | unique | - |\n" +
+	"| `name` | `string` | `TEXT` | Yes | - | - | - |\n" +
+	"| `active` | `bool` | `BOOLEAN` | No | `TRUE` | - | - |\n" +
+	"| `created_at` | `datetime` | `TIMESTAMP` | No | `now()` | - | - |\n\n" +
+	"### `post_tags`\n\n" +
+	"**Entity**: `PostTag`\n\n" +
+	"| Column | Type | SQL Type | Nullable | Default | Constraints | Description |\n" +
+	"|--------|------|----------|----------|---------|-------------|-------------|\n" +
+	"| `post_slug` | `string` | `VARCHAR(80)` | No | - | - | - |\n" +
+	"| `tag` | `string` | `TEXT` | No | - | - | - |\n\n" +
+	"**Primary Key**: `post_slug`, `tag`\n\n" +
+	"**Foreign Keys**:\n" +
+	"- `post_slug` references `posts` (`slug`)\n"
+
+func TestEntityTables(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(blogSpec, "blog.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	tables, skipped := entityTables(spec)
+	if len(tables) != 2 || len(skipped) != 1 || skipped[0] != "post_tags: has a composite primary key" {
+		t.Fatalf("Expected posts and users to resolve and post_tags to be skipped, got %d and %v", len(tables), skipped)
+	}
+
+	posts, users := tables[0], tables[1]
+	if posts.Type.Name != "Post" || posts.keyName() != "slug" || posts.generatedKey() {
+		t.Errorf("Expected posts keyed by slug, got %+v", posts.SpecTable)
+	}
+	if len(posts.inserted()) != 5 || len(posts.updated()) != 4 {
+		t.Errorf("Expected posts to insert every column and update all but the key, got %v and %v", posts.inserted(), posts.updated())
+	}
+	if !users.generatedKey() || len(users.inserted()) != 4 || users.Fields[2].Name != "name" {
+		t.Errorf("Expected users to leave its generated key out of inserts, got %v", users.inserted())
+	}
+
+	// The probe skips the key, constrained and optional fields
+	if p := posts.probe(); p != 1 {
+		t.Errorf("Expected author_id to be the posts probe, got %d", p)
+	}
+	if p := users.probe(); p != 3 {
+		t.Errorf("Expected active to be the users probe, got %d", p)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*specparser.SpecTable)
+		err    string
+	}{
+		{"no entity", func(tb *specparser.SpecTable) { tb.Entity = "" }, "has no entity"},
+		{"unknown entity", func(tb *specparser.SpecTable) { tb.Entity = "Author" }, "not a struct"},
+		{"no key", func(tb *specparser.SpecTable) { tb.PrimaryKey = nil }, "has no primary key"},
+		{"missing field", func(tb *specparser.SpecTable) { tb.Columns[2].Name = "headline" }, "column headline has no field"},
+		{"missing column", func(tb *specparser.SpecTable) { tb.Columns = tb.Columns[:4] }, "field published of Post has no column"},
+		{"optional key", func(tb *specparser.SpecTable) { tb.PrimaryKey = []string{"score"} }, "must be a required string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := spec.Tables[0]
+			table.Columns = append([]specparser.SpecColumn(nil), table.Columns...)
+			tt.modify(&table)
+			if _, err := resolveEntityTable(spec, table); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(blogSpec, "blog.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range migrationFiles(spec, "") {
+		files[f.Path] = f.Content
+	}
+	tests := []struct {
+		dialect string
+		want    []string
+	}{
+		{"sqlite", []string{"id INTEGER PRIMARY KEY AUTOINCREMENT,", "email VARCHAR(120) NOT NULL UNIQUE,", "slug VARCHAR(80) NOT NULL PRIMARY KEY,", "score NUMERIC(5,2) DEFAULT 0,", "created_at TIMESTAMP NOT NULL\n);", "PRIMARY KEY (post_slug, tag)", "CREATE INDEX posts_author_idx ON posts (author_id);"}},
+		{"postgres", []string{"id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,", "active BOOLEAN NOT NULL DEFAULT TRUE,", "FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE"}},
+		{"mysql", []string{"id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,", "title VARCHAR(255) NOT NULL,", "score DECIMAL(5,2) DEFAULT 0,", "created_at DATETIME NOT NULL"}},
+	}
+	for _, tt := range tests {
+		up := files["migrations/"+tt.dialect+"/0001_create_tables.up.sql"]
+		for _, want := range tt.want {
+			if !strings.Contains(up, want) {
+				t.Errorf("Expected the %s migration to contain %q, got:\n%s", tt.dialect, want, up)
+			}
+		}
+
+		// users is created before the tables referencing it and dropped after them
+		users, posts, tags := strings.Index(up, "CREATE TABLE users"), strings.Index(up, "CREATE TABLE posts"), strings.Index(up, "CREATE TABLE post_tags")
+		if users < 0 || users > posts || posts > tags {
+			t.Errorf("Expected users, posts, then post_tags in the %s migration, got:\n%s", tt.dialect, up)
+		}
+		down := files["migrations/"+tt.dialect+"/0001_create_tables.down.sql"]
+		if !strings.HasSuffix(down, "DROP TABLE IF EXISTS post_tags;\nDROP TABLE IF EXISTS posts;\nDROP TABLE IF EXISTS users;\n") {
+			t.Errorf("Expected the %s down migration to drop the tables in reverse, got:\n%s", tt.dialect, down)
+		}
+	}
+
+	quoted := []struct{ dialect, name, want string }{
+		{"sqlite", "users", "users"},
+		{"postgres", "user", `"user"`},
+		{"mysql", "Order", "`Order`"},
+	}
+	for _, tt := range quoted {
+		if got := quoteSQL(tt.dialect, tt.name); got != tt.want {
+			t.Errorf("Expected quoteSQL(%s, %s) to be %s, got %s", tt.dialect, tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestGeneratePersistence(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(blogSpec, "blog.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		files    map[string][]string
+	}{
+		{"go", map[string][]string{
+			"repositories.go":      {"//   - post_tags: has a composite primary key", "func NewUserRepository(db *sql.DB) *UserRepository", "\"INSERT INTO users (email, name, active, created_at) VALUES (?, ?, ?, ?)\"", "v.Id = int(id)", "func (r *PostRepository) Get(ctx context.Context, key string) (*Post, error)", "return rowAffected(res)"},
+			"repositories_test.go": {"_ \"modernc.org/sqlite\"", "os.ReadFile(\"migrations/sqlite/0001_create_tables.up.sql\")", "v := &Post{Slug: \"x\", AuthorId: 1, Title: \"a\"}", "if !got.Active {", "!errors.Is(err, sql.ErrNoRows)"},
+			"go.mod":               {"modernc.org/sqlite"},
+		}},
+		{"typescript", map[string][]string{
+			"src/repositories.ts":      {"import type { Knex } from \"knex\";", "export class UserRepository {", ".insert(userToRow(value)).returning(\"id\");", "published: value.published === undefined ? null : value.published.toISOString().slice(0, 10),", "active: Boolean(row.active),"},
+			"src/repositories.test.ts": {"import { PostRepository, UserRepository } from \"./repositories\";", "client: \"better-sqlite3\"", "await repository.insert({ id: 0, email: \"x\", active: true, createdAt: new Date(\"2024-01-01T12:00:00.000Z\") })"},
+			"package.json":             {"\"knex\"", "\"better-sqlite3\""},
+		}},
+		{"python", map[string][]string{
+			"src/repositories.py":        {"from .types import Post, User", "Column(\"slug\", String(80), primary_key=True),", "return value.model_copy(update={\"id\": result.inserted_primary_key[0]})", "conn.execute(insert(post_table).values({\"slug\": value.slug, **_post_row(value)}))"},
+			"tests/test_repositories.py": {"from src.repositories import PostRepository, UserRepository", "saved = repository.insert(Post(slug=\"x\", author_id=1, title=\"a\"))", "assert found.active is True"},
+			"pyproject.toml":             {"sqlalchemy"},
+		}},
+		{"java", map[string][]string{
+			"src/main/java/blog/UserRepository.java":      {"public interface UserRepository extends JpaRepository<User, Integer> {"},
+			"src/main/java/blog/PostRepository.java":      {"public interface PostRepository extends JpaRepository<Post, String> {"},
+			"src/main/resources/META-INF/orm.xml":         {"<entity class=\"User\">", "<generated-value strategy=\"IDENTITY\"/>", "<column name=\"email\" nullable=\"false\" unique=\"true\" length=\"120\"/>"},
+			"src/main/resources/META-INF/persistence.xml": {"<persistence-unit name=\"blog\" transaction-type=\"RESOURCE_LOCAL\">"},
+			"src/test/java/blog/RepositoriesTest.java":    {"import java.time.LocalDateTime;", "value.setCreatedAt(LocalDateTime.of(2024, 1, 1, 12, 0));", "assertEquals(2, repository.findById(saved.getSlug()).orElseThrow().getAuthorId());"},
+			"pom.xml": {"spring-data-jpa", "sqlite-jdbc"},
+		}},
+		{"rust", map[string][]string{
+			"src/repositories.rs":   {"use crate::types::{Post, User};", "value.id = result.last_insert_rowid() as i32;", "pub async fn get(&self, key: &str) -> Result<Option<Post>, sqlx::Error> {", "title: row.try_get(\"title\")?,"},
+			"tests/repositories.rs": {"use blog::repositories::{PostRepository, UserRepository};", "include_str!(\"../migrations/sqlite/0001_create_tables.up.sql\")", "assert!(!updated.active);", "repository.get(&saved.slug)"},
+			"src/lib.rs":            {"pub mod repositories;"},
+			"Cargo.toml":            {"sqlx", "\"chrono\"", "tempfile"},
+		}},
+		{"csharp", map[string][]string{
+			"src/Repositories.cs":        {"public class BlogDbContext : DbContext", "entity.Property(e => e.Id).HasColumnName(\"id\").ValueGeneratedOnAdd();", "entity.Property(e => e.Slug).HasColumnName(\"slug\").ValueGeneratedNever().HasMaxLength(80);", "return await _db.Set<Post>().FindAsync(key);"},
+			"tests/RepositoriesTests.cs": {"UseSqlite($\"Data Source={_path}\")", "Assert.Equal(1, found!.AuthorId);", "Assert.Null(await repository.GetAsync(saved.Id));"},
+			"Blog.csproj":                {"<None Include=\"migrations/**\" CopyToOutputDirectory=\"PreserveNewest\" />", "Microsoft.EntityFrameworkCore.Sqlite"},
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			files := generatedFiles(t, gen, spec, tt.language)
+			for path, content := range files {
+				for _, e := range checkSyntax(path, content) {
+					t.Errorf("Unexpected syntax error in %s: %s", path, e)
+				}
+			}
+			if _, ok := files["migrations/sqlite/0001_create_tables.up.sql"]; !ok {
+				t.Errorf("Expected the SQLite migration to be generated")
+			}
+			expectGenerated(t, files, tt.files)
+		})
+	}
+
+	// Without an entity that resolves, only the migrations are generated
+	spec.Tables = spec.Tables[2:]
+	adapter, _ := gen.registry.Get("go")
+	files := gen.generatePersistence(spec, adapter)
+	if len(files) != 6 || !strings.Contains(files[0].Content, "--   - post_tags: has a composite primary key") {
+		t.Errorf("Expected only migrations noting post_tags, got %d files", len(files))
+	}
+}
+
+func TestPersistenceBuilds(t *testing.T) {
+	// The generated repository tests need the SQLite driver, which go vet
+	// would have to download, so Go only builds the packages.
+	checkBuilds(t, blogSpec, "python")
+	t.Run("go", func(t *testing.T) {
+		requireTool(t, "go")
+		runTool(t, generateProject(t, blogSpec, "go"), "go", "build", "./...")
+	})
+}
@@ -0,0 +1,1293 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/propexpr"
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// propertyView is the data property templates render: a spec property with
+// its generated inputs and its check in the language.
+type propertyView struct {
+	specparser.SpecProperty
+
+	// Lang is the language ID
+	Lang string
+
+	// FuncName is the test's name as PascalCase (e.g., "DecodeReversesEncode")
+	FuncName string
+
+	// Title is the description, or the expression when there is none
+	Title string
+
+	// Inputs are the values the property library generates
+	Inputs []propertyInput
+
+	// Setup are the statements run before the check, such as calls Go
+	// hoists out of the expression
+	Setup []string
+
+	// Check is the property's condition in the language
+	Check string
+
+	// Async is set when the check awaits a function
+	Async bool
+}
+
+// propertyInput is a value generated for a property test.
+type propertyInput struct {
+	Name  string
+	Ident string
+
+	// Type is the input's type in the language
+	Type string
+
+	// Arbitrary generates the input's values with the language's property
+	// library; Java leaves it empty for types jqwik generates by default
+	Arbitrary string
+}
+
+// maxPropertyDepth bounds how deep generators nest, which also stops
+// recursive types.
+const maxPropertyDepth = 4
+
+// ============================================================================
+// Resolution
+// ============================================================================
+
+// propertyScope is a spec property resolved against the spec's functions:
+// the inputs it generates and the pseudo-types of the names it uses.
+type propertyScope struct {
+	spec *specparser.SpecAnalysis
+	expr *propexpr.Expr
+
+	// subject is the function whose heading the property sits under; the
+	// property's "result" is the subject's result
+	subject *specparser.SpecFunction
+
+	// inputs are the generated names in the order they appear, with their
+	// pseudo-types in types
+	inputs []string
+	types  map[string]string
+}
+
+// resolveProperty resolves a property's names. Each name takes the
+// pseudo-type of the parameter it is passed to; a property under a
+// function's heading also takes the function's parameters as inputs.
+func resolveProperty(spec *specparser.SpecAnalysis, p specparser.SpecProperty) (*propertyScope, error) {
+	if p.Expression == "" {
+		return nil, fmt.Errorf("described in prose only")
+	}
+	expr, err := propexpr.Parse(p.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &propertyScope{spec: spec, expr: expr, types: make(map[string]string)}
+	bind := func(name, pseudoType string) {
+		if _, ok := s.types[name]; !ok {
+			s.inputs = append(s.inputs, name)
+			s.types[name] = pseudoType
+		}
+	}
+	if p.Function != "" {
+		if s.subject = findSpecFunction(spec, p.Function); s.subject == nil {
+			return nil, fmt.Errorf("unknown function %s", p.Function)
+		}
+		for _, param := range s.subject.Parameters {
+			bind(param.Name, param.Type)
+		}
+	}
+
+	var failure error
+	fail := func(format string, args ...any) {
+		if failure == nil {
+			failure = fmt.Errorf(format, args...)
+		}
+	}
+	propexpr.Walk(expr, func(e *propexpr.Expr) {
+		if e.Kind != propexpr.Call {
+			return
+		}
+		if e.Name == "len" {
+			if len(e.Args) != 1 {
+				fail("len takes one argument")
+			}
+			return
+		}
+		f := findSpecFunction(spec, e.Name)
+		switch {
+		case f == nil:
+			fail("unknown function %s", e.Name)
+		case len(e.Args) != len(f.Parameters):
+			fail("%s takes %d arguments, got %d", e.Name, len(f.Parameters), len(e.Args))
+		default:
+			for i, arg := range e.Args {
+				if arg.Kind == propexpr.Ident && !s.isResult(arg.Name) {
+					bind(arg.Name, f.Parameters[i].Type)
+				}
+			}
+		}
+	})
+	propexpr.Walk(expr, func(e *propexpr.Expr) {
+		if e.Kind == propexpr.Ident && !s.isResult(e.Name) && s.types[e.Name] == "" {
+			fail("cannot tell the type of %s; pass it to a function", e.Name)
+		}
+	})
+	if failure != nil {
+		return nil, failure
+	}
+
+	t, err := s.typeOf(expr)
+	if err != nil {
+		return nil, err
+	}
+	if t != typeexpr.Bool {
+		return nil, fmt.Errorf("is not a condition")
+	}
+	if len(s.inputs) == 0 {
+		return nil, fmt.Errorf("has no inputs to generate")
+	}
+	return s, nil
+}
+
+// findSpecFunction finds a spec function, not a method, by name in any
+// case convention.
+func findSpecFunction(spec *specparser.SpecAnalysis, name string) *specparser.SpecFunction {
+	for i, f := range spec.Functions {
+		if f.Receiver == "" && (f.Name == name || toPascalCase(f.Name) == toPascalCase(name)) {
+			return &spec.Functions[i]
+		}
+	}
+	return nil
+}
+
+// isResult reports whether a name is the subject's result.
+func (s *propertyScope) isResult(name string) bool {
+	if s.subject == nil || name != "result" {
+		return false
+	}
+	_, isParam := s.types[name]
+	return !isParam
+}
+
+// typeOf returns the pseudo-type of an expression, reporting names and
+// fields that do not resolve.
+func (s *propertyScope) typeOf(e *propexpr.Expr) (string, error) {
+	switch e.Kind {
+	case propexpr.Literal:
+		return e.Type, nil
+
+	case propexpr.Ident:
+		if s.isResult(e.Name) {
+			return returnType(*s.subject)
+		}
+		return s.types[e.Name], nil
+
+	case propexpr.Call:
+		for _, arg := range e.Args {
+			if _, err := s.typeOf(arg); err != nil {
+				return "", err
+			}
+		}
+		if e.Name == "len" {
+			t, _ := s.typeOf(e.Args[0])
+			if !hasLength(t) {
+				return "", fmt.Errorf("len of %s, which is not a string or collection", e.Args[0])
+			}
+			return typeexpr.Int, nil
+		}
+		return returnType(*findSpecFunction(s.spec, e.Name))
+
+	case propexpr.Field:
+		base, err := s.typeOf(e.Args[0])
+		if err != nil {
+			return "", err
+		}
+		t, ok := s.structOf(base)
+		if !ok {
+			return "", fmt.Errorf("%s has no fields", e.Args[0])
+		}
+		for _, f := range t.Fields {
+			if toPascalCase(f.Name) == toPascalCase(e.Name) {
+				return fieldType(f), nil
+			}
+		}
+		return "", fmt.Errorf("%s has no field %s", t.Name, e.Name)
+
+	case propexpr.Unary:
+		t, err := s.typeOf(e.Args[0])
+		if e.Op == "!" {
+			return typeexpr.Bool, err
+		}
+		return t, err
+	}
+
+	left, err := s.typeOf(e.Args[0])
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.typeOf(e.Args[1]); err != nil {
+		return "", err
+	}
+	if propexpr.Comparison(e.Op) || propexpr.Logical(e.Op) {
+		return typeexpr.Bool, nil
+	}
+	return left, nil
+}
+
+// returnType returns the pseudo-type a function returns.
+func returnType(f specparser.SpecFunction) (string, error) {
+	if len(f.Returns) == 0 {
+		return "", fmt.Errorf("%s returns nothing", f.Name)
+	}
+	return f.Returns[0].Type, nil
+}
+
+// structOf returns the spec struct a pseudo-type names.
+func (s *propertyScope) structOf(pseudoType string) (specparser.SpecType, bool) {
+	expr, err := typeexpr.Parse(pseudoType)
+	if err != nil || expr.Kind != typeexpr.Named {
+		return specparser.SpecType{}, false
+	}
+	t, ok := findType(s.spec.Types, expr.Name)
+	return t, ok && isStructKind(t.Kind)
+}
+
+// hasLength reports whether len applies to a pseudo-type.
+func hasLength(pseudoType string) bool {
+	expr, err := typeexpr.Parse(pseudoType)
+	if err != nil {
+		return false
+	}
+	switch expr.Kind {
+	case typeexpr.List, typeexpr.Map, typeexpr.Set:
+		return true
+	case typeexpr.Primitive:
+		return expr.Name == typeexpr.String || expr.Name == typeexpr.Bytes
+	}
+	return false
+}
+
+// valueKind classifies a pseudo-type for comparisons: "number", "string",
+// "bool" and "enum" values compare with the language's operators, while
+// "" marks composite values.
+func (s *propertyScope) valueKind(pseudoType string) string {
+	expr, err := typeexpr.Parse(pseudoType)
+	if err != nil {
+		return ""
+	}
+	switch expr.Kind {
+	case typeexpr.Primitive:
+		switch expr.Name {
+		case typeexpr.Int, typeexpr.Int64, typeexpr.Float, typeexpr.Decimal:
+			return "number"
+		case typeexpr.String:
+			return "string"
+		case typeexpr.Bool:
+			return "bool"
+		}
+	case typeexpr.Named:
+		if t, ok := findType(s.spec.Types, expr.Name); ok && t.Kind == "enum" {
+			return "enum"
+		}
+	}
+	return ""
+}
+
+// namesSpecType reports whether a pseudo-type refers to a spec type, whose
+// generated code has no structural equality in Java, Rust and C#.
+func (s *propertyScope) namesSpecType(pseudoType string) bool {
+	found := false
+	for _, name := range typeNames(pseudoType) {
+		if _, ok := findType(s.spec.Types, name); ok {
+			found = true
+		}
+	}
+	return found
+}
+
+// typeNames returns the user type names in a pseudo-type.
+func typeNames(pseudoType string) []string {
+	expr, err := typeexpr.Parse(pseudoType)
+	if err != nil {
+		return nil
+	}
+	return expr.Names()
+}
+
+// propertyName names a property's test after its description, or after
+// its function and position when it has none.
+func propertyName(p specparser.SpecProperty, index int) string {
+	words := strings.FieldsFunc(p.Description, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 8 {
+		words = words[:8]
+	}
+	name := toPascalCase(strings.Join(words, " "))
+	if name == "" {
+		name = toPascalCase(p.Function) + "Invariant" + strconv.Itoa(index+1)
+	}
+	if !unicode.IsLetter(rune(name[0])) {
+		name = "Invariant" + name
+	}
+	return name
+}
+
+// ============================================================================
+// Rendering
+// ============================================================================
+
+// propertyRenderer renders a resolved property in one language, collecting
+// the setup, helpers and imports the rendered check needs.
+type propertyRenderer struct {
+	*propertyScope
+	lang  string
+	crate string
+
+	setup []string
+	temps int
+	async bool
+
+	// helpers names the comparison helpers and modules the check uses
+	helpers map[string]bool
+
+	// functions and used are the spec functions called and the spec types
+	// referenced, for imports
+	functions []specparser.SpecFunction
+	used      []string
+}
+
+func newPropertyRenderer(s *propertyScope, langID string) *propertyRenderer {
+	return &propertyRenderer{propertyScope: s, lang: langID, crate: toPackageName(s.spec.Name), helpers: make(map[string]bool)}
+}
+
+// ident returns an input's identifier.
+func (r *propertyRenderer) ident(name string) string {
+	if r.isResult(name) {
+		return "result"
+	}
+	return paramIdent(r.lang, name)
+}
+
+// view renders the property's inputs and check.
+func (r *propertyRenderer) view(p specparser.SpecProperty, name string) (propertyView, error) {
+	v := propertyView{SpecProperty: p, Lang: r.lang, FuncName: name, Title: p.Description}
+	if v.Title == "" {
+		v.Title = p.Expression
+	}
+	for _, input := range r.inputs {
+		pseudoType := r.types[input]
+		arbitrary, err := r.arbitrary(pseudoType)
+		if err != nil {
+			return v, err
+		}
+		for _, t := range typeNames(pseudoType) {
+			r.useType(t)
+		}
+		v.Inputs = append(v.Inputs, propertyInput{Name: input, Ident: r.ident(input), Type: mapType(pseudoType, r.lang), Arbitrary: arbitrary})
+	}
+
+	// A property under a function's heading checks the function's result
+	usesResult := false
+	propexpr.Walk(r.expr, func(e *propexpr.Expr) {
+		usesResult = usesResult || (e.Kind == propexpr.Ident && r.isResult(e.Name))
+	})
+	if usesResult {
+		call := &propexpr.Expr{Kind: propexpr.Call, Name: r.subject.Name}
+		for _, param := range r.subject.Parameters {
+			call.Args = append(call.Args, &propexpr.Expr{Kind: propexpr.Ident, Name: param.Name})
+		}
+		if _, err := r.call(call, "result"); err != nil {
+			return v, err
+		}
+	}
+
+	check, err := r.render(r.expr)
+	if err != nil {
+		return v, err
+	}
+	v.Setup, v.Check, v.Async = r.setup, check, r.async
+	return v, nil
+}
+
+// render renders an expression; Go hoists calls into setup statements.
+func (r *propertyRenderer) render(e *propexpr.Expr) (string, error) {
+	switch e.Kind {
+	case propexpr.Literal:
+		switch {
+		case e.Type == "string":
+			return strconv.Quote(e.Name), nil
+		case e.Type == "bool" && r.lang == "python":
+			return strings.ToUpper(e.Name[:1]) + e.Name[1:], nil
+		}
+		return e.Name, nil
+
+	case propexpr.Ident:
+		return r.ident(e.Name), nil
+
+	case propexpr.Call:
+		if e.Name == "len" {
+			return r.length(e.Args[0])
+		}
+		return r.call(e, "")
+
+	case propexpr.Field:
+		base, err := r.operand(e.Args[0])
+		if err != nil {
+			return "", err
+		}
+		t, _ := r.typeOf(e.Args[0])
+		st, _ := r.structOf(t)
+		for _, f := range st.Fields {
+			if toPascalCase(f.Name) == toPascalCase(e.Name) {
+				if r.lang == "java" {
+					return base + "." + javaAccessor("get", f.Name) + "()", nil
+				}
+				return base + "." + fieldIdent(r.lang, f.Name), nil
+			}
+		}
+		return "", fmt.Errorf("%s has no field %s", st.Name, e.Name)
+
+	case propexpr.Unary:
+		operand, err := r.operand(e.Args[0])
+		if err != nil {
+			return "", err
+		}
+		if e.Op == "!" && r.lang == "python" {
+			return "not " + operand, nil
+		}
+		return e.Op + operand, nil
+	}
+
+	left, err := r.operand(e.Args[0])
+	if err != nil {
+		return "", err
+	}
+	right, err := r.operand(e.Args[1])
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case e.Op == "==" || e.Op == "!=":
+		return r.equal(e, left, right), nil
+	case propexpr.Logical(e.Op) && r.lang == "python":
+		return left + map[string]string{"&&": " and ", "||": " or "}[e.Op] + right, nil
+	}
+	return left + " " + e.Op + " " + right, nil
+}
+
+// operand renders an operand, parenthesizing operations.
+func (r *propertyRenderer) operand(e *propexpr.Expr) (string, error) {
+	s, err := r.render(e)
+	if err == nil && (e.Kind == propexpr.Unary || e.Kind == propexpr.Binary) {
+		s = "(" + s + ")"
+	}
+	return s, err
+}
+
+// equal renders an equality test. Scalars compare with the language's
+// operators; composite values compare structurally, through JSON where
+// the generated types lack structural equality.
+func (r *propertyRenderer) equal(e *propexpr.Expr, left, right string) string {
+	pseudoType, _ := r.typeOf(e.Args[0])
+	if e.Args[0].Kind == propexpr.Literal {
+		pseudoType, _ = r.typeOf(e.Args[1])
+	}
+	kind := r.valueKind(pseudoType)
+	named := r.namesSpecType(pseudoType)
+
+	helper := ""
+	switch r.lang {
+	case "go":
+		if kind == "" {
+			helper = "reflect.DeepEqual"
+		}
+	case "typescript":
+		if kind == "" {
+			helper = "isDeepStrictEqual"
+		}
+	case "java":
+		switch {
+		case kind == "string" || (kind == "" && !named):
+			helper = "Objects.equals"
+		case kind == "":
+			helper = "sameValue"
+		}
+	case "rust":
+		if kind == "enum" || (kind == "" && named) {
+			helper = "same_value"
+			left, right = "&"+left, "&"+right
+		}
+	case "csharp":
+		if kind == "" {
+			helper = "SameValue"
+		}
+	}
+	if helper == "" {
+		op := e.Op
+		if r.lang == "typescript" {
+			op += "="
+		}
+		return left + " " + op + " " + right
+	}
+
+	r.helpers[helper] = true
+	check := helper + "(" + left + ", " + right + ")"
+	if e.Op == "!=" {
+		check = "!" + check
+	}
+	return check
+}
+
+// length renders the length of a string or collection.
+func (r *propertyRenderer) length(arg *propexpr.Expr) (string, error) {
+	s, err := r.operand(arg)
+	if err != nil {
+		return "", err
+	}
+	t, _ := r.typeOf(arg)
+	expr, _ := typeexpr.Parse(t)
+	text := expr.Kind == typeexpr.Primitive
+
+	switch r.lang {
+	case "go", "python":
+		return "len(" + s + ")", nil
+	case "typescript":
+		if expr.Kind == typeexpr.Set || (expr.Kind == typeexpr.Map && !strings.HasPrefix(mapType(t, r.lang), "Record<")) {
+			return s + ".size", nil
+		}
+		return s + ".length", nil
+	case "java":
+		switch {
+		case text && expr.Name == typeexpr.String:
+			return s + ".length()", nil
+		case text:
+			return s + ".length", nil
+		}
+		return s + ".size()", nil
+	case "rust":
+		return s + ".len()", nil
+	case "csharp":
+		if text {
+			return s + ".Length", nil
+		}
+		return s + ".Count", nil
+	}
+	return "", fmt.Errorf("no length in %s", r.lang)
+}
+
+// call renders a call of a spec function. Go binds each call's result in a
+// setup statement, returning early when the function fails; bind names the
+// variable a call's result is bound to in every language.
+func (r *propertyRenderer) call(e *propexpr.Expr, bind string) (string, error) {
+	f := findSpecFunction(r.spec, e.Name)
+	if !f.IsPublic && (r.lang == "java" || r.lang == "rust" || r.lang == "csharp") {
+		return "", fmt.Errorf("calls private function %s", f.Name)
+	}
+	if f.IsAsync && r.lang == "rust" {
+		return "", fmt.Errorf("calls async function %s", f.Name)
+	}
+	if !containsFunction(r.functions, f.Name) {
+		r.functions = append(r.functions, *f)
+	}
+
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		s, err := r.render(arg)
+		if err != nil {
+			return "", err
+		}
+		// Rust functions take their arguments by value
+		if r.lang == "rust" {
+			switch {
+			case arg.Kind == propexpr.Ident || arg.Kind == propexpr.Field:
+				s += ".clone()"
+			case arg.Kind == propexpr.Literal && arg.Type == "string":
+				s += ".to_string()"
+			}
+		}
+		args[i] = s
+	}
+	ident := moduleFuncIdent(r.lang, *f)
+	call := ident + "(" + strings.Join(args, ", ") + ")"
+
+	switch r.lang {
+	case "go":
+		view := newFunctionView(*f, r.lang)
+		if values := len(view.Results) - boolToInt(containsError(view.Results)); values != 1 {
+			return "", fmt.Errorf("%s returns %d values", f.Name, values)
+		}
+		for bind == "" || r.isInput(bind) {
+			r.temps++
+			bind = fmt.Sprintf("v%d", r.temps)
+		}
+		if containsError(view.Results) {
+			r.setup = append(r.setup, fmt.Sprintf("%s, err := %s\nif err != nil {\nreturn false\n}", bind, call))
+		} else {
+			r.setup = append(r.setup, bind+" := "+call)
+		}
+		return bind, nil
+	case "typescript":
+		if f.IsAsync {
+			r.async = true
+			call = "await " + call
+			if bind == "" {
+				call = "(" + call + ")"
+			}
+		}
+	case "python":
+		if f.IsAsync {
+			r.helpers["asyncio"] = true
+			call = "asyncio.run(" + call + ")"
+		}
+	case "java":
+		call = serviceRef(r.spec, r.lang, f.Module) + "." + call
+	case "rust":
+		call = rustModulePath(r.crate, f.Module, "service") + "::" + call
+		if rustWrapsErrors(*f) {
+			call += ".unwrap()"
+		}
+	case "csharp":
+		call = serviceRef(r.spec, r.lang, f.Module) + "." + call
+		if f.IsAsync {
+			call += ".GetAwaiter().GetResult()"
+		}
+	}
+
+	if bind == "" {
+		return call, nil
+	}
+	switch r.lang {
+	case "typescript":
+		r.setup = append(r.setup, fmt.Sprintf("const %s = %s;", bind, call))
+	case "python":
+		r.setup = append(r.setup, fmt.Sprintf("%s = %s", bind, call))
+	case "rust":
+		r.setup = append(r.setup, fmt.Sprintf("let %s = %s;", bind, call))
+	default:
+		r.setup = append(r.setup, fmt.Sprintf("var %s = %s;", bind, call))
+	}
+	return bind, nil
+}
+
+// isInput reports whether an identifier names one of the inputs.
+func (r *propertyRenderer) isInput(ident string) bool {
+	for _, input := range r.inputs {
+		if r.ident(input) == ident {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFunction(functions []specparser.SpecFunction, name string) bool {
+	for _, f := range functions {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// useType records a spec type the test refers to.
+func (r *propertyRenderer) useType(name string) {
+	t, ok := findType(r.spec.Types, name)
+	if ok && !containsString(r.used, typeName(t.Name)) {
+		r.used = append(r.used, typeName(t.Name))
+	}
+}
+
+// ============================================================================
+// Generators
+// ============================================================================
+
+// arbitrary returns the generator of a pseudo-type's values in the
+// language's property library.
+func (r *propertyRenderer) arbitrary(pseudoType string) (string, error) {
+	expr, err := typeexpr.Parse(pseudoType)
+	if err != nil {
+		return "", fmt.Errorf("no generator for %s", pseudoType)
+	}
+	switch r.lang {
+	case "go":
+		// testing/quick generates values from the types themselves
+		return "", r.quickable(expr, 0)
+	case "java":
+		arb, custom, err := r.jqwik(expr, 0)
+		if !custom {
+			arb = ""
+		}
+		return arb, err
+	}
+	return r.generator(expr, 0)
+}
+
+// specTypeOf returns the spec type a named type expression refers to.
+func (r *propertyRenderer) specTypeOf(expr *typeexpr.Expr, depth int) (specparser.SpecType, error) {
+	t, ok := findType(r.spec.Types, expr.Name)
+	switch {
+	case !ok:
+		return t, fmt.Errorf("no generator for %s", expr.Name)
+	case depth >= maxPropertyDepth:
+		return t, fmt.Errorf("%s nests too deeply to generate", t.Name)
+	case t.Kind != "enum" && !isStructKind(t.Kind):
+		return t, fmt.Errorf("no generator for %s %s", t.Kind, t.Name)
+	case t.Kind == "enum" && len(t.Values) == 0:
+		return t, fmt.Errorf("enum %s has no values", t.Name)
+	}
+	r.useType(t.Name)
+	return t, nil
+}
+
+// fieldExpr parses a field's pseudo-type, optional when the field is.
+func fieldExpr(f specparser.SpecField) (*typeexpr.Expr, error) {
+	expr, err := typeexpr.Parse(fieldType(f))
+	if err != nil {
+		return nil, fmt.Errorf("no generator for %s", f.Type)
+	}
+	return expr, nil
+}
+
+// quickable reports why testing/quick cannot generate a Go type, if it
+// cannot.
+func (r *propertyRenderer) quickable(expr *typeexpr.Expr, depth int) error {
+	switch expr.Kind {
+	case typeexpr.Primitive:
+		switch expr.Name {
+		case typeexpr.String, typeexpr.Int, typeexpr.Int64, typeexpr.Float, typeexpr.Decimal,
+			typeexpr.Bool, typeexpr.Bytes, typeexpr.Duration, typeexpr.UUID:
+			return nil
+		}
+		return fmt.Errorf("testing/quick cannot generate %s", mapType(expr.Name, "go"))
+	case typeexpr.List, typeexpr.Set, typeexpr.Map, typeexpr.Optional:
+		for _, arg := range expr.Args {
+			if err := r.quickable(arg, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case typeexpr.Named:
+		t, err := r.specTypeOf(expr, depth)
+		if err != nil || t.Kind == "enum" {
+			return err
+		}
+		for _, f := range t.Fields {
+			fe, err := fieldExpr(f)
+			if err != nil {
+				return err
+			}
+			if err := r.quickable(fe, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("testing/quick cannot generate %s", expr.Render("go"))
+}
+
+// generator renders a fast-check arbitrary, hypothesis strategy, proptest
+// strategy or FsCheck generator.
+func (r *propertyRenderer) generator(expr *typeexpr.Expr, depth int) (string, error) {
+	args := make([]string, len(expr.Args))
+	if expr.Kind != typeexpr.Named {
+		for i, arg := range expr.Args {
+			s, err := r.generator(arg, depth+1)
+			if err != nil {
+				return "", err
+			}
+			args[i] = s
+		}
+	}
+	unsupported := fmt.Errorf("no %s generator for %s", r.lang, expr.Render(r.lang))
+
+	switch expr.Kind {
+	case typeexpr.Primitive:
+		if s, ok := primitiveGenerators[r.lang][expr.Name]; ok {
+			return s, nil
+		}
+		return "", unsupported
+
+	case typeexpr.List:
+		return fmt.Sprintf(map[string]string{
+			"typescript": "fc.array(%s)",
+			"python":     "st.lists(%s)",
+			"rust":       "prop::collection::vec(%s, 0..8)",
+			"csharp":     "Gen.ListOf(%s).Select(values => values.ToList())",
+		}[r.lang], args[0]), nil
+
+	case typeexpr.Set:
+		if r.lang == "rust" && r.namesSpecType(expr.Args[0].String()) {
+			return "", unsupported
+		}
+		return fmt.Sprintf(map[string]string{
+			"typescript": "fc.uniqueArray(%s).map((values) => new Set(values))",
+			"python":     "st.sets(%s)",
+			"rust":       "prop::collection::hash_set(%s, 0..8)",
+			"csharp":     "Gen.ListOf(%s).Select(values => values.ToHashSet())",
+		}[r.lang], args[0]), nil
+
+	case typeexpr.Map:
+		switch r.lang {
+		case "typescript":
+			if expr.Args[0].Kind != typeexpr.Primitive || expr.Args[0].Name != typeexpr.String {
+				return "", unsupported
+			}
+			return fmt.Sprintf("fc.dictionary(%s, %s)", args[0], args[1]), nil
+		case "python":
+			return fmt.Sprintf("st.dictionaries(%s, %s)", args[0], args[1]), nil
+		case "rust":
+			return fmt.Sprintf("prop::collection::hash_map(%s, %s, 0..8)", args[0], args[1]), nil
+		case "csharp":
+			return fmt.Sprintf("Gen.ListOf(from key in %s from value in %s select (key, value)).Select(pairs => pairs.GroupBy(p => p.key).ToDictionary(g => g.Key, g => g.First().value))", args[0], args[1]), nil
+		}
+
+	case typeexpr.Optional:
+		switch r.lang {
+		case "typescript":
+			return fmt.Sprintf("fc.option(%s, { nil: null })", args[0]), nil
+		case "python":
+			return fmt.Sprintf("st.none() | %s", args[0]), nil
+		case "rust":
+			return fmt.Sprintf("prop::option::of(%s)", args[0]), nil
+		case "csharp":
+			t := mapType(expr.String(), r.lang)
+			return fmt.Sprintf("Gen.OneOf(Gen.Constant((%s)null), %s.Select(value => (%s)value))", t, args[0], t), nil
+		}
+
+	case typeexpr.Named:
+		return r.namedGenerator(expr, depth)
+	}
+	return "", unsupported
+}
+
+// primitiveGenerators are the generators of primitive types by language.
+var primitiveGenerators = map[string]map[string]string{
+	"typescript": {
+		typeexpr.String: "fc.string()", typeexpr.Int: "fc.integer()", typeexpr.Int64: "fc.integer()",
+		typeexpr.Float: "fc.double({ noNaN: true, noDefaultInfinity: true })", typeexpr.Decimal: "fc.double({ noNaN: true, noDefaultInfinity: true })",
+		typeexpr.Bool: "fc.boolean()", typeexpr.Bytes: "fc.uint8Array()", typeexpr.Date: "fc.date()", typeexpr.DateTime: "fc.date()",
+		typeexpr.Duration: "fc.nat()", typeexpr.UUID: "fc.uuid()",
+	},
+	"python": {
+		typeexpr.String: "st.text()", typeexpr.Int: "st.integers()", typeexpr.Int64: "st.integers()",
+		typeexpr.Float: "st.floats(allow_nan=False, allow_infinity=False)", typeexpr.Decimal: "st.decimals(allow_nan=False, allow_infinity=False)",
+		typeexpr.Bool: "st.booleans()", typeexpr.Bytes: "st.binary()", typeexpr.Date: "st.datetimes()", typeexpr.DateTime: "st.datetimes()",
+		typeexpr.Duration: "st.timedeltas()", typeexpr.UUID: "st.uuids().map(str)",
+	},
+	"rust": {
+		typeexpr.String: "any::<String>()", typeexpr.Int: "any::<i32>()", typeexpr.Int64: "any::<i64>()",
+		typeexpr.Float: "-1.0e9..1.0e9f64", typeexpr.Decimal: "-1.0e9..1.0e9f64",
+		typeexpr.Bool: "any::<bool>()", typeexpr.Bytes: "prop::collection::vec(any::<u8>(), 0..32)",
+	},
+	"csharp": {
+		typeexpr.String: "Arb.Generate<NonNull<string>>().Select(s => s.Get)", typeexpr.Int: "Arb.Generate<int>()", typeexpr.Int64: "Arb.Generate<long>()",
+		typeexpr.Float: "Arb.Generate<NormalFloat>().Select(f => f.Get)", typeexpr.Decimal: "Arb.Generate<decimal>()",
+		typeexpr.Bool: "Arb.Generate<bool>()", typeexpr.Bytes: "Arb.Generate<byte[]>()", typeexpr.Date: "Arb.Generate<DateTime>()",
+		typeexpr.DateTime: "Arb.Generate<DateTime>()", typeexpr.Duration: "Arb.Generate<TimeSpan>()", typeexpr.UUID: "Arb.Generate<Guid>()",
+	},
+}
+
+// namedGenerator renders the generator of a spec enum or struct, building
+// structs field by field from their fields' generators.
+func (r *propertyRenderer) namedGenerator(expr *typeexpr.Expr, depth int) (string, error) {
+	t, err := r.specTypeOf(expr, depth)
+	if err != nil {
+		return "", err
+	}
+	name := typeName(t.Name)
+
+	if t.Kind == "enum" {
+		values := make([]string, len(t.Values))
+		for i, v := range t.Values {
+			values[i] = name + "." + valueIdent(r.lang, v.Name)
+			if r.lang == "rust" {
+				values[i] = "Just(" + name + "::" + valueIdent(r.lang, v.Name) + ")"
+			}
+		}
+		switch r.lang {
+		case "typescript":
+			return "fc.constantFrom(" + strings.Join(values, ", ") + ")", nil
+		case "python":
+			return "st.sampled_from(" + name + ")", nil
+		case "rust":
+			return "prop_oneof![" + strings.Join(values, ", ") + "]", nil
+		}
+		return "Arb.Generate<" + name + ">()", nil
+	}
+
+	// hypothesis builds models from their signatures
+	if r.lang == "python" {
+		return "st.builds(" + name + ")", nil
+	}
+
+	gens := make([]string, len(t.Fields))
+	for i, f := range t.Fields {
+		fe, err := fieldExpr(f)
+		if err != nil {
+			return "", err
+		}
+		if gens[i], err = r.generator(fe, depth+1); err != nil {
+			return "", err
+		}
+	}
+
+	var parts []string
+	switch r.lang {
+	case "typescript":
+		for i, f := range t.Fields {
+			parts = append(parts, fieldIdent(r.lang, f.Name)+": "+gens[i])
+		}
+		return "fc.record({ " + strings.Join(parts, ", ") + " })", nil
+
+	case "rust":
+		// proptest combines strategies in tuples of up to 12
+		if len(t.Fields) > 12 {
+			return "", fmt.Errorf("%s has too many fields to generate", name)
+		}
+		if len(t.Fields) == 0 {
+			return "Just(" + name + " {})", nil
+		}
+		var vars []string
+		for i, f := range t.Fields {
+			vars = append(vars, fmt.Sprintf("v%d", i))
+			parts = append(parts, fmt.Sprintf("%s: v%d", fieldIdent(r.lang, f.Name), i))
+		}
+		return fmt.Sprintf("(%s,).prop_map(|(%s,)| %s { %s })", strings.Join(gens, ", "), strings.Join(vars, ", "), name, strings.Join(parts, ", ")), nil
+
+	case "csharp":
+		if len(t.Fields) == 0 {
+			return "Gen.Constant(new " + name + "())", nil
+		}
+		var from []string
+		for i, f := range t.Fields {
+			from = append(from, fmt.Sprintf("from v%d in %s", i, gens[i]))
+			parts = append(parts, fmt.Sprintf("%s = v%d", fieldIdent(r.lang, f.Name), i))
+		}
+		return fmt.Sprintf("(%s select new %s { %s })", strings.Join(from, " "), name, strings.Join(parts, ", ")), nil
+	}
+	return "", fmt.Errorf("no %s generator for %s", r.lang, name)
+}
+
+// jqwikArbitraries are the jqwik arbitraries of primitive types.
+var jqwikArbitraries = map[string]string{
+	typeexpr.String:  "Arbitraries.strings()",
+	typeexpr.Int:     "Arbitraries.integers()",
+	typeexpr.Int64:   "Arbitraries.longs()",
+	typeexpr.Float:   "Arbitraries.doubles()",
+	typeexpr.Decimal: "Arbitraries.bigDecimals()",
+	typeexpr.Bool:    "Arbitraries.of(true, false)",
+	typeexpr.UUID:    "Arbitraries.create(UUID::randomUUID)",
+}
+
+// jqwik renders a jqwik arbitrary, reporting whether the type needs a
+// provider method; jqwik generates the other types by default.
+func (r *propertyRenderer) jqwik(expr *typeexpr.Expr, depth int) (string, bool, error) {
+	switch expr.Kind {
+	case typeexpr.Primitive:
+		if s, ok := jqwikArbitraries[expr.Name]; ok {
+			return s, expr.Name == typeexpr.UUID, nil
+		}
+
+	case typeexpr.List, typeexpr.Set, typeexpr.Optional:
+		inner, custom, err := r.jqwik(expr.Args[0], depth+1)
+		suffix := map[typeexpr.Kind]string{typeexpr.List: ".list()", typeexpr.Set: ".set()", typeexpr.Optional: ".injectNull(0.1)"}[expr.Kind]
+		return inner + suffix, custom, err
+
+	case typeexpr.Map:
+		key, customKey, err := r.jqwik(expr.Args[0], depth+1)
+		if err != nil {
+			return "", false, err
+		}
+		value, customValue, err := r.jqwik(expr.Args[1], depth+1)
+		return fmt.Sprintf("Arbitraries.maps(%s, %s)", key, value), customKey || customValue, err
+
+	case typeexpr.Named:
+		t, err := r.specTypeOf(expr, depth)
+		if err != nil {
+			return "", false, err
+		}
+		name := typeName(t.Name)
+		if t.Kind == "enum" {
+			return "Arbitraries.of(" + name + ".class)", false, nil
+		}
+
+		// Combinators combine up to 8 arbitraries
+		if len(t.Fields) > 8 {
+			return "", false, fmt.Errorf("%s has too many fields to generate", name)
+		}
+		if len(t.Fields) == 0 {
+			return "Arbitraries.create(" + name + "::new)", true, nil
+		}
+		gens := make([]string, len(t.Fields))
+		params := make([]string, len(t.Fields))
+		body := []string{fmt.Sprintf("%s value = new %s();", name, name)}
+		for i, f := range t.Fields {
+			fe, err := fieldExpr(f)
+			if err != nil {
+				return "", false, err
+			}
+			if gens[i], _, err = r.jqwik(fe, depth+1); err != nil {
+				return "", false, err
+			}
+			params[i] = fmt.Sprintf("v%d", i)
+			body = append(body, fmt.Sprintf("value.set%s(v%d);", toPascalCase(f.Name), i))
+		}
+		lambda := " -> { " + strings.Join(append(body, "return value;"), " ") + " }"
+		if len(t.Fields) == 1 {
+			return gens[0] + ".map(v0" + lambda + ")", true, nil
+		}
+		return fmt.Sprintf("Combinators.combine(%s).as((%s)%s)", strings.Join(gens, ", "), strings.Join(params, ", "), lambda), true, nil
+	}
+	return "", false, fmt.Errorf("no java generator for %s", expr.Render("java"))
+}
+
+// ============================================================================
+// Test files
+// ============================================================================
+
+// generateProperties generates a property-based test for each spec
+// property with the ecosystem's library: testing/quick in Go, fast-check in
+// TypeScript, hypothesis in Python, jqwik in Java, proptest in Rust and
+// FsCheck in C#. Inputs are generated from the pseudo-types of the
+// parameters they are passed to. Properties a language cannot check are
+// listed at the top of its file instead.
+func (g *Generator) generateProperties(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	if len(spec.Properties) == 0 {
+		return nil
+	}
+
+	lang := adapter.GetLanguage()
+	pkg := toPackageName(spec.Name)
+
+	var names, skipped, types []string
+	var functions []specparser.SpecFunction
+	helpers := make(map[string]bool)
+	var body strings.Builder
+	for i, p := range spec.Properties {
+		label := p.Expression
+		if label == "" {
+			label = p.Description
+		}
+		name := propertyName(p, i)
+		for n := 2; containsString(names, name); n++ {
+			name = propertyName(p, i) + strconv.Itoa(n)
+		}
+
+		scope, err := resolveProperty(spec, p)
+		var view propertyView
+		if err == nil {
+			r := newPropertyRenderer(scope, lang.ID)
+			if view, err = r.view(p, name); err == nil {
+				for _, f := range r.functions {
+					if !containsFunction(functions, f.Name) {
+						functions = append(functions, f)
+					}
+				}
+				for _, t := range r.used {
+					if !containsString(types, t) {
+						types = append(types, t)
+					}
+				}
+				for h := range r.helpers {
+					helpers[h] = true
+				}
+			}
+		}
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+
+		names = append(names, name)
+		code := g.render(lang.ID, "property", view)
+		body.WriteString("\n")
+		body.WriteString(wrapRegion(lang.ID, "property", name, hashOf(p), code))
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	code := body.String()
+
+	var notes string
+	if len(skipped) > 0 {
+		prefix := langCommentPrefix(lang.ID)
+		notes = fmt.Sprintf("\n%s Properties not checked in %s:\n", prefix, lang.Name)
+		for _, s := range skipped {
+			notes += fmt.Sprintf("%s   - %s\n", prefix, s)
+		}
+	}
+
+	// The functions called and types generated, grouped by the module
+	// declaring them
+	module := func(name string) string { return typeModule(spec, name) }
+	funcModules := make(map[string]string)
+	var idents []string
+	for _, f := range functions {
+		ident := moduleFuncIdent(lang.ID, f)
+		funcModules[ident] = f.Module
+		idents = append(idents, ident)
+	}
+	var foreign []moduleNames
+	for _, group := range byModule(types, module) {
+		if group.Module != "" {
+			foreign = append(foreign, group)
+		}
+	}
+
+	var sb strings.Builder
+	var path string
+	switch lang.ID {
+	case "go":
+		path = "properties_test.go"
+		sb.WriteString(fmt.Sprintf("package %s\n\nimport (\n", pkg))
+		if helpers["reflect.DeepEqual"] {
+			sb.WriteString("\t\"reflect\"\n")
+		}
+		sb.WriteString("\t\"testing\"\n\t\"testing/quick\"\n)\n")
+		sb.WriteString(notes)
+		sb.WriteString(code)
+
+		// Calls to other modules' packages are qualified like their types
+		all := append(append([]string(nil), types...), idents...)
+		foreign = nil
+		for _, group := range byModule(all, func(name string) string {
+			if m, ok := funcModules[name]; ok {
+				return m
+			}
+			return module(name)
+		}) {
+			if group.Module != "" {
+				foreign = append(foreign, group)
+			}
+		}
+
+	case "typescript":
+		path = "src/properties.test.ts"
+		sb.WriteString("import { describe, it } from \"vitest\";\nimport fc from \"fast-check\";\n")
+		if helpers["isDeepStrictEqual"] {
+			sb.WriteString("import { isDeepStrictEqual } from \"node:util\";\n")
+		}
+		for _, group := range byModule(idents, func(name string) string { return funcModules[name] }) {
+			sb.WriteString(fmt.Sprintf("import { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath(path, group.Module, "service")))
+		}
+		// Only enums are referenced at runtime
+		var enums []string
+		for _, name := range types {
+			if t, ok := findType(spec.Types, name); ok && t.Kind == "enum" {
+				enums = append(enums, name)
+			}
+		}
+		sb.WriteString(moduleImports(spec, lang.ID, path, byModule(enums, module)))
+		sb.WriteString(notes)
+		sb.WriteString("\ndescribe(\"properties\", () => {")
+		sb.WriteString(code)
+		sb.WriteString("});\n")
+
+	case "python":
+		path = "tests/test_properties.py"
+		if helpers["asyncio"] {
+			sb.WriteString("import asyncio\n\n")
+		}
+		sb.WriteString("from hypothesis import given, strategies as st\n\n")
+		for _, group := range byModule(idents, func(name string) string { return funcModules[name] }) {
+			sb.WriteString(fmt.Sprintf("from %s import %s\n", pythonModulePath(group.Module, "service"), strings.Join(group.Names, ", ")))
+		}
+		sb.WriteString(moduleImports(spec, lang.ID, "", byModule(types, module)))
+		sb.WriteString(notes)
+		sb.WriteString(strings.ReplaceAll(code, "\n# rpg:begin", "\n\n# rpg:begin"))
+
+	case "java":
+		path = fmt.Sprintf("src/test/java/%s/PropertiesTest.java", pkg)
+		sb.WriteString(fmt.Sprintf("package %s;\n\n", pkg))
+		sb.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		if helpers["sameValue"] {
+			sb.WriteString("import com.fasterxml.jackson.databind.ObjectMapper;\n")
+		}
+		if strings.Contains(code, "BigDecimal") {
+			sb.WriteString("import java.math.BigDecimal;\n")
+		}
+		sb.WriteString("import java.util.*;\nimport net.jqwik.api.*;\n")
+		sb.WriteString(notes)
+		sb.WriteString("\n/** Property-based tests of the spec's invariants. */\nclass PropertiesTest {\n")
+		if helpers["sameValue"] {
+			sb.WriteString(`    private static final ObjectMapper MAPPER = new ObjectMapper();
+
+    /** Compares values by their JSON form, since generated classes do not override equals. */
+    private static boolean sameValue(Object a, Object b) {
+        return MAPPER.valueToTree(a).equals(MAPPER.valueToTree(b));
+    }
+`)
+		}
+		sb.WriteString(code)
+		sb.WriteString("}\n")
+
+	case "rust":
+		path = "tests/properties.rs"
+		sb.WriteString("use proptest::prelude::*;\n")
+		if helpers["same_value"] {
+			sb.WriteString("use serde::Serialize;\n")
+		}
+		for _, group := range byModule(types, module) {
+			sb.WriteString(fmt.Sprintf("use %s::{%s};\n", rustModulePath(pkg, group.Module, "types"), strings.Join(group.Names, ", ")))
+		}
+		sb.WriteString(notes)
+		if helpers["same_value"] {
+			sb.WriteString(`
+/// Compares values by their JSON form, since generated types do not derive
+/// PartialEq.
+fn same_value<T: Serialize>(a: &T, b: &T) -> bool {
+    serde_json::to_value(a).unwrap() == serde_json::to_value(b).unwrap()
+}
+`)
+		}
+		sb.WriteString(code)
+
+	case "csharp":
+		path = "tests/PropertiesTests.cs"
+		sb.WriteString("using System.Linq;\n")
+		if helpers["SameValue"] {
+			sb.WriteString("using System.Text.Json;\n")
+		}
+		sb.WriteString("using FsCheck;\nusing FsCheck.Xunit;\n")
+		sb.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		sb.WriteString(notes)
+		sb.WriteString(fmt.Sprintf("\nnamespace %s.Tests\n{\n", toPascalCase(spec.Name)))
+		sb.WriteString("    /// <summary>\n    /// Property-based tests of the spec's invariants.\n    /// </summary>\n    public class PropertiesTests\n    {")
+		if helpers["SameValue"] {
+			sb.WriteString(`
+        /// <summary>
+        /// Compares values by their JSON form, since generated classes use
+        /// reference equality.
+        /// </summary>
+        private static bool SameValue<T>(T a, T b) =>
+            JsonSerializer.Serialize(a) == JsonSerializer.Serialize(b);
+`)
+		}
+		sb.WriteString(code)
+		sb.WriteString("    }\n}\n")
+
+	default:
+		return nil
+	}
+
+	content := sb.String()
+	if lang.ID == "go" {
+		content = qualifyGo(spec, content, foreign)
+	}
+	return []GeneratedFile{{Path: path, Content: content, Category: "test", Elements: names}}
+}
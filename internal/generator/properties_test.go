@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const codecSpec = "# Codec\n\n" +
+	"## Types\n\n" +
+	"### Note (struct)\n\n" +
+	"- title: string - Title\n" +
+	"- tags: List<string> - Tags\n\n" +
+	"## Functions\n\n" +
+	"### Encode\n\n**Parameters**\n- `text`: `string`\n\n**Returns** `bytes`\n\n" +
+	"### Decode\n\n**Parameters**\n- `data`: `bytes`\n\n**Returns** `string`\n\n" +
+	"### Archive\n\n**Parameters**\n- `note`: `Note`\n\n**Returns** `Note`\n\n" +
+	"## Properties\n\n" +
+	"- `Decode(Encode(x)) == x` - Decoding reverses encoding\n" +
+	"- `Archive(n) == Archive(n)` - Archiving is deterministic\n" +
+	"- `y > 1`\n\n" +
+	"### Archive\n\n" +
+	"- `len(result.tags) <= len(note.tags)` - Archiving drops no tags\n"
+
+func TestResolveProperty(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(codecSpec, "codec.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		property specparser.SpecProperty
+		inputs   []string
+		err      string
+	}{
+		{specparser.SpecProperty{Expression: "Decode(Encode(x)) == x"}, []string{"x"}, ""},
+		{specparser.SpecProperty{Expression: "len(result) > 0", Function: "Encode"}, []string{"text"}, ""},
+		{specparser.SpecProperty{Expression: "Archive(n).title == n.title"}, []string{"n"}, ""},
+		{specparser.SpecProperty{Description: "Notes stay readable"}, nil, "prose"},
+		{specparser.SpecProperty{Expression: "y > 1"}, nil, "cannot tell the type of y"},
+		{specparser.SpecProperty{Expression: "Compress(x) == x"}, nil, "unknown function Compress"},
+		{specparser.SpecProperty{Expression: "Encode(x, y) == x"}, nil, "takes 1 arguments"},
+		{specparser.SpecProperty{Expression: "Archive(n).body == n.title"}, nil, "no field body"},
+		{specparser.SpecProperty{Expression: "Decode(x)"}, nil, "not a condition"},
+	}
+
+	for _, tt := range tests {
+		scope, err := resolveProperty(spec, tt.property)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected %+v to fail with %q, got %v", tt.property, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %+v: %v", tt.property, err)
+			continue
+		}
+		if strings.Join(scope.inputs, ",") != strings.Join(tt.inputs, ",") {
+			t.Errorf("Expected inputs %v for %+v, got %v", tt.inputs, tt.property, scope.inputs)
+		}
+	}
+}
+
+func TestGenerateProperties(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(codecSpec, "codec.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		files    map[string][]string
+	}{
+		{"go", map[string][]string{
+			"properties_test.go": {
+				"\"testing/quick\"",
+				"//   - y > 1: cannot tell the type of y; pass it to a function",
+				"property := func(x string) bool {\n\t\tv1 := Encode(x)\n\t\tv2 := Decode(v1)\n\t\treturn v2 == x\n\t}",
+				"return reflect.DeepEqual(v1, v2)",
+				"result := Archive(note)\n\t\treturn len(result.Tags) <= len(note.Tags)",
+			},
+		}},
+		{"typescript", map[string][]string{
+			"src/properties.test.ts": {
+				"import { decode, encode, archive } from \"./service\";",
+				"fc.property(fc.string(), (x) => {\n        return decode(encode(x)) === x;",
+				"fc.record({ title: fc.string(), tags: fc.array(fc.string()) })",
+				"isDeepStrictEqual(archive(n), archive(n))",
+			},
+			"package.json": {"\"fast-check\""},
+		}},
+		{"python", map[string][]string{
+			"tests/test_properties.py": {"from hypothesis import given, strategies as st", "@given(x=st.text())\ndef test_decoding_reverses_encoding(x):", "@given(n=st.builds(Note))", "assert len(result.tags) <= len(note.tags)"},
+		}},
+		{"java", map[string][]string{
+			"src/test/java/codec/PropertiesTest.java": {
+				"boolean decodingReversesEncoding(@ForAll String x) throws Exception {\n        return Objects.equals(Service.decode(Service.encode(x)), x);",
+				"return sameValue(Service.archive(n), Service.archive(n));",
+				"Combinators.combine(Arbitraries.strings(), Arbitraries.strings().list()).as((v0, v1) -> { Note value = new Note(); value.setTitle(v0); value.setTags(v1); return value; })",
+			},
+			"pom.xml": {"<artifactId>jqwik</artifactId>"},
+		}},
+		{"rust", map[string][]string{
+			"tests/properties.rs": {
+				"use codec::types::{Note};",
+				"fn decoding_reverses_encoding(x in any::<String>()) {\n        prop_assert!(codec::service::decode(codec::service::encode(x.clone())) == x);",
+				"prop_assert!(same_value(&codec::service::archive(n.clone()), &codec::service::archive(n.clone())));",
+			},
+			"Cargo.toml": {"proptest"},
+		}},
+		{"csharp", map[string][]string{
+			"tests/PropertiesTests.cs": {
+				"return Prop.ForAll(Arb.From(Arb.Generate<NonNull<string>>().Select(s => s.Get)), x =>",
+				"select new Note { Title = v0, Tags = v1 }",
+				"return SameValue(Service.Archive(n), Service.Archive(n));",
+			},
+			"Codec.csproj": {"\"FsCheck.Xunit\""},
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), tt.files)
+		})
+	}
+}
+
+func TestPropertiesBuild(t *testing.T) {
+	checkBuilds(t, codecSpec, "go", "python")
+}
@@ -0,0 +1,297 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// snapshotDir holds the last generated version of every file, relative to
+// the output directory. It is the common base for three-way merges.
+const snapshotDir = ".rpg/base"
+
+// Regeneration file statuses.
+const (
+	StatusCreated   = "created"
+	StatusUpdated   = "updated"
+	StatusUnchanged = "unchanged"
+	StatusConflict  = "conflict"
+)
+
+// Regenerate regenerates code for a spec into an existing output directory
+// without clobbering hand-written code. Only elements whose spec definition
+// hash changed are rewritten; the result is three-way merged with the user's
+// copy against the last generated version, and overlapping edits are
+// reported as conflicts with the user's version kept in place.
+func (g *Generator) Regenerate(spec *specparser.SpecAnalysis, language, outputDir string) (*RegenerationResult, error) {
+	adapter, err := g.registry.Get(language)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported language %s: %w", language, err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Names that clash once converted would generate code that does not compile
+	if err := identifierCollisions(spec, adapter.GetLanguage().ID); err != nil {
+		return nil, err
+	}
+
+	files := g.build(spec, adapter)
+	if g.renderErr != nil {
+		return nil, g.renderErr
+	}
+
+	// Merge only code that parses, so a broken file never reaches the
+	// user's copy
+	for i, f := range files {
+		if len(f.Diagnostics) == 0 {
+			files[i].Diagnostics = checkSyntax(f.Path, f.Content)
+		}
+	}
+	if err := syntaxError(files); err != nil {
+		return nil, err
+	}
+
+	result := &RegenerationResult{OutputDir: outputDir}
+	for _, f := range files {
+		file, conflicts, err := regenerateFile(outputDir, f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to regenerate %s: %w", f.Path, err)
+		}
+		result.Files = append(result.Files, file)
+		result.Conflicts = append(result.Conflicts, conflicts...)
+	}
+
+	return result, nil
+}
+
+// regenerateFile merges a freshly generated file into the output directory.
+func regenerateFile(outputDir string, f GeneratedFile) (RegeneratedFile, []MergeConflict, error) {
+	fullPath := filepath.Join(outputDir, f.Path)
+	file := RegeneratedFile{Path: f.Path}
+
+	current, err := os.ReadFile(fullPath)
+	if os.IsNotExist(err) {
+		if err := writeGenerated(outputDir, f.Path, f.Content, f.Content); err != nil {
+			return file, nil, err
+		}
+		file.Status = StatusCreated
+		file.ChangedElements = regionIDs(f.Content)
+		return file, nil, nil
+	}
+	if err != nil {
+		return file, nil, err
+	}
+
+	ours := string(current)
+	base, hasBase := readSnapshot(outputDir, f.Path)
+	if hasBase {
+		file.UserModified = ours != base
+	} else {
+		file.UserModified = !isUnmodified(ours)
+	}
+
+	if !hasBase {
+		if !isUnmodified(ours) && ours != f.Content {
+			// Without the last generated version there is no way to tell
+			// hand-written code from generated code, so leave the file alone.
+			file.Status = StatusConflict
+			return file, []MergeConflict{{
+				Path:    f.Path,
+				Ours:    ours,
+				Theirs:  f.Content,
+				Message: "no base snapshot found and the file was modified; kept local version",
+			}}, nil
+		}
+		base = ours
+	}
+
+	theirs, changed := selectRegions(f.Path, f.Content, ours, base)
+	file.ChangedElements = changed
+	file.RemovedElements = removedRegions(base, theirs)
+
+	merged := merge3(splitLines(base), splitLines(ours), splitLines(theirs))
+
+	// The snapshot is always pure generated code so later merges can tell
+	// hand-written edits apart from generated text
+	snapshot := f.Content
+
+	var conflicts []MergeConflict
+	if len(merged.Conflicts) > 0 {
+		conflicts, snapshot = resolveConflicts(f.Path, &merged, ours, base, f.Content)
+	}
+
+	content := joinLines(merged.Lines)
+	switch {
+	case len(conflicts) > 0:
+		file.Status = StatusConflict
+	case content == ours:
+		file.Status = StatusUnchanged
+	default:
+		file.Status = StatusUpdated
+	}
+
+	if err := writeGenerated(outputDir, f.Path, content, snapshot); err != nil {
+		return file, nil, err
+	}
+
+	return file, conflicts, nil
+}
+
+// selectRegions builds the regeneration target: the newly generated file in
+// which every element whose spec hash is unchanged is replaced by its current
+// (or last generated) text, so only changed elements differ from the base.
+// It returns the target content and the IDs of the changed elements.
+func selectRegions(path, generated, ours, base string) (string, []string) {
+	lines := splitLines(generated)
+	oursLines, baseLines := splitLines(ours), splitLines(base)
+	oursRegions, baseRegions := parseRegions(oursLines), parseRegions(baseLines)
+
+	var changed []string
+	regions := orderedRegions(parseRegions(lines))
+
+	// Splice bottom-up so earlier region indices stay valid
+	for i := len(regions) - 1; i >= 0; i-- {
+		r := regions[i]
+		if o, ok := oursRegions[r.ID]; ok && o.Hash == r.Hash {
+			lines = splice(lines, r, oursLines[o.Start:o.End+1])
+		} else if b, ok := baseRegions[r.ID]; ok && b.Hash == r.Hash {
+			lines = splice(lines, r, baseLines[b.Start:b.End+1])
+		} else {
+			changed = append([]string{r.ID}, changed...)
+		}
+	}
+
+	content := joinLines(lines)
+	if _, body, ok := splitHeader(content); ok {
+		content = stampHeader(path, body)
+	}
+	return content, changed
+}
+
+// resolveConflicts turns merge hunks into reported conflicts. Conflicting
+// regions keep their previous spec hash so they are reported again on the
+// next run until resolved, and the snapshot keeps their previous base text.
+func resolveConflicts(path string, merged *mergeResult, ours, base, generated string) ([]MergeConflict, string) {
+	oursRegions := parseRegions(splitLines(ours))
+	baseLines := splitLines(base)
+	baseRegions := parseRegions(baseLines)
+	generatedLines := splitLines(generated)
+	generatedRegions := parseRegions(generatedLines)
+	mergedRegions := orderedRegions(parseRegions(merged.Lines))
+
+	var conflicts []MergeConflict
+	conflicted := make(map[string]bool)
+
+	for _, hunk := range merged.Conflicts {
+		conflict := MergeConflict{
+			Path:   path,
+			Base:   joinLines(hunk.Base),
+			Ours:   joinLines(hunk.Ours),
+			Theirs: joinLines(hunk.Theirs),
+		}
+
+		if r, ok := enclosingRegion(mergedRegions, hunk); ok {
+			conflict.Element = r.ID
+			conflict.SpecHash = generatedRegions[r.ID].Hash
+			conflict.Message = fmt.Sprintf("local edits overlap regenerated code for %s; kept local version. "+
+				"Merge the regenerated code by hand, then set the region hash to %s to accept it", r.ID, conflict.SpecHash)
+
+			if o, ok := oursRegions[r.ID]; ok && !conflicted[r.ID] {
+				merged.Lines[r.Start] = setRegionHash(merged.Lines[r.Start], o.Hash)
+			}
+			conflicted[r.ID] = true
+		} else {
+			conflict.Message = "local edits overlap regenerated code outside protected regions; kept local version"
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	// Keep the previous base text for conflicting regions in the snapshot
+	regions := orderedRegions(generatedRegions)
+	for i := len(regions) - 1; i >= 0; i-- {
+		r := regions[i]
+		if b, ok := baseRegions[r.ID]; ok && conflicted[r.ID] {
+			generatedLines = splice(generatedLines, r, baseLines[b.Start:b.End+1])
+		}
+	}
+
+	return conflicts, joinLines(generatedLines)
+}
+
+// enclosingRegion returns the region containing a conflict hunk, if any.
+func enclosingRegion(regions []region, hunk mergeHunk) (region, bool) {
+	for _, r := range regions {
+		if hunk.Start >= r.Start && hunk.Start <= r.End {
+			return r, true
+		}
+	}
+	return region{}, false
+}
+
+// removedRegions returns IDs of regions in base that no longer exist in the
+// regeneration target.
+func removedRegions(base, target string) []string {
+	targetRegions := parseRegions(splitLines(target))
+	var removed []string
+	for _, r := range orderedRegions(parseRegions(splitLines(base))) {
+		if _, ok := targetRegions[r.ID]; !ok {
+			removed = append(removed, r.ID)
+		}
+	}
+	return removed
+}
+
+// regionIDs returns the IDs of all regions in content, in file order.
+func regionIDs(content string) []string {
+	var ids []string
+	for _, r := range orderedRegions(parseRegions(splitLines(content))) {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+// splice replaces a region's lines (markers included) with replacement.
+func splice(lines []string, r region, replacement []string) []string {
+	result := make([]string, 0, len(lines)-(r.End-r.Start+1)+len(replacement))
+	result = append(result, lines[:r.Start]...)
+	result = append(result, replacement...)
+	result = append(result, lines[r.End+1:]...)
+	return result
+}
+
+// writeGenerated writes a file and records its generated version as the
+// base for future merges.
+func writeGenerated(outputDir, path, content, snapshot string) error {
+	fullPath := filepath.Join(outputDir, path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		return err
+	}
+	return writeSnapshot(outputDir, path, snapshot)
+}
+
+// writeSnapshot stores the last generated version of a file.
+func writeSnapshot(outputDir, path, content string) error {
+	fullPath := filepath.Join(outputDir, snapshotDir, path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(fullPath, []byte(content), 0644)
+}
+
+// readSnapshot returns the last generated version of a file, if recorded.
+func readSnapshot(outputDir, path string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(outputDir, snapshotDir, path))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
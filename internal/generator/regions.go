@@ -0,0 +1,219 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Marker keywords used in generated files. A generated file starts with a
+// header line carrying a hash of its generated content, and every spec
+// element is wrapped in a protected region whose begin marker carries a hash
+// of the element's spec definition:
+//
+//	// rpg:generated hash=3f2a9c1b7e40
+//	// rpg:begin type:User hash=9b1c2d3e4f50
+//	...
+//	// rpg:end type:User
+const (
+	headerMarker = "rpg:generated"
+	beginMarker  = "rpg:begin"
+	endMarker    = "rpg:end"
+	hashPrefix   = "hash="
+)
+
+// region is a protected element region within a generated file.
+type region struct {
+	// ID identifies the element ("<category>:<name>")
+	ID string
+
+	// Hash is the spec definition hash recorded in the begin marker
+	Hash string
+
+	// Start and End are the line indices of the begin and end markers
+	Start int
+	End   int
+}
+
+// hashOf returns a short, stable hash of a value's JSON encoding.
+func hashOf(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", v))
+	}
+	return hashString(string(data))
+}
+
+// hashString returns a short hash of a string.
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:6])
+}
+
+// commentPrefix returns the line comment prefix for a generated file, or an
+// empty string when the format has no comment syntax (e.g. JSON).
+func commentPrefix(path string) string {
+	if strings.HasPrefix(filepath.Base(path), ".env") {
+		return "#"
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go", ".ts", ".java", ".rs", ".cs", ".mod":
+		return "//"
+	case ".py", ".toml":
+		return "#"
+	default:
+		return ""
+	}
+}
+
+// langCommentPrefix returns the line comment prefix for a language ID.
+func langCommentPrefix(langID string) string {
+	if langID == "python" {
+		return "#"
+	}
+	return "//"
+}
+
+// wrapRegion wraps element code in begin/end markers. The markers share the
+// indentation of the element's first line so they sit naturally inside
+// classes and modules.
+func wrapRegion(langID, category, name, hash, code string) string {
+	prefix := langCommentPrefix(langID)
+	indent := leadingWhitespace(firstNonEmptyLine(code))
+	id := category + ":" + name
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s%s %s %s %s%s\n", indent, prefix, beginMarker, id, hashPrefix, hash))
+	sb.WriteString(code)
+	if !strings.HasSuffix(code, "\n") {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("%s%s %s %s\n", indent, prefix, endMarker, id))
+	return sb.String()
+}
+
+// stampHeader prepends the header line with a hash of the content. Content
+// for formats without comment syntax is returned unchanged.
+func stampHeader(path, content string) string {
+	prefix := commentPrefix(path)
+	if prefix == "" {
+		return content
+	}
+	return fmt.Sprintf("%s %s %s%s\n", prefix, headerMarker, hashPrefix, hashString(content)) + content
+}
+
+// splitHeader separates the header line from the rest of the content and
+// returns the recorded hash. ok is false when the content has no header.
+func splitHeader(content string) (hash, body string, ok bool) {
+	line, rest, found := strings.Cut(content, "\n")
+	if !found {
+		return "", content, false
+	}
+	idx := strings.Index(line, headerMarker+" "+hashPrefix)
+	if idx < 0 {
+		return "", content, false
+	}
+	return strings.TrimSpace(line[idx+len(headerMarker)+1+len(hashPrefix):]), rest, true
+}
+
+// isUnmodified reports whether content still matches the hash recorded in its
+// header, i.e. nobody has edited the file since it was generated.
+func isUnmodified(content string) bool {
+	hash, body, ok := splitHeader(content)
+	return ok && hash == hashString(body)
+}
+
+// parseRegions finds the protected regions in a file's lines, keyed by
+// element ID. Unterminated or nested regions are ignored.
+func parseRegions(lines []string) map[string]region {
+	regions := make(map[string]region)
+	var open *region
+
+	for i, line := range lines {
+		if id, hash, ok := parseBeginMarker(line); ok {
+			open = &region{ID: id, Hash: hash, Start: i}
+			continue
+		}
+		if id, ok := parseEndMarker(line); ok && open != nil && id == open.ID {
+			open.End = i
+			regions[id] = *open
+			open = nil
+		}
+	}
+
+	return regions
+}
+
+// orderedRegions returns regions sorted by position in the file.
+func orderedRegions(regions map[string]region) []region {
+	result := make([]region, 0, len(regions))
+	for _, r := range regions {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start < result[j].Start
+	})
+	return result
+}
+
+// parseBeginMarker extracts the element ID and hash from a begin marker line.
+func parseBeginMarker(line string) (id, hash string, ok bool) {
+	rest, found := markerPayload(line, beginMarker)
+	if !found {
+		return "", "", false
+	}
+	idx := strings.LastIndex(rest, " "+hashPrefix)
+	if idx < 0 {
+		return strings.TrimSpace(rest), "", true
+	}
+	return strings.TrimSpace(rest[:idx]), strings.TrimSpace(rest[idx+len(hashPrefix)+1:]), true
+}
+
+// parseEndMarker extracts the element ID from an end marker line.
+func parseEndMarker(line string) (id string, ok bool) {
+	rest, found := markerPayload(line, endMarker)
+	if !found {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// markerPayload returns the text after a marker keyword on a comment line.
+func markerPayload(line, marker string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "#"} {
+		if after, ok := strings.CutPrefix(trimmed, prefix); ok {
+			after = strings.TrimSpace(after)
+			if payload, ok := strings.CutPrefix(after, marker+" "); ok {
+				return payload, true
+			}
+		}
+	}
+	return "", false
+}
+
+// setRegionHash rewrites the hash on a begin marker line.
+func setRegionHash(line, hash string) string {
+	idx := strings.LastIndex(line, " "+hashPrefix)
+	if idx < 0 {
+		return line + " " + hashPrefix + hash
+	}
+	return line[:idx] + " " + hashPrefix + hash
+}
+
+func firstNonEmptyLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func leadingWhitespace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
@@ -0,0 +1,1070 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/layout"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// route is an HTTP endpoint resolved against the spec's functions.
+type route struct {
+	Endpoint specparser.SpecEndpoint
+
+	// Name is the handler's base name (e.g., "getTasksById")
+	Name string
+
+	// Function is the spec function the handler calls, nil when none matches
+	Function *specparser.SpecFunction
+
+	// Args binds each function parameter to a request input; nil when some
+	// parameter has no matching input, leaving the handler a stub
+	Args []routeArg
+
+	// Status is the success status code
+	Status string
+
+	// Service is how Java, Rust and C# handlers refer to the service holding
+	// Function, qualified when it is declared in another module
+	Service string
+}
+
+// routeArg binds a function parameter to a request input.
+type routeArg struct {
+	Source string // path, query, header or body
+	Name   string // name of the input in the request
+	Param  string // name of the function parameter
+	Type   string // pseudo-type of the function parameter
+
+	// Required is set when the request must carry the input
+	Required bool
+
+	// Default is the spec's default for an input the request leaves out
+	Default string
+}
+
+// defaulted reports whether the function gets a default or zero value when
+// the request leaves the input out, rather than the request failing.
+func (a routeArg) defaulted() bool {
+	_, optional := optionalInner(a.Type)
+	return !a.Required && !optional
+}
+
+// defaultLiteral returns the literal of the input's default value in a
+// language, if it has one.
+func (a routeArg) defaultLiteral(lang string) (string, bool) {
+	inner, _ := optionalInner(a.Type)
+	return defaultLiteral(lang, specparser.SpecField{Type: inner, Default: a.Default})
+}
+
+// bound reports whether the handler can call its function.
+func (r route) bound() bool {
+	return r.Function != nil && r.Args != nil
+}
+
+// resolveRoutes resolves each endpoint's handler name, function and argument
+// bindings. Functions are matched by the endpoint's Function, then by its
+// operation name, ignoring case and separators.
+func resolveRoutes(spec *specparser.SpecAnalysis) []route {
+	functions := make(map[string]*specparser.SpecFunction)
+	for i := range spec.Functions {
+		functions[normalizeName(spec.Functions[i].Name)] = &spec.Functions[i]
+	}
+
+	var routes []route
+	for _, e := range spec.Endpoints {
+		r := route{Endpoint: e, Name: e.Name, Status: "200"}
+		if r.Name == "" {
+			r.Name = routeName(e.Method, e.Path)
+		}
+		for _, resp := range e.Responses {
+			if strings.HasPrefix(resp.Status, "2") {
+				r.Status = resp.Status
+				break
+			}
+		}
+
+		for _, candidate := range []string{e.Function, r.Name} {
+			if f, ok := functions[normalizeName(candidate)]; ok && candidate != "" {
+				r.Function = f
+				break
+			}
+		}
+		if r.Function != nil {
+			r.Args = bindArgs(e, r.Function)
+		}
+
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// bindArgs matches function parameters to path, query and header inputs by
+// name; the remaining parameter takes the request body. It returns nil when a
+// parameter cannot be bound.
+func bindArgs(e specparser.SpecEndpoint, f *specparser.SpecFunction) []routeArg {
+	args := []routeArg{}
+	bodyUsed := false
+
+	for _, p := range f.Parameters {
+		arg := routeArg{Param: p.Name, Type: p.Type}
+		for _, group := range []struct {
+			source string
+			params []specparser.SpecParameter
+		}{
+			{"path", endpointPathParams(e)},
+			{"query", e.QueryParams},
+			{"header", e.HeaderParams},
+		} {
+			for _, input := range group.params {
+				if normalizeName(input.Name) == normalizeName(p.Name) {
+					arg.Source, arg.Name = group.source, input.Name
+					// A path parameter is always part of the URL
+					arg.Required = input.Required || group.source == "path"
+					arg.Default = input.Default
+				}
+			}
+			if arg.Source != "" {
+				break
+			}
+		}
+
+		if arg.Source == "" {
+			if e.RequestType == "" || bodyUsed {
+				return nil
+			}
+			arg.Source, arg.Name, arg.Required = "body", "body", true
+			bodyUsed = true
+		} else if !isScalarType(p.Type) {
+			// Only scalars can be parsed from path, query and header strings
+			return nil
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// endpointPathParams returns the declared path parameters, falling back to
+// those of the path template.
+func endpointPathParams(e specparser.SpecEndpoint) []specparser.SpecParameter {
+	if len(e.PathParams) > 0 {
+		return e.PathParams
+	}
+	var params []specparser.SpecParameter
+	for _, name := range pathParamNames(e.Path) {
+		params = append(params, specparser.SpecParameter{Name: name, Type: "string", Required: true})
+	}
+	return params
+}
+
+// generateRoutes generates HTTP routing and one handler stub per endpoint
+// for the language's conventional web framework: net/http for Go, Express
+// for TypeScript, FastAPI for Python, Spring for Java, axum for Rust and
+// ASP.NET Core minimal APIs for C#.
+func (g *Generator) generateRoutes(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	if len(spec.Endpoints) == 0 {
+		return nil
+	}
+
+	lang := adapter.GetLanguage()
+	routes := resolveRoutes(spec)
+	for i, r := range routes {
+		if r.Function != nil {
+			routes[i].Service = serviceRef(spec, lang.ID, r.Function.Module)
+		}
+	}
+
+	regions := make([]string, len(routes))
+	var handlers strings.Builder
+	for i, r := range routes {
+		code := g.generateHandler(r, lang)
+		regions[i] = wrapRegion(lang.ID, "endpoint", r.Name, hashOf(r.Endpoint), code) + "\n"
+		handlers.WriteString(regions[i])
+	}
+
+	var content strings.Builder
+	var filePath string
+	switch lang.ID {
+	case "go":
+		return goRouteFiles(spec, routes, regions)
+
+	case "typescript":
+		filePath = "src/routes.ts"
+		content.WriteString("import { Router, Request, Response } from \"express\";\n")
+		for _, group := range byModule(routeTypeNames(routes, spec), func(name string) string { return typeModule(spec, name) }) {
+			content.WriteString(fmt.Sprintf("import type { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath(filePath, group.Module, "types")))
+		}
+		for _, group := range routeFunctionImports(routes, "typescript") {
+			content.WriteString(fmt.Sprintf("import { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath(filePath, group.Module, "service")))
+		}
+		content.WriteString("\nexport const router = Router();\n\n")
+		for _, r := range routes {
+			content.WriteString(fmt.Sprintf("router.%s(%q, %s);\n", strings.ToLower(r.Endpoint.Method), formatPath(r.Endpoint.Path, ":", ""), toCamelCase(handlerName(r))))
+		}
+		content.WriteString("\n")
+		content.WriteString(handlers.String())
+
+	case "python":
+		filePath = "src/routes.py"
+		content.WriteString("from typing import Optional, List, Dict, Any\n\n")
+		content.WriteString("from fastapi import APIRouter, Header, HTTPException, Query\n\n")
+		for _, group := range byModule(routeTypeNames(routes, spec), func(name string) string { return typeModule(spec, name) }) {
+			content.WriteString(fmt.Sprintf("from %s import %s\n", pythonRelativePath(group.Module, "types"), strings.Join(group.Names, ", ")))
+		}
+		for _, group := range routeFunctionImports(routes, "python") {
+			content.WriteString(fmt.Sprintf("from %s import %s\n", pythonRelativePath(group.Module, "service"), strings.Join(group.Names, ", ")))
+		}
+		content.WriteString("\nrouter = APIRouter()\n\n\n")
+		content.WriteString(handlers.String())
+
+	case "java":
+		filePath = fmt.Sprintf("src/main/java/%s/Routes.java", toPackageName(spec.Name))
+		content.WriteString(fmt.Sprintf("package %s;\n\n", toPackageName(spec.Name)))
+		content.WriteString("import org.springframework.http.ResponseEntity;\n")
+		content.WriteString("import org.springframework.web.bind.annotation.*;\n")
+		content.WriteString("import org.springframework.web.server.ResponseStatusException;\n")
+		content.WriteString("import org.springframework.http.HttpStatus;\n")
+		content.WriteString(moduleImports(spec, lang.ID, "", routeForeignTypes(routes, spec, "")))
+		content.WriteString("\n")
+		content.WriteString("@RestController\npublic class Routes {\n")
+		content.WriteString(strings.TrimSuffix(handlers.String(), "\n"))
+		content.WriteString("}\n")
+
+	case "rust":
+		filePath = "src/routes.rs"
+		code := handlers.String()
+		if strings.Contains(code, "HashMap<") {
+			content.WriteString("use std::collections::HashMap;\n\n")
+		}
+		var extractors, methods []string
+		for _, extractor := range []string{"Json", "Path", "Query"} {
+			if strings.Contains(code, extractor+"(") {
+				extractors = append(extractors, extractor)
+			}
+		}
+		for _, method := range []string{"DELETE", "GET", "PATCH", "POST", "PUT"} {
+			for _, r := range routes {
+				if r.Endpoint.Method == method {
+					methods = append(methods, strings.ToLower(method))
+					break
+				}
+			}
+		}
+		if len(extractors) > 0 {
+			content.WriteString(fmt.Sprintf("use axum::extract::{%s};\n", strings.Join(extractors, ", ")))
+		}
+		if strings.Contains(code, "HeaderMap") {
+			content.WriteString("use axum::http::{HeaderMap, StatusCode};\n")
+		} else {
+			content.WriteString("use axum::http::StatusCode;\n")
+		}
+		content.WriteString("use axum::response::{IntoResponse, Response};\n")
+		content.WriteString(fmt.Sprintf("use axum::routing::{%s};\n", strings.Join(methods, ", ")))
+		content.WriteString("use axum::Router;\n\n")
+		if len(spec.Types) > 0 {
+			content.WriteString("use crate::types::*;\n")
+		}
+		content.WriteString(moduleImports(spec, lang.ID, "", routeForeignTypes(routes, spec, "")))
+		if len(spec.Functions) > 0 {
+			content.WriteString("use crate::service;\n")
+		}
+		content.WriteString("\n/// Builds the router for the API endpoints.\npub fn router() -> Router {\n    Router::new()\n")
+		// axum takes all of a path's methods in a single route
+		var paths []string
+		methodRouters := make(map[string][]string)
+		for _, r := range routes {
+			path := formatPath(r.Endpoint.Path, ":", "")
+			if _, ok := methodRouters[path]; !ok {
+				paths = append(paths, path)
+			}
+			methodRouters[path] = append(methodRouters[path], fmt.Sprintf("%s(%s)", strings.ToLower(r.Endpoint.Method), toSnakeCase(handlerName(r))))
+		}
+		for _, path := range paths {
+			content.WriteString(fmt.Sprintf("        .route(%q, %s)\n", path, strings.Join(methodRouters[path], ".")))
+		}
+		content.WriteString("}\n\n")
+		content.WriteString(handlers.String())
+
+	case "csharp":
+		filePath = "src/Routes.cs"
+		content.WriteString("using Microsoft.AspNetCore.Builder;\n")
+		content.WriteString("using Microsoft.AspNetCore.Http;\n")
+		content.WriteString("using Microsoft.AspNetCore.Mvc;\n")
+		content.WriteString(moduleImports(spec, lang.ID, "", routeForeignTypes(routes, spec, "")))
+		content.WriteString("\n")
+		content.WriteString(fmt.Sprintf("namespace %s\n{\n", toPascalCase(spec.Name)))
+		content.WriteString("    public static class Routes\n    {\n")
+		content.WriteString("        /// <summary>\n        /// Maps the API endpoints.\n        /// </summary>\n")
+		content.WriteString("        public static void MapRoutes(this WebApplication app)\n        {\n")
+		for _, r := range routes {
+			content.WriteString(fmt.Sprintf("            app.Map%s(%q, %s);\n", toPascalCase(strings.ToLower(r.Endpoint.Method)), formatPath(r.Endpoint.Path, "{", "}"), toPascalCase(handlerName(r))))
+		}
+		content.WriteString("        }\n\n")
+		content.WriteString(strings.TrimSuffix(handlers.String(), "\n"))
+		content.WriteString("    }\n}\n")
+
+	default:
+		return nil
+	}
+
+	var elements []string
+	for _, r := range routes {
+		elements = append(elements, r.Name)
+	}
+
+	return []GeneratedFile{{
+		Path:     filePath,
+		Content:  content.String(),
+		Category: "endpoint",
+		Elements: elements,
+	}}
+}
+
+// goRouteFiles writes a routes.go with its own RegisterRoutes into each Go
+// package whose functions serve endpoints. A module's package imports the
+// root package for its types, so the root cannot import it back to call its
+// functions. Handlers without a function stay in the root package.
+func goRouteFiles(spec *specparser.SpecAnalysis, routes []route, regions []string) []GeneratedFile {
+	var modules []string
+	indexes := make(map[string][]int)
+	for i, r := range routes {
+		module := ""
+		if r.bound() {
+			module = r.Function.Module
+		}
+		if _, ok := indexes[module]; !ok {
+			modules = append(modules, module)
+		}
+		indexes[module] = append(indexes[module], i)
+	}
+	sort.Strings(modules)
+
+	var files []GeneratedFile
+	for _, module := range modules {
+		var own []route
+		var handlers strings.Builder
+		for _, i := range indexes[module] {
+			own = append(own, routes[i])
+			handlers.WriteString(regions[i])
+		}
+
+		var content strings.Builder
+		imports := []string{"encoding/json", "net/http"}
+		if strings.Contains(handlers.String(), "strconv.") {
+			imports = append(imports, "strconv")
+		}
+		content.WriteString(fmt.Sprintf("package %s\n\nimport (\n", layout.Package("go", toPackageName(spec.Name), module)))
+		for _, imp := range imports {
+			content.WriteString(fmt.Sprintf("\t%q\n", imp))
+		}
+		content.WriteString(")\n\n")
+		if module == "" {
+			content.WriteString("// RegisterRoutes registers the API endpoints on mux. Endpoints served by\n")
+			content.WriteString("// another module's functions are registered by that package's RegisterRoutes.\n")
+		} else {
+			content.WriteString(fmt.Sprintf("// RegisterRoutes registers the endpoints the %s module serves on mux.\n", module))
+		}
+		content.WriteString("func RegisterRoutes(mux *http.ServeMux) {\n")
+		for _, r := range own {
+			content.WriteString(fmt.Sprintf("\tmux.HandleFunc(%q, %s)\n", r.Endpoint.Method+" "+formatPath(r.Endpoint.Path, "{", "}"), handlerName(r)))
+		}
+		content.WriteString("}\n\n")
+		content.WriteString(handlers.String())
+		content.WriteString("// writeJSON writes v as a JSON response with the given status code.\n")
+		content.WriteString("func writeJSON(w http.ResponseWriter, status int, v any) {\n")
+		content.WriteString("\tw.Header().Set(\"Content-Type\", \"application/json\")\n")
+		content.WriteString("\tw.WriteHeader(status)\n")
+		content.WriteString("\t_ = json.NewEncoder(w).Encode(v)\n")
+		content.WriteString("}\n")
+
+		var elements []string
+		for _, r := range own {
+			elements = append(elements, r.Name)
+		}
+		files = append(files, GeneratedFile{
+			Path:     layout.Path("go", module, "routes.go"),
+			Content:  qualifyGo(spec, content.String(), routeForeignTypes(own, spec, module)),
+			Category: "endpoint",
+			Elements: elements,
+		})
+	}
+	return files
+}
+
+// generateHandler generates the handler for a single route.
+func (g *Generator) generateHandler(r route, lang languages.Language) string {
+	e := r.Endpoint
+	summary := e.Method + " " + e.Path
+	if e.Description != "" {
+		summary += " - " + e.Description
+	}
+
+	switch lang.ID {
+	case "go":
+		return generateGoHandler(r, summary)
+	case "typescript":
+		return generateTypeScriptHandler(r, summary)
+	case "python":
+		return generatePythonHandler(r, summary)
+	case "java":
+		return generateJavaHandler(r, summary)
+	case "rust":
+		return generateRustHandler(r, summary)
+	case "csharp":
+		return generateCSharpHandler(r, summary)
+	}
+	return ""
+}
+
+// generateGoHandler generates a net/http handler.
+func generateGoHandler(r route, summary string) string {
+	var sb strings.Builder
+	name := handlerName(r)
+	sb.WriteString(fmt.Sprintf("// %s handles %s\n", name, summary))
+	sb.WriteString(fmt.Sprintf("func %s(w http.ResponseWriter, r *http.Request) {\n", name))
+
+	if !r.bound() {
+		sb.WriteString(stubComment(r, "\t", "//"))
+		sb.WriteString("\thttp.Error(w, \"not implemented\", http.StatusNotImplemented)\n}\n")
+		return sb.String()
+	}
+
+	var args []string
+	errDeclared := false
+	for _, a := range r.Args {
+		v := paramIdent("go", a.Param)
+		args = append(args, v)
+
+		if a.Source == "body" {
+			sb.WriteString(fmt.Sprintf("\tvar %s %s\n", v, mapType(a.Type, "go")))
+			sb.WriteString(fmt.Sprintf("\tif err := json.NewDecoder(r.Body).Decode(&%s); err != nil {\n", v))
+			sb.WriteString("\t\thttp.Error(w, err.Error(), http.StatusBadRequest)\n\t\treturn\n\t}\n")
+			continue
+		}
+
+		var raw string
+		switch a.Source {
+		case "path":
+			raw = fmt.Sprintf("r.PathValue(%q)", a.Name)
+		case "query":
+			raw = fmt.Sprintf("r.URL.Query().Get(%q)", a.Name)
+		case "header":
+			raw = fmt.Sprintf("r.Header.Get(%q)", a.Name)
+		}
+
+		inner, optional := optionalInner(a.Type)
+		parse := goParse(inner)
+		switch {
+		case optional:
+			sb.WriteString(fmt.Sprintf("\tvar %s *%s\n", v, mapType(inner, "go")))
+			if parse == "" {
+				sb.WriteString(fmt.Sprintf("\tif raw := %s; raw != \"\" {\n\t\t%s = &raw\n\t}\n", raw, v))
+			} else {
+				sb.WriteString(fmt.Sprintf("\tif raw := %s; raw != \"\" {\n", raw))
+				sb.WriteString(fmt.Sprintf("\t\tparsed, err := %s\n", fmt.Sprintf(parse, "raw")))
+				sb.WriteString("\t\tif err != nil {\n\t\t\thttp.Error(w, err.Error(), http.StatusBadRequest)\n\t\t\treturn\n\t\t}\n")
+				sb.WriteString(fmt.Sprintf("\t\t%s = &parsed\n\t}\n", v))
+			}
+		case a.defaulted():
+			// Only parse an input the request carries
+			typ := mapType(inner, "go")
+			switch lit, ok := a.defaultLiteral("go"); {
+			case !ok:
+				sb.WriteString(fmt.Sprintf("\tvar %s %s\n", v, typ))
+			case typ == "string" || typ == "int" || typ == "bool":
+				sb.WriteString(fmt.Sprintf("\t%s := %s\n", v, lit))
+			default:
+				sb.WriteString(fmt.Sprintf("\tvar %s %s = %s\n", v, typ, lit))
+			}
+			if parse == "" {
+				sb.WriteString(fmt.Sprintf("\tif raw := %s; raw != \"\" {\n\t\t%s = raw\n\t}\n", raw, v))
+			} else {
+				sb.WriteString(fmt.Sprintf("\tif raw := %s; raw != \"\" {\n", raw))
+				sb.WriteString(fmt.Sprintf("\t\tparsed, err := %s\n", fmt.Sprintf(parse, "raw")))
+				sb.WriteString("\t\tif err != nil {\n\t\t\thttp.Error(w, err.Error(), http.StatusBadRequest)\n\t\t\treturn\n\t\t}\n")
+				sb.WriteString(fmt.Sprintf("\t\t%s = parsed\n\t}\n", v))
+			}
+		case parse == "":
+			sb.WriteString(fmt.Sprintf("\t%s := %s\n", v, raw))
+		default:
+			errDeclared = true
+			sb.WriteString(fmt.Sprintf("\t%s, err := %s\n", v, fmt.Sprintf(parse, raw)))
+			sb.WriteString("\tif err != nil {\n\t\thttp.Error(w, err.Error(), http.StatusBadRequest)\n\t\treturn\n\t}\n")
+		}
+	}
+
+	// Mirror the signature generateGoFunction produces
+	f := r.Function
+	call := fmt.Sprintf("%s(%s)", moduleFuncIdent("go", *f), strings.Join(args, ", "))
+	hasResult := len(f.Returns) > 0 && !containsError([]string{f.Returns[0].Type})
+	hasErr := len(f.Errors) > 0
+	for _, ret := range f.Returns {
+		hasErr = hasErr || containsError([]string{ret.Type})
+	}
+	switch {
+	case hasResult && hasErr:
+		sb.WriteString(fmt.Sprintf("\tresult, err := %s\n", call))
+	case hasResult:
+		sb.WriteString(fmt.Sprintf("\tresult := %s\n", call))
+	case hasErr && errDeclared:
+		sb.WriteString(fmt.Sprintf("\terr = %s\n", call))
+	case hasErr:
+		sb.WriteString(fmt.Sprintf("\terr := %s\n", call))
+	default:
+		sb.WriteString(fmt.Sprintf("\t%s\n", call))
+	}
+	if hasErr {
+		sb.WriteString("\tif err != nil {\n\t\thttp.Error(w, err.Error(), http.StatusInternalServerError)\n\t\treturn\n\t}\n")
+	}
+	if hasResult {
+		sb.WriteString(fmt.Sprintf("\twriteJSON(w, %s, result)\n", r.Status))
+	} else {
+		sb.WriteString(fmt.Sprintf("\tw.WriteHeader(%s)\n", r.Status))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// goParse returns the strconv call (with a %s placeholder for the raw
+// string) that parses a scalar, or "" for strings.
+func goParse(pseudoType string) string {
+	switch strings.ToLower(pseudoType) {
+	case "int", "integer":
+		return "strconv.Atoi(%s)"
+	case "int64":
+		return "strconv.ParseInt(%s, 10, 64)"
+	case "float", "float64":
+		return "strconv.ParseFloat(%s, 64)"
+	case "bool", "boolean":
+		return "strconv.ParseBool(%s)"
+	}
+	return ""
+}
+
+// generateTypeScriptHandler generates an Express handler.
+func generateTypeScriptHandler(r route, summary string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("/**\n * %s\n */\n", summary))
+	sb.WriteString(fmt.Sprintf("export async function %s(req: Request, res: Response): Promise<void> {\n", toCamelCase(handlerName(r))))
+
+	if !r.bound() {
+		sb.WriteString(stubComment(r, "  ", "//"))
+		sb.WriteString("  res.status(501).json({ error: \"not implemented\" });\n}\n")
+		return sb.String()
+	}
+
+	var args []string
+	for _, a := range r.Args {
+		v := paramIdent("typescript", a.Param)
+		args = append(args, v)
+
+		var raw string
+		switch a.Source {
+		case "body":
+			sb.WriteString(fmt.Sprintf("  const %s = req.body as %s;\n", v, mapType(a.Type, "typescript")))
+			continue
+		case "path":
+			raw = fmt.Sprintf("req.params[%q]", a.Name)
+		case "query":
+			raw = fmt.Sprintf("req.query[%q]", a.Name)
+		case "header":
+			raw = fmt.Sprintf("req.get(%q)", a.Name)
+		}
+
+		inner, optional := optionalInner(a.Type)
+		value := tsParse(inner, raw)
+		switch {
+		case optional:
+			value = fmt.Sprintf("%s !== undefined ? %s : null", raw, value)
+		case a.defaulted():
+			fallback, ok := a.defaultLiteral("typescript")
+			if !ok {
+				fallback = defaultValue(mapType(inner, "typescript"), "typescript")
+			}
+			value = fmt.Sprintf("%s !== undefined ? %s : %s", raw, value, fallback)
+		}
+		sb.WriteString(fmt.Sprintf("  const %s = %s;\n", v, value))
+	}
+
+	call := fmt.Sprintf("%s(%s)", funcIdent("typescript", r.Function.Name), strings.Join(args, ", "))
+	if r.Function.IsAsync {
+		call = "await " + call
+	}
+	if len(r.Function.Returns) > 0 {
+		sb.WriteString(fmt.Sprintf("  const result = %s;\n", call))
+		sb.WriteString(fmt.Sprintf("  res.status(%s).json(result);\n", r.Status))
+	} else {
+		sb.WriteString(fmt.Sprintf("  %s;\n", call))
+		sb.WriteString(fmt.Sprintf("  res.sendStatus(%s);\n", r.Status))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// tsParse converts a raw request string to a scalar.
+func tsParse(pseudoType, raw string) string {
+	switch strings.ToLower(pseudoType) {
+	case "int", "integer", "int64", "float", "float64":
+		return fmt.Sprintf("Number(%s)", raw)
+	case "bool", "boolean":
+		return fmt.Sprintf("%s === \"true\"", raw)
+	}
+	return fmt.Sprintf("String(%s)", raw)
+}
+
+// generatePythonHandler generates a FastAPI path operation; FastAPI parses
+// path, query, header and body inputs from the parameter declarations.
+func generatePythonHandler(r route, summary string) string {
+	var sb strings.Builder
+	e := r.Endpoint
+	sb.WriteString(fmt.Sprintf("@router.%s(%q, status_code=%s)\n", strings.ToLower(e.Method), formatPath(e.Path, "{", "}"), r.Status))
+
+	var params, args []string
+	if r.bound() {
+		for _, a := range r.Args {
+			v := paramIdent("python", a.Param)
+			args = append(args, v)
+
+			typ := mapType(a.Type, "python")
+			_, optional := optionalInner(a.Type)
+			def := "..."
+			if optional {
+				def = "None"
+			} else if a.defaulted() {
+				var ok bool
+				if def, ok = a.defaultLiteral("python"); !ok {
+					def = defaultValue(typ, "python")
+				}
+			}
+			switch {
+			case a.Source == "header":
+				params = append(params, fmt.Sprintf("%s: %s = Header(%s, alias=%q)", v, typ, def, a.Name))
+			case a.Source == "query" && v != a.Name:
+				params = append(params, fmt.Sprintf("%s: %s = Query(%s, alias=%q)", v, typ, def, a.Name))
+			case def != "...":
+				params = append(params, fmt.Sprintf("%s: %s = %s", v, typ, def))
+			default:
+				params = append(params, fmt.Sprintf("%s: %s", v, typ))
+			}
+		}
+	} else {
+		for _, p := range endpointPathParams(e) {
+			params = append(params, fmt.Sprintf("%s: %s", paramIdent("python", p.Name), mapType(p.Type, "python")))
+		}
+	}
+
+	// Parameters without defaults must come first
+	var required, defaulted []string
+	for _, p := range params {
+		if strings.Contains(p, " = ") {
+			defaulted = append(defaulted, p)
+		} else {
+			required = append(required, p)
+		}
+	}
+	params = append(required, defaulted...)
+
+	sb.WriteString(fmt.Sprintf("def %s(%s):\n", toSnakeCase(handlerName(r)), strings.Join(params, ", ")))
+	sb.WriteString(fmt.Sprintf("    \"\"\"%s\"\"\"\n", summary))
+	if !r.bound() {
+		sb.WriteString(stubComment(r, "    ", "#"))
+		sb.WriteString("    raise HTTPException(status_code=501, detail=\"not implemented\")\n")
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("    return %s(%s)\n", funcIdent("python", r.Function.Name), strings.Join(args, ", ")))
+	return sb.String()
+}
+
+// generateJavaHandler generates a Spring controller method.
+func generateJavaHandler(r route, summary string) string {
+	var sb strings.Builder
+	e := r.Endpoint
+	sb.WriteString(fmt.Sprintf("    /**\n     * %s\n     */\n", summary))
+	sb.WriteString(fmt.Sprintf("    @%sMapping(%q)\n", toPascalCase(strings.ToLower(e.Method)), formatPath(e.Path, "{", "}")))
+
+	var params, args []string
+	if r.bound() {
+		for _, a := range r.Args {
+			v := paramIdent("java", a.Param)
+			args = append(args, v)
+
+			inner, optional := optionalInner(a.Type)
+			typ := mapType(inner, "java")
+			if optional {
+				typ = boxedJavaType(typ)
+			}
+			// Spring fills in an input the request leaves out from defaultValue
+			binding := fmt.Sprintf("name = %q, required = %t", a.Name, a.Required && !optional)
+			if a.defaulted() {
+				def := a.Default
+				if def == "" {
+					def = strings.Trim(defaultValue(typ, "java"), `"`)
+				}
+				binding += fmt.Sprintf(", defaultValue = %q", def)
+			}
+			switch a.Source {
+			case "path":
+				params = append(params, fmt.Sprintf("@PathVariable(%q) %s %s", a.Name, typ, v))
+			case "query":
+				params = append(params, fmt.Sprintf("@RequestParam(%s) %s %s", binding, typ, v))
+			case "header":
+				params = append(params, fmt.Sprintf("@RequestHeader(%s) %s %s", binding, typ, v))
+			case "body":
+				params = append(params, fmt.Sprintf("@RequestBody %s %s", mapType(a.Type, "java"), v))
+			}
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("    public ResponseEntity<?> %s(%s) {\n", toCamelCase(handlerName(r)), strings.Join(params, ", ")))
+	if !r.bound() {
+		sb.WriteString(stubComment(r, "        ", "//"))
+		sb.WriteString("        throw new ResponseStatusException(HttpStatus.NOT_IMPLEMENTED);\n    }\n")
+		return sb.String()
+	}
+
+	call := fmt.Sprintf("%s.%s(%s)", r.Service, funcIdent("java", r.Function.Name), strings.Join(args, ", "))
+	if len(r.Function.Returns) > 0 {
+		sb.WriteString(fmt.Sprintf("        return ResponseEntity.status(%s).body(%s);\n", r.Status, call))
+	} else {
+		sb.WriteString(fmt.Sprintf("        %s;\n", call))
+		sb.WriteString(fmt.Sprintf("        return ResponseEntity.status(%s).build();\n", r.Status))
+	}
+	sb.WriteString("    }\n")
+	return sb.String()
+}
+
+// boxedJavaType returns the boxed form of a primitive so it can be null.
+func boxedJavaType(typ string) string {
+	switch typ {
+	case "int":
+		return "Integer"
+	case "long":
+		return "Long"
+	case "double":
+		return "Double"
+	case "boolean":
+		return "Boolean"
+	}
+	return typ
+}
+
+// generateRustHandler generates an axum handler.
+func generateRustHandler(r route, summary string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("/// %s\n", summary))
+
+	// Extractors: path parameters, query, headers, then the body last
+	var extractors []string
+	var pathArgs []routeArg
+	usesQuery, usesHeaders := false, false
+	var body *routeArg
+	if r.bound() {
+		for i, a := range r.Args {
+			switch a.Source {
+			case "path":
+				pathArgs = append(pathArgs, a)
+			case "query":
+				usesQuery = true
+			case "header":
+				usesHeaders = true
+			case "body":
+				body = &r.Args[i]
+			}
+		}
+	}
+	switch len(pathArgs) {
+	case 0:
+	case 1:
+		extractors = append(extractors, fmt.Sprintf("Path(%s): Path<%s>", paramIdent("rust", pathArgs[0].Param), mapType(pathArgs[0].Type, "rust")))
+	default:
+		var names, types []string
+		for _, a := range pathArgs {
+			names = append(names, paramIdent("rust", a.Param))
+			types = append(types, mapType(a.Type, "rust"))
+		}
+		extractors = append(extractors, fmt.Sprintf("Path((%s)): Path<(%s)>", strings.Join(names, ", "), strings.Join(types, ", ")))
+	}
+	if usesQuery {
+		extractors = append(extractors, "Query(query): Query<HashMap<String, String>>")
+	}
+	if usesHeaders {
+		extractors = append(extractors, "headers: HeaderMap")
+	}
+	if body != nil {
+		extractors = append(extractors, fmt.Sprintf("Json(%s): Json<%s>", paramIdent("rust", body.Param), mapType(body.Type, "rust")))
+	}
+
+	sb.WriteString(fmt.Sprintf("pub async fn %s(%s) -> Response {\n", toSnakeCase(handlerName(r)), strings.Join(extractors, ", ")))
+	if !r.bound() {
+		sb.WriteString(stubComment(r, "    ", "//"))
+		sb.WriteString("    StatusCode::NOT_IMPLEMENTED.into_response()\n}\n")
+		return sb.String()
+	}
+
+	var args []string
+	for _, a := range r.Args {
+		v := paramIdent("rust", a.Param)
+		args = append(args, v)
+
+		var raw string
+		switch a.Source {
+		case "query":
+			raw = fmt.Sprintf("query.get(%q).and_then(|v| v.parse().ok())", a.Name)
+		case "header":
+			raw = fmt.Sprintf("headers.get(%q).and_then(|v| v.to_str().ok()).and_then(|v| v.parse().ok())", a.Name)
+		default:
+			continue
+		}
+		inner, optional := optionalInner(a.Type)
+		switch {
+		case optional:
+			sb.WriteString(fmt.Sprintf("    let %s: Option<%s> = %s;\n", v, mapType(inner, "rust"), raw))
+		case a.defaulted():
+			if lit, ok := a.defaultLiteral("rust"); ok {
+				sb.WriteString(fmt.Sprintf("    let %s: %s = %s.unwrap_or(%s);\n", v, mapType(inner, "rust"), raw, lit))
+			} else {
+				sb.WriteString(fmt.Sprintf("    let %s: %s = %s.unwrap_or_default();\n", v, mapType(inner, "rust"), raw))
+			}
+		default:
+			sb.WriteString(fmt.Sprintf("    let %s: %s = match %s {\n", v, mapType(inner, "rust"), raw))
+			sb.WriteString("        Some(value) => value,\n")
+			sb.WriteString("        None => return StatusCode::BAD_REQUEST.into_response(),\n    };\n")
+		}
+	}
+
+	call := fmt.Sprintf("%s::%s(%s)", r.Service, funcIdent("rust", r.Function.Name), strings.Join(args, ", "))
+	if r.Function.IsAsync {
+		call += ".await"
+	}
+	status := fmt.Sprintf("StatusCode::from_u16(%s).unwrap()", r.Status)
+
+	// Declared errors come back as a Result, mirroring generateRustFunction
+	if rustWrapsErrors(*r.Function) {
+		sb.WriteString(fmt.Sprintf("    match %s {\n", call))
+		if len(r.Function.Returns) > 0 {
+			sb.WriteString(fmt.Sprintf("        Ok(result) => (%s, Json(result)).into_response(),\n", status))
+		} else {
+			sb.WriteString(fmt.Sprintf("        Ok(()) => %s.into_response(),\n", status))
+		}
+		sb.WriteString("        Err(err) => (StatusCode::INTERNAL_SERVER_ERROR, err.to_string()).into_response(),\n    }\n}\n")
+		return sb.String()
+	}
+	if len(r.Function.Returns) > 0 {
+		sb.WriteString(fmt.Sprintf("    let result = %s;\n", call))
+		sb.WriteString(fmt.Sprintf("    (%s, Json(result)).into_response()\n", status))
+	} else {
+		sb.WriteString(fmt.Sprintf("    %s;\n", call))
+		sb.WriteString(fmt.Sprintf("    %s.into_response()\n", status))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// generateCSharpHandler generates an ASP.NET Core minimal API handler;
+// route and query values bind by parameter name.
+func generateCSharpHandler(r route, summary string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("        /// <summary>\n        /// %s\n        /// </summary>\n", summary))
+
+	var params, defaulted, args []string
+	if r.bound() {
+		for _, a := range r.Args {
+			v := paramIdent("csharp", a.Param)
+			args = append(args, v)
+
+			typ := mapType(a.Type, "csharp")
+			// ASP.NET Core binds an input the request leaves out to the
+			// parameter's default
+			def := ""
+			if a.defaulted() {
+				lit, ok := a.defaultLiteral("csharp")
+				if !ok {
+					lit = "default"
+				}
+				def = " = " + lit
+			}
+			var param string
+			switch a.Source {
+			case "path":
+				param = fmt.Sprintf("[FromRoute(Name = %q)] %s %s", a.Name, typ, v)
+			case "query":
+				param = fmt.Sprintf("[FromQuery(Name = %q)] %s %s%s", a.Name, typ, v, def)
+			case "header":
+				param = fmt.Sprintf("[FromHeader(Name = %q)] %s %s%s", a.Name, typ, v, def)
+			case "body":
+				param = fmt.Sprintf("[FromBody] %s %s", typ, v)
+			}
+			// Parameters with defaults must come last
+			if def != "" {
+				defaulted = append(defaulted, param)
+			} else {
+				params = append(params, param)
+			}
+		}
+		params = append(params, defaulted...)
+	}
+
+	sb.WriteString(fmt.Sprintf("        public static IResult %s(%s)\n        {\n", toPascalCase(handlerName(r)), strings.Join(params, ", ")))
+	if !r.bound() {
+		sb.WriteString(stubComment(r, "            ", "//"))
+		sb.WriteString("            return Results.StatusCode(501);\n        }\n")
+		return sb.String()
+	}
+
+	call := fmt.Sprintf("%s.%s(%s)", r.Service, funcIdent("csharp", r.Function.Name), strings.Join(args, ", "))
+	if len(r.Function.Returns) > 0 {
+		sb.WriteString(fmt.Sprintf("            return Results.Json(%s, statusCode: %s);\n", call, r.Status))
+	} else {
+		sb.WriteString(fmt.Sprintf("            %s;\n", call))
+		sb.WriteString(fmt.Sprintf("            return Results.StatusCode(%s);\n", r.Status))
+	}
+	sb.WriteString("        }\n")
+	return sb.String()
+}
+
+// stubComment explains why a handler is left unimplemented.
+func stubComment(r route, indent, prefix string) string {
+	if r.Function == nil {
+		return fmt.Sprintf("%s%s TODO: Implement (no spec function matches %s)\n", indent, prefix, r.Name)
+	}
+	return fmt.Sprintf("%s%s TODO: Implement (bind request inputs to %s)\n", indent, prefix, r.Function.Name)
+}
+
+// handlerName returns a route's handler name; the prefix keeps handlers from
+// shadowing the service functions they call.
+func handlerName(r route) string {
+	return "handle" + toPascalCase(r.Name)
+}
+
+// routeFunctionImports returns the distinct functions called by bound
+// routes, as the language's identifiers grouped by the module declaring
+// them.
+func routeFunctionImports(routes []route, langID string) []moduleNames {
+	seen := make(map[string]bool)
+	var names []string
+	modules := make(map[string]string)
+	for _, r := range routes {
+		if r.bound() && !seen[r.Function.Name] {
+			seen[r.Function.Name] = true
+			ident := moduleFuncIdent(langID, *r.Function)
+			names = append(names, ident)
+			modules[ident] = r.Function.Module
+		}
+	}
+	return byModule(names, func(name string) string { return modules[name] })
+}
+
+// routeForeignTypes returns the request body types of bound routes that are
+// declared outside the given module, grouped by module.
+func routeForeignTypes(routes []route, spec *specparser.SpecAnalysis, module string) []moduleNames {
+	var names []string
+	for _, name := range routeTypeNames(routes, spec) {
+		if typeModule(spec, name) != module {
+			names = append(names, name)
+		}
+	}
+	return byModule(names, func(name string) string { return typeModule(spec, name) })
+}
+
+// routeTypeNames returns the distinct spec types used as request bodies by
+// bound routes.
+func routeTypeNames(routes []route, spec *specparser.SpecAnalysis) []string {
+	known := make(map[string]string)
+	for _, t := range spec.Types {
+		known[strings.ToLower(t.Name)] = t.Name
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, r := range routes {
+		if !r.bound() {
+			continue
+		}
+		for _, a := range r.Args {
+			name, ok := known[strings.ToLower(a.Type)]
+			if a.Source == "body" && ok && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// routeName derives a handler name from the method and path, e.g.
+// "GET /tasks/{id}" becomes "getTasksById".
+func routeName(method, path string) string {
+	words := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := pathParamName(segment); ok {
+			words = append(words, "By", toPascalCase(name))
+		} else if segment != "" {
+			words = append(words, toPascalCase(segment))
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// formatPath rewrites a path template's parameters with the given delimiters
+// (e.g., "{" "}" for "/tasks/{id}" or ":" "" for "/tasks/:id").
+func formatPath(path, open, close string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := pathParamName(segment); ok {
+			segments[i] = open + name + close
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParamNames returns the parameter names of a path template.
+func pathParamNames(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := pathParamName(segment); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// pathParamName reports whether a path segment is a "{name}" or ":name"
+// parameter and returns its name.
+func pathParamName(segment string) (string, bool) {
+	switch {
+	case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+		return segment[1 : len(segment)-1], true
+	case strings.HasPrefix(segment, ":") && len(segment) > 1:
+		return segment[1:], true
+	}
+	return "", false
+}
+
+// optionalInner unwraps Optional[T].
+func optionalInner(pseudoType string) (string, bool) {
+	t := strings.TrimSpace(pseudoType)
+	if strings.HasPrefix(strings.ToLower(t), "optional[") && strings.HasSuffix(t, "]") {
+		return t[len("optional[") : len(t)-1], true
+	}
+	return t, false
+}
+
+// isScalarType reports whether a pseudo-type (optionally wrapped in
+// Optional) can be parsed from a single request string.
+func isScalarType(pseudoType string) bool {
+	inner, _ := optionalInner(pseudoType)
+	switch strings.ToLower(inner) {
+	case "string", "str", "uuid", "int", "integer", "int64", "float", "float64", "bool", "boolean":
+		return true
+	}
+	return false
+}
+
+// normalizeName lowercases a name and drops separators so "create_task",
+// "createTask" and "CreateTask" match.
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(name)
+}
@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const taskAPISpec = "# Tasks\n\n" +
+	"Task tracking API.\n\n" +
+	"## Types\n\n" +
+	"### NewTask (struct)\n\n" +
+	"- title: string - Task title\n\n" +
+	"## Functions\n\n" +
+	"### getTask\n\n" +
+	"Looks up a task.\n\n" +
+	"**Parameters**\n" +
+	"- `id`: `string`\n" +
+	"- `verbose`: `bool`\n\n" +
+	"**Returns** `NewTask`\n\n" +
+	"### createTask\n\n" +
+	"**Parameters**\n" +
+	"- `task`: `NewTask`\n\n" +
+	"**Returns** `string`\n\n" +
+	"## API Endpoints\n\n" +
+	"### GET /tasks/{id}\n\n" +
+	"Get task details\n\n" +
+	"**Operation ID**: getTask\n\n" +
+	"**Auth**: bearer\n\n" +
+	"**Query Parameters**:\n" +
+	"- `verbose`: `bool` - (optional) Include history (default: false)\n\n" +
+	"**Response 200**: `NewTask` - The task\n" +
+	"**Response 404**: Not found\n\n" +
+	"### POST /tasks\n\n" +
+	"**Function**: `createTask`\n\n" +
+	"**Request**: `NewTask`\n\n" +
+	"**Response 201**: `string`\n\n" +
+	"### Admin (requires authentication)\n\n" +
+	"- DELETE /tasks/:id - Delete task\n"
+
+func TestGenerateRoutes(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(taskAPISpec, "tasks.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected []string
+	}{
+		{"go", "routes.go", []string{
+			`mux.HandleFunc("GET /tasks/{id}", handleGetTask)`,
+			`mux.HandleFunc("DELETE /tasks/{id}", handleDeleteTasksById)`,
+			// verbose is optional, so it is only parsed when present
+			"verbose := false\n\tif raw := r.URL.Query().Get(\"verbose\"); raw != \"\" {\n\t\tparsed, err := strconv.ParseBool(raw)",
+			"result := getTask(id, verbose)",
+			"writeJSON(w, 201, result)",
+			"http.StatusNotImplemented",
+			"// rpg:begin endpoint:postTasks",
+		}},
+		{"typescript", "src/routes.ts", []string{
+			`const verbose = req.query["verbose"] !== undefined ? req.query["verbose"] === "true" : false;`,
+			`router.delete("/tasks/:id", handleDeleteTasksById);`,
+			`import { getTask, createTask } from "./service";`,
+			"const task = req.body as NewTask;",
+		}},
+		{"python", "src/routes.py", []string{
+			`@router.post("/tasks", status_code=201)`,
+			"def handle_get_task(id: str, verbose: bool = False):",
+			"return create_task(task)",
+		}},
+		{"java", "src/main/java/tasks/Routes.java", []string{
+			`@GetMapping("/tasks/{id}")`,
+			`@RequestBody NewTask task`,
+			`@RequestParam(name = "verbose", required = false, defaultValue = "false") boolean verbose`,
+		}},
+		{"rust", "src/routes.rs", []string{
+			`.route("/tasks/:id", get(handle_get_task).delete(handle_delete_tasks_by_id))`,
+			"Json(task): Json<NewTask>",
+			`let verbose: bool = query.get("verbose").and_then(|v| v.parse().ok()).unwrap_or(false);`,
+		}},
+		{"csharp", "src/Routes.cs", []string{
+			`app.MapPost("/tasks", HandlePostTasks);`,
+			"Results.Json(Service.GetTask(id, verbose), statusCode: 200)",
+			`[FromQuery(Name = "verbose")] bool verbose = false)`,
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), map[string][]string{tt.path: tt.expected})
+		})
+	}
+}
+
+func TestRoutesBuild(t *testing.T) {
+	checkBuilds(t, taskAPISpec, "go", "python")
+}
+
+// goHandlerTest serves the generated task routes with httptest.
+const goHandlerTest = `package tasks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetTaskQuery(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterRoutes(mux)
+	for target, status := range map[string]int{
+		"/tasks/1":               http.StatusOK,
+		"/tasks/1?verbose=true":  http.StatusOK,
+		"/tasks/1?verbose=maybe": http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != status {
+			t.Errorf("GET %s = %d %s, expected %d", target, rec.Code, rec.Body, status)
+		}
+	}
+}
+`
+
+func TestGoHandlerOmitsOptionalQuery(t *testing.T) {
+	requireTool(t, "go")
+	dir := generateProject(t, taskAPISpec, "go")
+	writeFile(t, dir, "routes_test.go", goHandlerTest)
+	runTool(t, dir, "go", "test", "./...")
+}
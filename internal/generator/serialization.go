@@ -0,0 +1,657 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// wireName returns the JSON name of a field: its json tag, else the type's
+// JSON naming policy applied to the field name, else the field name as the
+// spec writes it. Every language serializes the field under this name.
+func wireName(t specparser.SpecType, f specparser.SpecField) string {
+	if name, _, _ := strings.Cut(f.Tags["json"], ","); name != "" && name != "-" {
+		return name
+	}
+	return applyNaming(f.Name, t.Naming["json"])
+}
+
+// wireSkipped reports whether a field's json tag leaves it out of JSON.
+func wireSkipped(f specparser.SpecField) bool {
+	name, _, _ := strings.Cut(f.Tags["json"], ",")
+	return name == "-"
+}
+
+// applyNaming renders a name in a naming policy (snake_case, camelCase,
+// PascalCase, kebab-case, SCREAMING_SNAKE_CASE, lowercase); unknown
+// policies leave the name unchanged.
+func applyNaming(name, policy string) string {
+	switch strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(policy)) {
+	case "snakecase", "snake":
+		return toSnakeCase(name)
+	case "camelcase", "camel":
+		return toCamelCase(name)
+	case "pascalcase", "pascal":
+		return toPascalCase(name)
+	case "kebabcase", "kebab":
+		return strings.ReplaceAll(toSnakeCase(name), "_", "-")
+	case "screamingsnakecase", "uppersnakecase", "screaming":
+		return strings.ToUpper(toSnakeCase(name))
+	case "lowercase", "lower":
+		return strings.ToLower(strings.ReplaceAll(toSnakeCase(name), "_", ""))
+	}
+	return name
+}
+
+// fieldType returns a field's pseudo-type, wrapping fields the spec marks
+// as not required in Optional so every language can represent JSON null.
+func fieldType(f specparser.SpecField) string {
+	if f.Required {
+		return f.Type
+	}
+	if expr, err := typeexpr.Parse(f.Type); err == nil && expr.Kind != typeexpr.Optional {
+		return "Optional[" + f.Type + "]"
+	}
+	return f.Type
+}
+
+// goStructTag renders a field's struct tag: the json tag with the wire name
+// and any options, followed by the spec's other tags in order.
+func goStructTag(t specparser.SpecType, f specparser.SpecField) string {
+	json := wireName(t, f)
+	if wireSkipped(f) {
+		json = "-"
+	} else if _, options, ok := strings.Cut(f.Tags["json"], ","); ok {
+		json += "," + options
+	}
+
+	tags := []string{fmt.Sprintf("json:%q", json)}
+	for _, key := range sortedTagKeys(f.Tags) {
+		if key != "json" {
+			tags = append(tags, fmt.Sprintf("%s:%q", key, f.Tags[key]))
+		}
+	}
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldAttribute returns the serialization attribute a field needs in
+// languages that annotate fields, or "" when the default name matches.
+func fieldAttribute(lang string, t specparser.SpecType, f specparser.SpecField) string {
+	wire := wireName(t, f)
+	switch lang {
+	case "rust":
+		if wireSkipped(f) {
+			return "#[serde(skip)]"
+		}
+		// serde drops the r# of raw identifiers
+		if wire != strings.TrimPrefix(fieldIdent("rust", f.Name), "r#") {
+			return fmt.Sprintf("#[serde(rename = %q)]", wire)
+		}
+	case "java":
+		if wireSkipped(f) {
+			return "@JsonIgnore"
+		}
+		return fmt.Sprintf("@JsonProperty(%q)", wire)
+	case "csharp":
+		if wireSkipped(f) {
+			return "[JsonIgnore]"
+		}
+		return fmt.Sprintf("[JsonPropertyName(%q)]", wire)
+	}
+	return ""
+}
+
+// pythonField renders a pydantic model field, aliasing it to its wire name
+// when that differs from the attribute name.
+func pythonField(t specparser.SpecType, f specparser.SpecField) (string, bool) {
+	name := fieldIdent("python", f.Name)
+	typ := mapType(fieldType(f), "python")
+
+	var args []string
+	if lit, ok := defaultLiteral("python", f); ok {
+		args = append(args, "default="+lit)
+	} else if !f.Required || wireSkipped(f) {
+		args = append(args, "default=None")
+	}
+	aliased := false
+	if wire := wireName(t, f); wireSkipped(f) {
+		args = append(args, "exclude=True")
+	} else if wire != name {
+		args = append(args, fmt.Sprintf("alias=%q", wire))
+		aliased = true
+	}
+
+	switch {
+	case len(args) == 0:
+		return fmt.Sprintf("%s: %s", name, typ), false
+	case len(args) == 1 && strings.HasPrefix(args[0], "default="):
+		return fmt.Sprintf("%s: %s = %s", name, typ, strings.TrimPrefix(args[0], "default=")), false
+	}
+	return fmt.Sprintf("%s: %s = Field(%s)", name, typ, strings.Join(args, ", ")), aliased
+}
+
+// ============================================================================
+// TypeScript (zod)
+// ============================================================================
+
+// tsSchemaName and tsToWireName name the zod schema parsing a type from its
+// wire format and the function converting it back.
+func tsSchemaName(typeName string) string { return typeName + "Schema" }
+func tsToWireName(typeName string) string { return toCamelCase(typeName) + "ToWire" }
+
+// tsSchema renders the zod schema and wire conversion of a struct.
+func tsSchema(t specparser.SpecType, types []specparser.SpecType) string {
+	var shape, fields, wire strings.Builder
+	for _, f := range t.Fields {
+		if wireSkipped(f) {
+			continue
+		}
+		prop, key := fieldIdent("typescript", f.Name), wireName(t, f)
+		expr, err := typeexpr.Parse(f.Type)
+
+		schema := "z.unknown()"
+		value := "value." + prop
+		if err == nil {
+			schema = zodSchema(expr, types)
+			if converted, ok := tsWireValue(expr, value, types); ok {
+				value = converted
+			}
+		}
+
+		decoded := "wire" + propertyAccess(key)
+		if f.Required {
+			shape.WriteString(fmt.Sprintf("      %s: %s,\n", tsKey(key), schema))
+		} else {
+			shape.WriteString(fmt.Sprintf("      %s: %s.nullish(),\n", tsKey(key), schema))
+			decoded += " ?? undefined"
+			if value == "value."+prop {
+				value += " ?? null"
+			} else {
+				value = fmt.Sprintf("value.%s == null ? null : %s", prop, value)
+			}
+		}
+		fields.WriteString(fmt.Sprintf("      %s: %s,\n", prop, decoded))
+		wire.WriteString(fmt.Sprintf("    %s: %s,\n", tsKey(key), value))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n/** Parses %s from its wire format. */\n", t.Name))
+	sb.WriteString(fmt.Sprintf("export const %s: z.ZodType<%s, z.ZodTypeDef, unknown> = z.lazy(() =>\n", tsSchemaName(t.Name), t.Name))
+	sb.WriteString("  z\n    .object({\n")
+	sb.WriteString(shape.String())
+	sb.WriteString("    })\n")
+	if fields.Len() == 0 {
+		sb.WriteString(fmt.Sprintf("    .transform((): %s => ({})),\n);\n", t.Name))
+	} else {
+		sb.WriteString("    .transform((wire) => ({\n")
+		sb.WriteString(fields.String())
+		sb.WriteString("    })),\n);\n")
+	}
+	sb.WriteString(fmt.Sprintf("\n/** Converts %s to its wire format. */\n", t.Name))
+	sb.WriteString(fmt.Sprintf("export function %s(value: %s): Record<string, unknown> {\n", tsToWireName(t.Name), t.Name))
+	if wire.Len() == 0 {
+		sb.WriteString("  return {};\n}\n")
+	} else {
+		sb.WriteString("  return {\n")
+		sb.WriteString(wire.String())
+		sb.WriteString("  };\n}\n")
+	}
+	return sb.String()
+}
+
+// tsAliasSchema renders the zod schema of an alias: its target's schema.
+func tsAliasSchema(t specparser.SpecType, target string, types []specparser.SpecType) string {
+	schema := "z.unknown()"
+	if expr, err := typeexpr.Parse(target); err == nil {
+		schema = zodSchema(expr, types)
+	}
+	return fmt.Sprintf("\n/** Parses %s from its wire format. */\nexport const %s: z.ZodType<%s, z.ZodTypeDef, unknown> = z.lazy(() => %s);\n", t.Name, tsSchemaName(t.Name), t.Name, schema)
+}
+
+// tsUnionSchema renders the zod schema of a union, which accepts any of
+// its members.
+func tsUnionSchema(t specparser.SpecType, types []specparser.SpecType) string {
+	var members []string
+	for _, f := range t.Fields {
+		schema := "z.unknown()"
+		if expr, err := typeexpr.Parse(f.Type); err == nil {
+			schema = zodSchema(expr, types)
+		}
+		members = append(members, schema)
+	}
+	schema := "z.never()"
+	switch len(members) {
+	case 0:
+	case 1:
+		schema = members[0]
+	default:
+		schema = fmt.Sprintf("z.union([%s])", strings.Join(members, ", "))
+	}
+	return fmt.Sprintf("\n/** Parses %s from its wire format. */\nexport const %s: z.ZodType<%s, z.ZodTypeDef, unknown> = z.lazy(() => %s);\n", t.Name, tsSchemaName(t.Name), t.Name, schema)
+}
+
+// tsKey quotes an object key that is not a plain identifier.
+func tsKey(key string) string {
+	if isIdentifier(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// propertyAccess renders a property access for a key.
+func propertyAccess(key string) string {
+	if isIdentifier(key) {
+		return "." + key
+	}
+	return "[" + strconv.Quote(key) + "]"
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// zodSchema renders the zod schema of a type expression. Spec types refer
+// to their own schemas; types JSON cannot carry are checked only by type.
+func zodSchema(e *typeexpr.Expr, types []specparser.SpecType) string {
+	switch e.Kind {
+	case typeexpr.Primitive:
+		switch e.Name {
+		case typeexpr.String, typeexpr.UUID:
+			return "z.string()"
+		case typeexpr.Int, typeexpr.Int64, typeexpr.Float, typeexpr.Decimal, typeexpr.Duration:
+			return "z.number()"
+		case typeexpr.Bool:
+			return "z.boolean()"
+		case typeexpr.Date, typeexpr.DateTime:
+			return "z.coerce.date()"
+		case typeexpr.Any:
+			return "z.any()"
+		case typeexpr.Void:
+			return "z.void()"
+		}
+	case typeexpr.Named:
+		if st, ok := findType(types, e.Name); ok && st.Kind != "interface" && len(e.Args) == 0 {
+			return fmt.Sprintf("z.lazy(() => %s)", tsSchemaName(toPascalCase(e.Name)))
+		}
+	case typeexpr.List:
+		return fmt.Sprintf("z.array(%s)", zodSchema(e.Args[0], types))
+	case typeexpr.Set:
+		return fmt.Sprintf("z.array(%s).transform((items) => new Set(items))", zodSchema(e.Args[0], types))
+	case typeexpr.Map:
+		return fmt.Sprintf("z.record(z.string(), %s)", zodSchema(e.Args[1], types))
+	case typeexpr.Optional:
+		return zodSchema(e.Args[0], types) + ".nullable()"
+	case typeexpr.Result, typeexpr.Pointer:
+		return zodSchema(e.Args[0], types)
+	case typeexpr.Tuple:
+		items := make([]string, len(e.Args))
+		for i, a := range e.Args {
+			items[i] = zodSchema(a, types)
+		}
+		return fmt.Sprintf("z.tuple([%s])", strings.Join(items, ", "))
+	case typeexpr.Union:
+		members := make([]string, len(e.Args))
+		for i, a := range e.Args {
+			members[i] = zodSchema(a, types)
+		}
+		return fmt.Sprintf("z.union([%s])", strings.Join(members, ", "))
+	}
+	return fmt.Sprintf("z.custom<%s>()", e.RenderNamed("typescript", typeName))
+}
+
+// tsWireValue returns the expression converting v to its wire value, and
+// whether any conversion is needed.
+func tsWireValue(e *typeexpr.Expr, v string, types []specparser.SpecType) (string, bool) {
+	switch e.Kind {
+	case typeexpr.Named:
+		if st, ok := findType(types, e.Name); ok && isStructKind(st.Kind) {
+			return fmt.Sprintf("%s(%s)", tsToWireName(st.Name), v), true
+		}
+	case typeexpr.List:
+		if item, ok := tsWireValue(e.Args[0], "item", types); ok {
+			return fmt.Sprintf("%s.map((item) => %s)", v, item), true
+		}
+	case typeexpr.Set:
+		if item, ok := tsWireValue(e.Args[0], "item", types); ok {
+			return fmt.Sprintf("Array.from(%s, (item) => %s)", v, item), true
+		}
+		return fmt.Sprintf("Array.from(%s)", v), true
+	case typeexpr.Map:
+		if item, ok := tsWireValue(e.Args[1], "item", types); ok {
+			return fmt.Sprintf("Object.fromEntries(Object.entries(%s).map(([key, item]) => [key, %s]))", v, item), true
+		}
+	case typeexpr.Optional:
+		if inner, ok := tsWireValue(e.Args[0], v, types); ok {
+			return fmt.Sprintf("%s == null ? null : %s", v, inner), true
+		}
+	}
+	return v, false
+}
+
+// findType looks up a spec type by name.
+func findType(types []specparser.SpecType, name string) (specparser.SpecType, bool) {
+	for _, t := range types {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return specparser.SpecType{}, false
+}
+
+// isStructKind reports whether a type kind generates a struct.
+func isStructKind(kind string) bool {
+	switch kind {
+	case "enum", "interface", "union", "alias":
+		return false
+	}
+	return true
+}
+
+// ============================================================================
+// Round-trip tests
+// ============================================================================
+
+// wireSample returns a sample JSON document for a type expression that
+// every language decodes and encodes back unchanged, or false when the
+// type has no such document (dates, decimals, enums, ...).
+func wireSample(e *typeexpr.Expr, types []specparser.SpecType, depth int) (string, bool) {
+	switch e.Kind {
+	case typeexpr.Primitive:
+		switch e.Name {
+		case typeexpr.String:
+			return `"x"`, true
+		case typeexpr.Int, typeexpr.Int64:
+			return "1", true
+		case typeexpr.Float:
+			return "1.5", true
+		case typeexpr.Bool:
+			return "true", true
+		}
+	case typeexpr.Named:
+		st, ok := findType(types, e.Name)
+		switch {
+		case !ok || depth >= 3:
+		case isStructKind(st.Kind):
+			return structSample(st, types, depth+1)
+		case st.Kind == "alias" && st.AliasOf != "":
+			// An alias is encoded as the type it stands for
+			if target, err := typeexpr.Parse(st.AliasOf); err == nil {
+				return wireSample(target, types, depth+1)
+			}
+		}
+	case typeexpr.List:
+		if item, ok := wireSample(e.Args[0], types, depth); ok {
+			return "[" + item + "]", true
+		}
+		return "[]", true
+	case typeexpr.Map:
+		if e.Args[0].Kind != typeexpr.Primitive || e.Args[0].Name != typeexpr.String {
+			return "", false
+		}
+		if item, ok := wireSample(e.Args[1], types, depth); ok {
+			return `{"k":` + item + "}", true
+		}
+		return "{}", true
+	case typeexpr.Optional:
+		return "null", true
+	}
+	return "", false
+}
+
+// structSample returns the sample document of a struct; fields that are not
+// required are null.
+func structSample(t specparser.SpecType, types []specparser.SpecType, depth int) (string, bool) {
+	var fields []string
+	for _, f := range t.Fields {
+		if wireSkipped(f) {
+			continue
+		}
+		value := "null"
+		if f.Required {
+			sample, constrained, ok := constrainedSample(f)
+			if !ok {
+				return "", false
+			}
+			if value = sample; !constrained {
+				expr, err := typeexpr.Parse(f.Type)
+				if err != nil {
+					return "", false
+				}
+				if value, ok = wireSample(expr, types, depth); !ok {
+					return "", false
+				}
+			}
+		}
+		fields = append(fields, fmt.Sprintf("%q:%s", wireName(t, f), value))
+	}
+	return "{" + strings.Join(fields, ",") + "}", true
+}
+
+// generateSerializationTests generates a test per struct type that decodes
+// a sample wire document and checks it encodes back unchanged.
+func (g *Generator) generateSerializationTests(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	type sample struct {
+		name, wire string
+	}
+	var samples []sample
+	var skipped []string
+	for _, t := range spec.Types {
+		if !isStructKind(t.Kind) {
+			continue
+		}
+		if wire, ok := structSample(t, spec.Types, 0); ok {
+			samples = append(samples, sample{t.Name, wire})
+		} else {
+			skipped = append(skipped, t.Name)
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	lang := adapter.GetLanguage()
+	pkg := toPackageName(spec.Name)
+	module := func(name string) string { return typeModule(spec, name) }
+	var names []string
+	for _, s := range samples {
+		names = append(names, s.name)
+	}
+	var foreign []moduleNames
+	for _, group := range byModule(names, module) {
+		if group.Module != "" {
+			foreign = append(foreign, group)
+		}
+	}
+	var sb strings.Builder
+	var path string
+	note := ""
+	if len(skipped) > 0 {
+		note = fmt.Sprintf("Skipped, no portable sample: %s", strings.Join(skipped, ", "))
+	}
+
+	switch lang.ID {
+	case "go":
+		path = "types_test.go"
+		if len(foreign) > 0 {
+			// The modules import the root package, so a test importing
+			// them has to be outside it, and import it too
+			pkg += "_test"
+			foreign = byModule(names, module)
+		}
+		sb.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+		sb.WriteString("import (\n\t\"encoding/json\"\n\t\"reflect\"\n\t\"testing\"\n)\n\n")
+		if note != "" {
+			sb.WriteString("// " + note + "\n\n")
+		}
+		sb.WriteString(`// assertRoundTrip decodes wire into v and checks it encodes back unchanged.
+func assertRoundTrip(t *testing.T, wire string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(wire), v); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	var expected, actual interface{}
+	_ = json.Unmarshal([]byte(wire), &expected)
+	_ = json.Unmarshal(data, &actual)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Round trip changed the wire format:\n got %s\nwant %s", data, wire)
+	}
+}
+`)
+		for _, s := range samples {
+			sb.WriteString(fmt.Sprintf("\nfunc Test%sSerialization(t *testing.T) {\n", s.name))
+			sb.WriteString(fmt.Sprintf("\tassertRoundTrip(t, %s, &%s{})\n}\n", backQuote(s.wire), s.name))
+		}
+
+	case "typescript":
+		path = "src/types.test.ts"
+		var imports []string
+		for _, s := range samples {
+			imports = append(imports, tsSchemaName(s.name), tsToWireName(s.name))
+		}
+		sb.WriteString("import { describe, it, expect } from \"vitest\";\n")
+		for _, group := range byModule(imports, module) {
+			sb.WriteString(fmt.Sprintf("import { %s } from %q;\n", strings.Join(group.Names, ", "), tsModulePath(path, group.Module, "types")))
+		}
+		sb.WriteString("\n")
+		if note != "" {
+			sb.WriteString("// " + note + "\n")
+		}
+		sb.WriteString("describe(\"serialization\", () => {\n")
+		for i, s := range samples {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("  it(%q, () => {\n", s.name))
+			sb.WriteString(fmt.Sprintf("    const wire = JSON.parse(%s);\n", singleQuote(s.wire)))
+			sb.WriteString(fmt.Sprintf("    const value = %s.parse(wire);\n", tsSchemaName(s.name)))
+			sb.WriteString(fmt.Sprintf("    expect(JSON.parse(JSON.stringify(%s(value)))).toEqual(wire);\n", tsToWireName(s.name)))
+			sb.WriteString("  });\n")
+		}
+		sb.WriteString("});\n")
+
+	case "python":
+		path = "tests/test_types.py"
+		sb.WriteString("import json\n\n")
+		for _, group := range byModule(names, module) {
+			sb.WriteString(fmt.Sprintf("from %s import %s\n", pythonModulePath(group.Module, "types"), strings.Join(group.Names, ", ")))
+		}
+		if note != "" {
+			sb.WriteString("\n# " + note + "\n")
+		}
+		for _, s := range samples {
+			sb.WriteString(fmt.Sprintf("\n\ndef test_%s_round_trip():\n", toSnakeCase(s.name)))
+			sb.WriteString(fmt.Sprintf("    wire = json.loads(%s)\n", singleQuote(s.wire)))
+			sb.WriteString(fmt.Sprintf("    assert %s.model_validate(wire).model_dump(mode=\"json\", by_alias=True) == wire\n", s.name))
+		}
+
+	case "java":
+		path = fmt.Sprintf("src/test/java/%s/TypesTest.java", pkg)
+		sb.WriteString(fmt.Sprintf("package %s;\n\n", pkg))
+		sb.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		sb.WriteString(`import com.fasterxml.jackson.databind.ObjectMapper;
+import org.junit.jupiter.api.Test;
+import static org.junit.jupiter.api.Assertions.*;
+
+`)
+		if note != "" {
+			sb.WriteString("// " + note + "\n")
+		}
+		sb.WriteString(`public class TypesTest {
+    private final ObjectMapper mapper = new ObjectMapper();
+
+    /** Decodes wire and checks it encodes back unchanged. */
+    private void assertRoundTrip(String wire, Class<?> type) throws Exception {
+        Object value = mapper.readValue(wire, type);
+        assertEquals(mapper.readTree(wire), mapper.readTree(mapper.writeValueAsString(value)));
+    }
+`)
+		for _, s := range samples {
+			sb.WriteString(fmt.Sprintf("\n    @Test\n    public void test%sSerialization() throws Exception {\n", s.name))
+			sb.WriteString(fmt.Sprintf("        assertRoundTrip(%s, %s.class);\n    }\n", strconv.Quote(s.wire), s.name))
+		}
+		sb.WriteString("}\n")
+
+	case "rust":
+		path = "tests/types.rs"
+		for _, group := range byModule(names, module) {
+			sb.WriteString(fmt.Sprintf("use %s::*;\n", rustModulePath(pkg, group.Module, "types")))
+		}
+		sb.WriteString("use serde::de::DeserializeOwned;\nuse serde::Serialize;\nuse serde_json::Value;\n\n")
+		if note != "" {
+			sb.WriteString("// " + note + "\n\n")
+		}
+		sb.WriteString(`/// Decodes wire and checks it encodes back unchanged.
+fn assert_round_trip<T: Serialize + DeserializeOwned>(wire: &str) {
+    let expected: Value = serde_json::from_str(wire).unwrap();
+    let value: T = serde_json::from_value(expected.clone()).unwrap();
+    assert_eq!(serde_json::to_value(&value).unwrap(), expected);
+}
+`)
+		for _, s := range samples {
+			sb.WriteString(fmt.Sprintf("\n#[test]\nfn %s_round_trip() {\n", toSnakeCase(s.name)))
+			sb.WriteString(fmt.Sprintf("    assert_round_trip::<%s>(%s);\n}\n", s.name, rawString(s.wire)))
+		}
+
+	case "csharp":
+		path = "tests/TypesTests.cs"
+		sb.WriteString("using System.Text.Json;\nusing System.Text.Json.Nodes;\nusing Xunit;\n")
+		sb.WriteString(moduleImports(spec, lang.ID, "", foreign))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("namespace %s.Tests\n{\n", toPascalCase(spec.Name)))
+		if note != "" {
+			sb.WriteString("    // " + note + "\n")
+		}
+		sb.WriteString(`    public class TypesTests
+    {
+        /// <summary>
+        /// Decodes wire and checks it encodes back unchanged.
+        /// </summary>
+        private static void AssertRoundTrip<T>(string wire)
+        {
+            var value = JsonSerializer.Deserialize<T>(wire);
+            var actual = JsonNode.Parse(JsonSerializer.Serialize(value));
+            Assert.True(JsonNode.DeepEquals(JsonNode.Parse(wire), actual), actual?.ToJsonString());
+        }
+`)
+		for _, s := range samples {
+			sb.WriteString(fmt.Sprintf("\n        [Fact]\n        public void %sRoundTrip()\n        {\n", s.name))
+			sb.WriteString(fmt.Sprintf("            AssertRoundTrip<%s>(%s);\n        }\n", s.name, strconv.Quote(s.wire)))
+		}
+		sb.WriteString("    }\n}\n")
+
+	default:
+		return nil
+	}
+
+	content := sb.String()
+	if lang.ID == "go" {
+		content = qualifyGo(spec, content, foreign)
+	}
+	return []GeneratedFile{{Path: path, Content: content, Category: "test", Elements: names}}
+}
@@ -0,0 +1,1426 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// stateMachine is a spec state machine resolved for generation: its
+// transitions grouped by state and event, and the guards and actions its
+// hooks provide.
+type stateMachine struct {
+	specparser.SpecStateMachine
+
+	// Ident is the machine's name as PascalCase (e.g., "Order")
+	Ident string
+
+	// Guards and Actions are the hook names, in order of first use
+	Guards  []string
+	Actions []string
+}
+
+// resolveStateMachine checks that a machine can be generated and collects
+// its hooks.
+func resolveStateMachine(m specparser.SpecStateMachine) (*stateMachine, error) {
+	r := &stateMachine{SpecStateMachine: m, Ident: safeIdent("go", toPascalCase(m.Name))}
+	if len(m.States) == 0 || len(m.Transitions) == 0 {
+		return nil, fmt.Errorf("has no transitions")
+	}
+	for _, t := range m.Transitions {
+		if t.Guard != "" && !containsString(r.Guards, t.Guard) {
+			r.Guards = append(r.Guards, t.Guard)
+		}
+		if t.Action != "" && !containsString(r.Actions, t.Action) {
+			r.Actions = append(r.Actions, t.Action)
+		}
+	}
+	for _, g := range r.Guards {
+		if containsString(r.Actions, g) {
+			return nil, fmt.Errorf("uses %s as both a guard and an action", g)
+		}
+	}
+	return r, nil
+}
+
+// stateMachines resolves the spec's state machines, describing those that
+// cannot be generated and why.
+func stateMachines(spec *specparser.SpecAnalysis) ([]*stateMachine, []string) {
+	var machines []*stateMachine
+	var skipped []string
+	for _, m := range spec.StateMachines {
+		r, err := resolveStateMachine(m)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", m.Name, err))
+			continue
+		}
+		machines = append(machines, r)
+	}
+	return machines, skipped
+}
+
+// hooked reports whether the machine has guards or actions, and so takes
+// hooks that decide and run them.
+func (m *stateMachine) hooked() bool {
+	return len(m.Guards) > 0 || len(m.Actions) > 0
+}
+
+// candidates returns the transitions from a state on an event in the order
+// they are tried. Those after the first without a guard are never reached.
+func (m *stateMachine) candidates(state, event string) []specparser.SpecTransition {
+	var ts []specparser.SpecTransition
+	for _, t := range m.Transitions {
+		if t.From != state || t.Event != event {
+			continue
+		}
+		ts = append(ts, t)
+		if t.Guard == "" {
+			break
+		}
+	}
+	return ts
+}
+
+// outcome returns the transition taken from a state on an event when every
+// guard passes or every guard rejects, and false when none is.
+func (m *stateMachine) outcome(state, event string, allow bool) (specparser.SpecTransition, bool) {
+	for _, t := range m.candidates(state, event) {
+		if t.Guard == "" || allow {
+			return t, true
+		}
+	}
+	return specparser.SpecTransition{}, false
+}
+
+// guarded reports whether a guard decides any transition from a state on
+// an event.
+func (m *stateMachine) guarded(state, event string) bool {
+	for _, t := range m.candidates(state, event) {
+		if t.Guard != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fromStates returns the states with transitions out, in declaration order.
+func (m *stateMachine) fromStates() []string {
+	var states []string
+	for _, s := range m.States {
+		for _, t := range m.Transitions {
+			if t.From == s.Name {
+				states = append(states, s.Name)
+				break
+			}
+		}
+	}
+	return states
+}
+
+// eventsFrom returns the events with transitions out of a state, in
+// declaration order.
+func (m *stateMachine) eventsFrom(state string) []string {
+	var events []string
+	for _, e := range m.Events {
+		if len(m.candidates(state, e.Name)) > 0 {
+			events = append(events, e.Name)
+		}
+	}
+	return events
+}
+
+// hookDoc describes where a guard or action is used, such as "the guard of
+// pending to paid on pay.".
+func (m *stateMachine) hookDoc(name string, guard bool) string {
+	var uses []string
+	for _, t := range m.Transitions {
+		if guard && t.Guard == name || !guard && t.Action == name {
+			uses = append(uses, fmt.Sprintf("%s to %s on %s", t.From, t.To, t.Event))
+		}
+	}
+	kind := "action"
+	if guard {
+		kind = "guard"
+	}
+	return fmt.Sprintf("the %s of %s.", kind, strings.Join(uses, ", and "))
+}
+
+// moves describes what a machine's transition function returns, with the
+// state and event parameters as given (e.g., "`state`").
+func (m *stateMachine) moves(state, event string) string {
+	text := fmt.Sprintf("the state the %s state machine moves to from %s on %s", m.Name, state, event)
+	if len(m.Actions) > 0 {
+		text += ", running the transition's action"
+	}
+	return text
+}
+
+// rejects describes when a machine's transition function fails.
+func (m *stateMachine) rejects(state, event string) string {
+	text := fmt.Sprintf("%s does not allow %s", state, event)
+	if len(m.Guards) > 0 {
+		text += " or every guard rejects it"
+	}
+	return text
+}
+
+// wrapComment wraps text into lines of at most 80 columns, each starting
+// with prefix.
+func wrapComment(prefix, text string) string {
+	var sb strings.Builder
+	line := prefix
+	for _, word := range strings.Fields(text) {
+		if line != prefix && len(line)+1+len(word) > 80 {
+			sb.WriteString(line + "\n")
+			line = prefix
+		}
+		line += " " + word
+	}
+	sb.WriteString(line + "\n")
+	return sb.String()
+}
+
+// machineCase is a row of a machine's exhaustive transition test: sending
+// an event in a state, with every guard passing or rejecting, either moves
+// to a state and runs an action or is illegal.
+type machineCase struct {
+	State, Event string
+	Allow        bool
+	To, Action   string
+	Legal        bool
+}
+
+// cases returns a test row for every state and event, and a second for the
+// guard rejecting where a guard decides the outcome.
+func (m *stateMachine) cases() []machineCase {
+	var cases []machineCase
+	for _, s := range m.States {
+		for _, e := range m.Events {
+			allows := []bool{true}
+			if m.guarded(s.Name, e.Name) {
+				allows = append(allows, false)
+			}
+			for _, allow := range allows {
+				c := machineCase{State: s.Name, Event: e.Name, Allow: allow}
+				if t, ok := m.outcome(s.Name, e.Name, allow); ok {
+					c.To, c.Action, c.Legal = t.To, t.Action, true
+				}
+				cases = append(cases, c)
+			}
+		}
+	}
+	return cases
+}
+
+// hookIdent returns the identifier of a guard or action method. Go exports
+// them so hooks can be implemented in other packages.
+func hookIdent(langID, name string) string {
+	if langID == "go" {
+		return safeIdent(langID, toPascalCase(name))
+	}
+	return funcIdent(langID, name)
+}
+
+// stateIdent returns the identifier of a state or event of a machine's
+// enum: qualified by the type's name in Go, a member elsewhere.
+func stateIdent(langID, typeName, name string) string {
+	if langID == "go" {
+		return typeName + toPascalCase(name)
+	}
+	return valueIdent(langID, name)
+}
+
+// transitionFunc returns the name of a machine's transition function:
+// TransitionOrder in Go, transitionOrder in TypeScript, transition_order in
+// Python and Rust, and the transition method of OrderMachine in Java and
+// C#.
+func transitionFunc(langID, ident string) string {
+	switch langID {
+	case "go":
+		return "Transition" + ident
+	case "typescript":
+		return "transition" + ident
+	case "python", "rust":
+		return "transition_" + toSnakeCase(ident)
+	case "java":
+		return ident + "Machine.transition"
+	case "csharp":
+		return ident + "Machine.Transition"
+	}
+	return ident
+}
+
+// ============================================================================
+// Generation
+// ============================================================================
+
+// generateStateMachines generates each spec state machine as a state enum,
+// an event enum and a transition function that rejects illegal transitions
+// with an error, along with tests that send every event in every state.
+// Guards and actions are methods of a hooks interface the caller passes to
+// the transition function.
+func (g *Generator) generateStateMachines(spec *specparser.SpecAnalysis, adapter languages.LanguageAdapter) []GeneratedFile {
+	lang := adapter.GetLanguage()
+	machines, skipped := stateMachines(spec)
+	if len(machines) == 0 {
+		return nil
+	}
+	var names []string
+	for _, m := range machines {
+		names = append(names, m.Ident)
+	}
+
+	var notes string
+	if len(skipped) > 0 {
+		prefix := langCommentPrefix(lang.ID)
+		notes = fmt.Sprintf("%s State machines not generated:\n", prefix)
+		for _, s := range skipped {
+			notes += fmt.Sprintf("%s   - %s\n", prefix, s)
+		}
+		notes += "\n"
+	}
+
+	pkg := toPackageName(spec.Name)
+	source := func(path, content string) GeneratedFile {
+		return GeneratedFile{Path: path, Content: content, Category: "statemachine", Elements: names}
+	}
+	test := func(path, content string) GeneratedFile {
+		return GeneratedFile{Path: path, Content: content, Category: "test", Elements: names}
+	}
+
+	switch lang.ID {
+	case "go":
+		var code, tests strings.Builder
+		for _, m := range machines {
+			code.WriteString("\n")
+			code.WriteString(wrapRegion(lang.ID, "statemachine", m.Ident, hashOf(m.SpecStateMachine), goStateMachine(m)))
+			tests.WriteString(goStateMachineTest(m))
+		}
+		content := fmt.Sprintf("package %s\n\nimport \"fmt\"\n\n%s%s%s", pkg, notes, goIllegalTransition, code.String())
+		imports := "\t\"errors\"\n"
+		if strings.Contains(tests.String(), "strings.") {
+			imports += "\t\"strings\"\n"
+		}
+		testContent := fmt.Sprintf("package %s\n\nimport (\n%s\t\"testing\"\n)\n%s", pkg, imports, tests.String())
+		return []GeneratedFile{source("statemachines.go", content), test("statemachines_test.go", testContent)}
+
+	case "typescript":
+		var code, tests strings.Builder
+		imports := []string{"IllegalTransitionError"}
+		for _, m := range machines {
+			code.WriteString("\n")
+			code.WriteString(wrapRegion(lang.ID, "statemachine", m.Ident, hashOf(m.SpecStateMachine), typeScriptStateMachine(m)))
+			tests.WriteString(typeScriptStateMachineTest(m))
+			imports = append(imports, m.Ident+"Event", m.Ident+"State", transitionFunc(lang.ID, m.Ident))
+			if m.hooked() {
+				imports = append(imports, m.Ident+"Hooks")
+			}
+		}
+		sort.Strings(imports)
+		content := notes + typeScriptIllegalTransition + code.String()
+		testContent := "import { describe, expect, it } from \"vitest\";\n"
+		testContent += fmt.Sprintf("import { %s } from \"./stateMachines\";\n", strings.Join(imports, ", "))
+		testContent += tests.String()
+		return []GeneratedFile{source("src/stateMachines.ts", content), test("src/stateMachines.test.ts", testContent)}
+
+	case "python":
+		var code, tests strings.Builder
+		imports := []string{"IllegalTransitionError"}
+		for _, m := range machines {
+			code.WriteString("\n\n")
+			code.WriteString(wrapRegion(lang.ID, "statemachine", m.Ident, hashOf(m.SpecStateMachine), pythonStateMachine(m)))
+			tests.WriteString(pythonStateMachineTest(m))
+			imports = append(imports, m.Ident+"Event", m.Ident+"State", transitionFunc(lang.ID, m.Ident))
+		}
+		header := "\"\"\"The spec's state machines and their transitions.\"\"\"\n\nfrom enum import Enum\n"
+		if strings.Contains(code.String(), "(Protocol)") {
+			header += "from typing import Protocol\n"
+		}
+		content := header + "\n\n" + notes + pythonIllegalTransition + code.String()
+		sort.Strings(imports)
+		testContent := "import pytest\n\n"
+		testContent += fmt.Sprintf("from %s import %s\n", pythonModulePath("", "state_machines"), strings.Join(imports, ", "))
+		testContent += tests.String()
+		return []GeneratedFile{source("src/state_machines.py", content), test("tests/test_state_machines.py", testContent)}
+
+	case "java":
+		dir := fmt.Sprintf("src/main/java/%s/", pkg)
+		files := []GeneratedFile{source(dir+"IllegalTransitionException.java", fmt.Sprintf("package %s;\n\n%s%s", pkg, notes, javaIllegalTransition))}
+		for _, m := range machines {
+			header := fmt.Sprintf("package %s;\n\n", pkg)
+			files = append(files,
+				source(dir+m.Ident+"State.java", header+javaEnum(m.Ident+"State", fmt.Sprintf("A state of the %s state machine.", m.Name), machineStates(m))),
+				source(dir+m.Ident+"Event.java", header+javaEnum(m.Ident+"Event", fmt.Sprintf("An event of the %s state machine.", m.Name), machineEvents(m))))
+			if m.hooked() {
+				files = append(files, source(dir+m.Ident+"Hooks.java", header+javaHooks(m)))
+			}
+			files = append(files,
+				source(dir+m.Ident+"Machine.java", header+javaStateMachine(m)),
+				test(fmt.Sprintf("src/test/java/%s/%sMachineTest.java", pkg, m.Ident), header+javaStateMachineTest(m)))
+		}
+		return files
+
+	case "rust":
+		var code, tests strings.Builder
+		var imports []string
+		for _, m := range machines {
+			code.WriteString("\n")
+			code.WriteString(wrapRegion(lang.ID, "statemachine", m.Ident, hashOf(m.SpecStateMachine), rustStateMachine(m)))
+			tests.WriteString(rustStateMachineTest(m))
+			imports = append(imports, m.Ident+"Event")
+			if m.hooked() {
+				imports = append(imports, m.Ident+"Hooks")
+			}
+			imports = append(imports, m.Ident+"State", transitionFunc(lang.ID, m.Ident))
+		}
+		content := "//! The spec's state machines and their transitions.\n\nuse serde::{Deserialize, Serialize};\n\n" + notes + rustIllegalTransition + code.String()
+		sort.Strings(imports)
+		testContent := fmt.Sprintf("use %s::{%s};\n", rustModulePath(pkg, "", "state_machines"), strings.Join(imports, ", "))
+		testContent += tests.String()
+		return []GeneratedFile{source("src/state_machines.rs", content), test("tests/state_machines.rs", testContent)}
+
+	case "csharp":
+		var code, tests strings.Builder
+		for _, m := range machines {
+			code.WriteString("\n")
+			code.WriteString(wrapRegion(lang.ID, "statemachine", m.Ident, hashOf(m.SpecStateMachine), csharpStateMachine(m)))
+			tests.WriteString("\n")
+			tests.WriteString(csharpStateMachineTest(m))
+		}
+		namespace := toPascalCase(spec.Name)
+		content := fmt.Sprintf("using System;\n\n%snamespace %s\n{\n%s%s}\n", notes, namespace, csharpIllegalTransition, code.String())
+		testContent := fmt.Sprintf("using System.Collections.Generic;\nusing Xunit;\n\nnamespace %s.Tests\n{%s}\n", namespace, tests.String())
+		return []GeneratedFile{source("src/StateMachines.cs", content), test("tests/StateMachinesTests.cs", testContent)}
+	}
+	return nil
+}
+
+// machineStates and machineEvents return a machine's states and events as
+// enum members with their descriptions.
+func machineStates(m *stateMachine) []specparser.SpecEnumValue {
+	var values []specparser.SpecEnumValue
+	for _, s := range m.States {
+		var notes []string
+		if s.Name == m.InitialState() {
+			notes = append(notes, "The initial state")
+		}
+		if s.Description != "" {
+			notes = append(notes, strings.TrimSuffix(s.Description, "."))
+		}
+		if s.Final {
+			notes = append(notes, "A final state")
+		}
+		description := ""
+		if len(notes) > 0 {
+			description = strings.Join(notes, ". ") + "."
+		}
+		values = append(values, specparser.SpecEnumValue{Name: s.Name, Description: description})
+	}
+	return values
+}
+
+func machineEvents(m *stateMachine) []specparser.SpecEnumValue {
+	var values []specparser.SpecEnumValue
+	for _, e := range m.Events {
+		values = append(values, specparser.SpecEnumValue{Name: e.Name, Description: e.Description})
+	}
+	return values
+}
+
+// ============================================================================
+// Go
+// ============================================================================
+
+const goIllegalTransition = `// IllegalTransitionError is returned when a state machine is sent an event
+// its state does not allow, or that every guard rejects.
+type IllegalTransitionError struct {
+	// Machine is the name of the state machine
+	Machine string
+
+	// State is the state the event was sent in
+	State string
+
+	// Event is the event that was rejected
+	Event string
+}
+
+// Error names the event and the state that rejected it.
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("%s: event %s is not allowed in state %s", e.Machine, e.Event, e.State)
+}
+`
+
+// goStateMachine renders a machine's enums, hooks interface and transition
+// function.
+func goStateMachine(m *stateMachine) string {
+	var sb strings.Builder
+	goEnum := func(typeName, doc string, values []specparser.SpecEnumValue) {
+		sb.WriteString(fmt.Sprintf("// %s is %s\ntype %s string\n\nconst (\n", typeName, lowerFirst(doc), typeName))
+		for _, v := range values {
+			if v.Description != "" {
+				sb.WriteString(fmt.Sprintf("\t// %s\n", strings.TrimSuffix(v.Description, ".")))
+			}
+			sb.WriteString(fmt.Sprintf("\t%s %s = %q\n", stateIdent("go", typeName, v.Name), typeName, v.Name))
+		}
+		sb.WriteString(")\n\n")
+	}
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	stateDoc := fmt.Sprintf("A state of the %s state machine.", m.Name)
+	if m.Description != "" {
+		stateDoc += "\n// " + m.Description
+	}
+	goEnum(stateType, stateDoc, machineStates(m))
+	goEnum(eventType, fmt.Sprintf("An event of the %s state machine.", m.Name), machineEvents(m))
+
+	sb.WriteString(fmt.Sprintf("// Initial%s is the state the %s state machine starts in.\n", stateType, m.Name))
+	sb.WriteString(fmt.Sprintf("const Initial%s = %s\n\n", stateType, stateIdent("go", stateType, m.InitialState())))
+
+	hooks := ""
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("// %sHooks decides the guards and runs the actions of the %s state\n// machine's transitions.\ntype %sHooks interface {\n", m.Ident, m.Name, m.Ident))
+		for i, name := range m.Guards {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("\t// %s is %s\n\t%s() bool\n", hookIdent("go", name), m.hookDoc(name, true), hookIdent("go", name)))
+		}
+		for i, name := range m.Actions {
+			if i > 0 || len(m.Guards) > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("\t// %s is %s\n\t%s()\n", hookIdent("go", name), m.hookDoc(name, false), hookIdent("go", name)))
+		}
+		sb.WriteString("}\n\n")
+		hooks = fmt.Sprintf(", hooks %sHooks", m.Ident)
+	}
+
+	fn := transitionFunc("go", m.Ident)
+	sb.WriteString(wrapComment("//", fmt.Sprintf("%s returns %s. It returns an *IllegalTransitionError when %s.", fn, m.moves("state", "event"), m.rejects("state", "event"))))
+	sb.WriteString(fmt.Sprintf("func %s(state %s, event %s%s) (%s, error) {\n\tswitch state {\n", fn, stateType, eventType, hooks, stateType))
+	for _, s := range m.fromStates() {
+		sb.WriteString(fmt.Sprintf("\tcase %s:\n\t\tswitch event {\n", stateIdent("go", stateType, s)))
+		for _, e := range m.eventsFrom(s) {
+			sb.WriteString(fmt.Sprintf("\t\tcase %s:\n", stateIdent("go", eventType, e)))
+			for _, t := range m.candidates(s, e) {
+				indent := "\t\t\t"
+				if t.Guard != "" {
+					sb.WriteString(fmt.Sprintf("%sif hooks.%s() {\n", indent, hookIdent("go", t.Guard)))
+					indent += "\t"
+				}
+				if t.Action != "" {
+					sb.WriteString(fmt.Sprintf("%shooks.%s()\n", indent, hookIdent("go", t.Action)))
+				}
+				sb.WriteString(fmt.Sprintf("%sreturn %s, nil\n", indent, stateIdent("go", stateType, t.To)))
+				if t.Guard != "" {
+					sb.WriteString("\t\t\t}\n")
+				}
+			}
+		}
+		sb.WriteString("\t\t}\n")
+	}
+	sb.WriteString("\t}\n")
+	sb.WriteString(fmt.Sprintf("\treturn state, &IllegalTransitionError{Machine: %q, State: string(state), Event: string(event)}\n}\n", m.Name))
+	return sb.String()
+}
+
+// goStateMachineTest renders a table test sending every event in every
+// state, with a fake hooks that passes every guard or none and records
+// the actions run.
+func goStateMachineTest(m *stateMachine) string {
+	var sb strings.Builder
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	fake := "fake" + m.Ident + "Hooks"
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("\n// %s passes every guard or none, and records the actions run.\n", fake))
+		sb.WriteString(fmt.Sprintf("type %s struct {\n\tallow   bool\n\tactions []string\n}\n", fake))
+		for _, name := range m.Guards {
+			sb.WriteString(fmt.Sprintf("\nfunc (h *%s) %s() bool { return h.allow }\n", fake, hookIdent("go", name)))
+		}
+		for _, name := range m.Actions {
+			sb.WriteString(fmt.Sprintf("\nfunc (h *%s) %s() { h.actions = append(h.actions, %q) }\n", fake, hookIdent("go", name), name))
+		}
+	}
+
+	fn := transitionFunc("go", m.Ident)
+	sb.WriteString(fmt.Sprintf("\nfunc Test%s(t *testing.T) {\n\ttests := []struct {\n", fn))
+	sb.WriteString(fmt.Sprintf("\t\tstate  %s\n\t\tevent  %s\n", stateType, eventType))
+	if m.hooked() {
+		sb.WriteString("\t\tallow  bool\n")
+	}
+	sb.WriteString(fmt.Sprintf("\t\twant   %s\n", stateType))
+	if m.hooked() {
+		sb.WriteString("\t\taction string\n")
+	}
+	sb.WriteString("\t\tlegal  bool\n\t}{\n")
+	for _, c := range m.cases() {
+		want := `""`
+		if c.Legal {
+			want = stateIdent("go", stateType, c.To)
+		}
+		row := []string{stateIdent("go", stateType, c.State), stateIdent("go", eventType, c.Event)}
+		if m.hooked() {
+			row = append(row, fmt.Sprint(c.Allow), want, fmt.Sprintf("%q", c.Action))
+		} else {
+			row = append(row, want)
+		}
+		row = append(row, fmt.Sprint(c.Legal))
+		sb.WriteString(fmt.Sprintf("\t\t{%s},\n", strings.Join(row, ", ")))
+	}
+	sb.WriteString("\t}\n\n\tfor _, tt := range tests {\n")
+
+	call := fmt.Sprintf("%s(tt.state, tt.event)", fn)
+	subject, args := "%s in %s", "tt.event, tt.state"
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("\t\thooks := &%s{allow: tt.allow}\n", fake))
+		call = fmt.Sprintf("%s(tt.state, tt.event, hooks)", fn)
+		subject, args = "%s in %s with guards passing: %v", "tt.event, tt.state, tt.allow"
+	}
+	sb.WriteString(fmt.Sprintf("\t\tgot, err := %s\n\t\tswitch {\n\t\tcase !tt.legal:\n", call))
+	sb.WriteString("\t\t\tvar illegal *IllegalTransitionError\n\t\t\tif !errors.As(err, &illegal) {\n")
+	sb.WriteString(fmt.Sprintf("\t\t\t\tt.Errorf(\"Expected %s to be illegal, got %%v\", %s, err)\n\t\t\t}\n", subject, args))
+	sb.WriteString("\t\tcase err != nil:\n")
+	sb.WriteString(fmt.Sprintf("\t\t\tt.Errorf(\"Expected %s to move to %%s, got %%v\", %s, tt.want, err)\n", subject, args))
+	sb.WriteString("\t\tcase got != tt.want:\n")
+	sb.WriteString(fmt.Sprintf("\t\t\tt.Errorf(\"Expected %s to move to %%s, got %%s\", %s, tt.want, got)\n\t\t}\n", subject, args))
+	if m.hooked() {
+		sb.WriteString("\t\tif actions := strings.Join(hooks.actions, \",\"); actions != tt.action {\n")
+		sb.WriteString(fmt.Sprintf("\t\t\tt.Errorf(\"Expected %s to run %%q, got %%q\", %s, tt.action, actions)\n\t\t}\n", subject, args))
+	}
+	sb.WriteString("\t}\n}\n")
+	return sb.String()
+}
+
+// ============================================================================
+// TypeScript
+// ============================================================================
+
+const typeScriptIllegalTransition = `/**
+ * Thrown when a state machine is sent an event its state does not allow,
+ * or that every guard rejects.
+ */
+export class IllegalTransitionError extends Error {
+  constructor(
+    readonly machine: string,
+    readonly state: string,
+    readonly event: string,
+  ) {
+    super(` + "`${machine}: event ${event} is not allowed in state ${state}`" + `);
+    this.name = "IllegalTransitionError";
+  }
+}
+`
+
+// typeScriptStateMachine renders a machine's enums, hooks interface and
+// transition function.
+func typeScriptStateMachine(m *stateMachine) string {
+	var sb strings.Builder
+	tsEnum := func(typeName, doc string, values []specparser.SpecEnumValue) {
+		sb.WriteString(fmt.Sprintf("/** %s */\nexport enum %s {\n", doc, typeName))
+		for _, v := range values {
+			if v.Description != "" {
+				sb.WriteString(fmt.Sprintf("  /** %s */\n", v.Description))
+			}
+			sb.WriteString(fmt.Sprintf("  %s = %q,\n", stateIdent("typescript", typeName, v.Name), v.Name))
+		}
+		sb.WriteString("}\n\n")
+	}
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	tsEnum(stateType, strings.TrimSpace(fmt.Sprintf("A state of the %s state machine. %s", m.Name, m.Description)), machineStates(m))
+	tsEnum(eventType, fmt.Sprintf("An event of the %s state machine.", m.Name), machineEvents(m))
+
+	sb.WriteString(fmt.Sprintf("/** The state the %s state machine starts in. */\n", m.Name))
+	sb.WriteString(fmt.Sprintf("export const initial%s = %s.%s;\n\n", stateType, stateType, stateIdent("typescript", stateType, m.InitialState())))
+
+	hooks := ""
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("/** Decides the guards and runs the actions of the %s state machine's transitions. */\nexport interface %sHooks {\n", m.Name, m.Ident))
+		for _, name := range m.Guards {
+			sb.WriteString(fmt.Sprintf("  /** %s */\n  %s(): boolean;\n", upperFirst(m.hookDoc(name, true)), hookIdent("typescript", name)))
+		}
+		for _, name := range m.Actions {
+			sb.WriteString(fmt.Sprintf("  /** %s */\n  %s(): void;\n", upperFirst(m.hookDoc(name, false)), hookIdent("typescript", name)))
+		}
+		sb.WriteString("}\n\n")
+		hooks = fmt.Sprintf(", hooks: %sHooks", m.Ident)
+	}
+
+	fn := transitionFunc("typescript", m.Ident)
+	sb.WriteString("/**\n")
+	sb.WriteString(wrapComment(" *", fmt.Sprintf("Returns %s.", m.moves("`state`", "`event`"))))
+	sb.WriteString(" *\n")
+	sb.WriteString(wrapComment(" *", fmt.Sprintf("@throws IllegalTransitionError when %s", m.rejects("`state`", "`event`"))))
+	sb.WriteString(" */\n")
+	sb.WriteString(fmt.Sprintf("export function %s(state: %s, event: %s%s): %s {\n  switch (state) {\n", fn, stateType, eventType, hooks, stateType))
+	for _, s := range m.fromStates() {
+		sb.WriteString(fmt.Sprintf("    case %s.%s:\n      switch (event) {\n", stateType, stateIdent("typescript", stateType, s)))
+		for _, e := range m.eventsFrom(s) {
+			sb.WriteString(fmt.Sprintf("        case %s.%s:\n", eventType, stateIdent("typescript", eventType, e)))
+			candidates := m.candidates(s, e)
+			for _, t := range candidates {
+				indent := "          "
+				if t.Guard != "" {
+					sb.WriteString(fmt.Sprintf("%sif (hooks.%s()) {\n", indent, hookIdent("typescript", t.Guard)))
+					indent += "  "
+				}
+				if t.Action != "" {
+					sb.WriteString(fmt.Sprintf("%shooks.%s();\n", indent, hookIdent("typescript", t.Action)))
+				}
+				sb.WriteString(fmt.Sprintf("%sreturn %s.%s;\n", indent, stateType, stateIdent("typescript", stateType, t.To)))
+				if t.Guard != "" {
+					sb.WriteString("          }\n")
+				}
+			}
+			if candidates[len(candidates)-1].Guard != "" {
+				sb.WriteString("          break;\n")
+			}
+		}
+		sb.WriteString("      }\n      break;\n")
+	}
+	sb.WriteString("  }\n")
+	sb.WriteString(fmt.Sprintf("  throw new IllegalTransitionError(%q, state, event);\n}\n", m.Name))
+	return sb.String()
+}
+
+// typeScriptStateMachineTest renders a vitest table test sending every
+// event in every state.
+func typeScriptStateMachineTest(m *stateMachine) string {
+	var sb strings.Builder
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	fake := "fake" + m.Ident + "Hooks"
+	if m.hooked() {
+		sb.WriteString("\n/** Returns hooks that pass every guard or none, recording the actions run. */\n")
+		sb.WriteString(fmt.Sprintf("function %s(allow: boolean): %sHooks & { actions: string[] } {\n", fake, m.Ident))
+		sb.WriteString("  const actions: string[] = [];\n  return {\n    actions,\n")
+		for _, name := range m.Guards {
+			sb.WriteString(fmt.Sprintf("    %s: () => allow,\n", hookIdent("typescript", name)))
+		}
+		for _, name := range m.Actions {
+			sb.WriteString(fmt.Sprintf("    %s: () => {\n      actions.push(%q);\n    },\n", hookIdent("typescript", name), name))
+		}
+		sb.WriteString("  };\n}\n")
+	}
+
+	fn := transitionFunc("typescript", m.Ident)
+	row := fmt.Sprintf("%s, %s, %s | null", stateType, eventType, stateType)
+	title := "from %s on %s"
+	params := "state, event, want"
+	if m.hooked() {
+		row = fmt.Sprintf("%s, %s, boolean, %s | null, string", stateType, eventType, stateType)
+		title = "from %s on %s with guards passing: %s"
+		params = "state, event, allow, want, action"
+	}
+	sb.WriteString(fmt.Sprintf("\ndescribe(%q, () => {\n  it.each<[%s]>([\n", fn, row))
+	for _, c := range m.cases() {
+		want := "null"
+		if c.Legal {
+			want = stateType + "." + stateIdent("typescript", stateType, c.To)
+		}
+		cells := []string{stateType + "." + stateIdent("typescript", stateType, c.State), eventType + "." + stateIdent("typescript", eventType, c.Event)}
+		if m.hooked() {
+			cells = append(cells, fmt.Sprint(c.Allow), want, fmt.Sprintf("%q", c.Action))
+		} else {
+			cells = append(cells, want)
+		}
+		sb.WriteString(fmt.Sprintf("    [%s],\n", strings.Join(cells, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("  ])(%q, (%s) => {\n", title, params))
+	call := fmt.Sprintf("%s(state, event)", fn)
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("    const hooks = %s(allow);\n", fake))
+		call = fmt.Sprintf("%s(state, event, hooks)", fn)
+	}
+	sb.WriteString("    if (want === null) {\n")
+	sb.WriteString(fmt.Sprintf("      expect(() => %s).toThrow(IllegalTransitionError);\n", call))
+	sb.WriteString("    } else {\n")
+	sb.WriteString(fmt.Sprintf("      expect(%s).toBe(want);\n", call))
+	sb.WriteString("    }\n")
+	if m.hooked() {
+		sb.WriteString("    expect(hooks.actions).toEqual(action ? [action] : []);\n")
+	}
+	sb.WriteString("  });\n});\n")
+	return sb.String()
+}
+
+// ============================================================================
+// Python
+// ============================================================================
+
+const pythonIllegalTransition = `class IllegalTransitionError(Exception):
+    """Raised when a state machine is sent an event its state does not allow,
+    or that every guard rejects."""
+
+    def __init__(self, machine: str, state: str, event: str) -> None:
+        super().__init__(f"{machine}: event {event} is not allowed in state {state}")
+        self.machine = machine
+        self.state = state
+        self.event = event
+`
+
+// pythonStateMachine renders a machine's enums, hooks protocol and
+// transition function.
+func pythonStateMachine(m *stateMachine) string {
+	var sb strings.Builder
+	pyEnum := func(typeName, doc string, values []specparser.SpecEnumValue) {
+		sb.WriteString(fmt.Sprintf("class %s(Enum):\n    \"\"\"%s\"\"\"\n\n", typeName, doc))
+		for _, v := range values {
+			if v.Description != "" {
+				sb.WriteString(fmt.Sprintf("    # %s\n", v.Description))
+			}
+			sb.WriteString(fmt.Sprintf("    %s = %q\n", stateIdent("python", typeName, v.Name), v.Name))
+		}
+		sb.WriteString("\n\n")
+	}
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	stateDoc := fmt.Sprintf("A state of the %s state machine.", m.Name)
+	if m.Description != "" {
+		stateDoc += "\n\n    " + m.Description + "\n    "
+	}
+	pyEnum(stateType, stateDoc, machineStates(m))
+	pyEnum(eventType, fmt.Sprintf("An event of the %s state machine.", m.Name), machineEvents(m))
+
+	sb.WriteString(fmt.Sprintf("# The state the %s state machine starts in\n", m.Name))
+	sb.WriteString(fmt.Sprintf("INITIAL_%s_STATE = %s.%s\n\n\n", strings.ToUpper(toSnakeCase(m.Ident)), stateType, stateIdent("python", stateType, m.InitialState())))
+
+	hooks := ""
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("class %sHooks(Protocol):\n", m.Ident))
+		sb.WriteString(fmt.Sprintf("    \"\"\"Decides the guards and runs the actions of the %s state machine's\n    transitions.\"\"\"\n", m.Name))
+		for _, name := range m.Guards {
+			sb.WriteString(fmt.Sprintf("\n    def %s(self) -> bool:\n        \"\"\"%s\"\"\"\n        ...\n", hookIdent("python", name), upperFirst(m.hookDoc(name, true))))
+		}
+		for _, name := range m.Actions {
+			sb.WriteString(fmt.Sprintf("\n    def %s(self) -> None:\n        \"\"\"%s\"\"\"\n        ...\n", hookIdent("python", name), upperFirst(m.hookDoc(name, false))))
+		}
+		sb.WriteString("\n\n")
+		hooks = fmt.Sprintf(", hooks: %sHooks", m.Ident)
+	}
+
+	fn := transitionFunc("python", m.Ident)
+	sb.WriteString(fmt.Sprintf("def %s(state: %s, event: %s%s) -> %s:\n", fn, stateType, eventType, hooks, stateType))
+	sb.WriteString(wrapComment("   ", fmt.Sprintf("\"\"\"Returns %s.", m.moves("state", "event"))))
+	sb.WriteString("\n")
+	sb.WriteString(wrapComment("   ", fmt.Sprintf("Raises IllegalTransitionError when %s.", m.rejects("state", "event"))))
+	sb.WriteString("    \"\"\"\n")
+	for i, s := range m.fromStates() {
+		keyword := "if"
+		if i > 0 {
+			keyword = "elif"
+		}
+		sb.WriteString(fmt.Sprintf("    %s state is %s.%s:\n", keyword, stateType, stateIdent("python", stateType, s)))
+		for j, e := range m.eventsFrom(s) {
+			keyword := "if"
+			if j > 0 {
+				keyword = "elif"
+			}
+			sb.WriteString(fmt.Sprintf("        %s event is %s.%s:\n", keyword, eventType, stateIdent("python", eventType, e)))
+			for _, t := range m.candidates(s, e) {
+				indent := "            "
+				if t.Guard != "" {
+					sb.WriteString(fmt.Sprintf("%sif hooks.%s():\n", indent, hookIdent("python", t.Guard)))
+					indent += "    "
+				}
+				if t.Action != "" {
+					sb.WriteString(fmt.Sprintf("%shooks.%s()\n", indent, hookIdent("python", t.Action)))
+				}
+				sb.WriteString(fmt.Sprintf("%sreturn %s.%s\n", indent, stateType, stateIdent("python", stateType, t.To)))
+			}
+		}
+	}
+	sb.WriteString(fmt.Sprintf("    raise IllegalTransitionError(%q, state.value, event.value)\n", m.Name))
+	return sb.String()
+}
+
+// pythonStateMachineTest renders a parametrized pytest test sending every
+// event in every state.
+func pythonStateMachineTest(m *stateMachine) string {
+	var sb strings.Builder
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	fake := "Fake" + m.Ident + "Hooks"
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("\n\nclass %s:\n    \"\"\"Passes every guard or none, and records the actions run.\"\"\"\n\n", fake))
+		sb.WriteString("    def __init__(self, allow: bool) -> None:\n        self.allow = allow\n        self.actions: list[str] = []\n")
+		for _, name := range m.Guards {
+			sb.WriteString(fmt.Sprintf("\n    def %s(self) -> bool:\n        return self.allow\n", hookIdent("python", name)))
+		}
+		for _, name := range m.Actions {
+			sb.WriteString(fmt.Sprintf("\n    def %s(self) -> None:\n        self.actions.append(%q)\n", hookIdent("python", name), name))
+		}
+	}
+
+	fn := transitionFunc("python", m.Ident)
+	params := "state, event, expected"
+	if m.hooked() {
+		params = "state, event, allow, expected, action"
+	}
+	sb.WriteString(fmt.Sprintf("\n\n@pytest.mark.parametrize(\n    %q,\n    [\n", params))
+	for _, c := range m.cases() {
+		want := "None"
+		if c.Legal {
+			want = stateType + "." + stateIdent("python", stateType, c.To)
+		}
+		cells := []string{stateType + "." + stateIdent("python", stateType, c.State), eventType + "." + stateIdent("python", eventType, c.Event)}
+		if m.hooked() {
+			action := "None"
+			if c.Action != "" {
+				action = fmt.Sprintf("%q", c.Action)
+			}
+			cells = append(cells, pythonBool(c.Allow), want, action)
+		} else {
+			cells = append(cells, want)
+		}
+		sb.WriteString(fmt.Sprintf("        (%s),\n", strings.Join(cells, ", ")))
+	}
+	sb.WriteString("    ],\n)\n")
+	sb.WriteString(fmt.Sprintf("def test_%s(%s):\n", fn, params))
+	sb.WriteString(fmt.Sprintf("    \"\"\"Sends every event in every state of the %s state machine.\"\"\"\n", m.Name))
+	call := fmt.Sprintf("%s(state, event)", fn)
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("    hooks = %s(allow)\n", fake))
+		call = fmt.Sprintf("%s(state, event, hooks)", fn)
+	}
+	sb.WriteString(fmt.Sprintf("    if expected is None:\n        with pytest.raises(IllegalTransitionError):\n            %s\n", call))
+	sb.WriteString(fmt.Sprintf("    else:\n        assert %s is expected\n", call))
+	if m.hooked() {
+		sb.WriteString("    assert hooks.actions == ([action] if action else [])\n")
+	}
+	return sb.String()
+}
+
+// pythonBool renders a Python boolean literal.
+func pythonBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+// ============================================================================
+// Java
+// ============================================================================
+
+const javaIllegalTransition = `/**
+ * Thrown when a state machine is sent an event its state does not allow, or
+ * that every guard rejects.
+ */
+public class IllegalTransitionException extends IllegalStateException {
+    private final String machine;
+    private final String state;
+    private final String event;
+
+    public IllegalTransitionException(String machine, String state, String event) {
+        super(machine + ": event " + event + " is not allowed in state " + state);
+        this.machine = machine;
+        this.state = state;
+        this.event = event;
+    }
+
+    public String getMachine() {
+        return machine;
+    }
+
+    public String getState() {
+        return state;
+    }
+
+    public String getEvent() {
+        return event;
+    }
+}
+`
+
+// javaEnum renders a state or event enum.
+func javaEnum(typeName, doc string, values []specparser.SpecEnumValue) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("/**\n * %s\n */\npublic enum %s {\n", doc, typeName))
+	for i, v := range values {
+		if v.Description != "" {
+			sb.WriteString(fmt.Sprintf("    /** %s */\n", v.Description))
+		}
+		sb.WriteString("    " + stateIdent("java", typeName, v.Name))
+		if i < len(values)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// javaHooks renders a machine's hooks interface.
+func javaHooks(m *stateMachine) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("/**\n * Decides the guards and runs the actions of the %s state machine's\n * transitions.\n */\npublic interface %sHooks {\n", m.Name, m.Ident))
+	first := true
+	method := func(name, returns string, guard bool) {
+		if !first {
+			sb.WriteString("\n")
+		}
+		first = false
+		sb.WriteString(fmt.Sprintf("    /** %s */\n    %s %s();\n", upperFirst(m.hookDoc(name, guard)), returns, hookIdent("java", name)))
+	}
+	for _, name := range m.Guards {
+		method(name, "boolean", true)
+	}
+	for _, name := range m.Actions {
+		method(name, "void", false)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// javaStateMachine renders the class holding a machine's initial state and
+// transition method.
+func javaStateMachine(m *stateMachine) string {
+	var sb strings.Builder
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	class := m.Ident + "Machine"
+	sb.WriteString(fmt.Sprintf("/**\n * The transitions of the %s state machine.", m.Name))
+	if m.Description != "" {
+		sb.WriteString(" " + m.Description)
+	}
+	sb.WriteString(fmt.Sprintf("\n */\npublic final class %s {\n", class))
+	sb.WriteString(fmt.Sprintf("    /** The state the %s state machine starts in. */\n", m.Name))
+	sb.WriteString(fmt.Sprintf("    public static final %s INITIAL_STATE = %s.%s;\n\n", stateType, stateType, stateIdent("java", stateType, m.InitialState())))
+	sb.WriteString(fmt.Sprintf("    private %s() {\n    }\n\n", class))
+
+	hooks := ""
+	if m.hooked() {
+		hooks = fmt.Sprintf(", %sHooks hooks", m.Ident)
+	}
+	sb.WriteString("    /**\n")
+	sb.WriteString(wrapComment("     *", fmt.Sprintf("Returns %s.", m.moves("a state", "an event"))))
+	sb.WriteString("     *\n")
+	sb.WriteString(wrapComment("     *", fmt.Sprintf("@throws IllegalTransitionException when %s", m.rejects("the state", "the event"))))
+	sb.WriteString("     */\n")
+	sb.WriteString(fmt.Sprintf("    public static %s transition(%s state, %s event%s) {\n        switch (state) {\n", stateType, stateType, eventType, hooks))
+	for _, s := range m.fromStates() {
+		sb.WriteString(fmt.Sprintf("            case %s:\n                switch (event) {\n", stateIdent("java", stateType, s)))
+		for _, e := range m.eventsFrom(s) {
+			sb.WriteString(fmt.Sprintf("                    case %s:\n", stateIdent("java", eventType, e)))
+			candidates := m.candidates(s, e)
+			for _, t := range candidates {
+				indent := "                        "
+				if t.Guard != "" {
+					sb.WriteString(fmt.Sprintf("%sif (hooks.%s()) {\n", indent, hookIdent("java", t.Guard)))
+					indent += "    "
+				}
+				if t.Action != "" {
+					sb.WriteString(fmt.Sprintf("%shooks.%s();\n", indent, hookIdent("java", t.Action)))
+				}
+				sb.WriteString(fmt.Sprintf("%sreturn %s.%s;\n", indent, stateType, stateIdent("java", stateType, t.To)))
+				if t.Guard != "" {
+					sb.WriteString("                        }\n")
+				}
+			}
+			if candidates[len(candidates)-1].Guard != "" {
+				sb.WriteString("                        break;\n")
+			}
+		}
+		sb.WriteString("                    default:\n                        break;\n")
+		sb.WriteString("                }\n                break;\n")
+	}
+	sb.WriteString("            default:\n                break;\n        }\n")
+	sb.WriteString(fmt.Sprintf("        throw new IllegalTransitionException(%q, state.name(), event.name());\n    }\n}\n", m.Name))
+	return wrapRegion("java", "statemachine", m.Ident, hashOf(m.SpecStateMachine), sb.String())
+}
+
+// javaStateMachineTest renders a JUnit parameterized test sending every
+// event in every state.
+func javaStateMachineTest(m *stateMachine) string {
+	var sb strings.Builder
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	sb.WriteString("import static org.junit.jupiter.api.Assertions.assertEquals;\nimport static org.junit.jupiter.api.Assertions.assertThrows;\n\n")
+	if m.hooked() {
+		sb.WriteString("import java.util.ArrayList;\nimport java.util.List;\n")
+	}
+	sb.WriteString("import org.junit.jupiter.params.ParameterizedTest;\nimport org.junit.jupiter.params.provider.CsvSource;\n\n")
+	sb.WriteString(fmt.Sprintf("/**\n * Sends every event in every state of the %s state machine.\n */\nclass %sMachineTest {\n", m.Name, m.Ident))
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("    /** Passes every guard or none, and records the actions run. */\n    private static class FakeHooks implements %sHooks {\n", m.Ident))
+		sb.WriteString("        private final boolean allow;\n        private final List<String> actions = new ArrayList<>();\n\n")
+		sb.WriteString("        FakeHooks(boolean allow) {\n            this.allow = allow;\n        }\n")
+		for _, name := range m.Guards {
+			sb.WriteString(fmt.Sprintf("\n        @Override\n        public boolean %s() {\n            return allow;\n        }\n", hookIdent("java", name)))
+		}
+		for _, name := range m.Actions {
+			sb.WriteString(fmt.Sprintf("\n        @Override\n        public void %s() {\n            actions.add(%q);\n        }\n", hookIdent("java", name), name))
+		}
+		sb.WriteString("    }\n\n")
+	}
+
+	name := "{1} in {0}"
+	params := fmt.Sprintf("%s state, %s event, %s expected", stateType, eventType, stateType)
+	if m.hooked() {
+		name = "{1} in {0} with guards passing: {2}"
+		params = fmt.Sprintf("%s state, %s event, boolean allow, %s expected, String action", stateType, eventType, stateType)
+	}
+	sb.WriteString(fmt.Sprintf("    @ParameterizedTest(name = %q)\n    @CsvSource({\n", name))
+	for _, c := range m.cases() {
+		cells := []string{stateIdent("java", stateType, c.State), stateIdent("java", eventType, c.Event)}
+		want := ""
+		if c.Legal {
+			want = stateIdent("java", stateType, c.To)
+		}
+		if m.hooked() {
+			cells = append(cells, fmt.Sprint(c.Allow), want, c.Action)
+		} else {
+			cells = append(cells, want)
+		}
+		sb.WriteString(fmt.Sprintf("        %q,\n", strings.Join(cells, ", ")))
+	}
+	sb.WriteString("    })\n")
+	sb.WriteString(fmt.Sprintf("    void transition(%s) {\n", params))
+	call := fmt.Sprintf("%sMachine.transition(state, event)", m.Ident)
+	if m.hooked() {
+		sb.WriteString("        FakeHooks hooks = new FakeHooks(allow);\n")
+		call = fmt.Sprintf("%sMachine.transition(state, event, hooks)", m.Ident)
+	}
+	sb.WriteString("        if (expected == null) {\n")
+	sb.WriteString(fmt.Sprintf("            assertThrows(IllegalTransitionException.class, () -> %s);\n", call))
+	sb.WriteString("        } else {\n")
+	sb.WriteString(fmt.Sprintf("            assertEquals(expected, %s);\n", call))
+	sb.WriteString("        }\n")
+	if m.hooked() {
+		sb.WriteString("        assertEquals(action == null ? List.of() : List.of(action), hooks.actions);\n")
+	}
+	sb.WriteString("    }\n}\n")
+	return sb.String()
+}
+
+// ============================================================================
+// Rust
+// ============================================================================
+
+const rustIllegalTransition = `/// Returned when a state machine is sent an event its state does not
+/// allow, or that every guard rejects.
+#[derive(Debug, thiserror::Error)]
+#[error("{machine}: event {event} is not allowed in state {state}")]
+pub struct IllegalTransitionError {
+    /// The name of the state machine
+    pub machine: &'static str,
+    /// The state the event was sent in
+    pub state: &'static str,
+    /// The event that was rejected
+    pub event: &'static str,
+}
+`
+
+// rustStateMachine renders a machine's enums, hooks trait and transition
+// function.
+func rustStateMachine(m *stateMachine) string {
+	var sb strings.Builder
+	rustEnum := func(typeName, doc, noun string, values []specparser.SpecEnumValue) {
+		sb.WriteString(fmt.Sprintf("/// %s\n#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]\npub enum %s {\n", doc, typeName))
+		for _, v := range values {
+			if v.Description != "" {
+				sb.WriteString(fmt.Sprintf("    /// %s\n", v.Description))
+			}
+			sb.WriteString(fmt.Sprintf("    #[serde(rename = %q)]\n    %s,\n", v.Name, stateIdent("rust", typeName, v.Name)))
+		}
+		sb.WriteString(fmt.Sprintf("}\n\nimpl %s {\n    /// Returns the %s's name in the spec.\n    pub fn as_str(self) -> &'static str {\n        match self {\n", typeName, noun))
+		for _, v := range values {
+			sb.WriteString(fmt.Sprintf("            %s::%s => %q,\n", typeName, stateIdent("rust", typeName, v.Name), v.Name))
+		}
+		sb.WriteString("        }\n    }\n}\n\n")
+	}
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	stateDoc := fmt.Sprintf("A state of the %s state machine.", m.Name)
+	if m.Description != "" {
+		stateDoc += "\n///\n/// " + m.Description
+	}
+	rustEnum(stateType, stateDoc, "state", machineStates(m))
+	rustEnum(eventType, fmt.Sprintf("An event of the %s state machine.", m.Name), "event", machineEvents(m))
+
+	sb.WriteString(fmt.Sprintf("/// The state the %s state machine starts in.\n", m.Name))
+	sb.WriteString(fmt.Sprintf("pub const INITIAL_%s_STATE: %s = %s::%s;\n\n", strings.ToUpper(toSnakeCase(m.Ident)), stateType, stateType, stateIdent("rust", stateType, m.InitialState())))
+
+	params := []string{"state: " + stateType, "event: " + eventType}
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("/// Decides the guards and runs the actions of the %s state machine's\n/// transitions.\npub trait %sHooks {\n", m.Name, m.Ident))
+		first := true
+		method := func(name, signature string, guard bool) {
+			if !first {
+				sb.WriteString("\n")
+			}
+			first = false
+			sb.WriteString(fmt.Sprintf("    /// %s\n    fn %s%s;\n", upperFirst(m.hookDoc(name, guard)), hookIdent("rust", name), signature))
+		}
+		for _, name := range m.Guards {
+			method(name, "(&self) -> bool", true)
+		}
+		for _, name := range m.Actions {
+			method(name, "(&mut self)", false)
+		}
+		sb.WriteString("}\n\n")
+		params = append(params, fmt.Sprintf("hooks: &mut impl %sHooks", m.Ident))
+	}
+
+	fn := transitionFunc("rust", m.Ident)
+	sb.WriteString(wrapComment("///", fmt.Sprintf("Returns %s. Fails with an [`IllegalTransitionError`] when %s.", m.moves("`state`", "`event`"), m.rejects("`state`", "`event`"))))
+	signature := fmt.Sprintf("pub fn %s(%s) -> Result<%s, IllegalTransitionError> {\n", fn, strings.Join(params, ", "), stateType)
+	if len(signature) > 101 {
+		signature = fmt.Sprintf("pub fn %s(\n    %s,\n) -> Result<%s, IllegalTransitionError> {\n", fn, strings.Join(params, ",\n    "), stateType)
+	}
+	sb.WriteString(signature)
+	sb.WriteString("    match (state, event) {\n")
+	complete := true
+	for _, s := range m.States {
+		for _, e := range m.Events {
+			candidates := m.candidates(s.Name, e.Name)
+			if len(candidates) == 0 || candidates[len(candidates)-1].Guard != "" {
+				complete = false
+			}
+			for _, t := range candidates {
+				pattern := fmt.Sprintf("(%s::%s, %s::%s)", stateType, stateIdent("rust", stateType, s.Name), eventType, stateIdent("rust", eventType, e.Name))
+				if t.Guard != "" {
+					pattern += fmt.Sprintf(" if hooks.%s()", hookIdent("rust", t.Guard))
+				}
+				to := fmt.Sprintf("Ok(%s::%s)", stateType, stateIdent("rust", stateType, t.To))
+				if t.Action != "" {
+					sb.WriteString(fmt.Sprintf("        %s => {\n            hooks.%s();\n            %s\n        }\n", pattern, hookIdent("rust", t.Action), to))
+				} else {
+					sb.WriteString(fmt.Sprintf("        %s => %s,\n", pattern, to))
+				}
+			}
+		}
+	}
+	if !complete {
+		sb.WriteString("        _ => Err(IllegalTransitionError {\n")
+		sb.WriteString(fmt.Sprintf("            machine: %q,\n            state: state.as_str(),\n            event: event.as_str(),\n        }),\n", m.Name))
+	}
+	sb.WriteString("    }\n}\n")
+	return sb.String()
+}
+
+// rustStateMachineTest renders a test sending every event in every state.
+func rustStateMachineTest(m *stateMachine) string {
+	var sb strings.Builder
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	fake := "Fake" + m.Ident + "Hooks"
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("\n/// Passes every guard or none, and records the actions run.\nstruct %s {\n    allow: bool,\n    actions: Vec<&'static str>,\n}\n\n", fake))
+		sb.WriteString(fmt.Sprintf("impl %sHooks for %s {\n", m.Ident, fake))
+		first := true
+		for _, name := range m.Guards {
+			if !first {
+				sb.WriteString("\n")
+			}
+			first = false
+			sb.WriteString(fmt.Sprintf("    fn %s(&self) -> bool {\n        self.allow\n    }\n", hookIdent("rust", name)))
+		}
+		for _, name := range m.Actions {
+			if !first {
+				sb.WriteString("\n")
+			}
+			first = false
+			sb.WriteString(fmt.Sprintf("    fn %s(&mut self) {\n        self.actions.push(%q);\n    }\n", hookIdent("rust", name), name))
+		}
+		sb.WriteString("}\n")
+	}
+
+	fn := transitionFunc("rust", m.Ident)
+	cases := m.cases()
+	row := fmt.Sprintf("(%s, %s, Option<%s>)", stateType, eventType, stateType)
+	if m.hooked() {
+		row = fmt.Sprintf("(%s, %s, bool, Option<%s>, Option<&str>)", stateType, eventType, stateType)
+	}
+	sb.WriteString(fmt.Sprintf("\n#[test]\nfn test_%s() {\n    let cases: [%s; %d] = [\n", fn, row, len(cases)))
+	for _, c := range cases {
+		want := "None"
+		if c.Legal {
+			want = fmt.Sprintf("Some(%s::%s)", stateType, stateIdent("rust", stateType, c.To))
+		}
+		cells := []string{stateType + "::" + stateIdent("rust", stateType, c.State), eventType + "::" + stateIdent("rust", eventType, c.Event)}
+		if m.hooked() {
+			action := "None"
+			if c.Action != "" {
+				action = fmt.Sprintf("Some(%q)", c.Action)
+			}
+			cells = append(cells, fmt.Sprint(c.Allow), want, action)
+		} else {
+			cells = append(cells, want)
+		}
+		sb.WriteString(fmt.Sprintf("        (%s),\n", strings.Join(cells, ", ")))
+	}
+	sb.WriteString("    ];\n")
+	if m.hooked() {
+		sb.WriteString("    for (state, event, allow, expected, action) in cases {\n")
+		sb.WriteString(fmt.Sprintf("        let mut hooks = %s {\n            allow,\n            actions: Vec::new(),\n        };\n", fake))
+		sb.WriteString(fmt.Sprintf("        let result = %s(state, event, &mut hooks);\n", fn))
+		sb.WriteString("        let case = format!(\"{event:?} in {state:?} with guards passing: {allow}\");\n")
+		sb.WriteString("        assert_eq!(result.ok(), expected, \"{case}\");\n")
+		sb.WriteString("        assert_eq!(hooks.actions, action.into_iter().collect::<Vec<_>>(), \"{case}\");\n")
+	} else {
+		sb.WriteString("    for (state, event, expected) in cases {\n")
+		sb.WriteString(fmt.Sprintf("        let result = %s(state, event);\n", fn))
+		sb.WriteString("        assert_eq!(result.ok(), expected, \"{event:?} in {state:?}\");\n")
+	}
+	sb.WriteString("    }\n}\n")
+	return sb.String()
+}
+
+// ============================================================================
+// C#
+// ============================================================================
+
+const csharpIllegalTransition = `    /// <summary>
+    /// Thrown when a state machine is sent an event its state does not allow,
+    /// or that every guard rejects.
+    /// </summary>
+    public class IllegalTransitionException : InvalidOperationException
+    {
+        public IllegalTransitionException(string machine, string state, string @event)
+            : base($"{machine}: event {@event} is not allowed in state {state}")
+        {
+            Machine = machine;
+            State = state;
+            Event = @event;
+        }
+
+        public string Machine { get; }
+
+        public string State { get; }
+
+        public string Event { get; }
+    }
+`
+
+// csharpStateMachine renders a machine's enums, hooks interface and the
+// static class holding its transition method.
+func csharpStateMachine(m *stateMachine) string {
+	var sb strings.Builder
+	csEnum := func(typeName, doc string, values []specparser.SpecEnumValue) {
+		sb.WriteString(fmt.Sprintf("    /// <summary>\n    /// %s\n    /// </summary>\n    public enum %s\n    {\n", doc, typeName))
+		for _, v := range values {
+			if v.Description != "" {
+				sb.WriteString(fmt.Sprintf("        /// <summary>%s</summary>\n", v.Description))
+			}
+			sb.WriteString(fmt.Sprintf("        %s,\n", stateIdent("csharp", typeName, v.Name)))
+		}
+		sb.WriteString("    }\n\n")
+	}
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	csEnum(stateType, strings.TrimSpace(fmt.Sprintf("A state of the %s state machine. %s", m.Name, m.Description)), machineStates(m))
+	csEnum(eventType, fmt.Sprintf("An event of the %s state machine.", m.Name), machineEvents(m))
+
+	hooks := ""
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("    /// <summary>\n    /// Decides the guards and runs the actions of the %s state machine's\n    /// transitions.\n    /// </summary>\n    public interface %sHooks\n    {\n", m.Name, m.Ident))
+		first := true
+		method := func(name, returns string, guard bool) {
+			if !first {
+				sb.WriteString("\n")
+			}
+			first = false
+			sb.WriteString(fmt.Sprintf("        /// <summary>%s</summary>\n        %s %s();\n", upperFirst(m.hookDoc(name, guard)), returns, hookIdent("csharp", name)))
+		}
+		for _, name := range m.Guards {
+			method(name, "bool", true)
+		}
+		for _, name := range m.Actions {
+			method(name, "void", false)
+		}
+		sb.WriteString("    }\n\n")
+		hooks = fmt.Sprintf(", %sHooks hooks", m.Ident)
+	}
+
+	sb.WriteString(fmt.Sprintf("    /// <summary>\n    /// The transitions of the %s state machine.\n    /// </summary>\n    public static class %sMachine\n    {\n", m.Name, m.Ident))
+	sb.WriteString(fmt.Sprintf("        /// <summary>The state the %s state machine starts in.</summary>\n", m.Name))
+	sb.WriteString(fmt.Sprintf("        public const %s InitialState = %s.%s;\n\n", stateType, stateType, stateIdent("csharp", stateType, m.InitialState())))
+	sb.WriteString("        /// <summary>\n")
+	sb.WriteString(wrapComment("        ///", fmt.Sprintf("Returns %s.", m.moves("a state", "an event"))))
+	sb.WriteString("        /// </summary>\n")
+	sb.WriteString(fmt.Sprintf("        /// <exception cref=\"IllegalTransitionException\">%s.</exception>\n", upperFirst(m.rejects("the state", "the event"))))
+	sb.WriteString(fmt.Sprintf("        public static %s Transition(%s state, %s @event%s)\n        {\n            switch (state)\n            {\n", stateType, stateType, eventType, hooks))
+	for _, s := range m.fromStates() {
+		sb.WriteString(fmt.Sprintf("                case %s.%s:\n                    switch (@event)\n                    {\n", stateType, stateIdent("csharp", stateType, s)))
+		for _, e := range m.eventsFrom(s) {
+			sb.WriteString(fmt.Sprintf("                        case %s.%s:\n", eventType, stateIdent("csharp", eventType, e)))
+			candidates := m.candidates(s, e)
+			for _, t := range candidates {
+				indent := "                            "
+				if t.Guard != "" {
+					sb.WriteString(fmt.Sprintf("%sif (hooks.%s())\n%s{\n", indent, hookIdent("csharp", t.Guard), indent))
+					indent += "    "
+				}
+				if t.Action != "" {
+					sb.WriteString(fmt.Sprintf("%shooks.%s();\n", indent, hookIdent("csharp", t.Action)))
+				}
+				sb.WriteString(fmt.Sprintf("%sreturn %s.%s;\n", indent, stateType, stateIdent("csharp", stateType, t.To)))
+				if t.Guard != "" {
+					sb.WriteString("                            }\n")
+				}
+			}
+			if candidates[len(candidates)-1].Guard != "" {
+				sb.WriteString("                            break;\n")
+			}
+		}
+		sb.WriteString("                    }\n                    break;\n")
+	}
+	sb.WriteString("            }\n")
+	sb.WriteString(fmt.Sprintf("            throw new IllegalTransitionException(%q, state.ToString(), @event.ToString());\n        }\n    }\n", m.Name))
+	return sb.String()
+}
+
+// csharpStateMachineTest renders an xUnit theory sending every event in
+// every state.
+func csharpStateMachineTest(m *stateMachine) string {
+	var sb strings.Builder
+	stateType, eventType := m.Ident+"State", m.Ident+"Event"
+	fake := "Fake" + m.Ident + "Hooks"
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("    /// <summary>\n    /// Passes every guard or none, and records the actions run.\n    /// </summary>\n    internal class %s : %sHooks\n    {\n", fake, m.Ident))
+		sb.WriteString("        private readonly bool _allow;\n\n")
+		sb.WriteString(fmt.Sprintf("        public %s(bool allow)\n        {\n            _allow = allow;\n        }\n\n", fake))
+		sb.WriteString("        public List<string> Actions { get; } = new();\n")
+		for _, name := range m.Guards {
+			sb.WriteString(fmt.Sprintf("\n        public bool %s() => _allow;\n", hookIdent("csharp", name)))
+		}
+		for _, name := range m.Actions {
+			sb.WriteString(fmt.Sprintf("\n        public void %s() => Actions.Add(%q);\n", hookIdent("csharp", name), name))
+		}
+		sb.WriteString("    }\n\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("    /// <summary>\n    /// Sends every event in every state of the %s state machine.\n    /// </summary>\n    public class %sMachineTests\n    {\n        [Theory]\n", m.Name, m.Ident))
+	for _, c := range m.cases() {
+		want := "null"
+		if c.Legal {
+			want = stateType + "." + stateIdent("csharp", stateType, c.To)
+		}
+		cells := []string{stateType + "." + stateIdent("csharp", stateType, c.State), eventType + "." + stateIdent("csharp", eventType, c.Event)}
+		if m.hooked() {
+			action := "null"
+			if c.Action != "" {
+				action = fmt.Sprintf("%q", c.Action)
+			}
+			cells = append(cells, fmt.Sprint(c.Allow), want, action)
+		} else {
+			cells = append(cells, want)
+		}
+		sb.WriteString(fmt.Sprintf("        [InlineData(%s)]\n", strings.Join(cells, ", ")))
+	}
+	params := fmt.Sprintf("%s state, %s @event, %s? expected", stateType, eventType, stateType)
+	call := fmt.Sprintf("%sMachine.Transition(state, @event)", m.Ident)
+	if m.hooked() {
+		params = fmt.Sprintf("%s state, %s @event, bool allow, %s? expected, string? action", stateType, eventType, stateType)
+		call = fmt.Sprintf("%sMachine.Transition(state, @event, hooks)", m.Ident)
+	}
+	sb.WriteString(fmt.Sprintf("        public void Transition(%s)\n        {\n", params))
+	if m.hooked() {
+		sb.WriteString(fmt.Sprintf("            var hooks = new %s(allow);\n", fake))
+	}
+	sb.WriteString("            if (expected is null)\n            {\n")
+	sb.WriteString(fmt.Sprintf("                Assert.Throws<IllegalTransitionException>(() => %s);\n", call))
+	sb.WriteString("            }\n            else\n            {\n")
+	sb.WriteString(fmt.Sprintf("                Assert.Equal(expected.Value, %s);\n", call))
+	sb.WriteString("            }\n")
+	if m.hooked() {
+		sb.WriteString("            Assert.Equal(action is null ? new List<string>() : new List<string> { action }, hooks.Actions);\n")
+	}
+	sb.WriteString("        }\n    }\n")
+	return sb.String()
+}
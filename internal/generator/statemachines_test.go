@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const ordersSpec = "# Orders\n\n" +
+	"Tracks orders.\n\n" +
+	"## State Machines\n\n" +
+	"### Order\n\n" +
+	"An order from checkout to delivery.\n\n" +
+	"**States**:\n" +
+	"- `pending` - Awaiting payment (initial)\n" +
+	"- `paid`\n" +
+	"- `shipped`\n" +
+	"- `delivered` - Handed to the customer (final)\n" +
+	"- `cancelled` (final)\n\n" +
+	"| From | Event | To | Guard | Action |\n" +
+	"|------|-------|----|-------|--------|\n" +
+	"| pending | pay | paid | paymentValid | capturePayment |\n" +
+	"| pending, paid | cancel | cancelled | - | - |\n" +
+	"| paid | ship | shipped | inStock | - |\n" +
+	"| paid | ship | cancelled | - | refund |\n" +
+	"| shipped | deliver | delivered | - | - |\n\n" +
+	"### Door\n\n" +
+	"```mermaid\n" +
+	"stateDiagram-v2\n" +
+	"    [*] --> Closed\n" +
+	"    Closed --> Open : open\n" +
+	"    Open --> Closed : close\n" +
+	"    Closed : The door is shut\n" +
+	"```\n"
+
+func TestStateMachineCases(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(ordersSpec, "orders.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	machines, skipped := stateMachines(spec)
+	if len(machines) != 2 || len(skipped) != 0 {
+		t.Fatalf("Expected both machines to resolve, got %d and %v", len(machines), skipped)
+	}
+
+	order := machines[0]
+	if strings.Join(order.Guards, ",") != "paymentValid,inStock" || strings.Join(order.Actions, ",") != "capturePayment,refund" {
+		t.Errorf("Expected the guards and actions in order of use, got %v and %v", order.Guards, order.Actions)
+	}
+
+	// Every state and event, and a second row for the two guarded pairs
+	cases := order.cases()
+	if len(cases) != 5*4+2 {
+		t.Errorf("Expected %d cases, got %d", 5*4+2, len(cases))
+	}
+	tests := []struct {
+		state, event string
+		allow        bool
+		to, action   string
+		legal        bool
+	}{
+		{"pending", "pay", true, "paid", "capturePayment", true},
+		{"pending", "pay", false, "", "", false},
+		{"paid", "ship", true, "shipped", "", true},
+		{"paid", "ship", false, "cancelled", "refund", true},
+		{"paid", "cancel", true, "cancelled", "", true},
+		{"delivered", "cancel", true, "", "", false},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, c := range cases {
+			if c.State != tt.state || c.Event != tt.event || c.Allow != tt.allow {
+				continue
+			}
+			found = true
+			if c.To != tt.to || c.Action != tt.action || c.Legal != tt.legal {
+				t.Errorf("Expected %s in %s (guards passing: %v) to give %q, %q, %v, got %+v", tt.event, tt.state, tt.allow, tt.to, tt.action, tt.legal, c)
+			}
+		}
+		if !found {
+			t.Errorf("Expected a case for %s in %s (guards passing: %v)", tt.event, tt.state, tt.allow)
+		}
+	}
+
+	// A name used as both a guard and an action cannot be a hook method
+	_, err = resolveStateMachine(specparser.SpecStateMachine{
+		Name:        "Lamp",
+		States:      []specparser.SpecState{{Name: "off"}, {Name: "on"}},
+		Transitions: []specparser.SpecTransition{{From: "off", Event: "press", To: "on", Guard: "power", Action: "power"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "both a guard and an action") {
+		t.Errorf("Expected a guard and action conflict, got %v", err)
+	}
+}
+
+func TestGenerateStateMachines(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(ordersSpec, "orders.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		files    map[string][]string
+	}{
+		{"go", map[string][]string{
+			"statemachines.go":      {"type OrderState string", "OrderStatePending OrderState = \"pending\"", "type OrderHooks interface", "func TransitionOrder(state OrderState, event OrderEvent, hooks OrderHooks) (OrderState, error)", "if hooks.InStock() {", "hooks.Refund()\n\t\t\treturn OrderStateCancelled, nil", "func TransitionDoor(state DoorState, event DoorEvent) (DoorState, error)", "const InitialOrderState = OrderStatePending"},
+			"statemachines_test.go": {"type fakeOrderHooks struct", "{OrderStatePaid, OrderEventShip, false, OrderStateCancelled, \"refund\", true}", "{OrderStateDelivered, OrderEventCancel, true, \"\", \"\", false}", "{DoorStateOpen, DoorEventOpen, \"\", false}"},
+		}},
+		{"typescript", map[string][]string{
+			"src/stateMachines.ts":      {"export enum OrderState {", "pending = \"pending\",", "export function transitionOrder(state: OrderState, event: OrderEvent, hooks: OrderHooks): OrderState {", "throw new IllegalTransitionError(\"Order\", state, event);"},
+			"src/stateMachines.test.ts": {"import { DoorEvent, DoorState, IllegalTransitionError, OrderEvent, OrderHooks, OrderState, transitionDoor, transitionOrder } from \"./stateMachines\";", "[OrderState.paid, OrderEvent.ship, false, OrderState.cancelled, \"refund\"],"},
+		}},
+		{"python", map[string][]string{
+			"src/state_machines.py":        {"class OrderState(Enum):", "class OrderHooks(Protocol):", "def transition_order(state: OrderState, event: OrderEvent, hooks: OrderHooks) -> OrderState:", "raise IllegalTransitionError(\"Order\", state.value, event.value)"},
+			"tests/test_state_machines.py": {"from src.state_machines import DoorEvent, DoorState, IllegalTransitionError,", "(OrderState.pending, OrderEvent.pay, False, None, None),"},
+		}},
+		{"java", map[string][]string{
+			"src/main/java/orders/OrderMachine.java":               {"public static OrderState transition(OrderState state, OrderEvent event, OrderHooks hooks) {", "case paid:", "throw new IllegalTransitionException(\"Order\", state.name(), event.name());"},
+			"src/main/java/orders/OrderHooks.java":                 {"boolean paymentValid();", "void refund();"},
+			"src/main/java/orders/OrderState.java":                 {"public enum OrderState {"},
+			"src/test/java/orders/OrderMachineTest.java":           {"\"paid, ship, false, cancelled, refund\",", "\"pending, pay, false, , \","},
+			"src/main/java/orders/IllegalTransitionException.java": {"extends IllegalStateException"},
+		}},
+		{"rust", map[string][]string{
+			"src/state_machines.rs":   {"pub enum OrderState {", "(OrderState::Pending, OrderEvent::Pay) if hooks.payment_valid() => {", "pub fn transition_door(\n    state: DoorState,\n    event: DoorEvent,\n) -> Result<DoorState, IllegalTransitionError> {"},
+			"tests/state_machines.rs": {"use orders::state_machines::{", "(OrderState::Paid, OrderEvent::Ship, false, Some(OrderState::Cancelled), Some(\"refund\")),"},
+			"src/lib.rs":              {"pub mod state_machines;"},
+			"Cargo.toml":              {"thiserror"},
+		}},
+		{"csharp", map[string][]string{
+			"src/StateMachines.cs":        {"public enum OrderState", "public static OrderState Transition(OrderState state, OrderEvent @event, OrderHooks hooks)", "case OrderEvent.Ship:"},
+			"tests/StateMachinesTests.cs": {"[InlineData(OrderState.Paid, OrderEvent.Ship, false, OrderState.Cancelled, \"refund\")]", "[InlineData(OrderState.Pending, OrderEvent.Pay, false, null, null)]"},
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), tt.files)
+		})
+	}
+}
+
+func TestStateMachinesBuild(t *testing.T) {
+	checkBuilds(t, ordersSpec, "go", "python")
+}
+
+func TestGoStateMachinesRun(t *testing.T) {
+	requireTool(t, "go")
+	runTool(t, generateProject(t, ordersSpec, "go"), "go", "test", "./...")
+}
@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/importer/treesitter"
+)
+
+// formatGo parses a generated Go file into its syntax tree and prints the
+// tree with go/format, so Go output is always parsable and gofmt-clean. A
+// file that does not parse is returned unchanged with its diagnostics, whose
+// line numbers count the header line stampHeader adds.
+//
+// Go structs and functions are built as syntax trees (see goStructDecl and
+// goFuncDecl) unless their templates are overridden; the other constructs
+// and any overridden template are rendered as text and checked here.
+func formatGo(path, content string) (string, []string) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		var list scanner.ErrorList
+		if errors.As(err, &list) {
+			var diagnostics []string
+			for _, e := range list {
+				e.Pos.Line++
+				diagnostics = append(diagnostics, e.Error())
+			}
+			return content, diagnostics
+		}
+		return content, []string{err.Error()}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return content, []string{fmt.Sprintf("%s: %v", path, err)}
+	}
+	return buf.String(), nil
+}
+
+// checkSyntax parses a generated file with its language's tree-sitter
+// grammar and returns a diagnostic for each syntax error. Files in other
+// languages, and Go files (see formatGo), are not checked.
+func checkSyntax(path, content string) []string {
+	lang := treesitter.DetectLanguage(path)
+	if lang == "" || lang == treesitter.LanguageGo {
+		return nil
+	}
+	errs, err := treesitter.CheckSyntax([]byte(content), lang)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", path, err)}
+	}
+	var diagnostics []string
+	for _, e := range errs {
+		diagnostics = append(diagnostics, fmt.Sprintf("%s:%s", path, e))
+	}
+	return diagnostics
+}
+
+// syntaxError reports the files whose generated code failed to parse.
+func syntaxError(files []GeneratedFile) error {
+	var diagnostics []string
+	for _, f := range files {
+		diagnostics = append(diagnostics, f.Diagnostics...)
+	}
+	if len(diagnostics) == 0 {
+		return nil
+	}
+	return fmt.Errorf("generated code has syntax errors:\n%s", strings.Join(diagnostics, "\n"))
+}
+
+// isGoSource reports whether a generated file is Go source.
+func isGoSource(path string) bool {
+	return filepath.Ext(path) == ".go"
+}
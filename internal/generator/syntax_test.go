@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+func TestFormatGo(t *testing.T) {
+	formatted, diagnostics := formatGo("types.go", "package x\n\ntype A struct {\n\tID string `json:\"id\"`\n\tDisplayName   string\n}\nfunc  f( ) {}\n")
+	if len(diagnostics) != 0 {
+		t.Fatalf("Expected no diagnostics, got %v", diagnostics)
+	}
+	expected := "package x\n\ntype A struct {\n\tID          string `json:\"id\"`\n\tDisplayName string\n}\n\nfunc f() {}\n"
+	if formatted != expected {
+		t.Errorf("Expected gofmt output:\n%s\ngot:\n%s", expected, formatted)
+	}
+
+	content := "package x\n\ntype A struct {\n\tItems []\n}\n"
+	formatted, diagnostics = formatGo("types.go", content)
+	if formatted != content {
+		t.Errorf("Expected unparsable content unchanged, got:\n%s", formatted)
+	}
+	// Line numbers count the header line
+	if len(diagnostics) == 0 || !strings.HasPrefix(diagnostics[0], "types.go:5:") {
+		t.Errorf("Expected a diagnostic at types.go:5, got %v", diagnostics)
+	}
+}
+
+func TestGoDeclarationsFromSyntaxTree(t *testing.T) {
+	task := typeView{
+		SpecType: specparser.SpecType{Name: "Task", Description: "A unit of work"},
+		Fields: []fieldView{
+			{Ident: "ID", Type: "string", Attribute: "`json:\"id\"`"},
+			{Ident: "Tags", Type: "[]string"},
+		},
+	}
+	code, ok := goStructDecl(task)
+	expected := "// A unit of work\ntype Task struct {\n\tID   string `json:\"id\"`\n\tTags []string\n}\n"
+	if !ok || code != expected {
+		t.Errorf("Expected struct:\n%s\ngot (%t):\n%s", expected, ok, code)
+	}
+
+	find := functionView{
+		Ident:   "FindTask",
+		Doc:     []string{"FindTask looks up a task."},
+		Params:  []paramView{{Ident: "id", Type: "string"}, {Ident: "opts", Type: "...Option"}},
+		Results: []string{"Task", "error"},
+		Result:  "Task{}, nil",
+	}
+	code, ok = goFuncDecl(find)
+	expected = "// FindTask looks up a task.\nfunc FindTask(id string, opts ...Option) (Task, error) {\n\t// TODO: Implement\n\treturn Task{}, nil\n}\n"
+	if !ok || code != expected {
+		t.Errorf("Expected function:\n%s\ngot (%t):\n%s", expected, ok, code)
+	}
+
+	// Malformed names and types are left to the templates
+	task.Fields[1].Type = "[]"
+	if _, ok := goStructDecl(task); ok {
+		t.Error("Expected a malformed field type to be refused")
+	}
+	find.Ident = "find task"
+	if _, ok := goFuncDecl(find); ok {
+		t.Error("Expected a malformed function name to be refused")
+	}
+}
+
+func TestCheckSyntax(t *testing.T) {
+	if d := checkSyntax("src/types.ts", "export interface A {\n  id: string;\n}\n"); len(d) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", d)
+	}
+	if d := checkSyntax("src/types.rs", "pub struct A {\n    pub id: Vec<,\n}\n"); len(d) == 0 || !strings.HasPrefix(d[0], "src/types.rs:2:") {
+		t.Errorf("Expected a diagnostic at src/types.rs:2, got %v", d)
+	}
+	if d := checkSyntax("Cargo.toml", "[package\n"); len(d) != 0 {
+		t.Errorf("Expected files without a grammar to be skipped, got %v", d)
+	}
+}
+
+func TestGenerateSyntaxErrors(t *testing.T) {
+	// Item.broken has the malformed type List[
+	spec, err := specparser.NewParser().Parse(inventorySpec, "inventory.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	gen := NewGenerator(languages.NewRegistry())
+
+	tests := []struct {
+		language string
+		path     string
+	}{
+		{"go", "types.go"},
+		{"rust", "src/types.rs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			dir := t.TempDir()
+			files, err := gen.Generate(spec, tt.language, dir)
+			if err == nil || !strings.Contains(err.Error(), tt.path+":") {
+				t.Errorf("Expected a syntax error in %s, got %v", tt.path, err)
+			}
+			for _, f := range files {
+				if f.Path != tt.path && len(f.Diagnostics) > 0 {
+					t.Errorf("Expected only %s to fail, got %s: %v", tt.path, f.Path, f.Diagnostics)
+				}
+			}
+			// Nothing is written, not even the files that parse or their
+			// base snapshots
+			if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+				t.Errorf("Expected nothing written, got %v (%v)", entries, err)
+			}
+
+			if _, err := gen.Regenerate(spec, tt.language, t.TempDir()); err == nil {
+				t.Error("Expected Regenerate to refuse code with syntax errors")
+			}
+		})
+	}
+}
+
+func TestEmptyBodies(t *testing.T) {
+	const kinds = "# Kinds\n\n## Types\n\n### Kind (enum)\n\nNo kinds yet.\n"
+	spec, err := specparser.NewParser().Parse(kinds, "kinds.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(spec.Types) != 1 || len(spec.Types[0].Values) != 0 {
+		t.Fatalf("Expected an enum without values, got %+v", spec.Types)
+	}
+
+	files, err := NewGenerator(languages.NewRegistry()).Generate(spec, "python", t.TempDir())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	contents := make(map[string]string)
+	for _, f := range files {
+		contents[f.Path] = f.Content
+	}
+	if !strings.Contains(contents["src/types.py"], "class Kind(Enum):\n    pass\n") {
+		t.Errorf("Expected an empty enum body to pass, got:\n%s", contents["src/types.py"])
+	}
+
+	checkBuilds(t, kinds, "go", "python")
+}
@@ -0,0 +1,555 @@
+package generator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// templateFS holds the default templates, one directory per language with a
+// <construct>.tmpl file per construct.
+//
+//go:embed templates
+var templateFS embed.FS
+
+// templateConstructs are the constructs rendered through templates. Each
+// language's template set defines one template per construct, named after
+// the construct. Routes, clients, configuration loaders, error catalogs,
+// CLI commands, state machines, repositories, migrations, serialization
+// tests and manifests are written directly and cannot be overridden.
+var templateConstructs = []string{"struct", "enum", "interface", "union", "alias", "function", "test", "mock", "property"}
+
+// templateLanguages are the languages with default templates.
+var templateLanguages = []string{"go", "typescript", "python", "java", "rust", "csharp"}
+
+// Templates holds each language's construct templates.
+type Templates struct {
+	byLang map[string]*template.Template
+
+	// custom holds the "language/construct" templates an override replaced
+	custom map[string]bool
+}
+
+// defaultTemplates are parsed once from templateFS.
+var defaultTemplates = mustLoadDefaults()
+
+// mustLoadDefaults parses the embedded templates, panicking on the
+// programming error of a default template that does not parse.
+func mustLoadDefaults() *Templates {
+	t, err := loadTemplates(templateFS, "templates")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// TemplateConstructs returns the constructs rendered through templates,
+// such as struct and enum.
+func TemplateConstructs() []string {
+	return append([]string(nil), templateConstructs...)
+}
+
+// DefaultTemplates returns the templates embedded in the binary.
+func DefaultTemplates() *Templates {
+	return defaultTemplates
+}
+
+// LoadTemplates returns the default templates with those found in dir
+// taking their place. dir has the layout DumpTemplates writes,
+// <language>/<construct>.tmpl, and may hold only the files to override.
+// An override may also {{define}} helper templates its language's other
+// templates use.
+func LoadTemplates(dir string) (*Templates, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("template directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("template directory %s is not a directory", dir)
+	}
+	return loadTemplates(templateFS, "templates", os.DirFS(dir))
+}
+
+// loadTemplates parses each language's templates from root within base,
+// then from each override filesystem in turn. A file in an override
+// replaces the template of the same construct.
+func loadTemplates(base fs.FS, root string, overrides ...fs.FS) (*Templates, error) {
+	t := &Templates{byLang: make(map[string]*template.Template), custom: make(map[string]bool)}
+	for _, lang := range templateLanguages {
+		set := template.New(lang).Funcs(templateFuncs())
+		for _, construct := range templateConstructs {
+			source, err := fs.ReadFile(base, path.Join(root, lang, construct+".tmpl"))
+			if err != nil {
+				return nil, fmt.Errorf("default template %s/%s: %w", lang, construct, err)
+			}
+			for _, o := range overrides {
+				custom, err := fs.ReadFile(o, path.Join(lang, construct+".tmpl"))
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("template %s/%s: %w", lang, construct, err)
+				}
+				source = custom
+				t.custom[lang+"/"+construct] = true
+			}
+			if _, err := set.New(construct).Parse(string(source)); err != nil {
+				return nil, fmt.Errorf("template %s/%s: %w", lang, construct, err)
+			}
+		}
+		t.byLang[lang] = set
+	}
+	return t, nil
+}
+
+// DumpTemplates writes the default templates to dir as
+// <language>/<construct>.tmpl, ready to edit and load with LoadTemplates.
+// It returns the paths written.
+func DumpTemplates(dir string) ([]string, error) {
+	var written []string
+	err := fs.WalkDir(templateFS, "templates", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(templateFS, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(p, "templates/")))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+		written = append(written, target)
+		return nil
+	})
+	return written, err
+}
+
+// render executes a language's template for a construct.
+func (t *Templates) render(langID, construct string, data any) (string, error) {
+	set, ok := t.byLang[langID]
+	if !ok {
+		return "", fmt.Errorf("no templates for language %s", langID)
+	}
+	var sb strings.Builder
+	if err := set.ExecuteTemplate(&sb, construct, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// isCustom reports whether an override replaced a language's template for
+// a construct.
+func (t *Templates) isCustom(langID, construct string) bool {
+	return t.custom[langID+"/"+construct]
+}
+
+// templateFuncs is the helper library available to every template.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Case conversion
+		"pascal": toPascalCase,
+		"camel":  toCamelCase,
+		"snake":  toSnakeCase,
+		"lower":  strings.ToLower,
+		"upper":  strings.ToUpper,
+
+		// Types: {{mapType .Type "go"}}, {{defaultValue .ReturnType "java"}}
+		"mapType":      mapType,
+		"defaultValue": defaultValue,
+		"schemaName":   tsSchemaName,
+
+		// Doc comments: {{comment "//" .Doc}} prefixes each line
+		"comment": commentLines,
+
+		// Lists
+		"join": func(list []string, sep string) string { return strings.Join(list, sep) },
+		"last": func(i int, list any) bool { return i == reflect.ValueOf(list).Len()-1 },
+	}
+}
+
+// typeView is the data struct, enum, interface, union and alias templates
+// render: the spec type with its fields' types and attributes resolved for
+// the language.
+type typeView struct {
+	specparser.SpecType
+
+	// Lang is the language ID
+	Lang string
+
+	// Fields are the type's fields, resolved for the language
+	Fields []fieldView
+
+	// Values are an enum's members with their identifiers
+	Values []valueView
+
+	// Methods are an interface's method signatures, resolved for the
+	// language
+	Methods []functionView
+
+	// Variants are a union's members, resolved for the language
+	Variants []variantView
+
+	// Target is the type an alias stands for in the language
+	Target string
+
+	// Unions are the unions in the type's module that a Java or C# struct
+	// is a member of, and so implements
+	Unions []string
+
+	// Aliased is set when a Python model has fields with wire-name aliases
+	Aliased bool
+
+	// Extra is generated code that belongs with the type, such as its
+	// schema and validation
+	Extra string
+}
+
+// fieldView is a struct field resolved for a language.
+type fieldView struct {
+	specparser.SpecField
+
+	// Ident is the field's identifier in the language
+	Ident string
+
+	// Type is the field's type in the language
+	Type string
+
+	// Attribute is the field's serialization tag or attribute, if any
+	Attribute string
+
+	// Default is the literal of the field's default value, if any
+	Default string
+
+	// Declaration is a Python field's full declaration
+	Declaration string
+
+	// Getter and Setter are a Java field's accessors
+	Getter, Setter string
+}
+
+// variantView is a union member resolved for a language.
+type variantView struct {
+	specparser.SpecField
+
+	// Type is the member's type in the language
+	Type string
+
+	// Ident is the type that joins the union: the member's own type, or
+	// for a Wrapper, the named type declared for it
+	Ident string
+
+	// Wrapper is set when the member is not a struct of the union's module,
+	// so Go, Java and C# declare a named type wrapping it
+	Wrapper bool
+}
+
+// valueView is an enum member with its identifier in a language.
+type valueView struct {
+	specparser.SpecEnumValue
+
+	// Ident is the member's identifier in the language
+	Ident string
+}
+
+// functionView is the data function templates render.
+type functionView struct {
+	specparser.SpecFunction
+
+	// Lang is the language ID
+	Lang string
+
+	// Ident is the function's identifier in the language
+	Ident string
+
+	// Doc are the doc comment's lines: the description, then the declared
+	// errors
+	Doc []string
+
+	// Params are the parameters with their types in the language
+	Params []paramView
+
+	// ReturnType is the return type in the language, "" for none
+	ReturnType string
+
+	// Results are the Go return types, which fakes name so they can
+	// return zero values
+	Results []string
+
+	// Result is the value the stub returns, "" when it returns nothing
+	Result string
+
+	// Throws are the exceptions a Java method declares
+	Throws []string
+}
+
+// paramView is a parameter with its type in a language.
+type paramView struct {
+	Name  string
+	Ident string
+	Type  string
+
+	// Optional is set for a parameter the spec marks optional
+	Optional bool
+}
+
+// testView is the data test templates render.
+type testView struct {
+	specparser.SpecTest
+
+	// Lang is the language ID
+	Lang string
+
+	// FuncName is the test's name as PascalCase (e.g., "CreatesAUser")
+	FuncName string
+}
+
+// newTypeView resolves a type's fields and companion code for a language.
+func newTypeView(t specparser.SpecType, types []specparser.SpecType, lang languages.Language) typeView {
+	langID := lang.ID
+	v := typeView{SpecType: t, Lang: langID}
+	for _, ev := range t.Values {
+		v.Values = append(v.Values, valueView{SpecEnumValue: ev, Ident: valueIdent(langID, ev.Name)})
+	}
+	for _, m := range t.Methods {
+		v.Methods = append(v.Methods, newMethodView(m, langID))
+	}
+	switch t.Kind {
+	case "interface", "enum":
+		return v
+	case "alias":
+		target := t.AliasOf
+		if target == "" {
+			// An alias of an unknown type, such as a custom GraphQL scalar
+			target = "any"
+		}
+		v.Target = mapType(target, langID)
+		if langID == "typescript" {
+			v.Extra = tsAliasSchema(t, target, types)
+		}
+		return v
+	case "union":
+		for _, f := range t.Fields {
+			vv := variantView{SpecField: f, Type: mapType(f.Type, langID)}
+			if member, ok := findType(types, f.Type); ok && member.Kind == "struct" && member.Module == t.Module {
+				vv.Ident = vv.Type
+			} else {
+				vv.Ident, vv.Wrapper = t.Name+toPascalCase(f.Name), true
+			}
+			v.Variants = append(v.Variants, vv)
+		}
+		if langID == "typescript" {
+			v.Extra = tsUnionSchema(t, types)
+		}
+		return v
+	}
+	for _, u := range types {
+		if u.Kind != "union" || u.Module != t.Module {
+			continue
+		}
+		for _, f := range u.Fields {
+			if strings.EqualFold(f.Type, t.Name) {
+				v.Unions = append(v.Unions, u.Name)
+				break
+			}
+		}
+	}
+
+	for _, f := range t.Fields {
+		fv := fieldView{SpecField: f, Ident: fieldIdent(langID, f.Name), Type: mapType(fieldType(f), langID)}
+		switch langID {
+		case "go":
+			fv.Attribute = goStructTag(t, f)
+		case "typescript":
+			fv.Type = mapType(f.Type, langID)
+		case "python":
+			var alias bool
+			fv.Declaration, alias = pythonField(t, f)
+			v.Aliased = v.Aliased || alias
+		case "java":
+			fv.Getter, fv.Setter = javaAccessor("get", f.Name), javaAccessor("set", f.Name)
+			fallthrough
+		default:
+			fv.Attribute = fieldAttribute(langID, t, f)
+			if lit, ok := defaultLiteral(langID, f); ok {
+				fv.Default = lit
+			}
+		}
+		v.Fields = append(v.Fields, fv)
+	}
+
+	switch langID {
+	case "typescript":
+		v.Extra = tsSchema(t, types)
+		if hasValidation(t) {
+			v.Extra += tsValidation(t, lang)
+		}
+	case "python":
+		v.Extra = pythonValidation(t, lang)
+	}
+	if hasValidation(t) {
+		switch langID {
+		case "go":
+			v.Extra = goPatternVars(t) + goValidation(t, lang)
+		case "java":
+			v.Extra = javaValidation(t, lang)
+		case "rust":
+			v.Extra = rustValidation(t, lang)
+		case "csharp":
+			v.Extra = csharpValidation(t, lang)
+		}
+	}
+	return v
+}
+
+// newFunctionView resolves a function's doc comment, parameters and return
+// type for a language.
+func newFunctionView(f specparser.SpecFunction, langID string) functionView {
+	v := functionView{SpecFunction: f, Lang: langID, Ident: moduleFuncIdent(langID, f), Doc: functionDoc(f, langID)}
+	for _, p := range f.Parameters {
+		v.Params = append(v.Params, paramView{Name: p.Name, Ident: paramIdent(langID, p.Name), Type: mapType(p.Type, langID), Optional: !p.Required})
+	}
+
+	var returnType string
+	if len(f.Returns) > 0 {
+		returnType = mapType(f.Returns[0].Type, langID)
+	}
+
+	switch langID {
+	case "go":
+		var returns []string
+		for _, r := range f.Returns {
+			returns = append(returns, mapType(r.Type, langID))
+		}
+		// Add error return if function has errors
+		if len(f.Errors) > 0 && !containsError(returns) {
+			returns = append(returns, "error")
+		}
+		v.Results = returns
+		switch {
+		case len(returns) == 1:
+			v.ReturnType = returns[0]
+		case len(returns) > 1:
+			v.ReturnType = "(" + strings.Join(returns, ", ") + ")"
+		}
+		var zeros []string
+		for _, r := range returns {
+			zeros = append(zeros, defaultValue(r, langID))
+		}
+		v.Result = strings.Join(zeros, ", ")
+
+	case "typescript":
+		v.ReturnType = "void"
+		if returnType != "" {
+			v.ReturnType = returnType
+		}
+		if f.IsAsync {
+			v.ReturnType = "Promise<" + v.ReturnType + ">"
+		}
+		if v.ReturnType != "void" && v.ReturnType != "Promise<void>" {
+			v.Result = defaultValue(v.ReturnType, langID)
+		}
+
+	case "python":
+		v.ReturnType = returnType
+
+	case "java":
+		v.ReturnType = "void"
+		if returnType != "" {
+			v.ReturnType = returnType
+			v.Result = defaultValue(returnType, langID)
+		}
+		// Declare the catalog exceptions the method throws
+		for _, e := range functionErrors(f) {
+			v.Throws = append(v.Throws, errorClass(e.Name, "Exception"))
+		}
+
+	case "rust":
+		// A Result over the declared errors when it has any
+		v.ReturnType = rustReturnType(f)
+
+	case "csharp":
+		v.ReturnType = "void"
+		if returnType != "" {
+			v.ReturnType = returnType
+		}
+		if f.IsAsync {
+			if returnType != "" {
+				v.ReturnType = "Task<" + returnType + ">"
+			} else {
+				v.ReturnType = "Task"
+			}
+		}
+	}
+	return v
+}
+
+// newMethodView resolves an interface method for a language. Methods are
+// named like functions, except that Go exports them so other packages can
+// implement the interface.
+func newMethodView(m specparser.SpecFunction, langID string) functionView {
+	v := newFunctionView(m, langID)
+	v.Ident = methodIdent(langID, m.Name)
+	if langID == "go" && m.Description != "" {
+		v.Doc[0] = v.Ident + " " + m.Description
+	}
+	return v
+}
+
+// functionDoc returns the lines of a function's doc comment: its
+// description, then the errors it declares.
+func functionDoc(f specparser.SpecFunction, langID string) []string {
+	var doc []string
+	if langID == "python" {
+		// The docstring summary is required before a Raises section
+		raises := errorDocs(f, langID)
+		if len(raises) == 0 {
+			if f.Description != "" {
+				doc = append(doc, f.Description)
+			}
+			return doc
+		}
+		summary := f.Description
+		if summary == "" {
+			summary = "Can fail with declared errors."
+		}
+		return append([]string{summary, ""}, raises...)
+	}
+
+	if f.Description != "" {
+		doc = append(doc, f.Description)
+	}
+	if errs := errorDocs(f, langID); len(errs) > 0 {
+		if len(doc) > 0 && langID != "typescript" && langID != "csharp" {
+			doc = append(doc, "")
+		}
+		doc = append(doc, errs...)
+	}
+	if langID == "go" && len(doc) > 0 {
+		ident := moduleFuncIdent(langID, f)
+		if f.Description != "" {
+			doc[0] = ident + " " + doc[0]
+		} else {
+			doc = append([]string{ident + " can fail with declared errors.", ""}, doc...)
+		}
+	}
+	return doc
+}
+
+// newTestView names a test for a language.
+func newTestView(t specparser.SpecTest, langID string) testView {
+	return testView{SpecTest: t, Lang: langID, FuncName: toPascalCase(strings.ReplaceAll(t.Name, " ", "_"))}
+}
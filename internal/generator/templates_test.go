@@ -0,0 +1,300 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const templatedSpec = "# Shop\n\n" +
+	"## Types\n\n" +
+	"### Item (struct)\n\n" +
+	"An item for sale.\n\n" +
+	"- name: string - Display name\n" +
+	"- price: int - Price in cents\n\n" +
+	"## Functions\n\n" +
+	"### findItem\n\n" +
+	"Looks up an item.\n\n" +
+	"**Parameters**\n- `name`: `string`\n\n" +
+	"**Returns** `Item`\n"
+
+func TestDefaultTemplates(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(templatedSpec, "shop.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected string
+	}{
+		{"go", "types.go", "// An item for sale.\ntype Item struct {\n\tName  string `json:\"name\"`\n"},
+		{"go", "service.go", "// findItem Looks up an item.\nfunc findItem(name string) Item {\n\t// TODO: Implement\n"},
+		{"typescript", "src/service.ts", "export function findItem(name: string): Item {"},
+		{"python", "src/service.py", "def find_item(name: str) -> Item:\n    \"\"\"Looks up an item.\"\"\"\n"},
+		{"java", "src/main/java/shop/Service.java", "    private static Item findItem(String name) {"},
+		{"rust", "src/types.rs", "pub struct Item {\n    pub name: String,\n    pub price: i32,\n}"},
+		{"csharp", "src/Types.cs", "        public string Name { get; set; }"},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), map[string][]string{tt.path: {tt.expected}})
+		})
+	}
+}
+
+func TestDefaultTemplatesBuild(t *testing.T) {
+	checkBuilds(t, templatedSpec, "go", "python")
+}
+
+func TestLoadTemplates(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(templatedSpec, "shop.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	dir := t.TempDir()
+	custom := "type {{.Name}} struct {\n{{- range .Fields}}\n\t{{pascal .Name}} {{.Type}} // {{.Description}}\n{{- end}}\n}\n"
+	if err := os.MkdirAll(filepath.Join(dir, "go"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go", "struct.tmpl"), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	if err := gen.UseTemplates(dir); err != nil {
+		t.Fatalf("UseTemplates() error: %v", err)
+	}
+	files, err := gen.Generate(spec, "go", t.TempDir())
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	contents := make(map[string]string)
+	for _, f := range files {
+		contents[f.Path] = f.Content
+	}
+	if expected := "type Item struct {\n\tName  string // Display name\n\tPrice int    // Price in cents\n}\n"; !strings.Contains(contents["types.go"], expected) {
+		t.Errorf("Expected the custom struct template, got:\n%s", contents["types.go"])
+	}
+	// Constructs without an override keep the default template
+	if !strings.Contains(contents["service.go"], "func findItem(name string) Item {") {
+		t.Errorf("Expected the default function template, got:\n%s", contents["service.go"])
+	}
+}
+
+func TestLoadTemplatesErrors(t *testing.T) {
+	if _, err := LoadTemplates(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing template directory")
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "rust"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "rust", "enum.tmpl"), []byte("{{range .Values}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplates(dir); err == nil || !strings.Contains(err.Error(), "rust/enum") {
+		t.Errorf("Expected a parse error naming rust/enum, got %v", err)
+	}
+
+	// A template that parses but fails to execute fails the generation
+	if err := os.WriteFile(filepath.Join(dir, "rust", "enum.tmpl"), []byte("{{.Missing}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gen := NewGenerator(languages.NewRegistry())
+	if err := gen.UseTemplates(dir); err != nil {
+		t.Fatalf("UseTemplates() error: %v", err)
+	}
+	spec, _ := specparser.NewParser().Parse("# X\n\n## Types\n\n### Color (enum)\n\n- Red\n", "x.spec.md")
+	if _, err := gen.Generate(spec, "rust", t.TempDir()); err == nil || !strings.Contains(err.Error(), "rust enum") {
+		t.Errorf("Expected a render error for the rust enum template, got %v", err)
+	}
+}
+
+func TestDumpTemplates(t *testing.T) {
+	dir := t.TempDir()
+	files, err := DumpTemplates(dir)
+	if err != nil {
+		t.Fatalf("DumpTemplates() error: %v", err)
+	}
+	if expected := len(templateLanguages) * len(templateConstructs); len(files) != expected {
+		t.Errorf("Expected %d templates, got %d", expected, len(files))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "csharp", "function.tmpl")); err != nil {
+		t.Errorf("Expected csharp/function.tmpl to be written: %v", err)
+	}
+
+	// The dumped templates load back as a complete override set
+	if _, err := LoadTemplates(dir); err != nil {
+		t.Errorf("LoadTemplates() of dumped templates error: %v", err)
+	}
+}
+
+const storeInterfaceSpec = "# Store\n\n" +
+	"## Types\n\n" +
+	"### User (struct)\n\n" +
+	"- id: string - Identifier\n\n" +
+	"### UserStore (interface)\n\n" +
+	"Persists users.\n\n" +
+	"- `find(id: string): User` - Looks up a user\n" +
+	"- `save(user: User, overwrite?: bool)`\n" +
+	"- `async list(limit: int = 10): List<User>`\n"
+
+func TestInterfaceTemplates(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(storeInterfaceSpec, "store.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected []string
+	}{
+		{"go", "types.go", []string{
+			"type UserStore interface {\n\t// Find Looks up a user\n\tFind(id string) User\n",
+			"\tSave(user User, overwrite bool)\n",
+		}},
+		{"typescript", "src/types.ts", []string{
+			"  /** Looks up a user */\n  find(id: string): User;\n",
+			"  save(user: User, overwrite?: boolean): void;\n",
+			"  list(limit?: number): Promise<User[]>;\n",
+		}},
+		{"python", "src/types.py", []string{
+			"class UserStore(Protocol):\n",
+			"    def find(self, id: str) -> User:\n        \"\"\"Looks up a user\"\"\"\n        ...\n",
+			"    async def list(self, limit: int) -> List[User]:\n",
+		}},
+		{"java", "src/main/java/store/Types.java", []string{
+			"    // Looks up a user\n    User find(String id);\n",
+			"    void save(User user, boolean overwrite);\n",
+		}},
+		{"rust", "src/types.rs", []string{
+			"pub trait UserStore {\n    /// Looks up a user\n    fn find(&self, id: String) -> User;\n",
+			"    async fn list(&self, limit: i32) -> Vec<User>;\n",
+		}},
+		{"csharp", "src/Types.cs", []string{
+			"        User Find(string id);\n",
+			"        Task<List<User>> List(int limit);\n",
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), map[string][]string{tt.path: tt.expected})
+		})
+	}
+}
+
+func TestInterfacesBuild(t *testing.T) {
+	checkBuilds(t, storeInterfaceSpec, "go", "python")
+}
+
+const searchSpec = "# Search\n\n" +
+	"## Types\n\n" +
+	"### Timestamp (alias)\n\n" +
+	"**Alias of**: `datetime`\n\n" +
+	"### User (struct)\n\n" +
+	"- id: string - Identifier\n\n" +
+	"### Post (struct)\n\n" +
+	"- title: string - Title\n\n" +
+	"### SearchResult (union)\n\n" +
+	"| Variant | Type | Description |\n" +
+	"|---------|------|-------------|\n" +
+	"| User | User | |\n" +
+	"| Post | Post | |\n" +
+	"| Tag | string | |\n"
+
+func TestUnionAndAliasTemplates(t *testing.T) {
+	spec, err := specparser.NewParser().Parse(searchSpec, "search.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		language string
+		path     string
+		expected []string
+	}{
+		{"go", "types.go", []string{
+			"type Timestamp = time.Time\n",
+			"type SearchResult interface {\n\tisSearchResult()\n}\n",
+			"type SearchResultTag string\n",
+			"func (User) isSearchResult()",
+			"func (SearchResultTag) isSearchResult()",
+		}},
+		{"typescript", "src/types.ts", []string{
+			"export type Timestamp = Date;\n",
+			"export type SearchResult = User | Post | string;\n",
+			"z.union([z.lazy(() => UserSchema), z.lazy(() => PostSchema), z.string()])",
+		}},
+		{"python", "src/types.py", []string{
+			"Timestamp = datetime\n",
+			"class Post(BaseModel):\n    title: str\n# rpg:end type:Post\n\n# rpg:begin type:Timestamp",
+			"SearchResult = Union[User, Post, str]\n",
+		}},
+		{"java", "src/main/java/search/Types.java", []string{
+			"public record Timestamp(@JsonValue LocalDateTime value) {}\n",
+			"public class User implements SearchResult {\n",
+			"@JsonSubTypes.Type(SearchResult.Tag.class)",
+			"    record Tag(@JsonValue String value) implements SearchResult {}\n",
+		}},
+		{"rust", "src/types.rs", []string{
+			"pub type Timestamp = chrono::DateTime<chrono::Utc>;\n",
+			"#[serde(untagged)]\npub enum SearchResult {\n    User(User),\n    Post(Post),\n    Tag(String),\n}\n",
+		}},
+		{"csharp", "src/Types.cs", []string{
+			"    public readonly record struct Timestamp(DateTime Value);\n",
+			"    public class User : SearchResult\n",
+			"    public interface SearchResult\n",
+			"    public readonly record struct SearchResultTag(string Value) : SearchResult;\n",
+		}},
+	}
+
+	gen := NewGenerator(languages.NewRegistry())
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			expectGenerated(t, generatedFiles(t, gen, spec, tt.language), map[string][]string{tt.path: tt.expected})
+		})
+	}
+}
+
+func TestUnionsAndAliasesBuild(t *testing.T) {
+	checkBuilds(t, searchSpec, "go", "python")
+}
+
+func TestGoStubResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		function specparser.SpecFunction
+		expected string
+	}{
+		{"no results", specparser.SpecFunction{Name: "ping"}, ""},
+		{"error only", specparser.SpecFunction{Name: "ping", Errors: []specparser.SpecError{{Type: "Timeout"}}}, "nil"},
+		{"value", specparser.SpecFunction{Name: "count", Returns: []specparser.SpecReturn{{Type: "int"}}}, "0"},
+		{"value and error", specparser.SpecFunction{
+			Name:    "create",
+			Returns: []specparser.SpecReturn{{Type: "string"}},
+			Errors:  []specparser.SpecError{{Type: "Conflict"}},
+		}, `"", nil`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newFunctionView(tt.function, "go").Result; got != tt.expected {
+				t.Errorf("Expected result %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
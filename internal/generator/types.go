@@ -108,6 +108,63 @@ type GeneratedFile struct {
 
 	// Elements lists the spec elements this file implements
 	Elements []string `json:"elements,omitempty"`
+
+	// Diagnostics lists the syntax errors found in the generated code
+	Diagnostics []string `json:"diagnostics,omitempty"`
+}
+
+// RegenerationResult summarizes an incremental regeneration.
+type RegenerationResult struct {
+	// OutputDir is the directory that was regenerated
+	OutputDir string `json:"outputDir"`
+
+	// Files lists the outcome for each generated file
+	Files []RegeneratedFile `json:"files"`
+
+	// Conflicts lists edits that could not be merged automatically
+	Conflicts []MergeConflict `json:"conflicts,omitempty"`
+}
+
+// RegeneratedFile describes the outcome of regenerating a single file.
+type RegeneratedFile struct {
+	// Path is the relative path within the output directory
+	Path string `json:"path"`
+
+	// Status is created, updated, unchanged, or conflict
+	Status string `json:"status"`
+
+	// UserModified indicates the file was edited since it was last generated
+	UserModified bool `json:"userModified"`
+
+	// ChangedElements lists regions rewritten because their spec changed ("<category>:<name>")
+	ChangedElements []string `json:"changedElements,omitempty"`
+
+	// RemovedElements lists regions whose element was removed from the spec
+	RemovedElements []string `json:"removedElements,omitempty"`
+}
+
+// MergeConflict describes overlapping generated and hand-written changes.
+type MergeConflict struct {
+	// Path is the relative path of the conflicting file
+	Path string `json:"path"`
+
+	// Element is the protected region the conflict falls in, if any
+	Element string `json:"element,omitempty"`
+
+	// Message explains the conflict and how to resolve it
+	Message string `json:"message"`
+
+	// Base is the last generated text
+	Base string `json:"base,omitempty"`
+
+	// Ours is the local text, which was kept
+	Ours string `json:"ours"`
+
+	// Theirs is the regenerated text that was not applied
+	Theirs string `json:"theirs"`
+
+	// SpecHash is the new spec hash to set on the region once resolved
+	SpecHash string `json:"specHash,omitempty"`
 }
 
 // ParityReportSummary provides a summary of parity analysis.
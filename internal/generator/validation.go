@@ -0,0 +1,797 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/languages"
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// formatPatterns maps the formats a constraint can name to the regular
+// expression a value of that format matches.
+var formatPatterns = map[string]string{
+	"email": `^[^@\s]+@[^@\s]+\.[^@\s]+$`,
+	"url":   `^https?://[^\s/$.?#][^\s]*$`,
+	"uuid":  `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`,
+}
+
+// validationCheck is one constraint check: the condition under which a
+// value fails it and the message reported when it does.
+type validationCheck struct {
+	cond    string
+	message string
+}
+
+// hasValidation reports whether a struct gets a constructor and validate
+// method: any of its fields has constraints or a default.
+func hasValidation(t specparser.SpecType) bool {
+	for _, f := range t.Fields {
+		if wireSkipped(f) {
+			continue
+		}
+		if len(f.Constraints) > 0 {
+			return true
+		}
+		if _, ok := defaultLiteral("go", f); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// anyValidation reports whether any struct of a spec validates its fields.
+func anyValidation(types []specparser.SpecType) bool {
+	for _, t := range types {
+		if isStructKind(t.Kind) && hasValidation(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesPatterns reports whether any struct checks a pattern or format.
+func usesPatterns(types []specparser.SpecType) bool {
+	for _, t := range types {
+		for _, f := range t.Fields {
+			for _, c := range f.Constraints {
+				if (c.Kind == "pattern" || c.Kind == "format") && isStructKind(t.Kind) && !wireSkipped(f) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// baseExpr returns a field type with Optional and pointers unwrapped.
+func baseExpr(fieldType string) *typeexpr.Expr {
+	e, err := typeexpr.Parse(fieldType)
+	if err != nil {
+		return nil
+	}
+	for e.Kind == typeexpr.Optional || e.Kind == typeexpr.Pointer {
+		e = e.Args[0]
+	}
+	return e
+}
+
+// isFloat reports whether a primitive holds fractional numbers.
+func isFloat(e *typeexpr.Expr) bool {
+	return e.Kind == typeexpr.Primitive && (e.Name == typeexpr.Float || e.Name == typeexpr.Decimal)
+}
+
+// numberLiteral renders a number for comparison with a value of type e.
+func numberLiteral(lang string, e *typeexpr.Expr, v string) string {
+	hasPoint := strings.ContainsAny(v, ".eE")
+	switch {
+	case lang == "java" && e.Name == typeexpr.Decimal:
+		return fmt.Sprintf("new BigDecimal(%q)", v)
+	case lang == "csharp" && e.Name == typeexpr.Decimal:
+		return v + "m"
+	case lang == "java" && e.Name == typeexpr.Int64:
+		return v + "L"
+	case (lang == "rust" || lang == "java") && isFloat(e) && !hasPoint:
+		return v + ".0"
+	}
+	return v
+}
+
+// stringLiteral quotes a string for a language; Go's escapes are valid in
+// each of them.
+func stringLiteral(lang, s string) string {
+	if lang == "csharp" {
+		return `@"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return strconv.Quote(s)
+}
+
+// defaultLiteral renders a field's default value, for string, number and
+// boolean fields.
+func defaultLiteral(lang string, f specparser.SpecField) (string, bool) {
+	e := baseExpr(f.Type)
+	if f.Default == "" || e == nil || e.Kind != typeexpr.Primitive {
+		return "", false
+	}
+	switch e.Name {
+	case typeexpr.String:
+		s := f.Default
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			s = unquoted
+		}
+		if lang == "rust" {
+			return strconv.Quote(s) + ".to_string()", true
+		}
+		return strconv.Quote(s), true
+	case typeexpr.Int, typeexpr.Int64, typeexpr.Float, typeexpr.Decimal:
+		if _, err := strconv.ParseFloat(f.Default, 64); err != nil {
+			return "", false
+		}
+		return numberLiteral(lang, e, f.Default), true
+	case typeexpr.Bool:
+		b, err := strconv.ParseBool(f.Default)
+		if err != nil {
+			return "", false
+		}
+		if lang == "python" && b {
+			return "True", true
+		} else if lang == "python" {
+			return "False", true
+		}
+		return strconv.FormatBool(b), true
+	}
+	return "", false
+}
+
+// constraintChecks renders the checks of a field's constraints on the value
+// expression acc. Go matches patterns with package-level variables named by
+// goPatternVar.
+func constraintChecks(lang string, t specparser.SpecType, f specparser.SpecField, acc string) []validationCheck {
+	e := baseExpr(f.Type)
+	subject := specparser.ConstraintSubject(f.Type)
+	if e == nil {
+		return nil
+	}
+
+	var checks []validationCheck
+	for _, c := range f.Constraints {
+		switch c.Kind {
+		case "min", "max":
+			if subject != "number" {
+				continue
+			}
+			op, bound := "<", "at least"
+			if c.Kind == "max" {
+				op, bound = ">", "at most"
+			}
+			lit := numberLiteral(lang, e, c.Value)
+			cond := fmt.Sprintf("%s %s %s", acc, op, lit)
+			if lang == "java" && e.Name == typeexpr.Decimal {
+				cond = fmt.Sprintf("%s.compareTo(%s) %s 0", acc, lit, op)
+			}
+			checks = append(checks, validationCheck{cond, fmt.Sprintf("must be %s %s", bound, c.Value)})
+
+		case "minLength", "maxLength":
+			if subject != "string" && subject != "collection" {
+				continue
+			}
+			op, bound := "<", "at least"
+			if c.Kind == "maxLength" {
+				op, bound = ">", "at most"
+			}
+			unit := "characters"
+			if subject == "collection" {
+				unit = "items"
+			}
+			cond := fmt.Sprintf("%s %s %s", lengthOf(lang, e, subject, acc), op, c.Value)
+			checks = append(checks, validationCheck{cond, fmt.Sprintf("must have %s %s %s", bound, c.Value, unit)})
+
+		case "pattern", "format":
+			if subject != "string" {
+				continue
+			}
+			pattern, message := c.Value, "must match "+c.Value
+			if c.Kind == "format" {
+				if pattern = formatPatterns[c.Value]; pattern == "" {
+					continue
+				}
+				message = "must be a valid " + c.Value
+			}
+			checks = append(checks, validationCheck{notMatching(lang, goPatternVar(t, f, c.Kind), pattern, acc), message})
+
+		case "oneOf":
+			if subject != "string" && subject != "number" {
+				continue
+			}
+			values := make([]string, len(c.Values))
+			for i, v := range c.Values {
+				if subject == "string" {
+					values[i] = stringLiteral(lang, v)
+				} else {
+					values[i] = numberLiteral(lang, e, v)
+				}
+			}
+			list := strings.Join(values, ", ")
+			var cond string
+			switch lang {
+			case "go":
+				cond = fmt.Sprintf("!slices.Contains([]%s{%s}, %s)", e.Render("go"), list, acc)
+			case "typescript":
+				cond = fmt.Sprintf("![%s].includes(%s)", list, acc)
+			case "python":
+				cond = fmt.Sprintf("%s not in [%s]", acc, list)
+			case "java":
+				cond = fmt.Sprintf("!List.of(%s).contains(%s)", list, acc)
+			case "rust":
+				if subject == "string" {
+					cond = fmt.Sprintf("![%s].contains(&%s.as_str())", list, acc)
+				} else {
+					cond = fmt.Sprintf("![%s].contains(&%s)", list, acc)
+				}
+			case "csharp":
+				cond = fmt.Sprintf("!new[] { %s }.Contains(%s)", list, acc)
+			}
+			checks = append(checks, validationCheck{cond, "must be one of " + strings.Join(c.Values, ", ")})
+		}
+	}
+	return checks
+}
+
+// lengthOf renders the length of a string (in code points) or collection.
+func lengthOf(lang string, e *typeexpr.Expr, subject, acc string) string {
+	if subject == "string" {
+		switch lang {
+		case "go":
+			return fmt.Sprintf("utf8.RuneCountInString(%s)", acc)
+		case "typescript":
+			return fmt.Sprintf("[...%s].length", acc)
+		case "java":
+			return fmt.Sprintf("%s.codePointCount(0, %s.length())", acc, acc)
+		case "rust":
+			return acc + ".chars().count()"
+		case "csharp":
+			return acc + ".EnumerateRunes().Count()"
+		}
+		return fmt.Sprintf("len(%s)", acc)
+	}
+	switch lang {
+	case "go", "python":
+		return fmt.Sprintf("len(%s)", acc)
+	case "typescript":
+		switch e.Kind {
+		case typeexpr.Set:
+			return acc + ".size"
+		case typeexpr.Map:
+			return fmt.Sprintf("Object.keys(%s).length", acc)
+		}
+		return acc + ".length"
+	case "java":
+		return acc + ".size()"
+	case "rust":
+		return acc + ".len()"
+	}
+	return acc + ".Count"
+}
+
+// notMatching renders the condition that acc does not match a pattern.
+func notMatching(lang, goVar, pattern, acc string) string {
+	switch lang {
+	case "go":
+		return fmt.Sprintf("!%s.MatchString(%s)", goVar, acc)
+	case "typescript":
+		return fmt.Sprintf("!new RegExp(%s).test(%s)", strconv.Quote(pattern), acc)
+	case "python":
+		return fmt.Sprintf("re.search(%s, %s) is None", strconv.Quote(pattern), acc)
+	case "java":
+		return fmt.Sprintf("!Pattern.compile(%s).matcher(%s).find()", strconv.Quote(pattern), acc)
+	case "rust":
+		return fmt.Sprintf("!regex::Regex::new(%s).unwrap().is_match(&%s)", rawString(pattern), acc)
+	}
+	return fmt.Sprintf("!Regex.IsMatch(%s, %s)", acc, stringLiteral("csharp", pattern))
+}
+
+// goPatternVar names the package-level regexp of a Go pattern or format check.
+func goPatternVar(t specparser.SpecType, f specparser.SpecField, kind string) string {
+	return lowerFirst(t.Name) + toPascalCase(f.Name) + toPascalCase(kind)
+}
+
+// failStatement renders how a validate method reports a broken constraint,
+// following the language's error style: Go returns an error, Rust an Err
+// and the others throw.
+func failStatement(lang languages.Language, field, message string) string {
+	switch lang.ErrorPatterns.Style {
+	case "tuple":
+		return fmt.Sprintf("return &ValidationError{Field: %q, Message: %q}", field, message)
+	case "result":
+		return fmt.Sprintf("return Err(ValidationError::new(%q, %q));", field, message)
+	}
+	switch lang.ID {
+	case "python":
+		return fmt.Sprintf("raise ValueError(%q)", field+" "+message)
+	case "typescript":
+		return fmt.Sprintf("throw new ValidationError(%q, %q);", field, message)
+	}
+	return fmt.Sprintf("throw new ValidationException(%q, %q);", field, message)
+}
+
+// writeChecks writes a field's checks as if-statements at an indent.
+func writeChecks(sb *strings.Builder, lang languages.Language, field string, checks []validationCheck, indent string) {
+	for _, c := range checks {
+		fail := failStatement(lang, field, c.message)
+		switch lang.ID {
+		case "python":
+			sb.WriteString(fmt.Sprintf("%sif %s:\n%s    %s\n", indent, c.cond, indent, fail))
+		case "go":
+			sb.WriteString(fmt.Sprintf("%sif %s {\n%s\t%s\n%s}\n", indent, c.cond, indent, fail, indent))
+		case "rust":
+			sb.WriteString(fmt.Sprintf("%sif %s {\n%s    %s\n%s}\n", indent, c.cond, indent, fail, indent))
+		case "csharp":
+			sb.WriteString(fmt.Sprintf("%sif (%s)\n%s{\n%s    %s\n%s}\n", indent, c.cond, indent, indent, fail, indent))
+		default:
+			sb.WriteString(fmt.Sprintf("%sif (%s) {\n%s  %s\n%s}\n", indent, c.cond, indent, fail, indent))
+		}
+	}
+}
+
+// constructorParams returns the fields a constructor takes: the required
+// fields without a default.
+func constructorParams(lang string, t specparser.SpecType) []specparser.SpecField {
+	var params []specparser.SpecField
+	for _, f := range t.Fields {
+		if _, ok := defaultLiteral(lang, f); f.Required && !ok && !wireSkipped(f) {
+			params = append(params, f)
+		}
+	}
+	return params
+}
+
+// ============================================================================
+// Per-language validation
+// ============================================================================
+
+// goValidation renders the NewX constructor and Validate method of a struct.
+func goValidation(t specparser.SpecType, lang languages.Language) string {
+	var sb strings.Builder
+
+	var params, fields, locals []string
+	for _, f := range t.Fields {
+		name := fieldIdent("go", f.Name)
+		goType := mapType(fieldType(f), "go")
+		if lit, ok := defaultLiteral("go", f); ok && !wireSkipped(f) {
+			if strings.HasPrefix(goType, "*") {
+				local := toCamelCase(f.Name) + "Default"
+				locals = append(locals, fmt.Sprintf("\t%s := %s(%s)\n", local, goType[1:], lit))
+				lit = "&" + local
+			}
+			fields = append(fields, fmt.Sprintf("\t\t%s: %s,\n", name, lit))
+		}
+	}
+	for _, f := range constructorParams("go", t) {
+		arg := paramIdent("go", f.Name)
+		params = append(params, fmt.Sprintf("%s %s", arg, mapType(fieldType(f), "go")))
+		fields = append(fields, fmt.Sprintf("\t\t%s: %s,\n", fieldIdent("go", f.Name), arg))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n// New%s returns a %s with the spec's defaults, checked by Validate.\n", t.Name, t.Name))
+	sb.WriteString(fmt.Sprintf("func New%s(%s) (*%s, error) {\n", t.Name, strings.Join(params, ", "), t.Name))
+	sb.WriteString(strings.Join(locals, ""))
+	sb.WriteString(fmt.Sprintf("\tv := &%s{\n%s\t}\n", t.Name, strings.Join(fields, "")))
+	sb.WriteString("\tif err := v.Validate(); err != nil {\n\t\treturn nil, err\n\t}\n\treturn v, nil\n}\n")
+
+	sb.WriteString(fmt.Sprintf("\n// Validate checks %s against the spec's field constraints.\n", t.Name))
+	sb.WriteString(fmt.Sprintf("func (v *%s) Validate() error {\n", t.Name))
+	for _, f := range t.Fields {
+		if len(f.Constraints) == 0 || wireSkipped(f) {
+			continue
+		}
+		name := fieldIdent("go", f.Name)
+		acc, indent := "v."+name, "\t"
+		if !f.Required {
+			if strings.HasPrefix(mapType(fieldType(f), "go"), "*") {
+				acc = "*v." + name
+			}
+			sb.WriteString(fmt.Sprintf("\tif v.%s != nil {\n", name))
+			indent = "\t\t"
+		}
+		writeChecks(&sb, lang, wireName(t, f), constraintChecks("go", t, f, acc), indent)
+		if !f.Required {
+			sb.WriteString("\t}\n")
+		}
+	}
+	sb.WriteString("\treturn nil\n}\n")
+	return sb.String()
+}
+
+// goPatternVars declares the regexps of a struct's pattern and format checks.
+func goPatternVars(t specparser.SpecType) string {
+	var sb strings.Builder
+	for _, f := range t.Fields {
+		if wireSkipped(f) || specparser.ConstraintSubject(f.Type) != "string" {
+			continue
+		}
+		for _, c := range f.Constraints {
+			pattern := c.Value
+			switch c.Kind {
+			case "format":
+				pattern = formatPatterns[c.Value]
+			case "pattern":
+			default:
+				continue
+			}
+			if pattern != "" {
+				sb.WriteString(fmt.Sprintf("var %s = regexp.MustCompile(%s)\n", goPatternVar(t, f, c.Kind), backQuote(pattern)))
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "\n" + sb.String()
+}
+
+// tsValidation renders the validateX and createX functions of a struct.
+func tsValidation(t specparser.SpecType, lang languages.Language) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n/** Checks %s against the spec's field constraints. */\n", t.Name))
+	sb.WriteString(fmt.Sprintf("export function validate%s(value: %s): void {\n", t.Name, t.Name))
+	for _, f := range t.Fields {
+		if len(f.Constraints) == 0 || wireSkipped(f) {
+			continue
+		}
+		acc, indent := "value."+fieldIdent("typescript", f.Name), "  "
+		if !f.Required {
+			sb.WriteString(fmt.Sprintf("  if (%s != null) {\n", acc))
+			indent = "    "
+		}
+		writeChecks(&sb, lang, wireName(t, f), constraintChecks("typescript", t, f, acc), indent)
+		if !f.Required {
+			sb.WriteString("  }\n")
+		}
+	}
+	sb.WriteString("}\n")
+
+	var defaults, defaulted []string
+	for _, f := range t.Fields {
+		if lit, ok := defaultLiteral("typescript", f); ok && !wireSkipped(f) {
+			defaults = append(defaults, fmt.Sprintf("%s: %s, ", fieldIdent("typescript", f.Name), lit))
+			if f.Required {
+				defaulted = append(defaulted, strconv.Quote(fieldIdent("typescript", f.Name)))
+			}
+		}
+	}
+	init := t.Name
+	if len(defaulted) > 0 {
+		keys := strings.Join(defaulted, " | ")
+		init = fmt.Sprintf("Omit<%s, %s> & Partial<Pick<%s, %s>>", t.Name, keys, t.Name, keys)
+	}
+	sb.WriteString(fmt.Sprintf("\n/** Creates %s with the spec's defaults, checked by validate%s. */\n", t.Name, t.Name))
+	sb.WriteString(fmt.Sprintf("export function create%s(init: %s): %s {\n", t.Name, init, t.Name))
+	sb.WriteString(fmt.Sprintf("  const value: %s = { %s...init };\n", t.Name, strings.Join(defaults, "")))
+	sb.WriteString(fmt.Sprintf("  validate%s(value);\n  return value;\n}\n", t.Name))
+	return sb.String()
+}
+
+// pythonValidation renders the model validator of a struct; pydantic runs
+// it on construction and reports failures as a ValidationError.
+func pythonValidation(t specparser.SpecType, lang languages.Language) string {
+	var body strings.Builder
+	for _, f := range t.Fields {
+		if len(f.Constraints) == 0 || wireSkipped(f) {
+			continue
+		}
+		acc, indent := "self."+fieldIdent("python", f.Name), "        "
+		if !f.Required {
+			body.WriteString(fmt.Sprintf("        if %s is not None:\n", acc))
+			indent = "            "
+		}
+		writeChecks(&body, lang, wireName(t, f), constraintChecks("python", t, f, acc), indent)
+	}
+	if body.Len() == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n    @model_validator(mode=\"after\")\n")
+	sb.WriteString(fmt.Sprintf("    def _check_constraints(self) -> %s:\n", t.Name))
+	sb.WriteString("        \"\"\"Checks the spec's field constraints.\"\"\"\n")
+	sb.WriteString(body.String())
+	sb.WriteString("        return self\n")
+	return sb.String()
+}
+
+// javaValidation renders the constructors and validate method of a class.
+func javaValidation(t specparser.SpecType, lang languages.Language) string {
+	var sb strings.Builder
+	if params := constructorParams("java", t); len(params) > 0 {
+		var args []string
+		for _, f := range params {
+			args = append(args, fmt.Sprintf("%s %s", mapType(fieldType(f), "java"), paramIdent("java", f.Name)))
+		}
+		// Jackson constructs through the no-argument constructor
+		sb.WriteString(fmt.Sprintf("    public %s() {}\n\n", t.Name))
+		sb.WriteString(fmt.Sprintf("    public %s(%s) {\n", t.Name, strings.Join(args, ", ")))
+		for _, f := range params {
+			sb.WriteString(fmt.Sprintf("        this.%s = %s;\n", fieldIdent("java", f.Name), paramIdent("java", f.Name)))
+		}
+		sb.WriteString("        validate();\n    }\n\n")
+	}
+
+	sb.WriteString("    /** Checks the spec's field constraints. */\n")
+	sb.WriteString("    public void validate() {\n")
+	for _, f := range t.Fields {
+		if len(f.Constraints) == 0 || wireSkipped(f) {
+			continue
+		}
+		acc, indent := fieldIdent("java", f.Name), "        "
+		if !f.Required {
+			sb.WriteString(fmt.Sprintf("        if (%s != null) {\n", acc))
+			indent = "            "
+		}
+		writeChecks(&sb, lang, wireName(t, f), constraintChecks("java", t, f, acc), indent)
+		if !f.Required {
+			sb.WriteString("        }\n")
+		}
+	}
+	sb.WriteString("    }\n")
+	return sb.String()
+}
+
+// rustValidation renders the new and validate functions of a struct.
+func rustValidation(t specparser.SpecType, lang languages.Language) string {
+	var sb strings.Builder
+	var args, fields []string
+	for _, f := range t.Fields {
+		name := fieldIdent("rust", f.Name)
+		lit, hasDefault := defaultLiteral("rust", f)
+		switch {
+		case wireSkipped(f):
+			fields = append(fields, fmt.Sprintf("            %s: Default::default(),\n", name))
+		case hasDefault && !f.Required:
+			fields = append(fields, fmt.Sprintf("            %s: Some(%s),\n", name, lit))
+		case hasDefault:
+			fields = append(fields, fmt.Sprintf("            %s: %s,\n", name, lit))
+		case !f.Required:
+			fields = append(fields, fmt.Sprintf("            %s: None,\n", name))
+		default:
+			args = append(args, fmt.Sprintf("%s: %s", name, mapType(fieldType(f), "rust")))
+			fields = append(fields, fmt.Sprintf("            %s,\n", name))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\nimpl %s {\n", t.Name))
+	sb.WriteString(fmt.Sprintf("    /// Creates a %s with the spec's defaults, checked by `validate`.\n", t.Name))
+	sb.WriteString(fmt.Sprintf("    pub fn new(%s) -> Result<Self, ValidationError> {\n", strings.Join(args, ", ")))
+	sb.WriteString(fmt.Sprintf("        let value = Self {\n%s        };\n", strings.Join(fields, "")))
+	sb.WriteString("        value.validate()?;\n        Ok(value)\n    }\n\n")
+
+	sb.WriteString("    /// Checks the spec's field constraints.\n")
+	sb.WriteString("    pub fn validate(&self) -> Result<(), ValidationError> {\n")
+	for _, f := range t.Fields {
+		if len(f.Constraints) == 0 || wireSkipped(f) {
+			continue
+		}
+		name := fieldIdent("rust", f.Name)
+		acc, indent := "self."+name, "        "
+		if !f.Required {
+			sb.WriteString(fmt.Sprintf("        if let Some(%s) = &self.%s {\n", name, name))
+			acc, indent = name, "            "
+			if specparser.ConstraintSubject(f.Type) == "number" {
+				acc = "*" + name
+			}
+		}
+		writeChecks(&sb, lang, wireName(t, f), constraintChecks("rust", t, f, acc), indent)
+		if !f.Required {
+			sb.WriteString("        }\n")
+		}
+	}
+	sb.WriteString("        Ok(())\n    }\n}\n")
+	return sb.String()
+}
+
+// csharpValidation renders the constructors and Validate method of a class.
+func csharpValidation(t specparser.SpecType, lang languages.Language) string {
+	var sb strings.Builder
+	if params := constructorParams("csharp", t); len(params) > 0 {
+		var args []string
+		for _, f := range params {
+			args = append(args, fmt.Sprintf("%s %s", mapType(fieldType(f), "csharp"), paramIdent("csharp", f.Name)))
+		}
+		// System.Text.Json constructs through the parameterless constructor
+		sb.WriteString(fmt.Sprintf("\n        public %s() { }\n\n", t.Name))
+		sb.WriteString(fmt.Sprintf("        public %s(%s)\n        {\n", t.Name, strings.Join(args, ", ")))
+		for _, f := range params {
+			sb.WriteString(fmt.Sprintf("            %s = %s;\n", fieldIdent("csharp", f.Name), paramIdent("csharp", f.Name)))
+		}
+		sb.WriteString("            Validate();\n        }\n")
+	}
+
+	sb.WriteString("\n        /// <summary>\n        /// Checks the spec's field constraints.\n        /// </summary>\n")
+	sb.WriteString("        public void Validate()\n        {\n")
+	for _, f := range t.Fields {
+		if len(f.Constraints) == 0 || wireSkipped(f) {
+			continue
+		}
+		acc, indent := fieldIdent("csharp", f.Name), "            "
+		if !f.Required {
+			acc = paramIdent("csharp", f.Name)
+			sb.WriteString(fmt.Sprintf("            if (%s is { } %s)\n            {\n", fieldIdent("csharp", f.Name), acc))
+			indent = "                "
+		}
+		writeChecks(&sb, lang, wireName(t, f), constraintChecks("csharp", t, f, acc), indent)
+		if !f.Required {
+			sb.WriteString("            }\n")
+		}
+	}
+	sb.WriteString("        }\n")
+	return sb.String()
+}
+
+// validationErrorType renders the error type validate methods report.
+// Python reports ValueError through pydantic and needs none.
+func validationErrorType(langID string) string {
+	switch langID {
+	case "go":
+		return `// ValidationError reports a field value that breaks a spec constraint.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+`
+	case "typescript":
+		return `/** Thrown when a field value breaks a spec constraint. */
+export class ValidationError extends Error {
+  constructor(
+    public readonly field: string,
+    message: string,
+  ) {
+    super(` + "`${field} ${message}`" + `);
+    this.name = "ValidationError";
+  }
+}
+`
+	case "java":
+		return `/** Thrown when a field value breaks a spec constraint. */
+public class ValidationException extends IllegalArgumentException {
+    private final String field;
+
+    public ValidationException(String field, String message) {
+        super(field + " " + message);
+        this.field = field;
+    }
+
+    public String getField() { return field; }
+}
+`
+	case "rust":
+		return `/// A field value that breaks a spec constraint.
+#[derive(Debug, Clone, PartialEq)]
+pub struct ValidationError {
+    pub field: String,
+    pub message: String,
+}
+
+impl ValidationError {
+    fn new(field: &str, message: &str) -> Self {
+        Self { field: field.to_string(), message: message.to_string() }
+    }
+}
+
+impl std::fmt::Display for ValidationError {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        write!(f, "{} {}", self.field, self.message)
+    }
+}
+
+impl std::error::Error for ValidationError {}
+`
+	case "csharp":
+		return `    /// <summary>
+    /// Thrown when a field value breaks a spec constraint.
+    /// </summary>
+    public class ValidationException : Exception
+    {
+        public string Field { get; }
+
+        public ValidationException(string field, string message) : base($"{field} {message}")
+        {
+            Field = field;
+        }
+    }
+`
+	}
+	return ""
+}
+
+// formatSamples holds a valid value of each format.
+var formatSamples = map[string]string{
+	"email": "a@example.com",
+	"url":   "https://example.com",
+	"uuid":  "00000000-0000-4000-8000-000000000000",
+}
+
+// constrainedSample returns a sample JSON value of a field that meets its
+// constraints. constrained is false when the constraints leave the usual
+// sample valid, and ok is false when no sample can be derived, as for
+// patterns.
+func constrainedSample(f specparser.SpecField) (sample string, constrained, ok bool) {
+	if len(f.Constraints) == 0 {
+		return "", false, true
+	}
+	e := baseExpr(f.Type)
+	subject := specparser.ConstraintSubject(f.Type)
+	bounds := map[string]float64{}
+	var oneOf []string
+	format := ""
+	for _, c := range f.Constraints {
+		switch c.Kind {
+		case "pattern":
+			return "", true, false
+		case "oneOf":
+			oneOf = c.Values
+		case "format":
+			format = c.Value
+		default:
+			if n, err := strconv.ParseFloat(c.Value, 64); err == nil {
+				bounds[c.Kind] = n
+			}
+		}
+	}
+
+	switch subject {
+	case "number":
+		if len(oneOf) > 0 {
+			return oneOf[0], true, true
+		}
+		v := 1.0
+		if isFloat(e) {
+			// Fractional, so every language decodes it as a float
+			v = 1.5
+		}
+		if lo, set := bounds["min"]; set && v < lo {
+			v = lo
+		}
+		if hi, set := bounds["max"]; set && v > hi {
+			v = hi
+		}
+		if !isFloat(e) {
+			v = math.Ceil(v)
+		} else if v == math.Trunc(v) {
+			v += 0.5
+		}
+		if hi, set := bounds["max"]; set && v > hi {
+			return "", true, false
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), true, true
+	case "string":
+		if len(oneOf) > 0 {
+			return strconv.Quote(oneOf[0]), true, true
+		}
+		if sample, known := formatSamples[format]; known {
+			return strconv.Quote(sample), true, true
+		}
+		n := 1.0
+		if lo, set := bounds["minLength"]; set && lo > n {
+			n = lo
+		}
+		if hi, set := bounds["maxLength"]; set && hi < n {
+			n = hi
+		}
+		return strconv.Quote(strings.Repeat("x", int(n))), true, true
+	case "collection":
+		// Samples hold one item
+		if lo, set := bounds["minLength"]; set && lo > 1 {
+			return "", true, false
+		}
+		if hi, set := bounds["maxLength"]; set && hi < 1 {
+			return "", true, false
+		}
+	}
+	return "", false, true
+}
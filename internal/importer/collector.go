@@ -99,6 +99,13 @@ var apiSpecFiles = []string{
 	"spec.json",
 }
 
+// API schema file extensions
+var apiSpecExtensions = []string{
+	".graphql",
+	".graphqls",
+	".gql",
+}
+
 // Configuration files
 var configFiles = []string{
 	"go.mod",
@@ -287,6 +294,11 @@ func CategorizeFile(path string) FileCategory {
 			return CategoryAPI
 		}
 	}
+	for _, apiExt := range apiSpecExtensions {
+		if strings.ToLower(ext) == apiExt {
+			return CategoryAPI
+		}
+	}
 
 	// Check for documentation files
 	for _, docFile := range docFiles {
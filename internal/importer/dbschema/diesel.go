@@ -0,0 +1,130 @@
+package dbschema
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+var (
+	dieselTablePattern    = regexp.MustCompile(`\b(?:diesel::)?table!\s*\{`)
+	dieselHeaderPattern   = regexp.MustCompile(`(?s)^\s*(?:use\s+[^;]*;\s*)*(?:#\[[^\]]*\]\s*)*(?:(?:\w+\.)?(\w+))\s*(?:\(([^)]*)\))?\s*\{`)
+	dieselColumnPattern   = regexp.MustCompile(`(?m)((?:^\s*(?:///.*|#\[.*\])\n)*)^\s*(\w+)\s*->\s*([^,\n]+?)\s*,?\s*$`)
+	dieselJoinablePattern = regexp.MustCompile(`\b(?:diesel::)?joinable!\s*\(\s*(\w+)\s*->\s*(\w+)\s*\(\s*(\w+)\s*\)\s*\)`)
+	dieselStructPattern   = regexp.MustCompile(`(?s)((?:#\[[^\]]*\]\s*)+)(?:pub(?:\([^)]*\))?\s+)?struct\s+(\w+)`)
+	dieselTableAttr       = regexp.MustCompile(`table_name\s*=\s*"?([\w:]+)"?`)
+	dieselSQLName         = regexp.MustCompile(`sql_name\s*=\s*"(\w+)"`)
+)
+
+// dieselTypes maps Diesel SQL types to SQL types.
+var dieselTypes = map[string]string{
+	"Int2": "smallint", "SmallInt": "smallint", "Int4": "integer", "Integer": "integer",
+	"Int8": "bigint", "BigInt": "bigint", "Float4": "real", "Float": "real",
+	"Float8": "double precision", "Double": "double precision", "Numeric": "numeric",
+	"Bool": "boolean", "Text": "text", "Varchar": "varchar", "VarChar": "varchar",
+	"Bytea": "bytea", "Binary": "blob", "Blob": "blob", "Date": "date", "Time": "time",
+	"Timestamp": "timestamp", "Timestamptz": "timestamptz", "Interval": "interval",
+	"Uuid": "uuid", "Json": "json", "Jsonb": "jsonb",
+}
+
+// dieselModels extracts the table! declarations of Rust sources (usually
+// the generated schema.rs), foreign keys from joinable!, and entity names
+// from structs that name their table.
+func dieselModels(files []sourceFile) []*specparser.SpecTable {
+	var tables []*specparser.SpecTable
+	for _, file := range files {
+		src := file.content
+		for _, loc := range dieselTablePattern.FindAllStringIndex(src, -1) {
+			end := matchingBrace(src, loc[1]-1)
+			if end < 0 {
+				continue
+			}
+			dieselTable(&tables, src[loc[1]:end])
+		}
+	}
+
+	for _, file := range files {
+		for _, m := range dieselJoinablePattern.FindAllStringSubmatch(file.content, -1) {
+			for _, t := range tables {
+				if t.Name == m[1] {
+					t.ForeignKeys = append(t.ForeignKeys, specparser.SpecForeignKey{Columns: []string{m[3]}, RefTable: m[2]})
+				}
+			}
+		}
+		for _, m := range dieselStructPattern.FindAllStringSubmatch(file.content, -1) {
+			attr := dieselTableAttr.FindStringSubmatch(m[1])
+			if attr == nil || !strings.Contains(m[1], "Queryable") && !strings.Contains(m[1], "Identifiable") {
+				continue
+			}
+			name := attr[1][strings.LastIndexByte(attr[1], ':')+1:]
+			for _, t := range tables {
+				if t.Name == name && t.Entity == "" {
+					t.Entity = m[2]
+				}
+			}
+		}
+	}
+	return tables
+}
+
+// dieselTable adds the table of one table! body:
+// "users (id) { id -> Int4, name -> Nullable<Varchar>, }".
+func dieselTable(tables *[]*specparser.SpecTable, body string) {
+	header := dieselHeaderPattern.FindStringSubmatchIndex(blankComments(body, false))
+	if header == nil {
+		return
+	}
+	name := body[header[2]:header[3]]
+	primaryKey := []string{"id"}
+	if header[4] >= 0 {
+		primaryKey = nil
+		for _, col := range strings.Split(body[header[4]:header[5]], ",") {
+			if col = strings.TrimSpace(col); col != "" {
+				primaryKey = append(primaryKey, col)
+			}
+		}
+	}
+	open := header[1] - 1
+	end := matchingBrace(body, open)
+	if end < 0 {
+		return
+	}
+
+	t := ormTable(tables, name, "")
+	t.Description = docComment(body[:header[2]])
+	t.PrimaryKey = primaryKey
+	for _, m := range dieselColumnPattern.FindAllStringSubmatch(body[open+1:end], -1) {
+		col := specparser.SpecColumn{Name: m[2], Description: docComment(m[1])}
+		if sqlName := dieselSQLName.FindStringSubmatch(m[1]); sqlName != nil {
+			col.Name = sqlName[1]
+		}
+		sqlType := dieselSQLType(strings.TrimSpace(m[3]), &col.Nullable)
+		col.Type, _ = pseudoType(sqlType)
+		if len(primaryKey) == 1 && primaryKey[0] == col.Name {
+			col.AutoIncrement = col.Type == "int" || col.Type == "int64"
+		}
+		addColumn(t, col)
+	}
+}
+
+// dieselSQLType maps a Diesel column type to a SQL type, unwrapping
+// Nullable<T> and Array<T>.
+func dieselSQLType(typ string, nullable *bool) string {
+	path, _, _ := strings.Cut(typ, "<")
+	if i := strings.LastIndex(path, "::"); i >= 0 {
+		typ = typ[i+2:]
+	}
+	if inner, ok := strings.CutPrefix(typ, "Nullable<"); ok {
+		*nullable = true
+		return dieselSQLType(strings.TrimSuffix(inner, ">"), nullable)
+	}
+	if inner, ok := strings.CutPrefix(typ, "Array<"); ok {
+		var ignored bool
+		return dieselSQLType(strings.TrimSuffix(inner, ">"), &ignored) + "[]"
+	}
+	if sqlType, ok := dieselTypes[typ]; ok {
+		return sqlType
+	}
+	return strings.ToLower(typ)
+}
@@ -0,0 +1,174 @@
+package dbschema
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+var (
+	dbSetPattern      = regexp.MustCompile(`DbSet<(\w+)>\s+(\w+)`)
+	csPropertyPattern = regexp.MustCompile(`^((?:(?:public|internal|protected|virtual|required|override)\s+)*)([\w.<>?,\[\] ]+?)\s+(\w+)\s*\{\s*get\s*;`)
+	csCollections     = regexp.MustCompile(`^(?:ICollection|IList|List|IEnumerable|HashSet|ISet)<(\w+)>$`)
+)
+
+// efCoreModels extracts the entity classes of C# sources. A class is an
+// entity when a DbContext exposes it as a DbSet, which also names its
+// table, or when it carries [Table].
+func efCoreModels(files []sourceFile) []*specparser.SpecTable {
+	sets := make(map[string]string)
+	for _, file := range files {
+		for _, m := range dbSetPattern.FindAllStringSubmatch(blankComments(file.content, false), -1) {
+			sets[m[1]] = m[2]
+		}
+	}
+
+	var classes []classDecl
+	entities := make(map[string]bool)
+	for _, file := range files {
+		for _, class := range findClasses(file.content, true) {
+			_, table := annotationArgs(class.annotations, "Table")
+			if _, ok := sets[class.name]; ok || table {
+				classes = append(classes, class)
+				entities[class.name] = true
+			}
+		}
+	}
+
+	var tables []*specparser.SpecTable
+	for _, class := range classes {
+		name := firstNonEmpty(sets[class.name], class.name)
+		if args, ok := annotationArgs(class.annotations, "Table"); ok {
+			positional, _ := keywordArgs(args)
+			if len(positional) > 0 {
+				name = unquote(positional[0])
+			}
+		}
+		t := ormTable(&tables, name, class.name)
+		t.Description = class.doc
+		for _, a := range class.annotations {
+			if a.name == "Index" {
+				efCoreIndex(t, a.args)
+			}
+		}
+		efCoreProperties(t, class, entities)
+	}
+	return tables
+}
+
+// efCoreProperties adds the columns and relations of an entity's
+// properties. Navigation properties to other entities become relations; a
+// reference navigation with a matching <Name>Id property belongs to its
+// target.
+func efCoreProperties(t *specparser.SpecTable, class classDecl, entities map[string]bool) {
+	properties := make(map[string]bool)
+	for _, m := range class.members {
+		if p := csPropertyPattern.FindStringSubmatch(m.decl); p != nil {
+			properties[p[3]] = true
+		}
+	}
+
+	hasKey := false
+	for _, m := range class.members {
+		if _, ok := annotationArgs(m.annotations, "Key"); ok {
+			hasKey = true
+		}
+	}
+
+	for _, m := range class.members {
+		p := csPropertyPattern.FindStringSubmatch(m.decl)
+		if p == nil {
+			continue
+		}
+		typ, name := strings.TrimSpace(p[2]), p[3]
+		has := func(name string) bool {
+			_, ok := annotationArgs(m.annotations, name)
+			return ok
+		}
+		if has("NotMapped") {
+			continue
+		}
+
+		elem := strings.TrimSuffix(typ, "?")
+		if c := csCollections.FindStringSubmatch(elem); c != nil && entities[c[1]] {
+			t.Relations = append(t.Relations, specparser.SpecRelation{Name: toSnakeCase(name), Kind: specparser.RelationHasMany, Table: c[1], Columns: []string{toSnakeCase(class.name) + "_id"}})
+			continue
+		}
+		if entities[elem] {
+			relation := specparser.SpecRelation{Name: toSnakeCase(name), Table: elem}
+			fkProperty := name + "Id"
+			if args, ok := annotationArgs(m.annotations, "ForeignKey"); ok {
+				fkProperty = efCoreName(args)
+			}
+			if properties[fkProperty] {
+				relation.Kind = specparser.RelationBelongsTo
+				relation.Columns = []string{toSnakeCase(fkProperty)}
+				t.ForeignKeys = append(t.ForeignKeys, specparser.SpecForeignKey{Columns: relation.Columns, RefTable: elem})
+			} else {
+				relation.Kind = specparser.RelationHasOne
+				relation.Columns = []string{toSnakeCase(class.name) + "_id"}
+			}
+			t.Relations = append(t.Relations, relation)
+			continue
+		}
+
+		col := specparser.SpecColumn{Name: toSnakeCase(name), Description: m.doc}
+		col.Type, _ = languageType(elem)
+		if elem == "byte[]" {
+			col.Type = "bytes"
+		}
+		// Value types are nullable only when marked with ?, and reference
+		// types are nullable unless [Required]
+		col.Nullable = strings.HasSuffix(typ, "?") || (elem == "string" || elem == "byte[]") && !has("Required")
+		if args, ok := annotationArgs(m.annotations, "Column"); ok {
+			positional, kw := keywordArgs(args)
+			if len(positional) > 0 {
+				col.Name = unquote(positional[0])
+			}
+			col.SQLType = unquote(kw["TypeName"])
+		}
+		for _, attr := range []string{"MaxLength", "StringLength"} {
+			if args, ok := annotationArgs(m.annotations, attr); ok && col.SQLType == "" {
+				positional, _ := keywordArgs(args)
+				if len(positional) > 0 {
+					col.SQLType = "varchar(" + positional[0] + ")"
+				}
+			}
+		}
+		isKey := has("Key") || !hasKey && len(t.PrimaryKey) == 0 && (name == "Id" || name == class.name+"Id")
+		if isKey {
+			t.PrimaryKey = append(t.PrimaryKey, col.Name)
+			col.Nullable = false
+			col.AutoIncrement = col.Type == "int" || col.Type == "int64"
+		}
+		if args, ok := annotationArgs(m.annotations, "DatabaseGenerated"); ok {
+			col.AutoIncrement = strings.Contains(args, "Identity")
+		}
+		addColumn(t, col)
+	}
+}
+
+// efCoreIndex adds the index of an [Index("A", "B", IsUnique = true)]
+// attribute, whose columns may also be given as nameof(A).
+func efCoreIndex(t *specparser.SpecTable, args string) {
+	positional, kw := keywordArgs(args)
+	var columns []string
+	for _, p := range positional {
+		columns = append(columns, toSnakeCase(efCoreName(p)))
+	}
+	if len(columns) == 0 {
+		return
+	}
+	name := firstNonEmpty(unquote(kw["Name"]), "IX_"+t.Name+"_"+strings.Join(columns, "_"))
+	t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: name, Columns: columns, Unique: isTrue(strings.ToLower(kw["IsUnique"]))})
+}
+
+// efCoreName returns the member named by "Name" or nameof(Name).
+func efCoreName(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if inner, ok := strings.CutPrefix(arg, "nameof("); ok {
+		return strings.TrimSuffix(inner, ")")
+	}
+	return unquote(arg)
+}
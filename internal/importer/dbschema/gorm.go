@@ -0,0 +1,398 @@
+package dbschema
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// gormStruct is a Go struct that may be a GORM model.
+type gormStruct struct {
+	name   string
+	doc    string
+	fields *ast.FieldList
+	table  string
+	model  bool
+}
+
+// gormTypes maps Go types GORM stores specially to pseudo-types; the flag
+// marks types that hold NULL.
+var gormTypes = map[string]struct {
+	typ      string
+	nullable bool
+}{
+	"time.Time":         {"datetime", false},
+	"gorm.DeletedAt":    {"datetime", true},
+	"sql.NullString":    {"string", true},
+	"sql.NullInt64":     {"int64", true},
+	"sql.NullInt32":     {"int", true},
+	"sql.NullInt16":     {"int", true},
+	"sql.NullFloat64":   {"float", true},
+	"sql.NullBool":      {"bool", true},
+	"sql.NullTime":      {"datetime", true},
+	"uuid.UUID":         {"uuid", false},
+	"decimal.Decimal":   {"decimal", false},
+	"datatypes.JSON":    {"any", false},
+	"datatypes.Date":    {"date", false},
+	"json.RawMessage":   {"any", false},
+	"pq.StringArray":    {"List[string]", false},
+	"pq.Int64Array":     {"List[int64]", false},
+	"datatypes.JSONMap": {"any", false},
+}
+
+var (
+	autoMigratePattern    = regexp.MustCompile(`AutoMigrate\(([^)]*)\)`)
+	autoMigrateArgPattern = regexp.MustCompile(`&(?:\w+\.)?(\w+)\{\}`)
+)
+
+// gormModels extracts GORM models: structs that embed gorm.Model, carry
+// gorm tags, have a TableName method or are passed to AutoMigrate. Tables
+// are named by TableName or, following GORM's naming strategy, the
+// snake_case plural of the struct name.
+func gormModels(files []sourceFile) []*specparser.SpecTable {
+	structs := make(map[string]*gormStruct)
+	var order []string
+	fset := gotoken.NewFileSet()
+	for _, f := range files {
+		file, err := goparser.ParseFile(fset, f.path, f.content, goparser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					doc := d.Doc
+					if ts.Doc != nil {
+						doc = ts.Doc
+					}
+					s := &gormStruct{name: ts.Name.Name, fields: st.Fields}
+					if doc != nil {
+						s.doc = strings.TrimSpace(doc.Text())
+					}
+					if existing, ok := structs[s.name]; ok {
+						s.table = existing.table
+						s.model = existing.model
+					} else {
+						order = append(order, s.name)
+					}
+					structs[s.name] = s
+					for _, field := range st.Fields.List {
+						if goTypeString(field.Type) == "gorm.Model" || field.Tag != nil && strings.Contains(field.Tag.Value, "gorm:") {
+							s.model = true
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				// func (User) TableName() string { return "people" }
+				if d.Name.Name != "TableName" || d.Recv == nil || len(d.Recv.List) != 1 || d.Body == nil {
+					continue
+				}
+				recv := strings.TrimPrefix(goTypeString(d.Recv.List[0].Type), "*")
+				for _, stmt := range d.Body.List {
+					ret, ok := stmt.(*ast.ReturnStmt)
+					if !ok || len(ret.Results) != 1 {
+						continue
+					}
+					if lit, ok := ret.Results[0].(*ast.BasicLit); ok && lit.Kind == gotoken.STRING {
+						name, _ := strconv.Unquote(lit.Value)
+						s := structs[recv]
+						if s == nil {
+							s = &gormStruct{name: recv}
+							structs[recv] = s
+							order = append(order, recv)
+						}
+						s.table = name
+						s.model = true
+					}
+				}
+			}
+		}
+	}
+
+	// Structs passed to AutoMigrate are models even without gorm tags
+	for _, f := range files {
+		for _, call := range autoMigratePattern.FindAllStringSubmatch(f.content, -1) {
+			for _, arg := range autoMigrateArgPattern.FindAllStringSubmatch(call[1], -1) {
+				if s := structs[arg[1]]; s != nil {
+					s.model = true
+				}
+			}
+		}
+	}
+
+	var tables []*specparser.SpecTable
+	for _, name := range order {
+		s := structs[name]
+		if !s.model || s.fields == nil {
+			continue
+		}
+		if s.table == "" {
+			s.table = plural(toSnakeCase(s.name))
+		}
+		t := ormTable(&tables, s.table, s.name)
+		t.Description = gormDescription(s)
+		gormFields(t, s, s.fields, structs)
+	}
+
+	// GORM creates many2many join tables itself, with a column per side
+	// named after the model and its primary key
+	for _, t := range tables {
+		for _, r := range t.Relations {
+			if r.Kind != specparser.RelationManyToMany || r.Through == "" {
+				continue
+			}
+			var target *specparser.SpecTable
+			for _, other := range tables {
+				if other.Entity == r.Table {
+					target = other
+				}
+			}
+			if target == nil || len(t.PrimaryKey) != 1 || len(target.PrimaryKey) != 1 {
+				continue
+			}
+			join := ormTable(&tables, r.Through, "")
+			if len(join.Columns) > 0 {
+				continue
+			}
+			for _, side := range []*specparser.SpecTable{t, target} {
+				column := toSnakeCase(side.Entity) + "_" + side.PrimaryKey[0]
+				join.PrimaryKey = append(join.PrimaryKey, column)
+				join.Columns = append(join.Columns, specparser.SpecColumn{Name: column})
+				join.ForeignKeys = append(join.ForeignKeys, specparser.SpecForeignKey{Columns: []string{column}, RefTable: side.Name, RefColumns: side.PrimaryKey})
+			}
+		}
+	}
+	return tables
+}
+
+// gormDescription returns a struct's doc comment without its leading name.
+func gormDescription(s *gormStruct) string {
+	doc := strings.Join(strings.Fields(s.doc), " ")
+	if rest, ok := strings.CutPrefix(doc, s.name+" "); ok {
+		rest = strings.TrimPrefix(rest, "is ")
+		rest = strings.TrimPrefix(rest, "represents ")
+		if rest != "" {
+			return strings.ToUpper(rest[:1]) + rest[1:]
+		}
+	}
+	return doc
+}
+
+// gormFields adds the columns and relations of a struct's fields to t.
+// Embedded structs, gorm.Model among them, contribute their fields.
+func gormFields(t *specparser.SpecTable, s *gormStruct, fields *ast.FieldList, structs map[string]*gormStruct) {
+	for _, field := range fields.List {
+		typ := goTypeString(field.Type)
+		tag := gormTag(field)
+		if _, skip := tag["-"]; skip {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			switch {
+			case typ == "gorm.Model":
+				t.PrimaryKey = []string{"id"}
+				t.Columns = append(t.Columns,
+					specparser.SpecColumn{Name: "id", Type: "int64", AutoIncrement: true},
+					specparser.SpecColumn{Name: "created_at", Type: "datetime", Nullable: true},
+					specparser.SpecColumn{Name: "updated_at", Type: "datetime", Nullable: true},
+					specparser.SpecColumn{Name: "deleted_at", Type: "datetime", Nullable: true})
+				t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: "idx_" + t.Name + "_deleted_at", Columns: []string{"deleted_at"}})
+			case structs[strings.TrimPrefix(typ, "*")] != nil && structs[strings.TrimPrefix(typ, "*")].fields != nil:
+				gormFields(t, s, structs[strings.TrimPrefix(typ, "*")].fields, structs)
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			gormField(t, s, ident.Name, typ, tag, field, structs)
+		}
+	}
+}
+
+// gormField adds the column or relation of one field.
+func gormField(t *specparser.SpecTable, s *gormStruct, name, typ string, tag map[string]string, field *ast.Field, structs map[string]*gormStruct) {
+	elem := strings.TrimPrefix(strings.TrimPrefix(typ, "[]"), "*")
+	if target, ok := structs[elem]; ok && target.fields != nil && gormTypes[elem].typ == "" {
+		relation := specparser.SpecRelation{Name: toSnakeCase(name), Table: elem}
+		switch {
+		case tag["many2many"] != "":
+			relation.Kind = specparser.RelationManyToMany
+			relation.Through = tag["many2many"]
+		case strings.HasPrefix(typ, "[]"):
+			relation.Kind = specparser.RelationHasMany
+			relation.Columns = []string{toSnakeCase(firstNonEmpty(tag["foreignkey"], s.name+"ID"))}
+		default:
+			fkField := firstNonEmpty(tag["foreignkey"], name+"ID")
+			if gormHasField(s, fkField) {
+				relation.Kind = specparser.RelationBelongsTo
+				relation.Columns = []string{toSnakeCase(fkField)}
+				fk := specparser.SpecForeignKey{Columns: relation.Columns, RefTable: elem}
+				if ref := tag["references"]; ref != "" {
+					fk.RefColumns = []string{toSnakeCase(ref)}
+				}
+				fk.OnDelete, fk.OnUpdate = gormConstraint(tag["constraint"])
+				t.ForeignKeys = append(t.ForeignKeys, fk)
+			} else {
+				relation.Kind = specparser.RelationHasOne
+				relation.Columns = []string{toSnakeCase(firstNonEmpty(tag["foreignkey"], s.name+"ID"))}
+			}
+		}
+		t.Relations = append(t.Relations, relation)
+		return
+	}
+
+	col := specparser.SpecColumn{Name: firstNonEmpty(tag["column"], toSnakeCase(name)), SQLType: tag["type"]}
+	if known, ok := gormTypes[strings.TrimPrefix(typ, "*")]; ok {
+		col.Type, col.Nullable = known.typ, known.nullable
+	} else {
+		col.Type, col.Nullable = languageType(typ)
+	}
+	if strings.HasPrefix(typ, "*") {
+		col.Nullable = true
+	}
+	if col.SQLType != "" && col.Type == "string" {
+		col.Type, _ = pseudoType(col.SQLType)
+	}
+	if size := tag["size"]; size != "" && col.SQLType == "" && col.Type == "string" {
+		col.SQLType = "varchar(" + size + ")"
+	}
+	_, primary := tag["primarykey"]
+	if primary || (name == "ID" && len(t.PrimaryKey) == 0) {
+		t.PrimaryKey = append(t.PrimaryKey, col.Name)
+		col.Nullable = false
+		col.AutoIncrement = col.Type == "int" || col.Type == "int64"
+	}
+	if v, ok := tag["autoincrement"]; ok {
+		col.AutoIncrement = v != "false"
+	}
+	if _, ok := tag["not null"]; ok {
+		col.Nullable = false
+	}
+	if _, ok := tag["unique"]; ok {
+		col.Unique = true
+	}
+	col.Default = tag["default"]
+	col.Description = firstNonEmpty(tag["comment"], strings.TrimSpace(field.Comment.Text()), strings.TrimSpace(field.Doc.Text()))
+	addColumn(t, col)
+
+	for _, kind := range []string{"index", "uniqueindex"} {
+		value, ok := tag[kind]
+		if !ok {
+			continue
+		}
+		idxName, _, _ := strings.Cut(value, ",")
+		if idxName == "" {
+			idxName = "idx_" + t.Name + "_" + col.Name
+		}
+		gormIndex(t, idxName, col.Name, kind == "uniqueindex")
+	}
+}
+
+// gormIndex adds a column to the named index, creating it when missing.
+func gormIndex(t *specparser.SpecTable, name, column string, unique bool) {
+	for i := range t.Indexes {
+		if t.Indexes[i].Name == name {
+			t.Indexes[i].Columns = append(t.Indexes[i].Columns, column)
+			return
+		}
+	}
+	t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: name, Columns: []string{column}, Unique: unique})
+}
+
+// gormConstraint parses "OnUpdate:CASCADE,OnDelete:SET NULL".
+func gormConstraint(value string) (onDelete, onUpdate string) {
+	for _, part := range strings.Split(value, ",") {
+		key, action, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "ondelete":
+			onDelete = strings.ToUpper(strings.TrimSpace(action))
+		case "onupdate":
+			onUpdate = strings.ToUpper(strings.TrimSpace(action))
+		}
+	}
+	return onDelete, onUpdate
+}
+
+// gormHasField reports whether a struct declares the named field.
+func gormHasField(s *gormStruct, name string) bool {
+	for _, field := range s.fields.List {
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// gormTag parses a field's gorm tag into lowercase keys: "column:name;
+// primaryKey;not null" becomes {column: name, primarykey: "", not null: ""}.
+func gormTag(field *ast.Field) map[string]string {
+	tag := make(map[string]string)
+	if field.Tag == nil {
+		return tag
+	}
+	raw, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return tag
+	}
+	for _, part := range strings.Split(reflect.StructTag(raw).Get("gorm"), ";") {
+		key, value, _ := strings.Cut(part, ":")
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			tag[key] = strings.TrimSpace(value)
+		}
+	}
+	return tag
+}
+
+// goTypeString renders a type expression as written.
+func goTypeString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return "*" + goTypeString(e.X)
+	case *ast.SelectorExpr:
+		return goTypeString(e.X) + "." + e.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + goTypeString(e.Elt)
+	case *ast.MapType:
+		return "map[" + goTypeString(e.Key) + "]" + goTypeString(e.Value)
+	case *ast.IndexExpr:
+		return goTypeString(e.X) + "[" + goTypeString(e.Index) + "]"
+	case *ast.InterfaceType:
+		return "interface{}"
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,495 @@
+package dbschema
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// sqlTypes maps lowercase SQL type names, without their arguments, to
+// pseudo-types.
+var sqlTypes = map[string]string{
+	"smallint": "int", "int2": "int", "tinyint": "int", "mediumint": "int", "int": "int",
+	"integer": "int", "int4": "int", "serial": "int", "serial4": "int", "smallserial": "int",
+	"serial2": "int", "year": "int",
+
+	"bigint": "int64", "int8": "int64", "bigserial": "int64", "serial8": "int64",
+
+	"real": "float", "float": "float", "float4": "float", "float8": "float", "double": "float",
+	"double precision": "float",
+
+	"decimal": "decimal", "numeric": "decimal", "money": "decimal", "dec": "decimal",
+
+	"boolean": "bool", "bool": "bool", "bit": "bool",
+
+	"char": "string", "character": "string", "varchar": "string", "character varying": "string",
+	"nchar": "string", "nvarchar": "string", "text": "string", "tinytext": "string",
+	"mediumtext": "string", "longtext": "string", "citext": "string", "clob": "string",
+	"enum": "string", "set": "string", "time": "string", "timetz": "string", "inet": "string",
+	"cidr": "string", "macaddr": "string", "xml": "string", "string": "string",
+
+	"bytea": "bytes", "blob": "bytes", "tinyblob": "bytes", "mediumblob": "bytes",
+	"longblob": "bytes", "binary": "bytes", "varbinary": "bytes",
+
+	"date": "date",
+
+	"timestamp": "datetime", "timestamptz": "datetime", "datetime": "datetime",
+	"timestamp with time zone": "datetime", "timestamp without time zone": "datetime",
+
+	"interval": "duration",
+
+	"uuid": "uuid", "uniqueidentifier": "uuid",
+
+	"json": "any", "jsonb": "any",
+}
+
+// pseudoType maps a declared SQL type to a pseudo-type, and reports whether
+// the type makes the database assign values (SERIAL and its variants).
+// Unknown types map to string.
+func pseudoType(sqlType string) (string, bool) {
+	lower := strings.ToLower(strings.TrimSpace(sqlType))
+	if strings.HasSuffix(lower, "[]") {
+		inner, _ := pseudoType(strings.TrimSuffix(lower, "[]"))
+		return "List[" + inner + "]", false
+	}
+	if lower == "tinyint(1)" || lower == "bit(1)" {
+		return "bool", false
+	}
+
+	base := lower
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		// VARCHAR(255), TIMESTAMP(3) WITH TIME ZONE
+		rest := ""
+		if j := strings.IndexByte(base, ')'); j > i {
+			rest = base[j+1:]
+		}
+		base = strings.TrimSpace(base[:i] + rest)
+	}
+	base = strings.TrimSpace(strings.NewReplacer(" unsigned", "", " zerofill", "", " signed", "").Replace(base))
+
+	auto := strings.Contains(base, "serial")
+	if t, ok := sqlTypes[base]; ok {
+		return t, auto
+	}
+	if first, _, ok := strings.Cut(base, " "); ok {
+		if t, ok := sqlTypes[first]; ok {
+			return t, auto
+		}
+	}
+	return "string", auto
+}
+
+// maxLengthPattern matches the length of a character type.
+var maxLengthPattern = regexp.MustCompile(`(?i)^(?:n?varchar|n?char|character varying|character)\s*\((\d+)\)`)
+
+// ParseFile parses a SQL DDL file into a spec analysis.
+func ParseFile(path string) (*specparser.SpecAnalysis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	spec, err := Parse(data, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return spec, nil
+}
+
+// Parse parses DDL into a spec analysis with the given name.
+func Parse(data []byte, name string) (*specparser.SpecAnalysis, error) {
+	s := newSchema()
+	if err := s.parse(string(data)); err != nil {
+		return nil, err
+	}
+	return newSpec(name, s.tables), nil
+}
+
+// skippedDirs are directories ImportDir does not descend into.
+var skippedDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, "target": true, "bin": true,
+	"obj": true, "dist": true, "build": true, "__pycache__": true, ".venv": true, "venv": true,
+}
+
+// ImportDir imports the data model of a source tree. Its .sql files are
+// applied in path order, skipping down migrations, and its Go, Java, C#,
+// Python and Rust files are scanned for ORM models. A table declared by
+// both keeps the DDL's columns and gains the model's entity name and
+// relations.
+func ImportDir(dir string) (*specparser.SpecAnalysis, error) {
+	s := newSchema()
+	sources := make(map[string][]sourceFile)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (skippedDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		lower := strings.ToLower(d.Name())
+		isSQL := strings.HasSuffix(lower, ".sql")
+		if isSQL && (strings.HasSuffix(lower, ".down.sql") || lower == "down.sql" || strings.HasSuffix(lower, "_down.sql")) {
+			return nil
+		}
+		if !isSQL && modelLanguage(path) == "" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if isSQL {
+			if err := s.parse(string(data)); err != nil {
+				return fmt.Errorf("%s: %w", relativePath(dir, path), err)
+			}
+			return nil
+		}
+		lang := modelLanguage(path)
+		sources[lang] = append(sources[lang], sourceFile{path: path, content: string(data)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tables := s.tables
+	for _, m := range parseModels(sources) {
+		t := s.table(m.Name)
+		if t == nil {
+			tables = append(tables, m)
+			s.byName[strings.ToLower(m.Name)] = m
+			continue
+		}
+		t.Entity = m.Entity
+		if t.Description == "" {
+			t.Description = m.Description
+		}
+		t.Relations = append(t.Relations, m.Relations...)
+	}
+
+	name := filepath.Base(filepath.Clean(dir))
+	if abs, err := filepath.Abs(dir); err == nil {
+		name = filepath.Base(abs)
+	}
+	return newSpec(name, tables), nil
+}
+
+// relativePath returns path relative to dir, or path itself.
+func relativePath(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// newSpec builds a spec analysis from tables: references are resolved,
+// relations derived from foreign keys, and an entity type is added per
+// table.
+func newSpec(name string, tables []*specparser.SpecTable) *specparser.SpecAnalysis {
+	resolve(tables)
+
+	spec := &specparser.SpecAnalysis{
+		Name:          name,
+		Types:         []specparser.SpecType{},
+		Functions:     []specparser.SpecFunction{},
+		Tests:         []specparser.SpecTest{},
+		Dependencies:  []specparser.SpecDependency{},
+		Configuration: []specparser.SpecConfig{},
+		Endpoints:     []specparser.SpecEndpoint{},
+	}
+	for _, t := range tables {
+		spec.Tables = append(spec.Tables, *t)
+		spec.Types = append(spec.Types, entityType(t))
+	}
+	if len(tables) > 0 {
+		spec.Overview = fmt.Sprintf("The data model of %s: %d tables.", name, len(tables))
+	}
+	spec.CalculateTotals()
+	return spec
+}
+
+// resolve names an entity for every table, points foreign keys and
+// relations that name an entity at its table, fills in foreign keys that
+// reference a primary key implicitly and the types of their columns, and
+// derives the relations foreign keys imply.
+func resolve(tables []*specparser.SpecTable) {
+	byName := make(map[string]*specparser.SpecTable)
+	byEntity := make(map[string]*specparser.SpecTable)
+	for _, t := range tables {
+		if t.Entity == "" {
+			t.Entity = entityName(t.Name)
+		}
+		byName[strings.ToLower(t.Name)] = t
+		byEntity[t.Entity] = t
+	}
+	lookup := func(name string) *specparser.SpecTable {
+		if t, ok := byName[strings.ToLower(name)]; ok {
+			return t
+		}
+		return byEntity[name]
+	}
+
+	for _, t := range tables {
+		for _, name := range t.PrimaryKey {
+			if col := t.Column(name); col != nil {
+				col.Nullable = false
+			}
+		}
+		for i := range t.ForeignKeys {
+			fk := &t.ForeignKeys[i]
+			if ref := lookup(fk.RefTable); ref != nil {
+				fk.RefTable = ref.Name
+				if len(fk.RefColumns) == 0 {
+					fk.RefColumns = ref.PrimaryKey
+				}
+			}
+		}
+		for i := range t.Relations {
+			r := &t.Relations[i]
+			if ref := lookup(r.Table); ref != nil {
+				r.Table = ref.Name
+			}
+			if through := lookup(r.Through); through != nil {
+				r.Through = through.Name
+			}
+		}
+	}
+
+	// Columns that models declare only through a relation, such as a JPA
+	// @JoinColumn, take the type of the column they reference
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			ref := lookup(fk.RefTable)
+			for i, name := range fk.Columns {
+				col := t.Column(name)
+				if col == nil || col.Type != "" {
+					continue
+				}
+				col.Type = "int64"
+				if ref != nil && i < len(fk.RefColumns) {
+					if refCol := ref.Column(fk.RefColumns[i]); refCol != nil && refCol.Type != "" {
+						col.Type = refCol.Type
+					}
+				}
+			}
+		}
+		for i := range t.Columns {
+			if t.Columns[i].Type == "" {
+				t.Columns[i].Type = "string"
+			}
+		}
+	}
+
+	for _, t := range tables {
+		join := isJoinTable(t)
+		if join {
+			a, b := lookup(t.ForeignKeys[0].RefTable), lookup(t.ForeignKeys[1].RefTable)
+			if a != nil && b != nil {
+				first, second := t.ForeignKeys[0].Columns, t.ForeignKeys[1].Columns
+				addRelation(a, specparser.SpecRelation{Name: b.Name, Kind: specparser.RelationManyToMany, Table: b.Name, Through: t.Name, Columns: concat(first, second)})
+				addRelation(b, specparser.SpecRelation{Name: a.Name, Kind: specparser.RelationManyToMany, Table: a.Name, Through: t.Name, Columns: concat(second, first)})
+			}
+		}
+		for _, fk := range t.ForeignKeys {
+			ref := lookup(fk.RefTable)
+			if ref == nil {
+				continue
+			}
+			addRelation(t, specparser.SpecRelation{Name: belongsToName(fk, ref), Kind: specparser.RelationBelongsTo, Table: ref.Name, Columns: fk.Columns})
+			if join {
+				continue
+			}
+			if uniqueColumns(t, fk.Columns) {
+				addRelation(ref, specparser.SpecRelation{Name: singular(t.Name), Kind: specparser.RelationHasOne, Table: t.Name, Columns: fk.Columns})
+			} else {
+				addRelation(ref, specparser.SpecRelation{Name: t.Name, Kind: specparser.RelationHasMany, Table: t.Name, Columns: fk.Columns})
+			}
+		}
+	}
+}
+
+// addRelation adds a relation unless the table already relates to the
+// same table in the same way, or has a relation of the same name.
+func addRelation(t *specparser.SpecTable, relation specparser.SpecRelation) {
+	for _, r := range t.Relations {
+		if r.Name == relation.Name || (r.Table == relation.Table && r.Kind == relation.Kind && r.Through == relation.Through) {
+			return
+		}
+	}
+	t.Relations = append(t.Relations, relation)
+}
+
+// isJoinTable reports whether a table only links two others: it has two
+// foreign keys, and every other column is assigned by the database.
+func isJoinTable(t *specparser.SpecTable) bool {
+	if len(t.ForeignKeys) != 2 {
+		return false
+	}
+	for _, c := range t.Columns {
+		if !containsFold(t.ForeignKeys[0].Columns, c.Name) && !containsFold(t.ForeignKeys[1].Columns, c.Name) &&
+			!c.AutoIncrement && c.Default == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqueColumns reports whether no two rows may share values of the given
+// columns: they are the primary key, a unique column, or a unique index.
+func uniqueColumns(t *specparser.SpecTable, columns []string) bool {
+	if sameColumns(t.PrimaryKey, columns) {
+		return true
+	}
+	if len(columns) == 1 {
+		if col := t.Column(columns[0]); col != nil && col.Unique {
+			return true
+		}
+	}
+	for _, idx := range t.Indexes {
+		if idx.Unique && sameColumns(idx.Columns, columns) {
+			return true
+		}
+	}
+	return false
+}
+
+// belongsToName names the relation a foreign key implies on its table:
+// the column without its id suffix, such as author for author_id, or the
+// referenced table's singular name.
+func belongsToName(fk specparser.SpecForeignKey, ref *specparser.SpecTable) string {
+	if len(fk.Columns) == 1 {
+		col := fk.Columns[0]
+		for _, suffix := range []string{"_id", "_ID", "Id", "ID"} {
+			if trimmed := strings.TrimSuffix(col, suffix); trimmed != col && trimmed != "" {
+				return toSnakeCase(trimmed)
+			}
+		}
+	}
+	return singular(ref.Name)
+}
+
+// entityType returns the spec type a row of the table maps to. Fields are
+// named after their columns and tagged with them; nullable columns are
+// optional fields.
+func entityType(t *specparser.SpecTable) specparser.SpecType {
+	typ := specparser.SpecType{
+		Name:        t.Entity,
+		Kind:        "struct",
+		Description: t.Description,
+		IsPublic:    true,
+	}
+	if typ.Description == "" {
+		typ.Description = fmt.Sprintf("A row of the %s table.", t.Name)
+	}
+	for _, c := range t.Columns {
+		field := specparser.SpecField{
+			Name:        c.Name,
+			Type:        c.Type,
+			Description: c.Description,
+			Required:    !c.Nullable,
+			Tags:        map[string]string{"db": c.Name},
+		}
+		if m := maxLengthPattern.FindStringSubmatch(c.SQLType); m != nil {
+			field.Constraints = []specparser.SpecConstraint{{Kind: "maxLength", Value: m[1]}}
+		}
+		typ.Fields = append(typ.Fields, field)
+	}
+	return typ
+}
+
+// entityName names the entity of a table: its singular name in
+// PascalCase, such as OrderItem for order_items.
+func entityName(table string) string {
+	var sb strings.Builder
+	for _, word := range strings.FieldsFunc(singular(toSnakeCase(table)), func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return sb.String()
+}
+
+// singular returns the singular of a plural table name by its last word,
+// such as category for categories and order_item for order_items.
+func singular(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "shes"), strings.HasSuffix(lower, "ches"),
+		strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return name
+	case strings.HasSuffix(lower, "s") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+// plural returns the plural of a singular name, the inverse of singular.
+func plural(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "sh"), strings.HasSuffix(lower, "ch"),
+		strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// toSnakeCase converts camelCase and PascalCase names to snake_case,
+// keeping acronyms together: OrgID becomes org_id.
+func toSnakeCase(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			prevLower := i > 0 && (s[i-1] >= 'a' && s[i-1] <= 'z' || isDigit(s[i-1]))
+			nextLower := i > 0 && i+1 < len(s) && s[i+1] >= 'a' && s[i+1] <= 'z' && s[i-1] >= 'A' && s[i-1] <= 'Z'
+			if (prevLower || nextLower) && sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_") {
+				sb.WriteByte('_')
+			}
+			sb.WriteByte(c + 'a' - 'A')
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) || len(a) == 0 {
+		return false
+	}
+	for _, v := range b {
+		if !containsFold(a, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func concat(a, b []string) []string {
+	return append(append([]string{}, a...), b...)
+}
@@ -0,0 +1,288 @@
+package dbschema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const shopSchema = `-- Postgres
+CREATE TABLE users (
+  id BIGSERIAL PRIMARY KEY,
+  email VARCHAR(255) NOT NULL UNIQUE,
+  name TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+COMMENT ON TABLE users IS 'Registered accounts.';
+COMMENT ON COLUMN users.email IS 'Login address.';
+
+CREATE TABLE posts (
+  id SERIAL PRIMARY KEY,
+  author_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  title VARCHAR(200) NOT NULL,
+  tags TEXT[],
+  CHECK (length(title) > 0)
+);
+CREATE UNIQUE INDEX posts_title_idx ON posts (lower(title));
+
+-- SQLite
+CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL);
+CREATE TABLE post_tags (
+  post_id INT NOT NULL,
+  tag_id INT NOT NULL,
+  PRIMARY KEY (post_id, tag_id),
+  FOREIGN KEY (post_id) REFERENCES posts(id),
+  FOREIGN KEY (tag_id) REFERENCES tags
+);
+
+-- MySQL
+CREATE TABLE ` + "`orders`" + ` (
+  ` + "`id`" + ` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  ` + "`user_id`" + ` bigint NOT NULL,
+  ` + "`total`" + ` decimal(10,2) DEFAULT '0.00' COMMENT 'Order total',
+  ` + "`paid`" + ` tinyint(1) NOT NULL DEFAULT 0,
+  PRIMARY KEY (` + "`id`" + `),
+  KEY ` + "`idx_user`" + ` (` + "`user_id`" + `),
+  CONSTRAINT ` + "`fk_user`" + ` FOREIGN KEY (` + "`user_id`" + `) REFERENCES ` + "`users`" + ` (` + "`id`" + `)
+) ENGINE=InnoDB COMMENT='Customer orders.';
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(shopSchema), "shop")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	var names []string
+	tables := make(map[string]specparser.SpecTable)
+	for _, table := range spec.Tables {
+		names = append(names, table.Name+":"+table.Entity)
+		tables[table.Name] = table
+	}
+	expected := "users:User,posts:Post,tags:Tag,post_tags:PostTag,orders:Order"
+	if got := strings.Join(names, ","); got != expected {
+		t.Errorf("Tables = %s, expected %s", got, expected)
+	}
+
+	users := tables["users"]
+	if users.Description != "Registered accounts." {
+		t.Errorf("Unexpected table comment: %q", users.Description)
+	}
+	tests := []struct {
+		table    string
+		column   string
+		typ      string
+		nullable bool
+	}{
+		{"users", "id", "int64", false},
+		{"users", "email", "string", false},
+		{"users", "name", "string", true},
+		{"users", "created_at", "datetime", false},
+		{"posts", "tags", "List[string]", true},
+		{"orders", "id", "int", false},
+		{"orders", "total", "decimal", true},
+		{"orders", "paid", "bool", false},
+	}
+	for _, tt := range tests {
+		table := tables[tt.table]
+		col := table.Column(tt.column)
+		if col == nil {
+			t.Errorf("Expected column %s.%s", tt.table, tt.column)
+			continue
+		}
+		if col.Type != tt.typ || col.Nullable != tt.nullable {
+			t.Errorf("Column %s.%s = %s nullable=%v, expected %s nullable=%v", tt.table, tt.column, col.Type, col.Nullable, tt.typ, tt.nullable)
+		}
+	}
+
+	if email := users.Column("email"); !email.Unique || email.Description != "Login address." {
+		t.Errorf("Unexpected email column: %+v", email)
+	}
+	if id := users.Column("id"); !id.AutoIncrement || strings.Join(users.PrimaryKey, ",") != "id" {
+		t.Errorf("Expected auto-increment primary key, got %+v %v", id, users.PrimaryKey)
+	}
+	if created := users.Column("created_at"); created.Default != "now()" {
+		t.Errorf("Unexpected default: %q", created.Default)
+	}
+
+	posts := tables["posts"]
+	if len(posts.ForeignKeys) != 1 || posts.ForeignKeys[0].RefTable != "users" || posts.ForeignKeys[0].OnDelete != "CASCADE" {
+		t.Errorf("Unexpected posts foreign keys: %+v", posts.ForeignKeys)
+	}
+	if len(posts.Indexes) != 1 || !posts.Indexes[0].Unique || posts.Indexes[0].Columns[0] != "lower(title)" {
+		t.Errorf("Unexpected posts indexes: %+v", posts.Indexes)
+	}
+	if len(posts.Checks) != 1 || posts.Checks[0] != "length(title) > 0" {
+		t.Errorf("Unexpected posts checks: %v", posts.Checks)
+	}
+
+	// An implicit reference targets the primary key
+	if fk := tables["post_tags"].ForeignKeys[1]; fk.RefTable != "tags" || strings.Join(fk.RefColumns, ",") != "id" {
+		t.Errorf("Unexpected implicit reference: %+v", fk)
+	}
+
+	orders := tables["orders"]
+	if orders.Description != "Customer orders." || orders.Column("total").Description != "Order total" {
+		t.Errorf("Expected MySQL comments, got %q and %q", orders.Description, orders.Column("total").Description)
+	}
+	if len(orders.Indexes) != 1 || orders.Indexes[0].Name != "idx_user" || orders.ForeignKeys[0].Name != "fk_user" {
+		t.Errorf("Unexpected orders keys: %+v %+v", orders.Indexes, orders.ForeignKeys)
+	}
+
+	relations := func(table string) string {
+		var rels []string
+		for _, r := range tables[table].Relations {
+			rels = append(rels, r.Name+" "+r.Kind+" "+r.Table+" "+r.Through)
+		}
+		return strings.Join(rels, "; ")
+	}
+	relationTests := []struct {
+		table    string
+		expected string
+	}{
+		{"users", "posts has many posts ; orders has many orders "},
+		{"posts", "author belongs to users ; tags many to many tags post_tags"},
+		{"tags", "posts many to many posts post_tags"},
+		{"post_tags", "post belongs to posts ; tag belongs to tags "},
+		{"orders", "user belongs to users "},
+	}
+	for _, tt := range relationTests {
+		if got := relations(tt.table); got != tt.expected {
+			t.Errorf("Relations of %s = %q, expected %q", tt.table, got, tt.expected)
+		}
+	}
+
+	// Each table gets an entity type
+	if len(spec.Types) != 5 || spec.Types[0].Name != "User" || spec.Types[0].Fields[1].Tags["db"] != "email" {
+		t.Fatalf("Unexpected entity types: %+v", spec.Types)
+	}
+	if f := spec.Types[0].Fields[2]; f.Type != "string" || f.Required {
+		t.Errorf("Expected nullable column to be an optional field, got %+v", f)
+	}
+
+	// The rendered spec keeps the tables
+	parsed, err := specparser.NewParser().Parse(specparser.Render(spec), "shop")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed.Tables, spec.Tables) {
+		t.Errorf("Expected tables to survive round trip, got %+v", parsed.Tables)
+	}
+}
+
+func TestParseMigrations(t *testing.T) {
+	src := `
+CREATE TABLE accounts (id integer primary key, name text, legacy text);
+ALTER TABLE accounts ADD COLUMN owner_id integer REFERENCES accounts (id);
+ALTER TABLE accounts DROP COLUMN legacy;
+ALTER TABLE accounts ADD CONSTRAINT accounts_name_key UNIQUE (name);
+CREATE TABLE scratch (id int);
+DROP TABLE IF EXISTS scratch;
+CREATE OR REPLACE FUNCTION touch() RETURNS trigger AS $$ BEGIN RETURN NEW; END; $$ LANGUAGE plpgsql;
+`
+	spec, err := Parse([]byte(src), "accounts")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(spec.Tables) != 1 {
+		t.Fatalf("Expected 1 table, got %d", len(spec.Tables))
+	}
+	accounts := spec.Tables[0]
+	var columns []string
+	for _, col := range accounts.Columns {
+		columns = append(columns, col.Name)
+	}
+	if got := strings.Join(columns, ","); got != "id,name,owner_id" {
+		t.Errorf("Columns = %s, expected id,name,owner_id", got)
+	}
+	if len(accounts.ForeignKeys) != 1 || accounts.ForeignKeys[0].RefTable != "accounts" {
+		t.Errorf("Unexpected foreign keys: %+v", accounts.ForeignKeys)
+	}
+	if len(accounts.Indexes) != 1 || accounts.Indexes[0].Name != "accounts_name_key" || !accounts.Indexes[0].Unique {
+		t.Errorf("Expected named unique constraint, got %+v", accounts.Indexes)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"unterminated string", `CREATE TABLE a (b text DEFAULT 'oops);`},
+		{"unterminated comment", `/* CREATE TABLE a (b int);`},
+		{"missing table name", `CREATE TABLE (b int);`},
+		{"unterminated table", `CREATE TABLE a (b int`},
+		{"unterminated index columns", `CREATE INDEX ix ON users (;`},
+		{"unterminated referenced columns", `CREATE TABLE t (id INT REFERENCES o(;`},
+		{"unterminated key columns", `CREATE TABLE t (a INT, PRIMARY KEY (;`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.src), "broken"); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestImportDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"migrations/001_init.up.sql": `CREATE TABLE teams (id serial primary key, name text not null);
+CREATE TABLE members (id serial primary key, team_id int not null references teams(id));`,
+		"migrations/001_init.down.sql": `DROP TABLE members; DROP TABLE teams;`,
+		"migrations/002_email.up.sql":  `ALTER TABLE members ADD COLUMN email text;`,
+		"models/team.go": `package models
+
+// Team groups members.
+type Team struct {
+	ID      int
+	Name    string
+	Members []Member
+}
+
+func (Team) TableName() string { return "teams" }
+
+type Member struct {
+	ID     int
+	TeamID int
+	Team   Team
+}
+
+func (Member) TableName() string { return "members" }
+`,
+		"node_modules/x/schema.sql": `this is not sql`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec, err := ImportDir(dir)
+	if err != nil {
+		t.Fatalf("ImportDir() error: %v", err)
+	}
+	if len(spec.Tables) != 2 {
+		t.Fatalf("Expected 2 tables, got %d", len(spec.Tables))
+	}
+	teams, members := spec.Tables[0], spec.Tables[1]
+	if teams.Description != "Groups members." || teams.Column("name").Nullable {
+		t.Errorf("Expected DDL columns with the model's description, got %+v", teams)
+	}
+	if members.Column("email") == nil {
+		t.Error("Expected migrations to apply in order")
+	}
+	if len(teams.Relations) != 1 || teams.Relations[0].Kind != specparser.RelationHasMany || teams.Relations[0].Table != "members" {
+		t.Errorf("Unexpected team relations: %+v", teams.Relations)
+	}
+}
@@ -0,0 +1,208 @@
+package dbschema
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// javaPrimitives are the Java types that cannot hold null.
+var javaPrimitives = map[string]bool{
+	"int": true, "long": true, "short": true, "byte": true,
+	"boolean": true, "double": true, "float": true, "char": true,
+}
+
+var (
+	javaFieldPattern  = regexp.MustCompile(`^((?:(?:public|private|protected|static|final|transient|volatile)\s+)*)([\w.<>?,\[\] ]+?)\s+(\w+)\s*(?:=.*)?$`)
+	jpaIndexPattern   = regexp.MustCompile(`@Index\s*\(([^)]*)\)`)
+	jpaUniquePattern  = regexp.MustCompile(`@UniqueConstraint\s*\(([^)]*)\)`)
+	jpaJoinColPattern = regexp.MustCompile(`@JoinColumn\s*\(([^)]*)\)`)
+)
+
+// jpaModels extracts the @Entity classes of Java and Kotlin sources. Join
+// tables named by @JoinTable become tables of their own.
+func jpaModels(files []sourceFile) []*specparser.SpecTable {
+	var tables []*specparser.SpecTable
+	for _, file := range files {
+		for _, class := range findClasses(file.content, false) {
+			if _, ok := annotationArgs(class.annotations, "Entity"); !ok {
+				continue
+			}
+			name := toSnakeCase(class.name)
+			if args, ok := annotationArgs(class.annotations, "Table"); ok {
+				_, kw := keywordArgs(args)
+				name = firstNonEmpty(unquote(kw["name"]), name)
+			}
+			t := ormTable(&tables, name, class.name)
+			t.Description = class.doc
+			if args, ok := annotationArgs(class.annotations, "Table"); ok {
+				jpaTableIndexes(t, args)
+			}
+			for _, m := range class.members {
+				jpaField(&tables, t, m)
+			}
+		}
+	}
+	return tables
+}
+
+// jpaTableIndexes adds the indexes and unique constraints of @Table.
+func jpaTableIndexes(t *specparser.SpecTable, args string) {
+	for _, m := range jpaIndexPattern.FindAllStringSubmatch(args, -1) {
+		_, kw := keywordArgs(m[1])
+		idx := specparser.SpecIndex{Name: unquote(kw["name"]), Columns: jpaColumnList(unquote(kw["columnList"])), Unique: isTrue(kw["unique"])}
+		if idx.Name == "" {
+			idx.Name = t.Name + "_" + strings.Join(idx.Columns, "_") + "_idx"
+		}
+		t.Indexes = append(t.Indexes, idx)
+	}
+	for _, m := range jpaUniquePattern.FindAllStringSubmatch(args, -1) {
+		_, kw := keywordArgs(m[1])
+		var columns []string
+		for _, c := range splitArgs(strings.Trim(kw["columnNames"], "{}")) {
+			columns = append(columns, unquote(c))
+		}
+		name := firstNonEmpty(unquote(kw["name"]), t.Name+"_"+strings.Join(columns, "_")+"_key")
+		t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: name, Columns: columns, Unique: true})
+	}
+}
+
+// jpaColumnList splits an @Index columnList, dropping sort orders.
+func jpaColumnList(list string) []string {
+	var columns []string
+	for _, part := range strings.Split(list, ",") {
+		if fields := strings.Fields(part); len(fields) > 0 {
+			columns = append(columns, fields[0])
+		}
+	}
+	return columns
+}
+
+// jpaField adds the column or relation of an entity field.
+func jpaField(tables *[]*specparser.SpecTable, t *specparser.SpecTable, m member) {
+	match := javaFieldPattern.FindStringSubmatch(m.decl)
+	if match == nil || strings.Contains(m.decl, "(") && !strings.Contains(m.decl, "=") {
+		return
+	}
+	modifiers, typ, name := match[1], strings.TrimSpace(match[2]), match[3]
+	if strings.Contains(modifiers, "static") || strings.Contains(modifiers, "transient") {
+		return
+	}
+	has := func(name string) bool {
+		_, ok := annotationArgs(m.annotations, name)
+		return ok
+	}
+	if has("Transient") {
+		return
+	}
+	if jpaRelation(tables, t, m, typ, name) {
+		return
+	}
+
+	col := specparser.SpecColumn{Name: toSnakeCase(name), Description: m.doc}
+	switch {
+	case typ == "byte[]" || typ == "Byte[]":
+		col.Type = "bytes"
+	case has("Enumerated"):
+		col.Type = "string"
+	default:
+		col.Type, _ = languageType(typ)
+	}
+	col.Nullable = !javaPrimitives[typ] && !has("NotNull") && !has("NonNull") && !has("NotBlank")
+	if args, ok := annotationArgs(m.annotations, "Column"); ok {
+		_, kw := keywordArgs(args)
+		col.Name = firstNonEmpty(unquote(kw["name"]), col.Name)
+		if strings.TrimSpace(kw["nullable"]) == "false" {
+			col.Nullable = false
+		}
+		col.Unique = isTrue(kw["unique"])
+		if def := unquote(kw["columnDefinition"]); def != "" {
+			col.SQLType = def
+		} else if length := strings.TrimSpace(kw["length"]); length != "" && col.Type == "string" {
+			col.SQLType = "varchar(" + length + ")"
+		}
+	}
+	if has("Id") {
+		t.PrimaryKey = append(t.PrimaryKey, col.Name)
+		col.Nullable = false
+		col.AutoIncrement = has("GeneratedValue")
+	}
+	addColumn(t, col)
+}
+
+// jpaRelation adds the relation of a field annotated @ManyToOne,
+// @OneToOne, @OneToMany or @ManyToMany, and reports whether it was one.
+func jpaRelation(tables *[]*specparser.SpecTable, t *specparser.SpecTable, m member, typ, name string) bool {
+	var kind, args string
+	for _, a := range m.annotations {
+		switch a.name {
+		case "ManyToOne", "OneToOne", "OneToMany", "ManyToMany":
+			kind, args = a.name, a.args
+		}
+	}
+	if kind == "" {
+		return false
+	}
+	_, kw := keywordArgs(args)
+	mappedBy := unquote(kw["mappedBy"])
+	target := typ
+	if i := strings.IndexByte(typ, '<'); i >= 0 {
+		target = strings.TrimSuffix(typ[i+1:], ">")
+	}
+	relation := specparser.SpecRelation{Name: toSnakeCase(name), Table: target}
+	joinColumn := ""
+	joinNullable := kind == "OneToOne" || strings.TrimSpace(kw["optional"]) != "false"
+	if joinArgs, ok := annotationArgs(m.annotations, "JoinColumn"); ok {
+		_, jkw := keywordArgs(joinArgs)
+		joinColumn = unquote(jkw["name"])
+		if strings.TrimSpace(jkw["nullable"]) == "false" {
+			joinNullable = false
+		}
+	}
+
+	switch {
+	case kind == "ManyToMany":
+		relation.Kind = specparser.RelationManyToMany
+		if joinArgs, ok := annotationArgs(m.annotations, "JoinTable"); ok {
+			_, jkw := keywordArgs(joinArgs)
+			relation.Through = unquote(jkw["name"])
+			jpaJoinTable(tables, relation.Through, t.Entity, target, joinArgs)
+		}
+	case mappedBy != "" && kind == "OneToOne":
+		relation.Kind = specparser.RelationHasOne
+		relation.Columns = []string{toSnakeCase(mappedBy) + "_id"}
+	case mappedBy != "" || kind == "OneToMany":
+		relation.Kind = specparser.RelationHasMany
+		relation.Columns = []string{firstNonEmpty(joinColumn, toSnakeCase(firstNonEmpty(mappedBy, t.Entity))+"_id")}
+	default:
+		relation.Kind = specparser.RelationBelongsTo
+		relation.Columns = []string{firstNonEmpty(joinColumn, toSnakeCase(name)+"_id")}
+		addColumn(t, specparser.SpecColumn{Name: relation.Columns[0], Nullable: joinNullable, Unique: kind == "OneToOne", Description: m.doc})
+		t.ForeignKeys = append(t.ForeignKeys, specparser.SpecForeignKey{Columns: relation.Columns, RefTable: target})
+	}
+	t.Relations = append(t.Relations, relation)
+	return true
+}
+
+// jpaJoinTable adds the join table of a @ManyToMany relation with a
+// foreign key to each side.
+func jpaJoinTable(tables *[]*specparser.SpecTable, name, owner, target, args string) {
+	if name == "" {
+		return
+	}
+	columns := []string{toSnakeCase(owner) + "_id", toSnakeCase(target) + "_id"}
+	for i, m := range jpaJoinColPattern.FindAllStringSubmatch(args, 2) {
+		_, kw := keywordArgs(m[1])
+		columns[i] = firstNonEmpty(unquote(kw["name"]), columns[i])
+	}
+	t := ormTable(tables, name, "")
+	if len(t.Columns) > 0 {
+		return
+	}
+	t.PrimaryKey = columns
+	for i, ref := range []string{owner, target} {
+		t.Columns = append(t.Columns, specparser.SpecColumn{Name: columns[i]})
+		t.ForeignKeys = append(t.ForeignKeys, specparser.SpecForeignKey{Columns: []string{columns[i]}, RefTable: ref})
+	}
+}
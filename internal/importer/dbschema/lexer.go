@@ -0,0 +1,173 @@
+// Package dbschema imports database schemas into spec analyses: SQL DDL in
+// the Postgres, MySQL and SQLite dialects, and ORM models (GORM, JPA, EF
+// Core, SQLAlchemy, Django and Diesel) become tables with their columns,
+// keys, indexes and relations, and an entity type per table.
+package dbschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind classifies a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenQuoted
+	tokenNumber
+	tokenString
+	tokenPunct
+)
+
+// token is a single lexical token.
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// is reports whether the token is the given keyword or punctuation,
+// ignoring case. Quoted identifiers never match keywords.
+func (t token) is(text string) bool {
+	return (t.kind == tokenName || t.kind == tokenPunct) && strings.EqualFold(t.text, text)
+}
+
+// lex splits SQL source into tokens. Comments are dropped; identifiers
+// quoted with double quotes, backticks or brackets become quoted tokens
+// holding the bare name.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+
+		case strings.HasPrefix(src[i:], "--") || c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+
+		case c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(src); j++ {
+				if src[j] == '\'' {
+					if j+1 < len(src) && src[j+1] == '\'' {
+						sb.WriteByte('\'')
+						j++
+						continue
+					}
+					break
+				}
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				sb.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			line += strings.Count(src[i:j], "\n")
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), line: line})
+			i = j + 1
+
+		case c == '"' || c == '`' || c == '[':
+			closing := map[byte]byte{'"': '"', '`': '`', '[': ']'}[c]
+			end := strings.IndexByte(src[i+1:], closing)
+			if c == '[' && (end <= 0 || !isNameStart(src[i+1]) || strings.ContainsAny(src[i+1:i+1+end], " \n,")) {
+				// An array suffix such as TEXT[] rather than a quoted name
+				tokens = append(tokens, token{kind: tokenPunct, text: "[", line: line})
+				i++
+				continue
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted identifier", line)
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: src[i+1 : i+1+end], line: line})
+			i += end + 2
+
+		case c == '$' && dollarTag(src[i:]) != "":
+			tag := dollarTag(src[i:])
+			end := strings.Index(src[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated dollar-quoted string", line)
+			}
+			body := src[i+len(tag) : i+len(tag)+end]
+			tokens = append(tokens, token{kind: tokenString, text: body, line: line})
+			line += strings.Count(body, "\n")
+			i += len(tag)*2 + end
+
+		case isNameStart(c):
+			j := i
+			for j < len(src) && (isNameStart(src[j]) || isDigit(src[j]) || src[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenName, text: src[i:j], line: line})
+			i = j
+
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			j := i + 1
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.' || src[j] == 'e' || src[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[i:j], line: line})
+			i = j
+
+		case len(src) > i+1 && operators[src[i:i+2]]:
+			tokens = append(tokens, token{kind: tokenPunct, text: src[i : i+2], line: line})
+			i += 2
+
+		default:
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c), line: line})
+			i++
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, line: line})
+	return tokens, nil
+}
+
+// operators are the two-character operators kept as single tokens.
+var operators = map[string]bool{
+	"::": true, ">=": true, "<=": true, "<>": true, "!=": true, "||": true, "->": true,
+}
+
+// dollarTag returns the opening tag of a Postgres dollar-quoted string,
+// such as "$$" or "$body$", or "" when s does not start with one.
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		switch {
+		case s[j] == '$':
+			return s[:j+1]
+		case !isNameStart(s[j]) && !(j > 1 && isDigit(s[j])):
+			return ""
+		}
+	}
+	return ""
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
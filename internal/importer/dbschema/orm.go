@@ -0,0 +1,498 @@
+package dbschema
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
+// sourceFile is a source file scanned for ORM models.
+type sourceFile struct {
+	path    string
+	content string
+}
+
+// modelExtractors extract the ORM models of a language's source files.
+// They see all of a language's files at once, since a model's relations
+// and table names often live in other files.
+var modelExtractors = map[string]func(files []sourceFile) []*specparser.SpecTable{
+	"go":     gormModels,
+	"java":   jpaModels,
+	"csharp": efCoreModels,
+	"python": pythonModels,
+	"rust":   dieselModels,
+}
+
+// modelLanguage returns the language of a source file that may declare ORM
+// models, or "".
+func modelLanguage(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		if strings.HasSuffix(path, "_test.go") {
+			return ""
+		}
+		return "go"
+	case ".java", ".kt":
+		return "java"
+	case ".cs":
+		return "csharp"
+	case ".py":
+		return "python"
+	case ".rs":
+		return "rust"
+	}
+	return ""
+}
+
+// ParseModels extracts the tables declared by the ORM models of a source
+// file; its extension selects the language. Relations and foreign keys may
+// name entities rather than tables until the spec is built.
+func ParseModels(path string, content []byte) []*specparser.SpecTable {
+	return parseModels(map[string][]sourceFile{modelLanguage(path): {{path: path, content: string(content)}}})
+}
+
+// ParseModelFile extracts the ORM models of a source file into a spec
+// analysis.
+func ParseModelFile(path string, content []byte) *specparser.SpecAnalysis {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return newSpec(name, ParseModels(path, content))
+}
+
+// parseModels extracts the ORM models of source files grouped by language.
+func parseModels(files map[string][]sourceFile) []*specparser.SpecTable {
+	var tables []*specparser.SpecTable
+	for _, lang := range []string{"go", "java", "csharp", "python", "rust"} {
+		if len(files[lang]) > 0 {
+			tables = append(tables, modelExtractors[lang](files[lang])...)
+		}
+	}
+	return tables
+}
+
+// languageType maps a field's type in an ORM model to a pseudo-type, and
+// reports whether it is optional. Types that are not primitives or
+// containers of them, such as enums, map to string.
+func languageType(typ string) (string, bool) {
+	e, err := typeexpr.Parse(strings.TrimSpace(typ))
+	if err != nil {
+		return "string", false
+	}
+	optional := false
+	for e.Kind == typeexpr.Optional || e.Kind == typeexpr.Pointer {
+		e = e.Args[0]
+		optional = true
+	}
+	if e.Kind == typeexpr.Named {
+		return "string", optional
+	}
+	return e.String(), optional
+}
+
+// ormTable returns the named table from tables, adding it when missing.
+func ormTable(tables *[]*specparser.SpecTable, name, entity string) *specparser.SpecTable {
+	for _, t := range *tables {
+		if strings.EqualFold(t.Name, name) {
+			if t.Entity == "" {
+				t.Entity = entity
+			}
+			return t
+		}
+	}
+	t := &specparser.SpecTable{Name: name, Entity: entity}
+	*tables = append(*tables, t)
+	return t
+}
+
+// addColumn adds a column to a table unless one of that name exists, and
+// returns the table's column.
+func addColumn(t *specparser.SpecTable, col specparser.SpecColumn) *specparser.SpecColumn {
+	if existing := t.Column(col.Name); existing != nil {
+		return existing
+	}
+	t.Columns = append(t.Columns, col)
+	return &t.Columns[len(t.Columns)-1]
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or
+// -1. Braces inside string literals are ignored.
+func matchingBrace(s string, open int) int {
+	return matchingDelimiter(s, open, s[open], map[byte]byte{'{': '}', '(': ')', '[': ']'}[s[open]])
+}
+
+// matchingDelimiter returns the index of the delimiter closing the one at
+// open, or -1.
+func matchingDelimiter(s string, open int, opening, closing byte) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == opening:
+			depth++
+		case c == closing:
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitArgs splits a call's argument list at top-level commas.
+func splitArgs(args string) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(args); i++ {
+		c := args[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[' || c == '{' || c == '<':
+			depth++
+		case c == ')' || c == ']' || c == '}' || c == '>':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(args[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(args[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// keywordArgs splits call arguments into positional ones and name=value
+// (or name: value) keyword ones.
+func keywordArgs(args string) ([]string, map[string]string) {
+	var positional []string
+	keywords := make(map[string]string)
+	for _, arg := range splitArgs(args) {
+		if name, value, ok := cutKeyword(arg); ok {
+			keywords[name] = value
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	return positional, keywords
+}
+
+// cutKeyword splits "name = value" into its parts.
+func cutKeyword(arg string) (string, string, bool) {
+	i := strings.IndexAny(arg, "=:")
+	if i <= 0 || (i+1 < len(arg) && arg[i+1] == '=') || (arg[i] == ':' && i+1 < len(arg) && arg[i+1] == ':') {
+		return "", "", false
+	}
+	name := strings.TrimSpace(arg[:i])
+	for _, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return "", "", false
+		}
+	}
+	return name, strings.TrimSpace(arg[i+1:]), true
+}
+
+// unquote strips string quotes from a literal.
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "@")
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// isTrue reports whether a literal reads as true in any of the languages.
+func isTrue(s string) bool {
+	switch strings.TrimSpace(s) {
+	case "true", "True":
+		return true
+	}
+	return false
+}
+
+// blankComments returns src with the text of its comments replaced by
+// spaces, so offsets into it match src while comment text no longer
+// affects brace matching. hash selects # comments (Python) instead of //
+// and /* */ ones.
+func blankComments(src string, hash bool) string {
+	b := []byte(src)
+	var quote byte
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote || c == '\n' {
+				quote = 0
+			}
+		case c == '"' || (c == '\'' && hash):
+			quote = c
+		case hash && c == '#':
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		case !hash && c == '/' && i+1 < len(b) && b[i+1] == '/':
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		case !hash && c == '/' && i+1 < len(b) && b[i+1] == '*':
+			for ; i < len(b) && !(b[i] == '*' && i+1 < len(b) && b[i+1] == '/'); i++ {
+				if b[i] != '\n' {
+					b[i] = ' '
+				}
+			}
+			if i+1 < len(b) {
+				b[i], b[i+1] = ' ', ' '
+				i++
+			}
+		}
+	}
+	return string(b)
+}
+
+// classDecl is a Java or C# class declaration.
+type classDecl struct {
+	name string
+
+	// annotations are the Java annotations or C# attributes on the class
+	annotations []annotation
+
+	// doc is the class's doc comment as plain text
+	doc string
+
+	// members are the class body's declarations, split at top-level
+	// semicolons and closing braces
+	members []member
+}
+
+// member is a declaration in a class body.
+type member struct {
+	annotations []annotation
+	doc         string
+
+	// decl is the declaration without annotations and comments, such as
+	// "private String name" or "public int Id { get; set; }"
+	decl string
+}
+
+// annotation is a Java annotation or C# attribute with its argument list.
+type annotation struct {
+	name string
+	args string
+}
+
+// annotationArgs returns the arguments of the named annotation and whether
+// it is present.
+func annotationArgs(annotations []annotation, name string) (string, bool) {
+	for _, a := range annotations {
+		if a.name == name {
+			return a.args, true
+		}
+	}
+	return "", false
+}
+
+var classPattern = regexp.MustCompile(`\b(?:class|record)\s+(\w+)[^{;]*\{`)
+
+// findClasses returns the classes declared in Java (attributes false) or
+// C# (attributes true) source.
+func findClasses(src string, attributes bool) []classDecl {
+	blank := blankComments(src, false)
+	var classes []classDecl
+	for _, m := range classPattern.FindAllStringSubmatchIndex(blank, -1) {
+		open := m[1] - 1
+		end := matchingBrace(blank, open)
+		if end < 0 {
+			continue
+		}
+		start := declarationStart(blank, m[0])
+		annotations, _ := leadingAnnotations(src[start:m[0]], blank[start:m[0]], attributes)
+		classes = append(classes, classDecl{
+			name:        src[m[2]:m[3]],
+			annotations: annotations,
+			doc:         docComment(src[start:m[0]]),
+			members:     splitMembers(src[open+1:end], blank[open+1:end], attributes),
+		})
+	}
+	return classes
+}
+
+// declarationStart returns where the declaration ending at end starts:
+// after the previous semicolon or brace outside parentheses and brackets.
+func declarationStart(blank string, end int) int {
+	depth := 0
+	for i := end - 1; i >= 0; i-- {
+		switch blank[i] {
+		case ')', ']':
+			depth++
+		case '(', '[':
+			depth--
+		case ';', '{', '}':
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// splitMembers splits a class body into its member declarations. A member
+// ends at a top-level semicolon or at the brace closing its body, so
+// methods, properties and nested types are single members.
+func splitMembers(body, blank string, attributes bool) []member {
+	var members []member
+	start, depth := 0, 0
+	var quote byte
+	for i := 0; i < len(blank); i++ {
+		c := blank[i]
+		end := -1
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{' || c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == '}':
+			if depth--; depth == 0 {
+				end = i + 1
+			}
+		case c == ';' && depth == 0:
+			end = i
+		}
+		if end < 0 {
+			continue
+		}
+		annotations, rest := leadingAnnotations(body[start:end], blank[start:end], attributes)
+		members = append(members, member{
+			annotations: annotations,
+			doc:         docComment(body[start:end]),
+			decl:        strings.Join(strings.Fields(blankComments(rest, false)), " "),
+		})
+		start = end + 1
+		if c == '}' {
+			start = end
+		}
+	}
+	return members
+}
+
+// leadingAnnotations parses the annotations (@Name(args)) or attributes
+// ([Name(args), Other]) at the start of a declaration, and returns them
+// with the rest of the declaration.
+func leadingAnnotations(text, blank string, attributes bool) ([]annotation, string) {
+	var annotations []annotation
+	i := 0
+	for {
+		for i < len(blank) && (blank[i] == ' ' || blank[i] == '\t' || blank[i] == '\n' || blank[i] == '\r') {
+			i++
+		}
+		if i >= len(blank) {
+			break
+		}
+
+		if attributes && blank[i] == '[' {
+			end := matchingBrace(blank, i)
+			if end < 0 {
+				break
+			}
+			for _, part := range splitArgs(text[i+1 : end]) {
+				annotations = append(annotations, newAnnotation(part))
+			}
+			i = end + 1
+			continue
+		}
+		if attributes || blank[i] != '@' {
+			break
+		}
+
+		j := i + 1
+		for j < len(blank) && (isNameStart(blank[j]) || isDigit(blank[j]) || blank[j] == '.') {
+			j++
+		}
+		k := j
+		for k < len(blank) && (blank[k] == ' ' || blank[k] == '\t') {
+			k++
+		}
+		if k < len(blank) && blank[k] == '(' {
+			end := matchingBrace(blank, k)
+			if end < 0 {
+				break
+			}
+			annotations = append(annotations, newAnnotation(text[i+1:end+1]))
+			i = end + 1
+			continue
+		}
+		annotations = append(annotations, newAnnotation(text[i+1:j]))
+		i = j
+	}
+	return annotations, text[i:]
+}
+
+// newAnnotation parses "Name(args)" or "Name"; a qualified name keeps its
+// last part and a C# Attribute suffix is dropped.
+func newAnnotation(text string) annotation {
+	text = strings.TrimSpace(text)
+	name, args := text, ""
+	if i := strings.IndexByte(text, '('); i >= 0 && strings.HasSuffix(text, ")") {
+		name, args = strings.TrimSpace(text[:i]), text[i+1:len(text)-1]
+	}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return annotation{name: strings.TrimSuffix(name, "Attribute"), args: args}
+}
+
+var (
+	javadocPattern = regexp.MustCompile(`(?s)/\*\*(.*?)\*/`)
+	xmlDocPattern  = regexp.MustCompile(`(?m)^\s*///(.*)$`)
+	xmlTagPattern  = regexp.MustCompile(`<[^>]+>`)
+)
+
+// docComment returns the first sentence-bearing paragraph of the last
+// Javadoc or /// doc comment in text, as plain text without tags.
+func docComment(text string) string {
+	var lines []string
+	if m := javadocPattern.FindAllStringSubmatch(text, -1); m != nil {
+		for _, line := range strings.Split(m[len(m)-1][1], "\n") {
+			line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+			if strings.HasPrefix(line, "@") {
+				break
+			}
+			lines = append(lines, line)
+		}
+	} else {
+		for _, m := range xmlDocPattern.FindAllStringSubmatch(text, -1) {
+			lines = append(lines, m[1])
+		}
+	}
+	doc := strings.Join(strings.Fields(xmlTagPattern.ReplaceAllString(strings.Join(lines, " "), " ")), " ")
+	return doc
+}
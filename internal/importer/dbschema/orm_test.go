@@ -0,0 +1,350 @@
+package dbschema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// describeTables summarizes tables as "name(col type[?][*], ...)" lines,
+// where ? marks a nullable column and * a primary key column, followed by
+// their foreign keys and relations.
+func describeTables(tables []specparser.SpecTable) string {
+	var lines []string
+	for _, t := range tables {
+		var cols []string
+		for _, c := range t.Columns {
+			col := c.Name + " " + c.Type
+			if c.Nullable {
+				col += "?"
+			}
+			if containsFold(t.PrimaryKey, c.Name) {
+				col += "*"
+			}
+			cols = append(cols, col)
+		}
+		lines = append(lines, fmt.Sprintf("%s(%s)", t.Name, strings.Join(cols, ", ")))
+		for _, fk := range t.ForeignKeys {
+			lines = append(lines, fmt.Sprintf("  fk %s -> %s(%s)", strings.Join(fk.Columns, ","), fk.RefTable, strings.Join(fk.RefColumns, ",")))
+		}
+		for _, r := range t.Relations {
+			rel := fmt.Sprintf("  %s %s %s", r.Name, r.Kind, r.Table)
+			if r.Through != "" {
+				rel += " through " + r.Through
+			}
+			lines = append(lines, rel)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestParseModels(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		src      string
+		expected string
+	}{
+		{
+			name: "gorm",
+			path: "models.go",
+			src: `package models
+
+type Company struct {
+	ID    uint
+	Name  string ` + "`gorm:\"size:100;not null\"`" + `
+	Users []User
+}
+
+type User struct {
+	gorm.Model
+	Email     *string ` + "`gorm:\"uniqueIndex\"`" + `
+	CompanyID uint
+	Company   Company
+	Languages []Language ` + "`gorm:\"many2many:user_languages\"`" + `
+}
+
+type Language struct {
+	Code string ` + "`gorm:\"primaryKey\"`" + `
+}
+`,
+			expected: `companies(id int*, name string)
+  users has many users
+users(id int64*, created_at datetime?, updated_at datetime?, deleted_at datetime?, email string?, company_id int)
+  fk company_id -> companies(id)
+  company belongs to companies
+  languages many to many languages through user_languages
+languages(code string*)
+  users many to many users through user_languages
+user_languages(user_id int64*, language_code string*)
+  fk user_id -> users(id)
+  fk language_code -> languages(code)
+  user belongs to users
+  language belongs to languages`,
+		},
+		{
+			name: "jpa",
+			path: "Post.java",
+			src: `@Entity
+@Table(name = "users")
+public class User {
+    @Id @GeneratedValue
+    private Long id;
+
+    @Column(nullable = false, length = 120)
+    private String email;
+
+    private int age;
+
+    @OneToMany(mappedBy = "author")
+    private List<Post> posts = new ArrayList<>();
+
+    @Transient
+    private String cached;
+
+    public Long getId() { return id; }
+}
+
+@Entity
+public class Post {
+    @Id @GeneratedValue
+    private Long id;
+
+    @ManyToOne(optional = false)
+    @JoinColumn(name = "author_id")
+    private User author;
+
+    @ManyToMany
+    @JoinTable(name = "post_tags", joinColumns = @JoinColumn(name = "post_id"), inverseJoinColumns = @JoinColumn(name = "tag_id"))
+    private Set<Tag> tags;
+}
+
+@Entity
+class Tag {
+    @Id private Integer id;
+}
+`,
+			expected: `users(id int64*, email string, age int)
+  posts has many post
+post(id int64*, author_id int64)
+  fk author_id -> users(id)
+  author belongs to users
+  tags many to many tag through post_tags
+post_tags(post_id int64*, tag_id int*)
+  fk post_id -> post(id)
+  fk tag_id -> tag(id)
+  post belongs to post
+  tag belongs to tag
+tag(id int*)
+  post many to many post through post_tags`,
+		},
+		{
+			name: "ef core",
+			path: "Models.cs",
+			src: `public class Customer
+{
+    public int Id { get; set; }
+
+    [Required]
+    [MaxLength(200)]
+    public string Email { get; set; } = "";
+
+    public DateTime? LastSeen { get; set; }
+
+    public ICollection<Order> Orders { get; set; } = new List<Order>();
+}
+
+[Table("purchase_orders")]
+public class Order
+{
+    [Key]
+    public Guid OrderKey { get; set; }
+
+    public int CustomerId { get; set; }
+    public Customer Customer { get; set; }
+
+    [NotMapped]
+    public bool IsBig => false;
+}
+
+public class ShopContext : DbContext
+{
+    public DbSet<Customer> Customers { get; set; }
+}
+`,
+			expected: `Customers(id int*, email string, last_seen datetime?)
+  orders has many purchase_orders
+purchase_orders(order_key uuid*, customer_id int)
+  fk customer_id -> Customers(id)
+  customer belongs to Customers`,
+		},
+		{
+			name: "sqlalchemy",
+			path: "models.py",
+			src: `member_roles = Table(
+    "member_roles",
+    Base.metadata,
+    Column("member_id", ForeignKey("members.id"), primary_key=True),
+    Column("role_id", ForeignKey("roles.id"), primary_key=True),
+)
+
+class Member(Base):
+    """A club member."""
+    __tablename__ = "members"
+
+    id: Mapped[int] = mapped_column(primary_key=True)
+    last: Mapped[Optional[str]] = mapped_column(String(50), index=True)  # family name
+    roles: Mapped[List["Role"]] = relationship(secondary=member_roles)
+
+class Role(Base):
+    __tablename__ = "roles"
+    id = Column(Integer, primary_key=True)
+    name = Column(String(30), nullable=False, unique=True)
+
+class Card(Base):
+    __tablename__ = "cards"
+    id: Mapped[int] = mapped_column(primary_key=True)
+    member_id: Mapped[int] = mapped_column(ForeignKey("members.id", ondelete="CASCADE"), unique=True)
+    member: Mapped["Member"] = relationship(back_populates="card")
+`,
+			expected: `member_roles(member_id int*, role_id int*)
+  fk member_id -> members(id)
+  fk role_id -> roles(id)
+  member belongs to members
+  role belongs to roles
+members(id int*, last string?)
+  roles many to many roles through member_roles
+  card has one cards
+roles(id int*, name string)
+  members many to many members through member_roles
+cards(id int*, member_id int)
+  fk member_id -> members(id)
+  member belongs to members`,
+		},
+		{
+			name: "django",
+			path: "blog/models.py",
+			src: `from django.db import models
+
+
+class Author(models.Model):
+    name = models.CharField(max_length=100)
+
+
+class Entry(models.Model):
+    headline = models.CharField(max_length=255, help_text="Shown in listings")
+    pub_date = models.DateField(null=True)
+    author = models.ForeignKey(Author, on_delete=models.CASCADE)
+    tags = models.ManyToManyField("Tag")
+
+    class Meta:
+        db_table = "entries"
+
+
+class Tag(models.Model):
+    slug = models.SlugField(primary_key=True)
+`,
+			expected: `blog_author(id int64*, name string)
+  entries has many entries
+entries(id int64*, headline string, pub_date date?, author_id int64)
+  fk author_id -> blog_author(id)
+  author belongs to blog_author
+  tags many to many blog_tag through entries_tags
+entries_tags(id int64*, entry_id int64, tag_id string)
+  fk entry_id -> entries(id)
+  fk tag_id -> blog_tag(slug)
+  entry belongs to entries
+  tag belongs to blog_tag
+blog_tag(slug string*)
+  entries many to many entries through entries_tags`,
+		},
+		{
+			name: "diesel",
+			path: "schema.rs",
+			src: `diesel::table! {
+    /// Published books.
+    books (id) {
+        id -> Int4,
+        title -> Varchar,
+        isbn -> Nullable<Text>,
+        author_id -> Int4,
+    }
+}
+
+diesel::table! {
+    authors {
+        id -> Int8,
+        born -> Nullable<diesel::sql_types::Date>,
+    }
+}
+
+diesel::joinable!(books -> authors (author_id));
+
+#[derive(Queryable, Identifiable)]
+#[diesel(table_name = books)]
+pub struct Book {
+    pub id: i32,
+}
+`,
+			expected: `books(id int*, title string, isbn string?, author_id int)
+  fk author_id -> authors(id)
+  author belongs to authors
+authors(id int64*, born date?)
+  books has many books`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := ParseModelFile(tt.path, []byte(tt.src))
+			if got := describeTables(spec.Tables); got != tt.expected {
+				t.Errorf("Tables =\n%s\nexpected\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseModelsDetails(t *testing.T) {
+	spec := ParseModelFile("User.java", []byte(`/** A registered account. */
+@Entity
+@Table(name = "users", indexes = { @Index(name = "idx_users_email", columnList = "email", unique = true) })
+public class User {
+    @Id
+    private Long id;
+
+    /** Login address; never shared. */
+    @Column(nullable = false, length = 120)
+    private String email;
+
+    @Enumerated(EnumType.STRING)
+    private Role role;
+}
+`))
+	if len(spec.Tables) != 1 {
+		t.Fatalf("Expected 1 table, got %d", len(spec.Tables))
+	}
+	users := spec.Tables[0]
+	if users.Description != "A registered account." || users.Entity != "User" {
+		t.Errorf("Unexpected table: %+v", users)
+	}
+	if email := users.Column("email"); email.SQLType != "varchar(120)" || email.Description != "Login address; never shared." {
+		t.Errorf("Unexpected email column: %+v", email)
+	}
+	if role := users.Column("role"); role.Type != "string" || !role.Nullable {
+		t.Errorf("Expected enum column to be a nullable string, got %+v", role)
+	}
+	if len(users.Indexes) != 1 || users.Indexes[0].Name != "idx_users_email" || !users.Indexes[0].Unique {
+		t.Errorf("Unexpected indexes: %+v", users.Indexes)
+	}
+
+	// Models in other files of the language are visible to relations
+	tables := parseModels(map[string][]sourceFile{"csharp": {
+		{path: "Context.cs", content: "class AppContext : DbContext { public DbSet<Widget> Widgets { get; set; } }"},
+		{path: "Widget.cs", content: "[Index(nameof(Code), IsUnique = true)]\nclass Widget { public int Id { get; set; } public string Code { get; set; } }"},
+	}})
+	if len(tables) != 1 || tables[0].Name != "Widgets" || len(tables[0].Indexes) != 1 || !tables[0].Indexes[0].Unique {
+		t.Errorf("Expected DbSet from another file to name the table, got %+v", tables)
+	}
+}
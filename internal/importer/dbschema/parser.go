@@ -0,0 +1,722 @@
+package dbschema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// schema is the tables a DDL script declares, in declaration order.
+type schema struct {
+	tables []*specparser.SpecTable
+	byName map[string]*specparser.SpecTable
+}
+
+// table returns the named table, or nil.
+func (s *schema) table(name string) *specparser.SpecTable {
+	return s.byName[strings.ToLower(name)]
+}
+
+// parser is a statement-level parser over lexed tokens. Statements other
+// than CREATE TABLE, CREATE INDEX, ALTER TABLE, DROP TABLE and COMMENT ON
+// are skipped.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// columnStops are the keywords that end a column's type and start its
+// constraints.
+var columnStops = map[string]bool{
+	"not": true, "null": true, "primary": true, "unique": true, "default": true,
+	"references": true, "check": true, "constraint": true, "auto_increment": true,
+	"autoincrement": true, "generated": true, "collate": true, "comment": true,
+	"on": true, "identity": true, "as": true, "charset": true, "stored": true,
+	"virtual": true, "invisible": true, "visible": true, "key": true,
+}
+
+// newSchema returns an empty schema.
+func newSchema() *schema {
+	return &schema{byName: make(map[string]*specparser.SpecTable)}
+}
+
+// parse applies a DDL script to the schema. Scripts are applied in order,
+// so a migration may alter or drop the tables of an earlier one.
+func (s *schema) parse(src string) error {
+	tokens, err := lex(strings.TrimPrefix(src, "\uFEFF"))
+	if err != nil {
+		return err
+	}
+
+	p := &parser{tokens: tokens}
+	for p.peek().kind != tokenEOF {
+		if p.accept(";") {
+			continue
+		}
+		if err := p.statement(s); err != nil {
+			return err
+		}
+		p.skipStatement()
+	}
+	return nil
+}
+
+// drop removes the named table.
+func (s *schema) drop(name string) {
+	table := s.table(name)
+	if table == nil {
+		return
+	}
+	delete(s.byName, strings.ToLower(name))
+	for i, t := range s.tables {
+		if t == table {
+			s.tables = append(s.tables[:i], s.tables[i+1:]...)
+			return
+		}
+	}
+}
+
+// statement parses one statement up to, but not including, its semicolon.
+func (p *parser) statement(s *schema) error {
+	switch {
+	case p.accept("create"):
+		p.accept("or", "replace")
+		unique := p.accept("unique")
+		for p.acceptAny("temp", "temporary", "unlogged", "global", "local", "fulltext", "spatial") {
+		}
+		switch {
+		case p.accept("table"):
+			return p.createTable(s)
+		case p.accept("index"):
+			return p.createIndex(s, unique)
+		}
+
+	case p.accept("alter", "table"):
+		return p.alterTable(s)
+
+	case p.accept("drop", "table"):
+		p.accept("if", "exists")
+		for {
+			name, ok := p.qualifiedName()
+			if !ok {
+				break
+			}
+			s.drop(name)
+			if !p.accept(",") {
+				break
+			}
+		}
+
+	case p.accept("comment", "on"):
+		p.commentOn(s)
+	}
+	return nil
+}
+
+// createTable parses the rest of a CREATE TABLE statement.
+func (p *parser) createTable(s *schema) error {
+	p.accept("if", "not", "exists")
+	name, ok := p.qualifiedName()
+	if !ok {
+		return p.errorf(p.peek(), "expected table name, got %q", p.peek().text)
+	}
+	if !p.accept("(") {
+		// CREATE TABLE ... AS SELECT, LIKE and PARTITION OF copy another
+		// table's shape and are skipped
+		return nil
+	}
+
+	table := &specparser.SpecTable{Name: name}
+	for {
+		if err := p.tableElement(table); err != nil {
+			return err
+		}
+		if p.accept(",") {
+			continue
+		}
+		if !p.accept(")") {
+			return p.errorf(p.peek(), "expected , or ) in table %s, got %q", name, p.peek().text)
+		}
+		break
+	}
+
+	// MySQL table options, such as ENGINE=InnoDB COMMENT='...'
+	for !p.at(";") && p.peek().kind != tokenEOF {
+		if p.accept("comment") {
+			p.accept("=")
+			if t := p.peek(); t.kind == tokenString {
+				table.Description = t.text
+			}
+		}
+		p.skipGroup()
+	}
+
+	if existing := s.table(name); existing != nil {
+		*existing = *table
+		return nil
+	}
+	s.tables = append(s.tables, table)
+	s.byName[strings.ToLower(name)] = table
+	return nil
+}
+
+// tableElement parses a column definition or a table constraint.
+func (p *parser) tableElement(table *specparser.SpecTable) error {
+	constraint := ""
+	if p.accept("constraint") {
+		constraint, _ = p.name()
+	}
+
+	switch {
+	case p.accept("primary", "key"):
+		columns, err := p.columnList()
+		if err != nil {
+			return err
+		}
+		table.PrimaryKey = columns
+		p.skipElement()
+	case p.acceptAny("unique"):
+		p.acceptAny("key", "index")
+		if constraint == "" && !p.at("(") {
+			constraint, _ = p.name()
+		}
+		columns, err := p.columnList()
+		if err != nil {
+			return err
+		}
+		addUnique(table, constraint, columns)
+		p.skipElement()
+	case p.accept("foreign", "key"):
+		fk := specparser.SpecForeignKey{Name: constraint}
+		if !p.at("(") {
+			p.name()
+		}
+		columns, err := p.columnList()
+		if err != nil {
+			return err
+		}
+		fk.Columns = columns
+		if !p.accept("references") {
+			return p.errorf(p.peek(), "expected REFERENCES in table %s, got %q", table.Name, p.peek().text)
+		}
+		if err := p.references(&fk); err != nil {
+			return err
+		}
+		table.ForeignKeys = append(table.ForeignKeys, fk)
+		p.skipElement()
+	case p.accept("check"):
+		table.Checks = append(table.Checks, p.groupText())
+		p.skipElement()
+	case p.acceptAny("key", "index", "fulltext", "spatial"):
+		p.acceptAny("key", "index")
+		name := constraint
+		if !p.at("(") {
+			name, _ = p.name()
+		}
+		columns, err := p.columnList()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = fmt.Sprintf("%s_%s_idx", table.Name, strings.Join(columns, "_"))
+		}
+		table.Indexes = append(table.Indexes, specparser.SpecIndex{Name: name, Columns: columns})
+		p.skipElement()
+	case p.accept("like"), p.accept("exclude"):
+		p.skipElement()
+	default:
+		return p.column(table)
+	}
+	return nil
+}
+
+// column parses a column definition and its constraints.
+func (p *parser) column(table *specparser.SpecTable) error {
+	name, ok := p.name()
+	if !ok {
+		return p.errorf(p.peek(), "expected column name in table %s, got %q", table.Name, p.peek().text)
+	}
+	col := specparser.SpecColumn{Name: name, Nullable: true}
+	col.SQLType = p.columnType()
+	col.Type, col.AutoIncrement = pseudoType(col.SQLType)
+	primary := false
+
+	for !p.at(",") && !p.at(")") && !p.at(";") && p.peek().kind != tokenEOF {
+		switch {
+		case p.accept("constraint"):
+			p.name()
+		case p.accept("not", "null"):
+			col.Nullable = false
+		case p.accept("null"):
+			col.Nullable = true
+		case p.accept("primary", "key"):
+			primary = true
+			p.acceptAny("asc", "desc")
+		case p.accept("unique"):
+			p.accept("key")
+			col.Unique = true
+		case p.accept("default"):
+			col.Default = p.expression()
+			if strings.HasPrefix(strings.ToLower(col.Default), "nextval(") {
+				col.AutoIncrement = true
+				col.Default = ""
+			}
+		case p.accept("references"):
+			fk := specparser.SpecForeignKey{Columns: []string{name}}
+			if err := p.references(&fk); err != nil {
+				return err
+			}
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		case p.accept("check"):
+			table.Checks = append(table.Checks, p.groupText())
+		case p.acceptAny("auto_increment", "autoincrement"):
+			col.AutoIncrement = true
+		case p.accept("identity"):
+			col.AutoIncrement = true
+			if p.at("(") {
+				p.skipGroup()
+			}
+		case p.accept("generated"):
+			p.accept("always")
+			p.accept("by", "default")
+			p.accept("as")
+			if p.accept("identity") {
+				col.AutoIncrement = true
+			}
+			if p.at("(") {
+				p.skipGroup()
+			}
+		case p.accept("comment"):
+			if t := p.next(); t.kind == tokenString {
+				col.Description = t.text
+			}
+		case p.accept("on", "update"):
+			p.expression()
+		default:
+			p.skipGroup()
+		}
+	}
+
+	if primary {
+		table.PrimaryKey = []string{name}
+		col.Nullable = false
+	}
+	table.Columns = append(table.Columns, col)
+	return nil
+}
+
+// columnType reads a column's type up to its first constraint, such as
+// "VARCHAR(255)", "DOUBLE PRECISION", "INT UNSIGNED" or "TEXT[]".
+func (p *parser) columnType() string {
+	var sb strings.Builder
+	for {
+		t := p.peek()
+		switch {
+		case t.is("(") && sb.Len() > 0:
+			p.next()
+			var args []string
+			for !p.at(")") && p.peek().kind != tokenEOF {
+				arg := p.next()
+				if arg.kind == tokenString {
+					args = append(args, "'"+arg.text+"'")
+				} else if !arg.is(",") {
+					args = append(args, arg.text)
+				}
+			}
+			p.accept(")")
+			sb.WriteString("(" + strings.Join(args, ",") + ")")
+		case t.is("["):
+			p.next()
+			for !p.at("]") && p.peek().kind != tokenEOF {
+				p.next()
+			}
+			p.accept("]")
+			sb.WriteString("[]")
+		case t.is("character") && p.peekAt(1).is("set"):
+			return sb.String()
+		case t.kind == tokenName && !columnStops[strings.ToLower(t.text)]:
+			p.next()
+			if sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(t.text)
+		case t.is("."):
+			// A schema-qualified type such as public.citext
+			p.next()
+			sb.Reset()
+		default:
+			return sb.String()
+		}
+	}
+}
+
+// references parses the rest of a REFERENCES clause into fk.
+func (p *parser) references(fk *specparser.SpecForeignKey) error {
+	fk.RefTable, _ = p.qualifiedName()
+	if p.at("(") {
+		columns, err := p.columnList()
+		if err != nil {
+			return err
+		}
+		fk.RefColumns = columns
+	}
+	for {
+		switch {
+		case p.accept("on", "delete"):
+			fk.OnDelete = p.referentialAction()
+		case p.accept("on", "update"):
+			fk.OnUpdate = p.referentialAction()
+		case p.accept("match"):
+			p.next()
+		case p.acceptAny("deferrable", "initially", "deferred", "immediate"):
+		case p.accept("not", "deferrable"):
+		default:
+			return nil
+		}
+	}
+}
+
+// referentialAction reads CASCADE, RESTRICT, NO ACTION, SET NULL or SET
+// DEFAULT.
+func (p *parser) referentialAction() string {
+	var words []string
+	for len(words) < 2 {
+		t := p.peek()
+		if t.kind != tokenName {
+			break
+		}
+		word := strings.ToUpper(t.text)
+		if len(words) == 0 && word != "SET" && word != "NO" {
+			p.next()
+			return word
+		}
+		if len(words) == 1 && word != "NULL" && word != "DEFAULT" && word != "ACTION" {
+			break
+		}
+		p.next()
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// createIndex parses the rest of a CREATE INDEX statement.
+func (p *parser) createIndex(s *schema, unique bool) error {
+	p.accept("concurrently")
+	p.accept("if", "not", "exists")
+	name := ""
+	if !p.at("on") {
+		name, _ = p.qualifiedName()
+	}
+	if !p.accept("on") {
+		return p.errorf(p.peek(), "expected ON in index %s, got %q", name, p.peek().text)
+	}
+	p.accept("only")
+	tableName, _ := p.qualifiedName()
+	if p.accept("using") {
+		p.next()
+	}
+	columns, err := p.columnList()
+	if err != nil {
+		return err
+	}
+
+	table := s.table(tableName)
+	if table == nil {
+		return nil
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s_%s_idx", table.Name, strings.Join(columns, "_"))
+	}
+	table.Indexes = append(table.Indexes, specparser.SpecIndex{Name: name, Columns: columns, Unique: unique})
+	return nil
+}
+
+// alterTable parses the ADD and DROP COLUMN actions of an ALTER TABLE
+// statement; other actions are skipped.
+func (p *parser) alterTable(s *schema) error {
+	p.accept("if", "exists")
+	p.accept("only")
+	name, _ := p.qualifiedName()
+	table := s.table(name)
+	if table == nil {
+		return nil
+	}
+
+	for {
+		if p.accept("add") {
+			p.accept("column")
+			p.accept("if", "not", "exists")
+			if err := p.tableElement(table); err != nil {
+				return err
+			}
+		} else if p.accept("drop") {
+			p.accept("column")
+			p.accept("if", "exists")
+			if name, ok := p.name(); ok && !strings.EqualFold(name, "constraint") {
+				dropColumn(table, name)
+			}
+			p.skipElement()
+		} else {
+			p.skipElement()
+		}
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+// commentOn parses COMMENT ON TABLE t IS '...' and COMMENT ON COLUMN
+// t.c IS '...' into descriptions.
+func (p *parser) commentOn(s *schema) {
+	switch {
+	case p.accept("table"):
+		name, _ := p.qualifiedName()
+		if p.accept("is") && p.peek().kind == tokenString {
+			if table := s.table(name); table != nil {
+				table.Description = p.next().text
+			}
+		}
+	case p.accept("column"):
+		var parts []string
+		for {
+			part, ok := p.name()
+			if !ok {
+				return
+			}
+			parts = append(parts, part)
+			if !p.accept(".") {
+				break
+			}
+		}
+		if len(parts) < 2 || !p.accept("is") || p.peek().kind != tokenString {
+			return
+		}
+		if table := s.table(parts[len(parts)-2]); table != nil {
+			if col := table.Column(parts[len(parts)-1]); col != nil {
+				col.Description = p.next().text
+			}
+		}
+	}
+}
+
+// dropColumn removes a column and the indexes that cover it.
+func dropColumn(table *specparser.SpecTable, name string) {
+	for i, c := range table.Columns {
+		if strings.EqualFold(c.Name, name) {
+			table.Columns = append(table.Columns[:i], table.Columns[i+1:]...)
+			break
+		}
+	}
+	var indexes []specparser.SpecIndex
+	for _, idx := range table.Indexes {
+		if !containsFold(idx.Columns, name) {
+			indexes = append(indexes, idx)
+		}
+	}
+	table.Indexes = indexes
+	var fks []specparser.SpecForeignKey
+	for _, fk := range table.ForeignKeys {
+		if !containsFold(fk.Columns, name) {
+			fks = append(fks, fk)
+		}
+	}
+	table.ForeignKeys = fks
+}
+
+// addUnique records a UNIQUE constraint: on the column itself when it
+// covers one column and is unnamed, as a unique index otherwise.
+func addUnique(table *specparser.SpecTable, name string, columns []string) {
+	if len(columns) == 1 && name == "" {
+		if col := table.Column(columns[0]); col != nil {
+			col.Unique = true
+			return
+		}
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s_%s_key", table.Name, strings.Join(columns, "_"))
+	}
+	table.Indexes = append(table.Indexes, specparser.SpecIndex{Name: name, Columns: columns, Unique: true})
+}
+
+// columnList parses a parenthesized list of columns. Elements that are not
+// plain column names, such as lower(email), are kept as expressions;
+// sort orders, collations and MySQL prefix lengths are dropped. A list
+// cut short by the end of the statement is an error.
+func (p *parser) columnList() ([]string, error) {
+	if !p.accept("(") {
+		return nil, nil
+	}
+	var columns []string
+	for !p.at(")") && p.peek().kind != tokenEOF {
+		start := p.pos
+		p.skipElement()
+		element := p.tokens[start:p.pos]
+		if len(element) > 0 && (element[0].kind == tokenName || element[0].kind == tokenQuoted) &&
+			(len(element) == 1 || element[1].is("(") && len(element) > 2 && element[2].kind == tokenNumber ||
+				element[1].kind == tokenName && !element[1].is("(")) {
+			columns = append(columns, element[0].text)
+		} else if len(element) > 0 {
+			columns = append(columns, exprText(element))
+		}
+		if !p.accept(",") && p.pos == start {
+			return nil, p.errorf(p.peek(), "expected , or ) in column list, got %q", p.peek().text)
+		}
+	}
+	p.accept(")")
+	return columns, nil
+}
+
+// groupText parses a parenthesized expression and returns its text.
+func (p *parser) groupText() string {
+	if !p.at("(") {
+		return ""
+	}
+	start := p.pos
+	p.skipGroup()
+	return exprText(p.tokens[start+1 : p.pos-1])
+}
+
+// expression reads a DEFAULT expression up to the next constraint keyword,
+// comma or closing parenthesis.
+func (p *parser) expression() string {
+	start := p.pos
+	for {
+		t := p.peek()
+		if t.kind == tokenEOF || t.is(",") || t.is(")") || t.is(";") ||
+			(p.pos > start && t.kind == tokenName && columnStops[strings.ToLower(t.text)] && !t.is("null")) {
+			break
+		}
+		p.skipGroup()
+	}
+	return exprText(p.tokens[start:p.pos])
+}
+
+// exprText renders tokens back into SQL with conventional spacing.
+func exprText(tokens []token) string {
+	var sb strings.Builder
+	for i, t := range tokens {
+		text := t.text
+		switch t.kind {
+		case tokenString:
+			text = "'" + strings.ReplaceAll(text, "'", "''") + "'"
+		case tokenQuoted:
+			text = `"` + text + `"`
+		}
+		if i > 0 {
+			prev := tokens[i-1]
+			tight := prev.is("(") || prev.is(".") || prev.is("::") || t.is(")") || t.is(",") ||
+				t.is(".") || t.is("::") || (t.is("(") && (prev.kind == tokenName || prev.kind == tokenQuoted))
+			if !tight {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+// name reads an identifier, bare or quoted.
+func (p *parser) name() (string, bool) {
+	t := p.peek()
+	if t.kind != tokenName && t.kind != tokenQuoted {
+		return "", false
+	}
+	p.next()
+	return t.text, true
+}
+
+// qualifiedName reads a possibly schema-qualified name and returns its
+// last part.
+func (p *parser) qualifiedName() (string, bool) {
+	name, ok := p.name()
+	for ok && p.accept(".") {
+		name, ok = p.name()
+	}
+	return name, ok
+}
+
+// skipElement skips to the next comma or closing parenthesis at the
+// current depth.
+func (p *parser) skipElement() {
+	for !p.at(",") && !p.at(")") && !p.at(";") && p.peek().kind != tokenEOF {
+		p.skipGroup()
+	}
+}
+
+// skipStatement skips to the end of the current statement.
+func (p *parser) skipStatement() {
+	for !p.at(";") && p.peek().kind != tokenEOF {
+		p.skipGroup()
+	}
+	p.accept(";")
+}
+
+// skipGroup skips one token, or a whole parenthesized group.
+func (p *parser) skipGroup() {
+	if !p.at("(") {
+		p.next()
+		return
+	}
+	depth := 0
+	for p.peek().kind != tokenEOF {
+		t := p.next()
+		if t.is("(") {
+			depth++
+		} else if t.is(")") {
+			if depth--; depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (p *parser) peek() token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// at reports whether the next token is the given keyword or punctuation.
+func (p *parser) at(text string) bool {
+	return p.peek().is(text)
+}
+
+// accept consumes the given sequence of keywords if the next tokens match
+// it, and reports whether they did.
+func (p *parser) accept(words ...string) bool {
+	for i, w := range words {
+		if !p.peekAt(i).is(w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+// acceptAny consumes the next token if it is one of the given keywords.
+func (p *parser) acceptAny(words ...string) bool {
+	for _, w := range words {
+		if p.accept(w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
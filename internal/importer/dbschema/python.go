@@ -0,0 +1,599 @@
+package dbschema
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// pyStatement is a logical line of Python source: continuation lines inside
+// brackets and triple-quoted strings are joined, and comments dropped.
+type pyStatement struct {
+	text   string
+	indent int
+}
+
+// pyClass is a Python class with the statements of its body.
+type pyClass struct {
+	name  string
+	bases []string
+	doc   string
+	body  []pyStatement
+
+	// meta holds the statements of a nested Meta class (Django)
+	meta []pyStatement
+}
+
+var (
+	pyClassPattern      = regexp.MustCompile(`^class\s+(\w+)\s*(?:\((.*)\))?\s*:`)
+	pyMetaPattern       = regexp.MustCompile(`^class\s+Meta\b`)
+	pyAssignPattern     = regexp.MustCompile(`(?s)^(\w+)\s*(?::\s*(.+?))?\s*=\s*(.+)$`)
+	pyCallPattern       = regexp.MustCompile(`(?s)^([\w.]+)\s*\((.*)\)$`)
+	pyTablePattern      = regexp.MustCompile(`(?s)^(\w+)\s*=\s*(?:\w+\.)?Table\s*\((.*)\)$`)
+	pyConstraintPattern = regexp.MustCompile(`(?s)\b(UniqueConstraint|Index)\s*\(([^()]*(?:\([^()]*\)[^()]*)*)\)`)
+)
+
+// sqlAlchemyTypes maps SQLAlchemy column types to SQL types.
+var sqlAlchemyTypes = map[string]string{
+	"Integer": "integer", "INTEGER": "integer", "SmallInteger": "smallint", "BigInteger": "bigint",
+	"BIGINT": "bigint", "String": "varchar", "VARCHAR": "varchar", "Unicode": "varchar",
+	"Text": "text", "TEXT": "text", "UnicodeText": "text", "Boolean": "boolean", "BOOLEAN": "boolean",
+	"DateTime": "timestamp", "TIMESTAMP": "timestamp", "Date": "date", "Time": "time",
+	"Float": "float", "Numeric": "numeric", "DECIMAL": "numeric", "LargeBinary": "blob",
+	"JSON": "json", "JSONB": "jsonb", "Uuid": "uuid", "UUID": "uuid", "Enum": "varchar",
+	"Interval": "interval",
+}
+
+// djangoFields maps Django model fields to SQL types.
+var djangoFields = map[string]string{
+	"AutoField": "serial", "BigAutoField": "bigserial", "SmallAutoField": "smallserial",
+	"CharField": "varchar", "SlugField": "varchar(50)", "EmailField": "varchar(254)",
+	"URLField": "varchar(200)", "FileField": "varchar(100)", "ImageField": "varchar(100)",
+	"FilePathField": "varchar(100)", "GenericIPAddressField": "inet", "TextField": "text",
+	"IntegerField": "integer", "SmallIntegerField": "smallint", "BigIntegerField": "bigint",
+	"PositiveIntegerField": "integer", "PositiveSmallIntegerField": "smallint",
+	"PositiveBigIntegerField": "bigint", "BooleanField": "boolean", "NullBooleanField": "boolean",
+	"DateField": "date", "DateTimeField": "timestamp", "TimeField": "time",
+	"DurationField": "interval", "DecimalField": "numeric", "FloatField": "double precision",
+	"UUIDField": "uuid", "JSONField": "jsonb", "BinaryField": "bytea",
+}
+
+// djangoActions maps on_delete handlers to referential actions.
+var djangoActions = map[string]string{
+	"CASCADE": "CASCADE", "SET_NULL": "SET NULL", "SET_DEFAULT": "SET DEFAULT",
+	"PROTECT": "RESTRICT", "RESTRICT": "RESTRICT", "DO_NOTHING": "NO ACTION",
+}
+
+// pythonModels extracts SQLAlchemy declarative models, SQLAlchemy Table
+// objects and Django models from Python sources.
+func pythonModels(files []sourceFile) []*specparser.SpecTable {
+	var tables []*specparser.SpecTable
+	for _, file := range files {
+		statements := pythonStatements(file.content)
+		assocTables := make(map[string]string)
+		for _, s := range statements {
+			if m := pyTablePattern.FindStringSubmatch(s.text); m != nil && s.indent == 0 {
+				assocTables[m[1]] = sqlAlchemyTable(&tables, m[2])
+			}
+		}
+		for _, class := range pythonClasses(statements) {
+			switch {
+			case isDjangoModel(class):
+				djangoModel(&tables, class, file.path)
+			case isSQLAlchemyModel(class):
+				sqlAlchemyModel(&tables, class, assocTables)
+			}
+		}
+	}
+	return tables
+}
+
+// pythonStatements splits Python source into logical lines.
+func pythonStatements(src string) []pyStatement {
+	var statements []pyStatement
+	var cur strings.Builder
+	depth, indent := 0, 0
+	triple := ""
+	for _, line := range strings.Split(src, "\n") {
+		if cur.Len() == 0 {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			indent = len(line) - len(strings.TrimLeft(line, " \t"))
+			line = trimmed
+		}
+		end := len(line)
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case triple != "":
+				if strings.HasPrefix(line[i:], triple) {
+					i += 2
+					triple = ""
+				}
+			case strings.HasPrefix(line[i:], `"""`) || strings.HasPrefix(line[i:], `'''`):
+				triple = line[i : i+3]
+				i += 2
+			case c == '"' || c == '\'':
+				for i++; i < len(line) && line[i] != c; i++ {
+					if line[i] == '\\' {
+						i++
+					}
+				}
+			case c == '#':
+				end = i
+				i = len(line)
+			case c == '(' || c == '[' || c == '{':
+				depth++
+			case c == ')' || c == ']' || c == '}':
+				depth--
+			}
+		}
+		text := strings.TrimRight(line[:end], " \t")
+		continued := strings.HasSuffix(text, "\\")
+		cur.WriteString(strings.TrimSuffix(text, "\\"))
+		if depth > 0 || triple != "" || continued {
+			cur.WriteString("\n")
+			continue
+		}
+		statements = append(statements, pyStatement{text: cur.String(), indent: indent})
+		cur.Reset()
+		depth = 0
+	}
+	return statements
+}
+
+// pythonClasses returns the classes declared in a file's statements, each
+// with the direct statements of its body.
+func pythonClasses(statements []pyStatement) []pyClass {
+	var classes []pyClass
+	for i, s := range statements {
+		m := pyClassPattern.FindStringSubmatch(s.text)
+		if m == nil {
+			continue
+		}
+		class := pyClass{name: m[1]}
+		for _, base := range splitArgs(m[2]) {
+			class.bases = append(class.bases, strings.TrimSpace(base))
+		}
+		bodyIndent := -1
+		inMeta := false
+		for _, b := range statements[i+1:] {
+			if b.indent <= s.indent {
+				break
+			}
+			if bodyIndent < 0 {
+				bodyIndent = b.indent
+				if strings.HasPrefix(b.text, `"""`) || strings.HasPrefix(b.text, `'''`) {
+					class.doc = strings.Join(strings.Fields(strings.Trim(b.text, `"'`)), " ")
+					continue
+				}
+			}
+			if b.indent == bodyIndent {
+				inMeta = pyMetaPattern.MatchString(b.text)
+				if !inMeta {
+					class.body = append(class.body, b)
+				}
+			} else if inMeta {
+				class.meta = append(class.meta, b)
+			}
+		}
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// pyAssignments returns the name = value statements of a class body, with
+// the annotation of annotated ones.
+func pyAssignments(body []pyStatement) [][3]string {
+	var assignments [][3]string
+	for _, s := range body {
+		if m := pyAssignPattern.FindStringSubmatch(s.text); m != nil {
+			assignments = append(assignments, [3]string{m[1], strings.TrimSpace(m[2]), strings.TrimSpace(m[3])})
+		}
+	}
+	return assignments
+}
+
+// pyCall splits a call expression into the callee's last name and its
+// arguments.
+func pyCall(expr string) (string, string, bool) {
+	m := pyCallPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if m == nil {
+		return "", "", false
+	}
+	name := m[1]
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name, m[2], true
+}
+
+// pyList returns the quoted names of a Python list or tuple literal.
+func pyList(expr string) []string {
+	var names []string
+	for _, item := range splitArgs(strings.Trim(strings.TrimSpace(expr), "[]()")) {
+		if item = unquote(item); item != "" {
+			names = append(names, item)
+		}
+	}
+	return names
+}
+
+func isSQLAlchemyModel(class pyClass) bool {
+	for _, a := range pyAssignments(class.body) {
+		if a[0] == "__tablename__" {
+			return true
+		}
+		if name, _, ok := pyCall(a[2]); ok && (name == "Column" || name == "mapped_column") {
+			return true
+		}
+	}
+	return false
+}
+
+func isDjangoModel(class pyClass) bool {
+	for _, base := range class.bases {
+		if base == "models.Model" {
+			return true
+		}
+	}
+	for _, a := range pyAssignments(class.body) {
+		if strings.HasPrefix(a[2], "models.") {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlAlchemyModel adds the table of a declarative model. Relationships to
+// entities the table has a foreign key to belong to them; others are one
+// or many depending on uselist and the Mapped annotation.
+func sqlAlchemyModel(tables *[]*specparser.SpecTable, class pyClass, assocTables map[string]string) {
+	name := toSnakeCase(class.name)
+	assignments := pyAssignments(class.body)
+	for _, a := range assignments {
+		if a[0] == "__tablename__" {
+			name = unquote(a[2])
+		}
+	}
+	t := ormTable(tables, name, class.name)
+	t.Description = class.doc
+
+	type pending struct{ name, annotation, args string }
+	var relationships []pending
+	for _, a := range assignments {
+		call, args, ok := pyCall(a[2])
+		switch {
+		case a[0] == "__table_args__":
+			sqlAlchemyConstraints(t, a[2])
+		case !ok:
+		case call == "Column" || call == "mapped_column":
+			sqlAlchemyColumn(t, a[0], a[1], args)
+		case call == "relationship":
+			relationships = append(relationships, pending{a[0], a[1], args})
+		}
+	}
+
+	for _, r := range relationships {
+		positional, kw := keywordArgs(r.args)
+		target := ""
+		if len(positional) > 0 {
+			target = unquote(positional[0])
+		} else if inner := mappedType(r.annotation); inner != "" {
+			target = strings.Trim(inner, `"'`)
+		}
+		if _, elem, ok := strings.Cut(target, "["); ok {
+			target = strings.Trim(strings.TrimSuffix(elem, "]"), `"'`)
+		}
+		relation := specparser.SpecRelation{Name: r.name, Table: target}
+		inner := mappedType(r.annotation)
+		switch {
+		case kw["secondary"] != "":
+			relation.Kind = specparser.RelationManyToMany
+			relation.Through = firstNonEmpty(assocTables[kw["secondary"]], unquote(kw["secondary"]))
+		case sqlAlchemyReferences(t, target):
+			relation.Kind = specparser.RelationBelongsTo
+		case kw["uselist"] == "False":
+			relation.Kind = specparser.RelationHasOne
+		case inner != "" && !strings.HasPrefix(strings.ToLower(inner), "list[") && !strings.HasPrefix(inner, "Set["):
+			relation.Kind = specparser.RelationHasOne
+		default:
+			relation.Kind = specparser.RelationHasMany
+		}
+		t.Relations = append(t.Relations, relation)
+	}
+}
+
+// mappedType returns T from a Mapped[T] annotation.
+func mappedType(annotation string) string {
+	if inner, ok := strings.CutPrefix(annotation, "Mapped["); ok {
+		return strings.TrimSuffix(inner, "]")
+	}
+	return ""
+}
+
+// sqlAlchemyReferences reports whether a table has a foreign key to the
+// table of an entity, whose table name follows the usual conventions.
+func sqlAlchemyReferences(t *specparser.SpecTable, entity string) bool {
+	for _, fk := range t.ForeignKeys {
+		ref := strings.ToLower(fk.RefTable)
+		snake := toSnakeCase(entity)
+		if ref == snake || ref == plural(snake) || ref == strings.ToLower(entity) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlAlchemyTable adds the table of a Table("name", metadata, Column...)
+// call and returns its name.
+func sqlAlchemyTable(tables *[]*specparser.SpecTable, args string) string {
+	positional, _ := keywordArgs(args)
+	if len(positional) == 0 {
+		return ""
+	}
+	t := ormTable(tables, unquote(positional[0]), "")
+	for _, arg := range positional[1:] {
+		if call, callArgs, ok := pyCall(arg); ok && call == "Column" {
+			colArgs, _ := keywordArgs(callArgs)
+			if len(colArgs) > 0 {
+				sqlAlchemyColumn(t, unquote(colArgs[0]), "", callArgs)
+			}
+		}
+	}
+	sqlAlchemyConstraints(t, args)
+	return t.Name
+}
+
+// sqlAlchemyColumn adds a Column or mapped_column. The column's type comes
+// from its type argument, else from the Mapped annotation; a foreign key
+// column with neither takes the type of the column it references.
+func sqlAlchemyColumn(t *specparser.SpecTable, field, annotation, args string) {
+	positional, kw := keywordArgs(args)
+	col := specparser.SpecColumn{Name: field, Nullable: true}
+	if len(positional) > 0 && (strings.HasPrefix(positional[0], `"`) || strings.HasPrefix(positional[0], `'`)) {
+		col.Name = unquote(positional[0])
+		positional = positional[1:]
+	}
+
+	for _, arg := range positional {
+		call, callArgs, isCall := pyCall(arg)
+		if !isCall {
+			call = arg
+			if i := strings.LastIndexByte(call, '.'); i >= 0 {
+				call = call[i+1:]
+			}
+		}
+		if call == "ForeignKey" {
+			fkArgs, fkKw := keywordArgs(callArgs)
+			if len(fkArgs) > 0 {
+				refTable, refColumn, _ := strings.Cut(unquote(fkArgs[0]), ".")
+				fk := specparser.SpecForeignKey{Columns: []string{col.Name}, RefTable: refTable, RefColumns: []string{refColumn}}
+				fk.OnDelete = strings.ToUpper(unquote(fkKw["ondelete"]))
+				fk.OnUpdate = strings.ToUpper(unquote(fkKw["onupdate"]))
+				t.ForeignKeys = append(t.ForeignKeys, fk)
+			}
+			continue
+		}
+		if sqlType, ok := sqlAlchemyTypes[call]; ok {
+			if length, _ := keywordArgs(callArgs); len(length) > 0 && sqlType == "varchar" {
+				sqlType += "(" + length[0] + ")"
+				col.SQLType = sqlType
+			}
+			col.Type, _ = pseudoType(sqlType)
+		}
+	}
+
+	if inner := mappedType(annotation); inner != "" {
+		typ, optional := languageType(inner)
+		if col.Type == "" {
+			col.Type = typ
+		}
+		col.Nullable = optional
+	}
+
+	if v, ok := kw["nullable"]; ok {
+		col.Nullable = isTrue(v)
+	}
+	col.Unique = isTrue(kw["unique"])
+	col.Default = strings.Trim(firstNonEmpty(kw["server_default"], kw["default"]), `"'`)
+	col.Description = unquote(firstNonEmpty(kw["comment"], kw["doc"]))
+	if isTrue(kw["primary_key"]) {
+		t.PrimaryKey = append(t.PrimaryKey, col.Name)
+		col.Nullable = false
+		col.AutoIncrement = kw["autoincrement"] != "False" && (col.Type == "int" || col.Type == "int64")
+	}
+	if isTrue(kw["index"]) {
+		t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: "ix_" + t.Name + "_" + col.Name, Columns: []string{col.Name}, Unique: col.Unique})
+		col.Unique = false
+	}
+	addColumn(t, col)
+}
+
+// sqlAlchemyConstraints adds the UniqueConstraint and Index entries of
+// __table_args__ or a Table call.
+func sqlAlchemyConstraints(t *specparser.SpecTable, args string) {
+	for _, m := range pyConstraintPattern.FindAllStringSubmatch(args, -1) {
+		positional, kw := keywordArgs(m[2])
+		var name string
+		var columns []string
+		for i, arg := range positional {
+			if m[1] == "Index" && i == 0 {
+				name = unquote(arg)
+				continue
+			}
+			columns = append(columns, unquote(arg))
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		if m[1] == "UniqueConstraint" {
+			name = firstNonEmpty(unquote(kw["name"]), t.Name+"_"+strings.Join(columns, "_")+"_key")
+		}
+		t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: name, Columns: columns, Unique: m[1] == "UniqueConstraint" || isTrue(kw["unique"])})
+	}
+}
+
+// djangoModel adds the table of a Django model. Tables default to
+// <app>_<model>, where the app is the directory holding models.py, and
+// get an implicit BigAutoField id unless a field is the primary key.
+func djangoModel(tables *[]*specparser.SpecTable, class pyClass, path string) {
+	meta := make(map[string]string)
+	for _, a := range pyAssignments(class.meta) {
+		meta[a[0]] = a[2]
+	}
+	if isTrue(meta["abstract"]) {
+		return
+	}
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == "models" {
+		dir = filepath.Dir(dir)
+	}
+	name := firstNonEmpty(unquote(meta["db_table"]), strings.ToLower(filepath.Base(dir)+"_"+class.name))
+	t := ormTable(tables, name, class.name)
+	t.Description = class.doc
+
+	var columns []specparser.SpecColumn
+	for _, a := range pyAssignments(class.body) {
+		field, args, ok := pyCall(a[2])
+		if !ok {
+			continue
+		}
+		positional, kw := keywordArgs(args)
+		col := specparser.SpecColumn{
+			Name:        a[0],
+			Nullable:    isTrue(kw["null"]),
+			Unique:      isTrue(kw["unique"]),
+			Default:     strings.Trim(kw["default"], `"'`),
+			Description: unquote(kw["help_text"]),
+		}
+
+		switch field {
+		case "ForeignKey", "OneToOneField":
+			target := djangoTarget(positional, kw, class.name)
+			col.Name = firstNonEmpty(unquote(kw["db_column"]), a[0]+"_id")
+			col.Unique = col.Unique || field == "OneToOneField"
+			columns = append(columns, col)
+			onDelete := kw["on_delete"]
+			if i := strings.LastIndexByte(onDelete, '.'); i >= 0 {
+				onDelete = onDelete[i+1:]
+			}
+			if len(positional) > 1 && onDelete == "" {
+				onDelete = positional[1][strings.LastIndexByte(positional[1], '.')+1:]
+			}
+			t.ForeignKeys = append(t.ForeignKeys, specparser.SpecForeignKey{Columns: []string{col.Name}, RefTable: target, OnDelete: djangoActions[onDelete]})
+			t.Relations = append(t.Relations, specparser.SpecRelation{Name: a[0], Kind: specparser.RelationBelongsTo, Table: target, Columns: []string{col.Name}})
+			continue
+
+		case "ManyToManyField":
+			target := djangoTarget(positional, kw, class.name)
+			through := unquote(kw["through"])
+			if through == "" {
+				through = firstNonEmpty(unquote(kw["db_table"]), t.Name+"_"+a[0])
+				djangoJoinTable(tables, through, class.name, target)
+			}
+			t.Relations = append(t.Relations, specparser.SpecRelation{Name: a[0], Kind: specparser.RelationManyToMany, Table: target, Through: through})
+			continue
+		}
+
+		sqlType, ok := djangoFields[field]
+		if !ok {
+			continue
+		}
+		if length := kw["max_length"]; length != "" && strings.HasPrefix(sqlType, "varchar") {
+			sqlType = "varchar(" + length + ")"
+		}
+		if field == "DecimalField" && kw["max_digits"] != "" {
+			sqlType = "numeric(" + kw["max_digits"] + ", " + firstNonEmpty(kw["decimal_places"], "0") + ")"
+		}
+		col.Name = firstNonEmpty(unquote(kw["db_column"]), col.Name)
+		col.Type, col.AutoIncrement = pseudoType(sqlType)
+		if strings.Contains(sqlType, "(") {
+			col.SQLType = sqlType
+		}
+		if isTrue(kw["primary_key"]) {
+			t.PrimaryKey = []string{col.Name}
+		}
+		if isTrue(kw["db_index"]) {
+			t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: t.Name + "_" + col.Name + "_idx", Columns: []string{col.Name}})
+		}
+		columns = append(columns, col)
+	}
+
+	if len(t.PrimaryKey) == 0 {
+		t.PrimaryKey = []string{"id"}
+		columns = append([]specparser.SpecColumn{{Name: "id", Type: "int64", AutoIncrement: true}}, columns...)
+	}
+	for _, col := range columns {
+		addColumn(t, col)
+	}
+
+	for _, key := range []string{"unique_together", "index_together"} {
+		value := strings.TrimSpace(meta[key])
+		if value == "" {
+			continue
+		}
+		groups := splitArgs(strings.Trim(value, "[]()"))
+		if len(groups) > 0 && !strings.HasPrefix(groups[0], "(") && !strings.HasPrefix(groups[0], "[") {
+			groups = []string{value}
+		}
+		for _, group := range groups {
+			columns := pyList(group)
+			suffix := map[bool]string{true: "_uniq", false: "_idx"}[key == "unique_together"]
+			t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: t.Name + "_" + strings.Join(columns, "_") + suffix, Columns: columns, Unique: key == "unique_together"})
+		}
+	}
+	for _, key := range []string{"indexes", "constraints"} {
+		for _, item := range splitArgs(strings.Trim(strings.TrimSpace(meta[key]), "[]()")) {
+			call, args, ok := pyCall(item)
+			if !ok || (call != "Index" && call != "UniqueConstraint") {
+				continue
+			}
+			_, kw := keywordArgs(args)
+			columns := pyList(kw["fields"])
+			name := firstNonEmpty(unquote(kw["name"]), t.Name+"_"+strings.Join(columns, "_")+"_idx")
+			t.Indexes = append(t.Indexes, specparser.SpecIndex{Name: name, Columns: columns, Unique: call == "UniqueConstraint"})
+		}
+	}
+}
+
+// djangoTarget returns the model a relation field points to; "self" is the
+// declaring model and app labels are dropped.
+func djangoTarget(positional []string, kw map[string]string, self string) string {
+	target := kw["to"]
+	if len(positional) > 0 {
+		target = positional[0]
+	}
+	target = unquote(target)
+	if target == "self" {
+		return self
+	}
+	if i := strings.LastIndexByte(target, '.'); i >= 0 {
+		target = target[i+1:]
+	}
+	return target
+}
+
+// djangoJoinTable adds the table Django creates for a ManyToManyField
+// without a through model.
+func djangoJoinTable(tables *[]*specparser.SpecTable, name, owner, target string) {
+	t := ormTable(tables, name, "")
+	if len(t.Columns) > 0 {
+		return
+	}
+	ownerColumn, targetColumn := strings.ToLower(owner)+"_id", strings.ToLower(target)+"_id"
+	if ownerColumn == targetColumn {
+		ownerColumn, targetColumn = "from_"+ownerColumn, "to_"+targetColumn
+	}
+	t.PrimaryKey = []string{"id"}
+	t.Columns = []specparser.SpecColumn{{Name: "id", Type: "int64", AutoIncrement: true}, {Name: ownerColumn}, {Name: targetColumn}}
+	t.ForeignKeys = []specparser.SpecForeignKey{
+		{Columns: []string{ownerColumn}, RefTable: owner, OnDelete: "CASCADE"},
+		{Columns: []string{targetColumn}, RefTable: target, OnDelete: "CASCADE"},
+	}
+	t.Indexes = []specparser.SpecIndex{{Name: name + "_" + ownerColumn + "_" + targetColumn + "_uniq", Columns: []string{ownerColumn, targetColumn}, Unique: true}}
+}
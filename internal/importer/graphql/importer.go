@@ -0,0 +1,205 @@
+package graphql
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// scalarTypes maps built-in and commonly declared custom scalars to
+// pseudo-types. Custom scalars are kept as alias types, of their mapped
+// pseudo-type when there is one.
+var scalarTypes = map[string]string{
+	"String":   "string",
+	"ID":       "string",
+	"Int":      "int",
+	"Float":    "float",
+	"Boolean":  "bool",
+	"DateTime": "datetime",
+	"Date":     "date",
+	"UUID":     "uuid",
+	"JSON":     "any",
+}
+
+// builtinScalars are the scalars every GraphQL schema has.
+var builtinScalars = map[string]bool{
+	"String":  true,
+	"ID":      true,
+	"Int":     true,
+	"Float":   true,
+	"Boolean": true,
+}
+
+// defaultOperations are the conventional root operation type names.
+var defaultOperations = map[string]string{
+	"query":        "Query",
+	"mutation":     "Mutation",
+	"subscription": "Subscription",
+}
+
+// ParseFile parses a GraphQL SDL file into a spec analysis.
+func ParseFile(path string) (*specparser.SpecAnalysis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	spec, err := Parse(data, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return spec, nil
+}
+
+// Parse parses SDL content into a spec analysis with the given name.
+func Parse(data []byte, name string) (*specparser.SpecAnalysis, error) {
+	doc, err := parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &specparser.SpecAnalysis{
+		Name:          name,
+		Overview:      doc.description,
+		Types:         []specparser.SpecType{},
+		Functions:     []specparser.SpecFunction{},
+		Tests:         []specparser.SpecTest{},
+		Dependencies:  []specparser.SpecDependency{},
+		Configuration: []specparser.SpecConfig{},
+		Endpoints:     []specparser.SpecEndpoint{},
+	}
+
+	// Root operation types become functions rather than types
+	roots := make(map[string]string)
+	for op, typeName := range defaultOperations {
+		if explicit, ok := doc.operations[op]; ok {
+			typeName = explicit
+		}
+		roots[typeName] = op
+	}
+
+	for _, def := range doc.definitions {
+		if op, ok := roots[def.name]; ok && def.kind == "type" {
+			for _, f := range def.fields {
+				spec.Functions = append(spec.Functions, operationFunction(def.name, op, f))
+			}
+			continue
+		}
+		if def.kind == "scalar" && builtinScalars[def.name] {
+			continue
+		}
+		spec.Types = append(spec.Types, specType(def))
+	}
+
+	spec.CalculateTotals()
+	return spec, nil
+}
+
+// specType converts a non-root definition.
+func specType(def *definition) specparser.SpecType {
+	typ := specparser.SpecType{
+		Name:        def.name,
+		Description: def.description,
+		Implements:  def.implements,
+		Directives:  def.directives,
+		IsPublic:    true,
+	}
+
+	switch def.kind {
+	case "type", "input":
+		typ.Kind = "struct"
+	case "interface":
+		typ.Kind = "interface"
+	case "union":
+		typ.Kind = "union"
+		for _, member := range def.members {
+			typ.Fields = append(typ.Fields, specparser.SpecField{Name: member, Type: member, Required: true})
+		}
+	case "enum":
+		typ.Kind = "enum"
+		for _, v := range def.values {
+			desc := v.description
+			if len(v.directives) > 0 {
+				desc = strings.TrimSpace(desc + " " + strings.Join(v.directives, " "))
+			}
+			typ.Values = append(typ.Values, specparser.SpecEnumValue{Name: v.name, Description: desc})
+		}
+	case "scalar":
+		typ.Kind = "alias"
+		typ.AliasOf = scalarTypes[def.name]
+	}
+
+	for _, f := range def.fields {
+		field := specparser.SpecField{
+			Name:        f.name,
+			Type:        pseudoType(f.typ, false),
+			Description: f.description,
+			Required:    f.typ.nonNull && f.defaultValue == "",
+			Default:     f.defaultValue,
+			Directives:  f.directives,
+		}
+		if len(f.args) > 0 {
+			var args []string
+			for _, arg := range f.args {
+				a := arg.name + ": " + arg.typ.String()
+				if arg.defaultValue != "" {
+					a += " = " + arg.defaultValue
+				}
+				args = append(args, a)
+			}
+			field.Description = strings.TrimSpace(fmt.Sprintf("%s (arguments: %s)", field.Description, strings.Join(args, ", ")))
+		}
+		typ.Fields = append(typ.Fields, field)
+	}
+
+	return typ
+}
+
+// operationFunction converts a Query/Mutation/Subscription field. The root
+// type is the receiver, and subscriptions stream their results.
+func operationFunction(root, op string, f *fieldDef) specparser.SpecFunction {
+	fn := specparser.SpecFunction{
+		Name:            f.name,
+		Receiver:        root,
+		Description:     f.description,
+		Returns:         []specparser.SpecReturn{{Type: pseudoType(f.typ, true)}},
+		Directives:      f.directives,
+		IsPublic:        true,
+		ServerStreaming: op == "subscription",
+	}
+
+	for _, arg := range f.args {
+		fn.Parameters = append(fn.Parameters, specparser.SpecParameter{
+			Name:        arg.name,
+			Type:        pseudoType(arg.typ, false),
+			Description: arg.description,
+			Required:    arg.typ.nonNull && arg.defaultValue == "",
+			Default:     arg.defaultValue,
+		})
+	}
+
+	return fn
+}
+
+// pseudoType maps a type reference to a pseudo-type. Nullable list elements
+// become Optional[T]; the outermost nullability is reported through the
+// required flag of fields and parameters, or as Optional[T] when wrapOuter
+// is set (return types).
+func pseudoType(t *typeRef, wrapOuter bool) string {
+	var s string
+	if t.elem != nil {
+		s = fmt.Sprintf("List[%s]", pseudoType(t.elem, true))
+	} else if mapped, ok := scalarTypes[t.name]; ok {
+		s = mapped
+	} else {
+		s = t.name
+	}
+
+	if wrapOuter && !t.nonNull {
+		return fmt.Sprintf("Optional[%s]", s)
+	}
+	return s
+}
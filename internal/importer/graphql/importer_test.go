@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const bffSchema = `"""
+Backend-for-frontend API.
+"""
+schema {
+  query: RootQuery
+  mutation: Mutation
+  subscription: Subscription
+}
+
+directive @auth(requires: Role = ADMIN) on OBJECT | FIELD_DEFINITION
+
+scalar DateTime
+scalar Cursor @specifiedBy(url: "https://example.com/cursor")
+
+"Anything with a global ID."
+interface Node {
+  id: ID!
+}
+
+type User implements Node & Entity @key(fields: "id") {
+  id: ID!
+  # comments are ignored
+  name: String
+  tags: [String]!
+  friends(first: Int = 10, after: Cursor): [User!]
+  role: Role @deprecated(reason: "use roles")
+  createdAt: DateTime!
+}
+
+enum Role {
+  ADMIN
+  "Regular member"
+  MEMBER @deprecated
+}
+
+union SearchResult = | User | Post
+
+input NewUser {
+  name: String!
+  role: Role = MEMBER
+}
+
+type RootQuery {
+  "Looks up a user."
+  user(id: ID!): User
+  search(text: String!, limit: Int = 20): [SearchResult!]!
+}
+
+type Mutation {
+  createUser(input: NewUser!): User! @auth(requires: ADMIN)
+}
+
+type Subscription {
+  userCreated: User!
+}
+
+extend type User {
+  email: String!
+}
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(bffSchema), "bff")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if spec.Overview != "Backend-for-frontend API." {
+		t.Errorf("Unexpected overview: %q", spec.Overview)
+	}
+
+	var names []string
+	types := make(map[string]specparser.SpecType)
+	for _, typ := range spec.Types {
+		names = append(names, typ.Name+":"+typ.Kind)
+		types[typ.Name] = typ
+	}
+	expected := "DateTime:alias,Cursor:alias,Node:interface,User:struct,Role:enum,SearchResult:union,NewUser:struct"
+	if got := strings.Join(names, ","); got != expected {
+		t.Errorf("Types = %s, expected %s", got, expected)
+	}
+
+	// Custom scalars become aliases, of their pseudo-type when it is known
+	if dt := types["DateTime"]; dt.AliasOf != "datetime" || dt.Description != "" {
+		t.Errorf("Unexpected DateTime scalar: %+v", dt)
+	}
+	if cursor := types["Cursor"]; cursor.AliasOf != "" || cursor.Description != "" {
+		t.Errorf("Unexpected Cursor scalar: %+v", cursor)
+	}
+
+	user := types["User"]
+	if strings.Join(user.Implements, ",") != "Node,Entity" {
+		t.Errorf("Unexpected implements: %v", user.Implements)
+	}
+	if strings.Join(user.Directives, ",") != `@key(fields: "id")` {
+		t.Errorf("Unexpected type directives: %v", user.Directives)
+	}
+
+	tests := []struct {
+		field    string
+		typ      string
+		required bool
+	}{
+		{"id", "string", true},
+		{"name", "string", false},
+		{"tags", "List[Optional[string]]", true},
+		{"friends", "List[User]", false},
+		{"role", "Role", false},
+		{"createdAt", "datetime", true},
+		{"email", "string", true},
+	}
+	if len(user.Fields) != len(tests) {
+		t.Fatalf("Expected %d User fields, got %d", len(tests), len(user.Fields))
+	}
+	for i, tt := range tests {
+		f := user.Fields[i]
+		if f.Name != tt.field || f.Type != tt.typ || f.Required != tt.required {
+			t.Errorf("Field %d = %s %s required=%v, expected %s %s required=%v", i, f.Name, f.Type, f.Required, tt.field, tt.typ, tt.required)
+		}
+	}
+	if !strings.Contains(user.Fields[3].Description, "first: Int = 10") {
+		t.Errorf("Expected field arguments in description, got %q", user.Fields[3].Description)
+	}
+	if strings.Join(user.Fields[4].Directives, ",") != `@deprecated(reason: "use roles")` {
+		t.Errorf("Unexpected field directives: %v", user.Fields[4].Directives)
+	}
+
+	if role := types["NewUser"].Fields[1]; role.Default != "MEMBER" || role.Required {
+		t.Errorf("Expected defaulted input field, got %+v", role)
+	}
+	if union := types["SearchResult"]; len(union.Fields) != 2 || union.Fields[1].Type != "Post" {
+		t.Errorf("Unexpected union: %+v", union)
+	}
+
+	if len(spec.Functions) != 4 {
+		t.Fatalf("Expected 4 functions, got %d", len(spec.Functions))
+	}
+	user0 := spec.Functions[0]
+	if user0.Name != "user" || user0.Receiver != "RootQuery" || user0.Description != "Looks up a user." ||
+		user0.Returns[0].Type != "Optional[User]" || !user0.Parameters[0].Required {
+		t.Errorf("Unexpected user query: %+v", user0)
+	}
+	search := spec.Functions[1]
+	if search.Returns[0].Type != "List[SearchResult]" || search.Parameters[1].Required || search.Parameters[1].Default != "20" {
+		t.Errorf("Unexpected search query: %+v", search)
+	}
+	if create := spec.Functions[2]; create.Returns[0].Type != "User" || strings.Join(create.Directives, ",") != "@auth(requires: ADMIN)" {
+		t.Errorf("Unexpected mutation: %+v", create)
+	}
+	if sub := spec.Functions[3]; sub.Receiver != "Subscription" || !sub.ServerStreaming {
+		t.Errorf("Expected streaming subscription, got %+v", sub)
+	}
+
+	// The rendered spec keeps implements and directives
+	parsed, err := specparser.NewParser().Parse(specparser.Render(spec), "bff")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	for _, typ := range parsed.Types {
+		if typ.Name == "User" && (len(typ.Implements) != 2 || len(typ.Directives) != 1) {
+			t.Errorf("Expected implements/directives to survive round trip, got %+v", typ)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"missing type", `type A { id: }`},
+		{"unterminated type", `type A { id: ID!`},
+		{"unterminated string", `"oops`},
+		{"unknown definition", `fragment F on User { id }`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.src), "broken"); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,484 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// document is a parsed SDL document.
+type document struct {
+	description string
+
+	// operations maps "query", "mutation" and "subscription" to root type names
+	operations map[string]string
+
+	// definitions in declaration order; extensions are merged into them
+	definitions []*definition
+	byName      map[string]*definition
+}
+
+// definition is a named type definition.
+type definition struct {
+	kind        string // scalar, type, interface, union, enum or input
+	name        string
+	description string
+	implements  []string
+	directives  []string
+	fields      []*fieldDef
+	members     []string
+	values      []enumValue
+}
+
+// fieldDef is a field of an object, interface or input type. Input fields
+// have no arguments and may have a default value.
+type fieldDef struct {
+	name         string
+	description  string
+	args         []*fieldDef
+	typ          *typeRef
+	defaultValue string
+	directives   []string
+}
+
+// typeRef is a (possibly wrapped) type reference: Name, [T] or T!.
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+// String formats the reference in SDL notation.
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type enumValue struct {
+	name        string
+	description string
+	directives  []string
+}
+
+// parser is a recursive-descent parser over lexed tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses SDL source into a document.
+func parse(src string) (*document, error) {
+	tokens, err := lex(strings.TrimPrefix(src, "\uFEFF"))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	doc := &document{
+		operations: make(map[string]string),
+		byName:     make(map[string]*definition),
+	}
+
+	for p.peek().kind != tokenEOF {
+		description := p.description()
+
+		tok := p.next()
+		if tok.kind != tokenName {
+			return nil, p.errorf(tok, "expected definition, got %q", tok.text)
+		}
+
+		extend := tok.text == "extend"
+		if extend {
+			tok = p.next()
+		}
+
+		switch tok.text {
+		case "schema":
+			if !extend && doc.description == "" {
+				doc.description = description
+			}
+			if err := p.schema(doc); err != nil {
+				return nil, err
+			}
+		case "directive":
+			if err := p.skipDirectiveDefinition(); err != nil {
+				return nil, err
+			}
+		case "scalar", "type", "interface", "union", "enum", "input":
+			def, err := p.definition(tok.text)
+			if err != nil {
+				return nil, err
+			}
+			def.description = description
+			doc.add(def, extend)
+		default:
+			return nil, p.errorf(tok, "unexpected %q", tok.text)
+		}
+	}
+
+	return doc, nil
+}
+
+// add records a definition, merging extensions into an existing definition
+// of the same name.
+func (doc *document) add(def *definition, extend bool) {
+	existing, ok := doc.byName[def.name]
+	if !ok {
+		doc.byName[def.name] = def
+		doc.definitions = append(doc.definitions, def)
+		return
+	}
+	if !extend && existing.description == "" {
+		existing.description = def.description
+	}
+	existing.implements = append(existing.implements, def.implements...)
+	existing.directives = append(existing.directives, def.directives...)
+	existing.fields = append(existing.fields, def.fields...)
+	existing.members = append(existing.members, def.members...)
+	existing.values = append(existing.values, def.values...)
+}
+
+// schema parses "schema @directives { query: Query ... }".
+func (p *parser) schema(doc *document) error {
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	if p.peek().text != "{" {
+		return nil
+	}
+	p.next()
+	for p.peek().text != "}" {
+		op := p.next()
+		if op.kind != tokenName {
+			return p.errorf(op, "expected operation type")
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		doc.operations[op.text] = p.next().text
+	}
+	p.next()
+	return nil
+}
+
+// definition parses the body of a type definition after its keyword.
+func (p *parser) definition(kind string) (*definition, error) {
+	name := p.next()
+	if name.kind != tokenName {
+		return nil, p.errorf(name, "expected %s name", kind)
+	}
+	def := &definition{kind: kind, name: name.text}
+
+	if p.peek().text == "implements" {
+		p.next()
+		if p.peek().text == "&" {
+			p.next()
+		}
+		for p.peek().kind == tokenName {
+			def.implements = append(def.implements, p.next().text)
+			if p.peek().text != "&" {
+				break
+			}
+			p.next()
+		}
+	}
+
+	var err error
+	if def.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case "type", "interface", "input":
+		if p.peek().text == "{" {
+			def.fields, err = p.fields(kind == "input")
+		}
+	case "union":
+		if p.peek().text == "=" {
+			p.next()
+			if p.peek().text == "|" {
+				p.next()
+			}
+			for p.peek().kind == tokenName {
+				def.members = append(def.members, p.next().text)
+				if p.peek().text != "|" {
+					break
+				}
+				p.next()
+			}
+		}
+	case "enum":
+		if p.peek().text == "{" {
+			def.values, err = p.enumValues()
+		}
+	}
+	return def, err
+}
+
+// fields parses "{ field(args): Type @directives ... }". Input fields take a
+// default value instead of arguments.
+func (p *parser) fields(input bool) ([]*fieldDef, error) {
+	p.next()
+	var fields []*fieldDef
+	for p.peek().text != "}" {
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf(p.peek(), "unterminated field list")
+		}
+		f, err := p.inputValue(!input)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	p.next()
+	return fields, nil
+}
+
+// inputValue parses a field, argument or input field definition.
+func (p *parser) inputValue(allowArgs bool) (*fieldDef, error) {
+	f := &fieldDef{description: p.description()}
+
+	name := p.next()
+	if name.kind != tokenName {
+		return nil, p.errorf(name, "expected field name, got %q", name.text)
+	}
+	f.name = name.text
+
+	if allowArgs && p.peek().text == "(" {
+		p.next()
+		for p.peek().text != ")" {
+			if p.peek().kind == tokenEOF {
+				return nil, p.errorf(name, "unterminated arguments of %s", f.name)
+			}
+			arg, err := p.inputValue(false)
+			if err != nil {
+				return nil, err
+			}
+			f.args = append(f.args, arg)
+		}
+		p.next()
+	}
+
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.typeRef()
+	if err != nil {
+		return nil, err
+	}
+	f.typ = typ
+
+	if p.peek().text == "=" {
+		p.next()
+		if f.defaultValue, err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// typeRef parses "Name", "[Type]" and a trailing "!".
+func (p *parser) typeRef() (*typeRef, error) {
+	t := &typeRef{}
+	tok := p.next()
+	switch {
+	case tok.text == "[":
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		t.elem = elem
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	case tok.kind == tokenName:
+		t.name = tok.text
+	default:
+		return nil, p.errorf(tok, "expected type, got %q", tok.text)
+	}
+	if p.peek().text == "!" {
+		p.next()
+		t.nonNull = true
+	}
+	return t, nil
+}
+
+// enumValues parses "{ VALUE @directives ... }".
+func (p *parser) enumValues() ([]enumValue, error) {
+	p.next()
+	var values []enumValue
+	for p.peek().text != "}" {
+		description := p.description()
+		name := p.next()
+		if name.kind != tokenName {
+			return nil, p.errorf(name, "expected enum value, got %q", name.text)
+		}
+		directives, err := p.directives()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, enumValue{name: name.text, description: description, directives: directives})
+	}
+	p.next()
+	return values, nil
+}
+
+// directives parses "@name(arg: value)" annotations into their SDL text.
+func (p *parser) directives() ([]string, error) {
+	var directives []string
+	for p.peek().text == "@" {
+		p.next()
+		name := p.next()
+		if name.kind != tokenName {
+			return nil, p.errorf(name, "expected directive name")
+		}
+		directive := "@" + name.text
+
+		if p.peek().text == "(" {
+			p.next()
+			var args []string
+			for p.peek().text != ")" {
+				arg := p.next()
+				if arg.kind != tokenName {
+					return nil, p.errorf(arg, "expected argument name in @%s", name.text)
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg.text+": "+value)
+			}
+			p.next()
+			directive += "(" + strings.Join(args, ", ") + ")"
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// value parses a constant value and returns its SDL text.
+func (p *parser) value() (string, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokenString:
+		return strconv.Quote(tok.text), nil
+	case tok.kind == tokenNumber || tok.kind == tokenName:
+		return tok.text, nil
+	case tok.text == "$":
+		return "$" + p.next().text, nil
+	case tok.text == "[":
+		var items []string
+		for p.peek().text != "]" {
+			if p.peek().kind == tokenEOF {
+				return "", p.errorf(tok, "unterminated list value")
+			}
+			item, err := p.value()
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		p.next()
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case tok.text == "{":
+		var entries []string
+		for p.peek().text != "}" {
+			key := p.next()
+			if key.kind != tokenName {
+				return "", p.errorf(key, "expected object field name")
+			}
+			if err := p.expect(":"); err != nil {
+				return "", err
+			}
+			item, err := p.value()
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, key.text+": "+item)
+		}
+		p.next()
+		return "{" + strings.Join(entries, ", ") + "}", nil
+	}
+	return "", p.errorf(tok, "expected value, got %q", tok.text)
+}
+
+// skipDirectiveDefinition skips "@name(args) repeatable on A | B".
+func (p *parser) skipDirectiveDefinition() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	p.next()
+	if p.peek().text == "(" {
+		p.next()
+		for p.peek().text != ")" {
+			if p.peek().kind == tokenEOF {
+				return p.errorf(p.peek(), "unterminated directive arguments")
+			}
+			if _, err := p.inputValue(false); err != nil {
+				return err
+			}
+		}
+		p.next()
+	}
+	if p.peek().text == "repeatable" {
+		p.next()
+	}
+	if tok := p.next(); tok.text != "on" {
+		return p.errorf(tok, "expected on in directive definition")
+	}
+	if p.peek().text == "|" {
+		p.next()
+	}
+	for p.peek().kind == tokenName {
+		p.next()
+		if p.peek().text != "|" {
+			break
+		}
+		p.next()
+	}
+	return nil
+}
+
+// description consumes an optional description string.
+func (p *parser) description() string {
+	if p.peek().kind == tokenString {
+		return strings.TrimSpace(p.next().text)
+	}
+	return ""
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(text string) error {
+	if tok := p.next(); tok.text != text {
+		return p.errorf(tok, "expected %q, got %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", tok.line, fmt.Sprintf(format, args...))
+}
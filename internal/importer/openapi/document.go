@@ -0,0 +1,343 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// object is a decoded JSON/YAML mapping that preserves key order, so
+// imported fields, paths and status codes keep their authored order.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]any)}
+}
+
+func (o *object) set(key string, value any) {
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// get returns the raw value for a key.
+func (o *object) get(key string) any {
+	if o == nil {
+		return nil
+	}
+	return o.values[key]
+}
+
+// obj returns a nested mapping, or nil.
+func (o *object) obj(key string) *object {
+	v, _ := o.get(key).(*object)
+	return v
+}
+
+// list returns a nested sequence, or nil.
+func (o *object) list(key string) []any {
+	v, _ := o.get(key).([]any)
+	return v
+}
+
+// str returns a scalar value as a string.
+func (o *object) str(key string) string {
+	return scalarString(o.get(key))
+}
+
+// boolean returns a boolean value, accepting "true" strings.
+func (o *object) boolean(key string) bool {
+	switch v := o.get(key).(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// has reports whether a key is present.
+func (o *object) has(key string) bool {
+	if o == nil {
+		return false
+	}
+	_, ok := o.values[key]
+	return ok
+}
+
+// scalarString formats a scalar value; mappings and sequences yield "".
+func scalarString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case *object, []any:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// document is a loaded OpenAPI (or referenced) file.
+type document struct {
+	path string
+	root *object
+}
+
+// decode parses JSON or YAML content into ordered values.
+func decode(data []byte) (*object, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.UseNumber()
+		v, err := decodeJSON(dec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		root, ok := v.(*object)
+		if !ok {
+			return nil, fmt.Errorf("document root must be an object")
+		}
+		return root, nil
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(node.Content) == 0 {
+		return nil, fmt.Errorf("document is empty")
+	}
+	v, err := decodeYAML(node.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	root, ok := v.(*object)
+	if !ok {
+		return nil, fmt.Errorf("document root must be a mapping")
+	}
+	return root, nil
+}
+
+// decodeJSON reads one JSON value from a token stream.
+func decodeJSON(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := newObject()
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return nil, fmt.Errorf("object key must be a string")
+				}
+				value, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj.set(key, value)
+			}
+			if _, err := dec.Token(); err != nil && err != io.EOF {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			var list []any
+			for dec.More() {
+				value, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			if _, err := dec.Token(); err != nil && err != io.EOF {
+				return nil, err
+			}
+			return list, nil
+		}
+		return nil, fmt.Errorf("unexpected delimiter %v", t)
+	default:
+		return t, nil
+	}
+}
+
+// decodeYAML converts a YAML node into ordered values.
+func decodeYAML(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		obj := newObject()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := decodeYAML(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			obj.set(node.Content[i].Value, value)
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		list := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := decodeYAML(child)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	case yaml.AliasNode:
+		return decodeYAML(node.Alias)
+	case yaml.ScalarNode:
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		return v, nil
+	}
+	return nil, nil
+}
+
+// resolver loads documents and resolves $ref pointers across files.
+type resolver struct {
+	docs map[string]*document
+}
+
+func newResolver() *resolver {
+	return &resolver{docs: make(map[string]*document)}
+}
+
+// load reads and caches a document by absolute path.
+func (r *resolver) load(path string) (*document, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if doc, ok := r.docs[absPath]; ok {
+		return doc, nil
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(absPath), err)
+	}
+
+	doc := &document{path: absPath, root: root}
+	r.docs[absPath] = doc
+	return doc, nil
+}
+
+// resolve follows $ref chains from a value in doc. It returns the target
+// value, the document it lives in, and the final reference ("" when v was
+// not a reference).
+func (r *resolver) resolve(doc *document, v any) (any, *document, string, error) {
+	ref := ""
+	for hops := 0; hops < 32; hops++ {
+		obj, ok := v.(*object)
+		if !ok || !obj.has("$ref") {
+			return v, doc, ref, nil
+		}
+		ref = obj.str("$ref")
+
+		target, targetDoc, err := r.lookup(doc, ref)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		v, doc = target, targetDoc
+	}
+	return nil, nil, "", fmt.Errorf("too many nested references at %s", ref)
+}
+
+// lookup resolves a single reference of the form "file#/json/pointer".
+func (r *resolver) lookup(doc *document, ref string) (any, *document, error) {
+	file, pointer, _ := strings.Cut(ref, "#")
+
+	target := doc
+	if file != "" {
+		if strings.Contains(file, "://") {
+			return nil, nil, fmt.Errorf("remote references are not supported: %s", ref)
+		}
+		loaded, err := r.load(filepath.Join(filepath.Dir(doc.path), file))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load reference %s: %w", ref, err)
+		}
+		target = loaded
+	}
+
+	var current any = target.root
+	for _, token := range pointerTokens(pointer) {
+		switch node := current.(type) {
+		case *object:
+			if !node.has(token) {
+				return nil, nil, fmt.Errorf("unresolved reference %s", ref)
+			}
+			current = node.get(token)
+		case []any:
+			var index int
+			if _, err := fmt.Sscanf(token, "%d", &index); err != nil || index < 0 || index >= len(node) {
+				return nil, nil, fmt.Errorf("unresolved reference %s", ref)
+			}
+			current = node[index]
+		default:
+			return nil, nil, fmt.Errorf("unresolved reference %s", ref)
+		}
+	}
+
+	return current, target, nil
+}
+
+// pointerTokens splits a JSON pointer ("/components/schemas/User") into
+// unescaped tokens.
+func pointerTokens(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return nil
+	}
+
+	tokens := strings.Split(pointer, "/")
+	for i, token := range tokens {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens
+}
+
+// refName returns the last pointer token of a reference, which names the
+// referenced schema.
+func refName(ref string) string {
+	_, pointer, _ := strings.Cut(ref, "#")
+	tokens := pointerTokens(pointer)
+	if len(tokens) == 0 {
+		base := filepath.Base(strings.TrimSuffix(ref, "#"))
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return tokens[len(tokens)-1]
+}
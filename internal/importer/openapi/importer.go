@@ -0,0 +1,852 @@
+// Package openapi imports OpenAPI 3.0/3.1 documents into spec analyses.
+//
+// Component schemas become spec types, paths and operations become spec
+// endpoints, and $ref pointers (local and relative-file) are resolved so the
+// resulting spec is deterministic and self-contained.
+package openapi
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// httpMethods are the operation keys of a path item.
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// ParseFile parses an OpenAPI document (YAML or JSON) into a spec analysis.
+func ParseFile(path string) (*specparser.SpecAnalysis, error) {
+	imp := newImporter()
+	doc, err := imp.resolver.load(path)
+	if err != nil {
+		return nil, err
+	}
+	return imp.run(doc)
+}
+
+// Parse parses OpenAPI content into a spec analysis. Relative-file
+// references are resolved against baseDir.
+func Parse(data []byte, baseDir string) (*specparser.SpecAnalysis, error) {
+	root, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := newImporter()
+	doc := &document{path: filepath.Join(absDir, "openapi"), root: root}
+	imp.resolver.docs[doc.path] = doc
+	return imp.run(doc)
+}
+
+// importer accumulates types while walking a document.
+type importer struct {
+	resolver *resolver
+	spec     *specparser.SpecAnalysis
+
+	// names maps a resolved schema location ("<file>#<pointer>") to its type name
+	names map[string]string
+
+	// hoisted maps inline schemas to the named types created for them
+	hoisted map[*object]string
+
+	// taken tracks type names already in use
+	taken map[string]bool
+}
+
+func newImporter() *importer {
+	return &importer{
+		resolver: newResolver(),
+		names:    make(map[string]string),
+		hoisted:  make(map[*object]string),
+		taken:    make(map[string]bool),
+	}
+}
+
+// run converts a loaded root document.
+func (imp *importer) run(doc *document) (*specparser.SpecAnalysis, error) {
+	root := doc.root
+
+	version := root.str("openapi")
+	if !strings.HasPrefix(version, "3.") {
+		if root.has("swagger") {
+			return nil, fmt.Errorf("swagger %s documents are not supported; convert to OpenAPI 3 first", root.str("swagger"))
+		}
+		return nil, fmt.Errorf("not an OpenAPI 3 document (missing or unsupported \"openapi\" version)")
+	}
+
+	info := root.obj("info")
+	name := info.str("title")
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(doc.path), filepath.Ext(doc.path))
+	}
+	overview := info.str("description")
+	if overview == "" {
+		overview = info.str("summary")
+	}
+
+	imp.spec = &specparser.SpecAnalysis{
+		Name:          name,
+		Overview:      strings.TrimSpace(overview),
+		Types:         []specparser.SpecType{},
+		Functions:     []specparser.SpecFunction{},
+		Tests:         []specparser.SpecTest{},
+		Dependencies:  []specparser.SpecDependency{},
+		Configuration: []specparser.SpecConfig{},
+		Endpoints:     []specparser.SpecEndpoint{},
+	}
+
+	// Reserve component schema names first so references resolve to them
+	schemas := root.obj("components").obj("schemas")
+	if schemas != nil {
+		for _, key := range schemas.keys {
+			imp.names[schemaKey(doc, "/components/schemas/"+escapePointer(key))] = imp.reserve(key)
+		}
+		for _, key := range schemas.keys {
+			location := schemaKey(doc, "/components/schemas/"+escapePointer(key))
+			if err := imp.addSchemaType(imp.names[location], schemas.get(key), doc); err != nil {
+				return nil, fmt.Errorf("schema %s: %w", key, err)
+			}
+		}
+	}
+
+	if err := imp.addEndpoints(doc); err != nil {
+		return nil, err
+	}
+
+	if servers := root.list("servers"); len(servers) > 0 {
+		if server, ok := servers[0].(*object); ok && server.str("url") != "" {
+			imp.spec.Configuration = append(imp.spec.Configuration, specparser.SpecConfig{
+				Name:        "API_BASE_URL",
+				Type:        "string",
+				Description: "Base URL of the API",
+				Default:     server.str("url"),
+			})
+		}
+	}
+
+	imp.spec.CalculateTotals()
+	return imp.spec, nil
+}
+
+// addSchemaType converts a named schema into a spec type.
+func (imp *importer) addSchemaType(name string, raw any, doc *document) error {
+	resolved, resolvedDoc, ref, err := imp.resolver.resolve(doc, raw)
+	if err != nil {
+		return err
+	}
+	schema, _ := resolved.(*object)
+
+	t := specparser.SpecType{
+		Name:        name,
+		Kind:        "struct",
+		Description: schemaDescription(schema),
+		IsPublic:    true,
+	}
+
+	switch {
+	case ref != "":
+		// A named schema that is just a reference to another one
+		t.Kind = "alias"
+		target, err := imp.typeOf(raw, doc, name)
+		if err != nil {
+			return err
+		}
+		t.AliasOf = target
+
+	case schema.has("enum"):
+		t.Kind = "enum"
+		for _, v := range schema.list("enum") {
+			if v == nil {
+				continue
+			}
+			value := specparser.SpecEnumValue{Name: scalarString(v)}
+			if _, isString := v.(string); !isString {
+				value.Value = value.Name
+			}
+			t.Values = append(t.Values, value)
+		}
+
+	case isConstEnum(schema):
+		// oneOf of titled constants, the JSON Schema idiom for named values
+		t.Kind = "enum"
+		for _, v := range schema.list("oneOf") {
+			option := v.(*object)
+			value := specparser.SpecEnumValue{
+				Name:        option.str("title"),
+				Value:       scalarString(option.get("const")),
+				Description: option.str("description"),
+			}
+			if value.Name == "" || value.Name == value.Value {
+				value.Name, value.Value = value.Value, ""
+			}
+			t.Values = append(t.Values, value)
+		}
+
+	case schema.has("oneOf") || schema.has("anyOf"):
+		t.Kind = "union"
+		variants := schema.list("oneOf")
+		if variants == nil {
+			variants = schema.list("anyOf")
+		}
+		for i, v := range variants {
+			variantType, err := imp.typeOf(v, resolvedDoc, fmt.Sprintf("%sOption%d", name, i+1))
+			if err != nil {
+				return err
+			}
+			variantName := variantType
+			if option, ok := v.(*object); ok && isIdentifier(option.str("title")) {
+				variantName = option.str("title")
+			}
+			if !isIdentifier(variantName) {
+				variantName = fmt.Sprintf("option%d", i+1)
+			}
+			t.Fields = append(t.Fields, specparser.SpecField{
+				Name:     variantName,
+				Type:     variantType,
+				Required: true,
+			})
+		}
+		if prop := schema.obj("discriminator").str("propertyName"); prop != "" {
+			t.Description = joinSentences(t.Description, fmt.Sprintf("Discriminated by `%s`.", prop))
+		}
+
+	case schema.has("allOf") || schema.has("properties") || schemaTypes(schema)[0] == "object" && !schema.has("additionalProperties"):
+		fields, err := imp.objectFields(schema, resolvedDoc, name, 0)
+		if err != nil {
+			return err
+		}
+		t.Fields = fields
+
+	default:
+		// Primitive, array, or map schemas become aliases
+		t.Kind = "alias"
+		target, err := imp.typeOf(schema, resolvedDoc, name+"Item")
+		if err != nil {
+			return err
+		}
+		t.AliasOf = target
+	}
+
+	imp.spec.Types = append(imp.spec.Types, t)
+	return nil
+}
+
+// objectFields collects the fields of an object schema, flattening allOf.
+func (imp *importer) objectFields(schema *object, doc *document, typeName string, depth int) ([]specparser.SpecField, error) {
+	if depth > 16 {
+		return nil, fmt.Errorf("allOf nesting too deep in %s", typeName)
+	}
+
+	var fields []specparser.SpecField
+
+	for _, part := range schema.list("allOf") {
+		resolved, partDoc, ref, err := imp.resolver.resolve(doc, part)
+		if err != nil {
+			return nil, err
+		}
+
+		// Inline types of a referenced base are named after the base
+		partName := typeName
+		if ref != "" {
+			if partName, err = imp.refType(part.(*object), doc); err != nil {
+				return nil, err
+			}
+		}
+
+		partSchema, _ := resolved.(*object)
+		partFields, err := imp.objectFields(partSchema, partDoc, partName, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		fields = mergeFields(fields, partFields)
+	}
+
+	required := make(map[string]bool)
+	for _, r := range schema.list("required") {
+		required[scalarString(r)] = true
+	}
+
+	props := schema.obj("properties")
+	if props == nil {
+		return fields, nil
+	}
+
+	for _, key := range props.keys {
+		raw := props.get(key)
+		fieldType, err := imp.typeOf(raw, doc, typeName+pascal(key))
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", key, err)
+		}
+
+		field := specparser.SpecField{
+			Name:     key,
+			Type:     fieldType,
+			Required: required[key],
+		}
+
+		// $ref siblings carry the property's own description
+		if prop, ok := raw.(*object); ok {
+			field.Description = schemaDescription(prop)
+			field.Default = scalarString(prop.get("default"))
+			if !prop.has("$ref") && field.Description == "" {
+				field.Description = prop.str("title")
+			}
+			field.Constraints = schemaConstraints(prop)
+			if inner, ok := nullableVariant(prop); ok {
+				innerSchema, _ := inner.(*object)
+				field.Constraints = append(field.Constraints, schemaConstraints(innerSchema)...)
+			}
+		}
+
+		fields = mergeFields(fields, []specparser.SpecField{field})
+	}
+
+	return fields, nil
+}
+
+// typeOf maps a schema to a pseudo-type. Inline enums, objects and unions are
+// hoisted into named types derived from context.
+func (imp *importer) typeOf(raw any, doc *document, context string) (string, error) {
+	if ref, ok := raw.(*object); ok && ref.has("$ref") {
+		return imp.refType(ref, doc)
+	}
+
+	schema, _ := raw.(*object)
+	if schema == nil {
+		return "any", nil
+	}
+
+	// anyOf/oneOf of a schema and null is the 2020-12 spelling of nullable
+	if inner, ok := nullableVariant(schema); ok {
+		innerType, err := imp.typeOf(inner, doc, context)
+		if err != nil || innerType == "any" || strings.HasPrefix(innerType, "Optional[") {
+			return innerType, err
+		}
+		return fmt.Sprintf("Optional[%s]", innerType), nil
+	}
+
+	types := schemaTypes(schema)
+	nullable := schema.boolean("nullable") || types[1] == "null"
+
+	var result string
+	var err error
+
+	switch {
+	case schema.has("enum") || schema.has("oneOf") || schema.has("anyOf") || schema.has("properties"):
+		if variants := schema.list("oneOf"); len(variants) == 1 {
+			return imp.typeOf(variants[0], doc, context)
+		}
+		result, err = imp.hoist(schema, doc, context)
+
+	case schema.has("allOf"):
+		if parts := schema.list("allOf"); len(parts) == 1 {
+			result, err = imp.typeOf(parts[0], doc, context)
+		} else {
+			result, err = imp.hoist(schema, doc, context)
+		}
+
+	case types[0] == "string":
+		result = stringType(schema.str("format"))
+
+	case types[0] == "integer":
+		result = "int"
+		if schema.str("format") == "int64" {
+			result = "int64"
+		}
+
+	case types[0] == "number":
+		result = "float"
+
+	case types[0] == "boolean":
+		result = "bool"
+
+	case types[0] == "array":
+		var item string
+		item, err = imp.typeOf(schema.get("items"), doc, context+"Item")
+		result = fmt.Sprintf("List[%s]", item)
+
+	case types[0] == "object" || schema.has("additionalProperties"):
+		value := "any"
+		if ap, ok := schema.get("additionalProperties").(*object); ok {
+			value, err = imp.typeOf(ap, doc, context+"Value")
+		}
+		result = fmt.Sprintf("Map[string, %s]", value)
+
+	default:
+		result = "any"
+	}
+
+	if err != nil {
+		return "", err
+	}
+	if nullable && result != "any" {
+		result = fmt.Sprintf("Optional[%s]", result)
+	}
+	return result, nil
+}
+
+// refType returns the type name for a referenced schema, importing schemas
+// that live outside the root document's components.
+func (imp *importer) refType(ref *object, doc *document) (string, error) {
+	resolved, targetDoc, finalRef, err := imp.resolver.resolve(doc, ref)
+	if err != nil {
+		return "", err
+	}
+
+	_, pointer, _ := strings.Cut(finalRef, "#")
+	location := schemaKey(targetDoc, pointer)
+	if name, ok := imp.names[location]; ok {
+		return name, nil
+	}
+
+	name := imp.reserve(refName(finalRef))
+	imp.names[location] = name
+	if err := imp.addSchemaType(name, resolved, targetDoc); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// hoist turns an inline schema into a named type.
+func (imp *importer) hoist(schema *object, doc *document, context string) (string, error) {
+	if name, ok := imp.hoisted[schema]; ok {
+		return name, nil
+	}
+
+	name := imp.reserve(context)
+	imp.hoisted[schema] = name
+	if err := imp.addSchemaType(name, schema, doc); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// addEndpoints converts paths and operations into endpoints.
+func (imp *importer) addEndpoints(doc *document) error {
+	root := doc.root
+	paths := root.obj("paths")
+	if paths == nil {
+		return nil
+	}
+
+	for _, path := range paths.keys {
+		resolved, itemDoc, _, err := imp.resolver.resolve(doc, paths.get(path))
+		if err != nil {
+			return fmt.Errorf("path %s: %w", path, err)
+		}
+		item, _ := resolved.(*object)
+		if item == nil {
+			continue
+		}
+
+		for _, method := range item.keys {
+			if !httpMethods[method] {
+				continue
+			}
+			op := item.obj(method)
+			if op == nil {
+				continue
+			}
+
+			endpoint, err := imp.endpoint(root, item, op, itemDoc, method, path)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			imp.spec.Endpoints = append(imp.spec.Endpoints, endpoint)
+		}
+	}
+
+	return nil
+}
+
+// endpoint converts a single operation.
+func (imp *importer) endpoint(root, item, op *object, doc *document, method, path string) (specparser.SpecEndpoint, error) {
+	e := specparser.SpecEndpoint{
+		Name:        op.str("operationId"),
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		Description: strings.TrimSpace(op.str("summary")),
+	}
+	if e.Name == "" {
+		e.Name = operationName(method, path)
+	}
+	if e.Description == "" {
+		e.Description = strings.TrimSpace(op.str("description"))
+	}
+	for _, tag := range op.list("tags") {
+		e.Tags = append(e.Tags, scalarString(tag))
+	}
+
+	context := pascal(e.Name)
+
+	params, err := imp.operationParameters(item, op, doc)
+	if err != nil {
+		return e, err
+	}
+	for _, p := range params {
+		param := specparser.SpecParameter{
+			Name:        p.str("name"),
+			Description: strings.TrimSpace(p.str("description")),
+			Required:    p.boolean("required") || p.str("in") == "path",
+		}
+
+		if schema := p.get("schema"); schema != nil {
+			param.Type, err = imp.typeOf(schema, doc, context+pascal(param.Name))
+			if resolved, _, _, rerr := imp.resolver.resolve(doc, schema); rerr == nil {
+				if s, ok := resolved.(*object); ok {
+					param.Default = scalarString(s.get("default"))
+				}
+			}
+		} else {
+			param.Type, err = imp.contentType(p.obj("content"), doc, context+pascal(param.Name))
+		}
+		if err != nil {
+			return e, err
+		}
+
+		switch p.str("in") {
+		case "path":
+			e.PathParams = append(e.PathParams, param)
+		case "query":
+			e.QueryParams = append(e.QueryParams, param)
+		case "header":
+			e.HeaderParams = append(e.HeaderParams, param)
+		}
+	}
+
+	if op.has("requestBody") {
+		resolved, bodyDoc, _, err := imp.resolver.resolve(doc, op.get("requestBody"))
+		if err != nil {
+			return e, err
+		}
+		if body, ok := resolved.(*object); ok {
+			e.RequestType, err = imp.contentType(body.obj("content"), bodyDoc, context+"Request")
+			if err != nil {
+				return e, err
+			}
+		}
+	}
+
+	if responses := op.obj("responses"); responses != nil {
+		for _, status := range responses.keys {
+			resolved, respDoc, _, err := imp.resolver.resolve(doc, responses.get(status))
+			if err != nil {
+				return e, err
+			}
+			resp, _ := resolved.(*object)
+
+			respContext := context + "Response"
+			if !strings.HasPrefix(status, "2") {
+				respContext = context + pascal(status) + "Response"
+			}
+			respType, err := imp.contentType(resp.obj("content"), respDoc, respContext)
+			if err != nil {
+				return e, err
+			}
+
+			e.Responses = append(e.Responses, specparser.SpecResponse{
+				Status:      status,
+				Type:        respType,
+				Description: strings.TrimSpace(resp.str("description")),
+			})
+		}
+	}
+
+	// Operation-level security overrides the document default; an empty
+	// list marks the operation as public
+	security := root.list("security")
+	if op.has("security") {
+		security = op.list("security")
+	}
+	seen := make(map[string]bool)
+	for _, requirement := range security {
+		req, ok := requirement.(*object)
+		if !ok {
+			continue
+		}
+		for _, scheme := range req.keys {
+			if !seen[scheme] {
+				seen[scheme] = true
+				e.Auth = append(e.Auth, scheme)
+			}
+		}
+	}
+
+	return e, nil
+}
+
+// operationParameters merges path-level and operation-level parameters; an
+// operation parameter overrides a path parameter with the same name and location.
+func (imp *importer) operationParameters(item, op *object, doc *document) ([]*object, error) {
+	var params []*object
+	index := make(map[string]int)
+
+	for _, list := range [][]any{item.list("parameters"), op.list("parameters")} {
+		for _, raw := range list {
+			resolved, _, _, err := imp.resolver.resolve(doc, raw)
+			if err != nil {
+				return nil, err
+			}
+			p, ok := resolved.(*object)
+			if !ok {
+				continue
+			}
+
+			key := p.str("in") + ":" + p.str("name")
+			if i, exists := index[key]; exists {
+				params[i] = p
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, p)
+		}
+	}
+
+	return params, nil
+}
+
+// contentType returns the type of a request/response body, preferring JSON.
+func (imp *importer) contentType(content *object, doc *document, context string) (string, error) {
+	if content == nil || len(content.keys) == 0 {
+		return "", nil
+	}
+
+	mediaType := content.keys[0]
+	for _, key := range content.keys {
+		if key == "application/json" {
+			mediaType = key
+			break
+		}
+		if strings.Contains(key, "json") && !strings.Contains(mediaType, "json") {
+			mediaType = key
+		}
+	}
+
+	media := content.obj(mediaType)
+	if !media.has("schema") {
+		if strings.Contains(mediaType, "json") {
+			return "any", nil
+		}
+		return "bytes", nil
+	}
+	return imp.typeOf(media.get("schema"), doc, context)
+}
+
+// reserve returns a unique type name derived from base.
+func (imp *importer) reserve(base string) string {
+	name := pascal(base)
+	if name == "" {
+		name = "Type"
+	}
+	candidate := name
+	for i := 2; imp.taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	imp.taken[candidate] = true
+	return candidate
+}
+
+// schemaTypes returns the primary type of a schema and "null" as the second
+// element when the schema is nullable via a 3.1 type array.
+func schemaTypes(schema *object) [2]string {
+	var result [2]string
+	switch t := schema.get("type").(type) {
+	case string:
+		result[0] = t
+	case []any:
+		for _, v := range t {
+			name := scalarString(v)
+			if name == "null" {
+				result[1] = "null"
+			} else if result[0] == "" {
+				result[0] = name
+			}
+		}
+	}
+	return result
+}
+
+// isConstEnum reports whether a schema is a oneOf whose options are all
+// constants.
+func isConstEnum(schema *object) bool {
+	options := schema.list("oneOf")
+	if len(options) == 0 {
+		return false
+	}
+	for _, v := range options {
+		option, ok := v.(*object)
+		if !ok || !option.has("const") {
+			return false
+		}
+	}
+	return true
+}
+
+// nullableVariant returns X for a schema of the form anyOf/oneOf [X, null].
+func nullableVariant(schema *object) (any, bool) {
+	variants := schema.list("anyOf")
+	if variants == nil {
+		variants = schema.list("oneOf")
+	}
+	if len(variants) != 2 {
+		return nil, false
+	}
+	for i, v := range variants {
+		if option, ok := v.(*object); ok && len(option.keys) == 1 && option.str("type") == "null" {
+			return variants[1-i], true
+		}
+	}
+	return nil, false
+}
+
+// constraintKeywords maps JSON Schema validation keywords to constraint
+// kinds. Array and map sizes are lengths, as strings' are.
+var constraintKeywords = []struct{ keyword, kind string }{
+	{"minimum", "min"}, {"maximum", "max"},
+	{"minLength", "minLength"}, {"maxLength", "maxLength"},
+	{"minItems", "minLength"}, {"maxItems", "maxLength"},
+	{"minProperties", "minLength"}, {"maxProperties", "maxLength"},
+	{"pattern", "pattern"},
+}
+
+// schemaConstraints returns the constraints a schema's validation keywords
+// put on a field. The email and uri formats are constraints too; other
+// formats choose the field's type (see stringType).
+func schemaConstraints(schema *object) []specparser.SpecConstraint {
+	var constraints []specparser.SpecConstraint
+	for _, k := range constraintKeywords {
+		if value := schema.str(k.keyword); value != "" {
+			constraints = append(constraints, specparser.SpecConstraint{Kind: k.kind, Value: value})
+		}
+	}
+	switch schema.str("format") {
+	case "email":
+		constraints = append(constraints, specparser.SpecConstraint{Kind: "format", Value: "email"})
+	case "uri":
+		constraints = append(constraints, specparser.SpecConstraint{Kind: "format", Value: "url"})
+	}
+	return constraints
+}
+
+// stringType maps a string format to a pseudo-type.
+func stringType(format string) string {
+	switch format {
+	case "date":
+		return "date"
+	case "date-time":
+		return "datetime"
+	case "uuid":
+		return "uuid"
+	case "byte", "binary":
+		return "bytes"
+	default:
+		return "string"
+	}
+}
+
+// schemaDescription returns a schema's description, falling back to its title.
+func schemaDescription(schema *object) string {
+	if desc := strings.TrimSpace(schema.str("description")); desc != "" {
+		return desc
+	}
+	return strings.TrimSpace(schema.str("title"))
+}
+
+// mergeFields appends fields, replacing earlier fields with the same name.
+func mergeFields(fields, more []specparser.SpecField) []specparser.SpecField {
+	for _, f := range more {
+		replaced := false
+		for i := range fields {
+			if fields[i].Name == f.Name {
+				fields[i] = f
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// operationName derives a camelCase name from a method and path, e.g.
+// "get /users/{id}" becomes "getUsersById".
+func operationName(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			sb.WriteString("By")
+			segment = strings.Trim(segment, "{}")
+		}
+		sb.WriteString(pascal(segment))
+	}
+	return sb.String()
+}
+
+// pascal converts a schema or property name to a PascalCase identifier,
+// keeping existing capitalization within words.
+func pascal(s string) string {
+	var sb strings.Builder
+	upperNext := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			sb.WriteRune(unicode.ToUpper(r))
+			upperNext = false
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// isIdentifier reports whether s is a plain identifier.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// schemaKey identifies a schema by document and JSON pointer.
+func schemaKey(doc *document, pointer string) string {
+	return doc.path + "#" + pointer
+}
+
+// escapePointer escapes a JSON pointer token.
+func escapePointer(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+// joinSentences joins two description fragments with a space.
+func joinSentences(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
@@ -0,0 +1,239 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const petstoreYAML = `openapi: 3.0.3
+info:
+  title: petstore
+  description: Manages pets.
+servers:
+  - url: https://api.example.com/v1
+security:
+  - bearerAuth: []
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        schema:
+          type: string
+          format: uuid
+    get:
+      operationId: getPet
+      summary: Get a pet by ID
+      tags: [pets]
+      parameters:
+        - $ref: '#/components/parameters/Verbose'
+      responses:
+        '200':
+          description: The pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        '404':
+          $ref: '#/components/responses/NotFound'
+  /pets:
+    post:
+      security: []
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewPet'
+      responses:
+        '201':
+          description: Created
+components:
+  parameters:
+    Verbose:
+      name: verbose
+      in: query
+      schema:
+        type: boolean
+        default: false
+  responses:
+    NotFound:
+      description: Pet not found
+      content:
+        application/json:
+          schema:
+            $ref: 'errors.yaml#/Error'
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          description: The pet's name
+        status:
+          type: string
+          enum: [available, sold]
+        tags:
+          type: array
+          items:
+            type: string
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          required: [id]
+          properties:
+            id:
+              type: integer
+              format: int64
+            owner:
+              $ref: '#/components/schemas/Owner'
+    Owner:
+      oneOf:
+        - $ref: '#/components/schemas/Person'
+        - $ref: '#/components/schemas/Company'
+      discriminator:
+        propertyName: kind
+    Person:
+      type: object
+      properties:
+        name:
+          type: string
+    Company:
+      type: object
+      properties:
+        name:
+          type: string
+        nickname:
+          type: [string, "null"]
+`
+
+const errorsYAML = `Error:
+  type: object
+  required: [code]
+  properties:
+    code:
+      type: integer
+    message:
+      type: string
+`
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "petstore.yaml")
+	if err := os.WriteFile(specPath, []byte(petstoreYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "errors.yaml"), []byte(errorsYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := ParseFile(specPath)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+
+	if spec.Name != "petstore" || spec.Overview != "Manages pets." {
+		t.Errorf("Unexpected name/overview: %q / %q", spec.Name, spec.Overview)
+	}
+
+	var names []string
+	types := make(map[string]specparser.SpecType)
+	for _, typ := range spec.Types {
+		names = append(names, typ.Name)
+		types[typ.Name] = typ
+	}
+	expectedNames := "NewPetStatus,NewPet,Pet,Owner,Person,Company,Error"
+	if strings.Join(names, ",") != expectedNames {
+		t.Errorf("Types = %s, expected %s", strings.Join(names, ","), expectedNames)
+	}
+
+	// allOf flattens the referenced base fields
+	var petFields []string
+	for _, f := range types["Pet"].Fields {
+		petFields = append(petFields, f.Name+":"+f.Type)
+	}
+	if got := strings.Join(petFields, ","); got != "name:string,status:NewPetStatus,tags:List[string],id:int64,owner:Owner" {
+		t.Errorf("Pet fields = %s", got)
+	}
+
+	if status := types["NewPetStatus"]; status.Kind != "enum" || len(status.Values) != 2 {
+		t.Errorf("Expected inline enum to be hoisted, got %+v", status)
+	}
+	if owner := types["Owner"]; owner.Kind != "union" || len(owner.Fields) != 2 || owner.Fields[1].Type != "Company" {
+		t.Errorf("Expected Owner union of Person and Company, got %+v", owner)
+	}
+	if nickname := types["Company"].Fields[1]; nickname.Type != "Optional[string]" {
+		t.Errorf("Expected 3.1 nullable type, got %s", nickname.Type)
+	}
+
+	if len(spec.Endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(spec.Endpoints))
+	}
+
+	get := spec.Endpoints[0]
+	if get.Name != "getPet" || get.Method != "GET" || get.Path != "/pets/{petId}" {
+		t.Errorf("Unexpected endpoint: %+v", get)
+	}
+	if len(get.PathParams) != 1 || get.PathParams[0].Type != "uuid" || !get.PathParams[0].Required {
+		t.Errorf("Unexpected path params: %+v", get.PathParams)
+	}
+	if len(get.QueryParams) != 1 || get.QueryParams[0].Name != "verbose" || get.QueryParams[0].Default != "false" {
+		t.Errorf("Unexpected query params: %+v", get.QueryParams)
+	}
+	if len(get.Responses) != 2 || get.Responses[0].Type != "Pet" || get.Responses[1].Type != "Error" {
+		t.Errorf("Unexpected responses: %+v", get.Responses)
+	}
+	if strings.Join(get.Auth, ",") != "bearerAuth" {
+		t.Errorf("Expected document-level auth, got %v", get.Auth)
+	}
+
+	post := spec.Endpoints[1]
+	if post.Name != "postPets" || post.RequestType != "NewPet" || len(post.Auth) != 0 {
+		t.Errorf("Unexpected endpoint: %+v", post)
+	}
+
+	if len(spec.Configuration) != 1 || spec.Configuration[0].Default != "https://api.example.com/v1" {
+		t.Errorf("Expected base URL configuration, got %+v", spec.Configuration)
+	}
+
+	md := specparser.Render(spec)
+	for _, expected := range []string{"### Pet (struct)", "### Owner (union)", "### GET /pets/{petId}", "**Response 404**: `Error` - Pet not found"} {
+		if !strings.Contains(md, expected) {
+			t.Errorf("Expected rendered spec to contain %q", expected)
+		}
+	}
+}
+
+func TestParseJSONPreservesOrder(t *testing.T) {
+	data := []byte(`{
+	"openapi": "3.1.0",
+	"info": {"title": "ordering"},
+	"components": {"schemas": {
+		"Zebra": {"type": "object", "properties": {"z": {"type": "string"}, "a": {"type": "number"}}},
+		"Alpha": {"type": "string", "format": "date-time"}
+	}}
+}`)
+
+	spec, err := Parse(data, t.TempDir())
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(spec.Types) != 2 || spec.Types[0].Name != "Zebra" || spec.Types[1].Kind != "alias" {
+		t.Fatalf("Unexpected types: %+v", spec.Types)
+	}
+	if f := spec.Types[0].Fields; f[0].Name != "z" || f[1].Type != "float" {
+		t.Errorf("Unexpected field order or types: %+v", f)
+	}
+}
+
+func TestParseRejectsSwagger2(t *testing.T) {
+	if _, err := Parse([]byte("swagger: '2.0'\ninfo:\n  title: old\n"), "."); err == nil {
+		t.Error("Expected error for Swagger 2.0 document")
+	}
+}
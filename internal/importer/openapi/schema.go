@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// ParseSchemaFile parses a standalone JSON Schema (draft 2020-12) document
+// into a spec analysis. OpenAPI 3.1 schemas are JSON Schema, so the same
+// conversion applies: $defs (and legacy definitions) become named types, and
+// a root schema that describes a value becomes a type named after its title.
+func ParseSchemaFile(path string) (*specparser.SpecAnalysis, error) {
+	imp := newImporter()
+	doc, err := imp.resolver.load(path)
+	if err != nil {
+		return nil, err
+	}
+	return imp.runSchema(doc)
+}
+
+// ParseSchema parses JSON Schema content into a spec analysis. Relative-file
+// references are resolved against baseDir.
+func ParseSchema(data []byte, baseDir string) (*specparser.SpecAnalysis, error) {
+	root, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := newImporter()
+	doc := &document{path: filepath.Join(absDir, "schema.json"), root: root}
+	imp.resolver.docs[doc.path] = doc
+	return imp.runSchema(doc)
+}
+
+// runSchema converts a loaded JSON Schema document.
+func (imp *importer) runSchema(doc *document) (*specparser.SpecAnalysis, error) {
+	root := doc.root
+
+	if dialect := root.str("$schema"); dialect != "" && !strings.Contains(dialect, "json-schema.org") {
+		return nil, fmt.Errorf("unsupported $schema %q", dialect)
+	}
+
+	name := root.str("title")
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(doc.path), filepath.Ext(doc.path))
+	}
+
+	imp.spec = &specparser.SpecAnalysis{
+		Name:          name,
+		Overview:      strings.TrimSpace(root.str("description")),
+		Types:         []specparser.SpecType{},
+		Functions:     []specparser.SpecFunction{},
+		Tests:         []specparser.SpecTest{},
+		Dependencies:  []specparser.SpecDependency{},
+		Configuration: []specparser.SpecConfig{},
+		Endpoints:     []specparser.SpecEndpoint{},
+	}
+
+	// A root that only bundles $defs is a container, not a type
+	rootIsType := root.has("type") || root.has("properties") || root.has("enum") ||
+		root.has("oneOf") || root.has("anyOf") || root.has("allOf") || root.has("$ref")
+	if rootIsType {
+		imp.names[schemaKey(doc, "")] = imp.reserve(pascal(name))
+	}
+
+	// Reserve definition names first so references resolve to them
+	type definition struct{ location, key, section string }
+	var defs []definition
+	for _, section := range []string{"$defs", "definitions"} {
+		container := root.obj(section)
+		if container == nil {
+			continue
+		}
+		for _, key := range container.keys {
+			location := schemaKey(doc, "/"+section+"/"+escapePointer(key))
+			imp.names[location] = imp.reserve(key)
+			defs = append(defs, definition{location, key, section})
+		}
+	}
+
+	if rootIsType {
+		// The root is converted without its bundled definitions
+		self := newObject()
+		for _, key := range root.keys {
+			if key != "$defs" && key != "definitions" && key != "$schema" && key != "$id" {
+				self.set(key, root.get(key))
+			}
+		}
+		if err := imp.addSchemaType(imp.names[schemaKey(doc, "")], self, doc); err != nil {
+			return nil, fmt.Errorf("root schema: %w", err)
+		}
+	}
+
+	for _, def := range defs {
+		if err := imp.addSchemaType(imp.names[def.location], root.obj(def.section).get(def.key), doc); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", def.section, def.key, err)
+		}
+	}
+
+	imp.spec.CalculateTotals()
+	return imp.spec, nil
+}
@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const orderSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Order",
+  "description": "A customer order.",
+  "type": "object",
+  "properties": {
+    "id": {"type": "string", "format": "uuid"},
+    "status": {"$ref": "#/$defs/Status"},
+    "priority": {"$ref": "#/$defs/Priority"},
+    "items": {"type": "array", "items": {"$ref": "#/$defs/LineItem"}, "minItems": 1},
+    "note": {"type": ["string", "null"]},
+    "payment": {"$ref": "#/$defs/Payment"},
+    "placedAt": {"$ref": "#/$defs/Timestamp"}
+  },
+  "required": ["id", "status", "items"],
+  "$defs": {
+    "Status": {"enum": ["pending", "shipped"]},
+    "Priority": {
+      "oneOf": [
+        {"const": 1, "title": "Low"},
+        {"const": 2, "title": "High", "description": "Ship first"}
+      ]
+    },
+    "LineItem": {
+      "type": "object",
+      "properties": {
+        "sku": {"type": "string", "pattern": "^[A-Z0-9-]+$"},
+        "quantity": {"type": "integer", "default": 1, "minimum": 1}
+      },
+      "required": ["sku"]
+    },
+    "Card": {"type": "object", "properties": {"number": {"type": "string"}}},
+    "Payment": {
+      "oneOf": [
+        {"$ref": "#/$defs/Card"},
+        {"type": "string", "title": "Voucher"}
+      ]
+    },
+    "Timestamp": {"type": "string", "format": "date-time"}
+  }
+}`
+
+func TestParseSchema(t *testing.T) {
+	spec, err := ParseSchema([]byte(orderSchema), ".")
+	if err != nil {
+		t.Fatalf("ParseSchema() error: %v", err)
+	}
+
+	if spec.Name != "Order" || spec.Overview != "A customer order." {
+		t.Errorf("Unexpected name/overview: %q %q", spec.Name, spec.Overview)
+	}
+
+	var names []string
+	types := make(map[string]specparser.SpecType)
+	for _, typ := range spec.Types {
+		names = append(names, typ.Name+":"+typ.Kind)
+		types[typ.Name] = typ
+	}
+	expected := "Order:struct,Status:enum,Priority:enum,LineItem:struct,Card:struct,Payment:union,Timestamp:alias"
+	if got := strings.Join(names, ","); got != expected {
+		t.Errorf("Types = %s, expected %s", got, expected)
+	}
+
+	tests := []struct {
+		field    string
+		typ      string
+		required bool
+	}{
+		{"id", "uuid", true},
+		{"status", "Status", true},
+		{"priority", "Priority", false},
+		{"items", "List[LineItem]", true},
+		{"note", "Optional[string]", false},
+		{"payment", "Payment", false},
+		{"placedAt", "Timestamp", false},
+	}
+	order := types["Order"]
+	if len(order.Fields) != len(tests) {
+		t.Fatalf("Expected %d Order fields, got %d", len(tests), len(order.Fields))
+	}
+	for i, tt := range tests {
+		f := order.Fields[i]
+		if f.Name != tt.field || f.Type != tt.typ || f.Required != tt.required {
+			t.Errorf("Field %d = %s %s required=%v, expected %s %s required=%v", i, f.Name, f.Type, f.Required, tt.field, tt.typ, tt.required)
+		}
+	}
+
+	if quantity := types["LineItem"].Fields[1]; quantity.Default != "1" || quantity.Type != "int" {
+		t.Errorf("Unexpected quantity field: %+v", quantity)
+	}
+
+	// Validation keywords become constraints
+	constraints := []struct {
+		field      specparser.SpecField
+		constraint specparser.SpecConstraint
+	}{
+		{order.Fields[3], specparser.SpecConstraint{Kind: "minLength", Value: "1"}},
+		{types["LineItem"].Fields[0], specparser.SpecConstraint{Kind: "pattern", Value: "^[A-Z0-9-]+$"}},
+		{types["LineItem"].Fields[1], specparser.SpecConstraint{Kind: "min", Value: "1"}},
+	}
+	for _, tt := range constraints {
+		if len(tt.field.Constraints) != 1 || tt.field.Constraints[0].Kind != tt.constraint.Kind || tt.field.Constraints[0].Value != tt.constraint.Value {
+			t.Errorf("Expected %s to have constraint %+v, got %+v", tt.field.Name, tt.constraint, tt.field.Constraints)
+		}
+	}
+	priority := types["Priority"]
+	if len(priority.Values) != 2 || priority.Values[1].Name != "High" || priority.Values[1].Value != "2" ||
+		priority.Values[1].Description != "Ship first" {
+		t.Errorf("Unexpected const enum: %+v", priority.Values)
+	}
+	if timestamp := types["Timestamp"]; timestamp.AliasOf != "datetime" {
+		t.Errorf("Expected Timestamp to alias datetime, got %+v", timestamp)
+	}
+	payment := types["Payment"]
+	if len(payment.Fields) != 2 || payment.Fields[0].Type != "Card" || payment.Fields[1].Name != "Voucher" {
+		t.Errorf("Unexpected union: %+v", payment.Fields)
+	}
+}
+
+func TestJSONSchemaRoundTrip(t *testing.T) {
+	spec, err := ParseSchema([]byte(orderSchema), ".")
+	if err != nil {
+		t.Fatalf("ParseSchema() error: %v", err)
+	}
+
+	// Schema to .spec.md and back
+	parsed, err := specparser.NewParser().Parse(specparser.Render(spec), "order.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() of rendered spec error: %v", err)
+	}
+
+	exported, err := specparser.RenderJSONSchema(parsed)
+	if err != nil {
+		t.Fatalf("RenderJSONSchema() error: %v", err)
+	}
+	// Only note is nullable; the other optional fields are just not required
+	if n := strings.Count(string(exported), `"null"`); n != 1 {
+		t.Errorf("Expected only note to allow null, got %d nullable schemas:\n%s", n, exported)
+	}
+
+	again, err := ParseSchema(exported, ".")
+	if err != nil {
+		t.Fatalf("ParseSchema() of exported schema error: %v\n%s", err, exported)
+	}
+
+	// The exported document bundles every type under $defs
+	if again.Name != spec.Name || len(again.Types) != len(spec.Types) {
+		t.Fatalf("Expected %d types named %s, got %d named %s", len(spec.Types), spec.Name, len(again.Types), again.Name)
+	}
+	for i, want := range spec.Types {
+		got := again.Types[i]
+		if got.Name != want.Name || got.Kind != want.Kind || got.AliasOf != want.AliasOf {
+			t.Errorf("Type %d = %s (%s %s), expected %s (%s %s)", i, got.Name, got.Kind, got.AliasOf, want.Name, want.Kind, want.AliasOf)
+			continue
+		}
+		if len(got.Fields) != len(want.Fields) || len(got.Values) != len(want.Values) {
+			t.Errorf("Type %s changed shape: %+v, expected %+v", want.Name, got, want)
+			continue
+		}
+		for j, f := range want.Fields {
+			g := got.Fields[j]
+			if g.Name != f.Name || g.Type != f.Type || g.Required != f.Required || g.Default != f.Default ||
+				fmt.Sprint(g.Constraints) != fmt.Sprint(f.Constraints) {
+				t.Errorf("%s field %d = %+v, expected %+v", want.Name, j, g, f)
+			}
+		}
+		for j, v := range want.Values {
+			if got.Values[j] != v {
+				t.Errorf("%s value %d = %+v, expected %+v", want.Name, j, got.Values[j], v)
+			}
+		}
+	}
+}
+
+func TestParseSchemaRejectsOtherDialects(t *testing.T) {
+	_, err := ParseSchema([]byte(`{"$schema": "https://example.com/meta", "type": "object"}`), ".")
+	if err == nil {
+		t.Error("Expected error for non-JSON Schema dialect")
+	}
+}
@@ -0,0 +1,413 @@
+package protobuf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// scalarTypes maps proto scalar types to pseudo-types.
+var scalarTypes = map[string]string{
+	"double":   "float",
+	"float":    "float",
+	"int32":    "int",
+	"sint32":   "int",
+	"sfixed32": "int",
+	"uint32":   "int",
+	"fixed32":  "int",
+	"int64":    "int64",
+	"sint64":   "int64",
+	"sfixed64": "int64",
+	"uint64":   "int64",
+	"fixed64":  "int64",
+	"bool":     "bool",
+	"string":   "string",
+	"bytes":    "bytes",
+}
+
+// wellKnownTypes maps google.protobuf types to pseudo-types following the
+// proto3 JSON mapping. Empty maps to "" and is dropped from signatures.
+var wellKnownTypes = map[string]string{
+	"google.protobuf.Timestamp":   "datetime",
+	"google.protobuf.Duration":    "string",
+	"google.protobuf.FieldMask":   "string",
+	"google.protobuf.Any":         "any",
+	"google.protobuf.Value":       "any",
+	"google.protobuf.Struct":      "Map[string, any]",
+	"google.protobuf.ListValue":   "List[any]",
+	"google.protobuf.Empty":       "",
+	"google.protobuf.DoubleValue": "Optional[float]",
+	"google.protobuf.FloatValue":  "Optional[float]",
+	"google.protobuf.Int64Value":  "Optional[int64]",
+	"google.protobuf.UInt64Value": "Optional[int64]",
+	"google.protobuf.Int32Value":  "Optional[int]",
+	"google.protobuf.UInt32Value": "Optional[int]",
+	"google.protobuf.BoolValue":   "Optional[bool]",
+	"google.protobuf.StringValue": "Optional[string]",
+	"google.protobuf.BytesValue":  "Optional[bytes]",
+}
+
+// ParseFile parses a .proto file into a spec analysis. Imports are resolved
+// relative to the importing file and the root file's directory; types from
+// imported files are included so references resolve.
+func ParseFile(path string) (*specparser.SpecAnalysis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSource(data, path)
+}
+
+// Parse parses proto content into a spec analysis. Imports are resolved
+// against baseDir.
+func Parse(data []byte, baseDir string) (*specparser.SpecAnalysis, error) {
+	return parseSource(data, filepath.Join(baseDir, "schema.proto"))
+}
+
+func parseSource(data []byte, path string) (*specparser.SpecAnalysis, error) {
+	root, err := parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	imp := &importer{
+		rootDir: filepath.Dir(path),
+		loaded:  make(map[string]bool),
+		symbols: make(map[string]string),
+		unions:  make(map[*oneof]string),
+		taken:   make(map[string]bool),
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		imp.loaded[abs] = true
+	}
+	if err := imp.loadImports(root, filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	imp.files = append(imp.files, root)
+
+	name := root.pkg
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return imp.run(root, name), nil
+}
+
+// importer converts parsed files into a spec analysis.
+type importer struct {
+	rootDir string
+
+	// files are the imported files (dependencies first) followed by the root
+	files  []*file
+	loaded map[string]bool
+
+	// symbols maps fully qualified proto names to spec type names
+	symbols map[string]string
+
+	// unions maps oneofs to the union types created for them
+	unions map[*oneof]string
+
+	// taken tracks type names already in use
+	taken map[string]bool
+}
+
+// loadImports parses imported files depth-first. Well-known google/protobuf
+// imports and files that cannot be found are skipped; references to their
+// types fall back to the type's simple name.
+func (imp *importer) loadImports(f *file, dir string) error {
+	for _, path := range f.imports {
+		if strings.HasPrefix(path, "google/protobuf/") {
+			continue
+		}
+
+		var found string
+		for _, candidate := range []string{filepath.Join(dir, path), filepath.Join(imp.rootDir, path)} {
+			if _, err := os.Stat(candidate); err == nil {
+				found, _ = filepath.Abs(candidate)
+				break
+			}
+		}
+		if found == "" || imp.loaded[found] {
+			continue
+		}
+		imp.loaded[found] = true
+
+		data, err := os.ReadFile(found)
+		if err != nil {
+			return err
+		}
+		dep, err := parse(string(data))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := imp.loadImports(dep, filepath.Dir(found)); err != nil {
+			return err
+		}
+		imp.files = append(imp.files, dep)
+	}
+	return nil
+}
+
+// run declares every type before converting, so forward references and
+// references across files resolve to the final spec names.
+func (imp *importer) run(root *file, name string) *specparser.SpecAnalysis {
+	for _, f := range imp.files {
+		for _, decl := range f.types {
+			imp.declare(decl, f.pkg, "")
+		}
+	}
+
+	spec := &specparser.SpecAnalysis{
+		Name:          name,
+		Overview:      root.doc,
+		Types:         []specparser.SpecType{},
+		Functions:     []specparser.SpecFunction{},
+		Tests:         []specparser.SpecTest{},
+		Dependencies:  []specparser.SpecDependency{},
+		Configuration: []specparser.SpecConfig{},
+		Endpoints:     []specparser.SpecEndpoint{},
+	}
+
+	for _, f := range imp.files {
+		for _, decl := range f.types {
+			spec.Types = append(spec.Types, imp.convert(decl, f, f.pkg)...)
+		}
+	}
+
+	for _, s := range root.services {
+		for _, r := range s.rpcs {
+			spec.Functions = append(spec.Functions, imp.function(s, r, root.pkg))
+		}
+	}
+
+	spec.CalculateTotals()
+	return spec
+}
+
+// declare registers a message or enum (and nested declarations) under its
+// fully qualified name. Nested types are named by joining the enclosing
+// names, e.g. User.Address becomes UserAddress.
+func (imp *importer) declare(decl any, scope, prefix string) {
+	switch d := decl.(type) {
+	case *message:
+		fullName := qualify(scope, d.name)
+		typeName := imp.reserve(prefix + pascal(d.name))
+		imp.symbols[fullName] = typeName
+		for _, o := range d.oneofs {
+			imp.unions[o] = imp.reserve(typeName + pascal(o.name))
+		}
+		for _, nested := range d.nested {
+			imp.declare(nested, fullName, typeName)
+		}
+	case *enum:
+		imp.symbols[qualify(scope, d.name)] = imp.reserve(prefix + pascal(d.name))
+	}
+}
+
+// convert turns a message or enum into spec types. A message is followed by
+// the unions for its oneofs and then its nested types.
+func (imp *importer) convert(decl any, f *file, scope string) []specparser.SpecType {
+	switch d := decl.(type) {
+	case *enum:
+		typ := specparser.SpecType{
+			Name:        imp.symbols[qualify(scope, d.name)],
+			Kind:        "enum",
+			Description: d.doc,
+			IsPublic:    true,
+		}
+		for _, v := range d.values {
+			typ.Values = append(typ.Values, specparser.SpecEnumValue{
+				Name:        v.name,
+				Value:       v.number,
+				Description: v.doc,
+			})
+		}
+		return []specparser.SpecType{typ}
+
+	case *message:
+		fullName := qualify(scope, d.name)
+		typ := specparser.SpecType{
+			Name:        imp.symbols[fullName],
+			Kind:        "struct",
+			Description: d.doc,
+			IsPublic:    true,
+		}
+
+		var unions []specparser.SpecType
+		for _, fld := range d.fields {
+			if fld.oneof == nil {
+				typ.Fields = append(typ.Fields, imp.field(fld, f.syntax, fullName))
+				continue
+			}
+			if fld != fld.oneof.fields[0] {
+				continue
+			}
+
+			// The first member of a oneof stands in for the whole group
+			union := specparser.SpecType{
+				Name:        imp.unions[fld.oneof],
+				Kind:        "union",
+				Description: fld.oneof.doc,
+				IsPublic:    true,
+			}
+			for _, member := range fld.oneof.fields {
+				variant := imp.field(member, f.syntax, fullName)
+				variant.Required = true
+				union.Fields = append(union.Fields, variant)
+			}
+			unions = append(unions, union)
+
+			typ.Fields = append(typ.Fields, specparser.SpecField{
+				Name:        fld.oneof.name,
+				Type:        union.Name,
+				Description: fld.oneof.doc,
+			})
+		}
+
+		types := append([]specparser.SpecType{typ}, unions...)
+		for _, nested := range d.nested {
+			types = append(types, imp.convert(nested, f, fullName)...)
+		}
+		return types
+	}
+	return nil
+}
+
+// field converts a message field. The field number is kept as the
+// "protobuf" tag and an explicit json_name as the "json" tag.
+func (imp *importer) field(fld *field, syntax, scope string) specparser.SpecField {
+	typ := imp.typeOf(fld.typ, scope)
+	switch {
+	case fld.keyType != "":
+		typ = fmt.Sprintf("Map[%s, %s]", imp.typeOf(fld.keyType, scope), typ)
+	case fld.label == "repeated":
+		typ = fmt.Sprintf("List[%s]", typ)
+	}
+
+	sf := specparser.SpecField{
+		Name:        fld.name,
+		Type:        typ,
+		Description: fld.doc,
+		Tags:        map[string]string{"protobuf": fld.number},
+		Default:     strings.Trim(fld.options["default"], `"`),
+	}
+	if jsonName := fld.options["json_name"]; jsonName != "" {
+		sf.Tags["json"] = jsonName
+	}
+
+	// Repeated and map fields are never absent. Singular proto3 fields have
+	// implicit presence unless marked optional; proto2 fields need "required".
+	switch {
+	case fld.label == "repeated" || fld.keyType != "":
+		sf.Required = true
+	case syntax == "proto3":
+		sf.Required = fld.label != "optional"
+	default:
+		sf.Required = fld.label == "required"
+	}
+	return sf
+}
+
+// function converts an RPC into a function whose receiver is the service.
+func (imp *importer) function(s *service, r rpc, scope string) specparser.SpecFunction {
+	fn := specparser.SpecFunction{
+		Name:            r.name,
+		Receiver:        s.name,
+		Description:     r.doc,
+		IsPublic:        true,
+		ClientStreaming: r.clientStreaming,
+		ServerStreaming: r.serverStreaming,
+	}
+
+	if request := imp.typeOf(r.request, scope); request != "" {
+		desc := "Request message"
+		if r.clientStreaming {
+			desc = "Stream of request messages"
+		}
+		fn.Parameters = []specparser.SpecParameter{{Name: "request", Type: request, Description: desc, Required: true}}
+	}
+	if response := imp.typeOf(r.response, scope); response != "" {
+		desc := "Response message"
+		if r.serverStreaming {
+			desc = "Stream of response messages"
+		}
+		fn.Returns = []specparser.SpecReturn{{Type: response, Description: desc}}
+	}
+	return fn
+}
+
+// typeOf maps a proto type reference to a pseudo-type, resolving message
+// and enum names using proto scoping rules: the innermost enclosing scope
+// wins, and a leading dot makes the name fully qualified.
+func (imp *importer) typeOf(ref, scope string) string {
+	if t, ok := scalarTypes[ref]; ok {
+		return t
+	}
+
+	name := strings.TrimPrefix(ref, ".")
+	if t, ok := wellKnownTypes[name]; ok {
+		return t
+	}
+	if strings.HasPrefix(ref, ".") {
+		if t, ok := imp.symbols[name]; ok {
+			return t
+		}
+	} else {
+		for s := scope; ; {
+			if t, ok := imp.symbols[qualify(s, name)]; ok {
+				return t
+			}
+			if s == "" {
+				break
+			}
+			if i := strings.LastIndex(s, "."); i >= 0 {
+				s = s[:i]
+			} else {
+				s = ""
+			}
+		}
+	}
+
+	// Unresolved (e.g. from an import that was not found)
+	return pascal(name[strings.LastIndex(name, ".")+1:])
+}
+
+// reserve returns a unique type name derived from base.
+func (imp *importer) reserve(base string) string {
+	name := base
+	for i := 2; imp.taken[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	imp.taken[name] = true
+	return name
+}
+
+// qualify joins a scope and a name with a dot.
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// pascal converts a proto identifier (snake_case or PascalCase) to PascalCase.
+func pascal(s string) string {
+	var sb strings.Builder
+	upperNext := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			sb.WriteRune(unicode.ToUpper(r))
+			upperNext = false
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,204 @@
+package protobuf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+const usersProto = `// User management API.
+syntax = "proto3";
+
+package acme.users.v1;
+
+import "google/protobuf/timestamp.proto";
+import "common/money.proto";
+
+option go_package = "example.com/users;users";
+
+// A registered user.
+message User {
+  string id = 1;
+  string display_name = 2 [json_name = "name"];
+  optional string email = 3; // Contact address
+  repeated string roles = 4;
+  map<string, int64> quotas = 5;
+  google.protobuf.Timestamp created_at = 6;
+  Status status = 7;
+  Address address = 8;
+  acme.common.Money balance = 9;
+
+  // How the user signed up.
+  oneof origin {
+    string invite_code = 10;
+    Referral referral = 11;
+  }
+
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_ACTIVE = 1;
+  }
+
+  message Address {
+    string city = 1;
+  }
+
+  reserved 20 to 25;
+}
+
+message Referral {
+  string referrer_id = 1;
+}
+
+message GetUserRequest { string id = 1; }
+
+service UserService {
+  // Fetches a single user.
+  rpc GetUser(GetUserRequest) returns (User);
+  rpc WatchUsers(GetUserRequest) returns (stream User) {
+    option deprecated = true;
+  }
+  rpc Chat(stream Referral) returns (stream Referral);
+}
+`
+
+const moneyProto = `syntax = "proto3";
+package acme.common;
+
+message Money {
+  string currency = 1;
+  int64 units = 2;
+}
+`
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "common"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "common", "money.proto"), []byte(moneyProto), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "users.proto")
+	if err := os.WriteFile(path, []byte(usersProto), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+
+	if spec.Name != "acme.users.v1" || spec.Overview != "User management API." {
+		t.Errorf("Unexpected name/overview: %q / %q", spec.Name, spec.Overview)
+	}
+
+	var names []string
+	types := make(map[string]specparser.SpecType)
+	for _, typ := range spec.Types {
+		names = append(names, typ.Name)
+		types[typ.Name] = typ
+	}
+	expectedNames := "Money,User,UserOrigin,UserStatus,UserAddress,Referral,GetUserRequest"
+	if got := strings.Join(names, ","); got != expectedNames {
+		t.Errorf("Types = %s, expected %s", got, expectedNames)
+	}
+
+	user := types["User"]
+	if user.Description != "A registered user." {
+		t.Errorf("Unexpected description: %q", user.Description)
+	}
+	var fields []string
+	for _, f := range user.Fields {
+		fields = append(fields, f.Name+":"+f.Type)
+	}
+	expectedFields := "id:string,display_name:string,email:string,roles:List[string],quotas:Map[string, int64]," +
+		"created_at:datetime,status:UserStatus,address:UserAddress,balance:Money,origin:UserOrigin"
+	if got := strings.Join(fields, ","); got != expectedFields {
+		t.Errorf("User fields = %s, expected %s", got, expectedFields)
+	}
+
+	tests := []struct {
+		field    int
+		required bool
+		tags     string
+	}{
+		{0, true, "1"},
+		{1, true, "2"},
+		{2, false, "3"},
+		{3, true, "4"},
+		{9, false, ""},
+	}
+	for _, tt := range tests {
+		f := user.Fields[tt.field]
+		if f.Required != tt.required || f.Tags["protobuf"] != tt.tags {
+			t.Errorf("Field %s: required=%v tag=%q, expected %v %q", f.Name, f.Required, f.Tags["protobuf"], tt.required, tt.tags)
+		}
+	}
+	if user.Fields[1].Tags["json"] != "name" {
+		t.Errorf("Expected json_name tag, got %v", user.Fields[1].Tags)
+	}
+	if user.Fields[2].Description != "Contact address" {
+		t.Errorf("Expected trailing comment as description, got %q", user.Fields[2].Description)
+	}
+
+	origin := types["UserOrigin"]
+	if origin.Kind != "union" || len(origin.Fields) != 2 || origin.Fields[1].Type != "Referral" || origin.Fields[1].Tags["protobuf"] != "11" {
+		t.Errorf("Unexpected oneof union: %+v", origin)
+	}
+	if status := types["UserStatus"]; status.Kind != "enum" || len(status.Values) != 2 || status.Values[1].Value != "1" {
+		t.Errorf("Unexpected enum: %+v", status)
+	}
+
+	if len(spec.Functions) != 3 {
+		t.Fatalf("Expected 3 functions, got %d", len(spec.Functions))
+	}
+	get := spec.Functions[0]
+	if get.Name != "GetUser" || get.Receiver != "UserService" || get.Description != "Fetches a single user." ||
+		get.Parameters[0].Type != "GetUserRequest" || get.Returns[0].Type != "User" || get.ClientStreaming || get.ServerStreaming {
+		t.Errorf("Unexpected GetUser: %+v", get)
+	}
+	if watch := spec.Functions[1]; watch.ClientStreaming || !watch.ServerStreaming {
+		t.Errorf("Expected server streaming WatchUsers, got %+v", watch)
+	}
+	if chat := spec.Functions[2]; !chat.ClientStreaming || !chat.ServerStreaming {
+		t.Errorf("Expected bidirectional Chat, got %+v", chat)
+	}
+
+	// The rendered spec parses back with types and streaming intact
+	parsed, err := specparser.NewParser().Parse(specparser.Render(spec), "users")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(parsed.Types) != len(spec.Types) {
+		t.Errorf("Expected %d types after round trip, got %d", len(spec.Types), len(parsed.Types))
+	}
+	for _, fn := range parsed.Functions {
+		if fn.Name == "Chat" && (!fn.ClientStreaming || !fn.ServerStreaming || fn.Receiver != "UserService") {
+			t.Errorf("Expected streaming to survive round trip, got %+v", fn)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"unsupported syntax", `syntax = "proto4";`},
+		{"missing field number", `syntax = "proto3"; message A { string id; }`},
+		{"unterminated message", `syntax = "proto3"; message A { string id = 1;`},
+		{"unterminated comment", `/* never closed`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.src), t.TempDir()); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package protobuf imports Protocol Buffers (proto3) schemas into spec
+// analyses: messages become struct types, enums become enum types, oneofs
+// become unions, and service RPCs become functions.
+package protobuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind classifies a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+// token is a single lexical token with the comment block that precedes it
+// and any comment trailing it on the same line.
+type token struct {
+	kind     tokenKind
+	text     string
+	line     int
+	doc      string
+	trailing string
+}
+
+// lex splits proto source into tokens. Comments are not emitted as tokens;
+// a comment block directly preceding a token is attached as its doc.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	var doc []string
+	line := 1
+	docLine := 0
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+			// A blank line detaches a comment from the next declaration.
+			if docLine > 0 && line-docLine > 1 {
+				doc, docLine = nil, 0
+			}
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			text := strings.TrimSpace(strings.TrimLeft(src[i+2:i+end], "/"))
+			if n := len(tokens); n > 0 && tokens[n-1].line == line {
+				tokens[n-1].trailing = text
+			} else {
+				doc = append(doc, text)
+				docLine = line
+			}
+			i += end
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			body := src[i+2 : i+2+end]
+			for _, l := range strings.Split(body, "\n") {
+				l = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), "*"))
+				if l != "" {
+					doc = append(doc, l)
+				}
+			}
+			line += strings.Count(body, "\n")
+			docLine = line
+			i += end + 4
+
+		case c == '"' || c == '\'':
+			j := i + 1
+			var sb strings.Builder
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				sb.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), line: line, doc: joinDoc(doc)})
+			doc, docLine = nil, 0
+			i = j + 1
+
+		case isIdentStart(c):
+			j := i
+			for j < len(src) && (isIdentStart(src[j]) || isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[i:j], line: line, doc: joinDoc(doc)})
+			doc, docLine = nil, 0
+			i = j
+
+		case isDigit(c) || ((c == '-' || c == '+') && i+1 < len(src) && isDigit(src[i+1])):
+			j := i + 1
+			for j < len(src) && (isIdentStart(src[j]) || isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[i:j], line: line, doc: joinDoc(doc)})
+			doc, docLine = nil, 0
+			i = j
+
+		case c == '.' && i+1 < len(src) && isIdentStart(src[i+1]):
+			// Fully qualified type reference (".pkg.Message").
+			j := i + 1
+			for j < len(src) && (isIdentStart(src[j]) || isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[i:j], line: line, doc: joinDoc(doc)})
+			doc, docLine = nil, 0
+			i = j
+
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), line: line, doc: joinDoc(doc)})
+			doc, docLine = nil, 0
+			i++
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, line: line})
+	return tokens, nil
+}
+
+func joinDoc(lines []string) string {
+	return strings.TrimSpace(strings.Join(lines, " "))
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
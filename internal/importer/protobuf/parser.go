@@ -0,0 +1,585 @@
+package protobuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// file is a parsed .proto file.
+type file struct {
+	syntax   string
+	pkg      string
+	doc      string
+	imports  []string
+	types    []any // *message or *enum, in declaration order
+	services []*service
+}
+
+// message is a message declaration, possibly with nested declarations.
+type message struct {
+	name   string
+	doc    string
+	fields []*field
+	oneofs []*oneof
+	nested []any // *message or *enum, in declaration order
+}
+
+// field is a message field. For map fields keyType is set and typ holds
+// the value type.
+type field struct {
+	name    string
+	typ     string
+	keyType string
+	number  string
+	label   string // "", "optional", "repeated" or "required"
+	doc     string
+	options map[string]string
+	oneof   *oneof
+}
+
+// oneof groups message fields of which at most one is set.
+type oneof struct {
+	name   string
+	doc    string
+	fields []*field
+}
+
+// enum is an enum declaration.
+type enum struct {
+	name   string
+	doc    string
+	values []enumValue
+}
+
+type enumValue struct {
+	name   string
+	number string
+	doc    string
+}
+
+// service is a service declaration.
+type service struct {
+	name string
+	doc  string
+	rpcs []rpc
+}
+
+type rpc struct {
+	name            string
+	doc             string
+	request         string
+	response        string
+	clientStreaming bool
+	serverStreaming bool
+}
+
+// parser is a recursive-descent parser over lexed tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses proto source into a file.
+func parse(src string) (*file, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	f := &file{syntax: "proto2"}
+
+	for p.peek().kind != tokenEOF {
+		tok := p.peek()
+		switch tok.text {
+		case "syntax", "edition":
+			p.next()
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			value := p.next()
+			if value.kind != tokenString {
+				return nil, p.errorf(value, "expected string after %s", tok.text)
+			}
+			if tok.text == "edition" || (value.text != "proto2" && value.text != "proto3") {
+				return nil, p.errorf(value, "unsupported syntax %q", value.text)
+			}
+			f.syntax = value.text
+			f.doc = tok.doc
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "package":
+			p.next()
+			f.pkg = strings.TrimPrefix(p.next().text, ".")
+			if f.doc == "" {
+				f.doc = tok.doc
+			}
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "import":
+			p.next()
+			if next := p.peek().text; next == "public" || next == "weak" {
+				p.next()
+			}
+			path := p.next()
+			if path.kind != tokenString {
+				return nil, p.errorf(path, "expected import path")
+			}
+			f.imports = append(f.imports, path.text)
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "option":
+			if _, _, err := p.option(); err != nil {
+				return nil, err
+			}
+		case "message":
+			m, err := p.message()
+			if err != nil {
+				return nil, err
+			}
+			f.types = append(f.types, m)
+		case "enum":
+			e, err := p.enum()
+			if err != nil {
+				return nil, err
+			}
+			f.types = append(f.types, e)
+		case "service":
+			s, err := p.service()
+			if err != nil {
+				return nil, err
+			}
+			f.services = append(f.services, s)
+		case "extend":
+			p.next()
+			p.next()
+			if err := p.skipBlock(); err != nil {
+				return nil, err
+			}
+		case ";":
+			p.next()
+		default:
+			return nil, p.errorf(tok, "unexpected %q", tok.text)
+		}
+	}
+
+	return f, nil
+}
+
+// message parses "message Name { ... }".
+func (p *parser) message() (*message, error) {
+	doc := p.next().doc
+	name := p.next()
+	if name.kind != tokenIdent {
+		return nil, p.errorf(name, "expected message name")
+	}
+	m := &message{name: name.text, doc: doc}
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for p.peek().text != "}" {
+		tok := p.peek()
+		if tok.kind == tokenEOF {
+			return nil, p.errorf(tok, "unterminated message %s", m.name)
+		}
+		switch tok.text {
+		case "message":
+			nested, err := p.message()
+			if err != nil {
+				return nil, err
+			}
+			m.nested = append(m.nested, nested)
+		case "enum":
+			nested, err := p.enum()
+			if err != nil {
+				return nil, err
+			}
+			m.nested = append(m.nested, nested)
+		case "oneof":
+			o, err := p.oneof()
+			if err != nil {
+				return nil, err
+			}
+			m.oneofs = append(m.oneofs, o)
+			m.fields = append(m.fields, o.fields...)
+		case "option":
+			if _, _, err := p.option(); err != nil {
+				return nil, err
+			}
+		case "reserved", "extensions":
+			p.skipStatement()
+		case "extend":
+			p.next()
+			p.next()
+			if err := p.skipBlock(); err != nil {
+				return nil, err
+			}
+		case ";":
+			p.next()
+		default:
+			f, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+			m.fields = append(m.fields, f)
+		}
+	}
+	p.next()
+	return m, nil
+}
+
+// field parses a normal or map field declaration.
+func (p *parser) field() (*field, error) {
+	f := &field{doc: p.peek().doc}
+
+	if label := p.peek().text; label == "optional" || label == "repeated" || label == "required" {
+		f.label = label
+		p.next()
+	}
+
+	typeTok := p.next()
+	if typeTok.kind != tokenIdent {
+		return nil, p.errorf(typeTok, "expected field type, got %q", typeTok.text)
+	}
+	f.typ = typeTok.text
+	if f.typ == "group" {
+		return nil, p.errorf(typeTok, "groups are not supported")
+	}
+	if f.typ == "map" && p.peek().text == "<" {
+		p.next()
+		f.keyType = p.next().text
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		f.typ = p.next().text
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+	}
+
+	name := p.next()
+	if name.kind != tokenIdent {
+		return nil, p.errorf(name, "expected field name")
+	}
+	f.name = name.text
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	number := p.next()
+	if number.kind != tokenNumber {
+		return nil, p.errorf(number, "expected field number for %s", f.name)
+	}
+	f.number = number.text
+
+	options, err := p.fieldOptions()
+	if err != nil {
+		return nil, err
+	}
+	f.options = options
+
+	end := p.peek()
+	if err := p.expect(";"); err != nil {
+		return nil, err
+	}
+	if f.doc == "" {
+		f.doc = end.trailing
+	}
+	return f, nil
+}
+
+// oneof parses "oneof name { fields }".
+func (p *parser) oneof() (*oneof, error) {
+	doc := p.next().doc
+	o := &oneof{name: p.next().text, doc: doc}
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for p.peek().text != "}" {
+		switch tok := p.peek(); tok.text {
+		case "option":
+			if _, _, err := p.option(); err != nil {
+				return nil, err
+			}
+		case ";":
+			p.next()
+		default:
+			if tok.kind == tokenEOF {
+				return nil, p.errorf(tok, "unterminated oneof %s", o.name)
+			}
+			f, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+			f.oneof = o
+			o.fields = append(o.fields, f)
+		}
+	}
+	p.next()
+	return o, nil
+}
+
+// enum parses "enum Name { VALUE = 0; ... }".
+func (p *parser) enum() (*enum, error) {
+	doc := p.next().doc
+	e := &enum{name: p.next().text, doc: doc}
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for p.peek().text != "}" {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, p.errorf(tok, "unterminated enum %s", e.name)
+		case tok.text == "option":
+			if _, _, err := p.option(); err != nil {
+				return nil, err
+			}
+		case tok.text == "reserved":
+			p.skipStatement()
+		case tok.text == ";":
+			p.next()
+		default:
+			p.next()
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			value := enumValue{name: tok.text, number: p.next().text, doc: tok.doc}
+			if _, err := p.fieldOptions(); err != nil {
+				return nil, err
+			}
+			end := p.peek()
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+			if value.doc == "" {
+				value.doc = end.trailing
+			}
+			e.values = append(e.values, value)
+		}
+	}
+	p.next()
+	return e, nil
+}
+
+// service parses "service Name { rpc ... }".
+func (p *parser) service() (*service, error) {
+	doc := p.next().doc
+	s := &service{name: p.next().text, doc: doc}
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for p.peek().text != "}" {
+		tok := p.peek()
+		switch tok.text {
+		case "rpc":
+			r, err := p.rpc()
+			if err != nil {
+				return nil, err
+			}
+			s.rpcs = append(s.rpcs, r)
+		case "option":
+			if _, _, err := p.option(); err != nil {
+				return nil, err
+			}
+		case ";":
+			p.next()
+		default:
+			if tok.kind == tokenEOF {
+				return nil, p.errorf(tok, "unterminated service %s", s.name)
+			}
+			return nil, p.errorf(tok, "unexpected %q in service %s", tok.text, s.name)
+		}
+	}
+	p.next()
+	return s, nil
+}
+
+// rpc parses "rpc Name (stream Req) returns (stream Resp);" or with a body.
+func (p *parser) rpc() (rpc, error) {
+	doc := p.next().doc
+	r := rpc{name: p.next().text, doc: doc}
+
+	var err error
+	if r.request, r.clientStreaming, err = p.rpcType(); err != nil {
+		return r, err
+	}
+	if tok := p.next(); tok.text != "returns" {
+		return r, p.errorf(tok, "expected returns in rpc %s", r.name)
+	}
+	if r.response, r.serverStreaming, err = p.rpcType(); err != nil {
+		return r, err
+	}
+
+	if p.peek().text == "{" {
+		return r, p.skipBlock()
+	}
+	end := p.peek()
+	if err := p.expect(";"); err != nil {
+		return r, err
+	}
+	if r.doc == "" {
+		r.doc = end.trailing
+	}
+	return r, nil
+}
+
+// rpcType parses "(stream Type)".
+func (p *parser) rpcType() (string, bool, error) {
+	if err := p.expect("("); err != nil {
+		return "", false, err
+	}
+	streaming := false
+	if p.peek().text == "stream" && p.tokens[p.pos+1].text != ")" {
+		p.next()
+		streaming = true
+	}
+	typ := p.next()
+	if typ.kind != tokenIdent {
+		return "", false, p.errorf(typ, "expected message type")
+	}
+	if err := p.expect(")"); err != nil {
+		return "", false, err
+	}
+	return typ.text, streaming, nil
+}
+
+// option parses "option name = value;" and returns the name and value.
+func (p *parser) option() (string, string, error) {
+	p.next()
+	name, err := p.optionName()
+	if err != nil {
+		return "", "", err
+	}
+	if err := p.expect("="); err != nil {
+		return "", "", err
+	}
+	value, err := p.optionValue()
+	if err != nil {
+		return "", "", err
+	}
+	return name, value, p.expect(";")
+}
+
+// fieldOptions parses an optional "[name = value, ...]" list.
+func (p *parser) fieldOptions() (map[string]string, error) {
+	if p.peek().text != "[" {
+		return nil, nil
+	}
+	p.next()
+
+	options := make(map[string]string)
+	for {
+		name, err := p.optionName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.optionValue()
+		if err != nil {
+			return nil, err
+		}
+		options[name] = value
+
+		tok := p.next()
+		if tok.text == "]" {
+			return options, nil
+		}
+		if tok.text != "," {
+			return nil, p.errorf(tok, "expected , or ] in field options")
+		}
+	}
+}
+
+// optionName parses a simple or parenthesized (custom) option name.
+func (p *parser) optionName() (string, error) {
+	var sb strings.Builder
+	for {
+		tok := p.next()
+		switch {
+		case tok.text == "(":
+			inner := p.next()
+			if err := p.expect(")"); err != nil {
+				return "", err
+			}
+			sb.WriteString("(" + inner.text + ")")
+		case tok.kind == tokenIdent:
+			sb.WriteString(tok.text)
+		default:
+			return "", p.errorf(tok, "expected option name")
+		}
+		if next := p.peek(); next.kind != tokenIdent || !strings.HasPrefix(next.text, ".") {
+			return sb.String(), nil
+		}
+	}
+}
+
+// optionValue parses a constant or skips an aggregate "{ ... }" value.
+func (p *parser) optionValue() (string, error) {
+	if p.peek().text == "{" {
+		return "", p.skipBlock()
+	}
+	tok := p.next()
+	if tok.kind == tokenEOF || tok.kind == tokenSymbol {
+		return "", p.errorf(tok, "expected option value")
+	}
+	return tok.text, nil
+}
+
+// skipBlock skips a balanced "{ ... }" block.
+func (p *parser) skipBlock() error {
+	start := p.peek()
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for depth := 1; depth > 0; {
+		tok := p.next()
+		switch tok.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+		if tok.kind == tokenEOF {
+			return p.errorf(start, "unterminated block")
+		}
+	}
+	return nil
+}
+
+// skipStatement skips tokens through the next ";".
+func (p *parser) skipStatement() {
+	for tok := p.next(); tok.text != ";" && tok.kind != tokenEOF; tok = p.next() {
+	}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(text string) error {
+	if tok := p.next(); tok.text != text {
+		return p.errorf(tok, "expected %q, got %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", tok.line, fmt.Sprintf(format, args...))
+}
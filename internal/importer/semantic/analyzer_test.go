@@ -114,6 +114,7 @@ func TestDefaultRegistry(t *testing.T) {
 		treesitter.LanguageJava,
 		treesitter.LanguageRust,
 		treesitter.LanguageCSharp,
+		treesitter.LanguageProtobuf,
 	}
 
 	for _, lang := range languages {
@@ -355,3 +356,53 @@ func (s *Service) Run() error {
 		t.Error("fmt dependency not found")
 	}
 }
+
+func TestProtobufAnalyzerFile(t *testing.T) {
+	code := []byte(`syntax = "proto3";
+package shop;
+
+message Order {
+  string id = 1;
+  repeated string items = 2;
+  map<string, int32> counts = 3;
+  optional string note = 4;
+}
+
+service Orders {
+  rpc Watch(Order) returns (stream Order);
+}
+`)
+
+	analysis, err := NewProtobufAnalyzer().AnalyzeFile("orders.proto", code)
+	if err != nil {
+		t.Fatalf("AnalyzeFile failed: %v", err)
+	}
+
+	if analysis.Package != "shop" || len(analysis.Types) != 1 {
+		t.Fatalf("unexpected analysis: package %q, %d types", analysis.Package, len(analysis.Types))
+	}
+
+	fields := analysis.Types[0].ResolvedFields
+	if len(fields) != 4 {
+		t.Fatalf("expected 4 fields, got %d", len(fields))
+	}
+	if fields[0].Tags != `protobuf:"1"` {
+		t.Errorf("expected field number tag, got %q", fields[0].Tags)
+	}
+	if !fields[1].IsSlice || fields[1].ResolvedType != "string" {
+		t.Errorf("items should be a string slice, got %+v", fields[1])
+	}
+	if !fields[2].IsMap || fields[2].KeyType != "string" || fields[2].ElementType != "int" {
+		t.Errorf("counts should be a map, got %+v", fields[2])
+	}
+	if !fields[3].IsOptional {
+		t.Error("note should be optional")
+	}
+
+	if len(analysis.Functions) != 1 {
+		t.Fatalf("expected 1 function, got %d", len(analysis.Functions))
+	}
+	if sig := analysis.Functions[0].Signature; sig != "rpc Watch(Order) returns (stream Order)" {
+		t.Errorf("unexpected signature: %s", sig)
+	}
+}
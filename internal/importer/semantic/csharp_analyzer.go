@@ -106,6 +106,9 @@ func (a *CSharpAnalyzer) Analyze(dir string) (*Analysis, error) {
 	a.buildTypeGraph(analysis)
 	a.extractDependencies(dir, analysis)
 
+	// Record the module each element is declared in
+	assignModules(analysis, dir)
+
 	return analysis, nil
 }
 
@@ -116,6 +116,9 @@ func (a *GoAnalyzer) Analyze(dir string) (*Analysis, error) {
 	// Extract dependencies
 	a.extractDependencies(analysis)
 
+	// Record the module each element is declared in
+	assignModules(analysis, dir)
+
 	return analysis, nil
 }
 
@@ -0,0 +1,35 @@
+package semantic
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/layout"
+)
+
+// assignModules sets the PackagePath of each type and function that has
+// none to the module of the file declaring it, relative to the analyzed
+// directory, so parity can tell elements generated in the wrong module.
+func assignModules(analysis *Analysis, dir string) {
+	lang := string(analysis.Language)
+	for i := range analysis.Types {
+		if analysis.Types[i].PackagePath == "" {
+			analysis.Types[i].PackagePath = layout.ModuleOf(lang, relativeFile(dir, analysis.Types[i].Location.File))
+		}
+	}
+	for i := range analysis.Functions {
+		if analysis.Functions[i].PackagePath == "" {
+			analysis.Functions[i].PackagePath = layout.ModuleOf(lang, relativeFile(dir, analysis.Functions[i].Location.File))
+		}
+	}
+}
+
+// relativeFile returns file relative to dir. Files already relative to dir
+// are returned as they are.
+func relativeFile(dir, file string) string {
+	rel, err := filepath.Rel(dir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,215 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/importer/protobuf"
+	"github.com/kon1790/rpg/internal/importer/treesitter"
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// ProtobufAnalyzer provides semantic analysis for Protocol Buffers schemas,
+// so .proto files can be the source side of a parity comparison
+type ProtobufAnalyzer struct{}
+
+// NewProtobufAnalyzer creates a new Protocol Buffers analyzer
+func NewProtobufAnalyzer() *ProtobufAnalyzer {
+	return &ProtobufAnalyzer{}
+}
+
+// Language returns the language this analyzer handles
+func (a *ProtobufAnalyzer) Language() treesitter.Language {
+	return treesitter.LanguageProtobuf
+}
+
+// IsAvailable always reports true; the proto parser is built in
+func (a *ProtobufAnalyzer) IsAvailable() bool {
+	return true
+}
+
+// Analyze performs semantic analysis on a directory of .proto files
+func (a *ProtobufAnalyzer) Analyze(dir string) (*Analysis, error) {
+	analysis := &Analysis{
+		Language:  treesitter.LanguageProtobuf,
+		Name:      filepath.Base(dir),
+		CallGraph: make(map[string][]string),
+		TypeGraph: make(map[string][]string),
+	}
+
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && path != dir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".proto") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking directory: %w", err)
+	}
+
+	// Each file's analysis includes the types it imports; keep the first
+	// occurrence so shared messages are counted once
+	seen := make(map[string]bool)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			analysis.Errors = append(analysis.Errors, AnalysisError{
+				File:     file,
+				Message:  err.Error(),
+				Severity: SeverityWarning,
+			})
+			continue
+		}
+
+		fileAnalysis, err := a.AnalyzeFile(file, content)
+		if err != nil {
+			analysis.Errors = append(analysis.Errors, AnalysisError{
+				File:     file,
+				Message:  err.Error(),
+				Severity: SeverityError,
+			})
+			continue
+		}
+
+		analysis.Files = append(analysis.Files, fileAnalysis)
+		for _, t := range fileAnalysis.Types {
+			if !seen[t.Name] {
+				seen[t.Name] = true
+				analysis.Types = append(analysis.Types, t)
+			}
+		}
+		analysis.Functions = append(analysis.Functions, fileAnalysis.Functions...)
+	}
+
+	return analysis, nil
+}
+
+// AnalyzeFile performs semantic analysis on a single .proto file
+func (a *ProtobufAnalyzer) AnalyzeFile(path string, content []byte) (*FileAnalysis, error) {
+	spec, err := protobuf.Parse(content, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	fileAnalysis := &FileAnalysis{
+		Path:    path,
+		Package: spec.Name,
+	}
+
+	for _, t := range spec.Types {
+		rt := ResolvedType{
+			TypeDef: treesitter.TypeDef{
+				Name:       t.Name,
+				Kind:       treesitter.TypeKind(t.Kind),
+				DocComment: t.Description,
+				IsPublic:   true,
+				Location:   treesitter.SourceLocation{File: path},
+			},
+			PackagePath: spec.Name,
+		}
+		for _, v := range t.Values {
+			rt.Variants = append(rt.Variants, v.Name)
+		}
+		for _, f := range t.Fields {
+			field := treesitter.Field{
+				Name:       f.Name,
+				Type:       f.Type,
+				IsOptional: !f.Required,
+				DocComment: f.Description,
+			}
+			if number := f.Tags["protobuf"]; number != "" {
+				field.Tags = fmt.Sprintf("protobuf:\"%s\"", number)
+			}
+			rt.Fields = append(rt.Fields, field)
+			rt.ResolvedFields = append(rt.ResolvedFields, resolvePseudoField(field))
+		}
+		fileAnalysis.Types = append(fileAnalysis.Types, rt)
+	}
+
+	for _, fn := range spec.Functions {
+		fileAnalysis.Functions = append(fileAnalysis.Functions, resolveSpecFunction(fn, path))
+	}
+
+	return fileAnalysis, nil
+}
+
+// resolvePseudoField maps a pseudo-typed field (List[T], Map[K, V],
+// Optional[T]) onto the slice/map/pointer flags the normalizer compares
+func resolvePseudoField(f treesitter.Field) ResolvedField {
+	rf := ResolvedField{Field: f, ResolvedType: f.Type, Tags: f.Tags}
+
+	typ := f.Type
+	if inner, ok := pseudoGeneric(typ, "Optional"); ok {
+		rf.IsPointer = true
+		typ = inner
+	}
+	switch {
+	case strings.HasPrefix(typ, "List["):
+		inner, _ := pseudoGeneric(typ, "List")
+		rf.IsSlice = true
+		rf.ElementType = inner
+		rf.ResolvedType = inner
+	case strings.HasPrefix(typ, "Map["):
+		inner, _ := pseudoGeneric(typ, "Map")
+		key, value, _ := strings.Cut(inner, ",")
+		rf.IsMap = true
+		rf.KeyType = strings.TrimSpace(key)
+		rf.ElementType = strings.TrimSpace(value)
+		rf.ResolvedType = fmt.Sprintf("map[%s]%s", rf.KeyType, rf.ElementType)
+	default:
+		rf.ResolvedType = typ
+	}
+	return rf
+}
+
+// pseudoGeneric unwraps "Name[inner]"
+func pseudoGeneric(typ, name string) (string, bool) {
+	if !strings.HasPrefix(typ, name+"[") || !strings.HasSuffix(typ, "]") {
+		return typ, false
+	}
+	return strings.TrimSpace(typ[len(name)+1 : len(typ)-1]), true
+}
+
+// resolveSpecFunction converts an RPC into a resolved function
+func resolveSpecFunction(fn specparser.SpecFunction, path string) ResolvedFunction {
+	rf := ResolvedFunction{
+		FunctionDef: treesitter.FunctionDef{
+			Name:       fn.Name,
+			IsPublic:   true,
+			DocComment: fn.Description,
+			Location:   treesitter.SourceLocation{File: path},
+		},
+	}
+
+	var params []string
+	for _, p := range fn.Parameters {
+		param := treesitter.Parameter{Name: p.Name, Type: p.Type, IsVariadic: fn.ClientStreaming}
+		rf.Parameters = append(rf.Parameters, param)
+		rf.ResolvedParameters = append(rf.ResolvedParameters, ResolvedParameter{Parameter: param, ResolvedType: p.Type})
+		params = append(params, p.Type)
+	}
+	for _, r := range fn.Returns {
+		rf.ResolvedReturnTypes = append(rf.ResolvedReturnTypes, r.Type)
+		rf.ReturnType = r.Type
+	}
+
+	request, response := strings.Join(params, ""), rf.ReturnType
+	if fn.ClientStreaming {
+		request = "stream " + request
+	}
+	if fn.ServerStreaming {
+		response = "stream " + response
+	}
+	rf.Signature = fmt.Sprintf("rpc %s(%s) returns (%s)", fn.Name, request, response)
+
+	return rf
+}
@@ -108,6 +108,9 @@ func (a *PythonAnalyzer) Analyze(dir string) (*Analysis, error) {
 	a.buildTypeGraph(analysis)
 	a.extractDependencies(analysis)
 
+	// Record the module each element is declared in
+	assignModules(analysis, dir)
+
 	return analysis, nil
 }
 
@@ -219,9 +222,9 @@ func (a *PythonAnalyzer) enrichWithPythonAST(dir string, analysis *Analysis) {
 
 		var astResult struct {
 			Functions []struct {
-				Name       string `json:"name"`
-				Line       int    `json:"line"`
-				IsAsync    bool   `json:"is_async"`
+				Name       string   `json:"name"`
+				Line       int      `json:"line"`
+				IsAsync    bool     `json:"is_async"`
 				Decorators []string `json:"decorators"`
 				Args       []struct {
 					Name       string `json:"name"`
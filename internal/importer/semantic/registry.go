@@ -11,6 +11,7 @@ func DefaultRegistry() *AnalyzerRegistry {
 	registry.Register(NewJavaAnalyzer())
 	registry.Register(NewRustAnalyzer())
 	registry.Register(NewCSharpAnalyzer())
+	registry.Register(NewProtobufAnalyzer())
 
 	return registry
 }
@@ -118,6 +118,9 @@ func (a *RustAnalyzer) Analyze(dir string) (*Analysis, error) {
 	a.buildTypeGraph(analysis)
 	a.extractDependencies(dir, analysis)
 
+	// Record the module each element is declared in
+	assignModules(analysis, dir)
+
 	return analysis, nil
 }
 
@@ -157,15 +160,15 @@ func (a *RustAnalyzer) enrichWithCargo(dir string, analysis *Analysis) {
 type CargoMessage struct {
 	Reason  string `json:"reason"`
 	Message *struct {
-		Code    *struct {
+		Code *struct {
 			Code string `json:"code"`
 		} `json:"code"`
 		Level   string `json:"level"`
 		Message string `json:"message"`
 		Spans   []struct {
-			FileName string `json:"file_name"`
-			LineStart int `json:"line_start"`
-			LineEnd   int `json:"line_end"`
+			FileName  string `json:"file_name"`
+			LineStart int    `json:"line_start"`
+			LineEnd   int    `json:"line_end"`
 		} `json:"spans"`
 	} `json:"message"`
 }
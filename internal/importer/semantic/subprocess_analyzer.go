@@ -13,12 +13,12 @@ import (
 
 // SubprocessAnalyzer provides semantic analysis via external tools
 type SubprocessAnalyzer struct {
-	lang       treesitter.Language
-	command    string
-	args       []string
-	timeout    time.Duration
-	tsParser   *treesitter.Parser
-	available  *bool // Cached availability check
+	lang      treesitter.Language
+	command   string
+	args      []string
+	timeout   time.Duration
+	tsParser  *treesitter.Parser
+	available *bool // Cached availability check
 }
 
 // SubprocessConfig configures a subprocess analyzer
@@ -168,6 +168,22 @@ func convertParseResult(pr *treesitter.ParseResult) *FileAnalysis {
 				ResolvedType: f.Type,
 			})
 		}
+		for _, m := range typ.MethodDefs {
+			sig := MethodSignature{
+				Name:       m.Name,
+				IsExported: m.IsPublic,
+			}
+			for _, p := range m.Parameters {
+				sig.Parameters = append(sig.Parameters, ResolvedParameter{
+					Parameter:    p,
+					ResolvedType: p.Type,
+				})
+			}
+			if m.ReturnType != "" {
+				sig.ReturnTypes = []string{m.ReturnType}
+			}
+			rt.MethodSignatures = append(rt.MethodSignatures, sig)
+		}
 		analysis.Types = append(analysis.Types, rt)
 	}
 
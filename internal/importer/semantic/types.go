@@ -3,6 +3,8 @@
 package semantic
 
 import (
+	"strings"
+
 	"github.com/kon1790/rpg/internal/importer/treesitter"
 )
 
@@ -136,6 +138,28 @@ type MethodSignature struct {
 	IsExported bool `json:"is_exported"`
 }
 
+// String formats the signature the way spec interfaces list methods,
+// e.g. "find(id: string): User"
+func (m MethodSignature) String() string {
+	params := make([]string, len(m.Parameters))
+	for i, p := range m.Parameters {
+		params[i] = p.Name
+		if p.ResolvedType != "" {
+			params[i] += ": " + p.ResolvedType
+		}
+	}
+
+	sig := m.Name + "(" + strings.Join(params, ", ") + ")"
+	switch len(m.ReturnTypes) {
+	case 0:
+	case 1:
+		sig += ": " + m.ReturnTypes[0]
+	default:
+		sig += ": (" + strings.Join(m.ReturnTypes, ", ") + ")"
+	}
+	return sig
+}
+
 // ResolvedParameter represents a parameter with resolved type
 type ResolvedParameter struct {
 	treesitter.Parameter
@@ -107,6 +107,9 @@ func (a *TypeScriptAnalyzer) Analyze(dir string) (*Analysis, error) {
 	a.buildTypeGraph(analysis)
 	a.extractDependencies(analysis)
 
+	// Record the module each element is declared in
+	assignModules(analysis, dir)
+
 	return analysis, nil
 }
 
@@ -57,9 +57,12 @@ func (p *CSharpParser) extractTypes(code []byte, root *sitter.Node, filename str
 	for _, node := range typeNodes {
 		typeDef := p.parseTypeNode(code, node, filename, root)
 		if typeDef != nil {
-			result.Types = append(result.Types, *typeDef)
 			// Extract methods from the type
 			methods := p.extractMethodsFromType(code, node, filename)
+			if typeDef.Kind == TypeKindInterface {
+				typeDef.MethodDefs = interfaceMethods(methods)
+			}
+			result.Types = append(result.Types, *typeDef)
 			result.Functions = append(result.Functions, methods...)
 		}
 	}
@@ -385,8 +388,11 @@ func (p *CSharpParser) parseMethodDeclaration(code []byte, node *sitter.Node, fi
 		ASTHash:  hashNode(code, node),
 	}
 
-	// Extract return type
-	typeNode := findChildByFieldName(node, "type")
+	// Extract return type; older grammars name the field "type"
+	typeNode := findChildByFieldName(node, "returns")
+	if typeNode == nil {
+		typeNode = findChildByFieldName(node, "type")
+	}
 	if typeNode != nil {
 		fn.ReturnType = nodeText(code, typeNode)
 	}
@@ -57,9 +57,12 @@ func (p *JavaParser) extractTypes(code []byte, root *sitter.Node, filename strin
 	for _, node := range typeNodes {
 		typeDef := p.parseTypeNode(code, node, filename, root)
 		if typeDef != nil {
-			result.Types = append(result.Types, *typeDef)
 			// Extract methods from the type
 			methods := p.extractMethodsFromType(code, node, filename)
+			if typeDef.Kind == TypeKindInterface {
+				typeDef.MethodDefs = interfaceMethods(methods)
+			}
+			result.Types = append(result.Types, *typeDef)
 			result.Functions = append(result.Functions, methods...)
 		}
 	}
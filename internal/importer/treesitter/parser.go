@@ -298,3 +298,15 @@ func isExported(name string, lang Language) bool {
 		return true
 	}
 }
+
+// interfaceMethods returns the methods an interface or trait declares. Its
+// members are public wherever the interface is visible, with or without a
+// modifier.
+func interfaceMethods(methods []FunctionDef) []FunctionDef {
+	defs := make([]FunctionDef, len(methods))
+	for i, m := range methods {
+		m.IsPublic = true
+		defs[i] = m
+	}
+	return defs
+}
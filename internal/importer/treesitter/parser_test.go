@@ -371,3 +371,82 @@ func TestMultiLanguageParser(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckSyntax(t *testing.T) {
+	tests := []struct {
+		lang     Language
+		code     string
+		expected int
+	}{
+		{LanguageGo, "package main\n\nfunc main() {}\n", 0},
+		{LanguageTypeScript, "export function f(a: number): number {\n  return a;\n}\n", 0},
+		{LanguageTypeScript, "export function f(a: number: number {\n  return a;\n}\n", 1},
+		{LanguagePython, "def f(a):\n    return a\n", 0},
+		{LanguagePython, "def f(a)\n    return a\n", 1},
+		{LanguageJava, "public class A {\n    void f() { int x = 1 }\n}\n", 1},
+		{LanguageRust, "pub fn f() -> i32 {\n    1\n}\n", 0},
+		{LanguageCSharp, "public class A\n{\n    public int X { get; set; }\n}\n", 0},
+	}
+
+	for _, tt := range tests {
+		errs, err := CheckSyntax([]byte(tt.code), tt.lang)
+		if err != nil {
+			t.Fatalf("CheckSyntax(%s) error: %v", tt.lang, err)
+		}
+		if len(errs) != tt.expected {
+			t.Errorf("Expected %d syntax errors in %s code %q, got %v", tt.expected, tt.lang, tt.code, errs)
+		}
+	}
+
+	errs, _ := CheckSyntax([]byte("public class A {\n    void f() { int x = 1 }\n}\n"), LanguageJava)
+	if len(errs) == 1 && (errs[0].Line != 2 || errs[0].String() != "2:25: missing ;") {
+		t.Errorf("Expected a missing semicolon at 2:25, got %s", errs[0])
+	}
+
+	if _, err := CheckSyntax(nil, LanguageProtobuf); err == nil {
+		t.Error("Expected an error for a language without a parser")
+	}
+}
+
+func TestInterfaceMethodDefs(t *testing.T) {
+	tests := []struct {
+		lang     Language
+		file     string
+		code     string
+		expected string // Return type of the find method
+	}{
+		{LanguageTypeScript, "store.ts", "export interface UserStore {\n  find(id: string): User;\n  save(user: User): void;\n}\n", "User"},
+		{LanguagePython, "store.py", "class UserStore(Protocol):\n    def find(self, id: str) -> User:\n        ...\n\n    def save(self, user: User):\n        ...\n", "User"},
+		{LanguageJava, "UserStore.java", "public interface UserStore {\n    User find(String id);\n    void save(User user);\n}\n", "User"},
+		{LanguageRust, "store.rs", "pub trait UserStore {\n    fn find(&self, id: String) -> Option<User>;\n    fn save(&self, user: User);\n}\n", "Option<User>"},
+		{LanguageCSharp, "UserStore.cs", "public interface UserStore\n{\n    User Find(string id);\n    void Save(User user);\n}\n", "User"},
+	}
+
+	parser := NewParser()
+	for _, tt := range tests {
+		t.Run(string(tt.lang), func(t *testing.T) {
+			result, err := parser.Parse([]byte(tt.code), tt.file, tt.lang)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(result.Types) != 1 || result.Types[0].Kind != TypeKindInterface {
+				t.Fatalf("Expected one interface, got %+v", result.Types)
+			}
+
+			methods := result.Types[0].MethodDefs
+			if len(methods) != 2 {
+				t.Fatalf("Expected 2 method signatures, got %+v", methods)
+			}
+			find := methods[0]
+			if len(find.Parameters) != 1 || find.Parameters[0].Name != "id" {
+				t.Errorf("Expected find to take id, got %+v", find.Parameters)
+			}
+			if find.ReturnType != tt.expected {
+				t.Errorf("Expected find to return %s, got %q", tt.expected, find.ReturnType)
+			}
+			if !find.IsPublic {
+				t.Error("Expected interface methods to be public")
+			}
+		})
+	}
+}
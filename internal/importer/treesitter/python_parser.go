@@ -404,9 +404,37 @@ func (p *PythonParser) parseClassNode(code []byte, node *sitter.Node, filename s
 		typeDef.Fields, typeDef.Methods = p.extractClassMembers(code, bodyNode)
 	}
 
+	// A Protocol is Python's interface
+	if typeDef.Extends == "Protocol" || typeDef.Extends == "typing.Protocol" {
+		typeDef.Kind = TypeKindInterface
+		if bodyNode != nil {
+			typeDef.MethodDefs = p.extractProtocolMethods(code, bodyNode, filename, root)
+		}
+	}
+
 	return typeDef
 }
 
+// extractProtocolMethods extracts the full signatures of a Protocol's
+// methods
+func (p *PythonParser) extractProtocolMethods(code []byte, bodyNode *sitter.Node, filename string, root *sitter.Node) []FunctionDef {
+	var methods []FunctionDef
+	for i := 0; i < int(bodyNode.NamedChildCount()); i++ {
+		child := bodyNode.NamedChild(i)
+		if child.Type() == "decorated_definition" {
+			child = findChildByFieldName(child, "definition")
+		}
+		if child == nil || child.Type() != "function_definition" {
+			continue
+		}
+		if fn := p.parseFunctionNode(code, child, filename, root); fn != nil {
+			methods = append(methods, *fn)
+		}
+	}
+
+	return interfaceMethods(methods)
+}
+
 // extractSuperclasses extracts base classes
 func (p *PythonParser) extractSuperclasses(code []byte, node *sitter.Node) []string {
 	var bases []string
@@ -164,12 +164,9 @@ func (p *RustParser) parseParameter(code []byte, node *sitter.Node) *Parameter {
 
 // extractReturnType extracts the return type
 func (p *RustParser) extractReturnType(code []byte, node *sitter.Node) string {
-	// Skip the "->" token
-	for i := 0; i < int(node.ChildCount()); i++ {
-		child := node.Child(i)
-		if child.Type() != "->" {
-			return nodeText(code, child)
-		}
+	// The field is usually the type itself; skip the "->" token otherwise
+	if node.ChildCount() > 1 && node.Child(0).Type() == "->" {
+		return nodeText(code, node.Child(1))
 	}
 	return nodeText(code, node)
 }
@@ -315,6 +312,7 @@ func (p *RustParser) parseTypeNode(code []byte, node *sitter.Node, filename stri
 	case "trait_item":
 		typeDef.Kind = TypeKindInterface
 		typeDef.Methods = p.extractTraitMethods(code, node)
+		typeDef.MethodDefs = p.extractTraitSignatures(code, node, filename, root)
 		typeDef.Generic = p.extractTypeParameters(code, node)
 
 	case "type_item":
@@ -431,6 +429,26 @@ func (p *RustParser) extractTraitMethods(code []byte, node *sitter.Node) []strin
 	return methods
 }
 
+// extractTraitSignatures extracts the full signatures of a trait's methods
+func (p *RustParser) extractTraitSignatures(code []byte, node *sitter.Node, filename string, root *sitter.Node) []FunctionDef {
+	bodyNode := findChildByFieldName(node, "body")
+	if bodyNode == nil {
+		return nil
+	}
+
+	var methods []FunctionDef
+	funcNodes := collectNodes(bodyNode, func(n *sitter.Node) bool {
+		return n.Type() == "function_signature_item" || n.Type() == "function_item"
+	})
+	for _, funcNode := range funcNodes {
+		if fn := p.parseFunctionNode(code, funcNode, filename, root); fn != nil {
+			methods = append(methods, *fn)
+		}
+	}
+
+	return interfaceMethods(methods)
+}
+
 // extractTypeParameters extracts generic type parameters
 func (p *RustParser) extractTypeParameters(code []byte, node *sitter.Node) []string {
 	var params []string
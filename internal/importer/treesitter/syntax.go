@@ -0,0 +1,72 @@
+package treesitter
+
+import (
+	"fmt"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// SyntaxError is a syntax error tree-sitter recovered from while parsing
+type SyntaxError struct {
+	// Line and Column locate the error, both 1-based
+	Line   int
+	Column int
+
+	// Message describes the error and quotes the offending source
+	Message string
+}
+
+func (e SyntaxError) String() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// CheckSyntax parses code and reports each ERROR node (unparsable source)
+// and MISSING node (a token the grammar expected but did not find). Nested
+// errors are reported once, at the outermost node.
+func CheckSyntax(code []byte, lang Language) ([]SyntaxError, error) {
+	tsLang := getTreeSitterLanguage(lang)
+	if tsLang == nil {
+		return nil, fmt.Errorf("no parser registered for language: %s", lang)
+	}
+	parser := sitter.NewParser()
+	parser.SetLanguage(tsLang)
+	tree := parser.Parse(nil, code)
+	if tree == nil {
+		return nil, fmt.Errorf("tree-sitter parse failed: returned nil tree")
+	}
+	defer tree.Close()
+
+	var errs []SyntaxError
+	walkTree(tree.RootNode(), func(n *sitter.Node) bool {
+		if !n.HasError() && !n.IsMissing() {
+			return false
+		}
+		var message string
+		switch {
+		case n.IsMissing():
+			message = fmt.Sprintf("missing %s", n.Type())
+		case n.IsError():
+			message = fmt.Sprintf("unexpected %s", quoteSource(nodeText(code, n)))
+		default:
+			return true
+		}
+		start := n.StartPoint()
+		errs = append(errs, SyntaxError{Line: int(start.Row) + 1, Column: int(start.Column) + 1, Message: message})
+		return false
+	})
+	return errs, nil
+}
+
+// quoteSource quotes the first line of a source snippet, shortened to keep
+// diagnostics on one line
+func quoteSource(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " ..."
+	}
+	if len(s) > 40 {
+		s = s[:40] + " ..."
+	}
+	return fmt.Sprintf("%q", s)
+}
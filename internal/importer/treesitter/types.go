@@ -12,6 +12,7 @@ const (
 	LanguageJava       Language = "java"
 	LanguageRust       Language = "rust"
 	LanguageCSharp     Language = "csharp"
+	LanguageProtobuf   Language = "protobuf"
 )
 
 // SourceLocation represents a position in source code
@@ -25,19 +26,19 @@ type SourceLocation struct {
 
 // FunctionDef represents an extracted function definition
 type FunctionDef struct {
-	Name        string         `json:"name"`
-	Signature   string         `json:"signature"`
-	Parameters  []Parameter    `json:"parameters"`
-	ReturnType  string         `json:"return_type"`
-	IsAsync     bool           `json:"is_async"`
-	IsPublic    bool           `json:"is_public"`
-	IsStatic    bool           `json:"is_static"`
-	DocComment  string         `json:"doc_comment,omitempty"`
-	Body        string         `json:"body,omitempty"`
-	Location    SourceLocation `json:"location"`
-	ASTHash     string         `json:"ast_hash,omitempty"`
-	Calls       []string       `json:"calls,omitempty"`       // Functions called within this function
-	Complexity  int            `json:"complexity,omitempty"`  // Cyclomatic complexity
+	Name       string         `json:"name"`
+	Signature  string         `json:"signature"`
+	Parameters []Parameter    `json:"parameters"`
+	ReturnType string         `json:"return_type"`
+	IsAsync    bool           `json:"is_async"`
+	IsPublic   bool           `json:"is_public"`
+	IsStatic   bool           `json:"is_static"`
+	DocComment string         `json:"doc_comment,omitempty"`
+	Body       string         `json:"body,omitempty"`
+	Location   SourceLocation `json:"location"`
+	ASTHash    string         `json:"ast_hash,omitempty"`
+	Calls      []string       `json:"calls,omitempty"`      // Functions called within this function
+	Complexity int            `json:"complexity,omitempty"` // Cyclomatic complexity
 }
 
 // Parameter represents a function parameter
@@ -55,6 +56,7 @@ type TypeDef struct {
 	Kind       TypeKind       `json:"kind"`
 	Fields     []Field        `json:"fields,omitempty"`
 	Methods    []string       `json:"methods,omitempty"`
+	MethodDefs []FunctionDef  `json:"method_defs,omitempty"` // Interface and trait methods with their signatures
 	Implements []string       `json:"implements,omitempty"`
 	Extends    string         `json:"extends,omitempty"`
 	Variants   []string       `json:"variants,omitempty"` // For enums
@@ -91,22 +93,22 @@ type Field struct {
 
 // Import represents an import/dependency
 type Import struct {
-	Path    string `json:"path"`
-	Alias   string `json:"alias,omitempty"`
-	IsLocal bool   `json:"is_local"` // Local vs external dependency
+	Path    string   `json:"path"`
+	Alias   string   `json:"alias,omitempty"`
+	IsLocal bool     `json:"is_local"`        // Local vs external dependency
 	Items   []string `json:"items,omitempty"` // For named imports
 }
 
 // ParseResult contains the complete AST analysis result
 type ParseResult struct {
-	Language    Language      `json:"language"`
-	FileName    string        `json:"file_name"`
-	Functions   []FunctionDef `json:"functions"`
-	Types       []TypeDef     `json:"types"`
-	Imports     []Import      `json:"imports"`
-	Constants   []Constant    `json:"constants"`
-	Errors      []ParseError  `json:"errors,omitempty"`
-	RawAST      interface{}   `json:"-"` // Internal: the raw tree-sitter tree
+	Language  Language      `json:"language"`
+	FileName  string        `json:"file_name"`
+	Functions []FunctionDef `json:"functions"`
+	Types     []TypeDef     `json:"types"`
+	Imports   []Import      `json:"imports"`
+	Constants []Constant    `json:"constants"`
+	Errors    []ParseError  `json:"errors,omitempty"`
+	RawAST    interface{}   `json:"-"` // Internal: the raw tree-sitter tree
 }
 
 // Constant represents a constant definition
@@ -146,13 +148,13 @@ type NormalizedType struct {
 
 // ProjectAnalysis represents the complete analysis of a project
 type ProjectAnalysis struct {
-	Name         string           `json:"name"`
-	Language     Language         `json:"language"`
-	Files        []ParseResult    `json:"files"`
-	AllFunctions []FunctionDef    `json:"all_functions"`
-	AllTypes     []TypeDef        `json:"all_types"`
-	AllImports   []Import         `json:"all_imports"`
-	CallGraph    map[string][]string `json:"call_graph,omitempty"`    // func -> called funcs
-	TypeGraph    map[string][]string `json:"type_graph,omitempty"`    // type -> implemented interfaces
-	Dependencies []string         `json:"dependencies"`              // External dependencies
+	Name         string              `json:"name"`
+	Language     Language            `json:"language"`
+	Files        []ParseResult       `json:"files"`
+	AllFunctions []FunctionDef       `json:"all_functions"`
+	AllTypes     []TypeDef           `json:"all_types"`
+	AllImports   []Import            `json:"all_imports"`
+	CallGraph    map[string][]string `json:"call_graph,omitempty"` // func -> called funcs
+	TypeGraph    map[string][]string `json:"type_graph,omitempty"` // type -> implemented interfaces
+	Dependencies []string            `json:"dependencies"`         // External dependencies
 }
@@ -329,6 +329,7 @@ func (p *TypeScriptParser) parseTypeNode(code []byte, node *sitter.Node, filenam
 	case "interface_declaration":
 		typeDef.Kind = TypeKindInterface
 		typeDef.Fields, typeDef.Methods = p.extractInterfaceMembers(code, node)
+		typeDef.MethodDefs = p.extractMethodSignatures(code, node, filename, root)
 		typeDef.Extends = p.extractExtends(code, node)
 
 	case "type_alias_declaration":
@@ -383,6 +384,27 @@ func (p *TypeScriptParser) extractInterfaceMembers(code []byte, node *sitter.Nod
 	return fields, methods
 }
 
+// extractMethodSignatures extracts the full signatures of an interface's
+// methods
+func (p *TypeScriptParser) extractMethodSignatures(code []byte, node *sitter.Node, filename string, root *sitter.Node) []FunctionDef {
+	bodyNode := findChildByFieldName(node, "body")
+	if bodyNode == nil {
+		return nil
+	}
+
+	var methods []FunctionDef
+	sigNodes := collectNodes(bodyNode, func(n *sitter.Node) bool {
+		return n.Type() == "method_signature"
+	})
+	for _, sigNode := range sigNodes {
+		if fn := p.parseFunctionNode(code, sigNode, filename, root); fn != nil {
+			methods = append(methods, *fn)
+		}
+	}
+
+	return interfaceMethods(methods)
+}
+
 // parsePropertySignature extracts a property from an interface
 func (p *TypeScriptParser) parsePropertySignature(code []byte, node *sitter.Node) *Field {
 	nameNode := findChildByFieldName(node, "name")
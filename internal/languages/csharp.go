@@ -153,27 +153,5 @@ func (a *CSharpAdapter) GetProjectStructure(specName string, hasTests bool) []Pr
 
 // MapType maps a pseudo-code type to C#'s equivalent.
 func (a *CSharpAdapter) MapType(pseudoType string) string {
-	typeMap := map[string]string{
-		"Text":      "string",
-		"String":    "string",
-		"Integer":   "int",
-		"Int":       "int",
-		"Number":    "int",
-		"Float":     "double",
-		"Decimal":   "decimal",
-		"Boolean":   "bool",
-		"Bool":      "bool",
-		"Timestamp": "DateTimeOffset",
-		"Duration":  "TimeSpan",
-		"UUID":      "Guid",
-		"Any":       "object",
-		"Nothing":   "void",
-		"Void":      "void",
-		"None":      "void",
-	}
-
-	if csType, ok := typeMap[pseudoType]; ok {
-		return csType
-	}
-	return pseudoType
+	return mapType(pseudoType, "csharp")
 }
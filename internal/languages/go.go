@@ -135,27 +135,5 @@ func (a *GoAdapter) GetProjectStructure(specName string, hasTests bool) []Projec
 
 // MapType maps a pseudo-code type to Go's equivalent.
 func (a *GoAdapter) MapType(pseudoType string) string {
-	typeMap := map[string]string{
-		"Text":      "string",
-		"String":    "string",
-		"Integer":   "int",
-		"Int":       "int",
-		"Number":    "int",
-		"Float":     "float64",
-		"Decimal":   "float64",
-		"Boolean":   "bool",
-		"Bool":      "bool",
-		"Timestamp": "time.Time",
-		"Duration":  "time.Duration",
-		"UUID":      "string",
-		"Any":       "interface{}",
-		"Nothing":   "",
-		"Void":      "",
-		"None":      "",
-	}
-
-	if goType, ok := typeMap[pseudoType]; ok {
-		return goType
-	}
-	return pseudoType
+	return mapType(pseudoType, "go")
 }
@@ -142,27 +142,5 @@ func (a *JavaAdapter) GetProjectStructure(specName string, hasTests bool) []Proj
 
 // MapType maps a pseudo-code type to Java's equivalent.
 func (a *JavaAdapter) MapType(pseudoType string) string {
-	typeMap := map[string]string{
-		"Text":      "String",
-		"String":    "String",
-		"Integer":   "int",
-		"Int":       "int",
-		"Number":    "int",
-		"Float":     "double",
-		"Decimal":   "BigDecimal",
-		"Boolean":   "boolean",
-		"Bool":      "boolean",
-		"Timestamp": "Instant",
-		"Duration":  "Duration",
-		"UUID":      "UUID",
-		"Any":       "Object",
-		"Nothing":   "void",
-		"Void":      "void",
-		"None":      "void",
-	}
-
-	if javaType, ok := typeMap[pseudoType]; ok {
-		return javaType
-	}
-	return pseudoType
+	return mapType(pseudoType, "java")
 }
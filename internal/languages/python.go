@@ -150,27 +150,5 @@ func (a *PythonAdapter) GetProjectStructure(specName string, hasTests bool) []Pr
 
 // MapType maps a pseudo-code type to Python's equivalent.
 func (a *PythonAdapter) MapType(pseudoType string) string {
-	typeMap := map[string]string{
-		"Text":      "str",
-		"String":    "str",
-		"Integer":   "int",
-		"Int":       "int",
-		"Number":    "int",
-		"Float":     "float",
-		"Decimal":   "Decimal",
-		"Boolean":   "bool",
-		"Bool":      "bool",
-		"Timestamp": "datetime",
-		"Duration":  "timedelta",
-		"UUID":      "UUID",
-		"Any":       "Any",
-		"Nothing":   "None",
-		"Void":      "None",
-		"None":      "None",
-	}
-
-	if pyType, ok := typeMap[pseudoType]; ok {
-		return pyType
-	}
-	return pseudoType
+	return mapType(pseudoType, "python")
 }
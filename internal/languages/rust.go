@@ -138,27 +138,5 @@ func (a *RustAdapter) GetProjectStructure(specName string, hasTests bool) []Proj
 
 // MapType maps a pseudo-code type to Rust's equivalent.
 func (a *RustAdapter) MapType(pseudoType string) string {
-	typeMap := map[string]string{
-		"Text":      "String",
-		"String":    "String",
-		"Integer":   "i64",
-		"Int":       "i64",
-		"Number":    "i64",
-		"Float":     "f64",
-		"Decimal":   "f64",
-		"Boolean":   "bool",
-		"Bool":      "bool",
-		"Timestamp": "chrono::DateTime<chrono::Utc>",
-		"Duration":  "std::time::Duration",
-		"UUID":      "uuid::Uuid",
-		"Any":       "serde_json::Value",
-		"Nothing":   "()",
-		"Void":      "()",
-		"None":      "()",
-	}
-
-	if rustType, ok := typeMap[pseudoType]; ok {
-		return rustType
-	}
-	return pseudoType
+	return mapType(pseudoType, "rust")
 }
@@ -1,6 +1,8 @@
 // Package languages defines language adapters and conventions.
 package languages
 
+import "github.com/kon1790/rpg/internal/typeexpr"
+
 // Language represents a supported target language with its conventions.
 type Language struct {
 	ID               string            `json:"id"`
@@ -79,3 +81,13 @@ type ProjectFile struct {
 	Purpose     string `json:"purpose"`
 	Description string `json:"description,omitempty"`
 }
+
+// mapType renders a pseudo-code type expression in the given language,
+// returning types outside the grammar unchanged.
+func mapType(pseudoType, lang string) string {
+	expr, err := typeexpr.Parse(pseudoType)
+	if err != nil {
+		return pseudoType
+	}
+	return expr.Render(lang)
+}
@@ -154,27 +154,5 @@ func (a *TypeScriptAdapter) GetProjectStructure(specName string, hasTests bool)
 
 // MapType maps a pseudo-code type to TypeScript's equivalent.
 func (a *TypeScriptAdapter) MapType(pseudoType string) string {
-	typeMap := map[string]string{
-		"Text":      "string",
-		"String":    "string",
-		"Integer":   "number",
-		"Int":       "number",
-		"Number":    "number",
-		"Float":     "number",
-		"Decimal":   "number",
-		"Boolean":   "boolean",
-		"Bool":      "boolean",
-		"Timestamp": "Date",
-		"Duration":  "number",
-		"UUID":      "string",
-		"Any":       "unknown",
-		"Nothing":   "void",
-		"Void":      "void",
-		"None":      "undefined",
-	}
-
-	if tsType, ok := typeMap[pseudoType]; ok {
-		return tsType
-	}
-	return pseudoType
+	return mapType(pseudoType, "typescript")
 }
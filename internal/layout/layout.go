@@ -0,0 +1,178 @@
+// Package layout maps module trees between the conventions of the target
+// languages: Go and Java packages, Python packages, Rust module
+// directories, C# namespace folders and TypeScript folders.
+//
+// A module is a slash-separated path of names relative to a project's
+// source root, such as "billing/invoices"; "" is the root module. Each
+// language spells a module's directory its own way ("billing/invoices" in
+// Go, "Billing/Invoices" in C#), so modules are compared by Key.
+package layout
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+// sourceRoots are the directories a language keeps its sources under,
+// which are not part of any module. The longest match is stripped.
+var sourceRoots = map[string][]string{
+	"java":       {"src/main/java/", "src/test/java/", "src/"},
+	"typescript": {"src/", "lib/"},
+	"python":     {"src/", "lib/"},
+	"rust":       {"src/"},
+	"csharp":     {"src/"},
+}
+
+// ModuleOf returns the module a source file belongs to: the directory
+// holding it, below the language's source root. file is relative to the
+// project directory.
+func ModuleOf(lang, file string) string {
+	file = strings.TrimPrefix(path.Clean(strings.ReplaceAll(file, "\\", "/")), "./")
+	for _, root := range sourceRoots[lang] {
+		if strings.HasPrefix(file, root) {
+			file = strings.TrimPrefix(file, root)
+			break
+		}
+	}
+	dir := path.Dir(file)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir
+}
+
+// CommonRoot returns the longest module path every module lies within,
+// such as the "com/acme" base package of a Java project.
+func CommonRoot(modules []string) string {
+	if len(modules) == 0 || modules[0] == "" {
+		return ""
+	}
+	root := strings.Split(modules[0], "/")
+	for _, m := range modules[1:] {
+		if m == "" {
+			return ""
+		}
+		segments := strings.Split(m, "/")
+		n := 0
+		for n < len(root) && n < len(segments) && root[n] == segments[n] {
+			n++
+		}
+		root = root[:n]
+	}
+	return strings.Join(root, "/")
+}
+
+// Relative returns a module's path below root ("" for root itself).
+func Relative(root, module string) string {
+	switch {
+	case module == root:
+		return ""
+	case root == "":
+		return module
+	}
+	return strings.TrimPrefix(module, root+"/")
+}
+
+// Key returns the form modules are compared in: lowercase, without the
+// underscores and dashes the languages' spellings differ by.
+func Key(module string) string {
+	var sb strings.Builder
+	for _, r := range module {
+		switch {
+		case r == '/':
+			sb.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// Dir returns a module's directory in a language's naming: lowercase in Go
+// and Java, snake_case in Python and Rust, kebab-case in TypeScript and
+// PascalCase in C#.
+func Dir(lang, module string) string {
+	if module == "" {
+		return ""
+	}
+	var segments []string
+	for _, s := range strings.Split(module, "/") {
+		words := splitWords(s)
+		switch lang {
+		case "go", "java":
+			s = strings.ToLower(strings.Join(words, ""))
+		case "typescript":
+			s = strings.ToLower(strings.Join(words, "-"))
+		case "csharp":
+			for i, w := range words {
+				words[i] = strings.ToUpper(w[:1]) + strings.ToLower(w[1:])
+			}
+			s = strings.Join(words, "")
+		default:
+			s = strings.ToLower(strings.Join(words, "_"))
+		}
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Path returns where a module's file goes, given the file's path in the
+// root module: the module's directory is added before the file name, so
+// "src/types.rs" becomes "src/billing/types.rs".
+func Path(lang, module, file string) string {
+	dir := Dir(lang, module)
+	if dir == "" {
+		return file
+	}
+	return path.Join(path.Dir(file), dir, path.Base(file))
+}
+
+// Package returns the package or namespace a module's files declare,
+// given the root module's: the last directory in Go, the dotted path below
+// root in Java and C#, the dotted path in Python, the :: path in Rust and
+// the directory in TypeScript.
+func Package(lang, root, module string) string {
+	dir := Dir(lang, module)
+	if dir == "" {
+		return root
+	}
+	switch lang {
+	case "go":
+		return path.Base(dir)
+	case "java", "csharp":
+		return root + "." + strings.ReplaceAll(dir, "/", ".")
+	case "python":
+		return strings.ReplaceAll(dir, "/", ".")
+	case "rust":
+		return strings.ReplaceAll(dir, "/", "::")
+	}
+	return dir
+}
+
+// splitWords splits a name at separators and camelCase boundaries.
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(current) > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			words = append(words, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
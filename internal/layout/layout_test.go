@@ -0,0 +1,90 @@
+package layout
+
+import "testing"
+
+func TestModuleOf(t *testing.T) {
+	tests := []struct {
+		lang     string
+		file     string
+		expected string
+	}{
+		{"go", "types.go", ""},
+		{"go", "billing/invoices/types.go", "billing/invoices"},
+		{"typescript", "src/types.ts", ""},
+		{"typescript", "src/billing/types.ts", "billing"},
+		{"python", "src/billing/types.py", "billing"},
+		{"java", "src/main/java/shop/billing/Types.java", "shop/billing"},
+		{"java", "src/test/java/shop/TypesTest.java", "shop"},
+		{"rust", "src/billing/mod.rs", "billing"},
+		{"csharp", "src/Billing/Types.cs", "Billing"},
+		{"csharp", "./src/Types.cs", ""},
+	}
+
+	for _, tt := range tests {
+		if result := ModuleOf(tt.lang, tt.file); result != tt.expected {
+			t.Errorf("ModuleOf(%s, %s) = %q, expected %q", tt.lang, tt.file, result, tt.expected)
+		}
+	}
+}
+
+func TestCommonRoot(t *testing.T) {
+	tests := []struct {
+		modules  []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"com/shop/billing", "com/shop/accounts"}, "com/shop"},
+		{[]string{"com/shop", "com/shop/billing"}, "com/shop"},
+		{[]string{"", "billing"}, ""},
+		{[]string{"billing", "accounts"}, ""},
+	}
+
+	for _, tt := range tests {
+		if result := CommonRoot(tt.modules); result != tt.expected {
+			t.Errorf("CommonRoot(%v) = %q, expected %q", tt.modules, result, tt.expected)
+		}
+	}
+
+	if result := Relative("com/shop", "com/shop/billing"); result != "billing" {
+		t.Errorf("Expected billing, got %q", result)
+	}
+	if result := Relative("com/shop", "com/shop"); result != "" {
+		t.Errorf("Expected the root module, got %q", result)
+	}
+}
+
+func TestLanguageLayout(t *testing.T) {
+	tests := []struct {
+		lang    string
+		file    string
+		path    string
+		pkg     string
+		pkgRoot string
+	}{
+		{"go", "types.go", "billing/lineitems/types.go", "lineitems", "shop"},
+		{"typescript", "src/types.ts", "src/billing/line-items/types.ts", "billing/line-items", ""},
+		{"python", "src/types.py", "src/billing/line_items/types.py", "billing.line_items", ""},
+		{"java", "src/main/java/shop/Types.java", "src/main/java/shop/billing/lineitems/Types.java", "shop.billing.lineitems", "shop"},
+		{"rust", "src/types.rs", "src/billing/line_items/types.rs", "billing::line_items", ""},
+		{"csharp", "src/Types.cs", "src/Billing/LineItems/Types.cs", "Shop.Billing.LineItems", "Shop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if result := Path(tt.lang, "billing/lineItems", tt.file); result != tt.path {
+				t.Errorf("Expected path %s, got %s", tt.path, result)
+			}
+			if result := Package(tt.lang, tt.pkgRoot, "billing/lineItems"); result != tt.pkg {
+				t.Errorf("Expected package %s, got %s", tt.pkg, result)
+			}
+			if result := Path(tt.lang, "", tt.file); result != tt.file {
+				t.Errorf("Expected the root module's path %s, got %s", tt.file, result)
+			}
+		})
+	}
+
+	// Spellings of the same module compare equal
+	if Key("billing/line_items") != Key("Billing/LineItems") || Key("billing/line-items") != Key("billing/lineitems") {
+		t.Error("Expected module spellings to share a key")
+	}
+}
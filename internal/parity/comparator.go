@@ -38,6 +38,16 @@ func (c *Comparator) Compare(source *semantic.Analysis, generated map[string]*se
 	for lang, genAnalysis := range generated {
 		langResult := c.compareLanguage(sourceFuncs, sourceTypes, genAnalysis)
 		langResult.Language = treesitter.Language(lang)
+
+		// Elements generated in the wrong module count against structure
+		if misplaced, compared := c.comparePlacement(source, genAnalysis); compared > 0 {
+			placement := float64(compared-len(misplaced)) / float64(compared)
+			langResult.ByDimension.Structural = (2*langResult.ByDimension.Structural + placement) / 3
+			langResult.OverallScore = c.calculateWeightedScore(langResult.ByDimension)
+			for _, m := range misplaced {
+				langResult.Misplaced = append(langResult.Misplaced, m.source.name)
+			}
+		}
 		result.ByLanguage[lang] = langResult
 
 		// Collect gaps
@@ -346,6 +356,12 @@ func (c *Comparator) identifyGaps(source, gen *semantic.Analysis, lang string) [
 		}
 	}
 
+	// Find types and functions generated in the wrong module
+	misplaced, _ := c.comparePlacement(source, gen)
+	for _, m := range misplaced {
+		gaps = append(gaps, placementGap(m, string(source.Language), lang))
+	}
+
 	return gaps
 }
 
@@ -1,6 +1,9 @@
 package parity
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/kon1790/rpg/internal/importer/semantic"
@@ -302,3 +305,140 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Threshold should be between 0.8 and 1.0, got %.2f", config.Threshold)
 	}
 }
+
+func TestIdentifierMap(t *testing.T) {
+	dir := t.TempDir()
+	if mappings, err := LoadIdentifierMap(dir); err != nil || mappings != nil {
+		t.Errorf("Expected no mappings without a map file, got %v, %v", mappings, err)
+	}
+
+	data := `[{"kind": "value", "scope": "Keyword", "specName": "1st", "identifier": "X1st"}]`
+	if err := os.MkdirAll(filepath.Join(dir, ".rpg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, IdentifierMapFile), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mappings, err := LoadIdentifierMap(dir)
+	if err != nil || len(mappings) != 1 {
+		t.Fatalf("Expected one mapping, got %v, %v", mappings, err)
+	}
+
+	c := NewComparator(DefaultConfig())
+	c.AddIdentifierMap(mappings)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"X1st", "1st"},
+		{"r#type", "type"},
+		{"@class", "class"},
+		{"class_", "class"},
+		{"user_", "user"},
+		{"__init__", "__init__"},
+	}
+
+	for _, tc := range tests {
+		if result := c.normalizer.normalizeName(tc.input); result != tc.expected {
+			t.Errorf("normalizeName(%s) = %s, expected %s", tc.input, result, tc.expected)
+		}
+	}
+}
+
+func TestMisplacedElements(t *testing.T) {
+	typeIn := func(name, module string) semantic.ResolvedType {
+		return semantic.ResolvedType{
+			TypeDef:     treesitter.TypeDef{Name: name, Kind: treesitter.TypeKindStruct, IsPublic: true},
+			PackagePath: module,
+		}
+	}
+	funcIn := func(name, module string) semantic.ResolvedFunction {
+		return semantic.ResolvedFunction{
+			FunctionDef: treesitter.FunctionDef{Name: name, IsPublic: true},
+			PackagePath: module,
+		}
+	}
+
+	// The Go source keeps its packages under internal/, the Java project
+	// under its base package
+	source := &semantic.Analysis{
+		Language:  treesitter.LanguageGo,
+		Types:     []semantic.ResolvedType{typeIn("User", "internal/accounts"), typeIn("Invoice", "internal/billing")},
+		Functions: []semantic.ResolvedFunction{funcIn("CreateInvoice", "internal/billing")},
+	}
+	generated := map[string]*semantic.Analysis{
+		"java": {
+			Language:  treesitter.LanguageJava,
+			Types:     []semantic.ResolvedType{typeIn("User", "com/shop/accounts"), typeIn("Invoice", "com/shop/accounts")},
+			Functions: []semantic.ResolvedFunction{funcIn("createInvoice", "com/shop/billing")},
+		},
+		"python": {
+			Language:  treesitter.LanguagePython,
+			Types:     []semantic.ResolvedType{typeIn("User", "accounts"), typeIn("Invoice", "billing")},
+			Functions: []semantic.ResolvedFunction{funcIn("create_invoice", "billing")},
+		},
+	}
+
+	result := NewComparator(DefaultConfig()).Compare(source, generated)
+
+	if misplaced := result.ByLanguage["java"].Misplaced; len(misplaced) != 1 || misplaced[0] != "Invoice" {
+		t.Errorf("Expected Invoice misplaced in java, got %v", misplaced)
+	}
+	if misplaced := result.ByLanguage["python"].Misplaced; len(misplaced) != 0 {
+		t.Errorf("Expected nothing misplaced in python, got %v", misplaced)
+	}
+	if java, python := result.ByLanguage["java"].ByDimension.Structural, result.ByLanguage["python"].ByDimension.Structural; java >= python {
+		t.Errorf("Expected misplacement to lower the structural score, got %.2f >= %.2f", java, python)
+	}
+
+	var found bool
+	for _, gap := range result.Gaps {
+		if gap.Dimension == "structural" && gap.SourceItem.Name == "Invoice" {
+			found = true
+			if gap.Discrepancy != "declared in module billing, generated in module accounts" {
+				t.Errorf("Unexpected discrepancy %q", gap.Discrepancy)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected a structural gap for Invoice, got %v", result.Gaps)
+	}
+}
+
+func TestTypeMatchMethods(t *testing.T) {
+	find := NormalizedSignature{
+		Name:       "find",
+		Parameters: []NormalizedParam{{Name: "id", BaseType: "string"}},
+		Returns:    []string{"user"},
+	}
+	save := NormalizedSignature{
+		Name:       "save",
+		Parameters: []NormalizedParam{{Name: "user", BaseType: "user"}},
+	}
+	findByEmail := find
+	findByEmail.Parameters = []NormalizedParam{{Name: "email", BaseType: "integer"}}
+	store := func(methods ...NormalizedSignature) NormalizedType {
+		return NormalizedType{Name: "user_store", Kind: "interface", Signatures: methods}
+	}
+
+	tests := []struct {
+		name     string
+		gen      NormalizedType
+		expected []string
+	}{
+		{"same methods", store(find, save), nil},
+		{"missing method", store(find), []string{"missing method: save"}},
+		{"extra method", store(find, save, NormalizedSignature{Name: "count"}), []string{"extra method: count"}},
+		{"changed signature", store(findByEmail, save), []string{"method find: parameter type mismatch: id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, diffs := TypeMatch(store(find, save), tt.gen, false)
+			if match != (len(tt.expected) == 0) || !reflect.DeepEqual(diffs, tt.expected) {
+				t.Errorf("Expected differences %v, got %v", tt.expected, diffs)
+			}
+		})
+	}
+}
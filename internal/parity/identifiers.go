@@ -0,0 +1,65 @@
+package parity
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IdentifierMapFile is where a generated project records the spec names it
+// had to rename, relative to the project directory
+const IdentifierMapFile = ".rpg/identifiers.json"
+
+// IdentifierMapping links a spec name to the identifier generated for it
+// when the language's case conversion alone would not compile, e.g. the
+// field "type" becoming r#type in Rust or type_ in Python
+type IdentifierMapping struct {
+	// Kind is what the name identifies: type, field, method, parameter,
+	// function or value
+	Kind string `json:"kind"`
+
+	// Scope is the type or function the name belongs to, if any
+	Scope string `json:"scope,omitempty"`
+
+	// SpecName is the name as the spec writes it
+	SpecName string `json:"specName"`
+
+	// Identifier is the name in the generated code
+	Identifier string `json:"identifier"`
+}
+
+// LoadIdentifierMap reads a generated project's identifier map. A project
+// without one had nothing renamed.
+func LoadIdentifierMap(dir string) ([]IdentifierMapping, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IdentifierMapFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var mappings []IdentifierMapping
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return nil, err
+	}
+	return mappings, nil
+}
+
+// AddIdentifierMap makes renamed identifiers compare as their spec names
+func (c *Comparator) AddIdentifierMap(mappings []IdentifierMapping) {
+	for _, m := range mappings {
+		c.normalizer.aliases[m.Identifier] = strings.Join(strings.Fields(m.SpecName), "_")
+	}
+}
+
+// unescapeIdentifier strips the escapes languages put on reserved words:
+// Rust's r# prefix, C#'s @ prefix and the trailing underscore elsewhere
+func unescapeIdentifier(name string) string {
+	name = strings.TrimPrefix(name, "r#")
+	name = strings.TrimPrefix(name, "@")
+	if trimmed := strings.TrimSuffix(name, "_"); trimmed != "" && !strings.HasSuffix(trimmed, "_") {
+		name = trimmed
+	}
+	return name
+}
@@ -0,0 +1,130 @@
+package parity
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kon1790/rpg/internal/importer/semantic"
+	"github.com/kon1790/rpg/internal/layout"
+)
+
+// placed is an element's module in an analysis.
+type placed struct {
+	name     string // Name as declared
+	module   string // layout.Key of the module below the analysis's common root
+	location string // File:line
+}
+
+// moduleIndex maps the normalized names of an analysis's types and
+// functions to the modules declaring them.
+type moduleIndex struct {
+	types, funcs map[string]placed
+
+	// modules is the number of distinct modules
+	modules int
+}
+
+// misplacement is an element generated in a different module than the
+// source declares it in.
+type misplacement struct {
+	kind        string
+	source, gen placed
+}
+
+// indexModules indexes an analysis's elements by module. Modules are taken
+// relative to the root every element lies within, such as the base package
+// of a Java project, so layouts compare across languages.
+func (c *Comparator) indexModules(a *semantic.Analysis) moduleIndex {
+	var modules []string
+	for _, t := range a.Types {
+		modules = append(modules, t.PackagePath)
+	}
+	for _, f := range a.Functions {
+		modules = append(modules, f.PackagePath)
+	}
+	root := layout.CommonRoot(modules)
+
+	idx := moduleIndex{types: make(map[string]placed), funcs: make(map[string]placed)}
+	distinct := make(map[string]bool)
+	add := func(m map[string]placed, name, module, file string, line int) {
+		p := placed{name: name, module: layout.Key(layout.Relative(root, module)), location: fmt.Sprintf("%s:%d", file, line)}
+		m[c.normalizer.normalizeName(name)] = p
+		distinct[p.module] = true
+	}
+	for _, t := range a.Types {
+		add(idx.types, t.Name, t.PackagePath, t.Location.File, t.Location.StartLine)
+	}
+	for _, f := range a.Functions {
+		add(idx.funcs, f.Name, f.PackagePath, f.Location.File, f.Location.StartLine)
+	}
+	idx.modules = len(distinct)
+	return idx
+}
+
+// comparePlacement finds the source elements generated in a different
+// module, and returns how many elements were compared. Placement is only
+// compared when the source spans more than one module.
+func (c *Comparator) comparePlacement(source, gen *semantic.Analysis) ([]misplacement, int) {
+	src := c.indexModules(source)
+	if src.modules < 2 {
+		return nil, 0
+	}
+	generated := c.indexModules(gen)
+
+	var misplaced []misplacement
+	compared := 0
+	for _, group := range []struct {
+		kind     string
+		src, gen map[string]placed
+	}{
+		{"type", src.types, generated.types},
+		{"function", src.funcs, generated.funcs},
+	} {
+		for name, s := range group.src {
+			g, ok := group.gen[name]
+			if !ok {
+				continue
+			}
+			compared++
+			if s.module != g.module {
+				misplaced = append(misplaced, misplacement{kind: group.kind, source: s, gen: g})
+			}
+		}
+	}
+	sort.Slice(misplaced, func(i, j int) bool {
+		if misplaced[i].kind != misplaced[j].kind {
+			return misplaced[i].kind > misplaced[j].kind
+		}
+		return misplaced[i].source.name < misplaced[j].source.name
+	})
+	return misplaced, compared
+}
+
+// placementGap reports an element generated in the wrong module.
+func placementGap(m misplacement, sourceLang, lang string) ParityGap {
+	return ParityGap{
+		Dimension: "structural",
+		Severity:  "low",
+		SourceItem: ItemReference{
+			Type:     m.kind,
+			Name:     m.source.name,
+			Location: m.source.location,
+			Language: sourceLang,
+		},
+		GeneratedItem: &ItemReference{
+			Type:     m.kind,
+			Name:     m.gen.name,
+			Location: m.gen.location,
+			Language: lang,
+		},
+		Discrepancy:  fmt.Sprintf("declared in %s, generated in %s", moduleName(m.source.module), moduleName(m.gen.module)),
+		SuggestedFix: fmt.Sprintf("Move %s '%s' to %s in %s", m.kind, m.gen.name, moduleName(m.source.module), lang),
+	}
+}
+
+func moduleName(module string) string {
+	if module == "" {
+		return "the root module"
+	}
+	return "module " + module
+}
@@ -6,102 +6,36 @@ import (
 
 	"github.com/kon1790/rpg/internal/importer/semantic"
 	"github.com/kon1790/rpg/internal/importer/treesitter"
+	"github.com/kon1790/rpg/internal/typeexpr"
 )
 
 // Normalizer provides cross-language normalization for comparison
 type Normalizer struct {
-	// Type vocabulary mapping: language-specific types -> normalized types
+	// Type vocabulary mapping: canonical primitives -> normalized types
 	typeVocab map[string]string
+
+	// Renamed identifiers -> the spec names they stand for
+	aliases map[string]string
 }
 
 // NewNormalizer creates a new normalizer
 func NewNormalizer() *Normalizer {
 	return &Normalizer{
 		typeVocab: buildTypeVocabulary(),
+		aliases:   make(map[string]string),
 	}
 }
 
-// buildTypeVocabulary builds the common type vocabulary
+// buildTypeVocabulary builds the common type vocabulary. Language spellings
+// are resolved to canonical primitives by the type grammar; this only names
+// the ones whose label differs.
 func buildTypeVocabulary() map[string]string {
 	return map[string]string{
-		// Integers
-		"int":     "integer",
-		"int8":    "integer",
-		"int16":   "integer",
-		"int32":   "integer",
-		"int64":   "integer",
-		"uint":    "integer",
-		"uint8":   "integer",
-		"uint16":  "integer",
-		"uint32":  "integer",
-		"uint64":  "integer",
-		"i8":      "integer",
-		"i16":     "integer",
-		"i32":     "integer",
-		"i64":     "integer",
-		"u8":      "integer",
-		"u16":     "integer",
-		"u32":     "integer",
-		"u64":     "integer",
-		"isize":   "integer",
-		"usize":   "integer",
-		"Integer": "integer",
-		"Long":    "integer",
-		"Short":   "integer",
-		"number":  "number",
-
-		// Floats
-		"float":   "float",
-		"float32": "float",
-		"float64": "float",
-		"f32":     "float",
-		"f64":     "float",
-		"Float":   "float",
-		"Double":  "float",
-		"double":  "float",
-
-		// Strings
-		"string":       "string",
-		"String":       "string",
-		"&str":         "string",
-		"str":          "string",
-		"StringBuilder": "string",
-
-		// Booleans
-		"bool":    "boolean",
-		"boolean": "boolean",
-		"Boolean": "boolean",
-
-		// Void/Unit
-		"void":   "void",
-		"()":     "void",
-		"None":   "void",
-		"null":   "null",
-		"nil":    "null",
-
-		// Byte
-		"byte":  "byte",
-		"Byte":  "byte",
-		"[]byte": "bytes",
-		"bytes": "bytes",
-
-		// Any/Object
-		"any":         "any",
-		"interface{}": "any",
-		"Object":      "any",
-		"object":      "any",
-		"dynamic":     "any",
-
-		// Error
-		"error":     "error",
-		"Error":     "error",
-		"Exception": "error",
-		"Result":    "result",
-
-		// Context
-		"context.Context": "context",
-		"Context":         "context",
-		"CancellationToken": "context",
+		typeexpr.Int:     "integer",
+		typeexpr.Int64:   "integer",
+		typeexpr.Float:   "float",
+		typeexpr.Decimal: "float",
+		typeexpr.Bool:    "boolean",
 	}
 }
 
@@ -127,9 +61,7 @@ func (n *Normalizer) NormalizeFunctions(funcs []semantic.ResolvedFunction, ignor
 		}
 
 		// Normalize return types
-		for _, rt := range fn.ResolvedReturnTypes {
-			sig.Returns = append(sig.Returns, n.normalizeType(rt))
-		}
+		sig.Returns = n.normalizeReturns(fn.ResolvedReturnTypes)
 
 		normalized = append(normalized, sig)
 	}
@@ -159,12 +91,37 @@ func (n *Normalizer) NormalizeTypes(types []semantic.ResolvedType, ignorePrivate
 			nt.Fields = append(nt.Fields, n.normalizeField(f))
 		}
 
+		// Normalize method signatures
+		for _, m := range t.MethodSignatures {
+			sig := NormalizedSignature{Name: n.normalizeName(m.Name), IsPublic: m.IsExported}
+			for _, p := range m.Parameters {
+				sig.Parameters = append(sig.Parameters, n.normalizeParam(p))
+			}
+			sig.Returns = n.normalizeReturns(m.ReturnTypes)
+			nt.Signatures = append(nt.Signatures, sig)
+		}
+
 		normalized = append(normalized, nt)
 	}
 
 	return normalized
 }
 
+// normalizeReturns normalizes return types, dropping the void types of
+// languages that spell out returning nothing and Go's error results, which
+// other languages raise instead
+func (n *Normalizer) normalizeReturns(types []string) []string {
+	var returns []string
+	for _, rt := range types {
+		switch strings.TrimSpace(rt) {
+		case "void", "()", "None", "error":
+			continue
+		}
+		returns = append(returns, n.normalizeType(rt))
+	}
+	return returns
+}
+
 // normalizeParam normalizes a parameter
 func (n *Normalizer) normalizeParam(p semantic.ResolvedParameter) NormalizedParam {
 	baseType, isPtr, isArray, isMap := n.parseType(p.ResolvedType)
@@ -191,6 +148,11 @@ func (n *Normalizer) normalizeField(f semantic.ResolvedField) NormalizedField {
 
 // normalizeName normalizes an identifier name to snake_case
 func (n *Normalizer) normalizeName(name string) string {
+	if spec, ok := n.aliases[name]; ok {
+		name = spec
+	}
+	name = unescapeIdentifier(name)
+
 	// Convert camelCase and PascalCase to snake_case
 	var result strings.Builder
 	for i, r := range name {
@@ -204,84 +166,68 @@ func (n *Normalizer) normalizeName(name string) string {
 
 // normalizeType normalizes a type name to common vocabulary
 func (n *Normalizer) normalizeType(typeName string) string {
-	// Strip modifiers and get base type
-	base, _, _, _ := n.parseType(typeName)
-
-	// Look up in vocabulary
-	if normalized, ok := n.typeVocab[base]; ok {
-		return normalized
+	expr, err := typeexpr.Parse(typeName)
+	if err != nil {
+		// Outside the grammar; compare by name
+		return n.normalizeName(strings.TrimSpace(typeName))
 	}
 
-	// For custom types, just normalize the name
-	return n.normalizeName(base)
-}
-
-// parseType parses a type string to extract modifiers
-func (n *Normalizer) parseType(typeName string) (baseType string, isPtr bool, isArray bool, isMap bool) {
-	typeName = strings.TrimSpace(typeName)
-
-	// Check for pointer/reference
-	if strings.HasPrefix(typeName, "*") || strings.HasPrefix(typeName, "&") {
-		isPtr = true
-		typeName = typeName[1:]
-	}
-
-	// Check for array/slice
-	if strings.HasPrefix(typeName, "[]") {
-		isArray = true
-		typeName = typeName[2:]
-	}
-	if strings.HasPrefix(typeName, "List<") || strings.HasPrefix(typeName, "Vec<") ||
-		strings.HasSuffix(typeName, "[]") || strings.HasPrefix(typeName, "Array<") {
-		isArray = true
-		// Extract inner type
-		typeName = extractGenericType(typeName)
-	}
-
-	// Check for map/dictionary
-	if strings.HasPrefix(typeName, "map[") || strings.HasPrefix(typeName, "Map<") ||
-		strings.HasPrefix(typeName, "HashMap<") || strings.HasPrefix(typeName, "Dictionary<") ||
-		strings.HasPrefix(typeName, "dict[") {
-		isMap = true
-		// For maps, we just note it's a map
-		baseType = "map"
-		return
-	}
-
-	// Check for Optional/Nullable
-	if strings.HasPrefix(typeName, "Optional<") || strings.HasPrefix(typeName, "Option<") ||
-		strings.HasSuffix(typeName, "?") {
-		isPtr = true // Treat optional as nullable
-		typeName = extractGenericType(typeName)
-		if strings.HasSuffix(typeName, "?") {
-			typeName = typeName[:len(typeName)-1]
+	// Strip modifiers and get base type
+	for {
+		switch expr.Kind {
+		case typeexpr.Pointer, typeexpr.Optional, typeexpr.List, typeexpr.Set:
+			expr = expr.Args[0]
+			continue
+		case typeexpr.Primitive:
+			if normalized, ok := n.typeVocab[expr.Name]; ok {
+				return normalized
+			}
+			return expr.Name
+		case typeexpr.Named:
+			// For custom types, just normalize the name
+			name := expr.Name
+			if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+				name = name[i+1:]
+			}
+			return n.normalizeName(name)
+		case typeexpr.Map:
+			return "map"
+		case typeexpr.Result:
+			return "result"
+		case typeexpr.Tuple:
+			return "tuple"
+		case typeexpr.Func:
+			return "func"
 		}
+		return "union"
 	}
-
-	baseType = typeName
-	return
 }
 
-// extractGenericType extracts the type from a generic like List<T>
-func extractGenericType(typeName string) string {
-	// Simple extraction: find content between < and >
-	start := strings.Index(typeName, "<")
-	end := strings.LastIndex(typeName, ">")
-	if start != -1 && end > start {
-		inner := typeName[start+1 : end]
-		// If there's a comma (like Dict<K,V>), take first part or simplify
-		if comma := strings.Index(inner, ","); comma != -1 {
-			return strings.TrimSpace(inner[:comma])
+// parseType parses a type string to extract modifiers
+func (n *Normalizer) parseType(typeName string) (baseType string, isPtr bool, isArray bool, isMap bool) {
+	expr, err := typeexpr.Parse(typeName)
+	if err != nil {
+		return strings.TrimSpace(typeName), false, false, false
+	}
+
+	for {
+		switch expr.Kind {
+		case typeexpr.Pointer, typeexpr.Optional:
+			// Treat optional as nullable
+			isPtr = true
+		case typeexpr.List, typeexpr.Set:
+			isArray = true
+		case typeexpr.Map:
+			// For maps, we just note it's a map
+			return "map", isPtr, isArray, true
+		default:
+			if expr.Kind == typeexpr.Primitive || expr.Kind == typeexpr.Named {
+				return expr.Name, isPtr, isArray, isMap
+			}
+			return expr.String(), isPtr, isArray, isMap
 		}
-		return strings.TrimSpace(inner)
-	}
-
-	// Handle array suffix like int[]
-	if strings.HasSuffix(typeName, "[]") {
-		return typeName[:len(typeName)-2]
+		expr = expr.Args[0]
 	}
-
-	return typeName
 }
 
 // SignatureMatch checks if two normalized signatures match
@@ -368,14 +314,48 @@ func TypeMatch(a, b NormalizedType, strict bool) (bool, []string) {
 		}
 	}
 
-	// Method count
-	if len(a.Methods) != len(b.Methods) && strict {
+	// Compare methods by name and signature when both analyzers resolved
+	// them; otherwise only their count
+	if len(a.Signatures) > 0 && len(b.Signatures) > 0 {
+		diffs = append(diffs, methodDiffs(a.Signatures, b.Signatures, strict)...)
+	} else if len(a.Methods) != len(b.Methods) && strict {
 		diffs = append(diffs, "method count mismatch")
 	}
 
 	return len(diffs) == 0, diffs
 }
 
+// methodDiffs compares two types' methods by name, then each pair's
+// signatures.
+func methodDiffs(a, b []NormalizedSignature, strict bool) []string {
+	var diffs []string
+
+	bMethods := make(map[string]NormalizedSignature)
+	for _, m := range b {
+		bMethods[m.Name] = m
+	}
+	aMethods := make(map[string]bool)
+	for _, am := range a {
+		aMethods[am.Name] = true
+		bm, ok := bMethods[am.Name]
+		if !ok {
+			diffs = append(diffs, "missing method: "+am.Name)
+			continue
+		}
+		_, sigDiffs := SignatureMatch(am, bm, strict)
+		for _, d := range sigDiffs {
+			diffs = append(diffs, "method "+am.Name+": "+d)
+		}
+	}
+	for _, bm := range b {
+		if !aMethods[bm.Name] {
+			diffs = append(diffs, "extra method: "+bm.Name)
+		}
+	}
+
+	return diffs
+}
+
 // LanguageNaming provides language-specific naming patterns
 type LanguageNaming struct {
 	// Case convention: camelCase, PascalCase, snake_case, SCREAMING_CASE
@@ -416,6 +396,13 @@ func GetLanguageNaming(lang treesitter.Language) LanguageNaming {
 			FieldCase:    "camelCase",
 			ConstCase:    "SCREAMING_CASE",
 		}
+	case treesitter.LanguageProtobuf:
+		return LanguageNaming{
+			FunctionCase: "PascalCase",
+			TypeCase:     "PascalCase",
+			FieldCase:    "snake_case",
+			ConstCase:    "SCREAMING_CASE",
+		}
 	case treesitter.LanguageTypeScript:
 		return LanguageNaming{
 			FunctionCase: "camelCase",
@@ -45,6 +45,7 @@ type LanguageResult struct {
 	MissingFuncs []string            `json:"missingFuncs,omitempty"`
 	TypeErrors   []TypeMismatch      `json:"typeErrors,omitempty"`
 	SigErrors    []SignatureMismatch `json:"sigErrors,omitempty"`
+	Misplaced    []string            `json:"misplaced,omitempty"` // Elements generated in a different module
 }
 
 // ParityGap represents a specific parity issue
@@ -79,10 +80,10 @@ type ItemReference struct {
 
 // TypeMismatch describes a type definition mismatch
 type TypeMismatch struct {
-	TypeName    string        `json:"typeName"`
-	SourceType  TypeInfo      `json:"sourceType"`
-	GenType     TypeInfo      `json:"genType"`
-	Differences []string      `json:"differences"`
+	TypeName    string   `json:"typeName"`
+	SourceType  TypeInfo `json:"sourceType"`
+	GenType     TypeInfo `json:"genType"`
+	Differences []string `json:"differences"`
 }
 
 // TypeInfo summarizes a type definition
@@ -95,10 +96,10 @@ type TypeInfo struct {
 
 // SignatureMismatch describes a function signature mismatch
 type SignatureMismatch struct {
-	FuncName     string   `json:"funcName"`
-	SourceSig    string   `json:"sourceSig"`
-	GenSig       string   `json:"genSig"`
-	Differences  []string `json:"differences"`
+	FuncName    string   `json:"funcName"`
+	SourceSig   string   `json:"sourceSig"`
+	GenSig      string   `json:"genSig"`
+	Differences []string `json:"differences"`
 }
 
 // ComparisonConfig configures the parity comparison
@@ -143,12 +144,12 @@ func DefaultConfig() ComparisonConfig {
 
 // NormalizedSignature represents a cross-language normalized function signature
 type NormalizedSignature struct {
-	Name       string              `json:"name"`
-	Parameters []NormalizedParam   `json:"parameters"`
-	Returns    []string            `json:"returns"`
-	IsAsync    bool                `json:"isAsync"`
-	IsPublic   bool                `json:"isPublic"`
-	Complexity int                 `json:"complexity"`
+	Name       string            `json:"name"`
+	Parameters []NormalizedParam `json:"parameters"`
+	Returns    []string          `json:"returns"`
+	IsAsync    bool              `json:"isAsync"`
+	IsPublic   bool              `json:"isPublic"`
+	Complexity int               `json:"complexity"`
 }
 
 // NormalizedParam represents a normalized parameter
@@ -162,12 +163,13 @@ type NormalizedParam struct {
 
 // NormalizedType represents a cross-language normalized type
 type NormalizedType struct {
-	Name       string            `json:"name"`
-	Kind       string            `json:"kind"` // struct, interface, enum, alias
-	Fields     []NormalizedField `json:"fields"`
-	Methods    []string          `json:"methods"`
-	Implements []string          `json:"implements"`
-	IsPublic   bool              `json:"isPublic"`
+	Name       string                `json:"name"`
+	Kind       string                `json:"kind"` // struct, interface, enum, alias
+	Fields     []NormalizedField     `json:"fields"`
+	Methods    []string              `json:"methods"`
+	Signatures []NormalizedSignature `json:"signatures,omitempty"` // Method signatures, when the analyzer resolves them
+	Implements []string              `json:"implements"`
+	IsPublic   bool                  `json:"isPublic"`
 }
 
 // NormalizedField represents a normalized field
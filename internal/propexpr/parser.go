@@ -0,0 +1,255 @@
+package propexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// token is an identifier, number, string or operator.
+type token struct {
+	text string
+	kind byte // 'i'dent, 'n'umber, 's'tring or 'o'perator
+}
+
+// wordOperators are the spellings of the logical operators as words.
+var wordOperators = map[string]string{"and": "&&", "or": "||", "not": "!"}
+
+// tokenize splits an expression into tokens. String literals are unquoted.
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			if op, ok := wordOperators[s[i:j]]; ok {
+				tokens = append(tokens, token{op, 'o'})
+			} else {
+				tokens = append(tokens, token{s[i:j], 'i'})
+			}
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.' && j+1 < len(s) && unicode.IsDigit(rune(s[j+1]))) {
+				j++
+			}
+			tokens = append(tokens, token{s[i:j], 'n'})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && rune(s[j]) != c {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			text := s[i+1 : j]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(s[i : j+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string %s", s[i:j+1])
+				}
+				text = unquoted
+			}
+			tokens = append(tokens, token{text, 's'})
+			i = j + 1
+		default:
+			op := ""
+			for _, candidate := range []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")", ",", "."} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q", c)
+			}
+			tokens = append(tokens, token{op, 'o'})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return token{}
+}
+
+// accept consumes the operator op if it is next.
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == 'o' && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("expected %q at end of expression", op)
+		}
+		return fmt.Errorf("expected %q, found %q", op, p.peek().text)
+	}
+	return nil
+}
+
+// binary parses a left-associative level of binary operators.
+func (p *parser) binary(ops []string, operand func() (*Expr, error)) (*Expr, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		matched := ""
+		for _, op := range ops {
+			if p.accept(op) {
+				matched = op
+				break
+			}
+		}
+		if matched == "" {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Kind: Binary, Op: matched, Args: []*Expr{left, right}}
+	}
+}
+
+func (p *parser) parseOr() (*Expr, error) {
+	return p.binary([]string{"||"}, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (*Expr, error) {
+	return p.binary([]string{"&&"}, p.parseComparison)
+}
+
+// parseComparison parses a comparison; comparisons do not chain.
+func (p *parser) parseComparison() (*Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			if t := p.peek(); t.kind == 'o' && Comparison(t.text) {
+				return nil, fmt.Errorf("comparisons cannot be chained; join them with &&")
+			}
+			return &Expr{Kind: Binary, Op: op, Args: []*Expr{left, right}}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (*Expr, error) {
+	return p.binary([]string{"+", "-"}, p.parseMultiplicative)
+}
+
+func (p *parser) parseMultiplicative() (*Expr, error) {
+	return p.binary([]string{"*", "/"}, p.parseUnary)
+}
+
+func (p *parser) parseUnary() (*Expr, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &Expr{Kind: Unary, Op: op, Args: []*Expr{operand}}, nil
+		}
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a primary followed by field selections.
+func (p *parser) parsePostfix() (*Expr, error) {
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(".") {
+		t := p.peek()
+		if t.kind != 'i' {
+			return nil, fmt.Errorf("expected a field name after %q", e.String()+".")
+		}
+		p.pos++
+		e = &Expr{Kind: Field, Name: t.text, Args: []*Expr{e}}
+	}
+	return e, nil
+}
+
+func (p *parser) parsePrimary() (*Expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case 'n':
+		p.pos++
+		if strings.Contains(t.text, ".") {
+			return &Expr{Kind: Literal, Name: t.text, Type: "float"}, nil
+		}
+		return &Expr{Kind: Literal, Name: t.text, Type: "int"}, nil
+	case 's':
+		p.pos++
+		return &Expr{Kind: Literal, Name: t.text, Type: "string"}, nil
+	case 'i':
+		p.pos++
+		if t.text == "true" || t.text == "false" {
+			return &Expr{Kind: Literal, Name: t.text, Type: "bool"}, nil
+		}
+		if !p.accept("(") {
+			return &Expr{Kind: Ident, Name: t.text}, nil
+		}
+		call := &Expr{Kind: Call, Name: t.text}
+		if p.accept(")") {
+			return call, nil
+		}
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+			if p.accept(")") {
+				return call, nil
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	case 'o':
+		if p.accept("(") {
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		}
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	return nil, fmt.Errorf("unexpected end of expression")
+}
@@ -0,0 +1,123 @@
+// Package propexpr implements the expression grammar of spec properties:
+// boolean invariants over a spec's functions, such as
+// "decode(encode(x)) == x" or "len(result) <= len(url)".
+//
+//	a || b   a && b   !a              (also: a or b, a and b, not a)
+//	a == b   a != b   a < b   a <= b   a > b   a >= b
+//	a + b    a - b    a * b   a / b    -a
+//	f(a, b)  x.field  (a)
+//	42  2.5  "text"  true  false
+//
+// Calls name spec functions or the builtin len. The code generator
+// resolves the names and renders the expression in each language.
+package propexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind identifies the form of an expression.
+type Kind int
+
+const (
+	// Ident is a variable; Name holds its name
+	Ident Kind = iota
+	// Literal is a constant; Name holds its value and Type its pseudo-type
+	Literal
+	// Call calls the function Name with Args
+	Call
+	// Field selects the field Name of Args[0]
+	Field
+	// Unary applies Op to Args[0]
+	Unary
+	// Binary applies Op to Args[0] and Args[1]
+	Binary
+)
+
+// Expr is a node of an expression tree.
+type Expr struct {
+	Kind Kind
+	Name string
+	Op   string
+	Args []*Expr
+
+	// Type is a literal's pseudo-type: int, float, string or bool
+	Type string
+}
+
+// Comparison reports whether op compares its operands.
+func Comparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// Logical reports whether op combines booleans.
+func Logical(op string) bool {
+	return op == "&&" || op == "||" || op == "!"
+}
+
+// Parse parses a property expression.
+func Parse(s string) (*Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return e, nil
+}
+
+// Walk calls fn for e and each of its subexpressions, parents first.
+func Walk(e *Expr, fn func(*Expr)) {
+	fn(e)
+	for _, arg := range e.Args {
+		Walk(arg, fn)
+	}
+}
+
+// String renders the expression in canonical syntax, parenthesizing
+// nested operations.
+func (e *Expr) String() string {
+	switch e.Kind {
+	case Literal:
+		if e.Type == "string" {
+			return strconv.Quote(e.Name)
+		}
+		return e.Name
+	case Call:
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = arg.String()
+		}
+		return e.Name + "(" + strings.Join(args, ", ") + ")"
+	case Field:
+		return e.Args[0].operand() + "." + e.Name
+	case Unary:
+		return e.Op + e.Args[0].operand()
+	case Binary:
+		return e.Args[0].operand() + " " + e.Op + " " + e.Args[1].operand()
+	}
+	return e.Name
+}
+
+// operand renders an operand, parenthesizing operations.
+func (e *Expr) operand() string {
+	if e.Kind == Unary || e.Kind == Binary {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
@@ -0,0 +1,75 @@
+package propexpr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"decode(encode(x)) == x", "decode(encode(x)) == x"},
+		{"len(result) <= len(url)", "len(result) <= len(url)"},
+		{"a && b || c", "(a && b) || c"},
+		{"a || b && c", "a || (b && c)"},
+		{"not (a and b)", "!(a && b)"},
+		{"total(items) >= 0 && total(items) < 100.5", "(total(items) >= 0) && (total(items) < 100.5)"},
+		{"a + b * c == d", "(a + (b * c)) == d"},
+		{"-x < 0", "(-x) < 0"},
+		{"parse(s).host != \"\"", "parse(s).host != \"\""},
+		{"greet('Ann') == \"Hi, \\\"Ann\\\"\"", "greet(\"Ann\") == \"Hi, \\\"Ann\\\"\""},
+		{"now() == true", "now() == true"},
+	}
+
+	for _, tt := range tests {
+		e, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.input, err)
+			continue
+		}
+		if result := e.String(); result != tt.expected {
+			t.Errorf("Parse(%q) = %s, expected %s", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", "empty expression"},
+		{"a == b == c", "cannot be chained"},
+		{"f(a, ", "unexpected end"},
+		{"(a", "expected \")\""},
+		{"a = b", "unexpected '='"},
+		{"\"open", "unterminated string"},
+		{"x.", "expected a field name"},
+		{"result length <= input length", "unexpected \"length\""},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Parse(%q) error = %v, expected it to mention %q", tt.input, err, tt.expected)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	e, err := Parse("decode(encode(x)) == x")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	var visited []string
+	Walk(e, func(n *Expr) {
+		if n.Kind == Call || n.Kind == Ident {
+			visited = append(visited, n.Name)
+		}
+	})
+	if strings.Join(visited, " ") != "decode encode x x" {
+		t.Errorf("Expected decode encode x x, got %v", visited)
+	}
+}
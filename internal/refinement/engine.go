@@ -15,11 +15,11 @@ import (
 
 // Engine orchestrates the iterative refinement loop
 type Engine struct {
-	config       LoopConfig
-	comparator   *parity.Comparator
-	registry     *semantic.AnalyzerRegistry
-	cache        *AnalysisCache
-	convergence  *ConvergenceTracker
+	config      LoopConfig
+	comparator  *parity.Comparator
+	registry    *semantic.AnalyzerRegistry
+	cache       *AnalysisCache
+	convergence *ConvergenceTracker
 }
 
 // NewEngine creates a new refinement engine
@@ -76,6 +76,11 @@ func (e *Engine) AnalyzeGenerated(ctx context.Context, projectPath string, lang
 		return nil, fmt.Errorf("analyzing generated %s: %w", lang, err)
 	}
 
+	// Match identifiers the generator escaped back to their spec names
+	if mappings, err := parity.LoadIdentifierMap(projectPath); err == nil {
+		e.comparator.AddIdentifierMap(mappings)
+	}
+
 	// Cache
 	e.cache.Generated[string(lang)] = analysis
 	return analysis, nil
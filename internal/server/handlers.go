@@ -8,13 +8,20 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/kon1790/rpg/internal/generator"
 	"github.com/kon1790/rpg/internal/github"
 	"github.com/kon1790/rpg/internal/importer"
+	"github.com/kon1790/rpg/internal/importer/dbschema"
+	"github.com/kon1790/rpg/internal/importer/graphql"
+	"github.com/kon1790/rpg/internal/importer/openapi"
+	"github.com/kon1790/rpg/internal/importer/protobuf"
 	"github.com/kon1790/rpg/internal/importer/semantic"
 	"github.com/kon1790/rpg/internal/importer/treesitter"
 	"github.com/kon1790/rpg/internal/languages"
 	"github.com/kon1790/rpg/internal/parity"
 	"github.com/kon1790/rpg/internal/refinement"
+	"github.com/kon1790/rpg/internal/specdiff"
+	"github.com/kon1790/rpg/internal/specparser"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -208,6 +215,7 @@ type SemanticType struct {
 	IsPublic             bool              `json:"isPublic"`
 	Fields               []SemanticField   `json:"fields,omitempty"`
 	Methods              []string          `json:"methods,omitempty"`
+	MethodSignatures     []string          `json:"methodSignatures,omitempty"`
 	ImplementsInterfaces []string          `json:"implementsInterfaces,omitempty"`
 	Generic              []string          `json:"generic,omitempty"`
 	DocComment           string            `json:"docComment,omitempty"`
@@ -309,6 +317,7 @@ type LanguageParityResult struct {
 	ByDimension  DimensionScoresOutput `json:"byDimension"`
 	MissingTypes []string              `json:"missingTypes,omitempty"`
 	MissingFuncs []string              `json:"missingFuncs,omitempty"`
+	Misplaced    []string              `json:"misplaced,omitempty"`
 }
 
 // SemanticParityGap represents a specific parity issue
@@ -437,6 +446,103 @@ type FileContent struct {
 	Size    int    `json:"size"`
 }
 
+// =============================================================================
+// SPEC EVOLUTION - Semantic comparison of spec revisions
+// =============================================================================
+
+// DiffSpecsInput contains the two spec revisions to compare
+type DiffSpecsInput struct {
+	OldSpecPath string `json:"oldSpecPath,omitempty" jsonschema_description:"Path to the old spec revision (use with newSpecPath)"`
+	NewSpecPath string `json:"newSpecPath,omitempty" jsonschema_description:"Path to the new spec revision (use with oldSpecPath)"`
+	SpecPath    string `json:"specPath,omitempty" jsonschema_description:"Path to a spec tracked in git (use with oldRef/newRef instead of two paths)"`
+	OldRef      string `json:"oldRef,omitempty" jsonschema_description:"Git ref (branch, tag, or commit) of the old revision of specPath"`
+	NewRef      string `json:"newRef,omitempty" jsonschema_description:"Git ref of the new revision of specPath (defaults to the working tree)"`
+}
+
+// DiffSpecsOutput contains the structured diff and a Markdown changelog
+type DiffSpecsOutput struct {
+	Diff      *specdiff.SpecDiff `json:"diff"`
+	Changelog string             `json:"changelog"`
+}
+
+// RegenerateSourceFromSpecInput contains the input for incremental regeneration
+type RegenerateSourceFromSpecInput struct {
+	SpecPath    string `json:"specPath" jsonschema:"required" jsonschema_description:"Path to the (updated) spec file"`
+	Language    string `json:"language" jsonschema:"required" jsonschema_description:"Target language ID (use list_languages to see options)"`
+	OutputDir   string `json:"outputDir,omitempty" jsonschema_description:"Directory holding the previously generated project (defaults to outputDir/specName/language)"`
+	TemplateDir string `json:"templateDir,omitempty" jsonschema_description:"Directory of custom <language>/<construct>.tmpl templates overriding the defaults (defaults to the server's -templates directory)"`
+}
+
+// RegenerateSourceFromSpecOutput reports per-file merge results and conflicts
+type RegenerateSourceFromSpecOutput struct {
+	OutputDir string                      `json:"outputDir"`
+	Files     []generator.RegeneratedFile `json:"files"`
+	Conflicts []generator.MergeConflict   `json:"conflicts,omitempty"`
+	Summary   string                      `json:"summary"`
+}
+
+// DumpTemplatesInput contains the directory to write the default templates to
+type DumpTemplatesInput struct {
+	OutputDir string `json:"outputDir" jsonschema:"required" jsonschema_description:"Directory to write <language>/<construct>.tmpl files to"`
+}
+
+// DumpTemplatesOutput lists the template files written
+type DumpTemplatesOutput struct {
+	Files   []string `json:"files"`
+	Summary string   `json:"summary"`
+}
+
+// =============================================================================
+// SCHEMA IMPORT - Deterministic spec generation from API schemas
+// =============================================================================
+
+// ImportSpecFromSchemaInput contains the schema file to convert into a spec
+type ImportSpecFromSchemaInput struct {
+	SchemaPath string `json:"schemaPath" jsonschema:"required" jsonschema_description:"Path to the schema file (e.g., openapi.yaml, service.proto, schema.graphql, order.schema.json, schema.sql)"`
+	Format     string `json:"format,omitempty" jsonschema_description:"Schema format: openapi, protobuf, graphql, jsonschema or sql (auto-detected from the file when omitted)"`
+	OutputPath string `json:"outputPath,omitempty" jsonschema_description:"Optional path to write the generated .spec.md file"`
+}
+
+// ImportSpecFromSchemaOutput contains the parsed spec and its Markdown rendering
+type ImportSpecFromSchemaOutput struct {
+	Format        string                   `json:"format"`
+	Spec          *specparser.SpecAnalysis `json:"spec"`
+	SpecMarkdown  string                   `json:"specMarkdown"`
+	OutputPath    string                   `json:"outputPath,omitempty"`
+	SpecGenerated bool                     `json:"specGenerated"`
+	Summary       string                   `json:"summary"`
+}
+
+// ImportDataModelInput contains the directory or file whose data model is imported
+type ImportDataModelInput struct {
+	SourcePath string `json:"sourcePath" jsonschema:"required" jsonschema_description:"Path to a project directory, a SQL DDL file, or an ORM model source file"`
+	Name       string `json:"name,omitempty" jsonschema_description:"Optional name for the generated spec (defaults to the directory or file name)"`
+	OutputPath string `json:"outputPath,omitempty" jsonschema_description:"Optional path to write the generated .spec.md file"`
+}
+
+// ImportDataModelOutput contains the imported data model and its Markdown rendering
+type ImportDataModelOutput struct {
+	Spec          *specparser.SpecAnalysis `json:"spec"`
+	SpecMarkdown  string                   `json:"specMarkdown"`
+	OutputPath    string                   `json:"outputPath,omitempty"`
+	SpecGenerated bool                     `json:"specGenerated"`
+	Summary       string                   `json:"summary"`
+}
+
+// ExportJSONSchemaInput contains the spec whose types are exported
+type ExportJSONSchemaInput struct {
+	SpecPath   string `json:"specPath" jsonschema:"required" jsonschema_description:"Path to the markdown spec file"`
+	OutputPath string `json:"outputPath,omitempty" jsonschema_description:"Optional path to write the JSON Schema document"`
+}
+
+// ExportJSONSchemaOutput contains the exported JSON Schema document
+type ExportJSONSchemaOutput struct {
+	Schema     string `json:"schema"`
+	OutputPath string `json:"outputPath,omitempty"`
+	Written    bool   `json:"written"`
+	Summary    string `json:"summary"`
+}
+
 // Tool handlers
 
 func (s *Server) handleListLanguages(ctx context.Context, req *mcp.CallToolRequest, input ListLanguagesInput) (*mcp.CallToolResult, ListLanguagesOutput, error) {
@@ -800,6 +906,7 @@ func (s *Server) performSemanticAnalysis(sourcePath, language string) *SemanticS
 			Kind:                 string(t.Kind),
 			IsPublic:             t.IsPublic,
 			Methods:              t.Methods,
+			MethodSignatures:     methodSignatures(t),
 			ImplementsInterfaces: t.ImplementsInterfaces,
 			Generic:              t.Generic,
 			DocComment:           t.DocComment,
@@ -1053,14 +1160,16 @@ func (s *Server) buildAIAnalysisPrompt(
 	sb.WriteString("- **Parameters**: List of parameters with types and descriptions\n")
 	sb.WriteString("- **Returns**: Return type and what it represents\n")
 	sb.WriteString("- **Logic**: Step-by-step description of what the function does\n")
-	sb.WriteString("- **HTTP Mapping**: If it's an HTTP handler, specify the route and method\n\n")
+	sb.WriteString("- **HTTP Mapping**: If it's an HTTP handler, specify the route and method\n")
+	sb.WriteString("- **Module**: The directory it is declared in, relative to the source root (omit for the root)\n\n")
 
 	sb.WriteString("### Types Section\n")
 	sb.WriteString("For EACH type/class/struct:\n")
 	sb.WriteString("- **Name**: The type name\n")
 	sb.WriteString("- **Fields**: All fields with their types\n")
-	sb.WriteString("- **Methods**: Associated methods\n")
-	sb.WriteString("- **Usage**: How this type is used in the system\n\n")
+	sb.WriteString("- **Methods**: Associated methods; list an interface's as signatures such as `- \x60find(id: string): User\x60 - Looks up a user`\n")
+	sb.WriteString("- **Usage**: How this type is used in the system\n")
+	sb.WriteString("- **Module**: The directory it is declared in, relative to the source root (omit for the root)\n\n")
 
 	sb.WriteString("### Configuration Section\n")
 	sb.WriteString("- Default values (ports, addresses, etc.)\n")
@@ -1114,6 +1223,9 @@ func (s *Server) generateAISpec(
 	sb.WriteString("## Dependencies\n\n")
 	sb.WriteString("*[AI: List external libraries and their usage]*\n\n")
 
+	sb.WriteString("## Properties\n\n")
+	sb.WriteString("*[AI: State invariants the code relies on as expressions over its functions, such as `decode(encode(x)) == x`]*\n\n")
+
 	sb.WriteString("## Tests\n\n")
 	sb.WriteString("*[AI: Describe test cases if present]*\n\n")
 
@@ -1365,7 +1477,13 @@ func (s *Server) generateSpecFromAnalysis(
 					if t.DocComment != "" {
 						sb.WriteString(fmt.Sprintf("%s\n\n", strings.TrimSpace(t.DocComment)))
 					}
-					if len(t.Methods) > 0 {
+					if len(t.MethodSignatures) > 0 {
+						sb.WriteString("**Methods:**\n")
+						for _, m := range t.MethodSignatures {
+							sb.WriteString(fmt.Sprintf("- `%s`\n", m))
+						}
+						sb.WriteString("\n")
+					} else if len(t.Methods) > 0 {
 						sb.WriteString("**Methods:**\n")
 						for _, m := range t.Methods {
 							sb.WriteString(fmt.Sprintf("- `%s`\n", m))
@@ -1440,8 +1558,11 @@ func (s *Server) generateSpecFromAnalysis(
 		}
 	}
 
-	// Database Schema from SQL files
-	if sqlFiles, ok := rawFilesByLanguage["sql"]; ok && len(sqlFiles) > 0 {
+	// Database tables from SQL migrations and ORM models, falling back to
+	// the raw schema files when they do not parse
+	if model, err := dbschema.ImportDir(files.RootPath); err == nil && len(model.Tables) > 0 {
+		sb.WriteString(specparser.RenderTables(model.Tables))
+	} else if sqlFiles, ok := rawFilesByLanguage["sql"]; ok && len(sqlFiles) > 0 {
 		sb.WriteString("## Database Schema\n\n")
 		for _, f := range sqlFiles {
 			if strings.Contains(f.Path, "migration") || strings.Contains(f.Path, "schema") {
@@ -1680,6 +1801,8 @@ func (s *Server) detectPrimaryLanguage(dir string) string {
 			extensionCounts["rust"]++
 		case ".cs":
 			extensionCounts["csharp"]++
+		case ".proto":
+			extensionCounts["protobuf"]++
 		}
 		return nil
 	})
@@ -1717,6 +1840,7 @@ func (s *Server) convertAnalysisToOutput(analysis *semantic.Analysis, depth stri
 			Kind:                 string(t.Kind),
 			IsPublic:             t.IsPublic,
 			Methods:              t.Methods,
+			MethodSignatures:     methodSignatures(t),
 			ImplementsInterfaces: t.ImplementsInterfaces,
 			Generic:              t.Generic,
 			DocComment:           t.DocComment,
@@ -1811,6 +1935,15 @@ func (s *Server) convertAnalysisToOutput(analysis *semantic.Analysis, depth stri
 	return output
 }
 
+// methodSignatures formats the resolved signatures of a type's methods
+func methodSignatures(t semantic.ResolvedType) []string {
+	var sigs []string
+	for _, m := range t.MethodSignatures {
+		sigs = append(sigs, m.String())
+	}
+	return sigs
+}
+
 // buildDeepAnalysisPrompt generates an AI prompt from the semantic analysis
 func (s *Server) buildDeepAnalysisPrompt(analysis *semantic.Analysis, depth string) string {
 	var sb strings.Builder
@@ -1832,7 +1965,11 @@ func (s *Server) buildDeepAnalysisPrompt(analysis *semantic.Analysis, depth stri
 			if t.DocComment != "" {
 				sb.WriteString(fmt.Sprintf("*%s*\n", strings.TrimSpace(t.DocComment)))
 			}
-			sb.WriteString(fmt.Sprintf("Location: `%s:%d`\n\n", t.Location.File, t.Location.StartLine))
+			sb.WriteString(fmt.Sprintf("Location: `%s:%d`\n", t.Location.File, t.Location.StartLine))
+			if t.PackagePath != "" {
+				sb.WriteString(fmt.Sprintf("**Module**: `%s`\n", t.PackagePath))
+			}
+			sb.WriteString("\n")
 
 			if len(t.ResolvedFields) > 0 {
 				sb.WriteString("**Fields:**\n")
@@ -1853,7 +1990,13 @@ func (s *Server) buildDeepAnalysisPrompt(analysis *semantic.Analysis, depth stri
 				sb.WriteString("\n")
 			}
 
-			if len(t.Methods) > 0 {
+			if len(t.MethodSignatures) > 0 {
+				sb.WriteString("**Methods:**\n")
+				for _, m := range t.MethodSignatures {
+					sb.WriteString(fmt.Sprintf("- `%s`\n", m))
+				}
+				sb.WriteString("\n")
+			} else if len(t.Methods) > 0 {
 				sb.WriteString(fmt.Sprintf("**Methods:** %s\n\n", strings.Join(t.Methods, ", ")))
 			}
 
@@ -1881,6 +2024,9 @@ func (s *Server) buildDeepAnalysisPrompt(analysis *semantic.Analysis, depth stri
 				sb.WriteString(fmt.Sprintf("*%s*\n", strings.TrimSpace(f.DocComment)))
 			}
 			sb.WriteString(fmt.Sprintf("Location: `%s:%d`\n", f.Location.File, f.Location.StartLine))
+			if f.PackagePath != "" {
+				sb.WriteString(fmt.Sprintf("**Module**: `%s`\n", f.PackagePath))
+			}
 			if f.Complexity > 1 {
 				sb.WriteString(fmt.Sprintf("Complexity: %d\n", f.Complexity))
 			}
@@ -2373,8 +2519,10 @@ func (s *Server) handleSemanticParityAnalysis(ctx context.Context, req *mcp.Call
 		}, SemanticParityAnalysisOutput{}, nil
 	}
 
-	// Analyze generated projects
+	// Analyze generated projects, collecting the identifiers the generator
+	// had to escape
 	generatedAnalyses := make(map[string]*semantic.Analysis)
+	var identifierMap []parity.IdentifierMapping
 	for _, proj := range input.GeneratedProjects {
 		projPath := expandPath(proj.Path)
 		if _, err := os.Stat(projPath); os.IsNotExist(err) {
@@ -2391,6 +2539,10 @@ func (s *Server) handleSemanticParityAnalysis(ctx context.Context, req *mcp.Call
 			continue
 		}
 		generatedAnalyses[proj.Language] = analysis
+
+		if mappings, err := parity.LoadIdentifierMap(projPath); err == nil {
+			identifierMap = append(identifierMap, mappings...)
+		}
 	}
 
 	// Configure parity comparison
@@ -2415,6 +2567,7 @@ func (s *Server) handleSemanticParityAnalysis(ctx context.Context, req *mcp.Call
 
 	// Perform parity comparison
 	comparator := parity.NewComparator(parityConfig)
+	comparator.AddIdentifierMap(identifierMap)
 	result := comparator.Compare(sourceAnalysis, generatedAnalyses)
 
 	// Convert to output format
@@ -2446,6 +2599,7 @@ func (s *Server) handleSemanticParityAnalysis(ctx context.Context, req *mcp.Call
 			},
 			MissingTypes: lr.MissingTypes,
 			MissingFuncs: lr.MissingFuncs,
+			Misplaced:    lr.Misplaced,
 		}
 	}
 
@@ -3117,6 +3271,379 @@ func (s *Server) handleGetFilesForLanguage(ctx context.Context, req *mcp.CallToo
 	}, nil
 }
 
+// =============================================================================
+// SPEC EVOLUTION HANDLERS
+// =============================================================================
+
+// handleDiffSpecs parses two spec revisions and reports their semantic differences
+func (s *Server) handleDiffSpecs(ctx context.Context, req *mcp.CallToolRequest, input DiffSpecsInput) (*mcp.CallToolResult, DiffSpecsOutput, error) {
+	oldContent, oldName, newContent, newName, err := loadSpecRevisions(input)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to load spec revisions: %v", err)},
+			},
+		}, DiffSpecsOutput{}, nil
+	}
+
+	parser := specparser.NewParser()
+	oldSpec, err := parser.Parse(oldContent, oldName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to parse old spec: %v", err)},
+			},
+		}, DiffSpecsOutput{}, nil
+	}
+	newSpec, err := parser.Parse(newContent, newName)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to parse new spec: %v", err)},
+			},
+		}, DiffSpecsOutput{}, nil
+	}
+
+	diff := specdiff.Compare(oldSpec, newSpec)
+
+	return nil, DiffSpecsOutput{
+		Diff:      diff,
+		Changelog: diff.Markdown(),
+	}, nil
+}
+
+// loadSpecRevisions reads the old and new spec content either from two paths
+// or from one path at two git refs.
+func loadSpecRevisions(input DiffSpecsInput) (oldContent, oldName, newContent, newName string, err error) {
+	if input.OldSpecPath != "" && input.NewSpecPath != "" {
+		oldPath := expandPath(input.OldSpecPath)
+		newPath := expandPath(input.NewSpecPath)
+
+		oldBytes, err := os.ReadFile(oldPath)
+		if err != nil {
+			return "", "", "", "", err
+		}
+		newBytes, err := os.ReadFile(newPath)
+		if err != nil {
+			return "", "", "", "", err
+		}
+		return string(oldBytes), filepath.Base(oldPath), string(newBytes), filepath.Base(newPath), nil
+	}
+
+	if input.SpecPath == "" || input.OldRef == "" {
+		return "", "", "", "", fmt.Errorf("provide either oldSpecPath and newSpecPath, or specPath with oldRef (and optionally newRef)")
+	}
+
+	specPath := expandPath(input.SpecPath)
+	name := filepath.Base(specPath)
+
+	oldContent, err = specdiff.ReadAtRef(specPath, input.OldRef)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	if input.NewRef != "" {
+		newContent, err = specdiff.ReadAtRef(specPath, input.NewRef)
+		if err != nil {
+			return "", "", "", "", err
+		}
+	} else {
+		newBytes, err := os.ReadFile(specPath)
+		if err != nil {
+			return "", "", "", "", err
+		}
+		newContent = string(newBytes)
+	}
+
+	return oldContent, name, newContent, name, nil
+}
+
+// handleRegenerateSourceFromSpec regenerates a project from an updated spec,
+// merging the result with hand-written changes instead of overwriting them
+func (s *Server) handleRegenerateSourceFromSpec(ctx context.Context, req *mcp.CallToolRequest, input RegenerateSourceFromSpecInput) (*mcp.CallToolResult, RegenerateSourceFromSpecOutput, error) {
+	specPath := expandPath(input.SpecPath)
+	spec, err := specparser.NewParser().ParseFile(specPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to parse spec file: %v", err)},
+			},
+		}, RegenerateSourceFromSpecOutput{}, nil
+	}
+
+	outputDir := input.OutputDir
+	if outputDir == "" {
+		specName := strings.TrimSuffix(filepath.Base(specPath), ".spec.md")
+		specName = strings.TrimSuffix(specName, ".md")
+		outputDir = filepath.Join(s.outputDir, specName, input.Language)
+	}
+	outputDir = expandPath(outputDir)
+
+	gen := generator.NewGenerator(s.registry)
+	templateDir := input.TemplateDir
+	if templateDir == "" {
+		templateDir = s.templateDir
+	}
+	if templateDir != "" {
+		if err := gen.UseTemplates(expandPath(templateDir)); err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Failed to load templates: %v", err)},
+				},
+			}, RegenerateSourceFromSpecOutput{}, nil
+		}
+	}
+
+	result, err := gen.Regenerate(spec, input.Language, outputDir)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Regeneration failed: %v", err)},
+			},
+		}, RegenerateSourceFromSpecOutput{}, nil
+	}
+
+	counts := make(map[string]int)
+	for _, f := range result.Files {
+		counts[f.Status]++
+	}
+
+	return nil, RegenerateSourceFromSpecOutput{
+		OutputDir: result.OutputDir,
+		Files:     result.Files,
+		Conflicts: result.Conflicts,
+		Summary: fmt.Sprintf("%d created, %d updated, %d unchanged, %d with conflicts (%d conflicting hunks kept as local code)",
+			counts[generator.StatusCreated], counts[generator.StatusUpdated], counts[generator.StatusUnchanged],
+			counts[generator.StatusConflict], len(result.Conflicts)),
+	}, nil
+}
+
+// handleDumpTemplates writes the embedded code templates out for customization
+func (s *Server) handleDumpTemplates(ctx context.Context, req *mcp.CallToolRequest, input DumpTemplatesInput) (*mcp.CallToolResult, DumpTemplatesOutput, error) {
+	files, err := generator.DumpTemplates(expandPath(input.OutputDir))
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to dump templates: %v", err)},
+			},
+		}, DumpTemplatesOutput{}, nil
+	}
+
+	return nil, DumpTemplatesOutput{
+		Files:   files,
+		Summary: fmt.Sprintf("Wrote %d templates; edit them and pass the directory as templateDir (or -templates) to use them", len(files)),
+	}, nil
+}
+
+// =============================================================================
+// SCHEMA IMPORT HANDLERS
+// =============================================================================
+
+// handleImportSpecFromSchema converts an API schema into a spec without an AI pass
+func (s *Server) handleImportSpecFromSchema(ctx context.Context, req *mcp.CallToolRequest, input ImportSpecFromSchemaInput) (*mcp.CallToolResult, ImportSpecFromSchemaOutput, error) {
+	schemaPath := expandPath(input.SchemaPath)
+	content, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to read schema file: %v", err)},
+			},
+		}, ImportSpecFromSchemaOutput{}, nil
+	}
+
+	format := strings.ToLower(input.Format)
+	if format == "" {
+		format = detectSchemaFormat(schemaPath, string(content))
+	}
+
+	var spec *specparser.SpecAnalysis
+	switch format {
+	case "openapi":
+		spec, err = openapi.ParseFile(schemaPath)
+	case "protobuf", "proto":
+		format = "protobuf"
+		spec, err = protobuf.ParseFile(schemaPath)
+	case "graphql":
+		spec, err = graphql.ParseFile(schemaPath)
+	case "jsonschema", "json-schema":
+		format = "jsonschema"
+		spec, err = openapi.ParseSchemaFile(schemaPath)
+	case "sql", "ddl":
+		format = "sql"
+		spec, err = dbschema.ParseFile(schemaPath)
+	case "":
+		err = fmt.Errorf("could not detect schema format; set format explicitly (openapi, protobuf, graphql, jsonschema, sql)")
+	default:
+		err = fmt.Errorf("unsupported schema format %q (supported: openapi, protobuf, graphql, jsonschema, sql)", format)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to import schema: %v", err)},
+			},
+		}, ImportSpecFromSchemaOutput{}, nil
+	}
+
+	output := ImportSpecFromSchemaOutput{
+		Format:       format,
+		Spec:         spec,
+		SpecMarkdown: specparser.Render(spec),
+		Summary: fmt.Sprintf("Imported %d types, %d functions, and %d endpoints from %s",
+			len(spec.Types), len(spec.Functions), len(spec.Endpoints), filepath.Base(schemaPath)),
+	}
+	if len(spec.Tables) > 0 {
+		output.Summary = fmt.Sprintf("Imported %d tables and %d entity types from %s",
+			len(spec.Tables), len(spec.Types), filepath.Base(schemaPath))
+	}
+
+	if input.OutputPath != "" {
+		output.OutputPath = expandPath(input.OutputPath)
+		if err := os.MkdirAll(filepath.Dir(output.OutputPath), 0755); err == nil {
+			if err := os.WriteFile(output.OutputPath, []byte(output.SpecMarkdown), 0644); err == nil {
+				output.SpecGenerated = true
+			}
+		}
+	}
+
+	return nil, output, nil
+}
+
+// handleImportDataModel imports the database schema of a project directory
+// (SQL migrations and ORM models) or of a single DDL or model file
+func (s *Server) handleImportDataModel(ctx context.Context, req *mcp.CallToolRequest, input ImportDataModelInput) (*mcp.CallToolResult, ImportDataModelOutput, error) {
+	sourcePath := expandPath(input.SourcePath)
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to access source path: %v", err)},
+			},
+		}, ImportDataModelOutput{}, nil
+	}
+
+	var spec *specparser.SpecAnalysis
+	switch {
+	case info.IsDir():
+		spec, err = dbschema.ImportDir(sourcePath)
+	case strings.EqualFold(filepath.Ext(sourcePath), ".sql"):
+		spec, err = dbschema.ParseFile(sourcePath)
+	default:
+		var content []byte
+		if content, err = os.ReadFile(sourcePath); err == nil {
+			spec = dbschema.ParseModelFile(sourcePath, content)
+		}
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to import data model: %v", err)},
+			},
+		}, ImportDataModelOutput{}, nil
+	}
+	if input.Name != "" {
+		spec.Name = input.Name
+	}
+
+	relations := 0
+	for _, t := range spec.Tables {
+		relations += len(t.Relations)
+	}
+	output := ImportDataModelOutput{
+		Spec:         spec,
+		SpecMarkdown: specparser.Render(spec),
+		Summary: fmt.Sprintf("Imported %d tables with %d relations from %s",
+			len(spec.Tables), relations, filepath.Base(sourcePath)),
+	}
+
+	if input.OutputPath != "" {
+		output.OutputPath = expandPath(input.OutputPath)
+		if err := os.MkdirAll(filepath.Dir(output.OutputPath), 0755); err == nil {
+			if err := os.WriteFile(output.OutputPath, []byte(output.SpecMarkdown), 0644); err == nil {
+				output.SpecGenerated = true
+			}
+		}
+	}
+
+	return nil, output, nil
+}
+
+// handleExportJSONSchema exports a spec's types as a JSON Schema document
+func (s *Server) handleExportJSONSchema(ctx context.Context, req *mcp.CallToolRequest, input ExportJSONSchemaInput) (*mcp.CallToolResult, ExportJSONSchemaOutput, error) {
+	specPath := expandPath(input.SpecPath)
+	spec, err := specparser.NewParser().ParseFile(specPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to parse spec file: %v", err)},
+			},
+		}, ExportJSONSchemaOutput{}, nil
+	}
+
+	schema, err := specparser.RenderJSONSchema(spec)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Failed to export JSON Schema: %v", err)},
+			},
+		}, ExportJSONSchemaOutput{}, nil
+	}
+
+	output := ExportJSONSchemaOutput{
+		Schema:  string(schema),
+		Summary: fmt.Sprintf("Exported %d types from %s", len(spec.Types), filepath.Base(specPath)),
+	}
+
+	if input.OutputPath != "" {
+		output.OutputPath = expandPath(input.OutputPath)
+		if err := os.MkdirAll(filepath.Dir(output.OutputPath), 0755); err == nil {
+			if err := os.WriteFile(output.OutputPath, schema, 0644); err == nil {
+				output.Written = true
+			}
+		}
+	}
+
+	return nil, output, nil
+}
+
+// detectSchemaFormat guesses a schema's format from its file name and content
+func detectSchemaFormat(path, content string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	head := content
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+
+	switch ext {
+	case ".yaml", ".yml", ".json":
+		if strings.Contains(head, "openapi:") || strings.Contains(head, `"openapi"`) {
+			return "openapi"
+		}
+		if strings.Contains(head, "json-schema.org") || strings.Contains(head, "$defs") {
+			return "jsonschema"
+		}
+	case ".proto":
+		return "protobuf"
+	case ".graphql", ".graphqls", ".gql":
+		return "graphql"
+	case ".sql", ".ddl":
+		return "sql"
+	}
+	return ""
+}
+
 // Example specs (embedded) - narrative style
 const simpleFunctionExample = `# slugify
 
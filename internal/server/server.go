@@ -5,7 +5,9 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/kon1790/rpg/internal/generator"
 	"github.com/kon1790/rpg/internal/languages"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -15,12 +17,16 @@ type Server struct {
 	mcpServer *mcp.Server
 	registry  *languages.Registry
 	outputDir string
+
+	// templateDir holds custom code templates overriding the defaults, if any
+	templateDir string
 }
 
 // New creates a new MCP server with all tools and resources registered.
 // outputDir specifies the base directory for generated projects (e.g., "./output").
 // Generated projects will be placed in outputDir/<language>/.
-func New(outputDir string) *Server {
+// templateDir, if not empty, holds code templates overriding the defaults.
+func New(outputDir, templateDir string) *Server {
 	// Create language registry
 	registry := languages.NewRegistry()
 
@@ -41,9 +47,10 @@ func New(outputDir string) *Server {
 	)
 
 	s := &Server{
-		mcpServer: mcpServer,
-		registry:  registry,
-		outputDir: outputDir,
+		mcpServer:   mcpServer,
+		registry:    registry,
+		outputDir:   outputDir,
+		templateDir: templateDir,
 	}
 
 	// Register all tools
@@ -150,6 +157,74 @@ func (s *Server) registerTools() {
 		Name:        "get_files_for_language",
 		Description: "Get raw file contents for a specific language for AI-driven analysis. Use this for languages without semantic parsers (SQL, Protobuf, GraphQL, etc.) or when you need the actual source code. Returns files with an AI prompt template for extracting types, functions, and patterns. The AI should interpret these files directly.",
 	}, s.handleGetFilesForLanguage)
+
+	// ==========================================================================
+	// SPEC EVOLUTION - Semantic comparison of spec revisions
+	// ==========================================================================
+
+	// Tool: diff_specs
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "diff_specs",
+		Description: "Compare two revisions of a spec (two file paths, or one path at two git refs) and report added, removed, and modified types, fields, functions, parameters, error conditions, API endpoints with their inputs and responses, commands, command flags, state machines with their states, events and transitions, tables and columns, tests, properties, configuration items, and dependencies including version bumps. Returns a structured JSON diff, the list of affected top-level elements, and a Markdown changelog.",
+	}, s.handleDiffSpecs)
+
+	// Tool: regenerate_source_from_spec
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name: "regenerate_source_from_spec",
+		Description: "Incrementally regenerate a previously generated project after the spec changed. " +
+			"Only elements whose spec definition hash changed are rewritten; each file is three-way merged " +
+			"with local edits against the last generated version (kept under .rpg/base), and overlapping edits " +
+			"are reported as conflicts with the hand-written code left in place.",
+	}, s.handleRegenerateSourceFromSpec)
+
+	// Tool: dump_templates
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name: "dump_templates",
+		Description: "Write the default code templates (one text/template file per language and construct: " +
+			strings.Join(generator.TemplateConstructs(), ", ") + ") to a directory. Edit any of them and pass the " +
+			"directory as templateDir to regenerate_source_from_spec, or start the server with -templates, to " +
+			"customize the generated code. HTTP routes and clients, configuration loaders, error catalogs, CLI " +
+			"commands, state machines, repositories, migrations, serialization tests and manifests are not " +
+			"template-rendered and cannot be overridden.",
+	}, s.handleDumpTemplates)
+
+	// ==========================================================================
+	// SCHEMA IMPORT / EXPORT - Deterministic conversion between specs and API schemas
+	// ==========================================================================
+
+	// Tool: import_spec_from_schema
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name: "import_spec_from_schema",
+		Description: "Convert an API schema file into a spec without an AI pass. " +
+			"Supports OpenAPI 3.0/3.1 (YAML or JSON, with $ref resolution across files): component schemas become types " +
+			"and paths/operations become API endpoints. Also supports Protocol Buffers (proto3): messages become types " +
+			"with field numbers kept as tags, enums keep their values, oneofs become unions, and service RPCs become " +
+			"functions with streaming flags. Also supports GraphQL SDL: object, input, interface, union, enum and scalar " +
+			"definitions become types (with implements and directives), and Query/Mutation/Subscription fields become " +
+			"functions with arguments and nullability. Also supports JSON Schema (draft 2020-12): $defs become types " +
+			"with required fields, defaults, enums and oneOf unions. Also supports SQL DDL (Postgres, MySQL and SQLite): " +
+			"tables become a Database section with columns, keys, indexes and relations, plus an entity type per table. " +
+			"Returns the structured spec and its .spec.md rendering, optionally written to outputPath.",
+	}, s.handleImportSpecFromSchema)
+
+	// Tool: import_data_model
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name: "import_data_model",
+		Description: "Import a project's data model into a spec without an AI pass. Given a directory, applies its SQL " +
+			"migrations in order (skipping down migrations) and reads ORM models: GORM structs, JPA @Entity classes, " +
+			"EF Core entities, SQLAlchemy and Django models, and Diesel table! schemas. Given a file, imports that DDL " +
+			"script or model source. Tables get columns, primary keys, indexes, foreign keys and checks, and relations " +
+			"(belongs to, has one, has many, many to many) derived from foreign keys and join tables. Returns the spec " +
+			"and its .spec.md rendering, optionally written to outputPath.",
+	}, s.handleImportDataModel)
+
+	// Tool: export_json_schema
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name: "export_json_schema",
+		Description: "Export a spec's types as a JSON Schema (draft 2020-12) document with one $defs entry per type. " +
+			"Required fields, defaults, enums and unions are preserved so the document imports back into the same " +
+			"types with import_spec_from_schema.",
+	}, s.handleExportJSONSchema)
 }
 
 // registerResources registers all MCP resources.
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/generator"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestNew(t *testing.T) {
+	// Registering a tool panics when its input or output type has no schema,
+	// such as a recursive type
+	s := New(t.TempDir(), "")
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.mcpServer.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error: %v", err)
+	}
+	tools := make(map[string]*mcp.Tool)
+	for _, tool := range result.Tools {
+		tools[tool.Name] = tool
+	}
+	for _, name := range []string{"list_languages", "import_spec_from_schema", "import_data_model", "dump_templates"} {
+		if tools[name] == nil {
+			t.Errorf("Expected the %s tool to be registered", name)
+		}
+	}
+
+	// dump_templates lists every construct it writes
+	if tool := tools["dump_templates"]; tool != nil {
+		for _, construct := range generator.TemplateConstructs() {
+			if !strings.Contains(tool.Description, construct) {
+				t.Errorf("Expected the dump_templates description to list %s, got %q", construct, tool.Description)
+			}
+		}
+	}
+}
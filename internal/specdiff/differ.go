@@ -0,0 +1,1349 @@
+package specdiff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+// Compare computes the semantic differences between an old and a new spec analysis.
+func Compare(oldSpec, newSpec *specparser.SpecAnalysis) *SpecDiff {
+	d := &SpecDiff{
+		OldName: oldSpec.Name,
+		NewName: newSpec.Name,
+		Changes: []Change{},
+	}
+
+	d.compareTypes(oldSpec.Types, newSpec.Types)
+	d.compareFunctions(oldSpec.Functions, newSpec.Functions)
+	d.compareEndpoints(oldSpec.Endpoints, newSpec.Endpoints)
+	d.compareCommands(oldSpec.Commands, newSpec.Commands)
+	d.compareStateMachines(oldSpec.StateMachines, newSpec.StateMachines)
+	d.compareTables(oldSpec.Tables, newSpec.Tables)
+	d.compareTests(oldSpec.Tests, newSpec.Tests)
+	d.compareProperties(oldSpec.Properties, newSpec.Properties)
+	d.compareConfiguration(oldSpec.Configuration, newSpec.Configuration)
+	d.compareDependencies(oldSpec.Dependencies, newSpec.Dependencies)
+
+	d.finalize()
+	return d
+}
+
+// compareTypes compares type definitions and their fields, enum values and methods.
+func (d *SpecDiff) compareTypes(oldTypes, newTypes []specparser.SpecType) {
+	oldByName := make(map[string]specparser.SpecType)
+	for _, t := range oldTypes {
+		if _, ok := oldByName[t.Name]; !ok {
+			oldByName[t.Name] = t
+		}
+	}
+	newByName := make(map[string]specparser.SpecType)
+	for _, t := range newTypes {
+		if _, ok := newByName[t.Name]; !ok {
+			newByName[t.Name] = t
+		}
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldType, inOld := oldByName[name]
+		newType, inNew := newByName[name]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryType, name, name, "", formatType(newType), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryType, name, name, formatType(oldType), "", nil)
+		default:
+			var details []string
+			if oldType.Kind != newType.Kind {
+				details = append(details, fmt.Sprintf("kind changed from %s to %s", oldType.Kind, newType.Kind))
+			}
+			if oldType.Description != newType.Description {
+				details = append(details, "description changed")
+			}
+			if oldType.AliasOf != newType.AliasOf {
+				details = append(details, fmt.Sprintf("alias target changed from %s to %s", oldType.AliasOf, newType.AliasOf))
+			}
+			if !equalStrings(oldType.Implements, newType.Implements) {
+				details = append(details, fmt.Sprintf("implements changed from [%s] to [%s]",
+					strings.Join(oldType.Implements, ", "), strings.Join(newType.Implements, ", ")))
+			}
+			if !equalStrings(oldType.Generic, newType.Generic) {
+				details = append(details, "generic parameters changed")
+			}
+			if !equalStringMaps(oldType.Naming, newType.Naming) {
+				details = append(details, "naming policy changed")
+			}
+			if oldType.Module != newType.Module {
+				details = append(details, fmt.Sprintf("module changed from %s to %s", orRoot(oldType.Module), orRoot(newType.Module)))
+			}
+			if oldType.IsPublic != newType.IsPublic {
+				details = append(details, fmt.Sprintf("visibility changed (public: %t -> %t)", oldType.IsPublic, newType.IsPublic))
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryType, name, name, formatType(oldType), formatType(newType), details)
+			}
+
+			d.compareFields(name, oldType.Fields, newType.Fields)
+			d.compareEnumValues(name, oldType.Values, newType.Values)
+			d.compareMethods(name, oldType.Methods, newType.Methods)
+		}
+	}
+}
+
+// compareFields compares the fields of a type.
+func (d *SpecDiff) compareFields(owner string, oldFields, newFields []specparser.SpecField) {
+	oldByName := make(map[string]specparser.SpecField)
+	for _, f := range oldFields {
+		oldByName[f.Name] = f
+	}
+	newByName := make(map[string]specparser.SpecField)
+	for _, f := range newFields {
+		newByName[f.Name] = f
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldField, inOld := oldByName[name]
+		newField, inNew := newByName[name]
+		path := owner + "." + name
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryField, path, owner, "", formatField(newField), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryField, path, owner, formatField(oldField), "", nil)
+		default:
+			var details []string
+			if oldField.Type != newField.Type {
+				details = append(details, fmt.Sprintf("type changed from %s to %s", oldField.Type, newField.Type))
+			}
+			if oldField.Required != newField.Required {
+				details = append(details, fmt.Sprintf("required changed from %t to %t", oldField.Required, newField.Required))
+			}
+			if oldField.Default != newField.Default {
+				details = append(details, fmt.Sprintf("default changed from %q to %q", oldField.Default, newField.Default))
+			}
+			if !equalStringMaps(oldField.Tags, newField.Tags) {
+				details = append(details, "tags changed")
+			}
+			if !reflect.DeepEqual(oldField.Constraints, newField.Constraints) {
+				details = append(details, "constraints changed")
+			}
+			if oldField.Description != newField.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryField, path, owner, formatField(oldField), formatField(newField), details)
+			}
+		}
+	}
+}
+
+// compareEnumValues compares the values of an enum type.
+func (d *SpecDiff) compareEnumValues(owner string, oldValues, newValues []specparser.SpecEnumValue) {
+	oldByName := make(map[string]specparser.SpecEnumValue)
+	for _, v := range oldValues {
+		oldByName[v.Name] = v
+	}
+	newByName := make(map[string]specparser.SpecEnumValue)
+	for _, v := range newValues {
+		newByName[v.Name] = v
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldValue, inOld := oldByName[name]
+		newValue, inNew := newByName[name]
+		path := owner + "." + name
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryValue, path, owner, "", name, nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryValue, path, owner, name, "", nil)
+		case oldValue.Value != newValue.Value:
+			d.add(ChangeModified, CategoryValue, path, owner, oldValue.Value, newValue.Value,
+				[]string{fmt.Sprintf("value changed from %q to %q", oldValue.Value, newValue.Value)})
+		}
+	}
+}
+
+// compareMethods compares interface method signatures by method name.
+func (d *SpecDiff) compareMethods(owner string, oldMethods, newMethods []specparser.SpecFunction) {
+	oldByName := make(map[string]specparser.SpecFunction)
+	for _, m := range oldMethods {
+		oldByName[m.Name] = m
+	}
+	newByName := make(map[string]specparser.SpecFunction)
+	for _, m := range newMethods {
+		newByName[m.Name] = m
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldMethod, inOld := oldByName[name]
+		newMethod, inNew := newByName[name]
+		path := owner + "." + name
+		oldSig, newSig := specparser.FormatSignature(oldMethod), specparser.FormatSignature(newMethod)
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryMethod, path, owner, "", newSig, nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryMethod, path, owner, oldSig, "", nil)
+		case oldSig != newSig:
+			d.add(ChangeModified, CategoryMethod, path, owner, oldSig, newSig,
+				[]string{fmt.Sprintf("signature changed from %s to %s", oldSig, newSig)})
+		}
+	}
+}
+
+// compareFunctions compares function definitions, their parameters and error conditions.
+func (d *SpecDiff) compareFunctions(oldFuncs, newFuncs []specparser.SpecFunction) {
+	oldByName := make(map[string]specparser.SpecFunction)
+	for _, f := range oldFuncs {
+		if _, ok := oldByName[functionKey(f)]; !ok {
+			oldByName[functionKey(f)] = f
+		}
+	}
+	newByName := make(map[string]specparser.SpecFunction)
+	for _, f := range newFuncs {
+		if _, ok := newByName[functionKey(f)]; !ok {
+			newByName[functionKey(f)] = f
+		}
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldFunc, inOld := oldByName[name]
+		newFunc, inNew := newByName[name]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryFunction, name, name, "", formatFunction(newFunc), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryFunction, name, name, formatFunction(oldFunc), "", nil)
+		default:
+			var details []string
+			if formatReturns(oldFunc.Returns) != formatReturns(newFunc.Returns) {
+				details = append(details, fmt.Sprintf("returns changed from %s to %s",
+					orNone(formatReturns(oldFunc.Returns)), orNone(formatReturns(newFunc.Returns))))
+			}
+			if oldFunc.IsAsync != newFunc.IsAsync {
+				details = append(details, fmt.Sprintf("async changed from %t to %t", oldFunc.IsAsync, newFunc.IsAsync))
+			}
+			if oldFunc.Module != newFunc.Module {
+				details = append(details, fmt.Sprintf("module changed from %s to %s", orRoot(oldFunc.Module), orRoot(newFunc.Module)))
+			}
+			if oldFunc.IsPublic != newFunc.IsPublic {
+				details = append(details, fmt.Sprintf("visibility changed (public: %t -> %t)", oldFunc.IsPublic, newFunc.IsPublic))
+			}
+			if oldFunc.Logic != newFunc.Logic {
+				details = append(details, "logic changed")
+			}
+			if oldFunc.Description != newFunc.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryFunction, name, name, formatFunction(oldFunc), formatFunction(newFunc), details)
+			}
+
+			d.compareParameters(name, oldFunc.Parameters, newFunc.Parameters)
+			d.compareErrors(name, oldFunc.Errors, newFunc.Errors)
+		}
+	}
+}
+
+// compareParameters compares function parameters by name and position.
+func (d *SpecDiff) compareParameters(owner string, oldParams, newParams []specparser.SpecParameter) {
+	oldByName := make(map[string]int)
+	for i, p := range oldParams {
+		oldByName[p.Name] = i
+	}
+	newByName := make(map[string]int)
+	for i, p := range newParams {
+		newByName[p.Name] = i
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldIdx, inOld := oldByName[name]
+		newIdx, inNew := newByName[name]
+		path := fmt.Sprintf("%s(%s)", owner, name)
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryParameter, path, owner, "", formatParameter(newParams[newIdx]), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryParameter, path, owner, formatParameter(oldParams[oldIdx]), "", nil)
+		default:
+			oldParam, newParam := oldParams[oldIdx], newParams[newIdx]
+			var details []string
+			if oldParam.Type != newParam.Type {
+				details = append(details, fmt.Sprintf("type changed from %s to %s", oldParam.Type, newParam.Type))
+			}
+			if oldParam.Required != newParam.Required {
+				details = append(details, fmt.Sprintf("required changed from %t to %t", oldParam.Required, newParam.Required))
+			}
+			if oldParam.Default != newParam.Default {
+				details = append(details, fmt.Sprintf("default changed from %q to %q", oldParam.Default, newParam.Default))
+			}
+			if oldIdx != newIdx {
+				details = append(details, fmt.Sprintf("position changed from %d to %d", oldIdx+1, newIdx+1))
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryParameter, path, owner, formatParameter(oldParam), formatParameter(newParam), details)
+			}
+		}
+	}
+}
+
+// compareErrors compares function error conditions.
+func (d *SpecDiff) compareErrors(owner string, oldErrors, newErrors []specparser.SpecError) {
+	oldByKey := make(map[string]specparser.SpecError)
+	for _, e := range oldErrors {
+		oldByKey[errorKey(e)] = e
+	}
+	newByKey := make(map[string]specparser.SpecError)
+	for _, e := range newErrors {
+		newByKey[errorKey(e)] = e
+	}
+
+	for _, key := range unionKeys(oldByKey, newByKey) {
+		oldErr, inOld := oldByKey[key]
+		newErr, inNew := newByKey[key]
+		path := owner + " ! " + key
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryError, path, owner, "", formatError(newErr), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryError, path, owner, formatError(oldErr), "", nil)
+		case oldErr.Message != newErr.Message || oldErr.Condition != newErr.Condition:
+			d.add(ChangeModified, CategoryError, path, owner, formatError(oldErr), formatError(newErr),
+				[]string{"error condition or message changed"})
+		}
+	}
+}
+
+// compareEndpoints compares API endpoints by operation name, or by method
+// and path when they have none, and their inputs and responses.
+func (d *SpecDiff) compareEndpoints(oldEndpoints, newEndpoints []specparser.SpecEndpoint) {
+	oldByKey := make(map[string]specparser.SpecEndpoint)
+	for _, e := range oldEndpoints {
+		oldByKey[endpointKey(e)] = e
+	}
+	newByKey := make(map[string]specparser.SpecEndpoint)
+	for _, e := range newEndpoints {
+		newByKey[endpointKey(e)] = e
+	}
+
+	for _, key := range unionKeys(oldByKey, newByKey) {
+		oldEndpoint, inOld := oldByKey[key]
+		newEndpoint, inNew := newByKey[key]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryEndpoint, key, key, "", formatEndpoint(newEndpoint), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryEndpoint, key, key, formatEndpoint(oldEndpoint), "", nil)
+		default:
+			var details []string
+			if oldEndpoint.Method != newEndpoint.Method {
+				details = append(details, fmt.Sprintf("method changed from %s to %s", oldEndpoint.Method, newEndpoint.Method))
+			}
+			if oldEndpoint.Path != newEndpoint.Path {
+				details = append(details, fmt.Sprintf("path changed from %s to %s", oldEndpoint.Path, newEndpoint.Path))
+			}
+			if oldEndpoint.Function != newEndpoint.Function {
+				details = append(details, fmt.Sprintf("function changed from %s to %s", orNone(oldEndpoint.Function), orNone(newEndpoint.Function)))
+			}
+			if oldEndpoint.RequestType != newEndpoint.RequestType {
+				details = append(details, fmt.Sprintf("request body changed from %s to %s", orNone(oldEndpoint.RequestType), orNone(newEndpoint.RequestType)))
+			}
+			if !equalStrings(oldEndpoint.Auth, newEndpoint.Auth) {
+				details = append(details, fmt.Sprintf("auth changed from [%s] to [%s]",
+					strings.Join(oldEndpoint.Auth, ", "), strings.Join(newEndpoint.Auth, ", ")))
+			}
+			if !equalStrings(oldEndpoint.Tags, newEndpoint.Tags) {
+				details = append(details, "tags changed")
+			}
+			if oldEndpoint.Description != newEndpoint.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryEndpoint, key, key, formatEndpoint(oldEndpoint), formatEndpoint(newEndpoint), details)
+			}
+
+			d.compareInputs(key, "path", oldEndpoint.PathParams, newEndpoint.PathParams)
+			d.compareInputs(key, "query", oldEndpoint.QueryParams, newEndpoint.QueryParams)
+			d.compareInputs(key, "header", oldEndpoint.HeaderParams, newEndpoint.HeaderParams)
+			d.compareResponses(key, oldEndpoint.Responses, newEndpoint.Responses)
+		}
+	}
+}
+
+// compareInputs compares an endpoint's path, query or header parameters.
+func (d *SpecDiff) compareInputs(owner, source string, oldParams, newParams []specparser.SpecParameter) {
+	oldByName := make(map[string]specparser.SpecParameter)
+	for _, p := range oldParams {
+		oldByName[p.Name] = p
+	}
+	newByName := make(map[string]specparser.SpecParameter)
+	for _, p := range newParams {
+		newByName[p.Name] = p
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldParam, inOld := oldByName[name]
+		newParam, inNew := newByName[name]
+		path := fmt.Sprintf("%s %s %s", owner, source, name)
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryInput, path, owner, "", formatParameter(newParam), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryInput, path, owner, formatParameter(oldParam), "", nil)
+		default:
+			var details []string
+			if oldParam.Type != newParam.Type {
+				details = append(details, fmt.Sprintf("type changed from %s to %s", oldParam.Type, newParam.Type))
+			}
+			if oldParam.Required != newParam.Required {
+				details = append(details, fmt.Sprintf("required changed from %t to %t", oldParam.Required, newParam.Required))
+			}
+			if oldParam.Default != newParam.Default {
+				details = append(details, fmt.Sprintf("default changed from %q to %q", oldParam.Default, newParam.Default))
+			}
+			if oldParam.Description != newParam.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryInput, path, owner, formatParameter(oldParam), formatParameter(newParam), details)
+			}
+		}
+	}
+}
+
+// compareResponses compares an endpoint's responses by status code.
+func (d *SpecDiff) compareResponses(owner string, oldResponses, newResponses []specparser.SpecResponse) {
+	oldByStatus := make(map[string]specparser.SpecResponse)
+	for _, r := range oldResponses {
+		oldByStatus[r.Status] = r
+	}
+	newByStatus := make(map[string]specparser.SpecResponse)
+	for _, r := range newResponses {
+		newByStatus[r.Status] = r
+	}
+
+	for _, status := range unionKeys(oldByStatus, newByStatus) {
+		oldResp, inOld := oldByStatus[status]
+		newResp, inNew := newByStatus[status]
+		path := owner + " " + status
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryResponse, path, owner, "", formatResponse(newResp), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryResponse, path, owner, formatResponse(oldResp), "", nil)
+		default:
+			var details []string
+			if oldResp.Type != newResp.Type {
+				details = append(details, fmt.Sprintf("body changed from %s to %s", orNone(oldResp.Type), orNone(newResp.Type)))
+			}
+			if oldResp.Description != newResp.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryResponse, path, owner, formatResponse(oldResp), formatResponse(newResp), details)
+			}
+		}
+	}
+}
+
+// compareCommands compares CLI commands by their path from the root, and
+// their flags.
+func (d *SpecDiff) compareCommands(oldCmds, newCmds []specparser.SpecCommand) {
+	oldByPath := make(map[string]specparser.SpecCommand)
+	for _, c := range oldCmds {
+		oldByPath[c.Path()] = c
+	}
+	newByPath := make(map[string]specparser.SpecCommand)
+	for _, c := range newCmds {
+		newByPath[c.Path()] = c
+	}
+
+	for _, path := range unionKeys(oldByPath, newByPath) {
+		oldCmd, inOld := oldByPath[path]
+		newCmd, inNew := newByPath[path]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryCommand, path, path, "", formatCommand(newCmd), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryCommand, path, path, formatCommand(oldCmd), "", nil)
+		default:
+			var details []string
+			if oldCmd.Function != newCmd.Function {
+				details = append(details, fmt.Sprintf("function changed from %s to %s", orNone(oldCmd.Function), orNone(newCmd.Function)))
+			}
+			if formatArgs(oldCmd.Args) != formatArgs(newCmd.Args) {
+				details = append(details, fmt.Sprintf("arguments changed from %s to %s",
+					orNone(formatArgs(oldCmd.Args)), orNone(formatArgs(newCmd.Args))))
+			}
+			if !equalExitCodes(oldCmd.ExitCodes, newCmd.ExitCodes) {
+				details = append(details, "exit codes changed")
+			}
+			if oldCmd.Output != newCmd.Output {
+				details = append(details, fmt.Sprintf("output changed from %s to %s", orNone(oldCmd.Output), orNone(newCmd.Output)))
+			}
+			if oldCmd.Description != newCmd.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryCommand, path, path, formatCommand(oldCmd), formatCommand(newCmd), details)
+			}
+
+			d.compareFlags(path, oldCmd.Flags, newCmd.Flags)
+		}
+	}
+}
+
+// compareFlags compares the flags of a command.
+func (d *SpecDiff) compareFlags(owner string, oldFlags, newFlags []specparser.SpecFlag) {
+	oldByName := make(map[string]specparser.SpecFlag)
+	for _, f := range oldFlags {
+		oldByName[f.Name] = f
+	}
+	newByName := make(map[string]specparser.SpecFlag)
+	for _, f := range newFlags {
+		newByName[f.Name] = f
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldFlag, inOld := oldByName[name]
+		newFlag, inNew := newByName[name]
+		path := owner + " --" + name
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryFlag, path, owner, "", formatFlag(newFlag), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryFlag, path, owner, formatFlag(oldFlag), "", nil)
+		default:
+			var details []string
+			if oldFlag.Type != newFlag.Type {
+				details = append(details, fmt.Sprintf("type changed from %s to %s", oldFlag.Type, newFlag.Type))
+			}
+			if oldFlag.Short != newFlag.Short {
+				details = append(details, fmt.Sprintf("short name changed from %s to %s", orNone(oldFlag.Short), orNone(newFlag.Short)))
+			}
+			if oldFlag.Required != newFlag.Required {
+				details = append(details, fmt.Sprintf("required changed from %t to %t", oldFlag.Required, newFlag.Required))
+			}
+			if oldFlag.Default != newFlag.Default {
+				details = append(details, fmt.Sprintf("default changed from %q to %q", oldFlag.Default, newFlag.Default))
+			}
+			if oldFlag.Description != newFlag.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryFlag, path, owner, formatFlag(oldFlag), formatFlag(newFlag), details)
+			}
+		}
+	}
+}
+
+// compareStateMachines compares state machines and their states, events
+// and transitions.
+func (d *SpecDiff) compareStateMachines(oldMachines, newMachines []specparser.SpecStateMachine) {
+	oldByName := make(map[string]specparser.SpecStateMachine)
+	for _, m := range oldMachines {
+		oldByName[m.Name] = m
+	}
+	newByName := make(map[string]specparser.SpecStateMachine)
+	for _, m := range newMachines {
+		newByName[m.Name] = m
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldMachine, inOld := oldByName[name]
+		newMachine, inNew := newByName[name]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryMachine, name, name, "", formatMachine(newMachine), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryMachine, name, name, formatMachine(oldMachine), "", nil)
+		default:
+			var details []string
+			if oldMachine.InitialState() != newMachine.InitialState() {
+				details = append(details, fmt.Sprintf("initial state changed from %s to %s",
+					orNone(oldMachine.InitialState()), orNone(newMachine.InitialState())))
+			}
+			if oldMachine.Description != newMachine.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryMachine, name, name, formatMachine(oldMachine), formatMachine(newMachine), details)
+			}
+
+			d.compareStates(name, oldMachine.States, newMachine.States)
+			d.compareEvents(name, oldMachine.Events, newMachine.Events)
+			d.compareTransitions(name, oldMachine.Transitions, newMachine.Transitions)
+		}
+	}
+}
+
+// compareStates compares the states of a state machine.
+func (d *SpecDiff) compareStates(owner string, oldStates, newStates []specparser.SpecState) {
+	oldByName := make(map[string]specparser.SpecState)
+	for _, s := range oldStates {
+		oldByName[s.Name] = s
+	}
+	newByName := make(map[string]specparser.SpecState)
+	for _, s := range newStates {
+		newByName[s.Name] = s
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldState, inOld := oldByName[name]
+		newState, inNew := newByName[name]
+		path := owner + "." + name
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryState, path, owner, "", formatState(newState), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryState, path, owner, formatState(oldState), "", nil)
+		default:
+			var details []string
+			if oldState.Final != newState.Final {
+				details = append(details, fmt.Sprintf("final changed from %t to %t", oldState.Final, newState.Final))
+			}
+			if oldState.Description != newState.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryState, path, owner, formatState(oldState), formatState(newState), details)
+			}
+		}
+	}
+}
+
+// compareEvents compares the events of a state machine.
+func (d *SpecDiff) compareEvents(owner string, oldEvents, newEvents []specparser.SpecEvent) {
+	oldByName := make(map[string]specparser.SpecEvent)
+	for _, e := range oldEvents {
+		oldByName[e.Name] = e
+	}
+	newByName := make(map[string]specparser.SpecEvent)
+	for _, e := range newEvents {
+		newByName[e.Name] = e
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldEvent, inOld := oldByName[name]
+		newEvent, inNew := newByName[name]
+		path := owner + "." + name
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryEvent, path, owner, "", name, nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryEvent, path, owner, name, "", nil)
+		case oldEvent.Description != newEvent.Description:
+			d.add(ChangeModified, CategoryEvent, path, owner, name, name, []string{"description changed"})
+		}
+	}
+}
+
+// compareTransitions compares the transitions of a state machine by the
+// state, event and guard they are taken from, so a changed target or
+// action shows as a modification.
+func (d *SpecDiff) compareTransitions(owner string, oldTransitions, newTransitions []specparser.SpecTransition) {
+	oldByKey := make(map[string]specparser.SpecTransition)
+	for _, t := range oldTransitions {
+		oldByKey[transitionKey(t)] = t
+	}
+	newByKey := make(map[string]specparser.SpecTransition)
+	for _, t := range newTransitions {
+		newByKey[transitionKey(t)] = t
+	}
+
+	for _, key := range unionKeys(oldByKey, newByKey) {
+		oldTransition, inOld := oldByKey[key]
+		newTransition, inNew := newByKey[key]
+		path := owner + ": " + key
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryTransition, path, owner, "", formatTransition(newTransition), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryTransition, path, owner, formatTransition(oldTransition), "", nil)
+		default:
+			var details []string
+			if oldTransition.To != newTransition.To {
+				details = append(details, fmt.Sprintf("target changed from %s to %s", oldTransition.To, newTransition.To))
+			}
+			if oldTransition.Action != newTransition.Action {
+				details = append(details, fmt.Sprintf("action changed from %s to %s", orNone(oldTransition.Action), orNone(newTransition.Action)))
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryTransition, path, owner, formatTransition(oldTransition), formatTransition(newTransition), details)
+			}
+		}
+	}
+}
+
+// compareTables compares database tables, their keys and constraints, and
+// their columns.
+func (d *SpecDiff) compareTables(oldTables, newTables []specparser.SpecTable) {
+	oldByName := make(map[string]specparser.SpecTable)
+	for _, t := range oldTables {
+		oldByName[t.Name] = t
+	}
+	newByName := make(map[string]specparser.SpecTable)
+	for _, t := range newTables {
+		newByName[t.Name] = t
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldTable, inOld := oldByName[name]
+		newTable, inNew := newByName[name]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryTable, name, name, "", formatTable(newTable), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryTable, name, name, formatTable(oldTable), "", nil)
+		default:
+			var details []string
+			if oldTable.Entity != newTable.Entity {
+				details = append(details, fmt.Sprintf("entity changed from %s to %s", orNone(oldTable.Entity), orNone(newTable.Entity)))
+			}
+			if !equalStrings(oldTable.PrimaryKey, newTable.PrimaryKey) {
+				details = append(details, fmt.Sprintf("primary key changed from (%s) to (%s)",
+					strings.Join(oldTable.PrimaryKey, ", "), strings.Join(newTable.PrimaryKey, ", ")))
+			}
+			if !reflect.DeepEqual(oldTable.Indexes, newTable.Indexes) {
+				details = append(details, "indexes changed")
+			}
+			if !reflect.DeepEqual(oldTable.ForeignKeys, newTable.ForeignKeys) {
+				details = append(details, "foreign keys changed")
+			}
+			if !equalStrings(oldTable.Checks, newTable.Checks) {
+				details = append(details, "checks changed")
+			}
+			if !reflect.DeepEqual(oldTable.Relations, newTable.Relations) {
+				details = append(details, "relations changed")
+			}
+			if oldTable.Description != newTable.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryTable, name, name, formatTable(oldTable), formatTable(newTable), details)
+			}
+
+			d.compareColumns(name, oldTable.Columns, newTable.Columns)
+		}
+	}
+}
+
+// compareColumns compares the columns of a table.
+func (d *SpecDiff) compareColumns(owner string, oldColumns, newColumns []specparser.SpecColumn) {
+	oldByName := make(map[string]specparser.SpecColumn)
+	for _, c := range oldColumns {
+		oldByName[c.Name] = c
+	}
+	newByName := make(map[string]specparser.SpecColumn)
+	for _, c := range newColumns {
+		newByName[c.Name] = c
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldCol, inOld := oldByName[name]
+		newCol, inNew := newByName[name]
+		path := owner + "." + name
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryColumn, path, owner, "", formatColumn(newCol), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryColumn, path, owner, formatColumn(oldCol), "", nil)
+		default:
+			var details []string
+			if oldCol.Type != newCol.Type {
+				details = append(details, fmt.Sprintf("type changed from %s to %s", oldCol.Type, newCol.Type))
+			}
+			if oldCol.SQLType != newCol.SQLType {
+				details = append(details, fmt.Sprintf("SQL type changed from %s to %s", orNone(oldCol.SQLType), orNone(newCol.SQLType)))
+			}
+			if oldCol.Nullable != newCol.Nullable {
+				details = append(details, fmt.Sprintf("nullable changed from %t to %t", oldCol.Nullable, newCol.Nullable))
+			}
+			if oldCol.Unique != newCol.Unique {
+				details = append(details, fmt.Sprintf("unique changed from %t to %t", oldCol.Unique, newCol.Unique))
+			}
+			if oldCol.AutoIncrement != newCol.AutoIncrement {
+				details = append(details, fmt.Sprintf("auto increment changed from %t to %t", oldCol.AutoIncrement, newCol.AutoIncrement))
+			}
+			if oldCol.Default != newCol.Default {
+				details = append(details, fmt.Sprintf("default changed from %q to %q", oldCol.Default, newCol.Default))
+			}
+			if oldCol.Description != newCol.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryColumn, path, owner, formatColumn(oldCol), formatColumn(newCol), details)
+			}
+		}
+	}
+}
+
+// compareTests compares test case definitions.
+func (d *SpecDiff) compareTests(oldTests, newTests []specparser.SpecTest) {
+	oldByName := make(map[string]specparser.SpecTest)
+	for _, t := range oldTests {
+		oldByName[t.Name] = t
+	}
+	newByName := make(map[string]specparser.SpecTest)
+	for _, t := range newTests {
+		newByName[t.Name] = t
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldTest, inOld := oldByName[name]
+		newTest, inNew := newByName[name]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryTest, name, name, "", formatTest(newTest), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryTest, name, name, formatTest(oldTest), "", nil)
+		default:
+			var details []string
+			if oldTest.Target != newTest.Target {
+				details = append(details, fmt.Sprintf("target changed from %s to %s", orNone(oldTest.Target), orNone(newTest.Target)))
+			}
+			if !equalConditions(oldTest.Given, newTest.Given) {
+				details = append(details, "given conditions changed")
+			}
+			if oldTest.When != newTest.When {
+				details = append(details, "when action changed")
+			}
+			if !equalAssertions(oldTest.Then, newTest.Then) {
+				details = append(details, "expected outcomes changed")
+			}
+			if oldTest.Description != newTest.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryTest, name, name, formatTest(oldTest), formatTest(newTest), details)
+			}
+		}
+	}
+}
+
+// compareProperties compares invariants by their expression, or by their
+// description when they have none. A property is owned by the function it
+// is about.
+func (d *SpecDiff) compareProperties(oldProps, newProps []specparser.SpecProperty) {
+	oldByKey := make(map[string]specparser.SpecProperty)
+	for _, p := range oldProps {
+		oldByKey[propertyKey(p)] = p
+	}
+	newByKey := make(map[string]specparser.SpecProperty)
+	for _, p := range newProps {
+		newByKey[propertyKey(p)] = p
+	}
+
+	for _, key := range unionKeys(oldByKey, newByKey) {
+		oldProp, inOld := oldByKey[key]
+		newProp, inNew := newByKey[key]
+		p := newProp
+		if !inNew {
+			p = oldProp
+		}
+		owner := key
+		if p.Function != "" {
+			owner = p.Function
+		}
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryProperty, key, owner, "", formatProperty(newProp), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryProperty, key, owner, formatProperty(oldProp), "", nil)
+		case oldProp.Description != newProp.Description:
+			d.add(ChangeModified, CategoryProperty, key, owner, formatProperty(oldProp), formatProperty(newProp), []string{"description changed"})
+		}
+	}
+}
+
+// compareConfiguration compares configuration items.
+func (d *SpecDiff) compareConfiguration(oldConfig, newConfig []specparser.SpecConfig) {
+	oldByName := make(map[string]specparser.SpecConfig)
+	for _, c := range oldConfig {
+		oldByName[c.Name] = c
+	}
+	newByName := make(map[string]specparser.SpecConfig)
+	for _, c := range newConfig {
+		newByName[c.Name] = c
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		oldCfg, inOld := oldByName[name]
+		newCfg, inNew := newByName[name]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryConfig, name, name, "", formatConfig(newCfg), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryConfig, name, name, formatConfig(oldCfg), "", nil)
+		default:
+			var details []string
+			if oldCfg.Type != newCfg.Type {
+				details = append(details, fmt.Sprintf("type changed from %s to %s", oldCfg.Type, newCfg.Type))
+			}
+			if oldCfg.Default != newCfg.Default {
+				details = append(details, fmt.Sprintf("default changed from %q to %q", oldCfg.Default, newCfg.Default))
+			}
+			if oldCfg.Required != newCfg.Required {
+				details = append(details, fmt.Sprintf("required changed from %t to %t", oldCfg.Required, newCfg.Required))
+			}
+			if oldCfg.Description != newCfg.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryConfig, name, name, formatConfig(oldCfg), formatConfig(newCfg), details)
+			}
+		}
+	}
+}
+
+// compareDependencies compares dependencies, including version bumps and
+// changed per-language package names.
+func (d *SpecDiff) compareDependencies(oldDeps, newDeps []specparser.SpecDependency) {
+	oldByKey := make(map[string]specparser.SpecDependency)
+	for _, dep := range oldDeps {
+		oldByKey[dependencyKey(dep)] = dep
+	}
+	newByKey := make(map[string]specparser.SpecDependency)
+	for _, dep := range newDeps {
+		newByKey[dependencyKey(dep)] = dep
+	}
+
+	for _, key := range unionKeys(oldByKey, newByKey) {
+		oldDep, inOld := oldByKey[key]
+		newDep, inNew := newByKey[key]
+
+		switch {
+		case !inOld:
+			d.add(ChangeAdded, CategoryDependency, key, key, "", formatDependency(newDep), nil)
+		case !inNew:
+			d.add(ChangeRemoved, CategoryDependency, key, key, formatDependency(oldDep), "", nil)
+		default:
+			var details []string
+			if oldDep.Version != newDep.Version {
+				details = append(details, fmt.Sprintf("version changed from %s to %s", orNone(oldDep.Version), orNone(newDep.Version)))
+			}
+			for _, lang := range unionKeys(oldDep.Packages, newDep.Packages) {
+				if oldPkg, newPkg := oldDep.Packages[lang], newDep.Packages[lang]; oldPkg != newPkg {
+					details = append(details, fmt.Sprintf("%s package changed from %s to %s", lang, orNone(oldPkg), orNone(newPkg)))
+				}
+			}
+			if oldDep.Stdlib != newDep.Stdlib {
+				details = append(details, fmt.Sprintf("standard library changed from %t to %t", oldDep.Stdlib, newDep.Stdlib))
+			}
+			if oldDep.Description != newDep.Description {
+				details = append(details, "description changed")
+			}
+			if len(details) > 0 {
+				d.add(ChangeModified, CategoryDependency, key, key, formatDependency(oldDep), formatDependency(newDep), details)
+			}
+		}
+	}
+}
+
+// add records a change.
+func (d *SpecDiff) add(kind ChangeKind, category Category, path, owner, before, after string, details []string) {
+	d.Changes = append(d.Changes, Change{
+		Kind:     kind,
+		Category: category,
+		Path:     path,
+		Owner:    owner,
+		Before:   before,
+		After:    after,
+		Details:  details,
+	})
+}
+
+// finalize orders the changes and computes the summary and affected elements.
+func (d *SpecDiff) finalize() {
+	rank := make(map[Category]int)
+	for i, c := range categoryOrder {
+		rank[c] = i
+	}
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		a, b := d.Changes[i], d.Changes[j]
+		if rank[a.Category] != rank[b.Category] {
+			return rank[a.Category] < rank[b.Category]
+		}
+		return a.Path < b.Path
+	})
+
+	d.Summary = Summary{ByCategory: make(map[Category]int)}
+	affected := make(map[string]bool)
+	for _, c := range d.Changes {
+		switch c.Kind {
+		case ChangeAdded:
+			d.Summary.Added++
+		case ChangeRemoved:
+			d.Summary.Removed++
+		case ChangeModified:
+			d.Summary.Modified++
+		}
+		d.Summary.ByCategory[c.Category]++
+		affected[c.Owner] = true
+	}
+
+	d.AffectedElements = make([]string, 0, len(affected))
+	for name := range affected {
+		d.AffectedElements = append(d.AffectedElements, name)
+	}
+	sort.Strings(d.AffectedElements)
+}
+
+// Helper functions
+
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for k := range a {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func functionKey(f specparser.SpecFunction) string {
+	if f.Receiver != "" {
+		return f.Receiver + "." + f.Name
+	}
+	return f.Name
+}
+
+// endpointKey identifies an endpoint by its operation name, so a changed
+// method or path is reported as a change rather than a new endpoint.
+func endpointKey(e specparser.SpecEndpoint) string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return e.Method + " " + e.Path
+}
+
+// propertyKey identifies a property by its function and expression.
+func propertyKey(p specparser.SpecProperty) string {
+	key := p.Expression
+	if key == "" {
+		key = p.Description
+	}
+	if p.Function != "" {
+		return p.Function + ": " + key
+	}
+	return key
+}
+
+// transitionKey identifies a transition by the state, event and guard it
+// is taken from.
+func transitionKey(t specparser.SpecTransition) string {
+	key := t.From + " on " + t.Event
+	if t.Guard != "" {
+		key += " [" + t.Guard + "]"
+	}
+	return key
+}
+
+func errorKey(e specparser.SpecError) string {
+	if e.Type != "" {
+		return e.Type
+	}
+	return e.Condition
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStringMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+func equalConditions(a, b []specparser.SpecCondition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalAssertions(a, b []specparser.SpecAssertion) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalExitCodes(a, b []specparser.SpecExitCode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// orRoot names the root module, which has an empty path.
+func orRoot(module string) string {
+	if module == "" {
+		return "the root module"
+	}
+	return module
+}
+
+func formatType(t specparser.SpecType) string {
+	return fmt.Sprintf("%s (%s, %d fields)", t.Name, t.Kind, len(t.Fields))
+}
+
+func formatField(f specparser.SpecField) string {
+	s := fmt.Sprintf("%s: %s", f.Name, f.Type)
+	if !f.Required {
+		s += " (optional)"
+	}
+	if f.Default != "" {
+		s += fmt.Sprintf(" = %s", f.Default)
+	}
+	return s
+}
+
+func formatParameter(p specparser.SpecParameter) string {
+	s := fmt.Sprintf("%s: %s", p.Name, p.Type)
+	if !p.Required {
+		s += " (optional)"
+	}
+	if p.Default != "" {
+		s += fmt.Sprintf(" = %s", p.Default)
+	}
+	return s
+}
+
+func formatReturns(returns []specparser.SpecReturn) string {
+	var types []string
+	for _, r := range returns {
+		types = append(types, r.Type)
+	}
+	return strings.Join(types, ", ")
+}
+
+func formatFunction(f specparser.SpecFunction) string {
+	var params []string
+	for _, p := range f.Parameters {
+		params = append(params, fmt.Sprintf("%s: %s", p.Name, p.Type))
+	}
+	s := fmt.Sprintf("%s(%s)", functionKey(f), strings.Join(params, ", "))
+	if ret := formatReturns(f.Returns); ret != "" {
+		s += " -> " + ret
+	}
+	return s
+}
+
+// formatArgs renders positional arguments in order, since their position
+// is how they are given.
+func formatArgs(args []specparser.SpecParameter) string {
+	var parts []string
+	for _, a := range args {
+		s := fmt.Sprintf("<%s: %s>", a.Name, a.Type)
+		if !a.Required {
+			s = fmt.Sprintf("[%s: %s]", a.Name, a.Type)
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatEndpoint(e specparser.SpecEndpoint) string {
+	s := e.Method + " " + e.Path
+	if e.Name != "" {
+		s += " (" + e.Name + ")"
+	}
+	return s
+}
+
+func formatResponse(r specparser.SpecResponse) string {
+	if r.Type != "" {
+		return r.Status + ": " + r.Type
+	}
+	return r.Status
+}
+
+func formatCommand(c specparser.SpecCommand) string {
+	if args := formatArgs(c.Args); args != "" {
+		return c.Path() + " " + args
+	}
+	return c.Path()
+}
+
+func formatFlag(f specparser.SpecFlag) string {
+	s := fmt.Sprintf("--%s: %s", f.Name, f.Type)
+	if f.Short != "" {
+		s = fmt.Sprintf("--%s, -%s: %s", f.Name, f.Short, f.Type)
+	}
+	if f.Required {
+		s += " (required)"
+	}
+	if f.Default != "" {
+		s += fmt.Sprintf(" = %s", f.Default)
+	}
+	return s
+}
+
+func formatMachine(m specparser.SpecStateMachine) string {
+	return fmt.Sprintf("%s (%d states, %d transitions)", m.Name, len(m.States), len(m.Transitions))
+}
+
+func formatState(s specparser.SpecState) string {
+	if s.Final {
+		return s.Name + " (final)"
+	}
+	return s.Name
+}
+
+func formatTransition(t specparser.SpecTransition) string {
+	s := fmt.Sprintf("%s --%s--> %s", t.From, t.Event, t.To)
+	if t.Guard != "" {
+		s = fmt.Sprintf("%s --%s [%s]--> %s", t.From, t.Event, t.Guard, t.To)
+	}
+	if t.Action != "" {
+		s += " / " + t.Action
+	}
+	return s
+}
+
+func formatTable(t specparser.SpecTable) string {
+	return fmt.Sprintf("%s (%d columns)", t.Name, len(t.Columns))
+}
+
+func formatColumn(c specparser.SpecColumn) string {
+	s := fmt.Sprintf("%s: %s", c.Name, c.Type)
+	if c.Nullable {
+		s += " (nullable)"
+	}
+	if c.Default != "" {
+		s += fmt.Sprintf(" = %s", c.Default)
+	}
+	return s
+}
+
+func formatError(e specparser.SpecError) string {
+	s := e.Condition
+	if e.Type != "" {
+		s = e.Type + ": " + s
+	}
+	if e.Message != "" {
+		s += fmt.Sprintf(" (%q)", e.Message)
+	}
+	return s
+}
+
+func formatTest(t specparser.SpecTest) string {
+	if t.Target != "" {
+		return fmt.Sprintf("%s -> %s", t.Name, t.Target)
+	}
+	return t.Name
+}
+
+func formatProperty(p specparser.SpecProperty) string {
+	if p.Expression != "" && p.Description != "" {
+		return fmt.Sprintf("%s (%s)", propertyKey(p), p.Description)
+	}
+	return propertyKey(p)
+}
+
+// dependencyKey identifies a dependency by name, scoped to its language
+// when it was listed under a language heading.
+func dependencyKey(dep specparser.SpecDependency) string {
+	if len(dep.Packages) == 1 {
+		for lang, pkg := range dep.Packages {
+			if pkg == dep.Name {
+				return fmt.Sprintf("%s (%s)", dep.Name, lang)
+			}
+		}
+	}
+	return dep.Name
+}
+
+func formatDependency(dep specparser.SpecDependency) string {
+	s := dep.Name
+	if dep.Version != "" {
+		s += "@" + dep.Version
+	}
+	if dep.Stdlib {
+		s += " (standard library)"
+	}
+	return s
+}
+
+func formatConfig(c specparser.SpecConfig) string {
+	s := fmt.Sprintf("%s: %s", c.Name, c.Type)
+	if c.Required {
+		s += " (required)"
+	}
+	if c.Default != "" {
+		s += fmt.Sprintf(" = %s", c.Default)
+	}
+	return s
+}
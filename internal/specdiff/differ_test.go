@@ -0,0 +1,434 @@
+package specdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kon1790/rpg/internal/specparser"
+)
+
+func baseSpec() *specparser.SpecAnalysis {
+	return &specparser.SpecAnalysis{
+		Name: "users",
+		Types: []specparser.SpecType{
+			{
+				Name: "User",
+				Kind: "struct",
+				Fields: []specparser.SpecField{
+					{Name: "id", Type: "string", Required: true},
+					{Name: "email", Type: "string", Required: true},
+				},
+			},
+			{Name: "Legacy", Kind: "struct"},
+		},
+		Functions: []specparser.SpecFunction{
+			{
+				Name: "CreateUser",
+				Parameters: []specparser.SpecParameter{
+					{Name: "email", Type: "string", Required: true},
+				},
+				Returns: []specparser.SpecReturn{{Type: "User"}},
+				Errors:  []specparser.SpecError{{Condition: "email is empty"}},
+			},
+		},
+		Tests: []specparser.SpecTest{
+			{Name: "creates user", When: "CreateUser is called"},
+		},
+		Configuration: []specparser.SpecConfig{
+			{Name: "PORT", Type: "int", Default: "8080"},
+		},
+	}
+}
+
+func TestCompareNoChanges(t *testing.T) {
+	diff := Compare(baseSpec(), baseSpec())
+
+	if diff.HasChanges() {
+		t.Errorf("Expected no changes, got %d: %+v", len(diff.Changes), diff.Changes)
+	}
+	if !strings.Contains(diff.Markdown(), "No semantic changes") {
+		t.Error("Expected changelog to report no changes")
+	}
+}
+
+func TestCompareDetectsChanges(t *testing.T) {
+	oldSpec := baseSpec()
+	newSpec := baseSpec()
+
+	// Modify a field, add a field, remove a type, add a type
+	newSpec.Types[0].Fields[1].Type = "Email"
+	newSpec.Types[0].Fields = append(newSpec.Types[0].Fields, specparser.SpecField{Name: "name", Type: "string"})
+	newSpec.Types = []specparser.SpecType{newSpec.Types[0], {Name: "Account", Kind: "struct"}}
+
+	// Add a parameter and an error, change the return type
+	newSpec.Functions[0].Parameters = append(newSpec.Functions[0].Parameters, specparser.SpecParameter{Name: "name", Type: "string"})
+	newSpec.Functions[0].Errors = append(newSpec.Functions[0].Errors, specparser.SpecError{Condition: "email already exists"})
+	newSpec.Functions[0].Returns = []specparser.SpecReturn{{Type: "Result<User, Error>"}}
+
+	// Change the test and config
+	newSpec.Tests[0].When = "CreateUser is called twice"
+	newSpec.Configuration[0].Default = "9090"
+
+	diff := Compare(oldSpec, newSpec)
+
+	expected := []struct {
+		kind     ChangeKind
+		category Category
+		path     string
+	}{
+		{ChangeAdded, CategoryType, "Account"},
+		{ChangeRemoved, CategoryType, "Legacy"},
+		{ChangeModified, CategoryField, "User.email"},
+		{ChangeAdded, CategoryField, "User.name"},
+		{ChangeModified, CategoryFunction, "CreateUser"},
+		{ChangeAdded, CategoryParameter, "CreateUser(name)"},
+		{ChangeAdded, CategoryError, "CreateUser ! email already exists"},
+		{ChangeModified, CategoryTest, "creates user"},
+		{ChangeModified, CategoryConfig, "PORT"},
+	}
+
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(expected), len(diff.Changes), diff.Changes)
+	}
+
+	for i, exp := range expected {
+		c := diff.Changes[i]
+		if c.Kind != exp.kind || c.Category != exp.category || c.Path != exp.path {
+			t.Errorf("Change %d = %s %s %s, expected %s %s %s", i, c.Kind, c.Category, c.Path, exp.kind, exp.category, exp.path)
+		}
+	}
+
+	if diff.Summary.Added != 4 || diff.Summary.Removed != 1 || diff.Summary.Modified != 4 {
+		t.Errorf("Unexpected summary: %+v", diff.Summary)
+	}
+
+	affected := strings.Join(diff.AffectedElements, ",")
+	if affected != "Account,CreateUser,Legacy,PORT,User,creates user" {
+		t.Errorf("Unexpected affected elements: %s", affected)
+	}
+
+	md := diff.Markdown()
+	for _, section := range []string{"## Types", "## Fields", "## Parameters", "## Error Conditions", "## Tests", "## Configuration"} {
+		if !strings.Contains(md, section) {
+			t.Errorf("Expected changelog to contain %q", section)
+		}
+	}
+}
+
+func TestCompareMethodSignatures(t *testing.T) {
+	store := func(methods ...specparser.SpecFunction) *specparser.SpecAnalysis {
+		return &specparser.SpecAnalysis{
+			Name:  "users",
+			Types: []specparser.SpecType{{Name: "UserStore", Kind: "interface", Methods: methods}},
+		}
+	}
+	find := specparser.SpecFunction{
+		Name:       "find",
+		Parameters: []specparser.SpecParameter{{Name: "id", Type: "string", Required: true}},
+		Returns:    []specparser.SpecReturn{{Type: "User"}},
+	}
+	findOptional := find
+	findOptional.Returns = []specparser.SpecReturn{{Type: "Optional[User]"}}
+	remove := specparser.SpecFunction{Name: "remove", Parameters: []specparser.SpecParameter{{Name: "id", Type: "string", Required: true}}}
+	count := specparser.SpecFunction{Name: "count", Returns: []specparser.SpecReturn{{Type: "int"}}}
+
+	diff := Compare(store(find, remove), store(findOptional, count))
+
+	expected := []struct {
+		kind  ChangeKind
+		path  string
+		after string
+	}{
+		{ChangeAdded, "UserStore.count", "count(): int"},
+		{ChangeModified, "UserStore.find", "find(id: string): Optional[User]"},
+		{ChangeRemoved, "UserStore.remove", ""},
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), diff.Changes)
+	}
+	for i, exp := range expected {
+		c := diff.Changes[i]
+		if c.Kind != exp.kind || c.Category != CategoryMethod || c.Path != exp.path || c.After != exp.after {
+			t.Errorf("Change %d = %s %s %s %q, expected %s method %s %q", i, c.Kind, c.Category, c.Path, c.After, exp.kind, exp.path, exp.after)
+		}
+	}
+}
+
+func TestCompareProperties(t *testing.T) {
+	spec := func(properties ...specparser.SpecProperty) *specparser.SpecAnalysis {
+		return &specparser.SpecAnalysis{Name: "codec", Properties: properties}
+	}
+	roundTrip := specparser.SpecProperty{Expression: "decode(encode(x)) == x"}
+	documented := roundTrip
+	documented.Description = "Decoding reverses encoding"
+	shorter := specparser.SpecProperty{Function: "shorten", Expression: "len(result) <= len(url)"}
+	prose := specparser.SpecProperty{Description: "Codes never collide"}
+
+	diff := Compare(spec(roundTrip, shorter), spec(documented, prose))
+
+	expected := []struct {
+		kind  ChangeKind
+		path  string
+		owner string
+	}{
+		{ChangeAdded, "Codes never collide", "Codes never collide"},
+		{ChangeModified, "decode(encode(x)) == x", "decode(encode(x)) == x"},
+		{ChangeRemoved, "shorten: len(result) <= len(url)", "shorten"},
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), diff.Changes)
+	}
+	for i, exp := range expected {
+		c := diff.Changes[i]
+		if c.Kind != exp.kind || c.Category != CategoryProperty || c.Path != exp.path || c.Owner != exp.owner {
+			t.Errorf("Change %d = %s %s %s (owner %s), expected %s property %s (owner %s)", i, c.Kind, c.Category, c.Path, c.Owner, exp.kind, exp.path, exp.owner)
+		}
+	}
+}
+
+func TestCompareCommands(t *testing.T) {
+	spec := func(commands ...specparser.SpecCommand) *specparser.SpecAnalysis {
+		return &specparser.SpecAnalysis{Name: "cli", Commands: commands}
+	}
+	migrate := specparser.SpecCommand{
+		Name:   "migrate",
+		Parent: "db",
+		Args:   []specparser.SpecParameter{{Name: "target", Type: "string", Required: true}},
+		Flags:  []specparser.SpecFlag{{Name: "dry-run", Type: "bool"}, {Name: "timeout", Type: "int", Default: "30"}},
+	}
+	changed := migrate
+	changed.Args = nil
+	changed.Flags = []specparser.SpecFlag{{Name: "dry-run", Type: "bool"}, {Name: "timeout", Type: "duration", Default: "30s"}, {Name: "force", Short: "f", Type: "bool"}}
+	seed := specparser.SpecCommand{Name: "seed", Parent: "db"}
+	status := specparser.SpecCommand{Name: "status", Output: "json"}
+
+	diff := Compare(spec(migrate, seed), spec(changed, status))
+
+	expected := []struct {
+		kind     ChangeKind
+		category Category
+		path     string
+		owner    string
+	}{
+		{ChangeModified, CategoryCommand, "db migrate", "db migrate"},
+		{ChangeRemoved, CategoryCommand, "db seed", "db seed"},
+		{ChangeAdded, CategoryCommand, "status", "status"},
+		{ChangeAdded, CategoryFlag, "db migrate --force", "db migrate"},
+		{ChangeModified, CategoryFlag, "db migrate --timeout", "db migrate"},
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), diff.Changes)
+	}
+	for i, exp := range expected {
+		c := diff.Changes[i]
+		if c.Kind != exp.kind || c.Category != exp.category || c.Path != exp.path || c.Owner != exp.owner {
+			t.Errorf("Change %d = %s %s %s (owner %s), expected %s %s %s (owner %s)", i, c.Kind, c.Category, c.Path, c.Owner, exp.kind, exp.category, exp.path, exp.owner)
+		}
+	}
+	if details := strings.Join(diff.Changes[0].Details, "; "); details != "arguments changed from <target: string> to none" {
+		t.Errorf("Unexpected command details: %s", details)
+	}
+	if after := diff.Changes[3].After; after != "--force, -f: bool" {
+		t.Errorf("Unexpected flag rendering: %s", after)
+	}
+	if !strings.Contains(diff.Markdown(), "## Command Flags") {
+		t.Error("Expected changelog to contain command flags")
+	}
+}
+
+func TestCompareStateMachines(t *testing.T) {
+	spec := func(machines ...specparser.SpecStateMachine) *specparser.SpecAnalysis {
+		return &specparser.SpecAnalysis{Name: "orders", StateMachines: machines}
+	}
+	order := specparser.SpecStateMachine{
+		Name:   "Order",
+		States: []specparser.SpecState{{Name: "pending"}, {Name: "paid"}, {Name: "cancelled", Final: true}},
+		Events: []specparser.SpecEvent{{Name: "pay"}, {Name: "cancel"}},
+		Transitions: []specparser.SpecTransition{
+			{From: "pending", Event: "pay", To: "paid", Guard: "paymentValid"},
+			{From: "pending", Event: "cancel", To: "cancelled"},
+		},
+	}
+	changed := order
+	changed.States = []specparser.SpecState{{Name: "pending"}, {Name: "paid"}, {Name: "shipped", Final: true}, {Name: "cancelled", Final: true}}
+	changed.Events = []specparser.SpecEvent{{Name: "pay", Description: "Payment captured"}, {Name: "cancel"}, {Name: "ship"}}
+	changed.Transitions = []specparser.SpecTransition{
+		{From: "pending", Event: "pay", To: "paid", Guard: "paymentValid", Action: "capturePayment"},
+		{From: "paid", Event: "ship", To: "shipped"},
+	}
+	door := specparser.SpecStateMachine{Name: "Door", States: []specparser.SpecState{{Name: "open"}, {Name: "closed"}}}
+
+	diff := Compare(spec(order), spec(changed, door))
+
+	expected := []struct {
+		kind     ChangeKind
+		category Category
+		path     string
+	}{
+		{ChangeAdded, CategoryMachine, "Door"},
+		{ChangeAdded, CategoryState, "Order.shipped"},
+		{ChangeModified, CategoryEvent, "Order.pay"},
+		{ChangeAdded, CategoryEvent, "Order.ship"},
+		{ChangeAdded, CategoryTransition, "Order: paid on ship"},
+		{ChangeRemoved, CategoryTransition, "Order: pending on cancel"},
+		{ChangeModified, CategoryTransition, "Order: pending on pay [paymentValid]"},
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), diff.Changes)
+	}
+	for i, exp := range expected {
+		c := diff.Changes[i]
+		if c.Kind != exp.kind || c.Category != exp.category || c.Path != exp.path {
+			t.Errorf("Change %d = %s %s %s, expected %s %s %s", i, c.Kind, c.Category, c.Path, exp.kind, exp.category, exp.path)
+		}
+	}
+	if after := diff.Changes[6].After; after != "pending --pay [paymentValid]--> paid / capturePayment" {
+		t.Errorf("Unexpected transition rendering: %s", after)
+	}
+	if affected := strings.Join(diff.AffectedElements, ","); affected != "Door,Order" {
+		t.Errorf("Unexpected affected elements: %s", affected)
+	}
+}
+
+func TestCompareTables(t *testing.T) {
+	spec := func(tables ...specparser.SpecTable) *specparser.SpecAnalysis {
+		return &specparser.SpecAnalysis{Name: "shop", Tables: tables}
+	}
+	users := specparser.SpecTable{
+		Name:       "users",
+		Entity:     "User",
+		PrimaryKey: []string{"id"},
+		Columns: []specparser.SpecColumn{
+			{Name: "id", Type: "int64", AutoIncrement: true},
+			{Name: "email", Type: "string", SQLType: "VARCHAR(255)"},
+			{Name: "nickname", Type: "string", Nullable: true},
+		},
+	}
+	changed := users
+	changed.Indexes = []specparser.SpecIndex{{Name: "users_email", Columns: []string{"email"}, Unique: true}}
+	changed.Columns = []specparser.SpecColumn{
+		{Name: "id", Type: "int64", AutoIncrement: true},
+		{Name: "email", Type: "string", SQLType: "VARCHAR(320)"},
+		{Name: "created_at", Type: "datetime", Default: "CURRENT_TIMESTAMP"},
+	}
+	orders := specparser.SpecTable{Name: "orders", Columns: []specparser.SpecColumn{{Name: "id", Type: "int64"}}}
+
+	diff := Compare(spec(users, orders), spec(changed))
+
+	expected := []struct {
+		kind     ChangeKind
+		category Category
+		path     string
+		details  string
+	}{
+		{ChangeRemoved, CategoryTable, "orders", ""},
+		{ChangeModified, CategoryTable, "users", "indexes changed"},
+		{ChangeAdded, CategoryColumn, "users.created_at", ""},
+		{ChangeModified, CategoryColumn, "users.email", "SQL type changed from VARCHAR(255) to VARCHAR(320)"},
+		{ChangeRemoved, CategoryColumn, "users.nickname", ""},
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), diff.Changes)
+	}
+	for i, exp := range expected {
+		c := diff.Changes[i]
+		if c.Kind != exp.kind || c.Category != exp.category || c.Path != exp.path || strings.Join(c.Details, "; ") != exp.details {
+			t.Errorf("Change %d = %s %s %s %v, expected %s %s %s [%s]", i, c.Kind, c.Category, c.Path, c.Details, exp.kind, exp.category, exp.path, exp.details)
+		}
+	}
+	if !strings.Contains(diff.Markdown(), "## Columns") {
+		t.Error("Expected changelog to contain columns")
+	}
+}
+
+func TestCompareEndpoints(t *testing.T) {
+	spec := func(endpoints ...specparser.SpecEndpoint) *specparser.SpecAnalysis {
+		return &specparser.SpecAnalysis{Name: "tasks", Endpoints: endpoints}
+	}
+	getTask := specparser.SpecEndpoint{
+		Name:        "getTask",
+		Method:      "GET",
+		Path:        "/tasks/{id}",
+		PathParams:  []specparser.SpecParameter{{Name: "id", Type: "int", Required: true}},
+		QueryParams: []specparser.SpecParameter{{Name: "verbose", Type: "bool", Default: "false"}},
+		Responses:   []specparser.SpecResponse{{Status: "200", Type: "Task"}, {Status: "404"}},
+	}
+	changed := getTask
+	changed.Path = "/v2/tasks/{id}"
+	changed.QueryParams = []specparser.SpecParameter{{Name: "verbose", Type: "bool", Default: "true"}}
+	changed.HeaderParams = []specparser.SpecParameter{{Name: "X-Trace", Type: "string"}}
+	changed.Responses = []specparser.SpecResponse{{Status: "200", Type: "TaskView"}}
+	health := specparser.SpecEndpoint{Method: "GET", Path: "/health"}
+	deleteTask := specparser.SpecEndpoint{Method: "DELETE", Path: "/tasks/{id}"}
+
+	diff := Compare(spec(getTask, health), spec(changed, deleteTask))
+
+	expected := []struct {
+		kind     ChangeKind
+		category Category
+		path     string
+		details  string
+	}{
+		{ChangeAdded, CategoryEndpoint, "DELETE /tasks/{id}", ""},
+		{ChangeRemoved, CategoryEndpoint, "GET /health", ""},
+		{ChangeModified, CategoryEndpoint, "getTask", "path changed from /tasks/{id} to /v2/tasks/{id}"},
+		{ChangeAdded, CategoryInput, "getTask header X-Trace", ""},
+		{ChangeModified, CategoryInput, "getTask query verbose", `default changed from "false" to "true"`},
+		{ChangeModified, CategoryResponse, "getTask 200", "body changed from Task to TaskView"},
+		{ChangeRemoved, CategoryResponse, "getTask 404", ""},
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), diff.Changes)
+	}
+	for i, exp := range expected {
+		c := diff.Changes[i]
+		if c.Kind != exp.kind || c.Category != exp.category || c.Path != exp.path || strings.Join(c.Details, "; ") != exp.details {
+			t.Errorf("Change %d = %s %s %s %v, expected %s %s %s [%s]", i, c.Kind, c.Category, c.Path, c.Details, exp.kind, exp.category, exp.path, exp.details)
+		}
+	}
+	if after := diff.Changes[2].After; after != "GET /v2/tasks/{id} (getTask)" {
+		t.Errorf("Unexpected endpoint rendering: %s", after)
+	}
+	if !strings.Contains(diff.Markdown(), "## API Endpoints") {
+		t.Error("Expected changelog to contain API endpoints")
+	}
+}
+
+func TestCompareDependencies(t *testing.T) {
+	spec := func(deps ...specparser.SpecDependency) *specparser.SpecAnalysis {
+		return &specparser.SpecAnalysis{Name: "shop", Dependencies: deps}
+	}
+	uuid := specparser.SpecDependency{Name: "uuid", Version: "1.6.0", Packages: map[string]string{"go": "github.com/google/uuid"}}
+	bumped := uuid
+	bumped.Version = "1.7.0"
+	decimal := specparser.SpecDependency{Name: "decimal", Version: "1.3.1"}
+	fmtPkg := specparser.SpecDependency{Name: "fmt", Packages: map[string]string{"go": "fmt"}, Stdlib: true}
+
+	diff := Compare(spec(uuid, decimal), spec(bumped, fmtPkg))
+
+	expected := []struct {
+		kind     ChangeKind
+		category Category
+		path     string
+		details  string
+	}{
+		{ChangeRemoved, CategoryDependency, "decimal", ""},
+		{ChangeAdded, CategoryDependency, "fmt (go)", ""},
+		{ChangeModified, CategoryDependency, "uuid", "version changed from 1.6.0 to 1.7.0"},
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), diff.Changes)
+	}
+	for i, exp := range expected {
+		c := diff.Changes[i]
+		if c.Kind != exp.kind || c.Category != exp.category || c.Path != exp.path || strings.Join(c.Details, "; ") != exp.details {
+			t.Errorf("Change %d = %s %s %s %v, expected %s %s %s [%s]", i, c.Kind, c.Category, c.Path, c.Details, exp.kind, exp.category, exp.path, exp.details)
+		}
+	}
+	if after := diff.Changes[2].After; after != "uuid@1.7.0" {
+		t.Errorf("Unexpected dependency rendering: %s", after)
+	}
+	if !strings.Contains(diff.Markdown(), "## Dependencies") {
+		t.Error("Expected changelog to contain dependencies")
+	}
+}
@@ -0,0 +1,41 @@
+package specdiff
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ReadAtRef returns the content of a file as of the given git ref
+// (branch, tag or commit SHA) in the repository containing the file.
+func ReadAtRef(path, ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("git ref cannot be empty")
+	}
+	if strings.HasPrefix(ref, "-") || strings.Contains(ref, ":") {
+		return "", fmt.Errorf("invalid git ref: %s", ref)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git is not installed or not in PATH: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	// "<ref>:./<file>" resolves the file relative to the command's working directory
+	cmd := exec.Command("git", "show", fmt.Sprintf("%s:./%s", ref, filepath.Base(absPath)))
+	cmd.Dir = filepath.Dir(absPath)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git show %s failed: %w\nOutput: %s", ref, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git show %s failed: %w", ref, err)
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,93 @@
+package specdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// categoryTitles maps categories to Markdown section titles.
+var categoryTitles = map[Category]string{
+	CategoryType:       "Types",
+	CategoryField:      "Fields",
+	CategoryValue:      "Enum Values",
+	CategoryMethod:     "Methods",
+	CategoryFunction:   "Functions",
+	CategoryParameter:  "Parameters",
+	CategoryError:      "Error Conditions",
+	CategoryEndpoint:   "API Endpoints",
+	CategoryInput:      "Endpoint Inputs",
+	CategoryResponse:   "Responses",
+	CategoryCommand:    "Commands",
+	CategoryFlag:       "Command Flags",
+	CategoryMachine:    "State Machines",
+	CategoryState:      "States",
+	CategoryEvent:      "Events",
+	CategoryTransition: "Transitions",
+	CategoryTable:      "Tables",
+	CategoryColumn:     "Columns",
+	CategoryTest:       "Tests",
+	CategoryProperty:   "Properties",
+	CategoryConfig:     "Configuration",
+	CategoryDependency: "Dependencies",
+}
+
+// Markdown renders the diff as a Markdown changelog.
+func (d *SpecDiff) Markdown() string {
+	var sb strings.Builder
+
+	if d.OldName == d.NewName {
+		sb.WriteString(fmt.Sprintf("# Spec Changelog: %s\n\n", d.NewName))
+	} else {
+		sb.WriteString(fmt.Sprintf("# Spec Changelog: %s -> %s\n\n", d.OldName, d.NewName))
+	}
+
+	if !d.HasChanges() {
+		sb.WriteString("No semantic changes detected.\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("**Added:** %d | **Removed:** %d | **Modified:** %d\n\n",
+		d.Summary.Added, d.Summary.Removed, d.Summary.Modified))
+
+	sb.WriteString("## Affected Elements\n\n")
+	for _, name := range d.AffectedElements {
+		sb.WriteString(fmt.Sprintf("- `%s`\n", name))
+	}
+	sb.WriteString("\n")
+
+	for _, category := range categoryOrder {
+		changes := d.byCategory(category)
+		if len(changes) == 0 {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("## %s\n\n", categoryTitles[category]))
+		for _, c := range changes {
+			switch c.Kind {
+			case ChangeAdded:
+				sb.WriteString(fmt.Sprintf("- **Added** `%s`\n", c.After))
+			case ChangeRemoved:
+				sb.WriteString(fmt.Sprintf("- **Removed** `%s`\n", c.Before))
+			case ChangeModified:
+				sb.WriteString(fmt.Sprintf("- **Modified** `%s`\n", c.Path))
+				for _, detail := range c.Details {
+					sb.WriteString(fmt.Sprintf("  - %s\n", detail))
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// byCategory returns the changes for a single category.
+func (d *SpecDiff) byCategory(category Category) []Change {
+	var result []Change
+	for _, c := range d.Changes {
+		if c.Category == category {
+			result = append(result, c)
+		}
+	}
+	return result
+}
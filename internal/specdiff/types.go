@@ -0,0 +1,122 @@
+// Package specdiff computes semantic differences between two spec revisions.
+package specdiff
+
+// ChangeKind describes how a spec element changed between revisions.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Category identifies the kind of spec element a change applies to.
+type Category string
+
+const (
+	CategoryType       Category = "type"
+	CategoryField      Category = "field"
+	CategoryValue      Category = "value"
+	CategoryMethod     Category = "method"
+	CategoryFunction   Category = "function"
+	CategoryParameter  Category = "parameter"
+	CategoryError      Category = "error"
+	CategoryEndpoint   Category = "endpoint"
+	CategoryInput      Category = "input"
+	CategoryResponse   Category = "response"
+	CategoryCommand    Category = "command"
+	CategoryFlag       Category = "flag"
+	CategoryMachine    Category = "state machine"
+	CategoryState      Category = "state"
+	CategoryEvent      Category = "event"
+	CategoryTransition Category = "transition"
+	CategoryTable      Category = "table"
+	CategoryColumn     Category = "column"
+	CategoryTest       Category = "test"
+	CategoryProperty   Category = "property"
+	CategoryConfig     Category = "config"
+	CategoryDependency Category = "dependency"
+)
+
+// categoryOrder is the order in which categories are reported.
+var categoryOrder = []Category{
+	CategoryType,
+	CategoryField,
+	CategoryValue,
+	CategoryMethod,
+	CategoryFunction,
+	CategoryParameter,
+	CategoryError,
+	CategoryEndpoint,
+	CategoryInput,
+	CategoryResponse,
+	CategoryCommand,
+	CategoryFlag,
+	CategoryMachine,
+	CategoryState,
+	CategoryEvent,
+	CategoryTransition,
+	CategoryTable,
+	CategoryColumn,
+	CategoryTest,
+	CategoryProperty,
+	CategoryConfig,
+	CategoryDependency,
+}
+
+// SpecDiff contains the semantic differences between two spec revisions.
+type SpecDiff struct {
+	// OldName is the name of the old spec revision
+	OldName string `json:"oldName"`
+
+	// NewName is the name of the new spec revision
+	NewName string `json:"newName"`
+
+	// Changes lists every detected change, ordered by category and path
+	Changes []Change `json:"changes"`
+
+	// Summary contains change counts
+	Summary Summary `json:"summary"`
+
+	// AffectedElements lists top-level types, functions, endpoints,
+	// commands, state machines, tables, tests, config items and
+	// dependencies whose generated code is affected by the changes
+	AffectedElements []string `json:"affectedElements"`
+}
+
+// Change describes a single added, removed or modified spec element.
+type Change struct {
+	// Kind is added, removed or modified
+	Kind ChangeKind `json:"kind"`
+
+	// Category is the element category (type, field, function, ...)
+	Category Category `json:"category"`
+
+	// Path identifies the element, e.g. "User.email" or "CreateUser(name)"
+	Path string `json:"path"`
+
+	// Owner is the top-level element (type, function, endpoint, command, state machine, table, test, config or dependency) that contains this element
+	Owner string `json:"owner"`
+
+	// Before is a one-line rendering of the old definition
+	Before string `json:"before,omitempty"`
+
+	// After is a one-line rendering of the new definition
+	After string `json:"after,omitempty"`
+
+	// Details lists what changed for modified elements
+	Details []string `json:"details,omitempty"`
+}
+
+// Summary contains change counts.
+type Summary struct {
+	Added      int              `json:"added"`
+	Removed    int              `json:"removed"`
+	Modified   int              `json:"modified"`
+	ByCategory map[Category]int `json:"byCategory"`
+}
+
+// HasChanges reports whether any change was detected.
+func (d *SpecDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
@@ -0,0 +1,334 @@
+package specparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// jsonSchemaDialect is the JSON Schema version types are exported as.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// RenderJSONSchema exports a spec's types as a JSON Schema (draft 2020-12)
+// document with one $defs entry per type. Required fields, defaults,
+// constraints, enums and unions map onto their JSON Schema counterparts:
+// a field that is not required is left out of "required", and only an
+// Optional type is nullable. Property order follows the spec so the
+// document imports back unchanged.
+func RenderJSONSchema(spec *SpecAnalysis) ([]byte, error) {
+	names := make(map[string]string)
+	for _, t := range spec.Types {
+		names[strings.ToLower(t.Name)] = t.Name
+	}
+	e := &schemaExporter{names: names}
+
+	defs := &jsonObject{}
+	for _, t := range spec.Types {
+		defs.set(t.Name, e.typeSchema(t))
+	}
+
+	doc := &jsonObject{}
+	doc.set("$schema", jsonSchemaDialect)
+	if spec.Name != "" {
+		doc.set("title", spec.Name)
+	}
+	if spec.Overview != "" {
+		doc.set("description", spec.Overview)
+	}
+	doc.set("$defs", defs)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// schemaExporter converts spec types, resolving type references
+// case-insensitively since parsed specs may lowercase type names.
+type schemaExporter struct {
+	names map[string]string
+}
+
+// typeSchema converts a single type definition.
+func (e *schemaExporter) typeSchema(t SpecType) *jsonObject {
+	s := &jsonObject{}
+	if t.Description != "" {
+		s.set("description", t.Description)
+	}
+
+	switch t.Kind {
+	case "enum":
+		// Plain string enums stay compact; values with an underlying value or
+		// a description use titled constants
+		detailed := false
+		for _, v := range t.Values {
+			if (v.Value != "" && v.Value != v.Name) || v.Description != "" {
+				detailed = true
+			}
+		}
+		if !detailed {
+			var values []string
+			for _, v := range t.Values {
+				values = append(values, v.Name)
+			}
+			s.set("enum", values)
+			return s
+		}
+		var options []*jsonObject
+		for _, v := range t.Values {
+			option := &jsonObject{}
+			option.set("title", v.Name)
+			if v.Value != "" {
+				option.set("const", literal(v.Value))
+			} else {
+				option.set("const", v.Name)
+			}
+			if v.Description != "" {
+				option.set("description", v.Description)
+			}
+			options = append(options, option)
+		}
+		s.set("oneOf", options)
+
+	case "union":
+		var options []*jsonObject
+		for _, f := range t.Fields {
+			option := e.schemaFor(f.Type)
+			if f.Name != f.Type {
+				option.set("title", f.Name)
+			}
+			options = append(options, option)
+		}
+		s.set("oneOf", options)
+
+	case "alias":
+		if t.AliasOf != "" {
+			target := e.schemaFor(t.AliasOf)
+			for _, key := range target.keys {
+				s.set(key, target.values[key])
+			}
+		}
+
+	default:
+		s.set("type", "object")
+		properties := &jsonObject{}
+		var required []string
+		for _, f := range t.Fields {
+			fieldType, optional := unwrapOptional(f.Type)
+			prop := e.schemaFor(fieldType)
+			constrain(prop, f.Constraints)
+			if optional {
+				prop = nullable(prop)
+			}
+			if f.Description != "" {
+				prop.set("description", f.Description)
+			}
+			if f.Default != "" {
+				prop.set("default", literal(f.Default))
+			}
+			properties.set(f.Name, prop)
+			if f.Required && !optional {
+				required = append(required, f.Name)
+			}
+		}
+		if len(properties.keys) > 0 {
+			s.set("properties", properties)
+		}
+		if len(required) > 0 {
+			s.set("required", required)
+		}
+	}
+
+	return s
+}
+
+// schemaFor converts a pseudo-type to a schema.
+func (e *schemaExporter) schemaFor(typ string) *jsonObject {
+	typ = strings.TrimSpace(typ)
+	s := &jsonObject{}
+
+	if inner, optional := unwrapOptional(typ); optional {
+		return nullable(e.schemaFor(inner))
+	}
+	if head, args, ok := splitGeneric(typ); ok {
+		switch strings.ToLower(head) {
+		case "list":
+			s.set("type", "array")
+			s.set("items", e.schemaFor(args[0]))
+			return s
+		case "map":
+			s.set("type", "object")
+			s.set("additionalProperties", e.schemaFor(args[len(args)-1]))
+			return s
+		}
+	}
+
+	switch strings.ToLower(typ) {
+	case "string":
+		s.set("type", "string")
+	case "int":
+		s.set("type", "integer")
+	case "int64":
+		s.set("type", "integer")
+		s.set("format", "int64")
+	case "float":
+		s.set("type", "number")
+	case "bool":
+		s.set("type", "boolean")
+	case "bytes":
+		s.set("type", "string")
+		s.set("contentEncoding", "base64")
+	case "date":
+		s.set("type", "string")
+		s.set("format", "date")
+	case "datetime":
+		s.set("type", "string")
+		s.set("format", "date-time")
+	case "uuid":
+		s.set("type", "string")
+		s.set("format", "uuid")
+	case "any", "":
+	default:
+		name := typ
+		if known, ok := e.names[strings.ToLower(typ)]; ok {
+			name = known
+		}
+		s.set("$ref", "#/$defs/"+name)
+	}
+	return s
+}
+
+// constrain adds a field's constraints to its schema as validation
+// keywords. Lengths bound the items of an array and the properties of a
+// map.
+func constrain(s *jsonObject, constraints []SpecConstraint) {
+	for _, c := range constraints {
+		switch c.Kind {
+		case "min":
+			s.set("minimum", literal(c.Value))
+		case "max":
+			s.set("maximum", literal(c.Value))
+		case "minLength", "maxLength":
+			keyword := c.Kind
+			switch s.values["type"] {
+			case "array":
+				keyword = strings.Replace(keyword, "Length", "Items", 1)
+			case "object":
+				keyword = strings.Replace(keyword, "Length", "Properties", 1)
+			}
+			s.set(keyword, literal(c.Value))
+		case "pattern":
+			s.set("pattern", c.Value)
+		case "format":
+			if c.Value == "url" {
+				s.set("format", "uri")
+			} else {
+				s.set("format", c.Value)
+			}
+		}
+	}
+}
+
+// nullable allows null in addition to a schema.
+func nullable(s *jsonObject) *jsonObject {
+	if len(s.keys) == 0 {
+		return s
+	}
+	if t, ok := s.values["type"].(string); ok && len(s.keys) == 1 {
+		s.set("type", []string{t, "null"})
+		return s
+	}
+	null := &jsonObject{}
+	null.set("type", "null")
+	wrapped := &jsonObject{}
+	wrapped.set("anyOf", []*jsonObject{s, null})
+	return wrapped
+}
+
+// unwrapOptional strips an Optional[T] wrapper.
+func unwrapOptional(typ string) (string, bool) {
+	if head, args, ok := splitGeneric(typ); ok && strings.EqualFold(head, "optional") && len(args) == 1 {
+		return args[0], true
+	}
+	return typ, false
+}
+
+// splitGeneric splits "Head[A, B]" into its head and top-level arguments.
+func splitGeneric(typ string) (string, []string, bool) {
+	open := strings.Index(typ, "[")
+	if open <= 0 || !strings.HasSuffix(typ, "]") {
+		return "", nil, false
+	}
+
+	var args []string
+	depth, start := 0, open+1
+	for i := open + 1; i < len(typ)-1; i++ {
+		switch typ[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(typ[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	args = append(args, strings.TrimSpace(typ[start:len(typ)-1]))
+	return typ[:open], args, true
+}
+
+// literal returns a default or constant as a JSON value, falling back to a
+// string when it is not valid JSON.
+func literal(value string) any {
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		return json.RawMessage(value)
+	}
+	return value
+}
+
+// jsonObject is a JSON object that keeps its keys in insertion order.
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *jsonObject) set(key string, value any) {
+	if o.values == nil {
+		o.values = make(map[string]any)
+	}
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON writes the object's keys in insertion order.
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -5,7 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Parser parses markdown spec files into structured SpecAnalysis.
@@ -35,6 +38,7 @@ func (p *Parser) Parse(content, name string) (*SpecAnalysis, error) {
 		Tests:         []SpecTest{},
 		Dependencies:  []SpecDependency{},
 		Configuration: []SpecConfig{},
+		Endpoints:     []SpecEndpoint{},
 	}
 
 	// Extract sections based on markdown headers
@@ -44,8 +48,18 @@ func (p *Parser) Parse(content, name string) (*SpecAnalysis, error) {
 		sectionLower := strings.ToLower(sectionName)
 
 		switch {
+		case strings.Contains(sectionLower, "state machine") || strings.Contains(sectionLower, "state diagram"):
+			analysis.StateMachines = append(analysis.StateMachines, parseStateMachines(sectionContent)...)
+		case strings.Contains(sectionLower, "propert") || strings.Contains(sectionLower, "invariant"):
+			analysis.Properties = append(analysis.Properties, parseProperties(sectionContent)...)
+		case strings.Contains(sectionLower, "command") || sectionLower == "cli":
+			analysis.Commands = append(analysis.Commands, parseCommands(sectionContent)...)
+		case hasWord(sectionLower, "database", "table", "tables", "entities"):
+			analysis.Tables = append(analysis.Tables, parseTables(sectionContent)...)
 		case strings.Contains(sectionLower, "type") || strings.Contains(sectionLower, "data") || strings.Contains(sectionLower, "model"):
 			analysis.Types = append(analysis.Types, parseTypes(sectionContent)...)
+		case strings.Contains(sectionLower, "endpoint") || strings.Contains(sectionLower, "route"):
+			analysis.Endpoints = append(analysis.Endpoints, parseEndpoints(sectionContent)...)
 		case strings.Contains(sectionLower, "function") || strings.Contains(sectionLower, "api") || strings.Contains(sectionLower, "method"):
 			analysis.Functions = append(analysis.Functions, parseFunctions(sectionContent)...)
 		case strings.Contains(sectionLower, "test"):
@@ -58,9 +72,25 @@ func (p *Parser) Parse(content, name string) (*SpecAnalysis, error) {
 	}
 
 	analysis.CalculateTotals()
+	analysis.ValidateTypes()
 	return analysis, nil
 }
 
+// hasWord reports whether a heading contains one of the words as a whole
+// word, so "Tables" matches but "Stable API" does not.
+func hasWord(heading string, words ...string) bool {
+	for _, field := range strings.FieldsFunc(heading, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		for _, word := range words {
+			if field == word {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // extractSpecName extracts the spec name from the content or falls back to filename.
 func extractSpecName(content, filename string) string {
 	// Try to get name from H1 header
@@ -148,9 +178,15 @@ func parseTypes(content string) []SpecType {
 	var types []SpecType
 
 	// Pattern for H3/H4 type definitions
-	typePattern := regexp.MustCompile(`(?mi)^###\s+(.+?)\s*(?:\((struct|interface|enum|class|type)\))?\s*$`)
+	typePattern := regexp.MustCompile(`(?mi)^###\s+(.+?)\s*(?:\((struct|interface|enum|union|alias|class|type)\))?\s*$`)
 	typeMatches := typePattern.FindAllStringSubmatchIndex(content, -1)
 
+	// A naming policy before the first type applies to every type
+	defaultNaming := parseNaming(content)
+	if len(typeMatches) > 0 {
+		defaultNaming = parseNaming(content[:typeMatches[0][0]])
+	}
+
 	for i, match := range typeMatches {
 		nameStart := match[2]
 		nameEnd := match[3]
@@ -175,10 +211,24 @@ func parseTypes(content string) []SpecType {
 			Name:        strings.TrimSpace(name),
 			Kind:        kind,
 			Description: extractDescription(sectionContent),
-			Fields:      parseFields(sectionContent),
 			Methods:     parseMethods(sectionContent),
+			AliasOf:     parseAliasOf(sectionContent),
+			Implements:  parseCodeList(sectionContent, "implements"),
+			Directives:  parseCodeList(sectionContent, "directives"),
+			Naming:      parseNaming(sectionContent),
+			Module:      parseModule(sectionContent),
 			IsPublic:    isPublic(name),
 		}
+		if specType.Naming == nil {
+			specType.Naming = defaultNaming
+		}
+
+		// Enum bullets list values rather than fields
+		if kind == "enum" {
+			specType.Values = parseEnumValues(sectionContent)
+		} else {
+			specType.Fields = parseFields(sectionContent)
+		}
 
 		types = append(types, specType)
 	}
@@ -190,61 +240,281 @@ func parseTypes(content string) []SpecType {
 	return types
 }
 
-// parseFields extracts fields from a type section.
-func parseFields(content string) []SpecField {
-	var fields []SpecField
+// parseCodeList extracts the code spans of a "**Label**: `a`, `b`" line.
+func parseCodeList(content, label string) []string {
+	linePattern := regexp.MustCompile(`(?mi)^\*\*` + label + `\*\*:?[ \t]*(.+)$`)
+	match := linePattern.FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
 
-	// Look for markdown table with fields
-	tablePattern := regexp.MustCompile(`(?m)\|(.+)\|(.+)\|(.+)\|`)
-	tableMatches := tablePattern.FindAllStringSubmatch(content, -1)
+	var values []string
+	for _, span := range regexp.MustCompile("\x60([^\x60]+)\x60").FindAllStringSubmatch(match[1], -1) {
+		values = append(values, span[1])
+	}
+	return values
+}
 
-	headerFound := false
-	for _, match := range tableMatches {
-		if len(match) < 4 {
+// parseAliasOf extracts the target of an alias type, e.g.
+// "**Alias of**: `datetime`".
+func parseAliasOf(content string) string {
+	if targets := parseCodeList(content, "alias of"); len(targets) > 0 {
+		return targets[0]
+	}
+	return ""
+}
+
+// parseNaming extracts the naming policies of a "**Naming**: json: snake_case"
+// line, keyed by serialization format.
+func parseNaming(content string) map[string]string {
+	match := regexp.MustCompile(`(?mi)^\*\*naming\*\*:?[ \t]*(.+)$`).FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+
+	naming := make(map[string]string)
+	for _, entry := range strings.Split(strings.ReplaceAll(match[1], "\x60", ""), ",") {
+		format, policy, ok := strings.Cut(entry, ":")
+		if !ok {
+			// A bare policy names the JSON field naming
+			format, policy = "json", entry
+		}
+		if format, policy = strings.TrimSpace(format), strings.TrimSpace(policy); format != "" && policy != "" {
+			naming[strings.ToLower(format)] = policy
+		}
+	}
+	if len(naming) == 0 {
+		return nil
+	}
+	return naming
+}
+
+// fieldTagsPattern matches a code span of struct-tag style serialization
+// tags, e.g. `json:"user_id,omitempty" xml:"uid"`.
+var fieldTagsPattern = regexp.MustCompile("\x60((?:\\w+:\"[^\"]*\"[ \t]*)+)\x60")
+
+// splitFieldTags separates the serialization tags from a field description.
+func splitFieldTags(description string) (string, map[string]string) {
+	match := fieldTagsPattern.FindStringSubmatchIndex(description)
+	if match == nil {
+		return description, nil
+	}
+
+	tags := make(map[string]string)
+	for _, tag := range regexp.MustCompile(`(\w+):"([^"]*)"`).FindAllStringSubmatch(description[match[2]:match[3]], -1) {
+		tags[tag[1]] = tag[2]
+	}
+	rest := strings.TrimSpace(description[:match[0]] + description[match[1]:])
+	return strings.TrimSpace(strings.TrimSuffix(rest, " -")), tags
+}
+
+// defaultPattern matches a "(default: value)" note in a field description.
+var defaultPattern = regexp.MustCompile(`\s*\(default:\s*([^()]+)\)`)
+
+// constraintKeys maps the keys of a constraint block to constraint kinds;
+// length expands to minLength and maxLength, and default sets the default.
+var constraintKeys = map[string]string{
+	"min": "min", "max": "max", "minlength": "minLength", "maxlength": "maxLength",
+	"length": "length", "pattern": "pattern", "format": "format",
+	"oneof": "oneOf", "enum": "oneOf", "default": "default",
+}
+
+// splitFieldConstraints separates the constraint block and default from a
+// field description, e.g. "Age {min: 0, max: 150, default: 18}",
+// "{length: 3..32, pattern: /^[a-z]+$/}" or "{oneOf: [draft, published]}".
+func splitFieldConstraints(description string) (string, []SpecConstraint, string) {
+	var constraints []SpecConstraint
+	def := ""
+	if match := defaultPattern.FindStringSubmatchIndex(description); match != nil {
+		def = strings.TrimSpace(description[match[2]:match[3]])
+		description = description[:match[0]] + description[match[1]:]
+	}
+
+	start := strings.Index(description, "{")
+	for start != -1 {
+		end, entries, ok := scanConstraintBlock(description[start:])
+		if ok {
+			for _, entry := range entries {
+				key, value, _ := strings.Cut(entry, ":")
+				kind := constraintKeys[strings.ToLower(strings.TrimSpace(key))]
+				value = strings.TrimSpace(value)
+				switch kind {
+				case "default":
+					def = value
+				case "length":
+					lo, hi, isRange := strings.Cut(value, "..")
+					if !isRange {
+						hi = lo
+					}
+					if lo = strings.TrimSpace(lo); lo != "" {
+						constraints = append(constraints, SpecConstraint{Kind: "minLength", Value: lo})
+					}
+					if hi = strings.TrimSpace(hi); hi != "" {
+						constraints = append(constraints, SpecConstraint{Kind: "maxLength", Value: hi})
+					}
+				case "pattern":
+					if len(value) >= 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+						value = value[1 : len(value)-1]
+					}
+					constraints = append(constraints, SpecConstraint{Kind: kind, Value: value})
+				case "oneOf":
+					var values []string
+					for _, v := range strings.Split(strings.Trim(value, "[]"), ",") {
+						if v = strings.Trim(strings.TrimSpace(v), `"'`); v != "" {
+							values = append(values, v)
+						}
+					}
+					constraints = append(constraints, SpecConstraint{Kind: kind, Values: values})
+				default:
+					constraints = append(constraints, SpecConstraint{Kind: kind, Value: value})
+				}
+			}
+			description = description[:start] + description[start+end:]
+		} else {
+			start++
+		}
+		next := strings.Index(description[start:], "{")
+		if next == -1 {
+			break
+		}
+		start += next
+	}
+
+	return strings.Join(strings.Fields(description), " "), constraints, def
+}
+
+// scanConstraintBlock scans a "{key: value, ...}" block at the start of s,
+// returning its length and entries. Commas and braces inside /regex/ values
+// and [lists] do not end an entry.
+func scanConstraintBlock(s string) (int, []string, bool) {
+	var entries []string
+	var entry strings.Builder
+	inPattern, depth := false, 0
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case inPattern:
+			if ch == '\\' && i+1 < len(s) {
+				entry.WriteByte(ch)
+				i++
+				ch = s[i]
+			} else if ch == '/' {
+				inPattern = false
+			}
+		case ch == '/' && strings.HasSuffix(strings.TrimSpace(entry.String()), ":"):
+			inPattern = true
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+		case ch == ',' && depth == 0, ch == '}' && depth == 0:
+			key, _, ok := strings.Cut(entry.String(), ":")
+			if !ok || constraintKeys[strings.ToLower(strings.TrimSpace(key))] == "" {
+				return 0, nil, false
+			}
+			entries = append(entries, entry.String())
+			entry.Reset()
+			if ch == '}' {
+				return i + 1, entries, true
+			}
 			continue
 		}
+		entry.WriteByte(ch)
+	}
+	return 0, nil, false
+}
 
-		col1 := strings.TrimSpace(match[1])
-		col2 := strings.TrimSpace(match[2])
-		col3 := strings.TrimSpace(match[3])
+// parseFields extracts fields from a type section.
+func parseFields(content string) []SpecField {
+	var fields []SpecField
 
-		// Skip header and separator rows
-		if strings.Contains(col1, "---") || strings.Contains(col2, "---") {
-			headerFound = true
+	// Look for markdown tables with fields, matching columns by header so a
+	// table may carry extra columns such as Required or Default
+	lines := strings.Split(content, "\n")
+	var header []string
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			header = nil
 			continue
 		}
-		if strings.ToLower(col1) == "field" || strings.ToLower(col1) == "name" {
-			headerFound = true
+		if isSeparatorRow(line) {
+			continue
+		}
+		cols := parseTableRow(line)
+		if header == nil {
+			if i+1 < len(lines) && isSeparatorRow(lines[i+1]) {
+				header = fieldColumns(cols)
+			}
 			continue
 		}
 
-		if !headerFound {
+		var name, typ, desc, def string
+		required := true
+		for j, col := range cols {
+			if j >= len(header) {
+				break
+			}
+			switch header[j] {
+			case "name":
+				name = strings.Trim(col, "`")
+			case "type":
+				typ = strings.Trim(col, "`")
+				required = required && !strings.Contains(strings.ToLower(typ), "optional")
+			case "required":
+				required = isAffirmative(col)
+			case "default":
+				if d := strings.Trim(col, "`"); d != "-" && d != "—" {
+					def = d
+				}
+			case "description":
+				desc = col
+			}
+		}
+		if name == "" {
 			continue
 		}
 
+		desc, tags := splitFieldTags(desc)
+		desc, constraints, noted := splitFieldConstraints(desc)
+		if def == "" {
+			def = noted
+		}
 		field := SpecField{
-			Name:        col1,
-			Type:        col2,
-			Description: col3,
-			Required:    !strings.Contains(strings.ToLower(col2), "optional"),
+			Name:        name,
+			Type:        typ,
+			Description: desc,
+			Required:    required,
+			Tags:        tags,
+			Default:     def,
+			Constraints: constraints,
+		}
+		// name? marks a field that may be left out
+		if name, ok := strings.CutSuffix(field.Name, "?"); ok {
+			field.Name, field.Required = name, false
 		}
 		fields = append(fields, field)
 	}
 
 	// Also look for bullet list fields
-	bulletPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?(\w+)\x60?\s*[:\-]\s*\x60?([^\x60\s]+)\x60?\s*[-:]?\s*(.*)$`)
+	bulletPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?(\w+)(\??)\x60?[ \t]*[:\-][ \t]*(\x60[^\x60]+\x60|[^\x60\s]+)[ \t]*[-:]?[ \t]*(.*)$`)
 	bulletMatches := bulletPattern.FindAllStringSubmatch(content, -1)
 
 	for _, match := range bulletMatches {
-		if len(match) < 4 {
+		if len(match) < 5 {
 			continue
 		}
 
+		desc, tags := splitFieldTags(strings.TrimSpace(match[4]))
+		desc, constraints, def := splitFieldConstraints(desc)
 		field := SpecField{
 			Name:        match[1],
-			Type:        match[2],
-			Description: strings.TrimSpace(match[3]),
-			Required:    true,
+			Type:        strings.Trim(match[3], "\x60"),
+			Description: desc,
+			Required:    match[2] == "",
+			Tags:        tags,
+			Default:     def,
+			Constraints: constraints,
 		}
 		fields = append(fields, field)
 	}
@@ -252,23 +522,137 @@ func parseFields(content string) []SpecField {
 	return fields
 }
 
-// parseMethods extracts method signatures from an interface section.
-func parseMethods(content string) []string {
-	var methods []string
+// parseEnumValues extracts enum values from bullet lists of the form
+// "- `NAME`", "- `NAME` = `value`" or "- `NAME` - description".
+func parseEnumValues(content string) []SpecEnumValue {
+	var values []SpecEnumValue
+
+	valuePattern := regexp.MustCompile(`(?m)^[-*][ \t]+\x60?([^\x60\s=:]+)\x60?(?:[ \t]*=[ \t]*\x60?([^\x60\s]+)\x60?)?(?:[ \t]*[-:][ \t]*(.*))?$`)
+	for _, match := range valuePattern.FindAllStringSubmatch(content, -1) {
+		values = append(values, SpecEnumValue{
+			Name:        match[1],
+			Value:       match[2],
+			Description: strings.TrimSpace(match[3]),
+		})
+	}
+
+	return values
+}
 
-	// Look for method signatures in code blocks or bullet lists
-	methodPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60([A-Z]\w+\([^)]*\)[^)\x60]*)\x60`)
-	matches := methodPattern.FindAllStringSubmatch(content, -1)
+// parseMethods extracts the method signatures of an interface section,
+// listed as bullets such as "- `find(id: string, limit?: int): User` -
+// Looks up a user".
+func parseMethods(content string) []SpecFunction {
+	var methods []SpecFunction
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			methods = append(methods, match[1])
+	methodPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60((?:async\s+)?[A-Za-z_]\w*\([^\x60]*)\x60(?:[ \t]*[-:][ \t]*(.*))?$`)
+	for _, match := range methodPattern.FindAllStringSubmatch(content, -1) {
+		method, ok := parseSignature(match[1])
+		if !ok {
+			continue
 		}
+		method.Description = strings.TrimSpace(match[2])
+		methods = append(methods, method)
 	}
 
 	return methods
 }
 
+// parseSignature parses a method signature. Parameters are written
+// "name: Type", with a "?" after the name when optional and "= value" for a
+// default, or Go-style as "name Type". The return type follows the
+// parameters after ":", "->" or a space; "(A, B)" returns several values.
+func parseSignature(sig string) (SpecFunction, bool) {
+	sig = strings.TrimSpace(sig)
+	var fn SpecFunction
+	if rest, ok := strings.CutPrefix(sig, "async "); ok {
+		fn.IsAsync = true
+		sig = strings.TrimSpace(rest)
+	}
+
+	lparen := strings.Index(sig, "(")
+	rparen := matchingParen(sig, lparen)
+	if lparen <= 0 || rparen < 0 {
+		return fn, false
+	}
+	fn.Name = strings.TrimSpace(sig[:lparen])
+	fn.IsPublic = true
+
+	for _, param := range splitTopLevel(sig[lparen+1 : rparen]) {
+		p := SpecParameter{Required: true}
+		if name, def, ok := strings.Cut(param, "="); ok {
+			param, p.Default, p.Required = strings.TrimSpace(name), strings.TrimSpace(def), false
+		}
+		if name, typ, ok := strings.Cut(param, ":"); ok {
+			p.Name, p.Type = strings.TrimSpace(name), strings.TrimSpace(typ)
+		} else if name, typ, ok := strings.Cut(param, " "); ok {
+			p.Name, p.Type = name, strings.TrimSpace(typ)
+		} else {
+			p.Name = param
+		}
+		if name, ok := strings.CutSuffix(p.Name, "?"); ok {
+			p.Name, p.Required = name, false
+		}
+		fn.Parameters = append(fn.Parameters, p)
+	}
+
+	ret := strings.TrimSpace(sig[rparen+1:])
+	ret = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(ret, "->"), ":"))
+	if strings.HasPrefix(ret, "(") && matchingParen(ret, 0) == len(ret)-1 {
+		for _, r := range splitTopLevel(ret[1 : len(ret)-1]) {
+			fn.Returns = append(fn.Returns, SpecReturn{Type: r})
+		}
+	} else if ret != "" && ret != "void" {
+		fn.Returns = []SpecReturn{{Type: ret}}
+	}
+	return fn, true
+}
+
+// matchingParen returns the index of the parenthesis closing the one at
+// open, or -1.
+func matchingParen(s string, open int) int {
+	if open < 0 || open >= len(s) || s[open] != '(' {
+		return -1
+	}
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits a comma-separated list, keeping the commas nested in
+// brackets such as those of Map<string, int>.
+func splitTopLevel(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '(', '<', '[', '{':
+			depth++
+		case ')', '>', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(list[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
 // parseTypesFromTables extracts type definitions from markdown tables.
 func parseTypesFromTables(content string) []SpecType {
 	var types []SpecType
@@ -290,13 +674,16 @@ func parseTypesFromTables(content string) []SpecType {
 			cols := parseTableRow(line)
 
 			if !inTable {
-				// Check if this is a header row
-				headerLower := strings.ToLower(strings.Join(cols, " "))
-				if strings.Contains(headerLower, "type") || strings.Contains(headerLower, "name") {
-					inTable = true
-					headerCols = cols
-					continue
+				// Only a header row whose first column is Name or Type starts a
+				// type table; field tables under a type heading are skipped
+				if i+1 < len(lines) && isSeparatorRow(lines[i+1]) {
+					first := strings.ToLower(cols[0])
+					if first == "type" || first == "name" {
+						inTable = true
+						headerCols = cols
+					}
 				}
+				continue
 			}
 
 			// Skip separator row
@@ -338,17 +725,36 @@ func parseTypesFromTables(content string) []SpecType {
 	return types
 }
 
-// parseTableRow parses a markdown table row into columns.
+// isSeparatorRow reports whether a line is a markdown table separator.
+func isSeparatorRow(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "|") && strings.Trim(line, "|-: ") == ""
+}
+
+// parseTableRow parses a markdown table row into columns. An escaped "\|"
+// is kept as a pipe inside its cell.
 func parseTableRow(row string) []string {
 	// Remove leading/trailing pipes
 	row = strings.TrimPrefix(row, "|")
-	row = strings.TrimSuffix(row, "|")
+	if !strings.HasSuffix(row, "\\|") {
+		row = strings.TrimSuffix(row, "|")
+	}
 
-	cols := strings.Split(row, "|")
-	for i := range cols {
-		cols[i] = strings.TrimSpace(cols[i])
+	var cols []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cols = append(cols, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
 	}
-	return cols
+	return append(cols, strings.TrimSpace(cell.String()))
 }
 
 // parseFunctions extracts function definitions from a section.
@@ -389,12 +795,55 @@ func parseFunctions(content string) []SpecFunction {
 			specFunc.IsAsync = true
 		}
 
+		specFunc.Receiver = parseReceiver(sectionContent)
+		specFunc.Module = parseModule(sectionContent)
+		specFunc.Directives = parseCodeList(sectionContent, "directives")
+		specFunc.ClientStreaming, specFunc.ServerStreaming = parseStreaming(sectionContent)
+
 		functions = append(functions, specFunc)
 	}
 
 	return functions
 }
 
+// parseReceiver extracts the receiver type of a method.
+func parseReceiver(content string) string {
+	receiverPattern := regexp.MustCompile(`(?mi)^\*\*receiver\*\*:?\s*\x60?(\w+)\x60?`)
+	if match := receiverPattern.FindStringSubmatch(content); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// parseModule extracts the module path of a type or function, e.g.
+// "**Module**: `billing/invoices`".
+func parseModule(content string) string {
+	modulePattern := regexp.MustCompile(`(?mi)^\*\*module\*\*:?\s*\x60?([\w./-]+)\x60?`)
+	if match := modulePattern.FindStringSubmatch(content); match != nil {
+		return strings.Trim(match[1], "/.")
+	}
+	return ""
+}
+
+// parseStreaming extracts the streaming mode (client, server or
+// bidirectional) of a function.
+func parseStreaming(content string) (client, server bool) {
+	streamingPattern := regexp.MustCompile(`(?mi)^\*\*streaming\*\*:?\s*\x60?(\w+)\x60?`)
+	match := streamingPattern.FindStringSubmatch(content)
+	if match == nil {
+		return false, false
+	}
+	switch strings.ToLower(match[1]) {
+	case "client":
+		return true, false
+	case "server":
+		return false, true
+	case "bidirectional", "bidi", "both":
+		return true, true
+	}
+	return false, false
+}
+
 // parseParameters extracts function parameters.
 func parseParameters(content string) []SpecParameter {
 	var params []SpecParameter
@@ -406,7 +855,7 @@ func parseParameters(content string) []SpecParameter {
 	}
 
 	// Parse bullet list parameters
-	paramPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?(\w+)\x60?\s*[:\(]\s*\x60?([^\x60\)\s,]+)\x60?[\),]?\s*[-:]?\s*(.*)$`)
+	paramPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?(\w+)\x60?[ \t]*[:\(][ \t]*(\x60[^\x60]+\x60|[^\x60\)\s,]+)[\),]?[ \t]*[-:]?[ \t]*(.*)$`)
 	paramMatches := paramPattern.FindAllStringSubmatch(content, -1)
 
 	for _, match := range paramMatches {
@@ -416,7 +865,7 @@ func parseParameters(content string) []SpecParameter {
 
 		param := SpecParameter{
 			Name:        match[1],
-			Type:        match[2],
+			Type:        strings.Trim(match[2], "\x60"),
 			Description: strings.TrimSpace(match[3]),
 			Required:    !strings.Contains(strings.ToLower(match[3]), "optional"),
 		}
@@ -501,32 +950,274 @@ func extractLogic(content string) string {
 	return ""
 }
 
-// parseErrors extracts error conditions.
+// parseErrors extracts error conditions. A bullet may name the error type
+// before a colon and end with its message in quotes, as in
+// "- `NotFound`: no user has the id - \"user not found\"". Tables name
+// their columns Error, Condition and Message.
 func parseErrors(content string) []SpecError {
 	var errors []SpecError
 
 	// Look for Errors section
 	errorPattern := regexp.MustCompile(`(?mi)(?:\*\*errors?\*\*|errors?:)\s*\n([\s\S]*?)(?:\n\*\*|\n###|\z)`)
-	if matches := errorPattern.FindStringSubmatch(content); len(matches) > 1 {
-		errorContent := matches[1]
+	matches := errorPattern.FindStringSubmatch(content)
+	if len(matches) < 2 {
+		return nil
+	}
 
-		// Parse bullet list errors
-		bulletPattern := regexp.MustCompile(`(?m)^[-*]\s+(.+)$`)
-		bullets := bulletPattern.FindAllStringSubmatch(errorContent, -1)
+	var header []string
+	lines := strings.Split(matches[1], "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
 
-		for _, match := range bullets {
-			if len(match) > 1 {
-				err := SpecError{
-					Condition: strings.TrimSpace(match[1]),
+		if strings.HasPrefix(line, "|") {
+			if isSeparatorRow(line) {
+				continue
+			}
+			cols := parseTableRow(line)
+			if header == nil {
+				if i+1 < len(lines) && isSeparatorRow(lines[i+1]) {
+					header = errorColumns(cols)
+				}
+				continue
+			}
+			var err SpecError
+			for j, col := range cols {
+				if j >= len(header) {
+					break
+				}
+				switch header[j] {
+				case "type":
+					err.Type = strings.Trim(col, "`")
+				case "condition":
+					err.Condition = col
+				case "message":
+					err.Message = strings.Trim(col, "`\"")
 				}
+			}
+			if err.Type != "" || err.Condition != "" {
 				errors = append(errors, err)
 			}
+			continue
+		}
+		header = nil
+
+		if match := errorBulletPattern.FindStringSubmatch(line); match != nil {
+			errors = append(errors, newError(match[1]))
 		}
 	}
 
 	return errors
 }
 
+var (
+	errorBulletPattern  = regexp.MustCompile(`^[-*]\s+(.+)$`)
+	errorTypePattern    = regexp.MustCompile(`^\x60?([A-Za-z_][\w.]*)\x60?:\s*`)
+	errorMessagePattern = regexp.MustCompile(`\s+[-—]\s+"([^"]*)"$`)
+)
+
+// newError parses an error bullet into its type, condition and message.
+func newError(text string) SpecError {
+	text = strings.TrimSpace(text)
+	var err SpecError
+	if match := errorTypePattern.FindStringSubmatchIndex(text); match != nil {
+		err.Type = text[match[2]:match[3]]
+		text = text[match[1]:]
+	}
+	if match := errorMessagePattern.FindStringSubmatchIndex(text); match != nil {
+		err.Message = text[match[2]:match[3]]
+		text = text[:match[0]]
+	}
+	err.Condition = strings.TrimSpace(text)
+	return err
+}
+
+// errorColumns names the columns of an error table header. Tables without
+// a recognised header fall back to Error, Condition, Message.
+func errorColumns(cols []string) []string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		switch strings.ToLower(col) {
+		case "error", "type", "code", "name":
+			names[i] = "type"
+		case "condition", "when", "description":
+			names[i] = "condition"
+		case "message":
+			names[i] = "message"
+		}
+	}
+	for _, name := range names {
+		if name != "" {
+			return names
+		}
+	}
+	return []string{"type", "condition", "message"}
+}
+
+// httpMethods matches the HTTP methods an endpoint may use.
+const httpMethods = `GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS`
+
+// parseEndpoints extracts HTTP endpoints from a section. Endpoints are either
+// detailed "### METHOD /path" blocks or "- METHOD /path - description"
+// bullets grouped under plain H3 headings, whose name becomes the endpoints'
+// tag and whose "(requires authentication)" note marks them as protected.
+func parseEndpoints(content string) []SpecEndpoint {
+	var endpoints []SpecEndpoint
+
+	headingPattern := regexp.MustCompile(`(?m)^###\s+(.+?)\s*$`)
+	endpointHeading := regexp.MustCompile(`(?i)^\x60?(` + httpMethods + `)\s+(/[^\s\x60]*)\x60?(?:\s+[-:]\s*(.*))?$`)
+	bulletPattern := regexp.MustCompile(`(?mi)^[-*]\s+\x60?(` + httpMethods + `)\s+(/[^\s\x60]*)\x60?(?:\s+[-:]\s*(.*))?$`)
+
+	// Split into the preamble and one block per H3 heading
+	type block struct{ heading, body string }
+	matches := headingPattern.FindAllStringSubmatchIndex(content, -1)
+	blocks := []block{{body: content}}
+	if len(matches) > 0 {
+		blocks[0].body = content[:matches[0][0]]
+	}
+	for i, match := range matches {
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		blocks = append(blocks, block{heading: content[match[2]:match[3]], body: content[match[1]:end]})
+	}
+
+	for _, b := range blocks {
+		if match := endpointHeading.FindStringSubmatch(b.heading); match != nil {
+			endpoint := parseEndpointBlock(b.body)
+			endpoint.Method = strings.ToUpper(match[1])
+			endpoint.Path = match[2]
+			if endpoint.Description == "" {
+				endpoint.Description = strings.TrimSpace(match[3])
+			}
+			if len(endpoint.PathParams) == 0 {
+				endpoint.PathParams = templateParams(endpoint.Path)
+			}
+			endpoints = append(endpoints, endpoint)
+			continue
+		}
+
+		// A plain heading groups bullet endpoints
+		tag, note := b.heading, ""
+		if open := strings.Index(tag, "("); open > 0 && strings.HasSuffix(tag, ")") {
+			tag, note = strings.TrimSpace(tag[:open]), strings.ToLower(tag[open+1:len(tag)-1])
+		}
+		for _, match := range bulletPattern.FindAllStringSubmatch(b.body, -1) {
+			endpoint := SpecEndpoint{
+				Method:      strings.ToUpper(match[1]),
+				Path:        match[2],
+				Description: strings.TrimSpace(match[3]),
+				PathParams:  templateParams(match[2]),
+			}
+			if tag != "" {
+				endpoint.Tags = []string{tag}
+			}
+			if strings.Contains(note, "auth") {
+				endpoint.Auth = []string{"required"}
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	return endpoints
+}
+
+// parseEndpointBlock extracts the details of a "### METHOD /path" block.
+func parseEndpointBlock(content string) SpecEndpoint {
+	endpoint := SpecEndpoint{
+		Description:  extractDescription(content),
+		PathParams:   parseParameterGroup(content, "path parameters"),
+		QueryParams:  parseParameterGroup(content, "query parameters"),
+		HeaderParams: parseParameterGroup(content, "header parameters"),
+	}
+
+	if match := regexp.MustCompile(`(?mi)^\*\*operation id\*\*:?[ \t]*\x60?([^\x60\s]+)\x60?`).FindStringSubmatch(content); match != nil {
+		endpoint.Name = match[1]
+	}
+	if match := regexp.MustCompile(`(?mi)^\*\*function\*\*:?[ \t]*\x60?([^\x60\s]+)\x60?`).FindStringSubmatch(content); match != nil {
+		endpoint.Function = match[1]
+	}
+	if match := regexp.MustCompile(`(?mi)^\*\*request\*\*:?[ \t]*\x60([^\x60]+)\x60`).FindStringSubmatch(content); match != nil {
+		endpoint.RequestType = strings.TrimSpace(match[1])
+	}
+	endpoint.Auth = parseNameList(content, "auth")
+	endpoint.Tags = parseNameList(content, "tags")
+
+	responsePattern := regexp.MustCompile(`(?mi)^\*\*response\s+(\w+)\*\*:?[ \t]*(?:\x60([^\x60]+)\x60)?[ \t]*(?:-[ \t]*)?(.*)$`)
+	for _, match := range responsePattern.FindAllStringSubmatch(content, -1) {
+		endpoint.Responses = append(endpoint.Responses, SpecResponse{
+			Status:      match[1],
+			Type:        strings.TrimSpace(match[2]),
+			Description: strings.TrimSpace(match[3]),
+		})
+	}
+
+	return endpoint
+}
+
+// parseParameterGroup extracts the bullet parameters under a bold label such
+// as "**Query Parameters**:", including "(optional)" and "(default: x)" notes.
+func parseParameterGroup(content, label string) []SpecParameter {
+	groupPattern := regexp.MustCompile(`(?mi)^\*\*` + label + `\*\*:?[ \t]*\n([\s\S]*?)(?:\n\*\*|\n###|\z)`)
+	match := groupPattern.FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+
+	var params []SpecParameter
+	paramPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?([\w.-]+)\x60?\s*:\s*\x60([^\x60]+)\x60\s*(?:-\s*)?(.*)$`)
+	defaultPattern := regexp.MustCompile(`\(default:\s*([^)]*)\)`)
+	for _, m := range paramPattern.FindAllStringSubmatch(match[1], -1) {
+		notes := m[3]
+		param := SpecParameter{Name: m[1], Type: strings.TrimSpace(m[2]), Required: true}
+		if strings.Contains(notes, "(optional)") {
+			param.Required = false
+			notes = strings.Replace(notes, "(optional)", "", 1)
+		}
+		if d := defaultPattern.FindStringSubmatch(notes); d != nil {
+			param.Default = strings.TrimSpace(d[1])
+			notes = strings.Replace(notes, d[0], "", 1)
+		}
+		param.Description = strings.TrimSpace(notes)
+		params = append(params, param)
+	}
+	return params
+}
+
+// parseNameList extracts a comma-separated list after a bold label.
+func parseNameList(content, label string) []string {
+	match := regexp.MustCompile(`(?mi)^\*\*` + label + `\*\*:?[ \t]*(.+)$`).FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(match[1], ",") {
+		if name = strings.Trim(strings.TrimSpace(name), "\x60"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// templateParams returns the parameters of a path template, in either the
+// "{id}" or ":id" style, typed as strings.
+func templateParams(path string) []SpecParameter {
+	var params []SpecParameter
+	for _, segment := range strings.Split(path, "/") {
+		name := ""
+		switch {
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			name = segment[1 : len(segment)-1]
+		case strings.HasPrefix(segment, ":"):
+			name = segment[1:]
+		}
+		if name != "" {
+			params = append(params, SpecParameter{Name: name, Type: "string", Required: true})
+		}
+	}
+	return params
+}
+
 // parseTests extracts test case definitions.
 func parseTests(content string) []SpecTest {
 	var tests []SpecTest
@@ -564,41 +1255,643 @@ func parseTests(content string) []SpecTest {
 	return tests
 }
 
-// parseGiven extracts test preconditions.
-func parseGiven(content string) []SpecCondition {
-	var conditions []SpecCondition
+// parseProperties extracts invariants from bullets such as
+// "- `decode(encode(x)) == x` - Decoding reverses encoding". A bullet
+// without a backticked expression is a property described in prose. The
+// bullets under a "### name" heading are about the function of that name.
+func parseProperties(content string) []SpecProperty {
+	var properties []SpecProperty
 
-	givenPattern := regexp.MustCompile(`(?mi)(?:\*\*given\*\*|given:)\s*\n([\s\S]*?)(?:\n\*\*|\n###|\z)`)
-	if matches := givenPattern.FindStringSubmatch(content); len(matches) > 1 {
-		bulletPattern := regexp.MustCompile(`(?m)^[-*]\s+(.+)$`)
-		bullets := bulletPattern.FindAllStringSubmatch(matches[1], -1)
+	headingPattern := regexp.MustCompile(`^###\s+\x60?([A-Za-z_]\w*)`)
+	bulletPattern := regexp.MustCompile(`^[-*]\s+(.+)$`)
+	expressionPattern := regexp.MustCompile(`^\x60([^\x60]+)\x60(?:[ \t]*[-:][ \t]*(.*))?$`)
 
-		for _, match := range bullets {
-			if len(match) > 1 {
-				conditions = append(conditions, SpecCondition{
-					Description: strings.TrimSpace(match[1]),
-				})
-			}
+	function := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			function = m[1]
+			continue
+		}
+		m := bulletPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
 		}
+		property := SpecProperty{Function: function}
+		if e := expressionPattern.FindStringSubmatch(m[1]); e != nil {
+			property.Expression = strings.TrimSpace(e[1])
+			property.Description = strings.TrimSpace(e[2])
+		} else {
+			property.Description = strings.TrimSpace(m[1])
+		}
+		properties = append(properties, property)
 	}
 
-	return conditions
-}
-
-// extractWhen extracts the test action.
-func extractWhen(content string) string {
-	whenPattern := regexp.MustCompile(`(?mi)(?:\*\*when\*\*|when:)\s*(.+)$`)
-	if matches := whenPattern.FindStringSubmatch(content); len(matches) > 1 {
-		return strings.TrimSpace(matches[1])
-	}
-	return ""
+	return properties
 }
 
-// parseThen extracts test assertions.
-func parseThen(content string) []SpecAssertion {
-	var assertions []SpecAssertion
+// parseCommands extracts the commands of "### db migrate" blocks, whose
+// heading is the command's path from the root, parents first. Placeholders
+// such as "<url>" or "[flags]" in a heading are ignored, and a parent
+// command not declared by its own block is added to hold its subcommands.
+func parseCommands(content string) []SpecCommand {
+	var commands []SpecCommand
 
-	thenPattern := regexp.MustCompile(`(?mi)(?:\*\*then\*\*|(?:expect|then):)\s*\n([\s\S]*?)(?:\n\*\*|\n###|\z)`)
+	headingPattern := regexp.MustCompile(`(?m)^###\s+(.+?)\s*$`)
+	matches := headingPattern.FindAllStringSubmatchIndex(content, -1)
+	for i, match := range matches {
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+
+		var path []string
+		for _, word := range strings.Fields(strings.ReplaceAll(content[match[2]:match[3]], "\x60", "")) {
+			if strings.ContainsAny(word[:1], "<[-") {
+				break
+			}
+			path = append(path, word)
+		}
+		if len(path) == 0 {
+			continue
+		}
+
+		command := parseCommandBlock(content[match[1]:end])
+		command.Name = path[len(path)-1]
+		command.Parent = strings.Join(path[:len(path)-1], " ")
+
+		find := func(path string) int {
+			for j := range commands {
+				if commands[j].Path() == path {
+					return j
+				}
+			}
+			return -1
+		}
+
+		// Add the groups that are missing ahead of the command
+		for depth := 1; depth < len(path); depth++ {
+			if find(strings.Join(path[:depth], " ")) < 0 {
+				commands = append(commands, SpecCommand{Name: path[depth-1], Parent: strings.Join(path[:depth-1], " ")})
+			}
+		}
+
+		// A block for a command already added as a group fills it in
+		if at := find(command.Path()); at >= 0 {
+			commands[at] = command
+		} else {
+			commands = append(commands, command)
+		}
+	}
+
+	return commands
+}
+
+// parseCommandBlock extracts the details of a "### command" block: its
+// function, arguments, flags, exit codes and output format.
+func parseCommandBlock(content string) SpecCommand {
+	command := SpecCommand{
+		Description: extractDescription(content),
+		Args:        parseParameterGroup(content, "arguments"),
+		Flags:       parseFlags(content),
+		ExitCodes:   parseExitCodes(content),
+	}
+	if match := regexp.MustCompile(`(?mi)^\*\*function\*\*:?[ \t]*\x60?([^\x60\s]+)\x60?`).FindStringSubmatch(content); match != nil {
+		command.Function = match[1]
+	}
+	if match := regexp.MustCompile(`(?mi)^\*\*output\*\*:?[ \t]*\x60?(\w+)\x60?`).FindStringSubmatch(content); match != nil {
+		command.Output = strings.ToLower(match[1])
+	}
+	return command
+}
+
+// parseFlags extracts the bullets under "**Flags**", such as
+// "- `--alias`, `-a`: `string` - Custom alias (default: x)". Flags are
+// optional unless marked "(required)".
+func parseFlags(content string) []SpecFlag {
+	match := regexp.MustCompile(`(?mi)^\*\*(?:flags|options)\*\*:?[ \t]*\n([\s\S]*?)(?:\n\*\*|\n###|\z)`).FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+
+	var flags []SpecFlag
+	flagPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?--([\w-]+)\x60?(?:\s*,\s*\x60?-(\w)\x60?)?\s*:\s*\x60([^\x60]+)\x60\s*(?:-\s*)?(.*)$`)
+	defaultPattern := regexp.MustCompile(`\(default:\s*([^)]*)\)`)
+	for _, m := range flagPattern.FindAllStringSubmatch(match[1], -1) {
+		notes := m[4]
+		flag := SpecFlag{Name: m[1], Short: m[2], Type: strings.TrimSpace(m[3])}
+		if strings.Contains(notes, "(required)") {
+			flag.Required = true
+			notes = strings.Replace(notes, "(required)", "", 1)
+		}
+		notes = strings.Replace(notes, "(optional)", "", 1)
+		if d := defaultPattern.FindStringSubmatch(notes); d != nil {
+			flag.Default = strings.TrimSpace(d[1])
+			notes = strings.Replace(notes, d[0], "", 1)
+		}
+		flag.Description = strings.TrimSpace(notes)
+		flags = append(flags, flag)
+	}
+	return flags
+}
+
+// parseExitCodes extracts the bullets under "**Exit Codes**", such as
+// "- `2` - The URL is invalid".
+func parseExitCodes(content string) []SpecExitCode {
+	match := regexp.MustCompile(`(?mi)^\*\*exit codes\*\*:?[ \t]*\n([\s\S]*?)(?:\n\*\*|\n###|\z)`).FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+
+	var codes []SpecExitCode
+	codePattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?(\d+)\x60?\s*(?:[-:]\s*)?(.*)$`)
+	for _, m := range codePattern.FindAllStringSubmatch(match[1], -1) {
+		code, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		codes = append(codes, SpecExitCode{Code: code, Description: strings.TrimSpace(m[2])})
+	}
+	return codes
+}
+
+// parseStateMachines extracts a machine from each "### Name" block. A block
+// gives its transitions as a table with From, Event, To, Guard and Action
+// columns or as a Mermaid stateDiagram, and may list its states and events
+// as bullets under "**States**" and "**Events**".
+func parseStateMachines(content string) []SpecStateMachine {
+	var machines []SpecStateMachine
+
+	headingPattern := regexp.MustCompile(`(?m)^###\s+(.+?)\s*$`)
+	matches := headingPattern.FindAllStringSubmatchIndex(content, -1)
+	for i, match := range matches {
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		name := strings.Trim(content[match[2]:match[3]], "\x60 ")
+		if name == "" {
+			continue
+		}
+		machine := parseStateMachineBlock(content[match[1]:end])
+		machine.Name = name
+		machines = append(machines, machine)
+	}
+
+	return machines
+}
+
+// parseStateMachineBlock extracts the states, events and transitions of a
+// "### Name" block. States and events not listed are added in the order
+// the transitions first use them.
+func parseStateMachineBlock(content string) SpecStateMachine {
+	var machine SpecStateMachine
+	prose := content
+	if fence := strings.Index(prose, "```"); fence >= 0 {
+		prose = prose[:fence]
+	}
+	machine.Description = extractDescription(prose)
+
+	state := func(name string) *SpecState {
+		for i := range machine.States {
+			if machine.States[i].Name == name {
+				return &machine.States[i]
+			}
+		}
+		machine.States = append(machine.States, SpecState{Name: name})
+		return &machine.States[len(machine.States)-1]
+	}
+	event := func(name string) {
+		for _, e := range machine.Events {
+			if e.Name == name {
+				return
+			}
+		}
+		machine.Events = append(machine.Events, SpecEvent{Name: name})
+	}
+
+	itemPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?([\w-]+)\x60?[ \t]*(?:[-:][ \t]*)?(.*)$`)
+	if group := regexp.MustCompile(`(?mi)^\*\*states\*\*:?[ \t]*\n([\s\S]*?)(?:\n\*\*|\n###|\n\||\n\x60\x60\x60|\z)`).FindStringSubmatch(content); group != nil {
+		for _, m := range itemPattern.FindAllStringSubmatch(group[1], -1) {
+			s := state(m[1])
+			notes := m[2]
+			if strings.Contains(notes, "(initial)") {
+				machine.Initial = m[1]
+				notes = strings.Replace(notes, "(initial)", "", 1)
+			}
+			if strings.Contains(notes, "(final)") {
+				s.Final = true
+				notes = strings.Replace(notes, "(final)", "", 1)
+			}
+			s.Description = strings.TrimSpace(notes)
+		}
+	}
+	if group := regexp.MustCompile(`(?mi)^\*\*events\*\*:?[ \t]*\n([\s\S]*?)(?:\n\*\*|\n###|\n\||\n\x60\x60\x60|\z)`).FindStringSubmatch(content); group != nil {
+		for _, m := range itemPattern.FindAllStringSubmatch(group[1], -1) {
+			machine.Events = append(machine.Events, SpecEvent{Name: m[1], Description: strings.TrimSpace(m[2])})
+		}
+	}
+	if match := regexp.MustCompile(`(?mi)^\*\*initial\*\*:?[ \t]*\x60?([\w-]+)\x60?`).FindStringSubmatch(content); match != nil {
+		machine.Initial = match[1]
+	}
+
+	transitions := parseTransitionTable(content)
+	if diagram := regexp.MustCompile("(?s)```mermaid\\s*\n(.*?)```").FindStringSubmatch(content); diagram != nil {
+		initial, finals, descriptions, diagramTransitions := parseStateDiagram(diagram[1])
+		if machine.Initial == "" {
+			machine.Initial = initial
+		}
+		for _, d := range descriptions {
+			if s := state(d[0]); s.Description == "" {
+				s.Description = d[1]
+			}
+		}
+		for _, name := range finals {
+			state(name).Final = true
+		}
+		transitions = append(transitions, diagramTransitions...)
+	}
+
+	// The initial state comes first when no states are listed
+	if machine.Initial != "" {
+		state(machine.Initial)
+	}
+	for _, t := range transitions {
+		state(t.From)
+		state(t.To)
+		event(t.Event)
+	}
+	machine.Transitions = transitions
+	return machine
+}
+
+// parseTransitionTable extracts transitions from a table whose header names
+// its From, Event and To columns, and optionally Guard and Action. A From
+// cell may list several states separated by commas.
+func parseTransitionTable(content string) []SpecTransition {
+	var transitions []SpecTransition
+
+	lines := strings.Split(content, "\n")
+	var header []string
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			header = nil
+			continue
+		}
+		if isSeparatorRow(line) {
+			continue
+		}
+		cols := parseTableRow(line)
+		if header == nil {
+			if i+1 < len(lines) && isSeparatorRow(lines[i+1]) {
+				header = transitionColumns(cols)
+			}
+			continue
+		}
+
+		var t SpecTransition
+		var from []string
+		for j, col := range cols {
+			if j >= len(header) {
+				break
+			}
+			cell := strings.Trim(col, "\x60 ")
+			if cell == "-" || cell == "—" {
+				cell = ""
+			}
+			switch header[j] {
+			case "from":
+				for _, name := range strings.Split(cell, ",") {
+					if name = strings.Trim(strings.TrimSpace(name), "\x60"); name != "" {
+						from = append(from, name)
+					}
+				}
+			case "event":
+				t.Event = cell
+			case "to":
+				t.To = cell
+			case "guard":
+				t.Guard = cell
+			case "action":
+				t.Action = cell
+			}
+		}
+		if t.Event == "" || t.To == "" {
+			continue
+		}
+		for _, name := range from {
+			t.From = name
+			transitions = append(transitions, t)
+		}
+	}
+
+	return transitions
+}
+
+// transitionColumns names the columns of a transition table header.
+func transitionColumns(cols []string) []string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		switch strings.ToLower(strings.Trim(col, "* ")) {
+		case "from", "source", "state", "current":
+			names[i] = "from"
+		case "event", "trigger", "on":
+			names[i] = "event"
+		case "to", "target", "next":
+			names[i] = "to"
+		case "guard", "condition", "when", "if":
+			names[i] = "guard"
+		case "action", "effect", "do":
+			names[i] = "action"
+		}
+	}
+	return names
+}
+
+// parseStateDiagram extracts a Mermaid stateDiagram: "[*] --> A" marks the
+// initial state, "A --> [*]" a final one, "A --> B : event [guard] /
+// action" a transition and "A : text" a state's description.
+func parseStateDiagram(diagram string) (initial string, finals []string, descriptions [][2]string, transitions []SpecTransition) {
+	transitionPattern := regexp.MustCompile(`^(\[\*\]|[\w-]+)\s*-->\s*(\[\*\]|[\w-]+)\s*(?::\s*(.*))?$`)
+	labelPattern := regexp.MustCompile(`^([^\[/]*?)\s*(?:\[([^\]]*)\])?\s*(?:/\s*(.*))?$`)
+	describedPattern := regexp.MustCompile(`^([\w-]+)\s*:\s*(.+)$`)
+	aliasPattern := regexp.MustCompile(`^state\s+"([^"]*)"\s+as\s+([\w-]+)`)
+
+	for _, line := range strings.Split(diagram, "\n") {
+		line = strings.TrimSpace(line)
+		if m := transitionPattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case m[1] == "[*]" && m[2] != "[*]":
+				initial = m[2]
+			case m[2] == "[*]" && m[1] != "[*]":
+				finals = append(finals, m[1])
+			case m[1] != "[*]":
+				label := labelPattern.FindStringSubmatch(strings.TrimSpace(m[3]))
+				if label == nil || label[1] == "" {
+					continue
+				}
+				transitions = append(transitions, SpecTransition{
+					From:   m[1],
+					Event:  label[1],
+					To:     m[2],
+					Guard:  strings.TrimSpace(label[2]),
+					Action: strings.TrimSpace(label[3]),
+				})
+			}
+			continue
+		}
+		if m := aliasPattern.FindStringSubmatch(line); m != nil {
+			descriptions = append(descriptions, [2]string{m[2], m[1]})
+			continue
+		}
+		if m := describedPattern.FindStringSubmatch(line); m != nil {
+			descriptions = append(descriptions, [2]string{m[1], strings.TrimSpace(m[2])})
+		}
+	}
+	return initial, finals, descriptions, transitions
+}
+
+// parseTables extracts database tables from a section, one "### name"
+// heading per table. Headings without a column table are skipped.
+func parseTables(content string) []SpecTable {
+	var tables []SpecTable
+
+	headingPattern := regexp.MustCompile(`(?m)^###\s+(.+?)\s*$`)
+	matches := headingPattern.FindAllStringSubmatchIndex(content, -1)
+	for i, match := range matches {
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		table := parseTableBlock(content[match[1]:end])
+		table.Name = strings.Trim(content[match[2]:match[3]], "\x60 ")
+		if table.Name == "" || len(table.Columns) == 0 {
+			continue
+		}
+		tables = append(tables, table)
+	}
+
+	return tables
+}
+
+// parseTableBlock extracts the entity, columns, keys, indexes, checks and
+// relations of a "### table" block.
+func parseTableBlock(content string) SpecTable {
+	table := SpecTable{
+		Description: extractDescription(content),
+		PrimaryKey:  parseCodeList(content, "primary key"),
+	}
+	if match := regexp.MustCompile(`(?mi)^\*\*entity\*\*:?[ \t]*\x60?(\w+)\x60?`).FindStringSubmatch(content); match != nil {
+		table.Entity = match[1]
+	}
+	table.Columns, table.PrimaryKey = parseColumns(content, table.PrimaryKey)
+
+	codePattern := regexp.MustCompile("\x60([^\x60]+)\x60")
+	codes := func(s string) []string {
+		var values []string
+		for _, m := range codePattern.FindAllStringSubmatch(s, -1) {
+			values = append(values, m[1])
+		}
+		return values
+	}
+
+	for _, item := range parseBulletGroup(content, "indexes") {
+		m := regexp.MustCompile(`^\x60([^\x60]+)\x60\s*(\(unique\))?\s*:\s*(.+)$`).FindStringSubmatch(item)
+		if m == nil {
+			continue
+		}
+		table.Indexes = append(table.Indexes, SpecIndex{Name: m[1], Unique: m[2] != "", Columns: codes(m[3])})
+	}
+
+	fkPattern := regexp.MustCompile(`^(?:\x60([^\x60]+)\x60:\s*)?(.+?)\s+references\s+\x60?(\w+)\x60?\s*\(([^)]*)\)(.*)$`)
+	actionPattern := regexp.MustCompile(`(?i)on (delete|update) (set null|set default|no action|cascade|restrict)`)
+	for _, item := range parseBulletGroup(content, "foreign keys") {
+		m := fkPattern.FindStringSubmatch(item)
+		if m == nil {
+			continue
+		}
+		fk := SpecForeignKey{Name: m[1], Columns: codes(m[2]), RefTable: m[3], RefColumns: codes(m[4])}
+		for _, action := range actionPattern.FindAllStringSubmatch(m[5], -1) {
+			if strings.EqualFold(action[1], "delete") {
+				fk.OnDelete = strings.ToUpper(action[2])
+			} else {
+				fk.OnUpdate = strings.ToUpper(action[2])
+			}
+		}
+		table.ForeignKeys = append(table.ForeignKeys, fk)
+	}
+
+	for _, item := range parseBulletGroup(content, "checks") {
+		table.Checks = append(table.Checks, strings.Trim(item, "\x60"))
+	}
+
+	relationPattern := regexp.MustCompile(`^\x60(\w+)\x60\s+(belongs to|has one|has many|many to many)\s+\x60(\w+)\x60(.*)$`)
+	for _, item := range parseBulletGroup(content, "relations") {
+		m := relationPattern.FindStringSubmatch(item)
+		if m == nil {
+			continue
+		}
+		relation := SpecRelation{Name: m[1], Kind: m[2], Table: m[3]}
+		rest := m[4]
+		if through := regexp.MustCompile(`through\s+\x60(\w+)\x60`).FindStringSubmatch(rest); through != nil {
+			relation.Through = through[1]
+		}
+		if via := strings.Index(rest, "via"); via >= 0 {
+			relation.Columns = codes(rest[via:])
+		}
+		table.Relations = append(table.Relations, relation)
+	}
+
+	return table
+}
+
+// parseBulletGroup returns the bullets under a "**Label**:" line, without
+// their markers.
+func parseBulletGroup(content, label string) []string {
+	group := regexp.MustCompile(`(?mi)^\*\*` + label + `\*\*:?[ \t]*\n([\s\S]*?)(?:\n\*\*|\n###|\n\||\z)`).FindStringSubmatch(content)
+	if group == nil {
+		return nil
+	}
+	var items []string
+	for _, m := range regexp.MustCompile(`(?m)^[-*]\s+(.+?)\s*$`).FindAllStringSubmatch(group[1], -1) {
+		items = append(items, m[1])
+	}
+	return items
+}
+
+// parseColumns extracts the columns of a table whose header names its
+// Column and Type columns, and optionally SQL Type, Nullable, Default,
+// Constraints and Description. Columns marked "primary key" are added to
+// the primary key when none is declared.
+func parseColumns(content string, primaryKey []string) ([]SpecColumn, []string) {
+	var columns []SpecColumn
+	declared := len(primaryKey) > 0
+
+	lines := strings.Split(content, "\n")
+	var header []string
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			header = nil
+			continue
+		}
+		if isSeparatorRow(line) {
+			continue
+		}
+		cols := parseTableRow(line)
+		if header == nil {
+			if i+1 < len(lines) && isSeparatorRow(lines[i+1]) {
+				header = columnColumns(cols)
+			}
+			continue
+		}
+
+		var column SpecColumn
+		for j, col := range cols {
+			if j >= len(header) {
+				break
+			}
+			cell := strings.Trim(col, "\x60 ")
+			if cell == "-" || cell == "—" {
+				cell = ""
+			}
+			switch header[j] {
+			case "name":
+				column.Name = cell
+			case "type":
+				column.Type = cell
+			case "sqlType":
+				column.SQLType = cell
+			case "nullable":
+				column.Nullable = isAffirmative(cell)
+			case "default":
+				column.Default = cell
+			case "constraints":
+				for _, c := range strings.Split(strings.ToLower(cell), ",") {
+					switch strings.TrimSpace(c) {
+					case "primary key":
+						if !declared {
+							primaryKey = append(primaryKey, column.Name)
+						}
+					case "unique":
+						column.Unique = true
+					case "auto increment", "autoincrement", "identity":
+						column.AutoIncrement = true
+					}
+				}
+			case "description":
+				column.Description = cell
+			}
+		}
+		if column.Name == "" {
+			continue
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, primaryKey
+}
+
+// columnColumns names the columns of a table's column table header.
+func columnColumns(cols []string) []string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		switch strings.ToLower(strings.Trim(col, "* ")) {
+		case "column", "name":
+			names[i] = "name"
+		case "type":
+			names[i] = "type"
+		case "sql type", "sql", "db type":
+			names[i] = "sqlType"
+		case "nullable", "null":
+			names[i] = "nullable"
+		case "default":
+			names[i] = "default"
+		case "constraints", "constraint", "keys":
+			names[i] = "constraints"
+		case "description", "notes":
+			names[i] = "description"
+		}
+	}
+	return names
+}
+
+// parseGiven extracts test preconditions.
+func parseGiven(content string) []SpecCondition {
+	var conditions []SpecCondition
+
+	givenPattern := regexp.MustCompile(`(?mi)(?:\*\*given\*\*|given:)\s*\n([\s\S]*?)(?:\n\*\*|\n###|\z)`)
+	if matches := givenPattern.FindStringSubmatch(content); len(matches) > 1 {
+		bulletPattern := regexp.MustCompile(`(?m)^[-*]\s+(.+)$`)
+		bullets := bulletPattern.FindAllStringSubmatch(matches[1], -1)
+
+		for _, match := range bullets {
+			if len(match) > 1 {
+				conditions = append(conditions, SpecCondition{
+					Description: strings.TrimSpace(match[1]),
+				})
+			}
+		}
+	}
+
+	return conditions
+}
+
+// extractWhen extracts the test action.
+func extractWhen(content string) string {
+	whenPattern := regexp.MustCompile(`(?mi)(?:\*\*when\*\*|when:)\s*(.+)$`)
+	if matches := whenPattern.FindStringSubmatch(content); len(matches) > 1 {
+		return strings.TrimSpace(matches[1])
+	}
+	return ""
+}
+
+// parseThen extracts test assertions.
+func parseThen(content string) []SpecAssertion {
+	var assertions []SpecAssertion
+
+	thenPattern := regexp.MustCompile(`(?mi)(?:\*\*then\*\*|(?:expect|then):)\s*\n([\s\S]*?)(?:\n\*\*|\n###|\z)`)
 	if matches := thenPattern.FindStringSubmatch(content); len(matches) > 1 {
 		bulletPattern := regexp.MustCompile(`(?m)^[-*]\s+(.+)$`)
 		bullets := bulletPattern.FindAllStringSubmatch(matches[1], -1)
@@ -615,64 +1908,189 @@ func parseThen(content string) []SpecAssertion {
 	return assertions
 }
 
-// parseDependencies extracts dependency definitions.
+// dependencyLanguages maps the names a spec uses for a language in
+// dependency headings, table columns and nested bullets to language IDs.
+var dependencyLanguages = map[string]string{
+	"go": "go", "golang": "go",
+	"typescript": "typescript", "ts": "typescript", "javascript": "typescript", "node": "typescript", "npm": "typescript",
+	"python": "python", "pypi": "python", "pip": "python",
+	"java": "java", "maven": "java", "gradle": "java",
+	"rust": "rust", "cargo": "rust", "crates": "rust",
+	"csharp": "csharp", "c#": "csharp", ".net": "csharp", "dotnet": "csharp", "nuget": "csharp",
+}
+
+// dependencyNameColumns lists the table headers that name a dependency.
+var dependencyNameColumns = map[string]bool{
+	"package": true, "name": true, "dependency": true, "module": true,
+	"library": true, "crate": true, "artifact": true,
+}
+
+// parseDependencies extracts dependency definitions from bullets such as
+// "- `uuid@^9.0.0` - Generates IDs", with nested "  - go: `module`"
+// bullets naming per-language packages, and from tables with Package,
+// Version, Purpose and per-language columns. A heading naming a language
+// scopes the dependencies under it to that language, and a "Standard
+// Library" heading marks them as not needing installation.
 func parseDependencies(content string) []SpecDependency {
 	var deps []SpecDependency
 
-	// Parse bullet list dependencies
-	depPattern := regexp.MustCompile(`(?m)^[-*]\s+\x60?([^\x60\s]+)\x60?\s*[-:]?\s*(.*)$`)
-	matches := depPattern.FindAllStringSubmatch(content, -1)
+	bulletPattern := regexp.MustCompile(`^[-*]\s+\x60?([^\x60\s]+)\x60?\s*[-:]?\s*(.*)$`)
+	nestedPattern := regexp.MustCompile(`^\s+[-*]\s+([\w#.]+)\s*:\s*\x60?([^\x60]+?)\x60?\s*$`)
 
-	for _, match := range matches {
-		if len(match) < 3 {
-			continue
-		}
+	lang, stdlib := "", false
+	var header []string
+	lines := strings.Split(content, "\n")
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case strings.HasPrefix(line, "#"):
+			heading := strings.TrimSpace(strings.TrimLeft(line, "#"))
+			lang = headingLanguage(heading)
+			lower := strings.ToLower(heading)
+			stdlib = strings.Contains(lower, "standard library") || strings.Contains(lower, "stdlib")
+			header = nil
+
+		case strings.HasPrefix(line, "|"):
+			if isSeparatorRow(line) {
+				continue
+			}
+			cols := parseTableRow(line)
+			if header == nil {
+				if i+1 < len(lines) && isSeparatorRow(lines[i+1]) {
+					header = cols
+				}
+				continue
+			}
 
-		dep := SpecDependency{
-			Name:        match[1],
-			Description: strings.TrimSpace(match[2]),
+			var dep *SpecDependency
+			for j, col := range cols {
+				if j < len(header) && dependencyNameColumns[strings.ToLower(header[j])] && col != "" {
+					d := newDependency(col, "", lang, stdlib)
+					dep = &d
+					break
+				}
+			}
+			if dep == nil {
+				continue
+			}
+			for j, col := range cols {
+				col = strings.Trim(col, "\x60")
+				if j >= len(header) || col == "" || col == "-" {
+					continue
+				}
+				column := strings.ToLower(header[j])
+				switch {
+				case column == "version":
+					dep.Version = col
+				case column == "purpose" || column == "description" || column == "notes":
+					dep.Description = col
+				case dependencyLanguages[column] != "":
+					if dep.Packages == nil {
+						dep.Packages = make(map[string]string)
+					}
+					dep.Packages[dependencyLanguages[column]] = col
+				}
+			}
+			deps = append(deps, *dep)
+
+		default:
+			header = nil
+			if m := nestedPattern.FindStringSubmatch(raw); m != nil && len(deps) > 0 {
+				if l := dependencyLanguages[strings.ToLower(m[1])]; l != "" {
+					dep := &deps[len(deps)-1]
+					if dep.Packages == nil {
+						dep.Packages = make(map[string]string)
+					}
+					dep.Packages[l] = strings.TrimSpace(m[2])
+				}
+				continue
+			}
+			if m := bulletPattern.FindStringSubmatch(raw); m != nil {
+				deps = append(deps, newDependency(m[1], strings.TrimSpace(m[2]), lang, stdlib))
+			}
 		}
-		deps = append(deps, dep)
 	}
 
 	return deps
 }
 
-// parseConfiguration extracts configuration items.
+// newDependency builds a dependency from a name that may carry a version
+// after "@" ("uuid@^9.0.0", "@types/node@20"), scoped to lang when the
+// dependency sits under a language heading.
+func newDependency(name, description, lang string, stdlib bool) SpecDependency {
+	name = strings.Trim(name, "\x60")
+	dep := SpecDependency{Name: name, Description: description, Stdlib: stdlib}
+	if at := strings.LastIndex(name, "@"); at > 0 {
+		dep.Name, dep.Version = name[:at], name[at+1:]
+	}
+	if lang != "" {
+		dep.Packages = map[string]string{lang: dep.Name}
+	}
+	return dep
+}
+
+// headingLanguage returns the language a heading such as "Go Standard
+// Library" or "Python packages" names, or "" when it names none.
+func headingLanguage(heading string) string {
+	for _, word := range strings.Fields(strings.ToLower(heading)) {
+		if lang := dependencyLanguages[strings.Trim(word, "():,")]; lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+// parseConfiguration extracts configuration items from a table, whose
+// columns are matched by header (Name or Variable, Type, Default, Required,
+// Description), or from a bullet list. "(default: x)" and "(required)"
+// notes in a description are honoured in both forms.
 func parseConfiguration(content string) []SpecConfig {
 	var configs []SpecConfig
 
 	// Parse table configs
-	tablePattern := regexp.MustCompile(`(?m)\|(.+)\|(.+)\|(.+)\|`)
-	matches := tablePattern.FindAllStringSubmatch(content, -1)
-
-	headerFound := false
-	for _, match := range matches {
-		if len(match) < 4 {
+	lines := strings.Split(content, "\n")
+	var header []string
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			header = nil
 			continue
 		}
-
-		col1 := strings.TrimSpace(match[1])
-		col2 := strings.TrimSpace(match[2])
-		col3 := strings.TrimSpace(match[3])
-
-		if strings.Contains(col1, "-") {
-			headerFound = true
+		if isSeparatorRow(line) {
 			continue
 		}
-		if strings.ToLower(col1) == "name" || strings.ToLower(col1) == "variable" {
-			headerFound = true
+		cols := parseTableRow(line)
+		if header == nil {
+			if i+1 < len(lines) && isSeparatorRow(lines[i+1]) {
+				header = configColumns(cols)
+			}
 			continue
 		}
 
-		if headerFound {
-			config := SpecConfig{
-				Name:        col1,
-				Type:        col2,
-				Description: col3,
+		config := SpecConfig{}
+		for j, col := range cols {
+			if j >= len(header) {
+				break
+			}
+			switch header[j] {
+			case "name":
+				config.Name = strings.Trim(col, "`")
+			case "type":
+				config.Type = strings.Trim(col, "`")
+			case "default":
+				if d := strings.Trim(col, "`"); d != "-" && d != "—" {
+					config.Default = d
+				}
+			case "required":
+				config.Required = isAffirmative(col)
+			case "description":
+				config.Description = col
 			}
-			configs = append(configs, config)
 		}
+		if config.Name == "" {
+			continue
+		}
+		configs = append(configs, withConfigNotes(config))
 	}
 
 	// Also parse bullet list configs
@@ -689,12 +2107,90 @@ func parseConfiguration(content string) []SpecConfig {
 			Description: strings.TrimSpace(match[2]),
 			Type:        "string",
 		}
-		configs = append(configs, config)
+		configs = append(configs, withConfigNotes(config))
 	}
 
 	return configs
 }
 
+// configColumns names the columns of a configuration table header. Tables
+// without a recognised header fall back to Name, Type, Description.
+func configColumns(cols []string) []string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		switch strings.ToLower(col) {
+		case "name", "variable", "env", "key", "setting":
+			names[i] = "name"
+		case "type":
+			names[i] = "type"
+		case "default":
+			names[i] = "default"
+		case "required":
+			names[i] = "required"
+		case "description", "notes", "purpose":
+			names[i] = "description"
+		}
+	}
+	if len(names) > 0 && names[0] == "" {
+		return []string{"name", "type", "description"}
+	}
+	return names
+}
+
+// fieldColumns names the columns of a field table from its header row.
+// A table of three or more columns without a Field or Name column is read
+// as name, type and description; any other table holds no fields.
+func fieldColumns(cols []string) []string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		switch strings.ToLower(strings.Trim(col, "* ")) {
+		case "field", "name", "property":
+			names[i] = "name"
+		case "type":
+			names[i] = "type"
+		case "required":
+			names[i] = "required"
+		case "default":
+			names[i] = "default"
+		case "description", "notes":
+			names[i] = "description"
+		}
+	}
+	if slices.Contains(names, "name") && slices.Contains(names, "type") {
+		return names
+	}
+	if len(cols) >= 3 {
+		return []string{"name", "type", "description"}
+	}
+	return nil
+}
+
+// isAffirmative reports whether a table cell reads as yes.
+func isAffirmative(cell string) bool {
+	switch strings.ToLower(strings.Trim(cell, "`* ")) {
+	case "yes", "y", "true", "required", "✓", "✔", "x":
+		return true
+	}
+	return false
+}
+
+// withConfigNotes moves "(default: x)" and "(required)" notes from a
+// configuration's description into its fields.
+func withConfigNotes(config SpecConfig) SpecConfig {
+	if match := defaultPattern.FindStringSubmatchIndex(config.Description); match != nil {
+		if config.Default == "" {
+			config.Default = strings.TrimSpace(config.Description[match[2]:match[3]])
+		}
+		config.Description = config.Description[:match[0]] + config.Description[match[1]:]
+	}
+	if strings.Contains(config.Description, "(required)") {
+		config.Required = true
+		config.Description = strings.Replace(config.Description, "(required)", "", 1)
+	}
+	config.Description = strings.TrimSpace(config.Description)
+	return config
+}
+
 // extractDescription extracts a description from section content.
 func extractDescription(content string) string {
 	lines := strings.Split(content, "\n")
@@ -0,0 +1,644 @@
+package specparser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFieldTable(t *testing.T) {
+	content := "# Shop\n\n## Data Model\n\n### Product\n\nA product for sale.\n\n" +
+		"| Field | Type | Required | Default | Description |\n" +
+		"|-------|------|----------|---------|-------------|\n" +
+		"| id | `string` | yes | - | Unique identifier |\n" +
+		"| price | `decimal` | yes | - | Unit price \\| incl. tax |\n" +
+		"| stock | `int` | no | 0 | Units on hand |\n"
+
+	spec, err := NewParser().Parse(content, "shop.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(spec.Types) != 1 {
+		t.Fatalf("Expected the Product type, got %+v", spec.Types)
+	}
+	fields := spec.Types[0].Fields
+	if len(fields) != 3 {
+		t.Fatalf("Expected 3 fields, got %+v", fields)
+	}
+	if f := fields[0]; f.Name != "id" || f.Type != "string" || !f.Required || f.Default != "" || f.Description != "Unique identifier" {
+		t.Errorf("Expected columns matched by header, got %+v", f)
+	}
+	if f := fields[1]; f.Description != "Unit price | incl. tax" {
+		t.Errorf("Expected an escaped pipe kept in its cell, got %q", f.Description)
+	}
+	if f := fields[2]; f.Name != "stock" || f.Type != "int" || f.Required || f.Default != "0" || f.Description != "Units on hand" {
+		t.Errorf("Expected optional stock defaulting to 0, got %+v", f)
+	}
+}
+
+const taskAPISpec = "# Tasks\n\n" +
+	"Task tracking API.\n\n" +
+	"## Types\n\n" +
+	"### NewTask (struct)\n\n" +
+	"- title: string - Task title\n\n" +
+	"## Functions\n\n" +
+	"### getTask\n\n" +
+	"Looks up a task.\n\n" +
+	"**Parameters**\n" +
+	"- `id`: `string`\n" +
+	"- `verbose`: `bool`\n\n" +
+	"**Returns** `NewTask`\n\n" +
+	"### createTask\n\n" +
+	"**Parameters**\n" +
+	"- `task`: `NewTask`\n\n" +
+	"**Returns** `string`\n\n" +
+	"## API Endpoints\n\n" +
+	"### GET /tasks/{id}\n\n" +
+	"Get task details\n\n" +
+	"**Operation ID**: getTask\n\n" +
+	"**Auth**: bearer\n\n" +
+	"**Query Parameters**:\n" +
+	"- `verbose`: `bool` - (optional) Include history (default: false)\n\n" +
+	"**Response 200**: `NewTask` - The task\n" +
+	"**Response 404**: Not found\n\n" +
+	"### POST /tasks\n\n" +
+	"**Function**: `createTask`\n\n" +
+	"**Request**: `NewTask`\n\n" +
+	"**Response 201**: `string`\n\n" +
+	"### Admin (requires authentication)\n\n" +
+	"- DELETE /tasks/:id - Delete task\n"
+
+func TestParseEndpoints(t *testing.T) {
+	spec, err := NewParser().Parse(taskAPISpec, "tasks.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(spec.Functions) != 2 {
+		t.Errorf("Expected the endpoint section not to add functions, got %d", len(spec.Functions))
+	}
+	if len(spec.Endpoints) != 3 {
+		t.Fatalf("Expected 3 endpoints, got %d", len(spec.Endpoints))
+	}
+
+	get := spec.Endpoints[0]
+	if get.Method != "GET" || get.Path != "/tasks/{id}" || get.Name != "getTask" || get.Description != "Get task details" {
+		t.Errorf("Unexpected endpoint: %+v", get)
+	}
+	if len(get.PathParams) != 1 || get.PathParams[0].Name != "id" {
+		t.Errorf("Expected path parameter from template, got %+v", get.PathParams)
+	}
+	if len(get.QueryParams) != 1 || get.QueryParams[0].Required || get.QueryParams[0].Default != "false" ||
+		get.QueryParams[0].Description != "Include history" {
+		t.Errorf("Unexpected query parameters: %+v", get.QueryParams)
+	}
+	if len(get.Responses) != 2 || get.Responses[0].Type != "NewTask" || get.Responses[1].Description != "Not found" {
+		t.Errorf("Unexpected responses: %+v", get.Responses)
+	}
+	if strings.Join(get.Auth, ",") != "bearer" {
+		t.Errorf("Unexpected auth: %v", get.Auth)
+	}
+
+	if post := spec.Endpoints[1]; post.Function != "createTask" || post.RequestType != "NewTask" {
+		t.Errorf("Unexpected endpoint: %+v", post)
+	}
+
+	del := spec.Endpoints[2]
+	if del.Method != "DELETE" || del.Path != "/tasks/:id" || strings.Join(del.Tags, ",") != "Admin" || len(del.Auth) != 1 {
+		t.Errorf("Unexpected bullet endpoint: %+v", del)
+	}
+
+	// Rendered endpoints parse back unchanged
+	again, err := NewParser().Parse(Render(spec), "tasks.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(again.Endpoints) != len(spec.Endpoints) {
+		t.Fatalf("Expected %d endpoints after round trip, got %d", len(spec.Endpoints), len(again.Endpoints))
+	}
+	for i, e := range spec.Endpoints {
+		if !reflect.DeepEqual(again.Endpoints[i], e) {
+			t.Errorf("Endpoint %d changed in round trip: %+v, expected %+v", i, again.Endpoints[i], e)
+		}
+	}
+}
+
+const inventorySpec = "# Inventory\n\n" +
+	"## Types\n\n" +
+	"### Item (struct)\n\n" +
+	"- sku: string - Stock keeping unit\n" +
+	"- tags: `Set[string]` - Labels\n" +
+	"- prices: `Map[string, decimal]` - Price per currency\n" +
+	"- parent: `Optional[Item]` - Containing item\n" +
+	"- dimensions: `(float, float)` - Width and height\n" +
+	"- broken: `List[` - Malformed\n\n" +
+	"## Functions\n\n" +
+	"### findItems\n\n" +
+	"**Parameters**\n" +
+	"- `filter`: `func(Item) -> bool` - Predicate\n" +
+	"- `limit`: `int?` - Maximum results\n\n" +
+	"**Returns** `Result[List[Item], NotFound]`\n"
+
+func TestParseTypeExpressions(t *testing.T) {
+	spec, err := NewParser().Parse(inventorySpec, "inventory.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(spec.Types) != 1 || len(spec.Types[0].Fields) != 6 {
+		t.Fatalf("Expected 1 type with 6 fields, got %+v", spec.Types)
+	}
+	if f := spec.Types[0].Fields[2]; f.Type != "Map[string, decimal]" || f.Description != "Price per currency" {
+		t.Errorf("Expected backticked type with a comma to parse, got %+v", f)
+	}
+
+	params := spec.Functions[0].Parameters
+	if len(params) != 2 || params[0].Type != "func(Item) -> bool" {
+		t.Errorf("Unexpected parameters: %+v", params)
+	}
+
+	if len(spec.TypeErrors) != 1 || !strings.HasPrefix(spec.TypeErrors[0], "Item.broken: ") {
+		t.Errorf("Expected one type error for Item.broken, got %v", spec.TypeErrors)
+	}
+}
+
+const signupSpec = "# Signup\n\n" +
+	"## Types\n\n" +
+	"### Signup (struct)\n\n" +
+	"| Field | Type | Description |\n" +
+	"|-------|------|-------------|\n" +
+	"| username | string | Login name {length: 3..20, pattern: /^[a-z][a-z0-9_]*$/} |\n" +
+	"| email | string | Contact {format: email} |\n" +
+	"| age | int | Age in years {min: 13, max: 130} |\n" +
+	"| plan | string | Billing plan {oneOf: [free, pro], default: free} |\n" +
+	"| tags | Optional[List[string]] | Labels {maxLength: 5} |\n\n" +
+	"### Rating (struct)\n\n" +
+	"- score: float - Stars {min: 0, max: 5}\n" +
+	"- note: string - Comment (default: none)\n"
+
+func TestParseConstraints(t *testing.T) {
+	spec, err := NewParser().Parse(signupSpec, "signup.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(spec.Types) != 2 {
+		t.Fatalf("Expected 2 types, got %+v", spec.Types)
+	}
+
+	username := spec.Types[0].Fields[0]
+	if username.Description != "Login name" {
+		t.Errorf("Expected the constraint block removed from the description, got %q", username.Description)
+	}
+	expected := []SpecConstraint{
+		{Kind: "minLength", Value: "3"},
+		{Kind: "maxLength", Value: "20"},
+		{Kind: "pattern", Value: "^[a-z][a-z0-9_]*$"},
+	}
+	if len(username.Constraints) != len(expected) {
+		t.Fatalf("Expected constraints %+v, got %+v", expected, username.Constraints)
+	}
+	for i, c := range expected {
+		if got := username.Constraints[i]; got.Kind != c.Kind || got.Value != c.Value {
+			t.Errorf("Constraint %d = %+v, expected %+v", i, got, c)
+		}
+	}
+
+	plan := spec.Types[0].Fields[3]
+	if plan.Default != "free" || len(plan.Constraints) != 1 || strings.Join(plan.Constraints[0].Values, ",") != "free,pro" {
+		t.Errorf("Unexpected plan field: %+v", plan)
+	}
+	if note := spec.Types[1].Fields[1]; note.Default != "none" || note.Description != "Comment" {
+		t.Errorf("Expected the (default: none) note parsed, got %+v", note)
+	}
+	if len(spec.TypeErrors) != 0 {
+		t.Errorf("Expected no type errors, got %v", spec.TypeErrors)
+	}
+
+	// Rendering keeps the constraints and defaults
+	again, err := NewParser().Parse(Render(spec), "signup.spec.md")
+	if err != nil {
+		t.Fatalf("Parse(Render()) error: %v", err)
+	}
+	for i, f := range spec.Types[0].Fields {
+		if g := again.Types[0].Fields[i]; len(g.Constraints) != len(f.Constraints) || g.Default != f.Default {
+			t.Errorf("Render round trip changed %s: %+v, expected %+v", f.Name, g, f)
+		}
+	}
+
+	misplaced, _ := NewParser().Parse("# X\n\n## Types\n\n### X\n\n- on: bool - Flag {min: 1}\n", "x.spec.md")
+	if len(misplaced.TypeErrors) != 1 || !strings.Contains(misplaced.TypeErrors[0], "constraint min") {
+		t.Errorf("Expected a type error for min on a bool, got %v", misplaced.TypeErrors)
+	}
+}
+
+const billingSpec = "# Billing\n\n" +
+	"## Configuration\n\n" +
+	"| Variable | Type | Default | Required | Description |\n" +
+	"|----------|------|---------|----------|-------------|\n" +
+	"| PORT | int | 8080 | No | HTTP server listen port |\n" +
+	"| API_KEY | string | - | Yes | Payment provider key |\n" +
+	"| TIMEOUT | duration | 30s | No | Upstream request timeout |\n" +
+	"| ALLOWED_HOSTS | List[string] | a.com,b.com | No | Hosts allowed to call |\n\n" +
+	"- REGION: Deployment region (required)\n"
+
+func TestParseConfiguration(t *testing.T) {
+	spec, err := NewParser().Parse(billingSpec, "billing.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(spec.Configuration) != 5 {
+		t.Fatalf("Expected 5 configuration items, got %+v", spec.Configuration)
+	}
+	if c := spec.Configuration[0]; c.Name != "PORT" || c.Type != "int" || c.Default != "8080" || c.Required ||
+		c.Description != "HTTP server listen port" {
+		t.Errorf("Expected columns matched by header, got %+v", c)
+	}
+	if c := spec.Configuration[1]; !c.Required || c.Default != "" {
+		t.Errorf("Expected required API_KEY without default, got %+v", c)
+	}
+	if c := spec.Configuration[4]; c.Name != "REGION" || !c.Required || c.Description != "Deployment region" {
+		t.Errorf("Expected (required) note on bullet config, got %+v", c)
+	}
+
+	// Rendered configuration parses back unchanged
+	again, err := NewParser().Parse(Render(spec), "billing.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(again.Configuration) != len(spec.Configuration) {
+		t.Fatalf("Expected %d configuration items after round trip, got %+v", len(spec.Configuration), again.Configuration)
+	}
+	for i, c := range spec.Configuration {
+		if again.Configuration[i] != c {
+			t.Errorf("Configuration %d changed in round trip: %+v, expected %+v", i, again.Configuration[i], c)
+		}
+	}
+}
+
+const ledgerSpec = "# Ledger\n\n" +
+	"## Types\n\n" +
+	"### Entry (struct)\n\n" +
+	"- id: string - Identifier\n\n" +
+	"## Dependencies\n\n" +
+	"- `uuid@1.6.0` - Unique identifiers\n" +
+	"  - go: `github.com/google/uuid@v1.6.0`\n" +
+	"  - typescript: `uuid@^9.0.1`\n" +
+	"  - java: `com.fasterxml.uuid:java-uuid-generator:4.3.0`\n" +
+	"  - rust: `uuid`\n" +
+	"- `github.com/shopspring/decimal` - Money arithmetic\n\n" +
+	"| Package | Version | Purpose | C# |\n" +
+	"|---------|---------|---------|----|\n" +
+	"| humanize | 4.9.0 | Readable numbers | Humanizer.Core |\n\n" +
+	"### Go Standard Library\n\n" +
+	"| Package | Purpose |\n" +
+	"|---------|---------|\n" +
+	"| net/http | HTTP server |\n"
+
+func TestParseDependencies(t *testing.T) {
+	spec, err := NewParser().Parse(ledgerSpec, "ledger.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(spec.Dependencies) != 4 {
+		t.Fatalf("Expected 4 dependencies, got %+v", spec.Dependencies)
+	}
+	uuid := spec.Dependencies[0]
+	if uuid.Name != "uuid" || uuid.Version != "1.6.0" || uuid.Description != "Unique identifiers" ||
+		len(uuid.Packages) != 4 || uuid.Packages["go"] != "github.com/google/uuid@v1.6.0" {
+		t.Errorf("Unexpected bullet dependency: %+v", uuid)
+	}
+	if d := spec.Dependencies[2]; d.Name != "humanize" || d.Version != "4.9.0" || d.Packages["csharp"] != "Humanizer.Core" {
+		t.Errorf("Expected table columns by header, got %+v", d)
+	}
+	if d := spec.Dependencies[3]; !d.Stdlib || d.Packages["go"] != "net/http" {
+		t.Errorf("Expected Go standard library package, got %+v", d)
+	}
+
+	// Rendered dependencies parse back unchanged
+	again, err := NewParser().Parse(Render(spec), "ledger.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if !reflect.DeepEqual(again.Dependencies, spec.Dependencies) {
+		t.Errorf("Dependencies changed in round trip: %+v, expected %+v", again.Dependencies, spec.Dependencies)
+	}
+}
+
+const accountsSpec = "# Accounts\n\n" +
+	"## Types\n\n" +
+	"### User (struct)\n\n" +
+	"- id: string - Identifier\n\n" +
+	"## Functions\n\n" +
+	"### getUser\n\n" +
+	"Fetches a user.\n\n" +
+	"**Parameters**\n- `id`: `string`\n\n" +
+	"**Returns** `User`\n\n" +
+	"**Errors**\n" +
+	"- `NotFound`: no user has the id - \"user not found\"\n" +
+	"- `ErrInvalidId`: the id is malformed\n\n" +
+	"### deleteUser\n\n" +
+	"**Parameters**\n- `id`: `string`\n\n" +
+	"**Errors**\n\n" +
+	"| Error | Condition | Message |\n" +
+	"|-------|-----------|---------|\n" +
+	"| NotFound | no user has the id | |\n" +
+	"| Forbidden | the caller may not delete users | |\n"
+
+func TestParseErrors(t *testing.T) {
+	spec, err := NewParser().Parse(accountsSpec, "accounts.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(spec.Functions) != 2 {
+		t.Fatalf("Expected 2 functions, got %+v", spec.Functions)
+	}
+
+	expected := SpecError{Type: "NotFound", Condition: "no user has the id", Message: "user not found"}
+	if got := spec.Functions[0].Errors; len(got) != 2 || got[0] != expected || got[1].Type != "ErrInvalidId" {
+		t.Errorf("Expected typed bullet errors, got %+v", got)
+	}
+	if got := spec.Functions[1].Errors; len(got) != 2 || got[1].Type != "Forbidden" || got[1].Condition != "the caller may not delete users" {
+		t.Errorf("Expected table errors, got %+v", got)
+	}
+
+	plain, _ := NewParser().Parse("# X\n\n## Functions\n\n### run\n\n**Errors**\n- Server startup failure\n", "x.spec.md")
+	if got := plain.Functions[0].Errors; len(got) != 1 || got[0].Type != "" || got[0].Condition != "Server startup failure" {
+		t.Errorf("Expected an untyped condition, got %+v", got)
+	}
+
+	// Rendered errors parse back unchanged
+	again, err := NewParser().Parse(Render(spec), "accounts.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	for i, f := range spec.Functions {
+		if !reflect.DeepEqual(again.Functions[i].Errors, f.Errors) {
+			t.Errorf("Errors of %s changed in round trip: %+v, expected %+v", f.Name, again.Functions[i].Errors, f.Errors)
+		}
+	}
+}
+
+const storeSpec = "# Shop\n\n" +
+	"## Types\n\n" +
+	"### Item (struct)\n\n" +
+	"- name: string - Display name\n\n" +
+	"### Invoice (struct)\n\n" +
+	"**Module**: `billing`\n\n" +
+	"- id: string - Invoice ID\n" +
+	"- items: List<Item> - Items billed\n\n" +
+	"### Refund (struct)\n\n" +
+	"**Module**: `billing/refunds`\n\n" +
+	"- invoice: Invoice - Refunded invoice\n\n" +
+	"## Functions\n\n" +
+	"### findItem\n\n" +
+	"**Parameters**\n- `name`: `string`\n\n" +
+	"**Returns** `Item`\n\n" +
+	"### createInvoice\n\n" +
+	"**Module**: `billing`\n\n" +
+	"**Parameters**\n- `item`: `Item`\n\n" +
+	"**Returns** `Invoice`\n\n" +
+	"## API Endpoints\n\n" +
+	"### POST /invoices\n\n" +
+	"**Function**: `createInvoice`\n\n" +
+	"**Request**: `Item`\n\n" +
+	"**Response 201**: `Invoice`\n\n" +
+	"### GET /items/{name}\n\n" +
+	"**Function**: `findItem`\n\n" +
+	"**Response 200**: `Item`\n"
+
+func TestParseModules(t *testing.T) {
+	spec, err := NewParser().Parse(storeSpec, "shop.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if spec.Types[0].Module != "" || spec.Types[1].Module != "billing" || spec.Types[2].Module != "billing/refunds" {
+		t.Errorf("Unexpected type modules: %+v", spec.Types)
+	}
+	if spec.Functions[0].Module != "" || spec.Functions[1].Module != "billing" {
+		t.Errorf("Unexpected function modules: %+v", spec.Functions)
+	}
+
+	// Rendered modules parse back unchanged
+	again, err := NewParser().Parse(Render(spec), "shop.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if again.Types[2].Module != "billing/refunds" || again.Functions[1].Module != "billing" {
+		t.Errorf("Modules changed in round trip: %+v, %+v", again.Types, again.Functions)
+	}
+}
+
+const shortenerSpec = "# Shortener\n\n" +
+	"Shortens URLs.\n\n" +
+	"## Types\n\n" +
+	"### Link (struct)\n\n" +
+	"- code: string - Short code\n" +
+	"- url: string - Target URL\n\n" +
+	"## Functions\n\n" +
+	"### Shorten\n\n" +
+	"**Parameters**\n" +
+	"- `url`: `string`\n" +
+	"- `alias`: `Optional[string]`\n" +
+	"- `ttl`: `int`\n\n" +
+	"**Returns** `Link`\n\n" +
+	"**Errors**\n" +
+	"- `InvalidUrl`: the URL is not valid\n\n" +
+	"### Migrate\n\n" +
+	"**Parameters**\n" +
+	"- `steps`: `int`\n" +
+	"- `dryRun`: `bool`\n\n" +
+	"**Returns** `int`\n\n" +
+	"## Commands\n\n" +
+	"### shorten <url>\n\n" +
+	"Shortens a URL.\n\n" +
+	"**Function**: `shorten`\n\n" +
+	"**Arguments**:\n" +
+	"- `url`: `string` - URL to shorten\n\n" +
+	"**Flags**:\n" +
+	"- `--alias`, `-a`: `string` - Custom alias\n" +
+	"- `--ttl`: `int` - Lifetime in seconds (default: 3600)\n\n" +
+	"**Exit Codes**:\n" +
+	"- `0` - Shortened\n" +
+	"- `2` - The URL is invalid\n\n" +
+	"**Output**: `json`\n\n" +
+	"### db migrate [steps]\n\n" +
+	"Applies pending migrations.\n\n" +
+	"**Arguments**:\n" +
+	"- `steps`: `int` - Migrations to apply (optional)\n\n" +
+	"**Flags**:\n" +
+	"- `--dry-run`: `bool` - Print the migrations without applying them\n\n" +
+	"### db seed\n\n" +
+	"Loads sample data.\n"
+
+func TestParseCommands(t *testing.T) {
+	spec, err := NewParser().Parse(shortenerSpec, "shortener.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if len(spec.Commands) != 4 {
+		t.Fatalf("Expected shorten, db and its 2 subcommands, got %+v", spec.Commands)
+	}
+	shorten := spec.Commands[0]
+	if shorten.Name != "shorten" || shorten.Function != "shorten" || shorten.Output != "json" {
+		t.Errorf("Expected shorten calling shorten with JSON output, got %+v", shorten)
+	}
+	if len(shorten.Args) != 1 || shorten.Args[0].Name != "url" {
+		t.Errorf("Expected the url argument, got %+v", shorten.Args)
+	}
+	if len(shorten.Flags) != 2 || shorten.Flags[0].Short != "a" || shorten.Flags[1].Default != "3600" {
+		t.Errorf("Expected --alias/-a and --ttl defaulting to 3600, got %+v", shorten.Flags)
+	}
+	if len(shorten.ExitCodes) != 2 || shorten.ExitCodes[1].Code != 2 {
+		t.Errorf("Expected exit codes 0 and 2, got %+v", shorten.ExitCodes)
+	}
+
+	db, migrate := spec.Commands[1], spec.Commands[2]
+	if db.Name != "db" || db.Parent != "" || migrate.Path() != "db migrate" || spec.Commands[3].Parent != "db" {
+		t.Errorf("Expected db grouping migrate and seed, got %+v", spec.Commands[1:])
+	}
+	if steps := migrate.Args; len(steps) != 1 || steps[0].Required {
+		t.Errorf("Expected an optional steps argument, got %+v", steps)
+	}
+
+	// Rendered commands parse back unchanged
+	again, err := NewParser().Parse(Render(spec), "shortener.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(again.Commands) != 4 || again.Commands[3].Path() != "db seed" || again.Commands[0].Flags[1].Default != "3600" {
+		t.Errorf("Commands changed in round trip: %+v", again.Commands)
+	}
+}
+
+const ordersSpec = "# Orders\n\n" +
+	"Tracks orders.\n\n" +
+	"## State Machines\n\n" +
+	"### Order\n\n" +
+	"An order from checkout to delivery.\n\n" +
+	"**States**:\n" +
+	"- `pending` - Awaiting payment (initial)\n" +
+	"- `paid`\n" +
+	"- `shipped`\n" +
+	"- `delivered` - Handed to the customer (final)\n" +
+	"- `cancelled` (final)\n\n" +
+	"| From | Event | To | Guard | Action |\n" +
+	"|------|-------|----|-------|--------|\n" +
+	"| pending | pay | paid | paymentValid | capturePayment |\n" +
+	"| pending, paid | cancel | cancelled | - | - |\n" +
+	"| paid | ship | shipped | inStock | - |\n" +
+	"| paid | ship | cancelled | - | refund |\n" +
+	"| shipped | deliver | delivered | - | - |\n\n" +
+	"### Door\n\n" +
+	"```mermaid\n" +
+	"stateDiagram-v2\n" +
+	"    [*] --> Closed\n" +
+	"    Closed --> Open : open\n" +
+	"    Open --> Closed : close\n" +
+	"    Closed : The door is shut\n" +
+	"```\n"
+
+func TestParseStateMachines(t *testing.T) {
+	spec, err := NewParser().Parse(ordersSpec, "orders.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(spec.StateMachines) != 2 {
+		t.Fatalf("Expected 2 state machines, got %+v", spec.StateMachines)
+	}
+
+	order := spec.StateMachines[0]
+	if order.Name != "Order" || order.Description != "An order from checkout to delivery." {
+		t.Errorf("Expected the Order machine and its description, got %q: %q", order.Name, order.Description)
+	}
+	if order.InitialState() != "pending" {
+		t.Errorf("Expected pending to be the initial state, got %q", order.InitialState())
+	}
+	if len(order.States) != 5 || !order.States[3].Final || order.States[3].Description != "Handed to the customer" {
+		t.Errorf("Expected 5 states with delivered final, got %+v", order.States)
+	}
+	var events []string
+	for _, e := range order.Events {
+		events = append(events, e.Name)
+	}
+	if strings.Join(events, ",") != "pay,cancel,ship,deliver" {
+		t.Errorf("Expected the events in order of first use, got %v", events)
+	}
+	if len(order.Transitions) != 6 {
+		t.Fatalf("Expected 6 transitions with cancel expanded per state, got %+v", order.Transitions)
+	}
+	first := order.Transitions[0]
+	if first.From != "pending" || first.Event != "pay" || first.To != "paid" || first.Guard != "paymentValid" || first.Action != "capturePayment" {
+		t.Errorf("Expected pending --pay--> paid guarded and with an action, got %+v", first)
+	}
+	if order.Transitions[2].From != "paid" || order.Transitions[2].Guard != "" {
+		t.Errorf("Expected paid --cancel--> cancelled without a guard, got %+v", order.Transitions[2])
+	}
+
+	door := spec.StateMachines[1]
+	if door.InitialState() != "Closed" || len(door.Transitions) != 2 || door.States[0].Description != "The door is shut" {
+		t.Errorf("Expected the Mermaid diagram to start Closed with 2 transitions, got %+v", door)
+	}
+
+	// Rendering and parsing again keeps the machines
+	again, err := NewParser().Parse(Render(spec), "orders.spec.md")
+	if err != nil {
+		t.Fatalf("Parse(Render()) error: %v", err)
+	}
+	if len(again.StateMachines) != 2 || len(again.StateMachines[0].Transitions) != 6 || again.StateMachines[0].InitialState() != "pending" || !again.StateMachines[0].States[4].Final {
+		t.Errorf("Expected the rendered machines to parse the same, got %+v", again.StateMachines)
+	}
+}
+
+const storeInterfaceSpec = "# Store\n\n" +
+	"## Types\n\n" +
+	"### User (struct)\n\n" +
+	"- id: string - Identifier\n\n" +
+	"### UserStore (interface)\n\n" +
+	"Persists users.\n\n" +
+	"- `find(id: string): User` - Looks up a user\n" +
+	"- `save(user: User, overwrite?: bool)`\n" +
+	"- `async list(limit: int = 10): List<User>`\n"
+
+func TestParseInterfaceMethods(t *testing.T) {
+	spec, err := NewParser().Parse(storeInterfaceSpec, "store.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	methods := spec.Types[1].Methods
+	if len(methods) != 3 {
+		t.Fatalf("Expected 3 methods, got %+v", methods)
+	}
+	if m := methods[0]; m.Name != "find" || m.Description != "Looks up a user" || len(m.Parameters) != 1 || m.Returns[0].Type != "User" {
+		t.Errorf("Unexpected find signature: %+v", m)
+	}
+	if p := methods[1].Parameters[1]; p.Name != "overwrite" || p.Type != "bool" || p.Required {
+		t.Errorf("Expected optional overwrite parameter, got %+v", p)
+	}
+	if m := methods[2]; !m.IsAsync || m.Parameters[0].Default != "10" || m.Returns[0].Type != "List<User>" {
+		t.Errorf("Unexpected list signature: %+v", m)
+	}
+
+	// Rendered signatures parse back unchanged
+	again, err := NewParser().Parse(Render(spec), "store.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if !reflect.DeepEqual(again.Types[1].Methods, methods) {
+		t.Errorf("Methods changed in round trip: %+v, expected %+v", again.Types[1].Methods, methods)
+	}
+}
+
+func TestTotalItems(t *testing.T) {
+	spec, err := NewParser().Parse(taskAPISpec, "tasks.spec.md")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	// Endpoints and the other later sections are not counted
+	if spec.TotalItems != 3 {
+		t.Errorf("Expected 1 type and 2 functions, got %d items", spec.TotalItems)
+	}
+}
@@ -0,0 +1,771 @@
+package specparser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Render renders a spec analysis as a .spec.md document in the layout the
+// parser reads, so imported specs can be written out and parsed back.
+func Render(spec *SpecAnalysis) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", spec.Name))
+
+	if spec.Overview != "" {
+		sb.WriteString("## Overview\n\n")
+		sb.WriteString(spec.Overview)
+		sb.WriteString("\n\n")
+	}
+
+	if len(spec.Types) > 0 {
+		sb.WriteString("## Types\n\n")
+		for _, t := range spec.Types {
+			renderType(&sb, t)
+		}
+	}
+
+	if len(spec.Functions) > 0 {
+		sb.WriteString("## Functions\n\n")
+		for _, f := range spec.Functions {
+			renderFunction(&sb, f)
+		}
+	}
+
+	if len(spec.Endpoints) > 0 {
+		sb.WriteString("## API Endpoints\n\n")
+		for _, e := range spec.Endpoints {
+			renderEndpoint(&sb, e)
+		}
+	}
+
+	if len(spec.Commands) > 0 {
+		sb.WriteString("## Commands\n\n")
+		renderCommands(&sb, spec.Commands)
+	}
+
+	if len(spec.StateMachines) > 0 {
+		sb.WriteString("## State Machines\n\n")
+		for _, m := range spec.StateMachines {
+			renderStateMachine(&sb, m)
+		}
+	}
+
+	if len(spec.Tables) > 0 {
+		sb.WriteString(RenderTables(spec.Tables))
+	}
+
+	if len(spec.Configuration) > 0 {
+		sb.WriteString("## Configuration\n\n")
+		sb.WriteString("| Name | Type | Default | Required | Description |\n")
+		sb.WriteString("|------|------|---------|----------|-------------|\n")
+		for _, c := range spec.Configuration {
+			def := c.Default
+			if def == "" {
+				def = "-"
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", c.Name, tableCell(c.Type), tableCell(def), yesNo(c.Required), tableCell(c.Description)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(spec.Dependencies) > 0 {
+		sb.WriteString("## Dependencies\n\n")
+		renderDependencies(&sb, spec.Dependencies)
+	}
+
+	if len(spec.Properties) > 0 {
+		sb.WriteString("## Properties\n\n")
+		renderProperties(&sb, spec.Properties)
+	}
+
+	if len(spec.Tests) > 0 {
+		sb.WriteString("## Tests\n\n")
+		for _, t := range spec.Tests {
+			renderTest(&sb, t)
+		}
+	}
+
+	return sb.String()
+}
+
+// renderType renders a single type definition.
+func renderType(sb *strings.Builder, t SpecType) {
+	kind := t.Kind
+	if kind == "" {
+		kind = "struct"
+	}
+	sb.WriteString(fmt.Sprintf("### %s (%s)\n\n", t.Name, kind))
+
+	if t.Description != "" {
+		sb.WriteString(t.Description)
+		sb.WriteString("\n\n")
+	}
+	if t.AliasOf != "" {
+		sb.WriteString(fmt.Sprintf("**Alias of**: `%s`\n\n", t.AliasOf))
+	}
+	if t.Module != "" {
+		sb.WriteString(fmt.Sprintf("**Module**: `%s`\n\n", t.Module))
+	}
+	if len(t.Implements) > 0 {
+		sb.WriteString(fmt.Sprintf("**Implements**: %s\n\n", codeList(t.Implements)))
+	}
+	if len(t.Directives) > 0 {
+		sb.WriteString(fmt.Sprintf("**Directives**: %s\n\n", codeList(t.Directives)))
+	}
+	if len(t.Naming) > 0 {
+		var policies []string
+		for _, format := range sortedKeys(t.Naming) {
+			policies = append(policies, format+": "+t.Naming[format])
+		}
+		sb.WriteString(fmt.Sprintf("**Naming**: %s\n\n", strings.Join(policies, ", ")))
+	}
+
+	switch kind {
+	case "enum":
+		for _, v := range t.Values {
+			sb.WriteString(fmt.Sprintf("- `%s`", v.Name))
+			if v.Value != "" && v.Value != v.Name {
+				sb.WriteString(fmt.Sprintf(" = `%s`", v.Value))
+			}
+			if v.Description != "" {
+				sb.WriteString(" - " + v.Description)
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+
+	default:
+		if len(t.Methods) > 0 {
+			for _, m := range t.Methods {
+				sb.WriteString(fmt.Sprintf("- `%s`", FormatSignature(m)))
+				if m.Description != "" {
+					sb.WriteString(" - " + m.Description)
+				}
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n")
+		}
+
+		if len(t.Fields) > 0 {
+			header := "Field"
+			if kind == "union" {
+				header = "Variant"
+			}
+			sb.WriteString(fmt.Sprintf("| %s | Type | Description |\n", header))
+			sb.WriteString("|-------|------|-------------|\n")
+			for _, f := range t.Fields {
+				// A field that may be left out is marked name?; only an
+				// Optional type may also be null
+				name := f.Name
+				if !f.Required && kind != "union" && !strings.HasPrefix(strings.ToLower(f.Type), "optional") {
+					name += "?"
+				}
+				desc := f.Description
+				if f.Default != "" {
+					desc = strings.TrimSpace(fmt.Sprintf("%s (default: %s)", desc, f.Default))
+				}
+				if len(f.Directives) > 0 {
+					desc = strings.TrimSpace(desc + " " + strings.Join(f.Directives, " "))
+				}
+				if len(f.Constraints) > 0 {
+					desc = strings.TrimSpace(desc + " " + renderConstraints(f.Constraints))
+				}
+				if len(f.Tags) > 0 {
+					var tags []string
+					for _, key := range sortedKeys(f.Tags) {
+						tags = append(tags, fmt.Sprintf("%s:%q", key, f.Tags[key]))
+					}
+					desc = strings.TrimSpace(desc + " `" + strings.Join(tags, " ") + "`")
+				}
+				sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", name, tableCell(f.Type), tableCell(desc)))
+			}
+			sb.WriteString("\n")
+		}
+	}
+}
+
+// renderFunction renders a single function definition.
+func renderFunction(sb *strings.Builder, f SpecFunction) {
+	sb.WriteString(fmt.Sprintf("### %s\n\n", f.Name))
+
+	if f.Description != "" {
+		sb.WriteString(f.Description)
+		sb.WriteString("\n\n")
+	}
+	if f.Receiver != "" {
+		sb.WriteString(fmt.Sprintf("**Receiver** `%s`\n\n", f.Receiver))
+	}
+	if f.Module != "" {
+		sb.WriteString(fmt.Sprintf("**Module**: `%s`\n\n", f.Module))
+	}
+	switch {
+	case f.ClientStreaming && f.ServerStreaming:
+		sb.WriteString("**Streaming** `bidirectional`\n\n")
+	case f.ClientStreaming:
+		sb.WriteString("**Streaming** `client`\n\n")
+	case f.ServerStreaming:
+		sb.WriteString("**Streaming** `server`\n\n")
+	}
+	if len(f.Directives) > 0 {
+		sb.WriteString(fmt.Sprintf("**Directives**: %s\n\n", codeList(f.Directives)))
+	}
+
+	if len(f.Parameters) > 0 {
+		sb.WriteString("**Parameters**\n")
+		renderParameters(sb, f.Parameters)
+		sb.WriteString("\n")
+	}
+
+	if len(f.Returns) > 0 {
+		var returns []string
+		for _, r := range f.Returns {
+			returns = append(returns, r.Type)
+		}
+		sb.WriteString(fmt.Sprintf("**Returns** `%s`\n\n", strings.Join(returns, ", ")))
+	}
+
+	if f.Logic != "" {
+		sb.WriteString("**Logic**\n")
+		sb.WriteString(f.Logic)
+		sb.WriteString("\n\n")
+	}
+
+	if len(f.Errors) > 0 {
+		sb.WriteString("**Errors**\n")
+		for _, e := range f.Errors {
+			sb.WriteString("- " + renderError(e) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// renderError renders an error condition as "`Type`: condition - "message"".
+func renderError(e SpecError) string {
+	line := e.Condition
+	if e.Type != "" {
+		line = strings.TrimSpace(fmt.Sprintf("`%s`: %s", e.Type, e.Condition))
+	}
+	if e.Message != "" {
+		line += ` - "` + e.Message + `"`
+	}
+	return line
+}
+
+// renderEndpoint renders a single HTTP endpoint.
+func renderEndpoint(sb *strings.Builder, e SpecEndpoint) {
+	sb.WriteString(fmt.Sprintf("### %s %s\n\n", e.Method, e.Path))
+
+	if e.Description != "" {
+		sb.WriteString(e.Description)
+		sb.WriteString("\n\n")
+	}
+
+	if e.Name != "" {
+		sb.WriteString(fmt.Sprintf("**Operation ID**: %s\n\n", e.Name))
+	}
+	if e.Function != "" {
+		sb.WriteString(fmt.Sprintf("**Function**: `%s`\n\n", e.Function))
+	}
+	if len(e.Auth) > 0 {
+		sb.WriteString(fmt.Sprintf("**Auth**: %s\n\n", strings.Join(e.Auth, ", ")))
+	}
+	if len(e.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("**Tags**: %s\n\n", strings.Join(e.Tags, ", ")))
+	}
+
+	for _, group := range []struct {
+		title  string
+		params []SpecParameter
+	}{
+		{"Path Parameters", e.PathParams},
+		{"Query Parameters", e.QueryParams},
+		{"Header Parameters", e.HeaderParams},
+	} {
+		if len(group.params) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("**%s**:\n", group.title))
+		renderParameters(sb, group.params)
+		sb.WriteString("\n")
+	}
+
+	if e.RequestType != "" {
+		sb.WriteString(fmt.Sprintf("**Request**: `%s`\n\n", e.RequestType))
+	}
+
+	for _, r := range e.Responses {
+		sb.WriteString(fmt.Sprintf("**Response %s**:", r.Status))
+		if r.Type != "" {
+			sb.WriteString(fmt.Sprintf(" `%s`", r.Type))
+			if r.Description != "" {
+				sb.WriteString(" -")
+			}
+		}
+		if r.Description != "" {
+			sb.WriteString(" " + r.Description)
+		}
+		sb.WriteString("\n")
+	}
+	if len(e.Responses) > 0 {
+		sb.WriteString("\n")
+	}
+}
+
+// renderCommands renders each command as a block headed by its path from
+// the root.
+func renderCommands(sb *strings.Builder, commands []SpecCommand) {
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("### %s\n\n", c.Path()))
+
+		if c.Description != "" {
+			sb.WriteString(c.Description)
+			sb.WriteString("\n\n")
+		}
+		if c.Function != "" {
+			sb.WriteString(fmt.Sprintf("**Function**: `%s`\n\n", c.Function))
+		}
+		if len(c.Args) > 0 {
+			sb.WriteString("**Arguments**:\n")
+			renderParameters(sb, c.Args)
+			sb.WriteString("\n")
+		}
+		if len(c.Flags) > 0 {
+			sb.WriteString("**Flags**:\n")
+			for _, f := range c.Flags {
+				sb.WriteString(fmt.Sprintf("- `--%s`", f.Name))
+				if f.Short != "" {
+					sb.WriteString(fmt.Sprintf(", `-%s`", f.Short))
+				}
+				sb.WriteString(fmt.Sprintf(": `%s`", f.Type))
+
+				var notes []string
+				if f.Required {
+					notes = append(notes, "(required)")
+				}
+				if f.Description != "" {
+					notes = append(notes, f.Description)
+				}
+				if f.Default != "" {
+					notes = append(notes, fmt.Sprintf("(default: %s)", f.Default))
+				}
+				if len(notes) > 0 {
+					sb.WriteString(" - " + strings.Join(notes, " "))
+				}
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n")
+		}
+		if len(c.ExitCodes) > 0 {
+			sb.WriteString("**Exit Codes**:\n")
+			for _, e := range c.ExitCodes {
+				sb.WriteString(fmt.Sprintf("- `%d`", e.Code))
+				if e.Description != "" {
+					sb.WriteString(" - " + e.Description)
+				}
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n")
+		}
+		if c.Output != "" {
+			sb.WriteString(fmt.Sprintf("**Output**: `%s`\n\n", c.Output))
+		}
+	}
+}
+
+// renderStateMachine renders a machine's states and events as bullets and
+// its transitions as a table.
+func renderStateMachine(sb *strings.Builder, m SpecStateMachine) {
+	sb.WriteString(fmt.Sprintf("### %s\n\n", m.Name))
+	if m.Description != "" {
+		sb.WriteString(m.Description)
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("**States**:\n")
+	for _, s := range m.States {
+		sb.WriteString(fmt.Sprintf("- `%s`", s.Name))
+		var notes []string
+		if s.Description != "" {
+			notes = append(notes, s.Description)
+		}
+		if s.Name == m.InitialState() {
+			notes = append(notes, "(initial)")
+		}
+		if s.Final {
+			notes = append(notes, "(final)")
+		}
+		if len(notes) > 0 {
+			sb.WriteString(" - " + strings.Join(notes, " "))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("**Events**:\n")
+	for _, e := range m.Events {
+		sb.WriteString(fmt.Sprintf("- `%s`", e.Name))
+		if e.Description != "" {
+			sb.WriteString(" - " + e.Description)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	if len(m.Transitions) > 0 {
+		sb.WriteString("| From | Event | To | Guard | Action |\n")
+		sb.WriteString("|------|-------|----|-------|--------|\n")
+		for _, t := range m.Transitions {
+			guard, action := t.Guard, t.Action
+			if guard == "" {
+				guard = "-"
+			}
+			if action == "" {
+				action = "-"
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", t.From, t.Event, t.To, tableCell(guard), tableCell(action)))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// RenderTables renders tables as a spec's Database section, for callers
+// that assemble a spec's Markdown themselves.
+func RenderTables(tables []SpecTable) string {
+	var sb strings.Builder
+	sb.WriteString("## Database\n\n")
+	for _, t := range tables {
+		renderTable(&sb, t)
+	}
+	return sb.String()
+}
+
+// renderTable renders a database table: its columns as a table, followed
+// by bullets for its indexes, foreign keys, checks and relations.
+func renderTable(sb *strings.Builder, t SpecTable) {
+	sb.WriteString(fmt.Sprintf("### `%s`\n\n", t.Name))
+	if t.Description != "" {
+		sb.WriteString(t.Description)
+		sb.WriteString("\n\n")
+	}
+	if t.Entity != "" {
+		sb.WriteString(fmt.Sprintf("**Entity**: `%s`\n\n", t.Entity))
+	}
+
+	sb.WriteString("| Column | Type | SQL Type | Nullable | Default | Constraints | Description |\n")
+	sb.WriteString("|--------|------|----------|----------|---------|-------------|-------------|\n")
+	for _, c := range t.Columns {
+		var constraints []string
+		if len(t.PrimaryKey) == 1 && t.PrimaryKey[0] == c.Name {
+			constraints = append(constraints, "primary key")
+		}
+		if c.Unique {
+			constraints = append(constraints, "unique")
+		}
+		if c.AutoIncrement {
+			constraints = append(constraints, "auto increment")
+		}
+		cells := []string{"`" + c.Name + "`", "`" + c.Type + "`", c.SQLType, yesNo(c.Nullable), c.Default, strings.Join(constraints, ", "), c.Description}
+		for i, cell := range cells {
+			if cell == "" {
+				cell = "-"
+			} else if i == 2 || i == 4 {
+				cell = "`" + cell + "`"
+			}
+			cells[i] = tableCell(cell)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	sb.WriteString("\n")
+
+	if len(t.PrimaryKey) > 1 {
+		sb.WriteString(fmt.Sprintf("**Primary Key**: %s\n\n", codeList(t.PrimaryKey)))
+	}
+
+	if len(t.Indexes) > 0 {
+		sb.WriteString("**Indexes**:\n")
+		for _, idx := range t.Indexes {
+			unique := ""
+			if idx.Unique {
+				unique = " (unique)"
+			}
+			sb.WriteString(fmt.Sprintf("- `%s`%s: %s\n", idx.Name, unique, codeList(idx.Columns)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(t.ForeignKeys) > 0 {
+		sb.WriteString("**Foreign Keys**:\n")
+		for _, fk := range t.ForeignKeys {
+			sb.WriteString("- ")
+			if fk.Name != "" {
+				sb.WriteString(fmt.Sprintf("`%s`: ", fk.Name))
+			}
+			sb.WriteString(fmt.Sprintf("%s references `%s` (%s)", codeList(fk.Columns), fk.RefTable, codeList(fk.RefColumns)))
+			if fk.OnDelete != "" {
+				sb.WriteString(" on delete " + strings.ToLower(fk.OnDelete))
+			}
+			if fk.OnUpdate != "" {
+				sb.WriteString(" on update " + strings.ToLower(fk.OnUpdate))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(t.Checks) > 0 {
+		sb.WriteString("**Checks**:\n")
+		for _, check := range t.Checks {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", check))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(t.Relations) > 0 {
+		sb.WriteString("**Relations**:\n")
+		for _, r := range t.Relations {
+			sb.WriteString(fmt.Sprintf("- `%s` %s `%s`", r.Name, r.Kind, r.Table))
+			if r.Through != "" {
+				sb.WriteString(fmt.Sprintf(" through `%s`", r.Through))
+			}
+			if len(r.Columns) > 0 {
+				sb.WriteString(" via " + codeList(r.Columns))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// renderTest renders a single test case.
+func renderTest(sb *strings.Builder, t SpecTest) {
+	sb.WriteString(fmt.Sprintf("### %s\n\n", t.Name))
+
+	if t.Description != "" {
+		sb.WriteString(t.Description)
+		sb.WriteString("\n\n")
+	}
+
+	if len(t.Given) > 0 {
+		sb.WriteString("**Given**\n")
+		for _, g := range t.Given {
+			sb.WriteString(fmt.Sprintf("- %s\n", g.Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	if t.When != "" {
+		sb.WriteString(fmt.Sprintf("**When** %s\n\n", t.When))
+	}
+
+	if len(t.Then) > 0 {
+		sb.WriteString("**Then**\n")
+		for _, a := range t.Then {
+			sb.WriteString(fmt.Sprintf("- %s\n", a.Description))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// renderParameters renders parameters as a bullet list.
+// renderProperties renders the properties not about a function as
+// bullets, followed by a "### name" heading for each function's.
+func renderProperties(sb *strings.Builder, properties []SpecProperty) {
+	var functions []string
+	byFunction := make(map[string][]SpecProperty)
+	for _, p := range properties {
+		if _, ok := byFunction[p.Function]; !ok && p.Function != "" {
+			functions = append(functions, p.Function)
+		}
+		byFunction[p.Function] = append(byFunction[p.Function], p)
+	}
+
+	bullets := func(properties []SpecProperty) {
+		for _, p := range properties {
+			switch {
+			case p.Expression == "":
+				sb.WriteString(fmt.Sprintf("- %s\n", p.Description))
+			case p.Description == "":
+				sb.WriteString(fmt.Sprintf("- `%s`\n", p.Expression))
+			default:
+				sb.WriteString(fmt.Sprintf("- `%s` - %s\n", p.Expression, p.Description))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	if len(byFunction[""]) > 0 {
+		bullets(byFunction[""])
+	}
+	for _, name := range functions {
+		sb.WriteString(fmt.Sprintf("### %s\n\n", name))
+		bullets(byFunction[name])
+	}
+}
+
+// FormatSignature renders a method signature the way interface sections
+// list them, e.g. "find(id: string, limit?: int): User".
+func FormatSignature(f SpecFunction) string {
+	params := make([]string, len(f.Parameters))
+	for i, p := range f.Parameters {
+		name := p.Name
+		if !p.Required && p.Default == "" {
+			name += "?"
+		}
+		params[i] = name
+		if p.Type != "" {
+			params[i] += ": " + p.Type
+		}
+		if p.Default != "" {
+			params[i] += " = " + p.Default
+		}
+	}
+
+	sig := fmt.Sprintf("%s(%s)", f.Name, strings.Join(params, ", "))
+	if f.IsAsync {
+		sig = "async " + sig
+	}
+	switch len(f.Returns) {
+	case 0:
+	case 1:
+		sig += ": " + f.Returns[0].Type
+	default:
+		returns := make([]string, len(f.Returns))
+		for i, r := range f.Returns {
+			returns[i] = r.Type
+		}
+		sig += ": (" + strings.Join(returns, ", ") + ")"
+	}
+	return sig
+}
+
+func renderParameters(sb *strings.Builder, params []SpecParameter) {
+	for _, p := range params {
+		sb.WriteString(fmt.Sprintf("- `%s`: `%s`", p.Name, p.Type))
+
+		var notes []string
+		if !p.Required {
+			notes = append(notes, "(optional)")
+		}
+		if p.Description != "" {
+			notes = append(notes, p.Description)
+		}
+		if p.Default != "" {
+			notes = append(notes, fmt.Sprintf("(default: %s)", p.Default))
+		}
+		if len(notes) > 0 {
+			sb.WriteString(" - " + strings.Join(notes, " "))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// renderConstraints renders field constraints as a "{min: 1, max: 10}" block.
+func renderConstraints(constraints []SpecConstraint) string {
+	entries := make([]string, len(constraints))
+	for i, c := range constraints {
+		switch c.Kind {
+		case "pattern":
+			entries[i] = fmt.Sprintf("pattern: /%s/", c.Value)
+		case "oneOf":
+			entries[i] = fmt.Sprintf("oneOf: [%s]", strings.Join(c.Values, ", "))
+		default:
+			entries[i] = fmt.Sprintf("%s: %s", c.Kind, c.Value)
+		}
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+// codeList renders values as a comma-separated list of code spans.
+func codeList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "`" + v + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// tableCell escapes a value for use in a markdown table cell.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "|", "\\|")
+	if s == "" {
+		return " "
+	}
+	return s
+}
+
+// renderDependencies renders installed dependencies as bullets, with
+// nested bullets for per-language packages, followed by standard library
+// packages grouped under a heading per language.
+func renderDependencies(sb *strings.Builder, deps []SpecDependency) {
+	var stdlibLangs []string
+	stdlib := make(map[string][]SpecDependency)
+	for _, d := range deps {
+		if d.Stdlib {
+			lang := ""
+			for l := range d.Packages {
+				lang = l
+			}
+			if _, ok := stdlib[lang]; !ok {
+				stdlibLangs = append(stdlibLangs, lang)
+			}
+			stdlib[lang] = append(stdlib[lang], d)
+			continue
+		}
+		renderDependency(sb, d)
+		for _, lang := range sortedKeys(d.Packages) {
+			sb.WriteString(fmt.Sprintf("  - %s: `%s`\n", lang, d.Packages[lang]))
+		}
+	}
+	sb.WriteString("\n")
+
+	for _, lang := range stdlibLangs {
+		heading := "Standard Library"
+		if name, ok := languageHeadings[lang]; ok {
+			heading = name + " " + heading
+		}
+		sb.WriteString(fmt.Sprintf("### %s\n\n", heading))
+		for _, d := range stdlib[lang] {
+			renderDependency(sb, d)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// languageHeadings names languages in dependency headings.
+var languageHeadings = map[string]string{
+	"go": "Go", "typescript": "TypeScript", "python": "Python",
+	"java": "Java", "rust": "Rust", "csharp": "C#",
+}
+
+// renderDependency renders a dependency bullet, with its version after "@".
+func renderDependency(sb *strings.Builder, d SpecDependency) {
+	name := d.Name
+	if d.Version != "" {
+		name += "@" + d.Version
+	}
+	sb.WriteString(fmt.Sprintf("- `%s`", name))
+	if d.Description != "" {
+		sb.WriteString(" - " + d.Description)
+	}
+	sb.WriteString("\n")
+}
+
+// yesNo renders a flag for a Yes/No table column.
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+// sortedKeys returns the keys of a map in order, for stable rendering.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,6 +1,15 @@
 // Package specparser provides types and utilities for parsing specification files.
 package specparser
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kon1790/rpg/internal/typeexpr"
+)
+
 // SpecAnalysis contains the fully parsed specification ready for code generation.
 type SpecAnalysis struct {
 	// Name is the project/spec name
@@ -18,14 +27,33 @@ type SpecAnalysis struct {
 	// Tests contains test case definitions
 	Tests []SpecTest `json:"tests"`
 
+	// Properties are invariants checked by property-based tests
+	Properties []SpecProperty `json:"properties,omitempty"`
+
 	// Dependencies contains external dependency requirements
 	Dependencies []SpecDependency `json:"dependencies"`
 
 	// Configuration contains environment variables and settings
 	Configuration []SpecConfig `json:"configuration"`
 
+	// Endpoints contains HTTP API endpoint definitions
+	Endpoints []SpecEndpoint `json:"endpoints"`
+
+	// Commands is the command line's tree of subcommands
+	Commands []SpecCommand `json:"commands,omitempty"`
+
+	// StateMachines are the workflows entities move through
+	StateMachines []SpecStateMachine `json:"stateMachines,omitempty"`
+
+	// Tables are the database tables the data model is stored in
+	Tables []SpecTable `json:"tables,omitempty"`
+
 	// TotalItems is the sum of types, functions, tests, and dependencies
 	TotalItems int `json:"totalItems"`
+
+	// TypeErrors lists field, parameter and return types that are not valid
+	// type expressions
+	TypeErrors []string `json:"typeErrors,omitempty"`
 }
 
 // SpecType represents a type definition from the spec.
@@ -33,17 +61,17 @@ type SpecType struct {
 	// Name of the type
 	Name string `json:"name"`
 
-	// Kind is the type kind: struct, interface, enum, alias
+	// Kind is the type kind: struct, interface, enum, union, alias
 	Kind string `json:"kind"`
 
 	// Description explains the purpose of this type
 	Description string `json:"description"`
 
-	// Fields for struct types
+	// Fields for struct types, or the variants of a union type
 	Fields []SpecField `json:"fields,omitempty"`
 
-	// Methods for interfaces
-	Methods []string `json:"methods,omitempty"`
+	// Methods are an interface's method signatures
+	Methods []SpecFunction `json:"methods,omitempty"`
 
 	// Values for enum types
 	Values []SpecEnumValue `json:"values,omitempty"`
@@ -51,9 +79,24 @@ type SpecType struct {
 	// Implements lists interfaces this type implements
 	Implements []string `json:"implements,omitempty"`
 
+	// Directives lists schema annotations such as GraphQL directives
+	Directives []string `json:"directives,omitempty"`
+
+	// AliasOf is the type expression an alias type stands for, such as
+	// "datetime"; empty when the target is unknown
+	AliasOf string `json:"aliasOf,omitempty"`
+
 	// Generic type parameters
 	Generic []string `json:"generic,omitempty"`
 
+	// Naming maps a serialization format (json, xml, ...) to the naming
+	// policy of its field names, such as snake_case or camelCase
+	Naming map[string]string `json:"naming,omitempty"`
+
+	// Module is the slash-separated module path the type belongs to, such
+	// as "billing/invoices"; empty for the root module
+	Module string `json:"module,omitempty"`
+
 	// IsPublic indicates if the type is exported/public
 	IsPublic bool `json:"isPublic"`
 }
@@ -77,6 +120,24 @@ type SpecField struct {
 
 	// Default value if any
 	Default string `json:"default,omitempty"`
+
+	// Directives lists schema annotations such as GraphQL directives
+	Directives []string `json:"directives,omitempty"`
+
+	// Constraints lists the validation rules values of the field must meet
+	Constraints []SpecConstraint `json:"constraints,omitempty"`
+}
+
+// SpecConstraint represents a validation rule on a field.
+type SpecConstraint struct {
+	// Kind is min, max, minLength, maxLength, pattern, format or oneOf
+	Kind string `json:"kind"`
+
+	// Value is the bound, regular expression or format (email, url, uuid)
+	Value string `json:"value,omitempty"`
+
+	// Values lists the allowed values of a oneOf constraint
+	Values []string `json:"values,omitempty"`
 }
 
 // SpecEnumValue represents a value in an enum type.
@@ -99,6 +160,10 @@ type SpecFunction struct {
 	// Receiver type if this is a method
 	Receiver string `json:"receiver,omitempty"`
 
+	// Module is the slash-separated module path the function belongs to;
+	// empty for the root module
+	Module string `json:"module,omitempty"`
+
 	// Description explains what the function does
 	Description string `json:"description"`
 
@@ -117,6 +182,15 @@ type SpecFunction struct {
 	// IsAsync indicates if the function is asynchronous
 	IsAsync bool `json:"isAsync"`
 
+	// ClientStreaming indicates the function accepts a stream of requests
+	ClientStreaming bool `json:"clientStreaming,omitempty"`
+
+	// ServerStreaming indicates the function returns a stream of responses
+	ServerStreaming bool `json:"serverStreaming,omitempty"`
+
+	// Directives lists schema annotations such as GraphQL directives
+	Directives []string `json:"directives,omitempty"`
+
 	// IsPublic indicates if the function is exported/public
 	IsPublic bool `json:"isPublic"`
 
@@ -163,6 +237,22 @@ type SpecError struct {
 	Message string `json:"message"`
 }
 
+// SpecProperty is an invariant that must hold for all inputs, such as
+// "decode(encode(x)) == x".
+type SpecProperty struct {
+	// Expression is the invariant as a boolean expression over the spec's
+	// functions; empty for a property described only in prose
+	Expression string `json:"expression,omitempty"`
+
+	// Description explains the property
+	Description string `json:"description,omitempty"`
+
+	// Function is the function a property listed under its name is about.
+	// Its expression can use the function's parameters, and "result" for
+	// what the function returns.
+	Function string `json:"function,omitempty"`
+}
+
 // SpecTest represents a test case definition.
 type SpecTest struct {
 	// Name of the test case
@@ -218,6 +308,9 @@ type SpecDependency struct {
 
 	// Language-specific package names
 	Packages map[string]string `json:"packages,omitempty"`
+
+	// Stdlib marks a standard library package, documented but not installed
+	Stdlib bool `json:"stdlib,omitempty"`
 }
 
 // SpecConfig represents a configuration item.
@@ -238,7 +331,425 @@ type SpecConfig struct {
 	Required bool `json:"required"`
 }
 
+// SpecEndpoint represents an HTTP API endpoint.
+type SpecEndpoint struct {
+	// Name is the operation name (e.g., an OpenAPI operationId)
+	Name string `json:"name"`
+
+	// Function is the spec function the endpoint's handler calls; when empty
+	// the function named like the operation is used
+	Function string `json:"function,omitempty"`
+
+	// Method is the HTTP method (GET, POST, ...)
+	Method string `json:"method"`
+
+	// Path is the URL path template (e.g., /users/{id})
+	Path string `json:"path"`
+
+	// Description explains what the endpoint does
+	Description string `json:"description"`
+
+	// PathParams lists the path template parameters
+	PathParams []SpecParameter `json:"pathParams,omitempty"`
+
+	// QueryParams lists the query string parameters
+	QueryParams []SpecParameter `json:"queryParams,omitempty"`
+
+	// HeaderParams lists the request header parameters
+	HeaderParams []SpecParameter `json:"headerParams,omitempty"`
+
+	// RequestType is the request body type, if any
+	RequestType string `json:"requestType,omitempty"`
+
+	// Responses lists the possible responses by status code
+	Responses []SpecResponse `json:"responses,omitempty"`
+
+	// Auth lists the authentication schemes required (empty for public endpoints)
+	Auth []string `json:"auth,omitempty"`
+
+	// Tags groups related endpoints
+	Tags []string `json:"tags,omitempty"`
+}
+
+// SpecResponse represents an HTTP response of an endpoint.
+type SpecResponse struct {
+	// Status is the status code ("200", "404", "default", ...)
+	Status string `json:"status"`
+
+	// Type is the response body type, if any
+	Type string `json:"type,omitempty"`
+
+	// Description explains when this response is returned
+	Description string `json:"description,omitempty"`
+}
+
+// SpecCommand represents a command line subcommand, such as "db migrate".
+// Commands are listed flat, parents first, with Parent linking a
+// subcommand to the command that groups it.
+type SpecCommand struct {
+	// Name is the command's word, such as "migrate"
+	Name string `json:"name"`
+
+	// Description explains what the command does
+	Description string `json:"description,omitempty"`
+
+	// Function is the spec function the command calls; when empty the
+	// function named like the command is used
+	Function string `json:"function,omitempty"`
+
+	// Args lists the positional arguments in order; only the last may be a
+	// list, taking the remaining arguments
+	Args []SpecParameter `json:"args,omitempty"`
+
+	// Flags lists the command's options
+	Flags []SpecFlag `json:"flags,omitempty"`
+
+	// ExitCodes lists the codes the command exits with and what they mean
+	ExitCodes []SpecExitCode `json:"exitCodes,omitempty"`
+
+	// Output is the format of what the command prints: "json" or "text"
+	Output string `json:"output,omitempty"`
+
+	// Parent is the path of the command this one is nested under, such as
+	// "db" for "db migrate"; empty for a top-level command. A command with
+	// subcommands only groups them.
+	Parent string `json:"parent,omitempty"`
+}
+
+// Path returns the command's words from the root, such as "db migrate".
+func (c SpecCommand) Path() string {
+	return strings.TrimSpace(c.Parent + " " + c.Name)
+}
+
+// SpecFlag represents a command line option such as "--alias, -a".
+type SpecFlag struct {
+	// Name is the long name without dashes
+	Name string `json:"name"`
+
+	// Short is the one-letter short name without the dash, if any
+	Short string `json:"short,omitempty"`
+
+	// Type is the value type; a bool flag takes no value
+	Type string `json:"type"`
+
+	// Default is the value used when the flag is not given
+	Default string `json:"default,omitempty"`
+
+	// Description explains the flag
+	Description string `json:"description,omitempty"`
+
+	// Required is true when the flag must be given
+	Required bool `json:"required,omitempty"`
+}
+
+// SpecExitCode represents a process exit code of a command.
+type SpecExitCode struct {
+	// Code is the exit status
+	Code int `json:"code"`
+
+	// Description explains when the command exits with it
+	Description string `json:"description,omitempty"`
+}
+
+// SpecStateMachine is a finite state machine: the states something moves
+// through and the events that move it between them.
+type SpecStateMachine struct {
+	// Name of the machine, such as "Order"
+	Name string `json:"name"`
+
+	// Description explains what the machine models
+	Description string `json:"description,omitempty"`
+
+	// States lists the states in order of declaration or first use
+	States []SpecState `json:"states"`
+
+	// Events lists the events in order of declaration or first use
+	Events []SpecEvent `json:"events"`
+
+	// Initial is the state the machine starts in; the first state when
+	// none is marked
+	Initial string `json:"initial,omitempty"`
+
+	// Transitions lists the allowed moves. Transitions sharing a state and
+	// event are tried in order, and the first whose guard passes is taken.
+	Transitions []SpecTransition `json:"transitions"`
+}
+
+// SpecState is a state of a state machine.
+type SpecState struct {
+	Name string `json:"name"`
+
+	// Description explains what being in the state means
+	Description string `json:"description,omitempty"`
+
+	// Final is set for a state the machine ends in
+	Final bool `json:"final,omitempty"`
+}
+
+// SpecEvent is an event that moves a state machine between states.
+type SpecEvent struct {
+	Name string `json:"name"`
+
+	// Description explains what the event is
+	Description string `json:"description,omitempty"`
+}
+
+// SpecTransition is a move from one state to another on an event.
+type SpecTransition struct {
+	From  string `json:"from"`
+	Event string `json:"event"`
+	To    string `json:"to"`
+
+	// Guard names the condition that must hold for the transition to be
+	// taken, if any
+	Guard string `json:"guard,omitempty"`
+
+	// Action names what is done when the transition is taken, if anything
+	Action string `json:"action,omitempty"`
+}
+
+// InitialState returns the state the machine starts in.
+func (m SpecStateMachine) InitialState() string {
+	if m.Initial != "" || len(m.States) == 0 {
+		return m.Initial
+	}
+	return m.States[0].Name
+}
+
+// SpecTable is a database table and the constraints on its rows.
+type SpecTable struct {
+	// Name of the table, such as "order_items"
+	Name string `json:"name"`
+
+	// Entity names the spec type a row maps to, if any
+	Entity string `json:"entity,omitempty"`
+
+	// Description explains what the table stores
+	Description string `json:"description,omitempty"`
+
+	// Columns lists the columns in declaration order
+	Columns []SpecColumn `json:"columns"`
+
+	// PrimaryKey lists the columns of the primary key
+	PrimaryKey []string `json:"primaryKey,omitempty"`
+
+	// Indexes lists the secondary indexes
+	Indexes []SpecIndex `json:"indexes,omitempty"`
+
+	// ForeignKeys lists the references to other tables
+	ForeignKeys []SpecForeignKey `json:"foreignKeys,omitempty"`
+
+	// Checks lists CHECK constraint expressions as written
+	Checks []string `json:"checks,omitempty"`
+
+	// Relations lists how rows relate to rows of other tables
+	Relations []SpecRelation `json:"relations,omitempty"`
+}
+
+// SpecColumn is a column of a database table.
+type SpecColumn struct {
+	Name string `json:"name"`
+
+	// Type is the column's pseudo-type, such as int64 or datetime
+	Type string `json:"type"`
+
+	// SQLType is the column type as declared, such as VARCHAR(255)
+	SQLType string `json:"sqlType,omitempty"`
+
+	// Nullable is set when the column accepts NULL
+	Nullable bool `json:"nullable,omitempty"`
+
+	// Unique is set when no two rows may share a value
+	Unique bool `json:"unique,omitempty"`
+
+	// AutoIncrement is set when the database assigns the value
+	AutoIncrement bool `json:"autoIncrement,omitempty"`
+
+	// Default is the default value expression as written
+	Default string `json:"default,omitempty"`
+
+	// Description explains what the column holds
+	Description string `json:"description,omitempty"`
+}
+
+// SpecIndex is a secondary index of a table.
+type SpecIndex struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+}
+
+// SpecForeignKey is a reference from columns of a table to columns of
+// another.
+type SpecForeignKey struct {
+	// Name of the constraint, if declared
+	Name string `json:"name,omitempty"`
+
+	Columns    []string `json:"columns"`
+	RefTable   string   `json:"refTable"`
+	RefColumns []string `json:"refColumns"`
+
+	// OnDelete and OnUpdate are the referential actions, such as CASCADE
+	OnDelete string `json:"onDelete,omitempty"`
+	OnUpdate string `json:"onUpdate,omitempty"`
+}
+
+// Relation kinds.
+const (
+	RelationBelongsTo  = "belongs to"
+	RelationHasOne     = "has one"
+	RelationHasMany    = "has many"
+	RelationManyToMany = "many to many"
+)
+
+// SpecRelation is how rows of a table relate to rows of another.
+type SpecRelation struct {
+	// Name is the relation's name on the entity, such as "author"
+	Name string `json:"name"`
+
+	// Kind is one of the Relation constants
+	Kind string `json:"kind"`
+
+	// Table is the related table
+	Table string `json:"table"`
+
+	// Columns are the foreign key columns: on this table for belongs to,
+	// on the related table for has one and has many, and on the join
+	// table for many to many
+	Columns []string `json:"columns,omitempty"`
+
+	// Through is the join table of a many to many relation
+	Through string `json:"through,omitempty"`
+}
+
+// Column returns the named column, or nil.
+func (t *SpecTable) Column(name string) *SpecColumn {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// ValidateTypes checks every field, parameter, return, request and response
+// type against the type expression grammar and records the failures in
+// TypeErrors. The types themselves are kept as written.
+func (s *SpecAnalysis) ValidateTypes() {
+	s.TypeErrors = nil
+	check := func(where, typeName string) {
+		if strings.TrimSpace(typeName) == "" {
+			return
+		}
+		if _, err := typeexpr.Parse(typeName); err != nil {
+			s.TypeErrors = append(s.TypeErrors, fmt.Sprintf("%s: %v", where, err))
+		}
+	}
+
+	for _, t := range s.Types {
+		for _, f := range t.Fields {
+			check(t.Name+"."+f.Name, f.Type)
+			for _, c := range f.Constraints {
+				if err := c.check(f.Type); err != nil {
+					s.TypeErrors = append(s.TypeErrors, fmt.Sprintf("%s.%s: %v", t.Name, f.Name, err))
+				}
+			}
+		}
+	}
+	for _, fn := range s.Functions {
+		for _, p := range fn.Parameters {
+			check(fn.Name+"("+p.Name+")", p.Type)
+		}
+		for _, r := range fn.Returns {
+			check(fn.Name+" returns", r.Type)
+		}
+	}
+	for _, e := range s.Endpoints {
+		where := e.Method + " " + e.Path
+		for _, group := range [][]SpecParameter{e.PathParams, e.QueryParams, e.HeaderParams} {
+			for _, p := range group {
+				check(where+" "+p.Name, p.Type)
+			}
+		}
+		check(where+" request", e.RequestType)
+		for _, r := range e.Responses {
+			check(where+" response "+r.Status, r.Type)
+		}
+	}
+	for _, c := range s.Commands {
+		where := c.Path()
+		for _, a := range c.Args {
+			check(where+" "+a.Name, a.Type)
+		}
+		for _, f := range c.Flags {
+			check(where+" --"+f.Name, f.Type)
+		}
+	}
+	for _, t := range s.Tables {
+		for _, c := range t.Columns {
+			check(t.Name+"."+c.Name, c.Type)
+		}
+	}
+}
+
 // CalculateTotals updates the TotalItems field.
 func (s *SpecAnalysis) CalculateTotals() {
 	s.TotalItems = len(s.Types) + len(s.Functions) + len(s.Tests) + len(s.Dependencies)
 }
+
+// ConstraintSubject classifies what a constraint on a field type applies to:
+// "number", "string" or "collection", or "" when no constraint applies.
+// Optional types classify as their inner type.
+func ConstraintSubject(fieldType string) string {
+	e, err := typeexpr.Parse(fieldType)
+	if err != nil {
+		return ""
+	}
+	for e.Kind == typeexpr.Optional || e.Kind == typeexpr.Pointer {
+		e = e.Args[0]
+	}
+	switch e.Kind {
+	case typeexpr.List, typeexpr.Set, typeexpr.Map:
+		return "collection"
+	case typeexpr.Primitive:
+		switch e.Name {
+		case typeexpr.Int, typeexpr.Int64, typeexpr.Float, typeexpr.Decimal:
+			return "number"
+		case typeexpr.String:
+			return "string"
+		}
+	}
+	return ""
+}
+
+// check reports whether the constraint can apply to a field type.
+func (c SpecConstraint) check(fieldType string) error {
+	subject := ConstraintSubject(fieldType)
+	var ok bool
+	switch c.Kind {
+	case "min", "max":
+		_, err := strconv.ParseFloat(c.Value, 64)
+		ok = subject == "number" && err == nil
+	case "minLength", "maxLength":
+		n, err := strconv.Atoi(c.Value)
+		ok = (subject == "string" || subject == "collection") && err == nil && n >= 0
+	case "pattern":
+		_, err := regexp.Compile(c.Value)
+		ok = subject == "string" && err == nil
+	case "format":
+		ok = subject == "string" && (c.Value == "email" || c.Value == "url" || c.Value == "uuid")
+	case "oneOf":
+		ok = (subject == "string" || subject == "number") && len(c.Values) > 0
+	}
+	if !ok {
+		return fmt.Errorf("constraint %s %q does not apply to %s", c.Kind, c.constraintValue(), fieldType)
+	}
+	return nil
+}
+
+func (c SpecConstraint) constraintValue() string {
+	if c.Kind == "oneOf" {
+		return strings.Join(c.Values, ", ")
+	}
+	return c.Value
+}
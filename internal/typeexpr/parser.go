@@ -0,0 +1,576 @@
+package typeexpr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// bareContainers lists container names that denote a container on their
+// own; other container names without arguments are taken as user types so
+// that a spec's own Record or Collection type keeps its name.
+var bareContainers = map[string]bool{
+	"list": true, "array": true, "slice": true, "vec": true,
+	"map": true, "dict": true, "dictionary": true, "set": true,
+}
+
+type parser struct {
+	input  string
+	tokens []string
+	pos    int
+}
+
+// tokenize splits a type expression into identifiers, lifetimes ('a) and
+// punctuation. Qualified names ("pkg.Type", "std::vec::Vec") and the empty
+// interface and struct literals are single tokens.
+func tokenize(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case isIdentRune(c):
+			j := i
+			for j < len(s) {
+				if isIdentRune(rune(s[j])) || s[j] == '.' && j+1 < len(s) && isIdentRune(rune(s[j+1])) {
+					j++
+				} else if strings.HasPrefix(s[j:], "::") {
+					j += 2
+				} else {
+					break
+				}
+			}
+			word := s[i:j]
+			if (word == "interface" || word == "struct") && strings.HasPrefix(strings.TrimLeft(s[j:], " "), "{}") {
+				j = strings.Index(s[j:], "}") + j + 1
+				word += "{}"
+			}
+			tokens = append(tokens, word)
+			i = j
+		case c == '\'':
+			j := i + 1
+			for j < len(s) && isIdentRune(rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		case strings.HasPrefix(s[i:], "->"), strings.HasPrefix(s[i:], "=>"):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.HasPrefix(s[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens
+}
+
+func isIdentRune(c rune) bool {
+	return c == '_' || c == '$' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) peekAt(offset int) string {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *parser) accept(tok string) bool {
+	if p.peek() == tok {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(tok string) error {
+	if !p.accept(tok) {
+		if p.pos >= len(p.tokens) {
+			return p.errorf("expected %q at end", tok)
+		}
+		return p.errorf("expected %q, found %q", tok, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid type %q: %s", p.input, fmt.Sprintf(format, args...))
+}
+
+// startsType reports whether the next token can begin a type.
+func (p *parser) startsType() bool {
+	tok := p.peek()
+	switch tok {
+	case "", ",", ")", "]", ">", "|", "=", "{", "}", ":", "->", "=>":
+		return false
+	}
+	return true
+}
+
+// parseUnion parses alternatives separated by "|". A union with a void
+// member is optional.
+func (p *parser) parseUnion() (*Expr, error) {
+	first, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != "|" {
+		return first, nil
+	}
+	members := []*Expr{first}
+	for p.accept("|") {
+		e, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, e)
+	}
+	return union(members), nil
+}
+
+// union builds a union from its members, folding void members into Optional.
+func union(members []*Expr) *Expr {
+	var rest []*Expr
+	for _, m := range members {
+		if !(m.Kind == Primitive && m.Name == Void) {
+			rest = append(rest, m)
+		}
+	}
+	var e *Expr
+	switch len(rest) {
+	case 0:
+		return NewPrimitive(Void)
+	case 1:
+		e = rest[0]
+	default:
+		e = &Expr{Kind: Union, Args: rest}
+	}
+	if len(rest) < len(members) && e.Kind != Optional {
+		e = &Expr{Kind: Optional, Args: []*Expr{e}}
+	}
+	return e
+}
+
+// parsePostfix parses a primary type followed by any number of "[]", "..."
+// and "?" suffixes.
+func (p *parser) parsePostfix() (*Expr, error) {
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.peek() == "[" && p.peekAt(1) == "]":
+			p.pos += 2
+			e = listOf(e)
+		case p.peek() == "...":
+			p.pos++
+			e = listOf(e)
+		case p.peek() == "?":
+			p.pos++
+			if e.Kind != Optional {
+				e = &Expr{Kind: Optional, Args: []*Expr{e}}
+			}
+		default:
+			return e, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (*Expr, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return nil, p.errorf("unexpected end of type expression")
+	case tok == "*":
+		p.pos++
+		return p.wrap(Pointer)
+	case tok == "&":
+		p.pos++
+		if strings.HasPrefix(p.peek(), "'") {
+			p.pos++
+		}
+		p.accept("mut")
+		return p.wrap(Pointer)
+	case tok == "...":
+		p.pos++
+		e, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return listOf(e), nil
+	case tok == "?":
+		// Java wildcard: ?, ? extends T, ? super T
+		p.pos++
+		if p.accept("extends") || p.accept("super") {
+			return p.parsePostfix()
+		}
+		return NewPrimitive(Any), nil
+	case tok == "[":
+		return p.parseBracket()
+	case tok == "(":
+		return p.parseParen()
+	case isIdentRune(rune(tok[0])):
+		return p.parseNamed()
+	}
+	return nil, p.errorf("unexpected %q", tok)
+}
+
+// wrap parses a type and wraps it in a node of the given kind.
+func (p *parser) wrap(kind Kind) (*Expr, error) {
+	e, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{Kind: kind, Args: []*Expr{e}}, nil
+}
+
+// parseBracket parses Go slices and arrays ([]T, [N]T) and TypeScript
+// tuples ([A, B]).
+func (p *parser) parseBracket() (*Expr, error) {
+	p.pos++
+	if isNumber(p.peek()) && p.peekAt(1) == "]" {
+		p.pos++
+	}
+	if p.accept("]") {
+		e, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return listOf(e), nil
+	}
+	args, err := p.parseList("]")
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{Kind: Tuple, Args: args}, nil
+}
+
+// parseParen parses grouping, tuples, the unit type and arrow functions.
+func (p *parser) parseParen() (*Expr, error) {
+	p.pos++
+	args, err := p.parseParams(")")
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("=>") || p.accept("->") {
+		ret, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		return function(args, ret), nil
+	}
+	switch len(args) {
+	case 0:
+		return NewPrimitive(Void), nil
+	case 1:
+		return args[0], nil
+	}
+	return &Expr{Kind: Tuple, Args: args}, nil
+}
+
+// parseNamed parses a name and its type arguments, and the Go and Rust
+// keyword forms (map[K]V, func(A) R, fn(A) -> R, impl T, dyn T).
+func (p *parser) parseNamed() (*Expr, error) {
+	name := p.next()
+	lower := strings.ToLower(name)
+
+	switch {
+	case name == "map" && p.peek() == "[":
+		// Go map[K]V; otherwise map[K, V] in pseudo syntax
+		start := p.pos
+		p.pos++
+		if key, err := p.parseUnion(); err == nil && p.accept("]") && p.startsType() {
+			value, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			if value.Kind == Tuple && len(value.Args) == 0 {
+				return &Expr{Kind: Set, Args: []*Expr{key}}, nil
+			}
+			return &Expr{Kind: Map, Args: []*Expr{key, value}}, nil
+		}
+		p.pos = start
+	case (name == "func" || name == "fn" || name == "Fn" || name == "FnMut" || name == "FnOnce") && p.peek() == "(":
+		p.pos++
+		args, err := p.parseParams(")")
+		if err != nil {
+			return nil, err
+		}
+		var ret *Expr
+		if p.accept("->") || name == "func" && p.startsType() {
+			if ret, err = p.parsePostfix(); err != nil {
+				return nil, err
+			}
+		}
+		return function(args, ret), nil
+	case name == "impl" || name == "dyn" || name == "const" || name == "readonly" || name == "final":
+		return p.parsePostfix()
+	case name == "struct{}":
+		return &Expr{Kind: Tuple}, nil
+	case lower == "callable" && p.peek() == "[" && (p.peekAt(1) == "[" || p.peekAt(1) == "..."):
+		p.pos++
+		var args []*Expr
+		if !p.accept("...") {
+			p.pos++
+			var err error
+			if args, err = p.parseList("]"); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		ret, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return function(args, ret), nil
+	}
+	return p.parseGeneric(name, lower)
+}
+
+// parseGeneric parses optional <..> or [..] type arguments after a name and
+// resolves the name against the primitive and container vocabularies.
+func (p *parser) parseGeneric(name, lower string) (*Expr, error) {
+	var args []*Expr
+	hasArgs := false
+	if kind, ok := containers[strings.ToLower(lastSegment(name))]; ok && (kind == Optional || kind == Result) && p.peek() == "[" && p.peekAt(1) == "]" {
+		return nil, p.errorf("%s needs a type argument", name)
+	}
+	if p.peek() == "<" || p.peek() == "[" && p.peekAt(1) != "]" {
+		closing := ">"
+		if p.next() == "[" {
+			closing = "]"
+		}
+		var err error
+		if args, err = p.parseList(closing); err != nil {
+			return nil, err
+		}
+		hasArgs = true
+	} else if args, ok, err := p.parseWordArgs(name); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		return resolve(name, lower, args, true, p)
+	}
+	return resolve(name, lower, args, hasArgs, p)
+}
+
+// parseWordArgs parses the prose forms produced by the importers: "List of
+// T", "Set of T", "Result of T", "Map of K to V" and "Optional T".
+func (p *parser) parseWordArgs(name string) ([]*Expr, bool, error) {
+	kind, ok := containers[strings.ToLower(name)]
+	if !ok {
+		return nil, false, nil
+	}
+	if kind == Optional && p.peek() != "" && isIdentRune(rune(p.peek()[0])) && p.peek() != "of" {
+		e, err := p.parsePostfix()
+		return []*Expr{e}, true, err
+	}
+	if !p.accept("of") {
+		return nil, false, nil
+	}
+	first, err := p.parsePostfix()
+	if err != nil {
+		return nil, true, err
+	}
+	if kind == Map && p.accept("to") {
+		value, err := p.parsePostfix()
+		return []*Expr{first, value}, true, err
+	}
+	return []*Expr{first}, true, nil
+}
+
+func resolve(name, lower string, args []*Expr, hasArgs bool, p *parser) (*Expr, error) {
+	base := strings.ToLower(lastSegment(name))
+
+	if transparent[base] && len(args) == 1 {
+		return args[0], nil
+	}
+	if kind, ok := containers[base]; ok && (hasArgs || bareContainers[base]) {
+		return container(kind, name, args, p)
+	}
+	if returns, ok := functional[base]; ok && (hasArgs || base == "runnable") {
+		switch {
+		case base == "predicate" || base == "bipredicate":
+			return function(args, NewPrimitive(Bool)), nil
+		case returns && len(args) > 0:
+			return function(args[:len(args)-1], args[len(args)-1]), nil
+		}
+		return function(args, nil), nil
+	}
+	if base == "predicate" || base == "bipredicate" {
+		return function(args, NewPrimitive(Bool)), nil
+	}
+	if canonical, ok := PrimitiveName(lower); ok {
+		return &Expr{Kind: Primitive, Name: canonical, spelling: base}, nil
+	}
+	return &Expr{Kind: Named, Name: name, Args: args}, nil
+}
+
+// container builds a container node, defaulting missing arguments.
+func container(kind Kind, name string, args []*Expr, p *parser) (*Expr, error) {
+	arity := func(min, max int) error {
+		if len(args) < min || len(args) > max {
+			return p.errorf("%s takes %d to %d type arguments, got %d", name, min, max, len(args))
+		}
+		return nil
+	}
+	switch kind {
+	case List, Set:
+		if len(args) == 0 {
+			args = []*Expr{NewPrimitive(Any)}
+		}
+		if err := arity(1, 1); err != nil {
+			return nil, err
+		}
+		if kind == List {
+			return listOf(args[0]), nil
+		}
+	case Map:
+		switch len(args) {
+		case 0:
+			args = []*Expr{NewPrimitive(String), NewPrimitive(Any)}
+		case 1:
+			args = []*Expr{NewPrimitive(String), args[0]}
+		}
+		if err := arity(2, 2); err != nil {
+			return nil, err
+		}
+	case Optional:
+		if err := arity(1, 1); err != nil {
+			return nil, err
+		}
+		if args[0].Kind == Optional {
+			return args[0], nil
+		}
+	case Result:
+		if len(args) == 1 {
+			args = append(args, NewPrimitive(Error))
+		}
+		if err := arity(2, 2); err != nil {
+			return nil, err
+		}
+	case Union:
+		if err := arity(1, len(args)); err != nil {
+			return nil, err
+		}
+		return union(args), nil
+	}
+	return &Expr{Kind: kind, Args: args}, nil
+}
+
+// listOf builds a list of e; a list of bytes ([]byte, Vec<u8>, byte[]) is
+// the bytes primitive.
+func listOf(e *Expr) *Expr {
+	if e.Kind == Primitive && (e.spelling == "byte" || e.spelling == "u8" || e.spelling == "uint8" || e.spelling == "sbyte") {
+		return NewPrimitive(Bytes)
+	}
+	return &Expr{Kind: List, Args: []*Expr{e}}
+}
+
+// function builds a function node; a void return is stored as nil.
+func function(args []*Expr, ret *Expr) *Expr {
+	if ret != nil && ret.Kind == Primitive && ret.Name == Void {
+		ret = nil
+	}
+	return &Expr{Kind: Func, Args: args, Return: ret}
+}
+
+// parseList parses comma separated types up to the closing token. Rust
+// lifetimes among the arguments are skipped.
+func (p *parser) parseList(closing string) ([]*Expr, error) {
+	var args []*Expr
+	for !p.accept(closing) {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+			if p.accept(closing) {
+				break
+			}
+		}
+		if strings.HasPrefix(p.peek(), "'") {
+			p.pos++
+			if len(args) == 0 {
+				p.accept(",")
+			}
+			continue
+		}
+		e, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+	}
+	return args, nil
+}
+
+// parseParams parses a parameter list, skipping parameter names written as
+// "name: T", "name?: T" or Go's "name T".
+func (p *parser) parseParams(closing string) ([]*Expr, error) {
+	var args []*Expr
+	for !p.accept(closing) {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		if p.peek() == "" {
+			return nil, p.errorf("unexpected end of type expression")
+		}
+		optional := false
+		switch {
+		case isIdentRune(rune(p.peek()[0])) && p.peekAt(1) == ":":
+			p.pos += 2
+		case isIdentRune(rune(p.peek()[0])) && p.peekAt(1) == "?" && p.peekAt(2) == ":":
+			p.pos += 3
+			optional = true
+		case isIdentRune(rune(p.peek()[0])) && p.peekAt(1) != "" && !strings.ContainsAny(p.peekAt(1)[:1], ",)]<>|?.[") && p.peekAt(1) != "->":
+			p.pos++
+		}
+		e, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		if optional && e.Kind != Optional {
+			e = &Expr{Kind: Optional, Args: []*Expr{e}}
+		}
+		args = append(args, e)
+	}
+	return args, nil
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !unicode.IsDigit(c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,340 @@
+package typeexpr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// primitiveTypes maps canonical primitives to each language's type.
+var primitiveTypes = map[string]map[string]string{
+	String: {
+		"go": "string", "typescript": "string", "python": "str",
+		"java": "String", "rust": "String", "csharp": "string",
+	},
+	Int: {
+		"go": "int", "typescript": "number", "python": "int",
+		"java": "int", "rust": "i32", "csharp": "int",
+	},
+	Int64: {
+		"go": "int64", "typescript": "number", "python": "int",
+		"java": "long", "rust": "i64", "csharp": "long",
+	},
+	Float: {
+		"go": "float64", "typescript": "number", "python": "float",
+		"java": "double", "rust": "f64", "csharp": "double",
+	},
+	Decimal: {
+		"go": "float64", "typescript": "number", "python": "Decimal",
+		"java": "BigDecimal", "rust": "f64", "csharp": "decimal",
+	},
+	Bool: {
+		"go": "bool", "typescript": "boolean", "python": "bool",
+		"java": "boolean", "rust": "bool", "csharp": "bool",
+	},
+	Bytes: {
+		"go": "[]byte", "typescript": "Uint8Array", "python": "bytes",
+		"java": "byte[]", "rust": "Vec<u8>", "csharp": "byte[]",
+	},
+	Any: {
+		"go": "interface{}", "typescript": "any", "python": "Any",
+		"java": "Object", "rust": "serde_json::Value", "csharp": "object",
+	},
+	Void: {
+		"go": "", "typescript": "void", "python": "None",
+		"java": "void", "rust": "()", "csharp": "void",
+	},
+	Error: {
+		"go": "error", "typescript": "Error", "python": "Exception",
+		"java": "Exception", "rust": "anyhow::Error", "csharp": "Exception",
+	},
+	Date: {
+		"go": "time.Time", "typescript": "Date", "python": "datetime",
+		"java": "LocalDate", "rust": "chrono::NaiveDate", "csharp": "DateTime",
+	},
+	DateTime: {
+		"go": "time.Time", "typescript": "Date", "python": "datetime",
+		"java": "LocalDateTime", "rust": "chrono::DateTime<chrono::Utc>", "csharp": "DateTime",
+	},
+	Duration: {
+		"go": "time.Duration", "typescript": "number", "python": "timedelta",
+		"java": "Duration", "rust": "std::time::Duration", "csharp": "TimeSpan",
+	},
+	UUID: {
+		"go": "string", "typescript": "string", "python": "str",
+		"java": "UUID", "rust": "uuid::Uuid", "csharp": "Guid",
+	},
+	Context: {
+		"go": "context.Context", "typescript": "AbortSignal", "python": "Any",
+		"java": "Object", "rust": "()", "csharp": "CancellationToken",
+	},
+}
+
+// javaBoxed maps Java primitives to the classes used as type arguments.
+var javaBoxed = map[string]string{
+	"int": "Integer", "long": "Long", "double": "Double", "boolean": "Boolean", "void": "Void",
+}
+
+// Render renders the expression as a type of the given language ("go",
+// "typescript", "python", "java", "rust" or "csharp"). User types keep
+// their names.
+func (e *Expr) Render(lang string) string {
+	return e.RenderNamed(lang, nil)
+}
+
+// RenderNamed renders the expression like Render, passing user type names
+// through name.
+func (e *Expr) RenderNamed(lang string, name func(string) string) string {
+	r := renderer{lang: lang, name: name}
+	return r.render(e)
+}
+
+type renderer struct {
+	lang string
+	name func(string) string
+}
+
+func (r renderer) render(e *Expr) string {
+	switch e.Kind {
+	case Primitive:
+		if t, ok := primitiveTypes[e.Name][r.lang]; ok {
+			return t
+		}
+		return e.Name
+	case Named:
+		n := e.Name
+		if r.name != nil {
+			n = r.name(n)
+		}
+		if len(e.Args) == 0 {
+			return n
+		}
+		if r.lang == "go" || r.lang == "python" {
+			return n + "[" + r.join(e.Args) + "]"
+		}
+		return n + "<" + r.join(e.Args) + ">"
+	case List:
+		return r.list(e.Args[0])
+	case Map:
+		return r.mapOf(e.Args[0], e.Args[1])
+	case Set:
+		return r.set(e.Args[0])
+	case Optional:
+		return r.optional(e.Args[0])
+	case Result:
+		return r.result(e.Args[0], e.Args[1])
+	case Tuple:
+		return r.tuple(e.Args)
+	case Func:
+		return r.function(e.Args, e.Return)
+	case Union:
+		return r.union(e.Args)
+	case Pointer:
+		if r.lang == "go" {
+			return "*" + r.render(e.Args[0])
+		}
+		return r.render(e.Args[0])
+	}
+	return e.Name
+}
+
+// arg renders a type argument, boxing Java primitives.
+func (r renderer) arg(e *Expr) string {
+	t := r.render(e)
+	if r.lang == "java" {
+		if boxed, ok := javaBoxed[t]; ok {
+			return boxed
+		}
+	}
+	return t
+}
+
+func (r renderer) join(args []*Expr) string {
+	parts := make([]string, len(args))
+	for i, a := range args {
+		parts[i] = r.arg(a)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r renderer) list(elem *Expr) string {
+	switch r.lang {
+	case "go":
+		return "[]" + r.render(elem)
+	case "typescript":
+		t := r.render(elem)
+		if strings.ContainsAny(t, " |") {
+			t = "(" + t + ")"
+		}
+		return t + "[]"
+	case "python":
+		return "List[" + r.arg(elem) + "]"
+	case "rust":
+		return "Vec<" + r.arg(elem) + ">"
+	}
+	return "List<" + r.arg(elem) + ">"
+}
+
+func (r renderer) mapOf(key, value *Expr) string {
+	switch r.lang {
+	case "go":
+		return "map[" + r.render(key) + "]" + r.render(value)
+	case "typescript":
+		return "Record<" + r.join([]*Expr{key, value}) + ">"
+	case "python":
+		return "Dict[" + r.join([]*Expr{key, value}) + "]"
+	case "java":
+		return "Map<" + r.join([]*Expr{key, value}) + ">"
+	case "rust":
+		return "HashMap<" + r.join([]*Expr{key, value}) + ">"
+	}
+	return "Dictionary<" + r.join([]*Expr{key, value}) + ">"
+}
+
+func (r renderer) set(elem *Expr) string {
+	switch r.lang {
+	case "go":
+		return "map[" + r.render(elem) + "]struct{}"
+	case "python":
+		return "Set[" + r.arg(elem) + "]"
+	case "rust", "csharp":
+		return "HashSet<" + r.arg(elem) + ">"
+	}
+	return "Set<" + r.arg(elem) + ">"
+}
+
+func (r renderer) optional(inner *Expr) string {
+	switch r.lang {
+	case "go":
+		// Slices, maps, interfaces and pointers are already nilable
+		switch t := r.render(inner); {
+		case strings.HasPrefix(t, "[]"), strings.HasPrefix(t, "map["), strings.HasPrefix(t, "*"),
+			strings.HasPrefix(t, "func("), t == "interface{}", t == "error", t == "":
+			return t
+		default:
+			return "*" + t
+		}
+	case "typescript":
+		return r.render(inner) + " | null"
+	case "python":
+		return "Optional[" + r.render(inner) + "]"
+	case "java":
+		// Java uses null; primitives are boxed so they can hold it
+		return r.arg(inner)
+	case "rust":
+		return "Option<" + r.render(inner) + ">"
+	case "csharp":
+		return r.render(inner) + "?"
+	}
+	return r.render(inner)
+}
+
+func (r renderer) result(value, err *Expr) string {
+	if r.lang == "rust" {
+		return "Result<" + r.render(value) + ", " + r.render(err) + ">"
+	}
+	// Other languages report errors out of band (error returns, exceptions)
+	return r.render(value)
+}
+
+func (r renderer) tuple(args []*Expr) string {
+	switch r.lang {
+	case "go":
+		fields := make([]string, len(args))
+		for i, a := range args {
+			fields[i] = fmt.Sprintf("Item%d %s", i+1, r.render(a))
+		}
+		return "struct{ " + strings.Join(fields, "; ") + " }"
+	case "typescript":
+		return "[" + r.join(args) + "]"
+	case "python":
+		return "Tuple[" + r.join(args) + "]"
+	case "java":
+		if len(args) == 2 {
+			return "Map.Entry<" + r.join(args) + ">"
+		}
+		return "List<Object>"
+	}
+	return "(" + r.join(args) + ")"
+}
+
+func (r renderer) function(args []*Expr, ret *Expr) string {
+	switch r.lang {
+	case "go":
+		s := "func(" + r.join(args) + ")"
+		if ret != nil {
+			s += " " + r.render(ret)
+		}
+		return s
+	case "typescript":
+		params := make([]string, len(args))
+		for i, a := range args {
+			params[i] = fmt.Sprintf("arg%d: %s", i, r.render(a))
+		}
+		return "(" + strings.Join(params, ", ") + ") => " + r.returnType(ret)
+	case "python":
+		return "Callable[[" + r.join(args) + "], " + r.returnType(ret) + "]"
+	case "java":
+		return r.javaFunction(args, ret)
+	case "rust":
+		s := "fn(" + r.join(args) + ")"
+		if ret != nil {
+			s += " -> " + r.render(ret)
+		}
+		return s
+	case "csharp":
+		if ret == nil {
+			if len(args) == 0 {
+				return "Action"
+			}
+			return "Action<" + r.join(args) + ">"
+		}
+		return "Func<" + r.join(append(append([]*Expr{}, args...), ret)) + ">"
+	}
+	return "func"
+}
+
+// javaFunction picks the java.util.function interface for a signature.
+func (r renderer) javaFunction(args []*Expr, ret *Expr) string {
+	if ret == nil {
+		switch len(args) {
+		case 0:
+			return "Runnable"
+		case 1:
+			return "Consumer<" + r.join(args) + ">"
+		case 2:
+			return "BiConsumer<" + r.join(args) + ">"
+		}
+		return "Object"
+	}
+	switch len(args) {
+	case 0:
+		return "Supplier<" + r.arg(ret) + ">"
+	case 1:
+		return "Function<" + r.join([]*Expr{args[0], ret}) + ">"
+	case 2:
+		return "BiFunction<" + r.join([]*Expr{args[0], args[1], ret}) + ">"
+	}
+	return "Object"
+}
+
+func (r renderer) returnType(ret *Expr) string {
+	if ret == nil {
+		return r.render(NewPrimitive(Void))
+	}
+	return r.render(ret)
+}
+
+func (r renderer) union(args []*Expr) string {
+	switch r.lang {
+	case "typescript":
+		parts := make([]string, len(args))
+		for i, a := range args {
+			parts[i] = r.render(a)
+		}
+		return strings.Join(parts, " | ")
+	case "python":
+		return "Union[" + r.join(args) + "]"
+	}
+	// Languages without untagged unions fall back to their any type
+	return r.render(NewPrimitive(Any))
+}
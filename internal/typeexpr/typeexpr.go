@@ -0,0 +1,293 @@
+// Package typeexpr implements the pseudo-type grammar shared by the spec
+// parser, the code generator and the parity normalizer.
+//
+// A type expression is a primitive (string, int, bool, ...), a user type, or
+// a composition of them:
+//
+//	List[T]  Map[K, V]  Set[T]  Optional[T]  Result[T, E]
+//	(A, B)                tuple
+//	func(A, B) -> R       function
+//	A | B                 union
+//
+// Parse also accepts the spellings of the supported languages (List<T>,
+// []T, T[], map[K]V, *T, &T, T?, Vec<T>, Dict[K, V], Callable[[A], R],
+// (a: A) => R, fn(A) -> R, ...) so that types extracted from source code
+// normalize to the same tree as their spec counterparts.
+package typeexpr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind identifies the form of a type expression.
+type Kind int
+
+const (
+	// Primitive is a built-in scalar type; Name holds its canonical name
+	Primitive Kind = iota
+	// Named is a user type; Name holds the name as written, Args any type arguments
+	Named
+	// List is an ordered collection of Args[0]
+	List
+	// Map maps keys of Args[0] to values of Args[1]
+	Map
+	// Set is an unordered collection of unique Args[0]
+	Set
+	// Optional is Args[0] or nothing
+	Optional
+	// Result is a success value of Args[0] or an error of Args[1]
+	Result
+	// Tuple is a fixed sequence of Args
+	Tuple
+	// Func takes Args and returns Return (nil when it returns nothing)
+	Func
+	// Union is any one of Args
+	Union
+	// Pointer is a pointer or reference to Args[0], found in language types
+	Pointer
+)
+
+// Expr is a node of a type expression tree.
+type Expr struct {
+	Kind   Kind
+	Name   string
+	Args   []*Expr
+	Return *Expr
+
+	// spelling is the lowercase name a primitive was written as
+	spelling string
+}
+
+// Canonical primitive names.
+const (
+	String   = "string"
+	Int      = "int"
+	Int64    = "int64"
+	Float    = "float"
+	Decimal  = "decimal"
+	Bool     = "bool"
+	Bytes    = "bytes"
+	Any      = "any"
+	Void     = "void"
+	Error    = "error"
+	Date     = "date"
+	DateTime = "datetime"
+	Duration = "duration"
+	UUID     = "uuid"
+	Context  = "context"
+)
+
+// primitives maps the lowercase spellings of primitives across the spec
+// vocabulary and the supported languages to their canonical names.
+var primitives = map[string]string{
+	"string": String, "str": String, "text": String, "char": String, "rune": String,
+	"stringbuilder": String,
+
+	"int": Int, "integer": Int, "number": Int, "int8": Int, "int16": Int, "int32": Int,
+	"uint": Int, "uint8": Int, "uint16": Int, "uint32": Int, "i8": Int, "i16": Int, "i32": Int,
+	"u8": Int, "u16": Int, "u32": Int, "isize": Int, "usize": Int, "short": Int, "ushort": Int,
+	"byte": Int, "sbyte": Int,
+
+	"int64": Int64, "uint64": Int64, "i64": Int64, "u64": Int64, "i128": Int64, "u128": Int64,
+	"long": Int64, "ulong": Int64, "bigint": Int64, "biginteger": Int64,
+
+	"float": Float, "float32": Float, "float64": Float, "f32": Float, "f64": Float, "double": Float,
+
+	"decimal": Decimal, "bigdecimal": Decimal,
+
+	"bool": Bool, "boolean": Bool,
+
+	"bytes": Bytes, "bytearray": Bytes, "buffer": Bytes, "uint8array": Bytes, "binary": Bytes,
+
+	"any": Any, "object": Any, "interface{}": Any, "dynamic": Any, "unknown": Any, "json": Any,
+	"serde_json::value": Any, "jsonnode": Any,
+
+	"void": Void, "none": Void, "nothing": Void, "null": Void, "nil": Void,
+	"undefined": Void, "never": Void,
+
+	"error": Error, "exception": Error, "anyhow::error": Error, "throwable": Error,
+
+	"date": Date, "localdate": Date, "naivedate": Date, "dateonly": Date,
+
+	"datetime": DateTime, "timestamp": DateTime, "time.time": DateTime, "instant": DateTime,
+	"localdatetime": DateTime, "offsetdatetime": DateTime, "zoneddatetime": DateTime,
+	"datetimeoffset": DateTime, "naivedatetime": DateTime,
+
+	"duration": Duration, "time.duration": Duration, "timedelta": Duration, "timespan": Duration,
+
+	"uuid": UUID, "guid": UUID,
+
+	"context": Context, "context.context": Context, "cancellationtoken": Context,
+}
+
+// containers maps the lowercase names of generic containers to their kind.
+var containers = map[string]Kind{
+	"list": List, "array": List, "vec": List, "vector": List, "slice": List, "seq": List,
+	"sequence": List, "iterable": List, "iterator": List, "collection": List, "arraylist": List,
+	"linkedlist": List, "ienumerable": List, "ilist": List, "icollection": List,
+	"readonlyarray": List, "readonlycollection": List, "vecdeque": List, "deque": List,
+
+	"map": Map, "dict": Map, "dictionary": Map, "hashmap": Map, "btreemap": Map, "treemap": Map,
+	"linkedhashmap": Map, "record": Map, "mapping": Map, "idictionary": Map, "readonlymap": Map,
+	"ireadonlydictionary": Map,
+
+	"set": Set, "hashset": Set, "btreeset": Set, "treeset": Set, "frozenset": Set, "iset": Set,
+	"linkedhashset": Set, "readonlyset": Set,
+
+	"optional": Optional, "option": Optional, "maybe": Optional, "nullable": Optional,
+
+	"result": Result, "tuple": Tuple, "union": Union,
+}
+
+// transparent lists wrappers that are dropped when normalizing: smart
+// pointers, and async wrappers whose asynchrony is tracked separately.
+var transparent = map[string]bool{
+	"box": true, "rc": true, "arc": true, "cow": true, "refcell": true, "cell": true,
+	"mutex": true, "rwlock": true, "promise": true, "task": true, "valuetask": true,
+	"future": true, "completablefuture": true, "awaitable": true, "coroutine": true,
+}
+
+// functional maps functional interfaces to whether their last type argument
+// is the return type.
+var functional = map[string]bool{
+	"func": true, "function": true, "bifunction": true, "supplier": true, "callable": true,
+	"action": false, "consumer": false, "biconsumer": false, "runnable": false,
+}
+
+// NewPrimitive returns a primitive type by canonical name.
+func NewPrimitive(name string) *Expr {
+	return &Expr{Kind: Primitive, Name: name}
+}
+
+// Parse parses a type expression.
+func Parse(s string) (*Expr, error) {
+	p := &parser{input: s, tokens: tokenize(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty type expression")
+	}
+	e, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return e, nil
+}
+
+// PrimitiveName returns the canonical name of a primitive spelling.
+func PrimitiveName(name string) (string, bool) {
+	canonical, ok := primitives[strings.ToLower(name)]
+	if !ok {
+		canonical, ok = primitives[strings.ToLower(lastSegment(name))]
+	}
+	return canonical, ok
+}
+
+// String renders the expression in canonical pseudo-type syntax.
+func (e *Expr) String() string {
+	switch e.Kind {
+	case Primitive:
+		return e.Name
+	case Named:
+		if len(e.Args) == 0 {
+			return e.Name
+		}
+		return e.Name + "[" + joinStrings(e.Args, ", ") + "]"
+	case List:
+		return "List[" + e.Args[0].String() + "]"
+	case Map:
+		return "Map[" + joinStrings(e.Args, ", ") + "]"
+	case Set:
+		return "Set[" + e.Args[0].String() + "]"
+	case Optional:
+		return "Optional[" + e.Args[0].String() + "]"
+	case Result:
+		return "Result[" + joinStrings(e.Args, ", ") + "]"
+	case Tuple:
+		return "(" + joinStrings(e.Args, ", ") + ")"
+	case Func:
+		s := "func(" + joinStrings(e.Args, ", ") + ")"
+		if e.Return != nil {
+			s += " -> " + e.Return.String()
+		}
+		return s
+	case Union:
+		return joinStrings(e.Args, " | ")
+	case Pointer:
+		return "*" + e.Args[0].String()
+	}
+	return e.Name
+}
+
+// Equal reports whether two expressions denote the same type.
+func (e *Expr) Equal(other *Expr) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	if e.Kind != other.Kind || e.Name != other.Name || len(e.Args) != len(other.Args) {
+		return false
+	}
+	for i := range e.Args {
+		if !e.Args[i].Equal(other.Args[i]) {
+			return false
+		}
+	}
+	return e.Return.Equal(other.Return)
+}
+
+// Walk calls fn for the expression and each of its descendants.
+func (e *Expr) Walk(fn func(*Expr)) {
+	if e == nil {
+		return
+	}
+	fn(e)
+	for _, arg := range e.Args {
+		arg.Walk(fn)
+	}
+	e.Return.Walk(fn)
+}
+
+// Names returns the user type names an expression references, in order of
+// first appearance.
+func (e *Expr) Names() []string {
+	var names []string
+	seen := make(map[string]bool)
+	e.Walk(func(x *Expr) {
+		if x.Kind == Named && !seen[x.Name] {
+			seen[x.Name] = true
+			names = append(names, x.Name)
+		}
+	})
+	return names
+}
+
+// Unwrap strips Optional and Pointer wrappers, reporting whether there were any.
+func (e *Expr) Unwrap() (*Expr, bool) {
+	wrapped := false
+	for e.Kind == Optional || e.Kind == Pointer {
+		e, wrapped = e.Args[0], true
+	}
+	return e, wrapped
+}
+
+func joinStrings(exprs []*Expr, sep string) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = e.String()
+	}
+	return strings.Join(parts, sep)
+}
+
+// lastSegment returns the final segment of a qualified name ("pkg.Type",
+// "crate::mod::Type").
+func lastSegment(name string) string {
+	if i := strings.LastIndex(name, "::"); i >= 0 {
+		name = name[i+2:]
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
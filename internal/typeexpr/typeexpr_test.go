@@ -0,0 +1,211 @@
+package typeexpr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Spec vocabulary
+		{"string", "string"},
+		{"Text", "string"},
+		{"Integer", "int"},
+		{"Timestamp", "datetime"},
+		{"User", "User"},
+		{"List[User]", "List[User]"},
+		{"Map[string, int]", "Map[string, int]"},
+		{"map", "Map[string, any]"},
+		{"Set[string]", "Set[string]"},
+		{"Optional[int]", "Optional[int]"},
+		{"Result[User, NotFound]", "Result[User, NotFound]"},
+		{"Result<User>", "Result[User, error]"},
+		{"(string, int)", "(string, int)"},
+		{"()", "void"},
+		{"func(string, int) -> bool", "func(string, int) -> bool"},
+		{"func(User)", "func(User)"},
+		{"int | string", "int | string"},
+		{"string?", "Optional[string]"},
+		{"List of User", "List[User]"},
+		{"Optional User", "Optional[User]"},
+		{"Map of string to int", "Map[string, int]"},
+
+		// Go
+		{"[]int", "List[int]"},
+		{"[4]string", "List[string]"},
+		{"[]byte", "bytes"},
+		{"map[string]int", "Map[string, int]"},
+		{"map[string]struct{}", "Set[string]"},
+		{"map[string][]*User", "Map[string, List[*User]]"},
+		{"*string", "*string"},
+		{"interface{}", "any"},
+		{"time.Time", "datetime"},
+		{"context.Context", "context"},
+		{"func(ctx context.Context, id string) (User, error)", "func(context, string) -> (User, error)"},
+		{"models.User", "models.User"},
+
+		// TypeScript
+		{"User[]", "List[User]"},
+		{"Array<number>", "List[int]"},
+		{"Record<string, User>", "Map[string, User]"},
+		{"string | null", "Optional[string]"},
+		{"User | undefined | null", "Optional[User]"},
+		{"Promise<User>", "User"},
+		{"[string, number]", "(string, int)"},
+		{"(id: string, force?: boolean) => void", "func(string, Optional[bool])"},
+
+		// Python
+		{"Dict[str, Any]", "Map[string, any]"},
+		{"typing.Optional[str]", "Optional[string]"},
+		{"Union[int, None]", "Optional[int]"},
+		{"Tuple[int, str]", "(int, string)"},
+		{"Callable[[int], str]", "func(int) -> string"},
+		{"Callable[..., None]", "func()"},
+		{"frozenset[str]", "Set[string]"},
+
+		// Java and C#
+		{"List<? extends User>", "List[User]"},
+		{"Map<String, List<Integer>>", "Map[string, List[int]]"},
+		{"Optional<Long>", "Optional[int64]"},
+		{"Function<String, Integer>", "func(string) -> int"},
+		{"Consumer<User>", "func(User)"},
+		{"Predicate<User>", "func(User) -> bool"},
+		{"Func<int, string, bool>", "func(int, string) -> bool"},
+		{"Task<IEnumerable<Guid>>", "List[uuid]"},
+		{"int?", "Optional[int]"},
+		{"byte[]", "bytes"},
+		{"String...", "List[string]"},
+		{"java.util.UUID", "uuid"},
+
+		// Rust
+		{"Vec<u8>", "bytes"},
+		{"Option<Box<User>>", "Optional[User]"},
+		{"Result<Vec<User>, anyhow::Error>", "Result[List[User], error]"},
+		{"HashMap<String, i64>", "Map[string, int64]"},
+		{"&'a str", "*string"},
+		{"&mut Vec<User>", "*List[User]"},
+		{"Cow<'a, str>", "string"},
+		{"DateTime<Utc>", "datetime"},
+		{"fn(i32) -> bool", "func(int) -> bool"},
+		{"impl Fn(String)", "func(string)"},
+		{"std::collections::HashSet<String>", "Set[string]"},
+		{"(String, u32)", "(string, int)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if got := e.String(); got != tt.expected {
+				t.Errorf("Parse(%q) = %s, expected %s", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"List[",
+		"Map[string, int, bool]",
+		"Optional[]",
+		"int)",
+		"List[int,,]",
+		"func(int",
+		"string |",
+		"(",
+		"func(",
+		"(int,",
+		"List[(",
+	}
+
+	for _, input := range tests {
+		if e, err := Parse(input); err == nil {
+			t.Errorf("Expected Parse(%q) to fail, got %s", input, e)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]string
+	}{
+		{"List[user]", map[string]string{
+			"go": "[]User", "typescript": "User[]", "python": "List[User]",
+			"java": "List<User>", "rust": "Vec<User>", "csharp": "List<User>",
+		}},
+		{"Map[string, int]", map[string]string{
+			"go": "map[string]int", "typescript": "Record<string, number>", "python": "Dict[str, int]",
+			"java": "Map<String, Integer>", "rust": "HashMap<String, i32>", "csharp": "Dictionary<string, int>",
+		}},
+		{"Set[uuid]", map[string]string{
+			"go": "map[string]struct{}", "typescript": "Set<string>", "python": "Set[str]",
+			"java": "Set<UUID>", "rust": "HashSet<uuid::Uuid>", "csharp": "HashSet<Guid>",
+		}},
+		{"Optional[int]", map[string]string{
+			"go": "*int", "typescript": "number | null", "python": "Optional[int]",
+			"java": "Integer", "rust": "Option<i32>", "csharp": "int?",
+		}},
+		{"Optional[List[string]]", map[string]string{"go": "[]string"}},
+		{"Result[user, error]", map[string]string{
+			"go": "User", "typescript": "User", "rust": "Result<User, anyhow::Error>",
+		}},
+		{"(string, bool)", map[string]string{
+			"go": "struct{ Item1 string; Item2 bool }", "typescript": "[string, boolean]",
+			"python": "Tuple[str, bool]", "java": "Map.Entry<String, Boolean>",
+			"rust": "(String, bool)", "csharp": "(string, bool)",
+		}},
+		{"func(string) -> bool", map[string]string{
+			"go": "func(string) bool", "typescript": "(arg0: string) => boolean",
+			"python": "Callable[[str], bool]", "java": "Function<String, Boolean>",
+			"rust": "fn(String) -> bool", "csharp": "Func<string, bool>",
+		}},
+		{"func(int)", map[string]string{
+			"go": "func(int)", "python": "Callable[[int], None]", "java": "Consumer<Integer>",
+			"csharp": "Action<int>",
+		}},
+		{"int | string", map[string]string{
+			"go": "interface{}", "typescript": "number | string", "python": "Union[int, str]",
+		}},
+		{"List[int | string]", map[string]string{"typescript": "(number | string)[]"}},
+	}
+
+	for _, tt := range tests {
+		e, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.input, err)
+		}
+		for lang, expected := range tt.expected {
+			got := e.RenderNamed(lang, func(name string) string {
+				return strings.ToUpper(name[:1]) + name[1:]
+			})
+			if got != expected {
+				t.Errorf("%s as %s = %q, expected %q", tt.input, lang, got, expected)
+			}
+		}
+	}
+}
+
+func TestEqualAndNames(t *testing.T) {
+	spec, _ := Parse("Map[string, List[User]]")
+	goType, _ := Parse("map[string][]User")
+	if !spec.Equal(goType) {
+		t.Errorf("Expected %s to equal %s", spec, goType)
+	}
+
+	other, _ := Parse("Map[string, Set[User]]")
+	if spec.Equal(other) {
+		t.Errorf("Expected %s not to equal %s", spec, other)
+	}
+
+	fn, _ := Parse("func(Order, List[Item]) -> Result[Receipt, PaymentError]")
+	if got := strings.Join(fn.Names(), ","); got != "Order,Item,Receipt,PaymentError" {
+		t.Errorf("Names() = %s", got)
+	}
+}